@@ -0,0 +1,327 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/schedule"
+)
+
+// alarmGrace is how late an alarm may still go off, e.g. after the machine slept
+const alarmGrace = 10 * time.Minute
+
+// startAlarmScheduler checks for due alarms in the background
+func (a *App) startAlarmScheduler() {
+	go func() {
+		ticker := time.NewTicker(15 * time.Second)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			due, err := a.alarms.Due(now, alarmGrace)
+			if err != nil || len(due) == 0 {
+				continue
+			}
+			// Several alarms at once only need to start the music once
+			alarm := due[0]
+			a.app.QueueUpdateDraw(func() {
+				a.fireAlarm(alarm)
+			})
+		}
+	}()
+}
+
+// fireAlarm starts an alarm's playlist, ramping the volume up
+func (a *App) fireAlarm(alarm schedule.Alarm) {
+	a.stopVolumeRamp()
+	a.stop()
+
+	if alarm.Playlist != "" {
+		if err := a.loadPlaylist(alarm.Playlist); err != nil {
+			a.handleError(err, "Alarm Playlist")
+			a.loadSongs()
+		}
+	}
+	if len(a.songs) == 0 {
+		a.showWarning("⏰ Alarm went off, but there are no songs to play")
+		return
+	}
+
+	// The alarm plays at its own volume without changing the user's, which
+	// is what gets saved
+	a.currentSong = 0
+	a.alarmVolume = alarm.Volume
+	if a.player != nil {
+		// The volume is applied as the file loads, so set it first
+		a.player.SetVolume(alarm.Volume)
+		if alarm.Ramp > 0 {
+			a.player.SetLiveGain(0)
+		}
+	}
+	a.updateSongList()
+	a.play()
+	a.showToast(fmt.Sprintf("[yellow]⏰ Alarm #%d[white] - %s", alarm.ID, a.songs[a.currentSong].Title))
+
+	if alarm.Ramp > 0 && a.player != nil {
+		a.startVolumeRamp(alarm)
+	}
+}
+
+// startVolumeRamp raises the playback level over the alarm's ramp time
+func (a *App) startVolumeRamp(alarm schedule.Alarm) {
+	stop := make(chan struct{})
+	a.stopRamp = stop
+	player := a.player
+	started := time.Now()
+
+	go func() {
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				// The volume is already at the target; the live gain does the ramp
+				gain := alarm.RampVolume(0, time.Since(started)) / alarm.Volume
+				player.SetLiveGain(gain)
+				if gain >= 1 {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// playbackVolume is the volume songs play at: an alarm's until the user
+// takes over, and otherwise theirs
+func (a *App) playbackVolume() float64 {
+	if a.alarmVolume > 0 {
+		return a.alarmVolume
+	}
+	return a.volume
+}
+
+// stopVolumeRamp ends a volume ramp at full level, e.g. when the user takes
+// over, and hands the volume back to them
+func (a *App) stopVolumeRamp() {
+	a.alarmVolume = 0
+	if a.stopRamp == nil {
+		return
+	}
+	close(a.stopRamp)
+	a.stopRamp = nil
+	if a.player != nil {
+		a.player.SetLiveGain(1)
+	}
+}
+
+// showAlarms lists the scheduled alarms; a adds one, d deletes the selected one
+func (a *App) showAlarms() {
+	a.pages.RemovePage("alarms")
+
+	alarms := a.alarms.List()
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).
+		SetTitle(" Alarms - a add, d delete, Esc close ").
+		SetTitleAlign(tview.AlignCenter)
+	for _, alarm := range alarms {
+		list.AddItem(alarm.Describe(), "", 0, nil)
+	}
+	if len(alarms) == 0 {
+		list.AddItem("No alarms - press a to add one", "", 0, nil)
+	}
+
+	closeList := func() {
+		a.pages.RemovePage("alarms")
+		a.app.SetFocus(a.songList)
+	}
+
+	list.SetDoneFunc(closeList)
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyRune {
+			return event
+		}
+		switch event.Rune() {
+		case 'a':
+			if !a.denyReadOnly("Editing alarms") {
+				a.showAddAlarm()
+			}
+			return nil
+		case 'd':
+			if a.denyReadOnly("Editing alarms") {
+				return nil
+			}
+			index := list.GetCurrentItem()
+			if index < len(alarms) {
+				if err := a.alarms.Remove(alarms[index].ID); err != nil {
+					a.handleError(err, "Delete Alarm")
+					return nil
+				}
+				a.showAlarms()
+			}
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("alarms", centered(list, 70, 16), true, true)
+	a.app.SetFocus(list)
+}
+
+// showAddAlarm asks for the time, playlist and volume of a new alarm
+func (a *App) showAddAlarm() {
+	playlists := []string{"(library)"}
+	if names, err := a.playlistManager.ListPlaylists(); err == nil {
+		playlists = append(playlists, names...)
+	}
+
+	at := tview.NewInputField().
+		SetLabel("Time (HH:MM or YYYY-MM-DD HH:MM)").
+		SetFieldWidth(17)
+	daily := tview.NewCheckbox().
+		SetLabel("Every day")
+	playlist := tview.NewDropDown().
+		SetLabel("Playlist").
+		SetOptions(playlists, nil).
+		SetCurrentOption(0)
+	volume := tview.NewInputField().
+		SetLabel("Volume (%)").
+		SetText(strconv.Itoa(int(a.volume * 100))).
+		SetFieldWidth(5).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	ramp := tview.NewInputField().
+		SetLabel("Ramp up (minutes)").
+		SetText("5").
+		SetFieldWidth(5).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+
+	closeForm := func() {
+		a.pages.RemovePage("alarm-add")
+		a.showAlarms()
+	}
+
+	form := tview.NewForm().
+		AddFormItem(at).
+		AddFormItem(daily).
+		AddFormItem(playlist).
+		AddFormItem(volume).
+		AddFormItem(ramp).
+		AddButton("Save", func() {
+			next, err := schedule.ParseTime(at.GetText(), time.Now())
+			if err != nil {
+				a.showWarning(err.Error())
+				return
+			}
+			percent, err := strconv.Atoi(volume.GetText())
+			if err != nil || percent < 1 || percent > 100 {
+				a.showWarning("Volume must be between 1 and 100")
+				return
+			}
+			minutes, err := strconv.Atoi(ramp.GetText())
+			if err != nil || minutes < 0 || minutes > 60 {
+				a.showWarning("Ramp up must be between 0 and 60 minutes")
+				return
+			}
+
+			alarm := schedule.Alarm{
+				Next:   next,
+				Daily:  daily.IsChecked(),
+				Volume: float64(percent) / 100,
+				Ramp:   time.Duration(minutes) * time.Minute,
+			}
+			if index, _ := playlist.GetCurrentOption(); index > 0 {
+				alarm.Playlist = playlists[index]
+			}
+
+			if _, err := a.alarms.Add(alarm); err != nil {
+				a.handleError(err, "Save Alarm")
+				return
+			}
+			closeForm()
+		}).
+		AddButton("Cancel", closeForm)
+	form.SetCancelFunc(closeForm)
+
+	form.SetTitle(" New Alarm ").SetBorder(true)
+	a.pages.AddPage("alarm-add", centered(form, 64, 15), true, true)
+	a.app.SetFocus(form)
+}
+
+// runAlarmCommand handles "tuneminal alarm add|list|remove"
+func runAlarmCommand(args []string, readOnly bool) error {
+	store := schedule.NewStore()
+	usage := fmt.Errorf("usage: tuneminal alarm add -at TIME [-daily] [-playlist NAME] [-volume PCT] [-ramp DURATION] | list | remove ID")
+	if len(args) == 0 {
+		return usage
+	}
+
+	switch args[0] {
+	case "list":
+		alarms := store.List()
+		if len(alarms) == 0 {
+			fmt.Println("No alarms")
+		}
+		for _, alarm := range alarms {
+			fmt.Println(alarm.Describe())
+		}
+		return nil
+
+	case "remove":
+		if len(args) != 2 {
+			return usage
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return usage
+		}
+		if readOnly {
+			return errReadOnly
+		}
+		return store.Remove(id)
+
+	case "add":
+		flags := flag.NewFlagSet("alarm add", flag.ContinueOnError)
+		at := flags.String("at", "", "time to go off: HH:MM or \"YYYY-MM-DD HH:MM\"")
+		daily := flags.Bool("daily", false, "go off every day")
+		playlist := flags.String("playlist", "", "playlist to play (default: the library)")
+		volume := flags.Int("volume", 80, "volume to ramp up to, in percent")
+		ramp := flags.Duration("ramp", 5*time.Minute, "how long the volume takes to ramp up")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *at == "" {
+			return usage
+		}
+
+		next, err := schedule.ParseTime(*at, time.Now())
+		if err != nil {
+			return err
+		}
+		if *volume < 1 || *volume > 100 {
+			return fmt.Errorf("volume must be between 1 and 100")
+		}
+		if readOnly {
+			return errReadOnly
+		}
+
+		alarm, err := store.Add(schedule.Alarm{
+			Next:     next,
+			Daily:    *daily,
+			Playlist: *playlist,
+			Volume:   float64(*volume) / 100,
+			Ramp:     *ramp,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println("Added", alarm.Describe())
+		fmt.Println("Alarms go off while tuneminal is running.")
+		return nil
+	}
+	return usage
+}
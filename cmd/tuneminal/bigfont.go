@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/bigfont"
+)
+
+// Lyrics display styles
+const (
+	lyricsStyleStandard = "standard"
+	lyricsStyleBig      = "big"
+)
+
+// showBigLyrics reports whether the current line is drawn in block letters
+func (a *App) showBigLyrics() bool {
+	return a.appConfig.LyricsStyle == lyricsStyleBig
+}
+
+// toggleBigLyrics switches between block letters and the usual lyrics
+func (a *App) toggleBigLyrics() {
+	if a.showBigLyrics() {
+		a.appConfig.LyricsStyle = lyricsStyleStandard
+		a.showToast("🔠 Standard lyrics")
+	} else {
+		a.appConfig.LyricsStyle = lyricsStyleBig
+		a.showToast("🔠 Big lyrics: the current line in block letters")
+	}
+	a.saveConfig()
+	a.updateKaraokeLyrics()
+}
+
+// bigLyricLines lays out lyrics with the line to sing in block letters
+// width columns wide, between the line just sung and the next one. It
+// returns nil when the line has characters the big font can't draw, so
+// the usual display is used instead.
+func bigLyricLines(previous, current, next string, width int) []string {
+	if current == "" || !bigfont.Supported(current) {
+		return nil
+	}
+
+	lines := []string{"[blue::d]" + tview.Escape(previous) + "[white::-]", ""}
+	for _, row := range bigfont.Render(current, width) {
+		lines = append(lines, "[yellow]"+row+"[white]")
+	}
+	return append(lines, "", "[white]"+tview.Escape(next))
+}
+
+// createBigLyricsDisplay shows the current line in block letters, or "" when
+// the big font can't draw it
+func (a *App) createBigLyricsDisplay(activeIndex int) string {
+	_, _, width, height := a.lyrics.GetInnerRect()
+	if width <= 0 {
+		width = 80
+	}
+
+	line := func(i int) string {
+		if i < 0 || i >= len(a.lyricLines) {
+			return ""
+		}
+		return a.lyricLines[i].Text
+	}
+	lines := bigLyricLines(line(activeIndex-1), line(activeIndex), line(activeIndex+1), width-2)
+	if lines == nil {
+		return ""
+	}
+	padding := max(0, (height-len(lines))/2)
+	return strings.Repeat("\n", padding) + strings.Join(lines, "\n")
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/paths"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// launchOptions carries command-line requests into the interactive app
+type launchOptions struct {
+	importLyrics []string // plain lyrics to time with the tap-to-sync tool
+	importSong   string   // song path the imported lyrics belong to
+	readOnly     bool     // --read-only was given
+	kiosk        bool     // --kiosk was given
+}
+
+// globalFlags are the options that may come before any command
+type globalFlags struct {
+	portable    bool   // --portable was given
+	portableDir string // directory given as --portable=DIR
+	readOnly    bool   // --read-only was given
+	kiosk       bool   // --kiosk was given
+	library     string // library given as --library=NAME
+}
+
+// parseGlobalFlags takes the global flags off the front of args
+func parseGlobalFlags(args []string) (globalFlags, []string) {
+	var flags globalFlags
+	for len(args) > 0 {
+		if args[0] == "--read-only" {
+			flags.readOnly = true
+		} else if args[0] == "--kiosk" {
+			flags.kiosk = true
+		} else if name, ok := strings.CutPrefix(args[0], "--library="); ok {
+			flags.library = name
+		} else if value, ok := strings.CutPrefix(args[0], "--portable"); ok && (value == "" || value[0] == '=') {
+			flags.portable = true
+			flags.portableDir = strings.TrimPrefix(value, "=")
+		} else {
+			break
+		}
+		args = args[1:]
+	}
+	return flags, args
+}
+
+// runCLI handles command-line subcommands. It returns the options to launch
+// the interactive app with, or nil when the command already did its work.
+func runCLI(args []string) (*launchOptions, error) {
+	flags, args := parseGlobalFlags(args)
+	if err := applyPortableMode(flags); err != nil {
+		return nil, err
+	}
+	readOnly := flags.readOnly || configReadOnly()
+	if err := useLibrary(flags.library); err != nil {
+		return nil, err
+	}
+
+	if len(args) == 0 {
+		return &launchOptions{readOnly: readOnly, kiosk: flags.kiosk}, nil
+	}
+
+	switch args[0] {
+	case "lyrics":
+		options, err := runLyricsCommand(args[1:])
+		if options != nil {
+			options.readOnly = readOnly
+		}
+		return options, err
+	case "lyrics-view":
+		return nil, runLyricsViewCommand(args[1:])
+	case "kiosk":
+		return nil, runKioskCommand(args[1:], readOnly)
+	case "organize":
+		return nil, runOrganizeCommand(args[1:], readOnly)
+	case "import-itunes":
+		return nil, runITunesImportCommand(args[1:], readOnly)
+	case "import-spotify":
+		return nil, runSpotifyImportCommand(args[1:], readOnly)
+	case "verify":
+		return nil, runVerifyCommand(args[1:])
+	case "alarm":
+		return nil, runAlarmCommand(args[1:], readOnly)
+	case "gain-scan":
+		return nil, runGainScanCommand(args[1:])
+	case "recap":
+		return nil, runRecapCommand(args[1:])
+	case "stats":
+		return nil, runStatsCommand(args[1:])
+	case "doctor":
+		return nil, runDoctorCommand(args[1:])
+	case "update":
+		return nil, runUpdateCommand(args[1:], readOnly)
+	case "help", "-h", "--help":
+		printUsage(os.Stdout)
+		return nil, nil
+	default:
+		printUsage(os.Stderr)
+		return nil, fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// applyPortableMode handles --portable[=DIR]. Without the flag, portable
+// mode turns on by itself when a tuneminal-data directory sits beside the
+// executable, so a copy on a USB stick keeps using its own files.
+func applyPortableMode(flags globalFlags) error {
+	dir := flags.portableDir
+	if dir == "" {
+		beside, err := paths.BesideExecutable()
+		if err != nil {
+			if flags.portable {
+				return fmt.Errorf("failed to find the executable's directory: %w", err)
+			}
+			return nil
+		}
+		if !flags.portable {
+			if info, err := os.Stat(beside); err != nil || !info.IsDir() {
+				return nil
+			}
+		}
+		dir = beside
+	}
+
+	dir, err := filepath.Abs(utils.ExpandHome(dir))
+	if err != nil {
+		return err
+	}
+	// Creating it now means later runs find it without the flag
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create portable directory: %w", err)
+	}
+	paths.SetPortable(dir)
+	return nil
+}
+
+// printUsage lists the available subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, `Usage: tuneminal [--portable[=DIR]] [--read-only] [--kiosk] [--library=NAME] [command]
+
+Without a command, starts the karaoke player.
+
+With --portable, config, playlists, history and exports are kept in DIR, by
+default a tuneminal-data directory beside the executable, instead of the home
+directory. Once that directory exists, portable mode turns on by itself.
+
+With --read-only (or read_only in the config), nothing in the library or the
+config is changed: deleting, renaming, moving and organizing files, editing
+lyrics, hiding songs and saving settings are turned off, e.g. for a kiosk.
+
+With --kiosk (or kiosk in the config), the player also runs as a dedicated
+karaoke box: read-only, sharing its playlist for guest requests, playing the
+kiosk playlist on start and reconnecting the audio device until it comes back.
+
+With --library=NAME, the player and commands work on that library from the
+config's libraries instead of the one last switched to with Ctrl+L.
+
+Commands:
+  lyrics import [-song FILE] -|FILE   Time plain lyrics (from stdin or a file) with tap-to-sync
+  lyrics coverage [-missing]          Report which songs have synced, unsynced or no lyrics;
+                                      -missing prints just the paths lacking lyrics, one per line
+  lyrics fetch [-]                    Fetch lyrics for every song missing them, or for the
+                                      paths read from stdin, e.g.
+                                      tuneminal lyrics coverage -missing | tuneminal lyrics fetch -
+  lyrics check [-width N] [-gap 20s]  Grade each song's lyrics A-F for karaoke: timestamps out
+                                      of order, long gaps, lines too wide, no end marker
+  lyrics export [-force] [FILE...]    Write the timed lyrics embedded in songs' tags (ID3 SYLT)
+                                      to an .lrc next to each, for every song or just FILEs
+  lyrics-view [-big] [-socket PATH]   Show just the big lyrics of the song playing in the
+                                      player, e.g. in a terminal on a screen facing the singer;
+                                      -big draws the current line in block letters
+  kiosk [-playlist NAME] [-off] [-apply]
+                                      Preview (or with -apply, set up) kiosk mode: the config
+                                      profile and starting on the first console at boot
+  organize [-template T] [-apply]     Preview (or with -apply, perform) moving library files
+                                      to match a naming template such as {artist}/{album}/{title}{ext}
+  import-itunes [-apply] FILE         Preview (or with -apply, import) the playlists, ratings
+                                      and play counts of an iTunes/Music Library.xml export
+  import-spotify [-name NAME] [-apply] FILE
+                                      Match a Spotify playlist export (CSV or JSON) to library
+                                      songs by title and artist and create local playlists
+  verify                              Checksum and decode every library file, listing problem files
+  gain-scan [-workers N] [-force]     Measure the loudness of every library file so playback
+                                      is normalized (normalize_volume in the config)
+  alarm add -at TIME [-daily] [-playlist NAME] [-volume PCT] [-ramp 5m]
+                                      Start a playlist at a set time, ramping the volume up
+  alarm list | alarm remove ID        Show or delete scheduled alarms
+  recap                               Show this week's plays and best scores, and on this
+                                      day last year
+  stats [-format csv|json] [-from DATE] [-to DATE]
+                                      Export per-song plays, scores and lyric status, joined
+                                      with the library, to the export directory
+  doctor                              Report audio, devices, config and data paths and recent
+                                      errors, for bug reports
+  update [-apply]                     Show whether there is a newer release and what's new;
+                                      -apply downloads and installs it over this binary`)
+}
+
+// runLyricsCommand handles "tuneminal lyrics ..."
+func runLyricsCommand(args []string) (*launchOptions, error) {
+	if len(args) > 0 && args[0] == "coverage" {
+		return nil, runCoverageCommand(args[1:])
+	}
+	if len(args) > 0 && args[0] == "fetch" {
+		return nil, runFetchCommand(args[1:])
+	}
+	if len(args) > 0 && args[0] == "check" {
+		return nil, runCheckCommand(args[1:])
+	}
+	if len(args) > 0 && args[0] == "export" {
+		return nil, runExportEmbeddedCommand(args[1:])
+	}
+	if len(args) == 0 || args[0] != "import" {
+		return nil, fmt.Errorf("usage: tuneminal lyrics import|coverage|fetch|check|export ...")
+	}
+
+	flags := flag.NewFlagSet("lyrics import", flag.ContinueOnError)
+	song := flags.String("song", "", "audio file the lyrics belong to (default: selected song)")
+	if err := flags.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != 1 {
+		return nil, fmt.Errorf("usage: tuneminal lyrics import [-song FILE] -|FILE")
+	}
+
+	var data []byte
+	var err error
+	if source := flags.Arg(0); source == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lyrics: %w", err)
+	}
+
+	lines := lyrics.PlainLines(string(data))
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no lyrics found in input")
+	}
+
+	return &launchOptions{importLyrics: lines, importSong: *song}, nil
+}
+
+// runExportEmbeddedCommand handles "tuneminal lyrics export"
+func runExportEmbeddedCommand(args []string) error {
+	flags := flag.NewFlagSet("lyrics export", flag.ContinueOnError)
+	force := flags.Bool("force", false, "overwrite lyrics files already next to the songs")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		var err error
+		if files, err = libraryFiles(); err != nil {
+			return err
+		}
+	}
+	exported := exportEmbeddedFiles(files, *force, os.Stdout)
+	fmt.Printf("Exported embedded lyrics of %d songs\n", exported)
+	return nil
+}
+
+// runCoverageCommand handles "tuneminal lyrics coverage"
+func runCoverageCommand(args []string) error {
+	flags := flag.NewFlagSet("lyrics coverage", flag.ContinueOnError)
+	missingOnly := flags.Bool("missing", false, "print only the paths of songs without lyrics")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := libraryCoverage()
+	if err != nil {
+		return err
+	}
+
+	if *missingOnly {
+		// One path per line, ready to pipe into a batch fetch
+		for _, path := range report.Missing {
+			fmt.Println(path)
+		}
+		return nil
+	}
+
+	fmt.Print(formatCoverage(report, false))
+	return nil
+}
+
+// runFetchCommand handles "tuneminal lyrics fetch"
+func runFetchCommand(args []string) error {
+	flags := flag.NewFlagSet("lyrics fetch", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var paths []string
+	if flags.NArg() == 1 && flags.Arg(0) == "-" {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if path := strings.TrimSpace(scanner.Text()); path != "" {
+				paths = append(paths, path)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read paths: %w", err)
+		}
+	} else {
+		report, err := libraryCoverage()
+		if err != nil {
+			return err
+		}
+		paths = report.Missing
+	}
+
+	if len(paths) == 0 {
+		fmt.Println("Nothing to fetch")
+		return nil
+	}
+
+	songs := make([]lyrics.SongInfo, len(paths))
+	for i, path := range paths {
+		songs[i] = songInfoFor(path)
+	}
+
+	fetcher := lyrics.NewBatchFetcher(lyrics.NewLRCLibProvider())
+	fetcher.OnProgress = func(progress lyrics.BatchProgress) {
+		if progress.Current != "" {
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", progress.Done+1, progress.Total, progress.Current)
+		}
+	}
+
+	results := fetcher.Run(context.Background(), songs)
+	fmt.Print(formatFetchSummary(results, false))
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/overrides"
+	"github.com/tuneminal/tuneminal/pkg/player"
+)
+
+// clickTrackBPM returns the tempo a lyrics-only song is clicked at
+func (a *App) clickTrackBPM(song Song) float64 {
+	if bpm := a.overrides.Get(song.Path).BPM; bpm > 0 {
+		return bpm
+	}
+	if a.appConfig.ClickTrackBPM > 0 {
+		return a.appConfig.ClickTrackBPM
+	}
+	return 90
+}
+
+// clickTrackCountIn returns how long the count-in before the first line of
+// a lyrics-only song lasts. Without the click there's none, so the lyrics
+// keep the timing of the recording an outside sound system plays, or of
+// the MIDI clock.
+func (a *App) clickTrackCountIn(song Song) time.Duration {
+	if !a.appConfig.LyricsOnlyClick || a.clock != nil {
+		return 0
+	}
+	beats := max(1, a.appConfig.MetronomeBeatsPerBar) * max(0, a.appConfig.ClickTrackCountInBars)
+	return time.Duration(float64(beats) * float64(time.Minute) / a.clickTrackBPM(song))
+}
+
+// loadLyricsOnly loads the lyrics of a lyrics-only song, moved later when
+// the first line comes too soon for the count-in, and sets the song's
+// duration to match
+func (a *App) loadLyricsOnly(song *Song) {
+	track, err := lyrics.LoadFile(song.LyricsPath)
+	if err != nil || len(track.Lines) == 0 {
+		a.loadDemoLyrics()
+		return
+	}
+
+	if shift := a.clickTrackCountIn(*song) - track.Lines[0].Time; shift > 0 {
+		for i := range track.Lines {
+			track.Lines[i].Time += shift
+		}
+	}
+	song.Duration = track.Lines[len(track.Lines)-1].Time + lyricsOnlyTail
+	a.setLyrics(track)
+}
+
+// clickTrack returns the click a lyrics-only song plays against, timed so
+// the count-in ends on the first line
+func (a *App) clickTrack(song Song) *player.MetronomeConfig {
+	var offset time.Duration
+	if len(a.lyricLines) > 0 {
+		offset = max(0, a.lyricLines[0].Time-a.clickTrackCountIn(song))
+	}
+	volume := a.appConfig.MetronomeVolume
+	if volume <= 0 {
+		volume = 0.5
+	}
+	return &player.MetronomeConfig{
+		BPM:         a.clickTrackBPM(song),
+		Offset:      offset,
+		Volume:      volume,
+		BeatsPerBar: a.appConfig.MetronomeBeatsPerBar,
+	}
+}
+
+// showClickTrack sets the tempo a lyrics-only song is practised at, how
+// many bars are counted in before it and whether there's a click at all
+func (a *App) showClickTrack(song Song) {
+	if a.denyReadOnly("Changing the click track") {
+		return
+	}
+
+	bpm := tview.NewInputField().
+		SetLabel("Tempo (BPM)").
+		SetText(strconv.FormatFloat(a.clickTrackBPM(song), 'f', -1, 64)).
+		SetFieldWidth(8)
+	countIn := tview.NewInputField().
+		SetLabel("Count-in bars").
+		SetText(strconv.Itoa(a.appConfig.ClickTrackCountInBars)).
+		SetFieldWidth(4).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	click := tview.NewCheckbox().
+		SetLabel("Click (off to prompt alongside other audio)").
+		SetChecked(a.appConfig.LyricsOnlyClick)
+
+	closeClickTrack := func() {
+		a.pages.RemovePage("click-track")
+		a.app.SetFocus(a.songList)
+	}
+
+	form := tview.NewForm().
+		AddFormItem(bpm).
+		AddFormItem(countIn).
+		AddFormItem(click).
+		AddButton("Save", func() {
+			tempo, err := strconv.ParseFloat(strings.TrimSpace(bpm.GetText()), 64)
+			if err != nil || tempo < 20 || tempo > 300 {
+				a.showWarning("Enter a tempo from 20 to 300 BPM")
+				return
+			}
+			bars, err := strconv.Atoi(countIn.GetText())
+			if err != nil || bars < 0 || bars > 8 {
+				a.showWarning("Enter from 0 to 8 count-in bars")
+				return
+			}
+
+			err = a.overrides.Update(song.Path, func(o *overrides.Override) {
+				o.BPM = tempo
+				o.BeatOffset = 0
+				o.TempoTapped = true
+			})
+			if err != nil {
+				a.handleError(err, "Save Click Track")
+				return
+			}
+			if bars != a.appConfig.ClickTrackCountInBars || click.IsChecked() != a.appConfig.LyricsOnlyClick {
+				a.appConfig.ClickTrackCountInBars = bars
+				a.appConfig.LyricsOnlyClick = click.IsChecked()
+				a.saveConfig()
+			}
+			closeClickTrack()
+			if click.IsChecked() {
+				a.showToast(fmt.Sprintf("[green]🥁 %s clicks at %.0f BPM, from the next play[white]", song.Title, tempo))
+			} else {
+				a.showToast("[green]📝 Lyrics-only songs play without a click, from the next play[white]")
+			}
+		}).
+		AddButton("Cancel", closeClickTrack)
+	form.SetCancelFunc(closeClickTrack)
+	form.SetBorder(true).
+		SetTitle(" Click Track - " + song.Title + " ").
+		SetTitleAlign(tview.AlignCenter)
+
+	a.pages.AddPage("click-track", centered(form, 60, 11), true, true)
+	a.app.SetFocus(form)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/midiclock"
+)
+
+// startClockSync (re)starts following the MIDI clock input in the config.
+// Lyrics-only songs then start and stop with it, and their lyrics move
+// with its beats instead of the time since they started.
+func (a *App) startClockSync() {
+	if a.clock != nil {
+		a.clock.Close()
+		a.clock = nil
+	}
+	if a.appConfig.ClockSync == "" {
+		return
+	}
+
+	clock, err := midiclock.Open(a.appConfig.ClockSync)
+	if err != nil {
+		a.errorLog.Add("MIDI Clock", err)
+		a.showToast(fmt.Sprintf("[red]❌ Can't follow the MIDI clock: %v[white]", err))
+		return
+	}
+	clock.OnTransport = func(running bool) {
+		a.app.QueueUpdateDraw(func() {
+			if a.clock == clock {
+				a.onClockTransport(running)
+			}
+		})
+	}
+	a.clock = clock
+}
+
+// followsClock reports whether the current song's lyrics move with the
+// MIDI clock
+func (a *App) followsClock() bool {
+	return a.clock != nil && a.currentSong >= 0 && a.currentSong < len(a.songs) && isLyricsOnly(a.songs[a.currentSong])
+}
+
+// clockPosition returns how far into the current song the MIDI clock is,
+// taking a beat to last as long as it does at the song's own tempo
+func (a *App) clockPosition() time.Duration {
+	transport := a.clock.Transport(time.Now())
+	beat := float64(time.Minute) / a.clickTrackBPM(a.songs[a.currentSong])
+	return time.Duration(transport.Beats * beat)
+}
+
+// onClockTransport starts, continues or pauses the cued lyrics-only song
+// when the band does
+func (a *App) onClockTransport(running bool) {
+	if !a.followsClock() {
+		return
+	}
+	switch {
+	case running && a.isPaused:
+		a.resume()
+	case running && !a.isPlaying:
+		a.play()
+	case !running && a.isPlaying && !a.isPaused:
+		a.pause()
+	default:
+		return
+	}
+
+	transport := a.clock.Transport(time.Now())
+	if running {
+		a.showToast(fmt.Sprintf("[cyan]⏱ Following the MIDI clock at %.0f BPM[white]", transport.BPM))
+	} else {
+		a.showToast("[cyan]⏱ MIDI clock stopped[white]")
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/config"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// offerConfigRecovery asks what to do about a config.json that couldn't be
+// read at start-up. Until it's answered the app runs on the defaults and
+// saves nothing, so the damaged file isn't overwritten.
+func (a *App) offerConfigRecovery() {
+	if a.configDamage == nil || a.isOverlayOpen() {
+		return
+	}
+	path := config.GetConfigPath()
+
+	buttons := []string{"Start fresh", "Not now"}
+	text := fmt.Sprintf("[red]Your settings couldn't be read[white]\n\n%v\n\nTuneminal is running on the default settings.", a.configDamage)
+	if a.readOnly {
+		// Restoring or starting fresh would change the file
+		buttons = []string{"OK"}
+		text += " Read-only mode is on, so the file is left for an admin to fix."
+	} else if _, err := config.ReadConfig(utils.BackupPath(path)); err == nil {
+		buttons = append([]string{"Restore backup"}, buttons...)
+		text += " The settings from before the last save can be restored."
+	}
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("config-recovery")
+			a.app.SetFocus(a.songList)
+
+			switch buttonLabel {
+			case "Restore backup":
+				if _, err := config.RestoreBackup(path); err != nil {
+					a.handleError(err, "Restore Settings")
+					return
+				}
+				a.configDamage = nil
+				a.reloadConfig()
+			case "Start fresh":
+				damaged, err := config.SetAside(path)
+				if err != nil && !os.IsNotExist(err) {
+					a.handleError(err, "Reset Settings")
+					return
+				}
+				a.configDamage = nil
+				a.saveConfig()
+				a.showMessage("⚙ Saved the default settings. The damaged file was kept as " + damaged)
+			default:
+				a.showToast("[yellow]⚙ Settings won't be saved until config.json is fixed[white]")
+			}
+		})
+	a.pages.AddPage("config-recovery", modal, true, true)
+	a.app.SetFocus(modal)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/config"
+)
+
+// configWatchInterval is how often the config file is checked for edits
+const configWatchInterval = 2 * time.Second
+
+// startConfigWatcher reloads the config when config.json is edited outside
+// the app, so settings can be tweaked without stopping the music
+func (a *App) startConfigWatcher() {
+	path := config.GetConfigPath()
+	var seen time.Time
+	if info, err := os.Stat(path); err == nil {
+		seen = info.ModTime()
+	}
+
+	go func() {
+		ticker := time.NewTicker(configWatchInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			info, err := os.Stat(path)
+			if err != nil || info.ModTime().Equal(seen) {
+				continue
+			}
+			seen = info.ModTime()
+			a.app.QueueUpdateDraw(a.reloadConfig)
+		}
+	}()
+}
+
+// restartSettings names the settings that are only picked up at start-up,
+// by whether they differ between two configs
+func restartSettings(old, updated *config.Config) []string {
+	var names []string
+	if old.AudioOutput != updated.AudioOutput || old.AudioOutputDevice != updated.AudioOutputDevice ||
+		old.AudioLatencyMs != updated.AudioLatencyMs || old.BufferSize != updated.BufferSize {
+		names = append(names, "audio output")
+	}
+	if old.SongCache != updated.SongCache || old.SongCacheMB != updated.SongCacheMB || old.DecodeCacheMB != updated.DecodeCacheMB {
+		names = append(names, "song caches")
+	}
+	if !slices.Equal(old.RemoteLibraries, updated.RemoteLibraries) {
+		names = append(names, "remote libraries")
+	}
+	if old.ReadOnly != updated.ReadOnly || old.Kiosk != updated.Kiosk || old.KioskPlaylist != updated.KioskPlaylist {
+		names = append(names, "read-only and kiosk mode")
+	}
+	return names
+}
+
+// reloadConfig applies an edited config.json. Settings that can change
+// under a playing song take effect straight away; the ones only read at
+// start-up keep their current values until Tuneminal is restarted.
+func (a *App) reloadConfig() {
+	updated, err := config.ReadConfig(config.GetConfigPath())
+	if err != nil {
+		// Most likely caught halfway through being saved; the next change
+		// to the file is picked up again
+		a.errorLog.Add("Config Reload", err)
+		a.showToast("[red]❌ config.json couldn't be read, keeping the current settings[white]")
+		return
+	}
+
+	// Fixing a damaged config by hand recovers it
+	a.configDamage = nil
+
+	// Volume, shuffle and repeat belong to the running session and are
+	// written back on the next save
+	current := *a.appConfig
+	current.DefaultVolume = a.volume
+	current.ShuffleMode = a.shuffleMode
+	current.RepeatMode = a.repeatMode
+	current.RepeatOne = a.repeatOne
+	updated.DefaultVolume = current.DefaultVolume
+	updated.ShuffleMode = current.ShuffleMode
+	updated.RepeatMode = current.RepeatMode
+	updated.RepeatOne = current.RepeatOne
+
+	before, _ := json.Marshal(&current)
+	after, _ := json.Marshal(updated)
+	if string(before) == string(after) {
+		// Our own save, or an edit that changed nothing
+		return
+	}
+
+	restart := restartSettings(&current, updated)
+	updated.AudioOutput = current.AudioOutput
+	updated.AudioOutputDevice = current.AudioOutputDevice
+	updated.AudioLatencyMs = current.AudioLatencyMs
+	updated.BufferSize = current.BufferSize
+	updated.SongCache = current.SongCache
+	updated.SongCacheMB = current.SongCacheMB
+	updated.DecodeCacheMB = current.DecodeCacheMB
+	updated.RemoteLibraries = current.RemoteLibraries
+	updated.ReadOnly = current.ReadOnly
+	updated.Kiosk = current.Kiosk
+	updated.KioskPlaylist = current.KioskPlaylist
+
+	watchChanged := !slices.Equal(current.WatchFolders, updated.WatchFolders) ||
+		current.ImportTemplate != updated.ImportTemplate || current.WatchMove != updated.WatchMove
+	clockChanged := current.ClockSync != updated.ClockSync
+	oscChanged := current.OSCAddress != updated.OSCAddress
+	*a.appConfig = *updated
+
+	a.applyAudioSettings()
+	a.applyMetronome()
+	if watchChanged {
+		a.startWatching()
+	}
+	if clockChanged {
+		a.startClockSync()
+	}
+	if oscChanged {
+		a.startOSC()
+	}
+	a.arrangePanels()
+	a.updateAllDisplays()
+	a.updateVisualizer()
+
+	if len(restart) > 0 {
+		a.showMessage(fmt.Sprintf("⚙ Settings reloaded. Restart Tuneminal to use the new %s.", strings.Join(restart, ", ")))
+		return
+	}
+	a.showToast("⚙ Settings reloaded from config.json")
+}
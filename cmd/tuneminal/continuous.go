@@ -0,0 +1,125 @@
+package main
+
+import (
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/remotefs"
+)
+
+// In a playlist, or with repeat on, one song follows another by itself. The
+// player is handed the next song ahead of time and carries straight on into
+// it, so there's no gap while it loads.
+
+// autoAdvanceIndex returns the song that plays by itself after the current
+// one: the same song in repeat-one mode, the first in the queue, else the
+// next in a playlist, in shuffled order when shuffle is on, going round again in repeat mode or when the
+// playlist is set to repeat at its end. It is -1 when playback stops after
+// the current song, or the playlist's end mode decides what follows.
+func (a *App) autoAdvanceIndex() int {
+	if a.currentSong < 0 || a.currentSong >= len(a.songs) {
+		return -1
+	}
+	// Repeat one plays the song again, and queued songs come next
+	if a.repeatOne {
+		return a.currentSong
+	}
+	if queued := a.queuedSongs(); len(queued) > 0 {
+		return queued[0]
+	}
+	if !a.repeatMode && a.currentPlaylist == "" {
+		return -1
+	}
+	// Auto-DJ chooses for itself once the song has ended
+	if a.autoDJ {
+		return -1
+	}
+	return a.nextInOrder(a.currentSong, a.repeatMode || a.playlistEndMode() == endRepeat)
+}
+
+// queueNextSong hands the player the song that follows the current one, or
+// clears what it had when nothing does
+func (a *App) queueNextSong() {
+	if a.player == nil {
+		return
+	}
+	a.queuedSong = ""
+	next := a.autoAdvanceIndex()
+	if next < 0 || isLyricsOnly(a.songs[next]) {
+		a.player.ClearNext()
+		return
+	}
+
+	// A remote song that isn't in the song cache yet loads the usual way
+	song := a.songs[next]
+	path := a.playbackPath(song)
+	if remotefs.IsRemote(path) {
+		a.player.ClearNext()
+		return
+	}
+	a.queuedSong = song.Path
+	a.player.QueueNext(path, a.queuedTranspose(song), a.trackGain(song))
+}
+
+// queuedTranspose returns the pitch shift for a song that isn't loaded yet,
+// as songTranspose does for the current one, reading the [transpose:] tag
+// from the song's own lyrics
+func (a *App) queuedTranspose(song Song) int {
+	if manual := a.overrides.Get(song.Path).Transpose; manual != nil {
+		return *manual
+	}
+	if song.LyricsPath == "" {
+		return 0
+	}
+	track, err := lyrics.LoadFile(song.LyricsPath)
+	if err != nil {
+		return 0
+	}
+	semitones, _ := track.Transpose()
+	return semitones
+}
+
+// startQueuedSong takes over the song the player carried on into, as play
+// would have set it up
+func (a *App) startQueuedSong() {
+	i := a.songIndex(a.queuedSong)
+	if i < 0 {
+		// Taken out of the list while it was queued
+		a.stop()
+		return
+	}
+	if a.currentSong >= 0 && a.currentSong < len(a.songs) {
+		a.resumeStore.Clear(a.songs[a.currentSong].Path)
+	}
+	a.recordPlay()
+
+	a.currentSong = i
+	a.selectedSong = i
+	song := a.songs[i]
+	a.loadSongLyrics(&song)
+	a.resetKaraoke()
+	// The player has already started the song, so long-form audio can
+	// only jump to where it was left off
+	start := a.resumePoint(song)
+	if start == 0 || a.player.SeekTo(start) != nil {
+		start = 0
+	}
+	a.songStarted(song, start)
+	a.position = a.player.GetPosition()
+}
+
+// advanceAfterSong moves on once a song has played to the end without a
+// queued one following it, such as when the next song needs loading
+// another way
+func (a *App) advanceAfterSong() {
+	// Stopping or pausing also ends the playback loop, but not the song
+	if a.isPlaying || a.isPaused || a.position < a.duration {
+		return
+	}
+	next := a.autoAdvanceIndex()
+	if next < 0 {
+		a.playlistEnded()
+		return
+	}
+	a.currentSong = next
+	a.updateSongList()
+	a.playOrCue()
+}
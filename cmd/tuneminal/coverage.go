@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/overrides"
+)
+
+// libraryFiles lists the audio files in the library, leaving out hidden songs
+func libraryFiles() ([]string, error) {
+	files, err := listLibraryFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	store := overrides.NewStore()
+	visible := files[:0]
+	for _, path := range files {
+		if !store.IsHidden(path) {
+			visible = append(visible, path)
+		}
+	}
+	return visible, nil
+}
+
+// libraryCoverage checks the lyric status of every file in the library
+func libraryCoverage() (*lyrics.CoverageReport, error) {
+	files, err := libraryFiles()
+	if err != nil {
+		return nil, err
+	}
+	return lyrics.Coverage(files), nil
+}
+
+// formatCoverage renders a coverage report; colors adds tview color tags
+func formatCoverage(report *lyrics.CoverageReport, colors bool) string {
+	tag := func(color string) string {
+		if colors {
+			return "[" + color + "]"
+		}
+		return ""
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("%sLyrics coverage for %d songs%s\n\n", tag("yellow"), report.Total(), tag("white")))
+	content.WriteString(fmt.Sprintf("%sSynced:   %s%4d (%.0f%%)\n", tag("green"), tag("white"), len(report.Synced), report.Percent(lyrics.Synced)))
+	content.WriteString(fmt.Sprintf("%sUnsynced: %s%4d (%.0f%%)\n", tag("cyan"), tag("white"), len(report.Unsynced), report.Percent(lyrics.Unsynced)))
+	content.WriteString(fmt.Sprintf("%sMissing:  %s%4d (%.0f%%)\n", tag("red"), tag("white"), len(report.Missing), report.Percent(lyrics.Missing)))
+
+	if len(report.Languages) > 0 {
+		content.WriteString(fmt.Sprintf("\n%sLanguages:%s\n", tag("yellow"), tag("white")))
+		for _, language := range sortedLanguages(report.Languages) {
+			name := "Unknown"
+			if language != "" {
+				name = lyrics.LanguageName(language)
+			}
+			content.WriteString(fmt.Sprintf("  %-12s %4d\n", name, report.Languages[language]))
+		}
+	}
+
+	if len(report.Unsynced) > 0 {
+		content.WriteString(fmt.Sprintf("\n%sText only (press Y on the song to time them):%s\n", tag("cyan"), tag("white")))
+		for _, path := range report.Unsynced {
+			content.WriteString("  " + filepath.Base(path) + "\n")
+		}
+	}
+	if len(report.Missing) > 0 {
+		content.WriteString(fmt.Sprintf("\n%sNo lyrics (fetch them with f, or `tuneminal lyrics fetch`):%s\n", tag("red"), tag("white")))
+		for _, path := range report.Missing {
+			content.WriteString("  " + filepath.Base(path) + "\n")
+		}
+	}
+
+	return content.String()
+}
+
+// showLyricsCoverage shows the library lyric coverage report
+func (a *App) showLyricsCoverage() {
+	report, err := libraryCoverage()
+	if err != nil {
+		a.handleError(err, "Lyrics Coverage")
+		return
+	}
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(formatCoverage(report, true))
+	view.SetBorder(true).
+		SetTitle(" Lyrics Coverage - f fetch missing, Esc close ").
+		SetTitleAlign(tview.AlignCenter)
+	view.SetDoneFunc(func(key tcell.Key) {
+		a.pages.RemovePage("lyrics-coverage")
+		a.app.SetFocus(a.songList)
+	})
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune && event.Rune() == 'f' {
+			a.pages.RemovePage("lyrics-coverage")
+			a.fetchMissingLyrics()
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("lyrics-coverage", centered(view, 70, 24), true, true)
+	a.app.SetFocus(view)
+}
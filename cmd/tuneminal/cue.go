@@ -0,0 +1,79 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Line cue modes, set by line_cue in the config
+const (
+	cueOff   = "off"
+	cueFlash = "flash"
+	cueBell  = "bell"
+	cueBoth  = "both"
+)
+
+// cueModes are the line cue modes in the order the settings offer them
+var cueModes = []string{cueOff, cueFlash, cueBell, cueBoth}
+
+// cueFlashLength is how long the lyrics border stays lit for a cue
+const cueFlashLength = 250 * time.Millisecond
+
+// lyricsBorderColor is the lyrics panel's usual border color
+const lyricsBorderColor = tcell.ColorRed
+
+// checkLineCue gives the configured cue shortly before the next sung line
+// starts, so singers know when to come in without watching the screen. It
+// runs on every playback tick.
+func (a *App) checkLineCue() {
+	if !a.cueFlashUntil.IsZero() && time.Now().After(a.cueFlashUntil) {
+		a.cueFlashUntil = time.Time{}
+		a.lyrics.SetBorderColor(a.lyricsBorder())
+	}
+
+	mode := a.appConfig.LineCue
+	if mode == "" || mode == cueOff || a.lyricTrack == nil || a.isPaused {
+		return
+	}
+
+	// Blank lines are breaks, not something to sing
+	next := a.lyricTrack.IndexAt(a.position) + 1
+	for next < len(a.lyricLines) && a.lyricLines[next].Text == "" {
+		next++
+	}
+	if next >= len(a.lyricLines) {
+		return
+	}
+
+	lead := time.Duration(a.appConfig.LineCueLeadMs) * time.Millisecond
+	if a.lyricLines[next].Time-a.position > lead {
+		// Seeking back before a cued line cues it again
+		if next == a.cuedLine {
+			a.cuedLine = -1
+		}
+		return
+	}
+	if next == a.cuedLine {
+		return
+	}
+	a.cuedLine = next
+
+	if mode == cueFlash || mode == cueBoth {
+		a.lyrics.SetBorderColor(tcell.ColorYellow)
+		a.cueFlashUntil = time.Now().Add(cueFlashLength)
+	}
+	if mode == cueBell || mode == cueBoth {
+		a.bellPending = true
+	}
+}
+
+// afterDraw runs after each screen update: it rings a pending cue bell and
+// adds the frame to a running recording
+func (a *App) afterDraw(screen tcell.Screen) {
+	if a.bellPending {
+		a.bellPending = false
+		screen.Beep()
+	}
+	a.captureRecording(screen)
+}
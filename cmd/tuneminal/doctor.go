@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/config"
+	"github.com/tuneminal/tuneminal/pkg/diagnostics"
+	"github.com/tuneminal/tuneminal/pkg/paths"
+	"github.com/tuneminal/tuneminal/pkg/player"
+	"github.com/tuneminal/tuneminal/pkg/songcache"
+)
+
+// audioBackends names the system sound API oto plays through on each OS
+var audioBackends = map[string]string{
+	"linux":   "ALSA",
+	"darwin":  "Core Audio",
+	"windows": "WASAPI",
+	"freebsd": "OSS",
+}
+
+// formatDiagnostics renders everything a bug report needs: the audio backend
+// and devices, the microphone, where the config and data live and recent
+// errors. colors adds tview color tags.
+func formatDiagnostics(cfg *config.Config, audio *player.AudioPlayer, colors bool) string {
+	tag := func(color string) string {
+		if colors {
+			return "[" + color + "]"
+		}
+		return ""
+	}
+	var content strings.Builder
+	section := func(title string) {
+		content.WriteString(fmt.Sprintf("\n%s%s%s\n", tag("yellow"), title, tag("white")))
+	}
+	item := func(label, value string) {
+		content.WriteString(fmt.Sprintf("  %-16s %s\n", label+":", value))
+	}
+	problem := func(label, value string) {
+		item(label, tag("red")+value+tag("white"))
+	}
+
+	content.WriteString(fmt.Sprintf("%sTuneminal diagnostics%s\n", tag("yellow"), tag("white")))
+	item("Version", version)
+	item("System", fmt.Sprintf("%s/%s, %s", runtime.GOOS, runtime.GOARCH, runtime.Version()))
+	item("Terminal", os.Getenv("TERM"))
+
+	section("Audio")
+	backend := audioBackends[runtime.GOOS]
+	if backend == "" {
+		backend = "unknown"
+	}
+	device := "system default device"
+	if output := outputConfig(cfg); output != nil {
+		backend = output.Backend
+		if output.Device != "" {
+			device = output.Device
+		}
+		item("Backend", backend+" (direct)")
+	} else {
+		item("Backend", "oto via "+backend)
+	}
+	ready, err := false, error(nil)
+	if audio != nil {
+		ready, err = audio.OutputStatus()
+	}
+	switch {
+	case audio == nil:
+		problem("Output", "no audio player")
+	case ready:
+		item("Output", "open, "+device)
+	case err != nil:
+		problem("Output", "failed to open: "+err.Error())
+	default:
+		item("Output", "not opened yet (opens with the first song)")
+	}
+	if runtime.GOOS == "linux" {
+		if devices, err := diagnostics.PlaybackDevices(); err != nil {
+			problem("Devices", "can't list them (is aplay installed?)")
+		} else if len(devices) == 0 {
+			problem("Devices", "no playback devices found")
+		} else {
+			for i, device := range devices {
+				label := ""
+				if i == 0 {
+					label = "Devices"
+				}
+				item(label, fmt.Sprintf("%s  %s", device.ID, device.Name))
+			}
+		}
+	}
+	if cfg.MonitorEnabled {
+		item("Monitor", cfg.MonitorDevice)
+	} else {
+		item("Monitor", "off")
+	}
+
+	section("Microphone")
+	if cfg.MonitorMicDevice == "" {
+		item("Mic", "not set (add one under Audio settings to hear it in the monitor)")
+	} else {
+		item("Mic", cfg.MonitorMicDevice)
+	}
+	if runtime.GOOS == "linux" {
+		if devices, err := diagnostics.CaptureDevices(); err != nil {
+			problem("Inputs", "can't list them (is arecord installed?)")
+		} else if len(devices) == 0 {
+			problem("Inputs", "no capture devices found")
+		} else {
+			for i, device := range devices {
+				label := ""
+				if i == 0 {
+					label = "Inputs"
+				}
+				item(label, fmt.Sprintf("%s  %s", device.ID, device.Name))
+			}
+		}
+	}
+
+	section("Files")
+	configPath := config.GetConfigPath()
+	if _, err := os.Stat(configPath); err != nil {
+		item("Config", configPath+" (not saved yet, using defaults)")
+	} else {
+		item("Config", configPath)
+	}
+	library := strings.Join(libraryRoots(), ", ")
+	if currentLibrary != nil {
+		library = currentLibrary.Name + ": " + library
+	}
+	if files, err := libraryFiles(); err != nil {
+		problem("Library", fmt.Sprintf("%s: %v", library, err))
+	} else {
+		item("Library", fmt.Sprintf("%s (%d songs)", library, len(files)))
+	}
+	if portable := paths.Portable(); portable != "" {
+		item("Portable", portable)
+	} else if home := os.Getenv(paths.HomeEnv); home != "" {
+		item("Home", home+" ("+paths.HomeEnv+")")
+	}
+	// In portable mode, with TUNEMINAL_HOME or ~/.tuneminal the directories are all the same
+	listed := make(map[string]bool)
+	for _, dir := range []struct{ label, path string }{
+		{"Config dir", paths.ConfigDir()},
+		{"Data dir", paths.DataDir()},
+		{"Cache dir", paths.CacheDir()},
+	} {
+		if listed[dir.path] {
+			continue
+		}
+		listed[dir.path] = true
+
+		files, err := diagnostics.DataFiles(dir.path)
+		if err != nil {
+			item(dir.label, dir.path+" (empty)")
+			continue
+		}
+		var total int64
+		for _, file := range files {
+			total += file.Size
+		}
+		item(dir.label, fmt.Sprintf("%s (%s)", dir.path, formatBytes(total)))
+		for _, file := range files {
+			item("", fmt.Sprintf("%-22s %s", file.Name, formatBytes(file.Size)))
+		}
+	}
+
+	if cfg.SongCache != "" && cfg.SongCache != songCacheOff {
+		count, size := songcache.New(paths.Cache("songs"), 0).Usage()
+		item("Song cache", fmt.Sprintf("%s, %d songs (%s of %d MB)", cfg.SongCache, count, formatBytes(size), cfg.SongCacheMB))
+	}
+	if cfg.DecodeCacheMB > 0 {
+		count, size := songcache.New(paths.Cache("decoded"), 0).Usage()
+		item("Decode cache", fmt.Sprintf("%d songs (%s of %d MB)", count, formatBytes(size), cfg.DecodeCacheMB))
+	}
+	if cfg.PrefetchMB > 0 {
+		item("Prefetch", fmt.Sprintf("next song, up to %d MB", cfg.PrefetchMB))
+	} else {
+		item("Prefetch", "off")
+	}
+
+	section("Recent errors")
+	recent := diagnostics.NewErrorLog().Recent()
+	if len(recent) == 0 {
+		content.WriteString("  None\n")
+	}
+	for _, logged := range recent {
+		content.WriteString(fmt.Sprintf("  %s  %s: %s\n", logged.Time.Format("2006-01-02 15:04:05"), logged.Context, logged.Message))
+	}
+
+	return content.String()
+}
+
+// formatBytes renders a size in B, KB or MB
+func formatBytes(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+// showDiagnostics shows the diagnostics page, reached from the help screen
+func (a *App) showDiagnostics() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(formatDiagnostics(a.appConfig, a.player, true))
+	view.SetBorder(true).
+		SetTitle(" Diagnostics - include this in bug reports (tuneminal doctor), Esc close ").
+		SetTitleAlign(tview.AlignCenter)
+	view.SetDoneFunc(func(key tcell.Key) {
+		a.pages.RemovePage("diagnostics")
+		a.app.SetFocus(a.songList)
+	})
+
+	a.pages.AddPage("diagnostics", centered(view, 90, 30), true, true)
+	a.app.SetFocus(view)
+}
+
+// runDoctorCommand handles "tuneminal doctor", opening the audio output to
+// check that sound works
+func runDoctorCommand(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: tuneminal doctor")
+	}
+
+	// Loading a missing config would save the defaults, so only load one that exists
+	cfg := config.DefaultConfig()
+	if _, err := os.Stat(config.GetConfigPath()); err == nil {
+		if loaded, err := config.LoadConfig(config.GetConfigPath()); err == nil {
+			cfg = loaded
+		}
+	}
+	audio := player.NewAudioPlayer()
+	audio.SetOutput(outputConfig(cfg))
+	audio.OpenOutput()
+	defer audio.Close()
+
+	fmt.Print(formatDiagnostics(cfg, audio, false))
+	return nil
+}
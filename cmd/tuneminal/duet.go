@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+)
+
+// duetColors tell the two singers' halves apart
+var duetColors = [2]string{"cyan", "fuchsia"}
+
+// showDuetLayout reports whether the lyrics panel is split between singers
+func (a *App) showDuetLayout() bool {
+	return a.duet != nil && a.appConfig.DuetLayout
+}
+
+// toggleDuetLayout switches between the split duet layout and the usual one
+func (a *App) toggleDuetLayout() {
+	a.appConfig.DuetLayout = !a.appConfig.DuetLayout
+	a.saveConfig()
+	a.updateKaraokeLyrics()
+
+	switch {
+	case !a.appConfig.DuetLayout:
+		a.showToast("🎤 Duet layout off")
+	case a.duet == nil:
+		a.showToast("🎤 Duet layout on for songs with P1:/P2: or M:/F: lyrics")
+	default:
+		a.showToast(fmt.Sprintf("🎤 Duet layout: %s | %s", a.duet.Names[0], a.duet.Names[1]))
+	}
+}
+
+// createDuetLyricsDisplay shows each singer's lines on their own half of the
+// lyrics panel. Each half follows its own part, so one singer's upcoming
+// lines stay in view while the other sings.
+func (a *App) createDuetLyricsDisplay() string {
+	_, _, width, _ := a.lyrics.GetInnerRect()
+	half := (width - 3) / 2
+	if half < 10 {
+		half = 30
+	}
+
+	var columns [2][]string
+	for part, track := range a.duet.Parts {
+		active := track.IndexAt(a.position)
+		color := duetColors[part]
+		column := []string{fmt.Sprintf("[%s::b]%s[white::-]", color, a.duet.Names[part]), ""}
+		for offset := -1; offset <= 2; offset++ {
+			column = append(column, formatDuetLine(track, active+offset, offset, color, half, a.appConfig.LyricsBidi), "")
+		}
+		columns[part] = column
+	}
+
+	var display strings.Builder
+	display.WriteString("\n\n")
+	for row := range columns[0] {
+		display.WriteString(padCenter(columns[0][row], half))
+		display.WriteString(" [gray]│[white] ")
+		display.WriteString(padCenter(columns[1][row], half))
+		display.WriteString("\n")
+	}
+	return display.String()
+}
+
+// formatDuetLine formats one line of a singer's half: offset is -1 for the
+// line just sung, 0 for the current one and above that for upcoming ones.
+// With bidi, right-to-left lines are put in the order they are drawn.
+func formatDuetLine(track *lyrics.Lyrics, index, offset int, color string, width int, bidi bool) string {
+	if index < 0 || index >= len(track.Lines) || track.Lines[index].Text == "" {
+		if offset == 0 {
+			return "[gray]♪ ∙∙∙[white::-]"
+		}
+		return "[gray]∙∙∙[white::-]"
+	}
+
+	text := truncateRunes(track.Lines[index].Text, width)
+	if bidi {
+		text = lyrics.Visual(text)
+	}
+	text = tview.Escape(text)
+	switch offset {
+	case -1:
+		return fmt.Sprintf("[blue::d]%s[white::-]", text)
+	case 0:
+		return fmt.Sprintf("[yellow::b]%s[white::-]", strings.ToUpper(text))
+	case 1:
+		return fmt.Sprintf("[%s]%s[white]", color, text)
+	default:
+		return fmt.Sprintf("[gray]%s[white]", text)
+	}
+}
+
+// truncateRunes shortens text to at most width characters
+func truncateRunes(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// padCenter pads tagged text with spaces to center it in width columns
+func padCenter(text string, width int) string {
+	gap := width - tview.TaggedStringWidth(text)
+	if gap <= 0 {
+		return text
+	}
+	return strings.Repeat(" ", gap/2) + text + strings.Repeat(" ", gap-gap/2)
+}
+
+// duetScore is one singer's own score, streak and hits in a duet
+type duetScore struct {
+	score  int
+	streak int
+	hits   int
+}
+
+// lineSingers returns which singers' parts lyric line index belongs to: 0,
+// 1 or both, or none when the song isn't a duet
+func (a *App) lineSingers(index int) []int {
+	if a.duet == nil || index < 0 || index >= len(a.duet.Roles) {
+		return nil
+	}
+	switch a.duet.Roles[index] {
+	case lyrics.FirstRole:
+		return []int{0}
+	case lyrics.SecondRole:
+		return []int{1}
+	}
+	return []int{0, 1}
+}
+
+// singerColor returns the color of the singer of lyric line index, or ""
+// for lines sung together and songs that aren't duets
+func (a *App) singerColor(index int) string {
+	if singers := a.lineSingers(index); len(singers) == 1 {
+		return duetColors[singers[0]]
+	}
+	return ""
+}
+
+// scoreDuetHit credits a hit line to the singers whose part it is, each
+// scored on their own streak as the main score is
+func (a *App) scoreDuetHit(index, beatBonus int) {
+	for _, singer := range a.lineSingers(index) {
+		track := &a.duetScores[singer]
+		track.score += int(100*(1+float64(track.streak)/10)) + beatBonus
+		track.streak++
+		track.hits++
+	}
+}
+
+// resetDuetScores clears both singers' scores
+func (a *App) resetDuetScores() {
+	a.duetScores = [2]duetScore{}
+}
+
+// duetAccuracy returns the share of a singer's lines they have hit, in percent
+func (a *App) duetAccuracy(singer int) float64 {
+	lines := 0
+	for i, line := range a.lyricLines {
+		if line.Text != "" && slices.Contains(a.lineSingers(i), singer) {
+			lines++
+		}
+	}
+	if lines == 0 {
+		return 0
+	}
+	return float64(a.duetScores[singer].hits) / float64(lines) * 100
+}
+
+// createDuetScoreDisplay shows each singer's score, streak and accuracy side
+// by side, or "" when the song isn't a duet
+func (a *App) createDuetScoreDisplay() string {
+	if a.duet == nil {
+		return ""
+	}
+	_, _, width, _ := a.score.GetInnerRect()
+	half := max((width-3)/2, 10)
+
+	var columns [2][]string
+	for singer, track := range a.duetScores {
+		color := duetColors[singer]
+		columns[singer] = []string{
+			fmt.Sprintf("[%s::b]%s[white::-]", color, tview.Escape(truncateRunes(a.duet.Names[singer], half))),
+			fmt.Sprintf("[%s]%d[white]", color, track.score),
+			fmt.Sprintf("Streak %d", track.streak),
+			fmt.Sprintf("%.1f%%", a.duetAccuracy(singer)),
+		}
+	}
+
+	var display strings.Builder
+	for row := range columns[0] {
+		display.WriteString(padCenter(columns[0][row], half))
+		display.WriteString("[gray]│[white]")
+		display.WriteString(padCenter(columns[1][row], half))
+		display.WriteString("\n")
+	}
+	return display.String()
+}
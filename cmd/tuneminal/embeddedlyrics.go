@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+)
+
+// hasTimedLyrics reports whether text is lyrics with timestamps, as from a
+// SYLT frame or an LRC pasted into the unsynchronised lyrics tag
+func hasTimedLyrics(text string) bool {
+	track, err := lyrics.Parse(strings.NewReader(text))
+	return err == nil && len(track.Lines) > 0
+}
+
+// exportEmbeddedLyrics writes the timed lyrics in a song's tags to an .lrc
+// next to it, where other players and the lyrics editor can use them
+func (a *App) exportEmbeddedLyrics(song Song) {
+	if a.denyReadOnly("Exporting lyrics") {
+		return
+	}
+	text := a.embeddedLyrics(song.Path)
+	if !hasTimedLyrics(text) {
+		a.showWarning("This song has no timed lyrics in its tags")
+		return
+	}
+	lyricsPath := a.writableLyricsPath(song, sidecarLyricsPath(song.Path))
+	if _, err := os.Stat(lyricsPath); err == nil {
+		a.showWarning(filepath.Base(lyricsPath) + " already exists")
+		return
+	}
+
+	err := a.editFile("embedded lyrics for "+song.Title, lyricsPath, func() error {
+		return os.WriteFile(lyricsPath, []byte(text), 0644)
+	})
+	if err != nil {
+		a.handleError(err, "Export Lyrics")
+		return
+	}
+	for i := range a.songs {
+		if a.songs[i].Path == song.Path {
+			a.songs[i].LyricsPath = lyricsPath
+		}
+	}
+	a.showToast("[green]✓ Saved " + filepath.Base(lyricsPath) + "[white]")
+}
+
+// timeEmbeddedLyrics starts tap-to-sync with the untimed lyrics in a song's
+// tags, so they can be sung along to
+func (a *App) timeEmbeddedLyrics(song Song) {
+	if a.denyReadOnly("Importing lyrics") {
+		return
+	}
+	a.startTapSync(song, lyrics.PlainLines(a.embeddedLyrics(song.Path)))
+}
+
+// exportEmbeddedFiles writes the timed lyrics embedded in each audio file to
+// an .lrc next to it, keeping lyrics files already there unless force is
+// set, and reports what it did to w
+func exportEmbeddedFiles(files []string, force bool, w io.Writer) (exported int) {
+	for _, path := range files {
+		meta, err := metadata.GetRealMetadata(path)
+		if err != nil {
+			fmt.Fprintf(w, "%s: %v\n", path, err)
+			continue
+		}
+		text := meta.SyncedLyrics
+		if text == "" {
+			text = meta.Lyrics
+		}
+		if !hasTimedLyrics(text) {
+			continue
+		}
+		lyricsPath := sidecarLyricsPath(path)
+		if _, err := os.Stat(lyricsPath); err == nil && !force {
+			fmt.Fprintf(w, "%s: kept the existing %s\n", path, filepath.Base(lyricsPath))
+			continue
+		}
+		if err := os.WriteFile(lyricsPath, []byte(text), 0644); err != nil {
+			fmt.Fprintf(w, "%s: %v\n", path, err)
+			continue
+		}
+		fmt.Fprintf(w, "%s: wrote %s\n", path, filepath.Base(lyricsPath))
+		exported++
+	}
+	return exported
+}
+
+// When to write saved lyrics into the song's tags, as set by embed_lyrics
+const (
+	embedLyricsAsk    = "ask"
+	embedLyricsAlways = "always"
+	embedLyricsNever  = "never"
+)
+
+// offerEmbedLyrics follows saving lyrics for song by writing them into its
+// tags as well, so other players show them too, asking first unless told to
+// always or never do. saved, when not empty, says what was just saved.
+func (a *App) offerEmbedLyrics(song Song, lyricsPath, saved string) {
+	mode := a.appConfig.EmbedLyrics
+	if a.readOnly || mode == embedLyricsNever || !metadata.CanEmbedLyrics(song.Path) || !a.canWrite(filepath.Dir(song.Path)) {
+		if saved != "" {
+			a.showMessage(saved)
+		}
+		return
+	}
+	if mode == embedLyricsAlways {
+		a.embedLyrics(song, lyricsPath)
+		if saved != "" {
+			a.showMessage(saved)
+		}
+		return
+	}
+
+	text := "Save the lyrics into " + filepath.Base(song.Path) + "'s tags too, so other players show them?"
+	if saved != "" {
+		text = saved + "\n\n" + text
+	}
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Embed", "Always embed", "Not now"}).
+		SetDoneFunc(func(_ int, label string) {
+			a.pages.RemovePage("embed-lyrics")
+			a.app.SetFocus(a.songList)
+			switch label {
+			case "Always embed":
+				a.appConfig.EmbedLyrics = embedLyricsAlways
+				a.saveConfig()
+				a.embedLyrics(song, lyricsPath)
+			case "Embed":
+				a.embedLyrics(song, lyricsPath)
+			}
+		})
+	a.pages.AddPage("embed-lyrics", modal, true, true)
+	a.app.SetFocus(modal)
+}
+
+// embedLyrics writes the lyrics file at lyricsPath into song's tags, timed
+func (a *App) embedLyrics(song Song, lyricsPath string) {
+	track, err := lyrics.LoadFile(lyricsPath)
+	if err != nil {
+		a.handleError(err, "Embed Lyrics")
+		return
+	}
+	lines := make([]metadata.TimedLine, len(track.Lines))
+	for i, line := range track.Lines {
+		lines[i] = metadata.TimedLine{Time: line.Time, Text: line.Text}
+	}
+	if err := metadata.EmbedLyrics(song.Path, lines, true); err != nil {
+		a.handleError(err, "Embed Lyrics")
+		return
+	}
+
+	// Read the new tags into the index now rather than at the next scan;
+	// reading an MP3 means decoding it, so not on the UI's time
+	go a.library.Read(song.Path)
+	a.showToast("[green]✓ Lyrics saved into " + filepath.Base(song.Path) + "'s tags[white]")
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/player"
+)
+
+// eqBandLabels name the equalizer bands in the panel
+var eqBandLabels = [len(player.EQBands)]string{"31", "62", "125", "250", "500", "1k", "2k", "4k", "8k", "16k"}
+
+// eqGains returns the equalizer gains from the config, kept within range;
+// missing bands are flat
+func (a *App) eqGains() player.EQGains {
+	var gains player.EQGains
+	copy(gains[:], a.appConfig.Equalizer)
+	for band, gain := range gains {
+		gains[band] = max(-player.MaxEQGain, min(player.MaxEQGain, gain))
+	}
+	return gains
+}
+
+// applyEqualizer sets the player's equalizer from the config
+func (a *App) applyEqualizer() {
+	if a.player == nil {
+		return
+	}
+	gains := a.eqGains()
+	a.player.SetEqualizer(&gains)
+}
+
+// eqPresetName returns the name of the preset gains match, or "custom"
+func eqPresetName(gains player.EQGains) string {
+	for _, name := range player.EQPresetNames {
+		if player.EQPresets[name] == gains {
+			return name
+		}
+	}
+	return "custom"
+}
+
+// formatEqualizer draws each band as a bar either side of 0 dB, the
+// selected one highlighted
+func formatEqualizer(gains player.EQGains, selected int) string {
+	var content strings.Builder
+	fmt.Fprintf(&content, "[white]Preset: [cyan]%s[white]\n\n", eqPresetName(gains))
+	for band, gain := range gains {
+		steps := int(math.Round(gain))
+		bar := []rune(strings.Repeat(" ", 2*player.MaxEQGain+1))
+		bar[player.MaxEQGain] = '│'
+		for i := 1; i <= steps; i++ {
+			bar[player.MaxEQGain+i] = '█'
+		}
+		for i := 1; i <= -steps; i++ {
+			bar[player.MaxEQGain-i] = '█'
+		}
+		marker, color := "  ", "white"
+		if band == selected {
+			marker, color = "▶ ", "yellow"
+		}
+		fmt.Fprintf(&content, "[%s]%s%4s Hz [cyan]%s[%s] %+3d dB\n", color, marker, eqBandLabels[band], string(bar), color, steps)
+	}
+	content.WriteString("\n[gray]←/→ band  ↑/↓ ±1 dB  0 reset band  p next preset  Enter/Esc done[white]")
+	return content.String()
+}
+
+// showEqualizer shows the equalizer panel. Changes are heard straight away
+// and saved when the panel closes.
+func (a *App) showEqualizer() {
+	gains := a.eqGains()
+	selected := 0
+
+	view := tview.NewTextView().SetDynamicColors(true)
+	view.SetBorder(true).
+		SetTitle(" Equalizer ").
+		SetTitleAlign(tview.AlignCenter)
+
+	update := func() {
+		view.SetText(formatEqualizer(gains, selected))
+		if a.player != nil {
+			current := gains
+			a.player.SetEqualizer(&current)
+		}
+	}
+	closePanel := func() {
+		a.pages.RemovePage("equalizer")
+		a.app.SetFocus(a.songList)
+		if gains == (player.EQGains{}) {
+			a.appConfig.Equalizer = nil
+		} else {
+			a.appConfig.Equalizer = append([]float64(nil), gains[:]...)
+		}
+		a.saveConfig()
+	}
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter, tcell.KeyEscape:
+			closePanel()
+			return nil
+		case tcell.KeyLeft:
+			selected = (selected + len(gains) - 1) % len(gains)
+		case tcell.KeyRight:
+			selected = (selected + 1) % len(gains)
+		case tcell.KeyUp:
+			gains[selected] = min(player.MaxEQGain, math.Round(gains[selected])+1)
+		case tcell.KeyDown:
+			gains[selected] = max(-player.MaxEQGain, math.Round(gains[selected])-1)
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case '0':
+				gains[selected] = 0
+			case 'p':
+				// Step on from the preset in use, or start at the first
+				next := 0
+				for i, name := range player.EQPresetNames {
+					if name == eqPresetName(gains) {
+						next = (i + 1) % len(player.EQPresetNames)
+					}
+				}
+				gains = player.EQPresets[player.EQPresetNames[next]]
+			default:
+				return nil
+			}
+		default:
+			return nil
+		}
+		update()
+		return nil
+	})
+	update()
+
+	a.pages.AddPage("equalizer", centered(view, 56, len(gains)+6), true, true)
+	a.app.SetFocus(view)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/config"
+	"github.com/tuneminal/tuneminal/pkg/export"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// autoExportFormats are the choices for exporting performances on quit
+var autoExportFormats = []string{"off", "json", "csv"}
+
+// newExportManager creates an export manager using the export folder and
+// file naming from the config
+func newExportManager(cfg *config.Config) *export.ExportManager {
+	manager := export.NewExportManager()
+	manager.SetDirectory(utils.ExpandHome(cfg.ExportDirectory))
+	manager.SetNameTemplate(cfg.ExportNameTemplate)
+	return manager
+}
+
+// savedConfig returns the config file's settings, or the defaults when there
+// is none, without writing one the way loading the app does
+func savedConfig() *config.Config {
+	if _, err := os.Stat(config.GetConfigPath()); err == nil {
+		if loaded, err := config.LoadConfig(config.GetConfigPath()); err == nil {
+			return loaded
+		}
+	}
+	return config.DefaultConfig()
+}
+
+// autoExportPerformances exports the songs sung this session when the
+// config asks for it. It runs on quit, so a failure goes to the error log.
+func (a *App) autoExportPerformances() {
+	format := a.appConfig.AutoExport
+	if format == "" || format == "off" || len(a.performances) == 0 {
+		return
+	}
+	if err := a.exportManager.ExportPerformanceData(a.performances, format); err != nil {
+		a.errorLog.Add("Auto Export", err)
+	}
+}
+
+// showExportOptions lets the export folder, file naming and automatic
+// export be changed and saved to the config
+func (a *App) showExportOptions() {
+	directory := tview.NewInputField().
+		SetLabel("Folder (empty for default)").
+		SetText(a.appConfig.ExportDirectory).
+		SetFieldWidth(40)
+	template := tview.NewInputField().
+		SetLabel("File name").
+		SetText(a.appConfig.ExportNameTemplate).
+		SetFieldWidth(40)
+	autoFormat := 0
+	for i, format := range autoExportFormats {
+		if format == a.appConfig.AutoExport {
+			autoFormat = i
+		}
+	}
+	autoExport := tview.NewDropDown().
+		SetLabel("Export performances on quit").
+		SetOptions(autoExportFormats, nil).
+		SetCurrentOption(autoFormat)
+
+	closeForm := func() {
+		a.pages.RemovePage("export-options")
+		a.app.SetFocus(a.songList)
+	}
+
+	form := tview.NewForm().
+		AddFormItem(directory).
+		AddFormItem(template).
+		AddFormItem(autoExport).
+		AddButton("Save", func() {
+			name := strings.TrimSpace(template.GetText())
+			if name != "" && !strings.Contains(name, "{date}") && !strings.Contains(name, "{time}") {
+				a.showWarning("Include {date} or {time} in the file name so exports don't overwrite each other")
+				return
+			}
+			a.appConfig.ExportDirectory = strings.TrimSpace(directory.GetText())
+			a.appConfig.ExportNameTemplate = name
+			_, a.appConfig.AutoExport = autoExport.GetCurrentOption()
+			a.exportManager = newExportManager(a.appConfig)
+			a.saveConfig()
+			closeForm()
+			a.showToast("[green]✓ Exports go to " + a.exportManager.GetExportPath() + "[white]")
+		}).
+		AddButton("Cancel", closeForm)
+	form.SetCancelFunc(closeForm)
+
+	form.SetTitle(" Export Options - {type} {date} {time} {song} ").SetBorder(true)
+	a.pages.AddPage("export-options", centered(form, 72, 11), true, true)
+	a.app.SetFocus(form)
+}
+
+// showExportSaveAs exports one dataset to a path the user types, with the
+// format taken from its extension
+func (a *App) showExportSaveAs() {
+	kinds := []string{"Performance", "Library", "Full Stats"}
+	kind := tview.NewDropDown().
+		SetLabel("Export").
+		SetOptions(kinds, nil).
+		SetCurrentOption(0)
+	path := tview.NewInputField().
+		SetLabel("Save as (.csv or .json)").
+		SetText(filepath.Join(a.exportManager.GetExportPath(), "export.csv")).
+		SetFieldWidth(44)
+
+	closeForm := func() {
+		a.pages.RemovePage("export-save-as")
+		a.app.SetFocus(a.songList)
+	}
+
+	form := tview.NewForm().
+		AddFormItem(kind).
+		AddFormItem(path).
+		AddButton("Export", func() {
+			target := utils.ExpandHome(strings.TrimSpace(path.GetText()))
+			format := strings.TrimPrefix(strings.ToLower(filepath.Ext(target)), ".")
+			if format != "csv" && format != "json" {
+				a.showWarning("The file name must end in .csv or .json")
+				return
+			}
+
+			manager := a.exportManager.SaveAs(target)
+			var err error
+			switch _, chosen := kind.GetCurrentOption(); chosen {
+			case "Performance":
+				err = a.exportPerformanceData(manager, format)
+			case "Library":
+				err = a.exportLibraryData(manager, format)
+			case "Full Stats":
+				err = exportFullStats(manager, "", "", format)
+			}
+			if err != nil {
+				a.handleError(err, "Export")
+				return
+			}
+			closeForm()
+			a.showMessage(fmt.Sprintf("✅ Exported to %s", target))
+		}).
+		AddButton("Cancel", closeForm)
+	form.SetCancelFunc(closeForm)
+
+	form.SetTitle(" Export - Save As ").SetBorder(true)
+	a.pages.AddPage("export-save-as", centered(form, 76, 9), true, true)
+	a.app.SetFocus(form)
+}
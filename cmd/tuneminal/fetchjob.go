@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/jobs"
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+	"github.com/tuneminal/tuneminal/pkg/paths"
+)
+
+// songInfoFor reads what lyric providers need to know about an audio file
+func songInfoFor(path string) lyrics.SongInfo {
+	info := lyrics.SongInfo{Path: path}
+	if meta, err := metadata.GetRealMetadata(path); err == nil {
+		info.Title = meta.Title
+		info.Artist = meta.Artist
+		info.Album = meta.Album
+		info.Duration = meta.Duration
+	} else {
+		info.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return info
+}
+
+// formatFetchSummary describes the outcome of a batch fetch
+func formatFetchSummary(results []lyrics.BatchResult, colors bool) string {
+	var saved, missing, failed []string
+	for _, result := range results {
+		name := filepath.Base(result.Song.Path)
+		switch {
+		case result.Err == nil:
+			saved = append(saved, fmt.Sprintf("%s → %s", name, filepath.Base(result.Path)))
+		case errors.Is(result.Err, lyrics.ErrNotFound):
+			missing = append(missing, name)
+		default:
+			failed = append(failed, fmt.Sprintf("%s: %v", name, result.Err))
+		}
+	}
+
+	section := func(content *strings.Builder, color, heading string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		if colors {
+			content.WriteString(fmt.Sprintf("\n[%s]%s (%d):[white]\n", color, heading, len(lines)))
+		} else {
+			content.WriteString(fmt.Sprintf("\n%s (%d):\n", heading, len(lines)))
+		}
+		for _, line := range lines {
+			content.WriteString("  " + line + "\n")
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Fetched lyrics for %d of %d songs\n", len(saved), len(results)))
+	section(&content, "green", "Saved", saved)
+	section(&content, "yellow", "Not found", missing)
+	section(&content, "red", "Failed", failed)
+	return content.String()
+}
+
+// fetchMissingLyrics starts a background job fetching lyrics for every song without them
+func (a *App) fetchMissingLyrics() {
+	if a.denyReadOnly("Fetching lyrics") {
+		return
+	}
+	if a.fetchJob != nil {
+		a.showFetchProgress()
+		return
+	}
+
+	report, err := libraryCoverage()
+	if err != nil {
+		a.handleError(err, "Fetch Lyrics")
+		return
+	}
+	// Songs with lyrics from another source than a sidecar have them already
+	report.Missing = slices.DeleteFunc(report.Missing, func(path string) bool {
+		i := a.songIndex(path)
+		return i >= 0 && a.songs[i].LyricsPath != ""
+	})
+	if len(report.Missing) == 0 {
+		a.showMessage("🎤 Every song already has lyrics")
+		return
+	}
+
+	a.fetchProgress = lyrics.BatchProgress{Total: len(report.Missing)}
+	a.fetchSummary = ""
+
+	fetcher := lyrics.NewBatchFetcher(lyrics.NewLRCLibProvider())
+	fetcher.CacheDir = paths.Cache("lyrics")
+
+	a.fetchJob = a.jobs.Start("Fetching lyrics", func(ctx context.Context, job *jobs.Job) {
+		fetcher.OnProgress = func(progress lyrics.BatchProgress) {
+			job.SetProgress(progress.Done, progress.Total, progress.Current)
+			a.app.QueueUpdateDraw(func() {
+				a.fetchProgress = progress
+				a.renderFetchProgress()
+			})
+		}
+
+		songs := make([]lyrics.SongInfo, 0, len(report.Missing))
+		for _, path := range report.Missing {
+			songs = append(songs, songInfoFor(path))
+		}
+		results := fetcher.Run(ctx, songs)
+
+		a.app.QueueUpdateDraw(func() {
+			a.fetchJob = nil
+			a.fetchSummary = formatFetchSummary(results, true)
+			if ctx.Err() != nil {
+				a.fetchSummary = "[yellow]Cancelled[white]\n" + a.fetchSummary
+			}
+
+			// Pick up the new lyrics files
+			for i := range a.songs {
+				if a.songs[i].LyricsPath == "" {
+					a.songs[i].LyricsPath = a.findLyricsFile(a.songs[i].Path, a.songs[i].Title, a.songs[i].Artist)
+				}
+			}
+			a.updateStatus()
+			a.showFetchProgress()
+		})
+	})
+
+	a.showFetchProgress()
+}
+
+// showFetchProgress shows the batch fetch panel. While the job runs Esc
+// cancels it and Enter hides the panel, leaving it running.
+func (a *App) showFetchProgress() {
+	a.pages.RemovePage("lyrics-fetch")
+
+	a.fetchView = tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	a.fetchView.SetBorder(true).SetTitleAlign(tview.AlignCenter)
+	a.fetchView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			if a.fetchJob != nil {
+				a.fetchJob.Cancel()
+				a.renderFetchProgress()
+				return nil
+			}
+			fallthrough
+		case tcell.KeyEnter:
+			a.pages.RemovePage("lyrics-fetch")
+			a.fetchView = nil
+			a.app.SetFocus(a.songList)
+			return nil
+		}
+		return event
+	})
+	a.renderFetchProgress()
+
+	a.pages.AddPage("lyrics-fetch", centered(a.fetchView, 70, 22), true, true)
+	a.app.SetFocus(a.fetchView)
+}
+
+// renderFetchProgress refreshes the fetch panel
+func (a *App) renderFetchProgress() {
+	progress := a.fetchProgress
+	if a.fetchView == nil {
+		return
+	}
+
+	if a.fetchSummary != "" {
+		a.fetchView.SetTitle(" Fetched Lyrics - Esc to close ")
+		a.fetchView.SetText(a.fetchSummary)
+		return
+	}
+	if a.fetchJob != nil && a.fetchJob.Cancelled() {
+		a.fetchView.SetTitle(" Fetching Lyrics - cancelling... ")
+	} else {
+		a.fetchView.SetTitle(" Fetching Lyrics - Esc cancel, Enter hide ")
+	}
+
+	width := 40
+	filled := 0
+	if progress.Total > 0 {
+		filled = progress.Done * width / progress.Total
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("[green]%s[gray]%s[white] %d/%d\n\n",
+		strings.Repeat("█", filled), strings.Repeat("░", width-filled), progress.Done, progress.Total))
+	if progress.Current != "" {
+		content.WriteString(fmt.Sprintf("Now: [yellow]%s[white]\n\n", progress.Current))
+	}
+	content.WriteString(fmt.Sprintf("[green]Saved: %d[white]   [yellow]Not found: %d[white]   [red]Failed: %d[white]\n",
+		progress.Succeeded, progress.NotFound, progress.Failed))
+	content.WriteString("\n[dim]Requests are spaced out and retried to respect the lyrics service.[white]")
+	a.fetchView.SetText(content.String())
+}
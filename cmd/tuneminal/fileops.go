@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/organize"
+)
+
+// fileProgressInterval is how often the progress dialog is redrawn
+const fileProgressInterval = 100 * time.Millisecond
+
+// moveSongInBackground moves a song's files from where song has them to where
+// target has them, without holding up the UI. Renames finish at once; moves
+// to another drive copy the audio, showing a progress dialog that Esc
+// cancels. success is shown when the move is done.
+func (a *App) moveSongInBackground(song, target Song, description, success string) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	view := tview.NewTextView().SetDynamicColors(true)
+	view.SetBorder(true).SetTitle(" Moving " + filepath.Base(song.Path) + " ")
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			cancel()
+			view.SetText("[yellow]Cancelling...[white]")
+			return nil
+		}
+		return event
+	})
+
+	// The dialog only appears once a copy is under way
+	var lastDraw time.Time
+	progress := func(done, total int64) {
+		if time.Since(lastDraw) < fileProgressInterval && done < total {
+			return
+		}
+		lastDraw = time.Now()
+		a.app.QueueUpdateDraw(func() {
+			if !a.pages.HasPage("file-progress") {
+				a.pages.AddPage("file-progress", centered(view, 64, 7), true, true)
+				a.app.SetFocus(view)
+			}
+			view.SetText(formatFileProgress(done, total))
+		})
+	}
+
+	go func() {
+		defer cancel()
+		err := organize.MoveFileProgress(ctx, song.Path, target.Path, progress)
+
+		// The lyric sidecar is moved and verified the same way; if it can't
+		// follow, it stays where it was and the user is told
+		var lyricsErr error
+		if err == nil && song.LyricsPath != "" && target.LyricsPath != song.LyricsPath {
+			if lyricsErr = organize.MoveFile(song.LyricsPath, target.LyricsPath); lyricsErr != nil {
+				target.LyricsPath = song.LyricsPath
+			}
+		}
+
+		a.app.QueueUpdateDraw(func() {
+			if a.pages.HasPage("file-progress") {
+				a.pages.RemovePage("file-progress")
+				a.app.SetFocus(a.songList)
+			}
+			switch {
+			case errors.Is(err, context.Canceled):
+				a.showToast("[yellow]Move cancelled, " + filepath.Base(song.Path) + " is where it was[white]")
+			case err != nil:
+				a.handleError(fmt.Errorf("failed to move file: %w", err), "Move File")
+			default:
+				for i := range a.songs {
+					if a.songs[i].Path == song.Path {
+						a.songs[i].Path = target.Path
+						a.songs[i].LyricsPath = target.LyricsPath
+					}
+				}
+				a.added.Rename(song.Path, target.Path)
+				a.recordMove(description, song, target)
+				a.updateAllDisplays()
+				if lyricsErr != nil {
+					a.showWarning(fmt.Sprintf("Moved the song, but its lyrics stayed at %s: %v", song.LyricsPath, lyricsErr))
+					return
+				}
+				a.showMessage(success)
+			}
+		})
+	}()
+}
+
+// formatFileProgress draws a progress bar for a copy
+func formatFileProgress(done, total int64) string {
+	const width = 40
+	filled := width
+	percent := 100
+	if total > 0 {
+		filled = int(done * width / total)
+		percent = int(done * 100 / total)
+	}
+	return fmt.Sprintf("\n [green]%s[white]%s %3d%%\n\n %s of %s, [yellow]Esc[white] to cancel",
+		strings.Repeat("█", filled), strings.Repeat("░", width-filled), percent,
+		formatBytes(done), formatBytes(total))
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// focusBorderColor is the border color of the panel that has focus
+const focusBorderColor = tcell.ColorWhite
+
+// highlightFocus brightens a panel's border while it has focus, and puts its
+// own color back when focus moves on
+func highlightFocus(box *tview.Box, color tcell.Color) {
+	box.SetFocusFunc(func() {
+		box.SetBorderColor(focusBorderColor)
+	})
+	box.SetBlurFunc(func() {
+		box.SetBorderColor(color)
+	})
+}
+
+// focusPanels are the panels Tab moves between, in order: the library, the
+// lyrics and the search box when it is shown
+func (a *App) focusPanels() []tview.Primitive {
+	panels := []tview.Primitive{a.songList, a.lyrics}
+	if a.panelVisible(panelSearch) {
+		panels = append(panels, a.searchInput)
+	}
+	return panels
+}
+
+// cycleFocus moves focus step panels along, wrapping around. From anywhere
+// else it starts at the library.
+func (a *App) cycleFocus(step int) {
+	panels := a.focusPanels()
+	current := slices.Index(panels, a.app.GetFocus())
+	if current < 0 {
+		a.app.SetFocus(a.songList)
+		return
+	}
+	a.app.SetFocus(panels[(current+step+len(panels))%len(panels)])
+}
+
+// lyricsBorder returns the lyrics panel's border color when no cue is lit
+func (a *App) lyricsBorder() tcell.Color {
+	if a.lyrics.HasFocus() {
+		return focusBorderColor
+	}
+	return lyricsBorderColor
+}
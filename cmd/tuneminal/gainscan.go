@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/tuneminal/tuneminal/pkg/jobs"
+	"github.com/tuneminal/tuneminal/pkg/loudness"
+	"github.com/tuneminal/tuneminal/pkg/player"
+)
+
+// trackGain returns the loudness normalization factor for a song, 1 when
+// normalization is off or the song hasn't been scanned
+func (a *App) trackGain(song Song) float64 {
+	if !a.appConfig.NormalizeVolume || a.loudness == nil {
+		return 1
+	}
+	result, ok := a.loudness.Get(song.Path)
+	if !ok {
+		return 1
+	}
+	return result.Factor()
+}
+
+// formatGainScan renders the outcome of a loudness scan
+func formatGainScan(result *loudness.ScanResult) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Measured %d file(s), %d already up to date", result.Measured, result.Current))
+	if len(result.Failed) == 0 {
+		content.WriteString("\n")
+		return content.String()
+	}
+
+	content.WriteString(fmt.Sprintf(", %d failed:\n", len(result.Failed)))
+	paths := make([]string, 0, len(result.Failed))
+	for path := range result.Failed {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		content.WriteString(fmt.Sprintf("  %s: %v\n", path, result.Failed[path]))
+	}
+	return content.String()
+}
+
+// scanLoudness measures the loudness of the library files not measured yet
+// in a background job, so normalization has them to work from
+func (a *App) scanLoudness() {
+	if a.loudnessJob != nil {
+		a.showToast("[yellow]Loudness scan already running[white]")
+		return
+	}
+
+	files, err := libraryFiles()
+	if err != nil {
+		a.handleError(err, "Loudness Scan")
+		return
+	}
+
+	a.loudnessJob = a.jobs.Start("Measuring loudness", func(ctx context.Context, job *jobs.Job) {
+		result := loudness.Scan(ctx, files, a.loudness, player.Decode, loudness.ScanOptions{
+			// Leave some of the CPU to the song playing
+			Workers: max(1, runtime.NumCPU()/2),
+			OnProgress: func(done, total int, path string) {
+				job.SetProgress(done, total, filepath.Base(path))
+			},
+		})
+		// What was measured before a cancel is still worth keeping
+		saveErr := a.loudness.Save()
+
+		a.app.QueueUpdateDraw(func() {
+			a.loudnessJob = nil
+			switch {
+			case saveErr != nil:
+				a.handleError(saveErr, "Loudness Scan")
+			case ctx.Err() != nil:
+				a.showToast("[yellow]Loudness scan cancelled[white]")
+			default:
+				a.showMessage(formatGainScan(result))
+			}
+		})
+	})
+}
+
+// runGainScanCommand handles "tuneminal gain-scan"
+func runGainScanCommand(args []string) error {
+	flags := flag.NewFlagSet("gain-scan", flag.ContinueOnError)
+	workers := flags.Int("workers", 0, "files to measure at once (default: one per CPU)")
+	force := flags.Bool("force", false, "measure every file again, even if it hasn't changed")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	files, err := libraryFiles()
+	if err != nil {
+		return err
+	}
+
+	store := loudness.NewStore()
+	result := loudness.Scan(context.Background(), files, store, player.Decode, loudness.ScanOptions{
+		Workers: *workers,
+		Force:   *force,
+		OnProgress: func(done, total int, path string) {
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", done, total, path)
+		},
+	})
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	fmt.Print(formatGainScan(result))
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("%d file(s) could not be measured", len(result.Failed))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Songs are grouped by the artist and album tags. The search box takes
+// artist:"..." and album:"..." filters, which match the tag exactly
+// (ignoring case), so jumping to a group is just a search that can be
+// cleared with Esc.
+
+// groupFilter is an artist or album to narrow the song list to
+type groupFilter struct {
+	artist string
+	album  string
+}
+
+// parseGroupFilter splits artist: and album: filters off the search text.
+// Values with spaces are quoted, e.g. artist:"Daft Punk".
+func parseGroupFilter(text string) (string, groupFilter) {
+	var filter groupFilter
+	var rest []string
+	for text = strings.TrimSpace(text); text != ""; text = strings.TrimSpace(text) {
+		var field *string
+		lower := strings.ToLower(text)
+		switch {
+		case strings.HasPrefix(lower, "artist:"):
+			field, text = &filter.artist, text[len("artist:"):]
+		case strings.HasPrefix(lower, "album:"):
+			field, text = &filter.album, text[len("album:"):]
+		}
+
+		var value string
+		if field != nil && strings.HasPrefix(text, `"`) {
+			value, text, _ = strings.Cut(text[1:], `"`)
+		} else {
+			end := strings.IndexAny(text, " \t")
+			if end < 0 {
+				end = len(text)
+			}
+			value, text = text[:end], text[end:]
+		}
+
+		if field != nil {
+			*field = value
+		} else {
+			rest = append(rest, value)
+		}
+	}
+	return strings.Join(rest, " "), filter
+}
+
+// empty reports whether the filter lets every song through
+func (f groupFilter) empty() bool {
+	return f.artist == "" && f.album == ""
+}
+
+// matches reports whether a song is in the filtered group
+func (f groupFilter) matches(song Song) bool {
+	if f.artist != "" && !strings.EqualFold(song.Artist, f.artist) {
+		return false
+	}
+	if f.album != "" && !strings.EqualFold(song.Album, f.album) {
+		return false
+	}
+	return true
+}
+
+// jumpToArtist narrows the song list to everything by the song's artist
+func (a *App) jumpToArtist(song Song) {
+	if song.Artist == "" {
+		a.showWarning("This song has no artist tag")
+		return
+	}
+	a.jumpToGroup(groupFilter{artist: song.Artist}, "by "+song.Artist)
+}
+
+// jumpToAlbum narrows the song list to the song's album
+func (a *App) jumpToAlbum(song Song) {
+	if song.Album == "" {
+		a.showWarning("This song has no album tag")
+		return
+	}
+	a.jumpToGroup(groupFilter{artist: song.Artist, album: song.Album}, "from "+song.Album)
+}
+
+// jumpToGroup searches for the group and moves to the song list so Enter
+// plays from it
+func (a *App) jumpToGroup(filter groupFilter, description string) {
+	var search []string
+	if filter.artist != "" {
+		search = append(search, `artist:"`+filter.artist+`"`)
+	}
+	if filter.album != "" {
+		search = append(search, `album:"`+filter.album+`"`)
+	}
+
+	count := 0
+	for _, song := range a.songs {
+		if filter.matches(song) {
+			count++
+		}
+	}
+
+	a.searchInput.SetText(strings.Join(search, " "))
+	a.app.SetFocus(a.songList)
+	a.showToast(fmt.Sprintf("[cyan]%d songs %s (/ then Esc shows all)[white]", count, description))
+}
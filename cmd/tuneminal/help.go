@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// helpEntry is one line of the help: the keys, named as keyLabel names
+// them, and what they do. readOnlyAction replaces action in read-only mode
+// when part of what it describes is turned off.
+type helpEntry struct {
+	keys           string
+	action         string
+	readOnlyAction string
+}
+
+// helpSection is a heading in the help and the entries under it
+type helpSection struct {
+	title   string
+	entries []helpEntry
+}
+
+// helpSections are the key bindings of the main screen, as the help lists them
+var helpSections = []helpSection{
+	{"BASIC CONTROLS", []helpEntry{
+		{keys: "Space", action: "Play/Pause current song"},
+		{keys: "s", action: "Stop playback and reset position"},
+		{keys: "n / p", action: "Next/previous song"},
+		{keys: "↑/↓", action: "Navigate songs (scroll when in lyrics)"},
+		{keys: "Enter", action: "Play the selected song"},
+		{keys: "Tab/Shift+Tab", action: "Cycle library, lyrics, search"},
+		{keys: "/", action: "Focus on search box (lang:xx filters)"},
+		{keys: "ESC", action: "Clear search and return to song list"},
+	}},
+	{"AUDIO CONTROLS", []helpEntry{
+		{keys: "+/-", action: "Increase/Decrease volume"},
+		{keys: "Shift+R", action: "Repeat: off, all or one"},
+		{keys: "Shift+S", action: "Toggle shuffle mode"},
+		{keys: "←/→", action: "Seek backward/forward"},
+		{keys: "a", action: "Audio settings (monitor, metronome, line cue)"},
+		{keys: "Shift+E", action: "Mic: play it through the speakers, automatic gain, echo and reverb"},
+		{keys: "Shift+M", action: "Toggle metronome click"},
+		{keys: "t", action: "Tap the tempo along with the song"},
+		{keys: "[ / ]", action: "Transpose the song down/up a semitone ([key:[] and [transpose:[] tags in the LRC set the default)"},
+		{keys: "Ctrl+E", action: "Equalizer: 10 bands with flat, bass boost, vocal and treble presets, to suit laptop speakers or a PA"},
+		{keys: "z / Alt+1-9", action: "Soundboard: applause, airhorns and drumrolls over the music, from the files in its directory"},
+	}},
+	{"QUICK ACCESS", []helpEntry{
+		{keys: "1-9", action: "Jump to song by number (1-9)"},
+		{keys: "0", action: "Jump to last song"},
+		{keys: "v", action: "Toggle mute/unmute"},
+		{keys: "m", action: "Mark song as favorite"},
+		{keys: "l", action: "Focus on lyrics panel"},
+		{keys: "r", action: "Reload song library from files"},
+		{keys: "b", action: "Chapter list (long tracks)"},
+		{keys: "o", action: "Actions for the selected song (play next, add to playlist, info, lyrics, files)"},
+		{keys: "Shift+A", action: `Show all songs by the playing artist (artist:"..." in search)`},
+		{keys: "Shift+B", action: `Show the rest of the playing song's album (album:"..." in search)`},
+		{keys: "Shift+N", action: "Recently added songs, grouped by day"},
+		{keys: "d", action: "Songs played this session; p steps back through them in the order they played"},
+		{keys: "u", action: "Queue: what plays next and the time each song should start, for telling singers when they're up"},
+		{keys: "Shift+Q", action: "Add the selected song to the queue, to build a singing order apart from the list"},
+		{keys: "Ctrl+L", action: "Switch library (libraries in the config each have their own folders, stats and default playlist)"},
+	}},
+	{"LYRICS", []helpEntry{
+		{keys: "e", action: "Edit lyrics for current song"},
+		{keys: "y", action: "Import lyrics from clipboard"},
+		{keys: "j", action: "Jump to specific time (during playback)"},
+		{keys: "k", action: "Toggle karaoke display mode"},
+		{keys: "{ / }", action: "Show the lyrics 0.1s earlier/later when they're off from the audio (remembered for the song)"},
+		{keys: "Shift+L", action: "Lyrics coverage report (f in the report fetches missing lyrics)", readOnlyAction: "Lyrics coverage report"},
+		{keys: "Shift+K", action: "Karaoke readiness: grade each song's lyrics, Enter opens the editor to fix"},
+		{keys: "Shift+F", action: "Big lyrics: the current line in block letters, readable across the room"},
+		{keys: "Shift+D", action: "Split duet layout: each singer's lines on their own half (P1:/P2:, M:/F: and D: in the LRC); each singer is scored on their own"},
+		{keys: "Shift+J", action: "Lyrics-only songs: an .lrc with no audio plays on a timer, with a click or alongside a live band (following its MIDI clock when set)"},
+	}},
+	{"LIBRARY", []helpEntry{
+		{keys: "f", action: "File management (move/rename/delete)"},
+		{keys: "i", action: "Show detailed song information"},
+		{keys: "x", action: "Export data (performance/library/mixtape)"},
+		{keys: "Shift+H", action: "Hide song from library"},
+		{keys: "Shift+U", action: "Show/unhide hidden songs"},
+		{keys: "Shift+W", action: "Watch folders and auto-import rules"},
+		{keys: "Shift+O", action: "Organize library files by tags (with dry-run preview)"},
+		{keys: "Shift+V", action: "Verify library files (checksums and decode check)"},
+		{keys: "Shift+P", action: "Share playlist over LAN / join one"},
+		{keys: "Ctrl+Z", action: "Undo lyric saves, renames, moves and playlist additions"},
+		{keys: "Ctrl+Y", action: "Redo what Ctrl+Z undid"},
+		{keys: "Ctrl+B", action: "Background jobs: library scans and checks, lyric fetches, loudness scans and exports with their progress (Esc cancels one)"},
+	}},
+	{"PARTY AND MORE", []helpEntry{
+		{keys: "c", action: "Clear all scores and start fresh"},
+		{keys: "g", action: "Party mode: singers take turns, with handicaps, teams and a leaderboard"},
+		{keys: "Shift+T", action: "Party flow: skip long intros and move on after the last line, with a countdown ([yellow]Shift+C[white] keeps listening)"},
+		{keys: "w", action: "Recap: this week's plays and best scores, and on this day last year"},
+		{keys: "Shift+G", action: "Record the screen as an asciicast for sharing (again to stop)"},
+		{keys: "Shift+Z", action: "Alarms: start a playlist at a set time with a volume ramp"},
+		{keys: "Shift+Y", action: "Insights: how often you use each feature, counted locally (off by default)"},
+		{keys: "Shift+I", action: "Check for a new release and read what's new (d downloads and installs it)", readOnlyAction: "Check for a new release and read what's new"},
+		{keys: "F2-F5", action: "Show/hide the header, search, score and visualizer panels (the layout is remembered)"},
+		{keys: "Shift+X", action: "Turn the visualizer off to save CPU, or back on"},
+	}},
+}
+
+// helpFooter closes the help after the key bindings
+const helpFooter = `[cyan]═══ KARAOKE FEATURES ═══[white]
+• [green]Real-time lyrics[white] highlight with the music • [green]Live scoring[white] system with accuracy tracking
+• [green]Streak system[white] for consecutive hits        • [green]Audio visualizer[white] responds to music
+• [green]Performance stats[white] and export capabilities • [green]5-line centered[white] lyrics display
+
+[white]🎵 [yellow]Press [red]h[yellow], [red]q[yellow], or [red]ESC[yellow] to close this help menu, [red]d[yellow] for diagnostics[white] 🎵`
+
+// formatHelp lays out the help text. In read-only mode the keys it turns
+// off are left out, along with the parts of entries that would change files.
+func formatHelp(readOnly bool) string {
+	var help strings.Builder
+	if readOnly {
+		help.WriteString("[yellow]🔒 Read-only mode: editing, file changes and saving settings are turned off[white]\n\n")
+	}
+	for _, section := range helpSections {
+		fmt.Fprintf(&help, "[cyan]═══ %s ═══[white]\n", section.title)
+		for _, entry := range section.entries {
+			action := entry.action
+			if readOnly {
+				if _, off := readOnlyKeys[entry.keys]; off {
+					continue
+				}
+				if entry.readOnlyAction != "" {
+					action = entry.readOnlyAction
+				}
+			}
+			fmt.Fprintf(&help, "[yellow]%s[white] - %s\n", entry.keys, action)
+		}
+		help.WriteString("\n")
+	}
+	help.WriteString(helpFooter)
+	return help.String()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/rivo/tview"
+)
+
+// hideSelectedSong asks for confirmation, then hides the selected song from
+// the library without touching the file
+func (a *App) hideSelectedSong() {
+	if a.denyReadOnly("Hiding songs") {
+		return
+	}
+	selected := a.selectedIndex()
+	if selected < 0 || selected >= len(a.songs) {
+		return
+	}
+	song := a.songs[selected]
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("[yellow]Hide \"%s\" from the library?[white]\n\nThe file stays on disk and can be shown again with Shift+U.", song.Title)).
+		AddButtons([]string{"Hide", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("hide-confirm")
+			a.app.SetFocus(a.songList)
+			if buttonLabel != "Hide" {
+				return
+			}
+
+			if err := a.overrides.SetHidden(song.Path, true); err != nil {
+				a.handleError(err, "Hide Song")
+				return
+			}
+			a.removeSongFromList(song.Path)
+			a.statusBar.SetText(fmt.Sprintf("[yellow]🙈 Hid %s[white]", filepath.Base(song.Path)))
+		})
+
+	a.pages.AddPage("hide-confirm", modal, true, true)
+	a.app.SetFocus(modal)
+}
+
+// removeSongFromList drops a song from the current list, stopping it if it is playing
+func (a *App) removeSongFromList(path string) {
+	for i, song := range a.songs {
+		if song.Path != path {
+			continue
+		}
+
+		if i == a.currentSong && (a.isPlaying || a.isPaused) {
+			a.stop()
+		}
+		a.songs = append(a.songs[:i], a.songs[i+1:]...)
+		a.songRemoved(i)
+		break
+	}
+	a.updateSongList()
+	a.updateNowPlaying()
+}
+
+// showHiddenSongs lists hidden songs; Enter shows the selected one again
+func (a *App) showHiddenSongs() {
+	if a.denyReadOnly("Unhiding songs") {
+		return
+	}
+	hidden := a.overrides.Hidden()
+	if len(hidden) == 0 {
+		a.showMessage("No hidden songs")
+		return
+	}
+
+	list := tview.NewList().ShowSecondaryText(true)
+	list.SetBorder(true).
+		SetTitle(" Hidden Songs - Enter to unhide, Esc close ").
+		SetTitleAlign(tview.AlignCenter)
+	for _, path := range hidden {
+		list.AddItem(filepath.Base(path), filepath.Dir(path), 0, nil)
+	}
+
+	closeList := func() {
+		a.pages.RemovePage("hidden-songs")
+		a.app.SetFocus(a.songList)
+	}
+
+	list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		closeList()
+		if err := a.overrides.SetHidden(hidden[index], false); err != nil {
+			a.handleError(err, "Unhide Song")
+			return
+		}
+		if a.currentPlaylist != "" {
+			a.loadPlaylist(a.currentPlaylist)
+		} else {
+			a.loadSongs()
+		}
+		a.statusBar.SetText(fmt.Sprintf("[green]👀 %s is back in the library[white]", filepath.Base(hidden[index])))
+	})
+	list.SetDoneFunc(closeList)
+
+	a.pages.AddPage("hidden-songs", centered(list, 70, 20), true, true)
+	a.app.SetFocus(list)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// usageFeatures names the features counted for insights by the key that
+// opens them on the main screen
+var usageFeatures = map[rune]string{
+	'S': "shuffle",
+	'R': "repeat",
+	'/': "search",
+	'e': "lyrics editor",
+	'f': "file management",
+	'x': "export",
+	'b': "chapters",
+	'a': "audio settings",
+	'E': "mic effects",
+	'F': "big lyrics",
+	'T': "party flow",
+	'P': "playlist sharing",
+	'y': "clipboard lyrics import",
+	'L': "lyrics coverage",
+	'H': "hide song",
+	'U': "hidden songs",
+	'W': "watch folders",
+	'O': "organize library",
+	'V': "verify library",
+	'M': "metronome",
+	't': "tap tempo",
+	'G': "screen recording",
+	'Z': "alarms",
+	'w': "recap",
+	'g': "party mode",
+	'K': "lyrics readiness",
+	'D': "duet layout",
+	'[': "transpose",
+	']': "transpose",
+	'{': "lyric offset",
+	'}': "lyric offset",
+	'j': "jump to time",
+	'i': "song info",
+	'o': "song menu",
+	'N': "recently added",
+	'A': "artist view",
+	'B': "album view",
+	'k': "karaoke display",
+	'X': "visualizer",
+	'J': "lyrics only",
+	'z': "soundboard",
+	'u': "queue",
+	'Q': "queue",
+	'd': "play history",
+}
+
+// insightsSaveInterval is how often counted uses are written to disk
+const insightsSaveInterval = time.Minute
+
+// noteUsage counts a use of feature when insights are turned on. It is
+// only counted in memory, for saveInsights to write out.
+func (a *App) noteUsage(feature string) {
+	if feature == "" || !a.appConfig.Insights {
+		return
+	}
+	a.insights.Record(feature)
+}
+
+// startInsightsSaver writes the feature counts out in the background now
+// and then, so keypresses never wait on the disk
+func (a *App) startInsightsSaver() {
+	go func() {
+		ticker := time.NewTicker(insightsSaveInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			a.saveInsights()
+		}
+	}()
+}
+
+// saveInsights writes the feature counts recorded since the last save
+func (a *App) saveInsights() {
+	if err := a.insights.Save(); err != nil {
+		a.errorLog.Add("Insights", err)
+	}
+}
+
+// formatInsights lists the features used, most used first, with a bar for
+// how often each is used next to the most used one
+func (a *App) formatInsights() string {
+	var content strings.Builder
+	content.WriteString("[gray]Counted on this machine only; nothing is sent anywhere.[white]\n\n")
+	if !a.appConfig.Insights {
+		content.WriteString("Insights are off. Press [yellow]o[white] to start counting which features you use,\nto see your own habits over time.\n")
+		return content.String()
+	}
+
+	usage := a.insights.Usage()
+	if len(usage) == 0 {
+		content.WriteString("Nothing counted yet. Use Tuneminal for a while and come back.\n")
+		return content.String()
+	}
+	for _, u := range usage {
+		bar := strings.Repeat("█", max(1, u.Count*20/usage[0].Count))
+		content.WriteString(fmt.Sprintf("%-24s [cyan]%-20s[white] %5d  [gray]last %s[white]\n",
+			u.Feature, bar, u.Count, u.Last.Format("2006-01-02")))
+	}
+	return content.String()
+}
+
+// showInsights shows how often each feature is used. o turns counting on or
+// off, x exports the counts as JSON and c clears them.
+func (a *App) showInsights() {
+	a.pages.RemovePage("insights")
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(a.formatInsights())
+	view.SetBorder(true).
+		SetTitle(" Insights - o on/off, x export, c clear, Esc close ").
+		SetTitleAlign(tview.AlignCenter)
+
+	closeView := func() {
+		a.pages.RemovePage("insights")
+		a.app.SetFocus(a.songList)
+	}
+	view.SetDoneFunc(func(key tcell.Key) {
+		closeView()
+	})
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'o':
+			a.appConfig.Insights = !a.appConfig.Insights
+			a.saveConfig()
+			view.SetText(a.formatInsights())
+			return nil
+		case 'x':
+			path, err := a.exportManager.NewFilePath("insights", "", "json")
+			if err == nil {
+				err = a.insights.Export(path)
+			}
+			if err != nil {
+				a.handleError(err, "Export Insights")
+				return nil
+			}
+			a.showToast("[green]✓ Insights exported to " + path + "[white]")
+			return nil
+		case 'c':
+			if err := a.insights.Clear(); err != nil {
+				a.handleError(err, "Clear Insights")
+				return nil
+			}
+			view.SetText(a.formatInsights())
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("insights", centered(view, 76, 24), true, true)
+	a.app.SetFocus(view)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// instrumentalMinGap is how long a break between lines has to be for the
+// lyrics panel to count down to the next line instead of holding the last
+const instrumentalMinGap = 8 * time.Second
+
+// instrumentalDots is how many dots mark the progress through a break
+const instrumentalDots = 12
+
+// instrumentalBreak reports whether the song is in a long break after lyric
+// line activeIndex, or before the first line when it is -1, returning how
+// long until the next line and how far through the break it is, from 0 to 1
+func (a *App) instrumentalBreak(activeIndex int) (left time.Duration, progress float64, ok bool) {
+	next := activeIndex + 1
+	if next >= len(a.lyricLines) {
+		return 0, 0, false
+	}
+	var start time.Duration
+	if activeIndex >= 0 {
+		start = a.lineEnd(activeIndex)
+	}
+	end := a.lyricLines[next].Time
+	if end-start < instrumentalMinGap || a.position < start || a.position >= end {
+		return 0, 0, false
+	}
+	return end - a.position, float64(a.position-start) / float64(end-start), true
+}
+
+// formatInstrumentalBreak shows the countdown to the next line, with a row
+// of dots filling in as the break goes on and the newest one pulsing
+func (a *App) formatInstrumentalBreak(activeIndex int, left time.Duration, progress float64) string {
+	label := "INSTRUMENTAL"
+	if activeIndex < 0 {
+		label = "INTRO"
+	}
+	seconds := int((left + time.Second - 1) / time.Second)
+	heading := fmt.Sprintf("[cyan::b]♪  %s — next line in %d:%02d  ♪[white::-]", label, seconds/60, seconds%60)
+
+	filled := int(progress * instrumentalDots)
+	pulse := a.position.Milliseconds()%1000 < 500
+	dots := make([]string, instrumentalDots)
+	for i := range dots {
+		switch {
+		case i < filled:
+			dots[i] = "[cyan]●"
+		case i == filled && pulse:
+			dots[i] = "[cyan]◉"
+		default:
+			dots[i] = "[gray]○"
+		}
+	}
+	return heading + "\n" + strings.Join(dots, " ") + "[white]"
+}
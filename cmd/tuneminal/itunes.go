@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/tuneminal/tuneminal/pkg/itunes"
+	"github.com/tuneminal/tuneminal/pkg/overrides"
+	"github.com/tuneminal/tuneminal/pkg/playlist"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// formatITunesImport renders what an iTunes import brings over
+func formatITunesImport(migration *itunes.Migration) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Matched %d of %d iTunes tracks to library files\n",
+		migration.Matched, migration.Matched+len(migration.Unmatched)))
+	content.WriteString(fmt.Sprintf("%d rating(s) and %d play count(s) to carry over\n",
+		len(migration.Ratings), len(migration.PlayCounts)))
+
+	if len(migration.Playlists) > 0 {
+		content.WriteString("\nPlaylists:\n")
+		for _, imported := range migration.Playlists {
+			line := fmt.Sprintf("  %s: %d song(s)", imported.Name, len(imported.Songs))
+			if imported.Missing > 0 {
+				line += fmt.Sprintf(", %d not in the library", imported.Missing)
+			}
+			content.WriteString(line + "\n")
+		}
+	}
+
+	if len(migration.Unmatched) > 0 {
+		content.WriteString("\nNot found in the library:\n")
+		for _, track := range migration.Unmatched {
+			content.WriteString(fmt.Sprintf("  %s - %s\n", track.Artist, track.Name))
+		}
+	}
+	return content.String()
+}
+
+// mergeIntoPlaylist adds songs to the playlist called name, creating it if
+// needed, and skips songs it already has. It returns how many were added.
+func mergeIntoPlaylist(playlists *playlist.PlaylistManager, name, description string, songs []string) (int, error) {
+	list, err := playlists.LoadPlaylist(name)
+	if err != nil {
+		if list, err = playlists.CreatePlaylist(name, description); err != nil {
+			return 0, err
+		}
+	}
+
+	added := 0
+	existing := make(map[string]bool)
+	for _, song := range list.Songs {
+		existing[song] = true
+	}
+	for _, song := range songs {
+		if !existing[song] {
+			existing[song] = true
+			list.Songs = append(list.Songs, song)
+			added++
+		}
+	}
+	return added, playlists.SavePlaylist(list)
+}
+
+// applyITunesImport adds the imported playlists' songs to playlists of the
+// same name, creating them as needed, and stores ratings and play counts
+func applyITunesImport(migration *itunes.Migration, playlists *playlist.PlaylistManager, songOverrides *overrides.Store) (string, error) {
+	added := 0
+	for _, imported := range migration.Playlists {
+		if len(imported.Songs) == 0 {
+			continue
+		}
+		count, err := mergeIntoPlaylist(playlists, imported.Name, "Imported from iTunes", imported.Songs)
+		if err != nil {
+			return "", err
+		}
+		added += count
+	}
+
+	for path, stars := range migration.Ratings {
+		if err := songOverrides.Update(path, func(o *overrides.Override) { o.Rating = stars }); err != nil {
+			return "", err
+		}
+	}
+	for path, plays := range migration.PlayCounts {
+		if err := songOverrides.Update(path, func(o *overrides.Override) { o.ImportedPlays = plays }); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("Added %d song(s) to %d playlist(s), stored %d rating(s) and %d play count(s)",
+		added, len(migration.Playlists), len(migration.Ratings), len(migration.PlayCounts)), nil
+}
+
+// runITunesImportCommand handles "tuneminal import-itunes"
+func runITunesImportCommand(args []string, readOnly bool) error {
+	flags := flag.NewFlagSet("import-itunes", flag.ContinueOnError)
+	apply := flags.Bool("apply", false, "import into the library (default is a dry run)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: tuneminal import-itunes [-apply] Library.xml")
+	}
+
+	library, err := itunes.Open(utils.ExpandHome(flags.Arg(0)))
+	if err != nil {
+		return err
+	}
+	songs, err := scanLibraryFolders()
+	if err != nil {
+		return err
+	}
+
+	migration := itunes.Plan(library, songs)
+	fmt.Print(formatITunesImport(migration))
+
+	if !*apply {
+		fmt.Println("\nDry run only; pass -apply to import.")
+		return nil
+	}
+	if readOnly {
+		return errReadOnly
+	}
+
+	summary, err := applyITunesImport(migration, playlist.NewPlaylistManager(), overrides.NewStore())
+	if err != nil {
+		return err
+	}
+	fmt.Println("\n" + summary)
+	return nil
+}
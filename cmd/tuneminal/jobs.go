@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/jobs"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// newJobManager creates the manager background jobs run under, redrawing
+// the status bar and jobs panel as they change
+func (a *App) newJobManager() *jobs.Manager {
+	return jobs.NewManager(func() {
+		// Changes come from the UI goroutine too, which mustn't wait on itself
+		go a.app.QueueUpdateDraw(a.renderJobs)
+	})
+}
+
+// renderJobs refreshes the status bar and, when it is open, the jobs panel
+func (a *App) renderJobs() {
+	a.updateStatus()
+	if a.jobsView != nil {
+		running := a.jobs.Running()
+		a.jobsSelected = max(0, min(a.jobsSelected, len(running)-1))
+		a.jobsView.SetText(formatJobs(running, a.jobsSelected))
+	}
+}
+
+// formatJobCount shows how many of a job's items are done, when it knows
+func formatJobCount(progress jobs.Progress) string {
+	if progress.Total == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" %d/%d", progress.Done, progress.Total)
+}
+
+// jobStatus sums up the running jobs for the status bar, empty when there
+// are none
+func (a *App) jobStatus() string {
+	running := a.jobs.Running()
+	switch len(running) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("[cyan]⏳ %s%s[white] (Ctrl+B)", running[0].Name, formatJobCount(running[0].Progress()))
+	}
+	return fmt.Sprintf("[cyan]⏳ %d jobs running[white] (Ctrl+B)", len(running))
+}
+
+// formatJobs lists the running jobs with their progress, the selected one
+// marked
+func formatJobs(running []*jobs.Job, selected int) string {
+	if len(running) == 0 {
+		return "\n[gray]No background jobs running.[white]\n\n[gray]Enter/Esc close[white]"
+	}
+
+	const width = 30
+	var content strings.Builder
+	for i, job := range running {
+		progress := job.Progress()
+		marker, color := "  ", "white"
+		if i == selected {
+			marker, color = "▶ ", "yellow"
+		}
+		state := utils.FormatDuration(time.Since(job.Started).Truncate(time.Second))
+		if job.Cancelled() {
+			state = "cancelling..."
+		}
+		fmt.Fprintf(&content, "[%s]%s%s[gray] %s[white]\n", color, marker, job.Name, state)
+
+		filled := 0
+		if progress.Total > 0 {
+			filled = min(width, progress.Done*width/progress.Total)
+		}
+		fmt.Fprintf(&content, "  [green]%s[gray]%s[white]%s\n",
+			strings.Repeat("█", filled), strings.Repeat("░", width-filled), formatJobCount(progress))
+		if progress.Current != "" {
+			fmt.Fprintf(&content, "  [gray]%s[white]\n", progress.Current)
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("[gray]↑/↓ pick  Esc cancel job  Enter close[white]")
+	return content.String()
+}
+
+// showJobs shows the background jobs that are running, any of which Esc
+// cancels
+func (a *App) showJobs() {
+	a.jobsView = tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	a.jobsView.SetBorder(true).
+		SetTitle(" Background Jobs ").
+		SetTitleAlign(tview.AlignCenter)
+	a.jobsSelected = 0
+
+	closePanel := func() {
+		a.pages.RemovePage("jobs")
+		a.jobsView = nil
+		a.app.SetFocus(a.songList)
+	}
+	a.jobsView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		running := a.jobs.Running()
+		switch event.Key() {
+		case tcell.KeyUp:
+			a.jobsSelected--
+		case tcell.KeyDown:
+			a.jobsSelected++
+		case tcell.KeyEscape:
+			// Esc stops the job picked; with nothing left to stop it closes
+			if a.jobsSelected < len(running) && !running[a.jobsSelected].Cancelled() {
+				running[a.jobsSelected].Cancel()
+				return nil
+			}
+			closePanel()
+			return nil
+		case tcell.KeyEnter:
+			closePanel()
+			return nil
+		default:
+			if event.Rune() == 'q' {
+				closePanel()
+			}
+			return nil
+		}
+		a.renderJobs()
+		return nil
+	})
+	a.renderJobs()
+
+	a.pages.AddPage("jobs", centered(a.jobsView, 60, 20), true, true)
+	a.app.SetFocus(a.jobsView)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/journal"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// journalInterval is how often the run in progress is written down
+const journalInterval = 5 * time.Second
+
+// isScoredRun reports whether the current song is being sung for a score,
+// which is when it has lyrics to hit
+func (a *App) isScoredRun() bool {
+	if a.currentSong < 0 || a.currentSong >= len(a.songs) {
+		return false
+	}
+	return (a.isPlaying || a.isPaused) && a.songs[a.currentSong].LyricsPath != "" && a.totalLyrics > 0
+}
+
+// writeJournal notes the song, position and score of the run in progress, so
+// it can be resumed if the app crashes or is quit before the end
+func (a *App) writeJournal() {
+	a.lastJournalWrite = time.Now()
+	if a.journal == nil || !a.isScoredRun() || a.position <= 0 {
+		return
+	}
+
+	song := a.songs[a.currentSong]
+	entry := journal.Entry{
+		Path:     song.Path,
+		Title:    song.Title,
+		Position: a.position,
+		Score:    a.karaokeScore,
+		Streak:   a.streak,
+	}
+	for i, line := range a.lyricLines {
+		if line.IsHit {
+			entry.Hits = append(entry.Hits, i)
+		}
+	}
+	if err := a.journal.Write(entry); err != nil {
+		a.errorLog.Add("Session Journal", err)
+	}
+}
+
+// clearJournal forgets the run in progress once it ends or another starts
+func (a *App) clearJournal() {
+	if a.journal == nil {
+		return
+	}
+	if err := a.journal.Clear(); err != nil {
+		a.errorLog.Add("Session Journal", err)
+	}
+}
+
+// offerJournalResume asks whether to pick up a scored run that was cut
+// short last time. It's skipped when something is already playing or shown.
+func (a *App) offerJournalResume() {
+	if a.journal == nil || a.kiosk || a.isPlaying || a.isOverlayOpen() {
+		return
+	}
+	entry := a.journal.Read()
+	if entry == nil {
+		return
+	}
+	if a.songIndex(entry.Path) < 0 {
+		a.clearJournal()
+		return
+	}
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("[yellow]Resume \"%s\"?[white]\n\nLast time it stopped at %s with a score of %d.",
+			entry.Title, utils.FormatDuration(entry.Position), entry.Score)).
+		AddButtons([]string{"Resume", "Discard"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("journal-resume")
+			a.app.SetFocus(a.songList)
+			if buttonLabel != "Resume" {
+				a.clearJournal()
+				return
+			}
+			a.resumeJournal(*entry)
+		})
+	a.pages.AddPage("journal-resume", modal, true, true)
+	a.app.SetFocus(modal)
+}
+
+// resumeJournal plays a song from the journal at the position it stopped,
+// with the score and the lines already hit as they were
+func (a *App) resumeJournal(entry journal.Entry) {
+	index := a.songIndex(entry.Path)
+	if index < 0 {
+		a.showWarning("Song not found: " + entry.Title)
+		a.clearJournal()
+		return
+	}
+
+	a.currentSong = index
+	a.isPaused = false
+	a.play()
+	if !a.isPlaying {
+		return
+	}
+	if err := a.player.SeekTo(entry.Position); err != nil {
+		a.handleError(err, "Resume Session")
+		return
+	}
+	a.position = entry.Position
+
+	active := a.findCurrentLyricIndex(entry.Position)
+	for i := range a.lyricLines {
+		a.lyricLines[i].IsActive = i <= active
+	}
+	a.hitLyrics = 0
+	for _, i := range entry.Hits {
+		if i >= 0 && i < len(a.lyricLines) {
+			a.lyricLines[i].IsHit = true
+			a.hitLyrics++
+		}
+	}
+	a.karaokeScore = entry.Score
+	a.streak = entry.Streak
+	a.accuracy = a.calculateAccuracy()
+	a.writeJournal()
+	a.updateAllDisplays()
+	a.showToast(fmt.Sprintf("⏯ Resumed at %s", utils.FormatDuration(entry.Position)))
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tuneminal/tuneminal/pkg/config"
+	"github.com/tuneminal/tuneminal/pkg/playlist"
+)
+
+// kioskProfileMarker starts and ends the lines kiosk setup adds to the
+// login profile, so they can be found again and taken out
+const kioskProfileMarker = "# tuneminal kiosk"
+
+// startKiosk turns on what a dedicated karaoke box needs: guests can request
+// songs over the network and the music starts by itself. Read-only mode is
+// already on, and audio recovery keeps trying until the device comes back.
+func (a *App) startKiosk() {
+	if !a.kiosk {
+		return
+	}
+
+	a.repeatMode = true
+	if err := a.startSharing(); err != nil {
+		a.errorLog.Add("Kiosk", fmt.Errorf("failed to start the request server: %w", err))
+	}
+
+	// Start playing once the screen is up
+	go a.app.QueueUpdateDraw(a.startKioskPlaylist)
+}
+
+// startKioskPlaylist plays the kiosk playlist from the top, or the library
+// when there is none
+func (a *App) startKioskPlaylist() {
+	if name := a.appConfig.KioskPlaylist; name != "" {
+		if err := a.loadPlaylist(name); err != nil {
+			a.errorLog.Add("Kiosk", fmt.Errorf("failed to load playlist %q: %w", name, err))
+			a.loadSongs()
+		}
+		a.publishSharedPlaylist()
+	}
+	if len(a.songs) == 0 {
+		return
+	}
+	a.currentSong = 0
+	a.updateSongList()
+	a.play()
+}
+
+// kioskProfileScript is the login profile snippet that starts the kiosk on
+// the first console, restarting it if it exits. With console autologin (on a
+// Raspberry Pi, raspi-config's "Console Autologin"), it starts at boot.
+func kioskProfileScript(executable string) string {
+	return fmt.Sprintf(`%s
+if [ "$(tty)" = "/dev/tty1" ]; then
+  while true; do %s --kiosk; sleep 2; done
+fi
+%s end
+`, kioskProfileMarker, shellQuote(executable), kioskProfileMarker)
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// withoutKioskScript removes the kiosk snippet from a login profile
+func withoutKioskScript(profile string) string {
+	start := strings.Index(profile, kioskProfileMarker+"\n")
+	if start < 0 {
+		return profile
+	}
+	endMarker := kioskProfileMarker + " end\n"
+	end := strings.Index(profile[start:], endMarker)
+	if end < 0 {
+		return profile
+	}
+	return profile[:start] + profile[start+end+len(endMarker):]
+}
+
+// runKioskCommand previews, and with -apply sets up, kiosk mode: the config
+// profile and starting on the first console at boot. -off undoes both.
+func runKioskCommand(args []string, readOnly bool) error {
+	flags := flag.NewFlagSet("kiosk", flag.ContinueOnError)
+	playlistName := flags.String("playlist", "", "playlist to play on start (default: the whole library)")
+	off := flags.Bool("off", false, "turn kiosk mode off and stop starting it at boot")
+	apply := flags.Bool("apply", false, "make the changes (default is a dry run)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("usage: tuneminal kiosk [-playlist NAME] [-off] [-apply]")
+	}
+
+	if *playlistName != "" {
+		if _, err := playlist.NewPlaylistManager().GetPlaylistSongs(*playlistName); err != nil {
+			return fmt.Errorf("no playlist %q: %w", *playlistName, err)
+		}
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the tuneminal executable: %w", err)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	profilePath := filepath.Join(home, ".profile")
+
+	// Loading a missing config would save the defaults, so only load one that exists
+	cfg := config.DefaultConfig()
+	if _, err := os.Stat(config.GetConfigPath()); err == nil {
+		if cfg, err = config.LoadConfig(config.GetConfigPath()); err != nil {
+			return err
+		}
+	}
+	script := kioskProfileScript(executable)
+
+	if *off {
+		fmt.Printf("Turn kiosk mode off in %s\nRemove the kiosk start-up from %s\n", config.GetConfigPath(), profilePath)
+	} else {
+		fmt.Printf("Turn kiosk mode on in %s:\n", config.GetConfigPath())
+		fmt.Println("  - read-only: no file changes, no saved settings")
+		fmt.Printf("  - request server on %s for guests to propose songs\n", cfg.ShareAddress)
+		if *playlistName != "" {
+			fmt.Printf("  - play the %q playlist on start, repeating\n", *playlistName)
+		} else {
+			fmt.Println("  - play the library on start, repeating")
+		}
+		fmt.Println("  - keep reconnecting the audio device when it is unplugged")
+		fmt.Printf("\nStart it on the first console at login, in %s:\n\n%s", profilePath, script)
+		fmt.Println("\nTurn on console autologin (raspi-config: System Options > Boot / Auto Login) to start at boot.")
+	}
+	if !*apply {
+		fmt.Println("\nDry run only; pass -apply to make the changes.")
+		return nil
+	}
+	if readOnly {
+		return errReadOnly
+	}
+
+	cfg.Kiosk = !*off
+	if !*off {
+		cfg.KioskPlaylist = *playlistName
+	}
+	if err := cfg.SaveConfig(config.GetConfigPath()); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	profile, err := os.ReadFile(profilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	updated := withoutKioskScript(string(profile))
+	if !*off {
+		if updated != "" && !strings.HasSuffix(updated, "\n") {
+			updated += "\n"
+		}
+		updated += script
+	}
+	if err := os.WriteFile(profilePath, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to update %s: %w", profilePath, err)
+	}
+	fmt.Println("\nDone.")
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+)
+
+// languageRecheck is how long a detected language is trusted before the
+// lyrics file is checked for changes again
+const languageRecheck = 5 * time.Second
+
+// detectedLanguage caches the language found in a lyrics file
+type detectedLanguage struct {
+	code    string
+	modTime time.Time
+	checked time.Time
+}
+
+// songLanguage returns the language code of a song's lyrics, or "" when it
+// has none or it couldn't be told. Detection runs again when the file changes.
+func (a *App) songLanguage(song Song) string {
+	if song.LyricsPath == "" {
+		return ""
+	}
+
+	cached, ok := a.languages[song.LyricsPath]
+	if ok && time.Since(cached.checked) < languageRecheck {
+		return cached.code
+	}
+
+	info, err := os.Stat(song.LyricsPath)
+	if err != nil {
+		return ""
+	}
+	if !ok || !info.ModTime().Equal(cached.modTime) {
+		cached.code = lyrics.FileLanguage(song.LyricsPath)
+		cached.modTime = info.ModTime()
+	}
+	cached.checked = time.Now()
+	a.languages[song.LyricsPath] = cached
+	return cached.code
+}
+
+// languageLabel returns the song list label for a song's lyric language
+func (a *App) languageLabel(song Song) string {
+	code := a.songLanguage(song)
+	if code == "" {
+		return ""
+	}
+	return " [cyan]" + strings.ToUpper(code) + "[white]"
+}
+
+// parseSearchQuery splits a "lang:xx" filter off the search text. The
+// language may be given as a code or a name, e.g. lang:es or lang:spanish.
+func parseSearchQuery(text string) (query, language string) {
+	var terms []string
+	for _, field := range strings.Fields(text) {
+		if value, ok := strings.CutPrefix(strings.ToLower(field), "lang:"); ok {
+			language = value
+			continue
+		}
+		terms = append(terms, field)
+	}
+	return strings.Join(terms, " "), language
+}
+
+// matchesLanguage reports whether a song's lyrics are in the language filter
+func (a *App) matchesLanguage(song Song, language string) bool {
+	if language == "" {
+		return true
+	}
+	code := a.songLanguage(song)
+	return code != "" && (code == language || strings.EqualFold(lyrics.LanguageName(code), language))
+}
+
+// sortedLanguages orders language counts by number of songs, unknown last
+func sortedLanguages(counts map[string]int) []string {
+	languages := make([]string, 0, len(counts))
+	for language := range counts {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if (languages[i] == "") != (languages[j] == "") {
+			return languages[j] == ""
+		}
+		if counts[languages[i]] != counts[languages[j]] {
+			return counts[languages[i]] > counts[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+	return languages
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Panels that can be hidden to give the lyrics more room
+const (
+	panelHeader     = "header"
+	panelSearch     = "search"
+	panelScore      = "score"
+	panelVisualizer = "visualizer"
+)
+
+// panelKeys are the keys that show and hide each panel
+var panelKeys = map[tcell.Key]string{
+	tcell.KeyF2: panelHeader,
+	tcell.KeyF3: panelSearch,
+	tcell.KeyF4: panelScore,
+	tcell.KeyF5: panelVisualizer,
+}
+
+// panelVisible reports whether a panel is shown
+func (a *App) panelVisible(name string) bool {
+	return !slices.Contains(a.appConfig.HiddenPanels, name)
+}
+
+// arrangePanels lays out the main page from the panels that are shown
+func (a *App) arrangePanels() {
+	a.mainLayout.Clear()
+
+	if a.panelVisible(panelHeader) {
+		a.mainLayout.AddItem(a.header, 10, 1, false)
+	}
+	if a.panelVisible(panelSearch) {
+		a.mainLayout.AddItem(a.searchInput, 3, 1, false)
+	}
+
+	// Main content area (horizontal)
+	contentArea := tview.NewFlex().SetDirection(tview.FlexColumn)
+
+	// Left panel (songs + score)
+	leftPanel := tview.NewFlex().SetDirection(tview.FlexRow)
+	leftPanel.AddItem(a.songList, 0, 1, true)
+	if a.panelVisible(panelScore) {
+		leftPanel.AddItem(a.score, 6, 1, false)
+	}
+	contentArea.AddItem(leftPanel, 0, 1, true)
+
+	// Right panel (now playing + visualizer)
+	rightPanel := tview.NewFlex().SetDirection(tview.FlexRow)
+	rightPanel.AddItem(a.nowPlaying, 0, 1, false)
+	if a.panelVisible(panelVisualizer) {
+		rightPanel.AddItem(a.visualizer, 0, 1, false)
+	}
+	contentArea.AddItem(rightPanel, 0, 1, false)
+
+	a.mainLayout.AddItem(contentArea, 0, 1, true)
+
+	// Progress bar, lyrics and status bar always stay
+	a.mainLayout.AddItem(a.progress, 1, 1, false)
+	a.mainLayout.AddItem(a.lyrics, 0, 1, false)
+	a.mainLayout.AddItem(a.statusBar, 1, 1, false)
+}
+
+// togglePanel shows or hides a panel and remembers the layout
+func (a *App) togglePanel(name string) {
+	if a.panelVisible(name) {
+		a.appConfig.HiddenPanels = append(a.appConfig.HiddenPanels, name)
+		a.showToast("Hid the " + name + " panel")
+	} else {
+		a.appConfig.HiddenPanels = slices.DeleteFunc(a.appConfig.HiddenPanels, func(hidden string) bool {
+			return hidden == name
+		})
+		a.showToast("Showing the " + name + " panel")
+	}
+	a.arrangePanels()
+	a.saveConfig()
+}
+
+// focusSearch moves to the search box, bringing it back first if it was hidden
+func (a *App) focusSearch() {
+	if !a.panelVisible(panelSearch) {
+		a.togglePanel(panelSearch)
+	}
+	a.app.SetFocus(a.searchInput)
+}
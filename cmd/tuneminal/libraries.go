@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/config"
+	"github.com/tuneminal/tuneminal/pkg/history"
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// currentLibrary is the library in use, nil when the config sets up none and
+// the songs come from libraryDir
+var currentLibrary *config.Library
+
+// libraryRoots returns the folders the library in use is scanned from
+func libraryRoots() []string {
+	if currentLibrary == nil {
+		return []string{libraryDir}
+	}
+	return currentLibrary.Roots
+}
+
+// homeRoot returns the library folder that imports and organizing put files
+// in: the first of the library in use
+func homeRoot() string {
+	return libraryRoots()[0]
+}
+
+// selectLibrary finds the library called name in cfg. An empty name picks
+// the one last used, or else the first; nil means none are set up.
+func selectLibrary(cfg *config.Config, name string) (*config.Library, error) {
+	if len(cfg.Libraries) == 0 {
+		if name != "" {
+			return nil, fmt.Errorf("no library named %q: none are set up under libraries in %s", name, config.GetConfigPath())
+		}
+		return nil, nil
+	}
+
+	wanted := name
+	if wanted == "" {
+		wanted = cfg.ActiveLibrary
+	}
+	for _, library := range cfg.Libraries {
+		if library.Name == wanted {
+			return expandLibrary(library)
+		}
+	}
+	if name != "" {
+		return nil, fmt.Errorf("no library named %q in %s", name, config.GetConfigPath())
+	}
+	return expandLibrary(cfg.Libraries[0])
+}
+
+// expandLibrary checks a library has folders and expands ~ in them
+func expandLibrary(library config.Library) (*config.Library, error) {
+	if len(library.Roots) == 0 {
+		return nil, fmt.Errorf("library %q has no folders: add them as roots in %s", library.Name, config.GetConfigPath())
+	}
+	roots := make([]string, len(library.Roots))
+	for i, root := range library.Roots {
+		roots[i] = utils.ExpandHome(root)
+	}
+	library.Roots = roots
+	return &library, nil
+}
+
+// useLibrary picks the library this run works on: the one named by
+// --library, or the one last used. A missing config isn't created.
+func useLibrary(name string) error {
+	cfg := config.DefaultConfig()
+	if _, err := os.Stat(config.GetConfigPath()); err == nil {
+		if loaded, err := config.LoadConfig(config.GetConfigPath()); err == nil {
+			cfg = loaded
+		}
+	}
+	library, err := selectLibrary(cfg, name)
+	if err != nil {
+		return err
+	}
+	currentLibrary = library
+	return nil
+}
+
+// listLibraryFiles lists the audio files in every folder of the library in
+// use, each once
+func listLibraryFiles() ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, root := range libraryRoots() {
+		found, err := metadata.ListAudioFiles(root)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range found {
+			if !seen[path] {
+				seen[path] = true
+				files = append(files, path)
+			}
+		}
+	}
+	return files, nil
+}
+
+// scanLibraryFolders reads the metadata of every audio file in the library
+// in use
+func scanLibraryFolders() ([]*metadata.SongMetadata, error) {
+	var songs []*metadata.SongMetadata
+	seen := make(map[string]bool)
+	for _, root := range libraryRoots() {
+		found, err := metadata.ScanDirectory(root)
+		if err != nil {
+			return nil, err
+		}
+		for _, song := range found {
+			if !seen[song.Path] {
+				seen[song.Path] = true
+				songs = append(songs, song)
+			}
+		}
+	}
+	return songs, nil
+}
+
+// inLibrary reports whether path is inside a folder of the library in use.
+// Without libraries set up everything is, remote songs included.
+func inLibrary(path string) bool {
+	if currentLibrary == nil {
+		return true
+	}
+	for _, root := range currentLibrary.Roots {
+		if isWithin(root, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithin reports whether path is root or somewhere under it
+func isWithin(root, path string) bool {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return false
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// libraryPlays keeps the plays of songs in the library in use, so each
+// library has stats of its own
+func libraryPlays(plays []history.Play) []history.Play {
+	if currentLibrary == nil {
+		return plays
+	}
+	var kept []history.Play
+	for _, play := range plays {
+		if inLibrary(play.Path) {
+			kept = append(kept, play)
+		}
+	}
+	return kept
+}
+
+// showLibraryPicker lists the libraries set up in the config to switch
+// between
+func (a *App) showLibraryPicker() {
+	if len(a.appConfig.Libraries) == 0 {
+		a.showMessage("No libraries are set up yet.\n\nAdd them under \"libraries\" in " + config.GetConfigPath() +
+			", each with a name and its folders, e.g.\n" +
+			`{"name": "Kids", "roots": ["~/Music/Kids"], "default_playlist": "Sing-along"}`)
+		return
+	}
+
+	list := tview.NewList()
+	list.SetBorder(true).
+		SetTitle(" Libraries ").
+		SetTitleAlign(tview.AlignCenter)
+
+	closeList := func() {
+		a.pages.RemovePage("library-picker")
+		a.app.SetFocus(a.songList)
+	}
+	current := 0
+	for i, library := range a.appConfig.Libraries {
+		name := library.Name
+		label := name
+		if currentLibrary != nil && currentLibrary.Name == name {
+			label = "[green]● " + name + "[white]"
+			current = i
+		}
+		list.AddItem(label, strings.Join(library.Roots, ", "), 0, func() {
+			closeList()
+			a.switchLibrary(name)
+		})
+	}
+	list.SetCurrentItem(current)
+	list.SetDoneFunc(closeList)
+
+	a.pages.AddPage("library-picker", centered(list, 60, 2*list.GetItemCount()+2), true, true)
+	a.app.SetFocus(list)
+}
+
+// switchLibrary makes the library called name the one in use, listing its
+// songs and loading its default playlist
+func (a *App) switchLibrary(name string) {
+	library, err := selectLibrary(a.appConfig, name)
+	if err != nil {
+		a.handleError(err, "Switch Library")
+		return
+	}
+
+	a.stop()
+	currentLibrary = library
+	a.appConfig.ActiveLibrary = library.Name
+	a.saveConfig()
+	a.currentPlaylist = ""
+	a.loadSongs()
+	a.loadDefaultPlaylist()
+	a.showToast(fmt.Sprintf("[green]✓ %s library: %d songs[white]", library.Name, len(a.songs)))
+}
+
+// loadDefaultPlaylist loads the playlist the library in use starts with,
+// when it names one
+func (a *App) loadDefaultPlaylist() {
+	if currentLibrary == nil || currentLibrary.DefaultPlaylist == "" {
+		return
+	}
+	if err := a.loadPlaylist(currentLibrary.DefaultPlaylist); err != nil {
+		a.errorLog.Add("Default Playlist", err)
+	}
+}
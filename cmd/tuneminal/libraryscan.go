@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/jobs"
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+)
+
+// librarySaveInterval is how often a background scan saves the library
+// index, so a long first scan isn't lost if the app is closed part way
+const librarySaveInterval = 10 * time.Second
+
+// songFromMetadata makes a library song from a file's metadata
+func (a *App) songFromMetadata(meta *metadata.SongMetadata) Song {
+	return Song{
+		Title:      meta.Title,
+		Artist:     meta.Artist,
+		Album:      meta.Album,
+		Path:       meta.Path,
+		LyricsPath: a.findLyricsFile(meta.Path, meta.Title, meta.Artist),
+		Duration:   meta.Duration,
+		Chapters:   meta.Chapters,
+	}
+}
+
+// scanLibrary reads the files the library index doesn't know yet in a
+// background job, adding each to the song list as it is read. Loading the
+// songs again stops it.
+func (a *App) scanLibrary(paths []string) {
+	if a.libraryScan != nil {
+		a.libraryScan.Cancel()
+		a.libraryScan = nil
+	}
+	if len(paths) == 0 {
+		if err := a.library.Save(); err != nil {
+			a.errorLog.Add("Library Index", err)
+		}
+		return
+	}
+	a.showToast(fmt.Sprintf("[cyan]🔎 Reading %d new or changed files...[white]", len(paths)))
+
+	a.libraryScan = a.jobs.Start("Reading library", func(ctx context.Context, job *jobs.Job) {
+		added := 0
+		lastSave := time.Now()
+		for i, path := range paths {
+			if ctx.Err() != nil {
+				break
+			}
+			job.SetProgress(i, len(paths), filepath.Base(path))
+
+			meta, err := a.library.Read(path)
+			if err != nil {
+				continue
+			}
+			added++
+			a.app.QueueUpdateDraw(func() {
+				if ctx.Err() == nil {
+					a.addScannedSong(meta)
+				}
+			})
+			if time.Since(lastSave) > librarySaveInterval {
+				a.library.Save()
+				lastSave = time.Now()
+			}
+		}
+
+		err := a.library.Save()
+		a.app.QueueUpdateDraw(func() {
+			if err != nil {
+				a.errorLog.Add("Library Index", err)
+			}
+			// A scan replaced by a newer one finishes quietly
+			if a.libraryScan != job {
+				return
+			}
+			a.libraryScan = nil
+			if ctx.Err() != nil {
+				a.showToast(fmt.Sprintf("[yellow]Library scan cancelled: %d of %d files read[white]", added, len(paths)))
+				return
+			}
+			a.showToast(fmt.Sprintf("[green]✅ Library scan done: %d of %d files read[white]", added, len(paths)))
+		})
+	})
+}
+
+// addScannedSong adds a song the background scan has read to the song list
+func (a *App) addScannedSong(meta *metadata.SongMetadata) {
+	if a.overrides.IsHidden(meta.Path) || a.songIndex(meta.Path) >= 0 {
+		return
+	}
+	a.songs = append(a.songs, a.songFromMetadata(meta))
+	if a.currentSong < 0 {
+		a.currentSong = 0
+		a.selectedSong = 0
+	}
+	a.updateSongList()
+}
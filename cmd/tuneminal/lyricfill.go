@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// Colors of the current line's sung and unsung parts
+const (
+	lyricSungStyle   = "[yellow::b]"
+	lyricUnsungStyle = "[gray::b]"
+)
+
+// lyricFillStyles returns the styles of the current line's sung and unsung
+// parts, bold unless lyrics_emphasis asks for color only
+func (a *App) lyricFillStyles() (sung, unsung string) {
+	if a.appConfig.LyricsEmphasis == "color" {
+		return "[yellow::-]", "[gray::-]"
+	}
+	return lyricSungStyle, lyricUnsungStyle
+}
+
+// perCharacterTime and lineTailTime limit how long a line takes to fill, so
+// a line followed by a long instrumental isn't filled in over the whole break
+const (
+	perCharacterTime = 250 * time.Millisecond
+	lineTailTime     = time.Second
+)
+
+// lineProgress returns how much of lyric line index has been sung, from 0 to
+// 1. LRC only times the start of each line, so the fill runs evenly from
+// there to the start of the next line.
+func (a *App) lineProgress(index int) float64 {
+	if index < 0 || index >= len(a.lyricLines) {
+		return 0
+	}
+	start, end := a.lyricLines[index].Time, a.lineEnd(index)
+	if end <= start {
+		return 1
+	}
+	return min(max(float64(a.position-start)/float64(end-start), 0), 1)
+}
+
+// lineEnd returns when lyric line index has been sung: the start of the next
+// line, or sooner when the line is short for the time it has
+func (a *App) lineEnd(index int) time.Duration {
+	start := a.lyricLines[index].Time
+	end := a.duration
+	if index+1 < len(a.lyricLines) {
+		end = a.lyricLines[index+1].Time
+	}
+	characters := len([]rune(strings.TrimSpace(a.lyricLines[index].Text)))
+	return min(end, start+time.Duration(characters)*perCharacterTime+lineTailTime)
+}
+
+// fillRows colors the first sung share of the characters across rows in the
+// sung style and the rest in the unsung one, keeping each row on its own line
+func fillRows(rows []string, sung float64, sungStyle, unsungStyle string) string {
+	total := 0
+	for i, row := range rows {
+		rows[i] = strings.TrimSpace(row)
+		total += len([]rune(rows[i]))
+	}
+	filled := int(sung*float64(total) + 0.5)
+
+	for i, row := range rows {
+		characters := []rune(row)
+		split := min(max(filled, 0), len(characters))
+		filled -= len(characters)
+		rows[i] = sungStyle + string(characters[:split]) + unsungStyle + string(characters[split:])
+	}
+	return strings.Join(rows, "\n")
+}
+
+// fillRowsRTL is fillRows for a right-to-left line, already reordered to be
+// drawn: the rows still fill top to bottom, but each from its right end
+func fillRowsRTL(rows []string, sung float64, sungStyle, unsungStyle string) string {
+	total := 0
+	for i, row := range rows {
+		rows[i] = strings.TrimSpace(row)
+		total += len([]rune(rows[i]))
+	}
+	filled := int(sung*float64(total) + 0.5)
+
+	for i, row := range rows {
+		characters := []rune(row)
+		split := len(characters) - min(max(filled, 0), len(characters))
+		filled -= len(characters)
+		rows[i] = unsungStyle + string(characters[:split]) + sungStyle + string(characters[split:])
+	}
+	return strings.Join(rows, "\n")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/overrides"
+)
+
+// lyricShiftStep is how far one press moves the lyrics against the audio
+const lyricShiftStep = 100 * time.Millisecond
+
+// applyLyricShift moves the lyrics loaded from filename by the shift chosen
+// for the current song, when they are its lyrics
+func (a *App) applyLyricShift(filename string, track *lyrics.Lyrics) {
+	if a.currentSong < 0 || a.currentSong >= len(a.songs) || a.songs[a.currentSong].LyricsPath != filename {
+		return
+	}
+	if shift := a.overrides.Get(a.songs[a.currentSong].Path).LyricShift; shift != 0 {
+		track.SetOffset(track.Offset - shift)
+	}
+}
+
+// adjustLyricShift moves the current song's lyrics later or earlier against
+// the audio and remembers it for the song
+func (a *App) adjustLyricShift(delta time.Duration) {
+	if a.currentSong < 0 || a.currentSong >= len(a.songs) {
+		return
+	}
+	song := a.songs[a.currentSong]
+	if song.LyricsPath == "" || isLyricsOnly(song) {
+		a.showToast("[yellow]This song has no lyrics to shift[white]")
+		return
+	}
+
+	shift := a.overrides.Get(song.Path).LyricShift + delta
+	err := a.overrides.Update(song.Path, func(o *overrides.Override) {
+		o.LyricShift = shift
+	})
+	if err != nil {
+		a.handleError(err, "Save Lyric Offset")
+		return
+	}
+
+	// Reload with the new shift, keeping the lines already sung
+	hits := make([]bool, len(a.lyricLines))
+	for i, line := range a.lyricLines {
+		hits[i] = line.IsHit
+	}
+	a.loadLyricsFromFile(song.LyricsPath)
+	if len(hits) == len(a.lyricLines) {
+		for i := range a.lyricLines {
+			a.lyricLines[i].IsHit = hits[i]
+		}
+	}
+
+	a.showToast("⏱ Lyrics " + formatLyricShift(shift))
+	a.updateKaraokeLyrics()
+}
+
+// formatLyricShift describes how far the lyrics are moved from their file
+func formatLyricShift(shift time.Duration) string {
+	switch {
+	case shift > 0:
+		return fmt.Sprintf("%.1fs later", shift.Seconds())
+	case shift < 0:
+		return fmt.Sprintf("%.1fs earlier", -shift.Seconds())
+	}
+	return "as timed in the file"
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/organize"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// Lyrics-only songs are LRC files with no recording. They play as a timer
+// over silence, against a click track or with an outside sound system, and
+// never start by themselves: moving onto one only cues it.
+
+// lyricsOnlyDir returns where LRC files added on their own are kept
+func lyricsOnlyDir() string {
+	return filepath.Join(homeRoot(), "lyrics-only")
+}
+
+// lyricsOnlyTail is how long a lyrics-only song keeps clicking after its
+// last line
+const lyricsOnlyTail = 5 * time.Second
+
+// isLyricsOnly reports whether song is an LRC file with no recording, which
+// plays as its lyrics against a click track
+func isLyricsOnly(song Song) bool {
+	return strings.EqualFold(filepath.Ext(song.Path), ".lrc")
+}
+
+// lyricsOnlySong reads the LRC file at path as a song of its own
+func lyricsOnlySong(path string) (Song, bool) {
+	track, err := lyrics.LoadFile(path)
+	if err != nil || len(track.Lines) == 0 {
+		return Song{}, false
+	}
+	title := track.Tags["ti"]
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return Song{
+		Title:      title,
+		Artist:     track.Tags["ar"],
+		Album:      track.Tags["al"],
+		Path:       path,
+		LyricsPath: path,
+		Duration:   track.Lines[len(track.Lines)-1].Time + lyricsOnlyTail,
+	}, true
+}
+
+// lyricsOnlySongs finds the LRC files in the library that no audio file
+// goes with, so they can be practised against the click track
+func (a *App) lyricsOnlySongs() []Song {
+	used := make(map[string]bool)
+	for _, song := range a.songs {
+		if song.LyricsPath != "" {
+			used[filepath.Clean(song.LyricsPath)] = true
+		}
+	}
+
+	var songs []Song
+	for _, root := range libraryRoots() {
+		filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() || !strings.EqualFold(filepath.Ext(path), ".lrc") {
+				return nil
+			}
+			if used[filepath.Clean(path)] || a.overrides.IsHidden(path) {
+				return nil
+			}
+			used[filepath.Clean(path)] = true
+			if song, ok := lyricsOnlySong(path); ok {
+				songs = append(songs, song)
+			}
+			return nil
+		})
+	}
+	return songs
+}
+
+// lyricsOnlyLabel marks lyrics-only songs in the song list
+func lyricsOnlyLabel(song Song) string {
+	if !isLyricsOnly(song) {
+		return ""
+	}
+	return " [gray]lyrics only[white]"
+}
+
+// playOrCue starts the current song, or cues it when it's lyrics-only so
+// it can be started in time with the band
+func (a *App) playOrCue() {
+	if a.currentSong >= 0 && a.currentSong < len(a.songs) && isLyricsOnly(a.songs[a.currentSong]) {
+		a.cueLyricsOnly(a.currentSong)
+		return
+	}
+	a.play()
+}
+
+// cueLyricsOnly stops what's playing and shows the lyrics of song i from
+// the start, ready for Space to start its timer
+func (a *App) cueLyricsOnly(i int) {
+	if a.isPlaying || a.isPaused {
+		a.stop()
+	}
+	a.currentSong = i
+	a.selectedSong = i
+
+	song := a.songs[i]
+	a.loadLyricsOnly(&song)
+	a.position = 0
+	a.duration = song.Duration
+	a.updateAllDisplays()
+	a.app.SetFocus(a.songList)
+	a.showToast("[cyan]📝 " + song.Title + " is ready - Space starts the lyrics[white]")
+}
+
+// showLyricsOnly lists the lyrics-only songs. Enter cues one, a adds an LRC
+// file and c sets the click track.
+func (a *App) showLyricsOnly() {
+	table := tview.NewTable().SetSelectable(true, false)
+	table.SetBorder(true).
+		SetTitle(" Lyrics Only - Enter cues, a adds an .lrc, c click track ").
+		SetTitleAlign(tview.AlignCenter)
+
+	for _, song := range a.songs {
+		if !isLyricsOnly(song) {
+			continue
+		}
+		label := song.Title
+		if song.Artist != "" {
+			label += " - " + song.Artist
+		}
+		row := table.GetRowCount()
+		table.SetCell(row, 0, tview.NewTableCell(tview.Escape(label)).SetReference(song.Path).SetExpansion(1))
+		table.SetCell(row, 1, tview.NewTableCell(utils.FormatDuration(song.Duration)).SetTextColor(tcell.ColorGray))
+	}
+	if table.GetRowCount() == 0 {
+		table.SetCell(0, 0, tview.NewTableCell("[gray]No lyrics-only songs yet - press a to add an .lrc file[white]").SetSelectable(false))
+	}
+
+	closeTable := func() {
+		a.pages.RemovePage("lyrics-only")
+		a.app.SetFocus(a.songList)
+	}
+	selectedSong := func() int {
+		row, _ := table.GetSelection()
+		if path, ok := table.GetCell(row, 0).GetReference().(string); ok {
+			return a.songIndex(path)
+		}
+		return -1
+	}
+
+	table.SetSelectedFunc(func(row, column int) {
+		if i := selectedSong(); i >= 0 {
+			closeTable()
+			a.cueLyricsOnly(i)
+		}
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'a':
+			closeTable()
+			a.showAddLyricsOnly()
+			return nil
+		case 'c':
+			if i := selectedSong(); i >= 0 {
+				closeTable()
+				a.showClickTrack(a.songs[i])
+			}
+			return nil
+		}
+		return event
+	})
+	table.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			closeTable()
+		}
+	})
+
+	a.pages.AddPage("lyrics-only", centered(table, 70, 20), true, true)
+	a.app.SetFocus(table)
+}
+
+// showAddLyricsOnly copies an LRC file into the library as a lyrics-only song
+func (a *App) showAddLyricsOnly() {
+	if a.denyReadOnlyMedia(homeRoot(), "Adding lyrics") {
+		return
+	}
+
+	path := tview.NewInputField().
+		SetLabel("LRC file").
+		SetFieldWidth(50)
+
+	closeAdd := func() {
+		a.pages.RemovePage("add-lyrics-only")
+		a.app.SetFocus(a.songList)
+	}
+
+	form := tview.NewForm().
+		AddFormItem(path).
+		AddButton("Add", func() {
+			src := utils.ExpandHome(strings.TrimSpace(path.GetText()))
+			song, err := a.addLyricsOnly(src)
+			if err != nil {
+				a.handleError(err, "Add Lyrics")
+				return
+			}
+			closeAdd()
+			a.showToast("[green]📝 Added " + song.Title + " to the lyrics-only songs[white]")
+			a.showLyricsOnly()
+		}).
+		AddButton("Cancel", closeAdd)
+	form.SetCancelFunc(closeAdd)
+	form.SetBorder(true).
+		SetTitle(" Add Lyrics Only ").
+		SetTitleAlign(tview.AlignCenter)
+
+	a.pages.AddPage("add-lyrics-only", centered(form, 70, 7), true, true)
+	a.app.SetFocus(form)
+}
+
+// addLyricsOnly copies the LRC file at src into lyricsOnlyDir and adds it
+// to the song list
+func (a *App) addLyricsOnly(src string) (Song, error) {
+	if !strings.EqualFold(filepath.Ext(src), ".lrc") {
+		return Song{}, fmt.Errorf("%s is not an .lrc file", filepath.Base(src))
+	}
+	if _, ok := lyricsOnlySong(src); !ok {
+		return Song{}, fmt.Errorf("no timed lyrics found in %s", filepath.Base(src))
+	}
+	if err := os.MkdirAll(lyricsOnlyDir(), 0755); err != nil {
+		return Song{}, err
+	}
+
+	dst := organize.UniquePath(filepath.Join(lyricsOnlyDir(), filepath.Base(src)))
+	if err := organize.CopyFile(src, dst); err != nil {
+		return Song{}, fmt.Errorf("cannot add %s: %w", filepath.Base(src), err)
+	}
+	song, _ := lyricsOnlySong(dst)
+
+	if err := a.added.Record([]string{dst}); err != nil {
+		a.errorLog.Add("Added Dates", err)
+	}
+	if a.currentPlaylist == "" {
+		a.songs = append(a.songs, song)
+		a.updateSongList()
+	}
+	return song, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/paths"
+)
+
+// lyricResolver looks for lyrics in the sources and order the config sets
+func (a *App) lyricResolver() *lyrics.Resolver {
+	sources := a.appConfig.LyricSources
+	if len(sources) == 0 {
+		sources = lyrics.DefaultSources
+	}
+	folder := a.appConfig.LyricsFolder
+	if folder == "" {
+		folder = paths.Data("lyrics")
+	}
+	return &lyrics.Resolver{
+		Sources:  sources,
+		Patterns: a.appConfig.LyricPatterns,
+		Folder:   folder,
+		CacheDir: paths.Cache("lyrics"),
+		Embedded: a.embeddedLyrics,
+	}
+}
+
+// embeddedLyrics returns the lyrics in a song's tags as the library index
+// read them, so finding lyrics doesn't open every song: the synchronised
+// lyrics as LRC if there are any, else the unsynchronised ones
+func (a *App) embeddedLyrics(path string) string {
+	meta, ok := a.library.Cached(path)
+	if !ok {
+		return ""
+	}
+	if meta.SyncedLyrics != "" {
+		return meta.SyncedLyrics
+	}
+	return meta.Lyrics
+}
+
+// findLyricsFile finds the lyrics file for an audio file from the lyric
+// sources, going by its file name and its title and artist
+func (a *App) findLyricsFile(audioPath, title, artist string) string {
+	return a.lyricResolver().Find(lyrics.SongInfo{Path: audioPath, Title: title, Artist: artist})
+}
+
+// lyricsSavePath returns where edited lyrics for song are saved: over the
+// file they were found in, unless that is a cached copy, which would be
+// replaced; then next to the song, unless that can't be written
+func (a *App) lyricsSavePath(song Song) string {
+	lyricsPath := a.findLyricsFile(song.Path, song.Title, song.Artist)
+	if lyricsPath == "" || a.lyricResolver().IsCached(lyricsPath) {
+		lyricsPath = sidecarLyricsPath(song.Path)
+	}
+	return a.writableLyricsPath(song, lyricsPath)
+}
+
+// writableLyricsPath returns lyricsPath, or when its folder can't be
+// written, as on a read-only drive, the same name in the lyrics folder,
+// where the lyrics are found just the same
+func (a *App) writableLyricsPath(song Song, lyricsPath string) string {
+	if a.canWrite(filepath.Dir(lyricsPath)) {
+		return lyricsPath
+	}
+	folder := a.lyricResolver().Folder
+	// Saving reports the error if the folder can't be made either
+	os.MkdirAll(folder, 0755)
+	return filepath.Join(folder, filepath.Base(sidecarLyricsPath(song.Path)))
+}
+
+// sidecarLyricsPath is the .lrc of the same name next to an audio file
+func sidecarLyricsPath(audioPath string) string {
+	return strings.TrimSuffix(audioPath, filepath.Ext(audioPath)) + ".lrc"
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/lyricsview"
+)
+
+// lyricsViewNextLines is how many upcoming lines a lyrics view shows
+const lyricsViewNextLines = 2
+
+// startLyricsView lets "tuneminal lyrics-view" in another terminal show the
+// lyrics. Another player already serving views is noted in the error log.
+func (a *App) startLyricsView() {
+	server, err := lyricsview.Listen(lyricsview.DefaultSocketPath())
+	if err != nil {
+		a.errorLog.Add("Lyrics View", err)
+		return
+	}
+	a.lyricsView = server
+}
+
+// publishLyricsView sends the lines being sung to the connected lyrics views
+func (a *App) publishLyricsView() {
+	if a.lyricsView == nil {
+		return
+	}
+
+	state := lyricsview.State{
+		Playing: a.isPlaying,
+		Paused:  a.isPaused,
+		Lyrics:  len(a.lyricLines) > 0,
+	}
+	if a.currentSong >= 0 && a.currentSong < len(a.songs) && (a.isPlaying || a.isPaused) {
+		state.Title = a.songs[a.currentSong].Title
+		state.Artist = a.songs[a.currentSong].Artist
+	}
+	if state.Lyrics {
+		active := a.findCurrentLyricIndex(a.position)
+		line := func(i int) string {
+			if i < 0 || i >= len(a.lyricLines) {
+				return ""
+			}
+			return a.lyricLines[i].Text
+		}
+		state.Previous = line(active - 1)
+		state.Current = line(active)
+		for i := active + 1; i <= active+lyricsViewNextLines && i < len(a.lyricLines); i++ {
+			state.Next = append(state.Next, line(i))
+		}
+	}
+	a.lyricsView.Publish(state)
+}
+
+// stopLyricsView disconnects the lyrics views
+func (a *App) stopLyricsView() {
+	if a.lyricsView != nil {
+		a.lyricsView.Close()
+		a.lyricsView = nil
+	}
+}
+
+// runLyricsViewCommand shows just the lyrics of the song playing in another
+// Tuneminal, big and centered, for a screen facing the singer. It waits for
+// the player to start and reconnects when it restarts.
+func runLyricsViewCommand(args []string) error {
+	flags := flag.NewFlagSet("lyrics-view", flag.ContinueOnError)
+	socket := flags.String("socket", lyricsview.DefaultSocketPath(), "socket the player serves lyrics views on")
+	big := flags.Bool("big", false, "draw the current line in block letters")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("usage: tuneminal lyrics-view [-big] [-socket PATH]")
+	}
+
+	app := tview.NewApplication()
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter).
+		SetWordWrap(true)
+	show := func(state *lyricsview.State) {
+		app.QueueUpdateDraw(func() {
+			_, _, width, height := view.GetInnerRect()
+			lines := []string{"[gray]Waiting for Tuneminal...[white]", "", "[gray::d]q to quit[white::-]"}
+			if state != nil {
+				lines = formatLyricsView(*state, *big, width-2)
+			}
+			padding := max(0, (height-len(lines))/2)
+			view.SetText(strings.Repeat("\n", padding) + strings.Join(lines, "\n"))
+		})
+	}
+
+	go func() {
+		for {
+			conn, err := lyricsview.Dial(*socket)
+			if err != nil {
+				show(nil)
+				time.Sleep(time.Second)
+				continue
+			}
+			lyricsview.Read(conn, func(state lyricsview.State) {
+				show(&state)
+			})
+			conn.Close()
+		}
+	}()
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Rune() == 'q' {
+			app.Stop()
+			return nil
+		}
+		return event
+	})
+	return app.SetRoot(view, true).Run()
+}
+
+// formatLyricsView lays out a lyrics view: the song, the line just sung,
+// the line to sing in large capitals, or block letters width columns wide
+// when big, and the lines coming up
+func formatLyricsView(state lyricsview.State, big bool, width int) []string {
+	if !state.Playing && !state.Paused {
+		return []string{"[yellow::b]♪  TUNEMINAL  ♪[white::-]", "", "[gray]Pick a song on the player[white]"}
+	}
+
+	var lines []string
+	if state.Title != "" {
+		lines = append(lines, fmt.Sprintf("[gray]%s - %s[white]", tview.Escape(state.Artist), tview.Escape(state.Title)), "", "")
+	}
+	if !state.Lyrics {
+		return append(lines, "[yellow::b]♪  NO LYRICS AVAILABLE  ♪[white::-]")
+	}
+
+	if big && !state.Paused {
+		next := ""
+		if len(state.Next) > 0 {
+			next = state.Next[0]
+		}
+		if rows := bigLyricLines(state.Previous, state.Current, next, width); rows != nil {
+			return append(lines, rows...)
+		}
+	}
+
+	lines = append(lines, "[blue::d]"+tview.Escape(state.Previous)+"[white::-]", "")
+	current := "∙∙∙"
+	if state.Current != "" {
+		current = strings.ToUpper(state.Current)
+	}
+	if state.Paused {
+		current += "  ⏸"
+	}
+	lines = append(lines, "[yellow::b]"+tview.Escape(current)+"[white::-]", "")
+	for _, next := range state.Next {
+		lines = append(lines, tview.Escape(next))
+	}
+	return lines
+}
@@ -0,0 +1,52 @@
+package main
+
+import "time"
+
+// playbackTick is how often the playing song's displays are refreshed
+const playbackTick = 100 * time.Millisecond
+
+// syncPosition reads the position from the player's clock just before it is
+// drawn, rather than using the one from the last tick, which can be most of
+// a tick old by the time the screen is updated
+func (a *App) syncPosition() {
+	if a.player != nil && a.isPlaying && !a.isPaused {
+		if a.followsClock() {
+			a.position = a.clockPosition()
+			return
+		}
+		a.position = a.player.GetPosition()
+	}
+}
+
+// scheduleLyricTransition times the switch to the next lyric line exactly,
+// when it falls before the next tick, so the highlight lands on the beat
+// instead of up to a tick late. Only the UI goroutine calls it.
+func (a *App) scheduleLyricTransition() {
+	if a.lyricTimer != nil {
+		a.lyricTimer.Stop()
+		a.lyricTimer = nil
+	}
+	if a.player == nil || !a.isPlaying || a.isPaused || a.lyricTrack == nil || a.followsClock() {
+		return
+	}
+
+	position := a.player.GetPosition()
+	next := a.lyricTrack.IndexAt(position) + 1
+	if next >= len(a.lyricLines) {
+		return
+	}
+	delay := a.lyricLines[next].Time - position
+	if delay <= 0 || delay > playbackTick {
+		return
+	}
+	a.lyricTimer = time.AfterFunc(delay, func() {
+		a.app.QueueUpdateDraw(func() {
+			if !a.isPlaying || a.isPaused {
+				return
+			}
+			a.syncPosition()
+			a.updateKaraokeLyrics()
+			a.updateScore()
+		})
+	})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+)
+
+// lyricsWidth returns the columns available for a lyric line, or 0 before
+// the panel has been drawn
+func (a *App) lyricsWidth() int {
+	_, _, width, _ := a.lyrics.GetInnerRect()
+	return max(width-2, 0)
+}
+
+// wrapLyric splits a lyric line that is wider than width into rows. A line
+// that fits on two rows is split where the halves are closest in length, so
+// it reads as one line broken in the middle rather than a long row and a
+// stray word. A width of 0 means unknown, and the line is kept whole.
+func wrapLyric(text string, width int) []string {
+	if width <= 0 || tview.TaggedStringWidth(text) <= width {
+		return []string{text}
+	}
+
+	words := strings.Fields(text)
+	best, bestWidth := 0, 0
+	for i := 1; i < len(words); i++ {
+		widest := max(
+			tview.TaggedStringWidth(strings.Join(words[:i], " ")),
+			tview.TaggedStringWidth(strings.Join(words[i:], " ")))
+		if best == 0 || widest < bestWidth {
+			best, bestWidth = i, widest
+		}
+	}
+	if best > 0 && bestWidth <= width {
+		return []string{strings.Join(words[:best], " "), strings.Join(words[best:], " ")}
+	}
+	return tview.WordWrap(text, width)
+}
+
+// rightToLeft reports whether a lyric line should be reordered to read right
+// to left, which lyrics_bidi leaves to terminals that do it themselves
+func (a *App) rightToLeft(text string) bool {
+	return a.appConfig.LyricsBidi && lyrics.IsRTL(text)
+}
+
+// lyricRows wraps a lyric line to the panel. A right-to-left line is wrapped
+// in reading order, so it starts on the top row, and each row then reordered
+// to be drawn left to right.
+func (a *App) lyricRows(text string) []string {
+	rows := wrapLyric(text, a.lyricsWidth())
+	if a.rightToLeft(text) {
+		for i, row := range rows {
+			rows[i] = lyrics.Visual(strings.TrimSpace(row))
+		}
+	}
+	return rows
+}
+
+// styleRows puts the same style on every row of a wrapped line, as the
+// lyrics panel draws each row centered on its own
+func styleRows(style string, rows []string, reset string) string {
+	for i, row := range rows {
+		rows[i] = style + strings.TrimSpace(row) + reset
+	}
+	return strings.Join(rows, "\n")
+}
+
+// formatCurrentLyric draws the line being sung as large as the panel allows:
+// in capitals between beat notes, then with the notes closer, then without
+// them, and only then split across rows. The share of it already sung, from
+// 0 to 1, is filled in. The lyrics_case, lyrics_notes and lyrics_emphasis
+// settings can keep the lyrics' own case, drop the notes or drop the bold.
+// A right-to-left line fills in from the right.
+func (a *App) formatCurrentLyric(text, beat string, sung float64) string {
+	width := a.lyricsWidth()
+	if a.appConfig.LyricsCase != "original" {
+		text = strings.ToUpper(text)
+	}
+	sungStyle, unsungStyle := a.lyricFillStyles()
+	fill, drawn := fillRows, text
+	if a.rightToLeft(text) {
+		fill, drawn = fillRowsRTL, lyrics.Visual(strings.TrimSpace(text))
+	}
+
+	if a.appConfig.LyricsNotes {
+		for _, gap := range []string{"  ", " "} {
+			line := beat + gap + text + gap + beat
+			if width <= 0 || tview.TaggedStringWidth(line) <= width {
+				return sungStyle + beat + gap + fill([]string{drawn}, sung, sungStyle, unsungStyle) + sungStyle + gap + beat + "[white::-]"
+			}
+		}
+	}
+	return fill(a.lyricRows(text), sung, sungStyle, unsungStyle) + "[white::-]"
+}
@@ -1,26 +1,53 @@
 package main
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"path/filepath"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/added"
+	"github.com/tuneminal/tuneminal/pkg/asciicast"
 	"github.com/tuneminal/tuneminal/pkg/config"
+	"github.com/tuneminal/tuneminal/pkg/diagnostics"
 	"github.com/tuneminal/tuneminal/pkg/export"
+	"github.com/tuneminal/tuneminal/pkg/history"
+	"github.com/tuneminal/tuneminal/pkg/insights"
+	"github.com/tuneminal/tuneminal/pkg/jobs"
+	"github.com/tuneminal/tuneminal/pkg/journal"
+	"github.com/tuneminal/tuneminal/pkg/libindex"
+	"github.com/tuneminal/tuneminal/pkg/loudness"
 	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/lyricsview"
 	"github.com/tuneminal/tuneminal/pkg/metadata"
+	"github.com/tuneminal/tuneminal/pkg/midiclock"
+	"github.com/tuneminal/tuneminal/pkg/osc"
+	"github.com/tuneminal/tuneminal/pkg/overrides"
+	"github.com/tuneminal/tuneminal/pkg/party"
 	"github.com/tuneminal/tuneminal/pkg/player"
 	"github.com/tuneminal/tuneminal/pkg/playlist"
+	"github.com/tuneminal/tuneminal/pkg/queue"
+	"github.com/tuneminal/tuneminal/pkg/remote"
+	"github.com/tuneminal/tuneminal/pkg/remotefs"
+	"github.com/tuneminal/tuneminal/pkg/resume"
+	"github.com/tuneminal/tuneminal/pkg/schedule"
+	"github.com/tuneminal/tuneminal/pkg/songcache"
+	"github.com/tuneminal/tuneminal/pkg/tempo"
+	"github.com/tuneminal/tuneminal/pkg/undo"
+	"github.com/tuneminal/tuneminal/pkg/update"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+	"github.com/tuneminal/tuneminal/pkg/watch"
 )
 
+// libraryDir is the directory the song library is scanned from when no
+// libraries are set up in the config
+const libraryDir = "uploads/demo"
+
 // App represents the main Tuneminal application
 type App struct {
 	app           *tview.Application
@@ -36,6 +63,7 @@ type App struct {
 	lyrics        *tview.TextView
 	progress      *tview.TextView
 	score         *tview.TextView
+	mainLayout    *tview.Flex // rebuilt when panels are shown or hidden
 	
 	// Preloader
 	preloader     *tview.TextView
@@ -56,9 +84,141 @@ type App struct {
 	// Export/Import
 	exportManager   *export.ExportManager
 
+	// LAN playlist sharing (host side, then guest side)
+	remoteServer    *remote.Server
+	sharedPlaylist  *remote.SharedPlaylist
+	shareClient     *remote.Client
+	shareHost       string
+	sharedState     *remote.SharedState
+	stopGuest       chan struct{}
+
+	// Lyrics views in other terminals, nil when another player serves them
+	lyricsView      *lyricsview.Server
+
+	// Background jobs, and the panel listing them while it is shown
+	jobs            *jobs.Manager
+	jobsView        *tview.TextView
+	jobsSelected    int
+
+	// Background lyrics fetch job
+	fetchJob        *jobs.Job
+	fetchProgress   lyrics.BatchProgress
+	fetchSummary    string
+	fetchView       *tview.TextView
+
+	// Watch folders for auto-import
+	watcher         *watch.Watcher
+
+	// MIDI clock that lyrics-only songs follow, nil when off
+	clock           *midiclock.Clock
+
+	// OSC control surface listener, nil when off
+	oscServer       *osc.Server
+
+	// Soundboard effects decoded so far, by path
+	sounds          map[string]*player.Sound
+
+	// Library integrity check in progress
+	verifyJob       *jobs.Job
+
+	// Mixtape export in progress
+	mixtapeJob      *jobs.Job
+
+	// Loudness scan in progress
+	loudnessJob     *jobs.Job
+
+	// Scheduled alarms, and the volume ramp and volume of one that went
+	// off; alarmVolume is 0 once the user has taken over
+	alarms          *schedule.Store
+	stopRamp        chan struct{}
+	alarmVolume     float64
+
+	// Screen recording as an asciicast, nil when not recording
+	recorder        *asciicast.Recorder
+
+	// Metronome tempo detection and tapping
+	tempoDetecting  map[string]bool
+	tapper          tempo.Tapper
+	tapSong         string
+
+	// Per-song overrides such as hidden files
+	overrides       *overrides.Store
+
+	// When each song first showed up in the library
+	added           *added.Store
+
+	// Metadata of library files from the last scan, and the scan reading
+	// the rest in the background
+	library         *libindex.Index
+	libraryScan     *jobs.Job
+
+	// Songs lined up to play next, and the queue while it is shown
+	playQueue       *queue.Queue
+	queuePanel      *queuePanel
+
+	// Songs started this session, and the paths previous steps back through
+	sessionPlays    []sessionPlay
+	backStack       []string
+
+	// Auto-DJ picking songs once a playlist has run out, and the fade
+	// before quitting after the last song
+	autoDJ          bool
+	endFading       bool
+
+	// Lyrics sync editor while it is open, following playback
+	syncEditor      *syncEditorSession
+
+	// Resume positions for long-form audio
+	resumeStore     *resume.Store
+	lastResumeSave  time.Time
+	journal         *journal.Journal
+	lastJournalWrite time.Time
+	partyFlowCancelled bool // the countdown was cancelled for the playing song
+	latestRelease   *update.Release // the newest release, once checked
+	insights        *insights.Store
+	lyricTimer      *time.Timer // switches to the next lyric line between ticks
+
+	// Play history and the song being played, logged when it stops
+	history         *history.Store
+	playSong        Song
+	playStarted     time.Time
+
+	// Songs sung since the app started, for performance exports
+	performances    []export.PerformanceData
+
+	// Party mode singers, handicaps and teams
+	party           *party.Session
+
+	// Lyric languages detected so far, keyed by lyrics path
+	languages       map[string]detectedLanguage
+
+	// Loudness measurements for volume normalization
+	loudness        *loudness.Store
+
+	// Errors shown to the user, kept for the diagnostics page
+	errorLog        *diagnostics.ErrorLog
+
+	// Local copies of songs from network shares
+	songCache       *songcache.Cache
+	prefetcher      songcache.Prefetcher // the next song, read ahead into memory
+
+	// Music folders on other machines and their cached listings
+	remoteLibraries []*remotefs.Library
+	remoteIndex     *remotefs.Index
+
+	// Edits Ctrl+Z and Ctrl+Y take back and make again
+	undoStack       *undo.Stack
+
+	// Upcoming line cue: the line last cued, when its flash ends and
+	// whether the bell should ring on the next draw
+	cuedLine        int
+	cueFlashUntil   time.Time
+	bellPending     bool
+
 	// State
 	songs         []Song
 	currentSong   int
+	selectedSong  int // browse cursor in the song list, apart from the playing song
 	isPlaying     bool
 	isPaused      bool
 	position      time.Duration
@@ -66,6 +226,9 @@ type App struct {
 	isLoading     bool // Prevent multiple simultaneous play attempts
 	
 	// Karaoke features
+	lyricTrack    *lyrics.Lyrics
+	duet          *lyrics.Duet // Lyrics split by singer, nil when not a duet
+	duetScores    [2]duetScore // Each singer's own score in a duet
 	lyricLines    []LyricLine
 	karaokeScore  int
 	streak        int
@@ -76,16 +239,35 @@ type App struct {
 	// Visualizer state
 	visualizerBars []int
 	beatPhase      int
+	visualizerState visualizerState
+	visualizerSince time.Time
+	pausedBars      []int
+	configDamage    error // why config.json couldn't be read at start-up, until recovered
 	spectrumColors []string
 
 	// Audio control state
 	volume         float64
 	shuffleMode    bool
-	repeatMode     bool
+	repeatMode     bool // repeat all
+	repeatOne      bool
+	shuffleOrder   []string // song paths in the order shuffle plays them
+	queuedSong     string // path of the song the player carries on into
 	
 	// Thread safety (simplified for stability)
 	// stateMutex     sync.RWMutex
 	
+	// Requests from the command line, handled once the UI is up
+	launchOptions *launchOptions
+
+	// Read-only mode: nothing in the library or config is changed
+	readOnly      bool
+	kiosk         bool // a dedicated karaoke box, see kiosk.go
+
+	// Folders whose files can be changed, probed once per library load, and
+	// whether any library folder can't be, e.g. on a read-only drive
+	writableDirs  map[string]bool
+	readOnlyMedia bool
+
 	// App state
 	showPreloader bool
 	preloaderDone bool
@@ -95,34 +277,47 @@ type App struct {
 type Song struct {
 	Title      string
 	Artist     string
+	Album      string
 	Path       string
 	LyricsPath string
 	Duration   time.Duration
+	Chapters   []metadata.Chapter
 }
 
-// LyricLine represents a single line of lyrics with timing
+// LyricLine tracks karaoke scoring state for a single line of lyrics
 type LyricLine struct {
-	Time    time.Duration
-	Text    string
-	Index   int
+	lyrics.LyricLine
 	IsActive bool
 	IsHit   bool
 }
 
 // NewApp creates a new Tuneminal application
-func NewApp() *App {
-	// Load configuration
-	appConfig, err := config.LoadConfig(config.GetConfigPath())
+func NewApp(options *launchOptions) *App {
+	// Load configuration; read-only mode doesn't save a default one
+	var appConfig *config.Config
+	var err error
+	if _, statErr := os.Stat(config.GetConfigPath()); statErr != nil && options.readOnly {
+		appConfig = config.DefaultConfig()
+	} else {
+		appConfig, err = config.LoadConfig(config.GetConfigPath())
+	}
 	if err != nil {
 		// Use default config if loading fails
 		appConfig = config.DefaultConfig()
 	}
+	// A damaged config is kept until the user says what to do with it
+	var configDamage error
+	if errors.Is(err, config.ErrDamaged) {
+		configDamage = err
+		// The defaults mustn't lift read-only or kiosk mode set by an admin
+		appConfig.ReadOnly, appConfig.Kiosk = config.Lockdown(config.GetConfigPath())
+	}
 
 	// Initialize audio player, playlist manager, lyrics editor, and export manager
 	audioPlayer := player.NewAudioPlayer()
 	playlistManager := playlist.NewPlaylistManager()
 	lyricsEditor := lyrics.NewLyricEditor()
-	exportManager := export.NewExportManager()
+	exportManager := newExportManager(appConfig)
 
 	app := &App{
 		app:           tview.NewApplication(),
@@ -131,8 +326,24 @@ func NewApp() *App {
 		playlistManager: playlistManager,
 		lyricsEditor:  lyricsEditor,
 		exportManager: exportManager,
+		resumeStore:   resume.NewStore(),
+		journal:       journal.New(),
+		overrides:     overrides.NewStore(),
+		added:         added.NewStore(),
+		library:       libindex.New(),
+		tempoDetecting: make(map[string]bool),
+		alarms:        schedule.NewStore(),
+		history:       history.NewStore(),
+		playQueue:     queue.New(),
+		insights:      insights.NewStore(),
+		party:         party.NewSession(),
+		languages:     make(map[string]detectedLanguage),
+		loudness:      loudness.NewStore(),
+		errorLog:      diagnostics.NewErrorLog(),
+		undoStack:     undo.NewStack(undoLimit),
 		songs:         []Song{},
 		currentSong:   -1,
+		selectedSong:  -1,
 		showPreloader: true,
 		preloaderDone: false,
 		karaokeScore:  0,
@@ -146,10 +357,38 @@ func NewApp() *App {
 		volume:        appConfig.DefaultVolume,
 		shuffleMode:   appConfig.ShuffleMode,
 		repeatMode:    appConfig.RepeatMode,
+		repeatOne:     appConfig.RepeatOne,
+		launchOptions: options,
+		readOnly:      options.readOnly || appConfig.ReadOnly || options.kiosk || appConfig.Kiosk,
+		kiosk:         options.kiosk || appConfig.Kiosk,
+		configDamage:  configDamage,
+	}
+	if app.readOnly {
+		// Transpose, tempo and alarms still work, just for this session
+		app.overrides.KeepInMemory()
+		app.alarms.KeepInMemory()
 	}
 	
+	app.jobs = app.newJobManager()
+	app.songCache = app.newSongCache()
+	app.openRemoteLibraries()
+	if decoded := app.newDecodeCache(); decoded != nil {
+		audioPlayer.SetDecodeCache(decoded)
+	}
 	app.setupUI()
+	app.applyAudioSettings()
 	app.loadSongs()
+	app.loadDefaultPlaylist()
+	app.startWatching()
+	app.startClockSync()
+	app.startOSC()
+	app.startAlarmScheduler()
+	app.startLyricsView()
+	app.startKiosk()
+	app.startUpdateCheck()
+	app.startVisualizerAnimation()
+	app.startConfigWatcher()
+	app.startInsightsSaver()
 	
 	return app
 }
@@ -170,6 +409,7 @@ func (a *App) setupUI() {
 	
 	// Start with preloader
 	a.app.SetRoot(a.pages, true)
+	a.app.SetAfterDrawFunc(a.afterDraw)
 	
 	// Start preloader animation
 	go a.preloaderAnimation()
@@ -217,9 +457,10 @@ func (a *App) createAllComponents() {
 		SetFieldWidth(25).
 		SetChangedFunc(a.onSearchChanged)
 	a.searchInput.SetBorder(true).
-		SetTitle("[blue]Search Songs[white]").
+		SetTitle("[blue]Search Songs (filters: lang:es artist:\"...\" album:\"...\")[white]").
 		SetTitleAlign(tview.AlignLeft).
 		SetBorderColor(tcell.ColorBlue)
+	highlightFocus(a.searchInput.Box, tcell.ColorBlue)
 	
 	// Add input capture for search field
 	a.searchInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
@@ -234,8 +475,11 @@ func (a *App) createAllComponents() {
 			a.app.SetFocus(a.songList)
 			return nil
 		} else if event.Key() == tcell.KeyTab {
-			// Tab exits search and returns focus to song list
-			a.app.SetFocus(a.songList)
+			// Tab moves on to the next panel
+			a.cycleFocus(1)
+			return nil
+		} else if event.Key() == tcell.KeyBacktab {
+			a.cycleFocus(-1)
 			return nil
 		} else if event.Key() == tcell.KeyRune && event.Rune() == '/' {
 			// '/' exits search and returns focus to song list
@@ -251,8 +495,10 @@ func (a *App) createAllComponents() {
 		SetTitle("[yellow]Music Library[white]").
 		SetTitleAlign(tview.AlignLeft).
 		SetBorderColor(tcell.ColorYellow)
+	highlightFocus(a.songList.Box, tcell.ColorYellow)
 	a.songList.SetSelectedBackgroundColor(tcell.ColorDarkBlue).
 		SetSelectedTextColor(tcell.ColorWhite)
+	a.songList.SetChangedFunc(a.onSongListChanged)
 	
 	// Now playing
 	a.nowPlaying = tview.NewTextView().
@@ -293,7 +539,8 @@ func (a *App) createAllComponents() {
 	a.lyrics.SetBorder(true).
 		SetTitle("[red]Karaoke Lyrics[white]").
 		SetTitleAlign(tview.AlignCenter).
-		SetBorderColor(tcell.ColorRed)
+		SetBorderColor(lyricsBorderColor)
+	highlightFocus(a.lyrics.Box, lyricsBorderColor)
 	
 	// Score display
 	a.score = tview.NewTextView().
@@ -316,51 +563,23 @@ func (a *App) createAllComponents() {
 
 // createMainLayout creates the main layout
 func (a *App) createMainLayout() *tview.Flex {
-	// Create main vertical layout
-	mainLayout := tview.NewFlex().SetDirection(tview.FlexRow)
-	
-	// Header
-	mainLayout.AddItem(a.header, 10, 1, false)
-	
-	// Search bar
-	mainLayout.AddItem(a.searchInput, 3, 1, false)
-	
-	// Main content area (horizontal)
-	contentArea := tview.NewFlex().SetDirection(tview.FlexColumn)
-	
-	// Left panel (songs + score)
-	leftPanel := tview.NewFlex().SetDirection(tview.FlexRow)
-	leftPanel.AddItem(a.songList, 0, 1, true)
-	leftPanel.AddItem(a.score, 6, 1, false)
-	contentArea.AddItem(leftPanel, 0, 1, true)
-	
-	// Right panel (now playing + visualizer)
-	rightPanel := tview.NewFlex().SetDirection(tview.FlexRow)
-	rightPanel.AddItem(a.nowPlaying, 0, 1, false)
-	rightPanel.AddItem(a.visualizer, 0, 1, false)
-	contentArea.AddItem(rightPanel, 0, 1, false)
+	// Create main vertical layout, filled from the panels that are shown
+	a.mainLayout = tview.NewFlex().SetDirection(tview.FlexRow)
+	a.arrangePanels()
 	
-	// Add content to main layout
-	mainLayout.AddItem(contentArea, 0, 1, true)
-	
-	// Progress bar
-	mainLayout.AddItem(a.progress, 1, 1, false)
-	
-	// Bottom panel (lyrics only - controls moved to help window)
-	mainLayout.AddItem(a.lyrics, 0, 1, false)
-	
-	// Status bar
-	mainLayout.AddItem(a.statusBar, 1, 1, false)
-	
-	return mainLayout
+	return a.mainLayout
 }
 
 // setupKeyBindings sets up comprehensive key bindings
 func (a *App) setupKeyBindings() {
 	a.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		// Check if help modal is open - if so, let it handle all input
-		if a.pages.HasPage("help") {
-			return event // Let the help modal handle input
+		// Check if help or another dialog is open - if so, let it handle all input
+		if a.isOverlayOpen() {
+			if event.Key() == tcell.KeyCtrlC {
+				a.quit()
+				return nil
+			}
+			return event // Let the dialog handle input
 		}
 		
 		// Check if search input has focus - if so, let it handle Tab and '/' normally
@@ -376,22 +595,54 @@ func (a *App) setupKeyBindings() {
 			return event
 		}
 		
+		if action, off := readOnlyAction(event); off && a.denyReadOnly(action) {
+			return nil
+		}
+		
 		switch event.Key() {
 		case tcell.KeyCtrlC, tcell.KeyEscape:
 			a.quit()
 			return nil
+		case tcell.KeyCtrlZ:
+			a.undoEdit()
+			return nil
+		case tcell.KeyCtrlY:
+			a.redoEdit()
+			return nil
+		case tcell.KeyCtrlL:
+			a.showLibraryPicker()
+			return nil
+		case tcell.KeyCtrlE:
+			a.showEqualizer()
+			return nil
+		case tcell.KeyCtrlB:
+			a.showJobs()
+			return nil
 		case tcell.KeyUp:
+			// Arrow keys scroll the lyrics when they have focus
+			if currentFocus == a.lyrics {
+				return event
+			}
 			a.navigateUp()
 			return nil
 		case tcell.KeyDown:
+			if currentFocus == a.lyrics {
+				return event
+			}
 			a.navigateDown()
 			return nil
 		case tcell.KeyEnter:
 			a.playSelectedSong()
 			return nil
 		case tcell.KeyTab:
-			// Tab to switch between search and song list (only when search doesn't have focus)
-			a.app.SetFocus(a.searchInput)
+			// Tab moves focus on through the library, lyrics and search
+			a.cycleFocus(1)
+			return nil
+		case tcell.KeyBacktab:
+			a.cycleFocus(-1)
+			return nil
+		case tcell.KeyF2, tcell.KeyF3, tcell.KeyF4, tcell.KeyF5:
+			a.togglePanel(panelKeys[event.Key()])
 			return nil
 		case tcell.KeyRight:
 			a.seekForward()
@@ -400,6 +651,11 @@ func (a *App) setupKeyBindings() {
 			a.seekBackward()
 			return nil
 		case tcell.KeyRune:
+			if pad := event.Rune() - '1'; event.Modifiers()&tcell.ModAlt != 0 && pad >= 0 && pad < soundboardPads {
+				a.playSoundboardPad(int(pad))
+				return nil
+			}
+			a.noteUsage(usageFeatures[event.Rune()])
 			switch event.Rune() {
 			case 'q':
 				a.quit()
@@ -420,7 +676,7 @@ func (a *App) setupKeyBindings() {
 				a.previous()
 				return nil
 			case '/':
-				a.app.SetFocus(a.searchInput)
+				a.focusSearch()
 				return nil
 			case 'r':
 				a.loadSongs()
@@ -449,29 +705,119 @@ func (a *App) setupKeyBindings() {
 			case 'x':
 				a.showExportDialog()
 				return nil
+			case 'b':
+				a.showChapterList()
+				return nil
+			case 'a':
+				a.showAudioSettings()
+				return nil
+			case 'E':
+				a.showMicEffects()
+				return nil
+			case 'F':
+				a.toggleBigLyrics()
+				return nil
+			case 'T':
+				a.showPartyFlow()
+				return nil
+			case 'C':
+				a.cancelPartyFlow()
+				return nil
+			case 'I':
+				a.showUpdate()
+				return nil
+			case 'Y':
+				a.showInsights()
+				return nil
+			case 'X':
+				a.toggleVisualizer()
+				return nil
+			case 'J':
+				a.showLyricsOnly()
+				return nil
+			case 'z':
+				a.showSoundboard()
+				return nil
+			case 'u':
+				a.showQueue()
+				return nil
+			case 'Q':
+				a.enqueueSong(a.selectedIndex(), false)
+				return nil
+			case 'd':
+				a.showPlayHistory()
+				return nil
+			case 'P':
+				a.showShareMenu()
+				return nil
+			case 'y':
+				a.importLyricsFromClipboard()
+				return nil
+			case 'L':
+				a.showLyricsCoverage()
+				return nil
+			case 'H':
+				a.hideSelectedSong()
+				return nil
+			case 'U':
+				a.showHiddenSongs()
+				return nil
+			case 'W':
+				a.showWatchSettings()
+				return nil
+			case 'O':
+				a.showOrganizeLibrary()
+				return nil
+			case 'V':
+				a.verifyLibrary()
+				return nil
+			case 'M':
+				a.toggleMetronome()
+				return nil
+			case 't':
+				a.tapTempo()
+				return nil
+			case 'G':
+				a.toggleRecording()
+				return nil
+			case 'Z':
+				a.showAlarms()
+				return nil
+			case 'w':
+				a.showRecap()
+				return nil
+			case 'g':
+				a.showParty()
+				return nil
+			case 'K':
+				a.showReadiness()
+				return nil
+			case 'D':
+				a.toggleDuetLayout()
+				return nil
+			case '[':
+				a.adjustTranspose(-1)
+				return nil
+			case ']':
+				a.adjustTranspose(1)
+				return nil
+			case '{':
+				a.adjustLyricShift(-lyricShiftStep)
+				return nil
+			case '}':
+				a.adjustLyricShift(lyricShiftStep)
+				return nil
 			case '1', '2', '3', '4', '5', '6', '7', '8', '9':
 				// Quick song selection - jump to song number
-				songIndex := int(event.Rune() - '1')
-				if songIndex >= 0 && songIndex < len(a.songs) {
-					a.currentSong = songIndex
-					a.updateSongList()
-					a.updateNowPlaying()
-					a.updateKaraokeLyrics()
-					a.app.SetFocus(a.songList)
-				}
+				a.selectSong(int(event.Rune() - '1'))
 				return nil
 			case '0':
 				// Jump to last song
-				if len(a.songs) > 0 {
-					a.currentSong = len(a.songs) - 1
-					a.updateSongList()
-					a.updateNowPlaying()
-					a.updateKaraokeLyrics()
-					a.app.SetFocus(a.songList)
-				}
+				a.selectSong(len(a.songs) - 1)
 				return nil
 			case 'v':
 				// Quick volume toggle (mute/unmute)
+				a.stopVolumeRamp()
 				if a.volume > 0 {
 					a.volume = 0
 				} else {
@@ -497,8 +843,26 @@ func (a *App) setupKeyBindings() {
 				return nil
 			case 'i':
 				// Show song information
+				a.showSongInfo()
+				return nil
+			case 'o':
+				// Actions for the selected song
+				a.showSongMenu()
+				return nil
+			case 'N':
+				// Songs added lately, newest first
+				a.showRecentlyAdded()
+				return nil
+			case 'A':
+				// Everything by the playing song's artist
 				if a.currentSong >= 0 && a.currentSong < len(a.songs) {
-					a.showSongInfo()
+					a.jumpToArtist(a.songs[a.currentSong])
+				}
+				return nil
+			case 'B':
+				// The rest of the playing song's album
+				if a.currentSong >= 0 && a.currentSong < len(a.songs) {
+					a.jumpToAlbum(a.songs[a.currentSong])
 				}
 				return nil
 			case 'k':
@@ -512,6 +876,7 @@ func (a *App) setupKeyBindings() {
 				a.accuracy = 0.0
 				a.hitLyrics = 0
 				a.totalLyrics = 0
+				a.resetDuetScores()
 				a.updateScore()
 				a.showMessage("🎯 Scores cleared!")
 				return nil
@@ -624,153 +989,129 @@ func (a *App) preloaderAnimation() {
 				a.updateAllDisplays()
 				// Force focus to song list
 				a.app.SetFocus(a.songList)
+				a.handleLaunchOptions()
+				a.offerConfigRecovery()
+				a.offerJournalResume()
 			})
 		}
 	}
 }
 
-// findLyricsFile finds the corresponding lyrics file for an audio file
-func (a *App) findLyricsFile(audioPath string) string {
-	// Replace audio extension with .lrc
-	ext := filepath.Ext(audioPath)
-	lyricsPath := strings.TrimSuffix(audioPath, ext) + ".lrc"
-	
-	// Check if lyrics file exists
-	if _, err := os.Stat(lyricsPath); err == nil {
-		return lyricsPath
-	}
-	
-	// Try alternative naming patterns
-	baseName := strings.TrimSuffix(filepath.Base(audioPath), ext)
-	dir := filepath.Dir(audioPath)
-	
-	// Try different patterns
-	patterns := []string{
-		filepath.Join(dir, baseName + ".lrc"),
-		filepath.Join(dir, strings.ReplaceAll(baseName, "_", " ") + ".lrc"),
-		filepath.Join(dir, strings.ReplaceAll(baseName, "_", "-") + ".lrc"),
-	}
-	
-	for _, pattern := range patterns {
-		if _, err := os.Stat(pattern); err == nil {
-			return pattern
-		}
-	}
-	
-	return ""
-}
-
-// loadSongs loads songs with real metadata from files
+// loadSongs loads songs with real metadata from files. Files the library
+// index already knows are listed straight away; new and changed ones are
+// read in the background and join the list as they are.
 func (a *App) loadSongs() {
-	// Scan directory for real audio files with metadata
-	songMetadata, err := metadata.ScanDirectory("uploads/demo")
-	if err != nil {
-		return
+	a.checkLibraryWritable()
+
+	// Scan each library folder for real audio files with metadata; a folder
+	// that can't be read, such as an unplugged drive, leaves the others listed
+	var songMetadata []*metadata.SongMetadata
+	var stale []string
+	for _, root := range libraryRoots() {
+		known, changed, err := a.library.Scan(root)
+		if err != nil {
+			a.errorLog.Add("Library", err)
+			continue
+		}
+		songMetadata = append(songMetadata, known...)
+		stale = append(stale, changed...)
 	}
 	
 	// Convert metadata to app songs
 	a.songs = []Song{}
 	
+	found := make([]string, 0, len(songMetadata)+len(stale))
 	for _, meta := range songMetadata {
-		appSong := Song{
-			Title:      meta.Title,
-			Artist:     meta.Artist,
-			Path:       meta.Path,
-			LyricsPath: a.findLyricsFile(meta.Path),
-			Duration:   meta.Duration,
+		found = append(found, meta.Path)
+	}
+	found = append(found, stale...)
+	if err := a.added.Record(found); err != nil {
+		a.errorLog.Add("Added Dates", err)
+	}
+	
+	for _, meta := range songMetadata {
+		if a.overrides.IsHidden(meta.Path) {
+			continue
 		}
-		a.songs = append(a.songs, appSong)
+		a.songs = append(a.songs, a.songFromMetadata(meta))
 	}
+	a.songs = append(a.songs, a.lyricsOnlySongs()...)
+	a.songs = append(a.songs, a.cachedRemoteSongs()...)
+	a.refreshRemoteLibraries()
 	
 	// Set default selection to first song if available
 	if len(a.songs) > 0 {
 	a.currentSong = 0
+	a.selectedSong = 0
 	}
 	
 	// Update displays
 	a.updateAllDisplays()
+	a.scanLibrary(stale)
 }
 
 // loadDemoLyrics loads demo lyrics with timing
 func (a *App) loadDemoLyrics() {
-	a.lyricLines = []LyricLine{
-		{Time: 0 * time.Second, Text: "Welcome to Tuneminal Karaoke!", Index: 0, IsActive: false, IsHit: false},
-		{Time: 2 * time.Second, Text: "", Index: 1, IsActive: false, IsHit: false},
-		{Time: 3 * time.Second, Text: "This is a demo song", Index: 2, IsActive: false, IsHit: false},
-		{Time: 5 * time.Second, Text: "For the Tuneminal app", Index: 3, IsActive: false, IsHit: false},
-		{Time: 7 * time.Second, Text: "Karaoke in your terminal", Index: 4, IsActive: false, IsHit: false},
-		{Time: 9 * time.Second, Text: "It's really quite a snap", Index: 5, IsActive: false, IsHit: false},
-		{Time: 11 * time.Second, Text: "", Index: 6, IsActive: false, IsHit: false},
-		{Time: 12 * time.Second, Text: "Chorus", Index: 7, IsActive: false, IsHit: false},
-		{Time: 14 * time.Second, Text: "Sing along with me", Index: 8, IsActive: false, IsHit: false},
-		{Time: 16 * time.Second, Text: "In your terminal today", Index: 9, IsActive: false, IsHit: false},
-		{Time: 18 * time.Second, Text: "Tuneminal makes it easy", Index: 10, IsActive: false, IsHit: false},
-		{Time: 20 * time.Second, Text: "To karaoke the Go way", Index: 11, IsActive: false, IsHit: false},
-		{Time: 22 * time.Second, Text: "", Index: 12, IsActive: false, IsHit: false},
-		{Time: 23 * time.Second, Text: "Verse 2", Index: 13, IsActive: false, IsHit: false},
-		{Time: 25 * time.Second, Text: "No need for fancy GUIs", Index: 14, IsActive: false, IsHit: false},
-		{Time: 27 * time.Second, Text: "Just terminal and text", Index: 15, IsActive: false, IsHit: false},
-		{Time: 29 * time.Second, Text: "Tuneminal brings the music", Index: 16, IsActive: false, IsHit: false},
-		{Time: 31 * time.Second, Text: "To your command line next", Index: 17, IsActive: false, IsHit: false},
-		{Time: 33 * time.Second, Text: "", Index: 18, IsActive: false, IsHit: false},
-		{Time: 34 * time.Second, Text: "Thank you for using Tuneminal!", Index: 19, IsActive: false, IsHit: false},
+	demo := []string{
+		"Welcome to Tuneminal Karaoke!",
+		"",
+		"This is a demo song",
+		"For the Tuneminal app",
+		"Karaoke in your terminal",
+		"It's really quite a snap",
+		"",
+		"Chorus",
+		"Sing along with me",
+		"In your terminal today",
+		"Tuneminal makes it easy",
+		"To karaoke the Go way",
+		"",
+		"Verse 2",
+		"No need for fancy GUIs",
+		"Just terminal and text",
+		"Tuneminal brings the music",
+		"To your command line next",
+		"",
+		"Thank you for using Tuneminal!",
+	}
+	times := []int{0, 2, 3, 5, 7, 9, 11, 12, 14, 16, 18, 20, 22, 23, 25, 27, 29, 31, 33, 34}
+
+	track := lyrics.New()
+	for i, text := range demo {
+		track.Lines = append(track.Lines, lyrics.LyricLine{
+			Time:  time.Duration(times[i]) * time.Second,
+			Text:  text,
+			Index: i,
+		})
 	}
+	a.setLyrics(track)
 }
 
 // loadLyricsFromFile loads lyrics from an LRC file
 func (a *App) loadLyricsFromFile(filename string) {
-	file, err := os.Open(filename)
-	if err != nil {
-		// If file doesn't exist, use demo lyrics
+	track, err := lyrics.LoadFile(filename)
+	if err != nil || len(track.Lines) == 0 {
+		// If the file is missing or empty, use demo lyrics
 		a.loadDemoLyrics()
 		return
 	}
-	defer file.Close()
 
-	a.lyricLines = []LyricLine{}
-	scanner := bufio.NewScanner(file)
-	index := 0
-	
-	// Regex to match LRC time format [mm:ss.xx]
-	timeRegex := regexp.MustCompile(`\[(\d{2}):(\d{2})\.(\d{2})\]`)
-	
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		
-		// Skip empty lines and metadata lines
-		if line == "" || strings.HasPrefix(line, "[") && !timeRegex.MatchString(line) {
-			continue
-		}
-		
-		// Extract time and text
-		matches := timeRegex.FindStringSubmatch(line)
-		if len(matches) == 4 {
-			minutes, _ := strconv.Atoi(matches[1])
-			seconds, _ := strconv.Atoi(matches[2])
-			centiseconds, _ := strconv.Atoi(matches[3])
-			
-			time := time.Duration(minutes)*time.Minute + 
-					time.Duration(seconds)*time.Second + 
-					time.Duration(centiseconds)*10*time.Millisecond
-			
-			// Extract text after the time tag
-			text := timeRegex.ReplaceAllString(line, "")
-			text = strings.TrimSpace(text)
-			
-			a.lyricLines = append(a.lyricLines, LyricLine{
-				Time:     time,
-				Text:     text,
-				Index:    index,
-				IsActive: false,
-				IsHit:    false,
-			})
-			index++
-		}
+	a.applyLyricShift(filename, track)
+	a.setLyrics(track)
+}
+
+// setLyrics makes track the active lyrics and resets per-line karaoke state
+func (a *App) setLyrics(track *lyrics.Lyrics) {
+	// Duet lyrics are shown without their singer prefixes
+	a.duet = lyrics.SplitDuet(track)
+	if a.duet != nil {
+		track = a.duet.All
 	}
-	
-	// If no lyrics were loaded, use demo lyrics
-	if len(a.lyricLines) == 0 {
-		a.loadDemoLyrics()
+	a.lyricTrack = track
+	a.cuedLine = -1
+	a.lyricLines = make([]LyricLine, len(track.Lines))
+	for i, line := range track.Lines {
+		a.lyricLines[i] = LyricLine{LyricLine: line}
 	}
 }
 
@@ -805,33 +1146,77 @@ func (a *App) updateHeader() {
 
 // updateSongList updates the song list display
 func (a *App) updateSongList() {
-	a.songList.Clear()
-	
+	titles := make([]string, len(a.songs))
 	for i, song := range a.songs {
-		title := fmt.Sprintf("%s - %s [%s]", song.Title, song.Artist, formatDuration(song.Duration))
-		
-		// Add status prefix
-		if i == a.currentSong {
-			if a.isPlaying {
-				if a.isPaused {
-					title = "⏸ " + title
-				} else {
-					title = "▶ " + title
-				}
-			} else {
-				title = "● " + title
+		titles[i] = a.songStatusPrefix(i) + fmt.Sprintf("%s - %s [%s]%s", song.Title, song.Artist, utils.FormatDuration(song.Duration), a.languageLabel(song)+lyricsOnlyLabel(song))
+	}
+
+	// When the list already holds these songs, only the status icons change,
+	// so they're updated in place. Rebuilding it on every tick would flicker
+	// and lose the scroll position.
+	if !a.songListMatches(titles) {
+		offset, _ := a.songList.GetOffset()
+		a.songList.Clear()
+		for _, title := range titles {
+			a.songList.AddItem(title, "", 0, nil)
+		}
+		a.songList.SetOffset(offset, 0)
+	} else {
+		for i, title := range titles {
+			if main, _ := a.songList.GetItemText(i); main != title {
+				a.songList.SetItemText(i, title, "")
 			}
-		} else {
-			title = "  " + title
 		}
-		
-		a.songList.AddItem(title, "", 0, nil)
 	}
 	
-	// Set the current selection
-	if a.currentSong >= 0 && a.currentSong < len(a.songs) {
-		a.songList.SetCurrentItem(a.currentSong)
+	// Keep the browse cursor where it was, which needn't be the playing song
+	if selected := a.selectedIndex(); selected >= 0 && selected < len(a.songs) && selected != a.songList.GetCurrentItem() {
+		a.songList.SetCurrentItem(selected)
 	}
+	// Keep guests of a shared playlist in sync
+	a.publishSharedPlaylist()
+}
+
+// songStatusPrefixes are the icons in front of songs in the list
+var songStatusPrefixes = []string{"▶ ", "⏸ ", "● ", "  "}
+
+// songStatusPrefix returns the icon in front of song i: playing, paused,
+// stopped on it, or blank
+func (a *App) songStatusPrefix(i int) string {
+	if i != a.currentSong {
+		return "  "
+	}
+	if !a.isPlaying {
+		return "● "
+	}
+	if a.isPaused {
+		return "⏸ "
+	}
+	return "▶ "
+}
+
+// songListMatches reports whether the list shows the songs with these
+// titles, whatever their status icons, so it needn't be rebuilt. A search
+// result or another view of the list doesn't match.
+func (a *App) songListMatches(titles []string) bool {
+	if a.songList.GetItemCount() != len(titles) {
+		return false
+	}
+	withoutPrefix := func(title string) string {
+		for _, prefix := range songStatusPrefixes {
+			if rest, ok := strings.CutPrefix(title, prefix); ok {
+				return rest
+			}
+		}
+		return title
+	}
+	for i, title := range titles {
+		main, secondary := a.songList.GetItemText(i)
+		if secondary != "" || withoutPrefix(main) != withoutPrefix(title) {
+			return false
+		}
+	}
+	return true
 }
 
 // updateNowPlaying updates the now playing display
@@ -844,12 +1229,25 @@ func (a *App) updateNowPlaying() {
 	song := a.songs[a.currentSong]
 	
 	// Get current volume percentage
-	volumePercent := int(a.volume * 100)
+	volumePercent := int(a.playbackVolume() * 100)
 
 	// Create now playing text
 	playlistInfo := ""
+	if currentLibrary != nil {
+		playlistInfo = fmt.Sprintf("\n[white]Library: [cyan]%s[white]", currentLibrary.Name)
+	}
 	if a.currentPlaylist != "" {
-		playlistInfo = fmt.Sprintf("\n[white]Playlist: [cyan]%s[white]", a.currentPlaylist)
+		playlistInfo += fmt.Sprintf("\n[white]Playlist: [cyan]%s[white]", a.currentPlaylist)
+	}
+	if queue := a.queueSummary(); queue != "" {
+		playlistInfo += fmt.Sprintf("\n[white]Queue: [cyan]%s[white]", queue)
+	}
+	if key := a.keyStatus(); key != "" {
+		playlistInfo += fmt.Sprintf("\n[white]Key: [cyan]%s[white]", key)
+	}
+	if chapter := a.currentChapterIndex(song); chapter >= 0 {
+		playlistInfo += fmt.Sprintf("\n[white]Chapter: [cyan]%s[white] (%d/%d)",
+			chapterTitle(song.Chapters[chapter], chapter), chapter+1, len(song.Chapters))
 	}
 
 	text := fmt.Sprintf(`[white]Title: [yellow]%s[white]
@@ -861,30 +1259,51 @@ Position: [yellow]%s[white]
 
 [white]Volume: [cyan]%d%%[white]
 [white]Repeat: [cyan]%s[white]
-[white]Shuffle: [cyan]%s[white]`,
+[white]Shuffle: [cyan]%s[white]
+[white]Metronome: [cyan]%s[white]`,
 		song.Title,
 		song.Artist,
-		formatDuration(song.Duration),
-		formatDuration(a.position),
+		utils.FormatDuration(song.Duration),
+		utils.FormatDuration(a.position),
 		a.getStatusText(),
 		playlistInfo,
 		volumePercent,
 		a.getRepeatModeText(),
-		a.getShuffleModeText())
+		a.getShuffleModeText(),
+		a.metronomeStatus())
 	
 	a.nowPlaying.SetText(text)
 }
 
 // updateKaraokeLyrics creates a beautiful 5-line auto-scrolling karaoke display
 func (a *App) updateKaraokeLyrics() {
+	a.publishLyricsView()
+	if display := a.createPartyFlowDisplay(); display != "" {
+		a.lyrics.SetText(display)
+		return
+	}
+	if display := a.createSongNotesDisplay(); display != "" {
+		a.lyrics.SetText(display)
+		return
+	}
 	if len(a.lyricLines) == 0 {
 		a.lyrics.SetText(a.createEmptyLyricsDisplay())
 		return
 	}
+	if a.showDuetLayout() {
+		a.lyrics.SetText(a.createDuetLyricsDisplay())
+		return
+	}
 	
 	// Find current active lyric line
 	currentTime := a.position
 	activeIndex := a.findCurrentLyricIndex(currentTime)
+	if a.showBigLyrics() {
+		if display := a.createBigLyricsDisplay(activeIndex); display != "" {
+			a.lyrics.SetText(display)
+			return
+		}
+	}
 	
 	// Create 5-line display with current line in center (index 2)
 	display := a.createFiveLineLyricsDisplay(activeIndex)
@@ -894,17 +1313,10 @@ func (a *App) updateKaraokeLyrics() {
 
 // findCurrentLyricIndex finds the index of the currently active lyric
 func (a *App) findCurrentLyricIndex(currentTime time.Duration) int {
-	activeIndex := -1
-	
-	for i, lyric := range a.lyricLines {
-		if currentTime >= lyric.Time {
-			activeIndex = i
-		} else {
-			break
-		}
+	if a.lyricTrack == nil {
+		return -1
 	}
-	
-	return activeIndex
+	return a.lyricTrack.IndexAt(currentTime)
 }
 
 // createFiveLineLyricsDisplay creates a beautiful 5-line karaoke display that ALWAYS shows 5 lines
@@ -917,11 +1329,20 @@ func (a *App) createFiveLineLyricsDisplay(activeIndex int) string {
 	// ALWAYS create exactly 5 lines: 2 previous, 1 current, 2 upcoming
 	lines := []string{}
 	
+	// In a long break the countdown takes the center, between the line just
+	// sung and the one coming up
+	left, progress, inBreak := a.instrumentalBreak(activeIndex)
+	
 	for line := 0; line < 5; line++ {
 		lyricIndex := activeIndex + (line - 2) // Center current line at position 2
+		if inBreak && line < 2 {
+			lyricIndex++
+		}
 		var formattedLine string
 		
-		if line == 0 || line == 4 {
+		if inBreak && line == 2 {
+			formattedLine = a.formatInstrumentalBreak(activeIndex, left, progress)
+		} else if line == 0 || line == 4 {
 			// Top and bottom padding lines (very subtle)
 			formattedLine = a.formatLyricLine(lyricIndex, "padding")
 		} else if line == 1 {
@@ -999,22 +1420,28 @@ func (a *App) formatLyricLine(index int, lineType string) string {
 		if a.position.Milliseconds()%1000 < 500 {
 			beatIndicator = "♫"
 		}
-		// Create a large, prominent display with uppercase text
-		upperText := strings.ToUpper(text)
-		return fmt.Sprintf("[yellow::b]%s  %s  %s[white::-]", beatIndicator, upperText, beatIndicator)
+		// In a duet the notes take the singer's color
+		if color := a.singerColor(index); color != "" {
+			beatIndicator = "[" + color + "]" + beatIndicator
+		}
+		// Create a large, prominent display with uppercase text, as large as fits
+		return a.formatCurrentLyric(text, beatIndicator, a.lineProgress(index))
 		
 	case "previous":
 		// Previous line: Smaller, completed style
-		return fmt.Sprintf("[blue::d]%s[white::-]", text)
+		return styleRows("[blue::d]", a.lyricRows(text), "[white::-]")
 		
 	case "next":
-		// Next line: Normal size, upcoming
-		return fmt.Sprintf("[white]%s", text)
+		// Next line: Normal size, upcoming, in the singer's color in a duet
+		if color := a.singerColor(index); color != "" {
+			return styleRows("["+color+"]", a.lyricRows(text), "[white]")
+		}
+		return styleRows("[white]", a.lyricRows(text), "")
 		
 	case "padding":
 		// Padding lines: Very subtle but visible on all backgrounds
 		if text != "" {
-			return fmt.Sprintf("[gray]%s[white::-]", text)
+			return styleRows("[gray]", a.lyricRows(text), "[white::-]")
 		}
 		return "[gray]∙∙∙[white::-]"
 		
@@ -1138,6 +1565,11 @@ func (a *App) calculateHitChance(lyricIndex int) float64 {
 	if totalChance > 0.95 {
 		totalChance = 0.95 // Cap at 95%
 	}
+
+	// With a live mic, lines only land when someone is singing
+	if singing, live := a.micSinging(); live && !singing {
+		totalChance *= 0.2
+	}
 	
 	return totalChance
 }
@@ -1167,6 +1599,7 @@ func (a *App) hitLyric(lyricIndex int) {
 	
 	points := int(float64(basePoints) * streakMultiplier) + beatBonus
 	a.karaokeScore += points
+	a.scoreDuetHit(lyricIndex, beatBonus)
 	
 	// Update streak
 	a.streak++
@@ -1209,6 +1642,16 @@ func (a *App) createScoreDisplay() string {
 	// Accuracy with performance indicator
 	accuracyColor := a.getAccuracyColor()
 	display.WriteString(fmt.Sprintf("%sAccuracy: %.1f%%[white]\n\n", accuracyColor, a.accuracy))
+
+	// Each singer's own score in a duet
+	if duet := a.createDuetScoreDisplay(); duet != "" {
+		display.WriteString(duet + "\n")
+	}
+
+	// Mic level, when one is capturing
+	if meter := a.micMeter(); meter != "" {
+		display.WriteString(meter + "\n\n")
+	}
 	
 	// Dynamic status and achievements
 	status := a.getPerformanceStatus()
@@ -1312,28 +1755,6 @@ func (a *App) getPerformanceTips() string {
 	}
 }
 
-// updateVisualizer creates dynamic audio visualizations
-func (a *App) updateVisualizer() {
-	if !a.isPlaying {
-		// Static display when not playing
-		a.visualizer.SetText(`[white]♪ Audio Spectrum ♪
-
-[dim]   ░░░░  ░░░░  ░░░░  ░░░░  ░░░░  ░░░░
-   ░░░░  ░░░░  ░░░░  ░░░░  ░░░░  ░░░░
-   ░░░░  ░░░░  ░░░░  ░░░░  ░░░░  ░░░░
-   ░░░░  ░░░░  ░░░░  ░░░░  ░░░░  ░░░░
-   ░░░░  ░░░░  ░░░░  ░░░░  ░░░░  ░░░░
-
-  Bass   Low   Mid  High  Treble Ultra[white]`)
-		return
-	}
-	
-	// Dynamic visualization during playback
-	a.generateVisualizerData()
-	display := a.createVisualizerDisplay()
-	a.visualizer.SetText(display)
-}
-
 // generateVisualizerData creates dynamic audio visualization data
 func (a *App) generateVisualizerData() {
 	// Simulate audio analysis with position-based patterns
@@ -1375,16 +1796,12 @@ func (a *App) generateVisualizerData() {
 	}
 }
 
-// createVisualizerDisplay builds the visual representation
-func (a *App) createVisualizerDisplay() string {
+// createVisualizerDisplay builds the visual representation of the bars,
+// under title and over status
+func (a *App) createVisualizerDisplay(title, status string) string {
 	var display strings.Builder
 	
-	// Title with beat indicator
-	beatIndicator := "♪"
-	if a.beatPhase%2 == 0 {
-		beatIndicator = "♫"
-	}
-	display.WriteString(fmt.Sprintf("[white]%s Live Audio Spectrum %s\n\n", beatIndicator, beatIndicator))
+	display.WriteString("[white]" + title + "\n\n")
 	
 	// Draw spectrum bars (8 rows, 12 columns)
 	for row := 7; row >= 0; row-- { // Top to bottom
@@ -1411,9 +1828,6 @@ func (a *App) createVisualizerDisplay() string {
 	// Frequency labels
 	display.WriteString("\n Bass  Low  Mid  Mid  High High Treb Treb  Ultr Ultr  Air  Air\n")
 	
-	// Add dynamic status
-	intensity := a.calculateVisualizerIntensity()
-	status := a.getIntensityStatus(intensity)
 	display.WriteString(fmt.Sprintf("\n[white]%s[white]", status))
 	
 	return display.String()
@@ -1555,8 +1969,8 @@ func (a *App) updateProgress() {
 	}
 	
 	// Create beautiful time display with decorative elements
-	currentTime := formatDuration(a.position)
-	totalTime := formatDuration(a.duration)
+	currentTime := utils.FormatDuration(a.position)
+	totalTime := utils.FormatDuration(a.duration)
 	
 	// Build the complete progress display
 	progressText := fmt.Sprintf("%s [white]%s[cyan::b] %s [white]/ [cyan::b]%s [white]%s %s %s[white]", 
@@ -1579,6 +1993,20 @@ func (a *App) updateStatus() {
 		len(a.songs), 
 		a.getStatusText(),
 		a.karaokeScore)
+	if singer, ok := a.party.Current(); ok {
+		status = fmt.Sprintf("[white]🎤 Up: [yellow]%s[white] | %s", singer.Name, status)
+	}
+	if end := a.playlistEndStatus(); end != "" {
+		status += " | " + end
+	}
+	if running := a.jobStatus(); running != "" {
+		status += " | " + running
+	}
+	if a.readOnly {
+		status = "[yellow]🔒 Read-only[white] | " + status
+	} else if a.readOnlyMedia {
+		status = "[yellow]🔒 Read-only media[white] | " + status
+	}
 	
 	a.statusBar.SetText(status)
 }
@@ -1596,7 +2024,10 @@ func (a *App) getStatusText() string {
 
 // getRepeatModeText returns the repeat mode display text
 func (a *App) getRepeatModeText() string {
-	if a.repeatMode {
+	switch {
+	case a.repeatOne:
+		return "One"
+	case a.repeatMode:
 		return "All"
 	}
 	return "Off"
@@ -1619,28 +2050,7 @@ func (a *App) showHelp() {
 	}
 	
 	// Create comprehensive help modal
-	helpText := `[cyan]═══ BASIC CONTROLS ═══[white]                    [cyan]═══ ADVANCED FEATURES ═══[white]
-[yellow]Space[white] - Play/Pause current song               [yellow]E[white] - Edit lyrics for current song
-[yellow]s[white] - Stop playback and reset position          [yellow]F[white] - File management (move/rename/delete)
-[yellow]↑/↓[white] - Navigate between songs                   [yellow]X[white] - Export data (performance/library)
-[yellow]Enter[white] - Play the selected song                [yellow]J[white] - Jump to specific time (during playback)
-[yellow]Tab[white] - Switch between search and song list     [yellow]I[white] - Show detailed song information
-[yellow]/[white] - Focus on search box                       [yellow]K[white] - Toggle karaoke display mode
-[yellow]ESC[white] - Clear search and return to song list    [yellow]C[white] - Clear all scores and start fresh
-
-[cyan]═══ AUDIO CONTROLS ═══[white]                   [cyan]═══ QUICK ACCESS ═══[white]
-[yellow]+/-[white] - Increase/Decrease volume               [yellow]1-9[white] - Jump to song by number (1-9)
-[yellow]R[white] - Toggle repeat mode                       [yellow]0[white] - Jump to last song
-[yellow]S[white] - Toggle shuffle mode                      [yellow]V[white] - Toggle mute/unmute
-[yellow]←/→[white] - Seek backward/forward                   [yellow]M[white] - Mark song as favorite
-[yellow]r[white] - Reload song library from files           [yellow]L[white] - Focus on lyrics panel
-
-[cyan]═══ KARAOKE FEATURES ═══[white]
-• [green]Real-time lyrics[white] highlight with the music • [green]Live scoring[white] system with accuracy tracking
-• [green]Streak system[white] for consecutive hits        • [green]Audio visualizer[white] responds to music
-• [green]Performance stats[white] and export capabilities • [green]5-line centered[white] lyrics display
-
-[white]🎵 [yellow]Press [red]H[yellow], [red]Q[yellow], or [red]ESC[yellow] to close this help menu[white] 🎵`
+	helpText := formatHelp(a.readOnly)
 
 	// Create a TextView for better control over sizing
 	helpView := tview.NewTextView().
@@ -1673,6 +2083,13 @@ func (a *App) showHelp() {
 					a.player.Resume()
 				}
 				return nil
+			case 'd', 'D':
+				a.pages.RemovePage("help")
+				if wasPlaying && a.player != nil {
+					a.player.Resume()
+				}
+				a.showDiagnostics()
+				return nil
 			}
 		}
 		return event // Let other keys pass through
@@ -1707,6 +2124,13 @@ func (a *App) showHelp() {
 					a.player.Resume()
 				}
 				return nil
+			case 'd', 'D':
+				a.pages.RemovePage("help")
+				if wasPlaying && a.player != nil {
+					a.player.Resume()
+				}
+				a.showDiagnostics()
+				return nil
 			}
 		}
 		return event // Let other keys pass through
@@ -1717,25 +2141,16 @@ func (a *App) showHelp() {
 }
 
 // Navigation functions
+// They only move the browse cursor, so a playing song keeps playing
 func (a *App) navigateUp() {
-	if a.currentSong > 0 {
-		a.currentSong--
-		a.updateSongList()
-		a.updateNowPlaying()
-		a.updateKaraokeLyrics()
-		// Ensure focus stays on song list
-		a.app.SetFocus(a.songList)
+	if selected := a.selectedIndex(); selected > 0 {
+		a.selectSong(selected - 1)
 	}
 }
 
 func (a *App) navigateDown() {
-	if a.currentSong < len(a.songs)-1 {
-		a.currentSong++
-		a.updateSongList()
-	a.updateNowPlaying()
-	a.updateKaraokeLyrics()
-		// Ensure focus stays on song list
-		a.app.SetFocus(a.songList)
+	if selected := a.selectedIndex(); selected < len(a.songs)-1 {
+		a.selectSong(selected + 1)
 	}
 }
 
@@ -1746,12 +2161,9 @@ func (a *App) playSelectedSong() {
 		return
 	}
 	
-	if a.currentSong >= 0 && a.currentSong < len(a.songs) {
-		// Get the selected song index from the song list
-		selectedIndex := a.songList.GetCurrentItem()
-		
+	if selectedIndex := a.selectedIndex(); selectedIndex >= 0 && selectedIndex < len(a.songs) {
 		// If pressing Enter on the same currently playing song, toggle play/pause
-		if selectedIndex == a.currentSong && a.isPlaying {
+		if selectedIndex == a.currentSong && (a.isPlaying || a.isPaused) {
 			a.togglePlayPause()
 		} else {
 			// Different song or not playing, start new playback
@@ -1770,17 +2182,18 @@ func (a *App) onSearchChanged(text string) {
 func (a *App) filterAndUpdateSongList(searchText string) {
 	a.songList.Clear()
 	
+	query, group := parseGroupFilter(searchText)
+	query, language := parseSearchQuery(query)
+
 	// If no search text, show all songs
-	if searchText == "" {
+	if query == "" && language == "" && group.empty() {
 		for i, song := range a.songs {
 			// Format: "Title - Artist [Duration]"
-			mainText := fmt.Sprintf("%s - %s", song.Title, song.Artist)
-			secondaryText := fmt.Sprintf("[%02d:%02d]", 
-				int(song.Duration.Minutes()), 
-				int(song.Duration.Seconds())%60)
+			mainText := fmt.Sprintf("%s - %s%s", song.Title, song.Artist, a.languageLabel(song)+lyricsOnlyLabel(song))
+			secondaryText := fmt.Sprintf("[%s]", utils.FormatDuration(song.Duration))
 			
 			a.songList.AddItem(mainText, secondaryText, 0, func() {
-				a.currentSong = i
+				a.selectedSong = i
 				a.playSelectedSong()
 			})
 		}
@@ -1788,25 +2201,23 @@ func (a *App) filterAndUpdateSongList(searchText string) {
 	}
 	
 	// Filter songs that match search text (case insensitive)
-	searchLower := strings.ToLower(searchText)
+	searchLower := strings.ToLower(query)
 	matchedIndices := []int{}
 	
 	for i, song := range a.songs {
 		titleMatch := strings.Contains(strings.ToLower(song.Title), searchLower)
 		artistMatch := strings.Contains(strings.ToLower(song.Artist), searchLower)
 		
-		if titleMatch || artistMatch {
+		if (titleMatch || artistMatch) && a.matchesLanguage(song, language) && group.matches(song) {
 			matchedIndices = append(matchedIndices, i)
 			
 			// Format: "Title - Artist [Duration]" with search highlighting
-			mainText := fmt.Sprintf("%s - %s", song.Title, song.Artist)
-			secondaryText := fmt.Sprintf("[%02d:%02d] [green]✓[white]", 
-				int(song.Duration.Minutes()), 
-				int(song.Duration.Seconds())%60)
+			mainText := fmt.Sprintf("%s - %s%s", song.Title, song.Artist, a.languageLabel(song)+lyricsOnlyLabel(song))
+			secondaryText := fmt.Sprintf("[%s] [green]✓[white]", utils.FormatDuration(song.Duration))
 			
 			a.songList.AddItem(mainText, secondaryText, 0, func(index int) func() {
 				return func() {
-					a.currentSong = index
+					a.selectedSong = index
 					a.playSelectedSong()
 				}
 			}(i))
@@ -1832,76 +2243,119 @@ func (a *App) play() {
 		a.isLoading = false
 	}()
 
+	// Log the song this replaces before its score is reset
+	a.recordPlay()
+
 	song := a.songs[a.currentSong]
+	a.selectedSong = a.currentSong
 
-	// Load lyrics for this song
-	if song.LyricsPath != "" {
-		a.loadLyricsFromFile(song.LyricsPath)
-	} else {
-		a.lyricLines = []LyricLine{
-			{Time: 0 * time.Second, Text: "No lyrics available", Index: 0, IsActive: false, IsHit: false},
+	// Songs from remote libraries are fetched into the song cache first
+	if remotefs.IsRemote(song.Path) {
+		if err := a.fetchRemoteSong(&song); err != nil {
+			a.handleError(err, "Fetch Remote Song")
+			return
 		}
 	}
 
+	// Load lyrics for this song
+	a.loadSongLyrics(&song)
+
 	// Reset karaoke state only for NEW playback (not resume)
 	if !a.isPaused {
-		a.karaokeScore = 0
-		a.streak = 0
-		a.accuracy = 0.0
-		a.totalLyrics = len(a.lyricLines)
-		a.hitLyrics = 0
-		for i := range a.lyricLines {
-			a.lyricLines[i].IsHit = false
-			a.lyricLines[i].IsActive = false
-		}
+		a.resetKaraoke()
 	}
 
 	// Real audio playback with optimized responsiveness
 	if a.player != nil {
-		// Load the audio file (this is cached after first load)
-		if err := a.player.LoadFile(song.Path); err != nil {
+		// Shift the pitch when the song or its lyrics ask for it
+		a.player.SetPitch(a.songTranspose(song))
+		a.player.SetTrackGain(a.trackGain(song))
+
+		// Load the audio file, from memory if it was prefetched or else
+		// from the local song cache if it has a copy
+		if err := a.loadSong(song); err != nil {
 			a.handleError(err, "Load Audio File")
 			return
 		}
 
 		// Apply current volume setting
-		a.player.SetVolume(a.volume)
+		a.player.SetVolume(a.playbackVolume())
 
-		// If resuming from pause, seek to current position
-		if a.isPaused && a.position > 0 {
-			if err := a.player.SeekTo(a.position); err != nil {
-				a.handleError(err, "Seek to Position")
-				// Continue anyway, will start from beginning
-			}
-		}
-
-		// Start playback immediately - don't wait for UI updates
-		if err := a.player.Play(); err != nil {
+		// Start playback immediately - don't wait for UI updates. Long-form
+		// audio starts where it was left off.
+		start := a.resumePoint(song)
+		if err := a.player.PlayFrom(start); err != nil {
 			a.handleError(err, "Start Playback")
 			return
 		}
-
-		// Set UI state (after audio starts)
-		a.isPlaying = true
-		a.isPaused = false
-		a.duration = song.Duration
-
-		// Update UI in background to not block audio
-		go func() {
-			a.app.QueueUpdateDraw(func() {
-				a.updateAllDisplays()
-			})
-		}()
+		a.songStarted(song, start)
 
 		// Start position tracking for UI updates
 		go a.trackRealPlayback()
 	}
 }
 
+// loadSongLyrics makes song's lyrics the active ones
+func (a *App) loadSongLyrics(song *Song) {
+	if isLyricsOnly(*song) {
+		a.loadLyricsOnly(song)
+	} else if song.LyricsPath != "" {
+		a.loadLyricsFromFile(song.LyricsPath)
+	} else {
+		track := lyrics.New()
+		track.Lines = append(track.Lines, lyrics.LyricLine{Time: 0, Text: "No lyrics available"})
+		a.setLyrics(track)
+	}
+}
+
+// resetKaraoke clears the score and line hits for a new run of the song
+func (a *App) resetKaraoke() {
+	a.karaokeScore = 0
+	a.streak = 0
+	a.accuracy = 0.0
+	a.totalLyrics = len(a.lyricLines)
+	a.hitLyrics = 0
+	a.resetDuetScores()
+	for i := range a.lyricLines {
+		a.lyricLines[i].IsHit = false
+		a.lyricLines[i].IsActive = false
+	}
+	a.clearJournal()
+}
+
+// songStarted sets up everything that goes with a song once its audio has
+// started playing from start
+func (a *App) songStarted(song Song, start time.Duration) {
+	a.applyMetronome()
+	a.startPlayRecord(song)
+	a.notePlayHistory(song)
+	a.takeFromQueue(song)
+	a.prefetchNext()
+	a.queueNextSong()
+
+	a.position = start
+	a.startPartyFlow()
+
+	// Set UI state (after audio starts)
+	a.isPlaying = true
+	a.isPaused = false
+	a.duration = song.Duration
+
+	// Update UI in background to not block audio
+	go func() {
+		a.app.QueueUpdateDraw(func() {
+			a.updateAllDisplays()
+		})
+	}()
+}
+
 func (a *App) togglePlayPause() {
 	if a.isPlaying && !a.isPaused {
 		// Currently playing, so pause
 		a.pause()
+	} else if a.isPaused && a.player != nil {
+		// Paused, so continue from the same position
+		a.resume()
 	} else {
 		// Currently paused or stopped, so start/resume
 		if a.currentSong >= 0 {
@@ -1916,12 +2370,29 @@ func (a *App) pause() {
 	}
 	a.isPaused = true
 	a.isPlaying = false
+	a.saveResumePosition()
+	a.updateAllDisplays()
+}
+
+// resume continues a paused song
+func (a *App) resume() {
+	if err := a.player.Failure(); err != nil {
+		a.isPaused = false
+		a.isPlaying = true
+		go a.recoverPlayback(err)
+		return
+	}
+	a.player.Resume()
+	a.isPlaying = true
+	a.isPaused = false
 	a.updateAllDisplays()
+
+	go a.trackRealPlayback()
 }
 
 // trackRealPlayback tracks real audio playback position
 func (a *App) trackRealPlayback() {
-	ticker := time.NewTicker(100 * time.Millisecond)
+	ticker := time.NewTicker(playbackTick)
 	defer ticker.Stop()
 
 	for range ticker.C {
@@ -1934,31 +2405,74 @@ func (a *App) trackRealPlayback() {
 			a.position = a.player.GetPosition()
 		}
 
-		// Check if song is finished
-		if !a.player.IsPlaying() || a.position >= a.duration {
+		// A failed output isn't the end of the song, so try to get it back
+		if err := a.player.Failure(); err != nil {
+			a.recoverPlayback(err)
+			break
+		}
+
+		// The player carried straight on into the song queued after this one
+		if _, ok := a.player.TakeAdvance(); ok {
+			a.app.QueueUpdateDraw(a.startQueuedSong)
+			continue
+		}
+
+		// Check if song is finished. A song following the MIDI clock ends
+		// with its lyrics, however slowly the band plays it, and one with a
+		// song queued after it ends when the player moves on.
+		// A paused song isn't playing but hasn't ended either.
+		if (!a.player.IsPlaying() && !a.isPaused && !a.followsClock()) || (a.position >= a.duration && !a.player.HasQueued()) {
+			if a.currentSong >= 0 && a.currentSong < len(a.songs) {
+				a.resumeStore.Clear(a.songs[a.currentSong].Path)
+			}
+			a.clearJournal()
 			a.position = a.duration
+			a.recordPlay()
 			a.isPlaying = false
 			a.isPaused = false
 			// Ensure focus returns to song list when song ends
 			a.app.QueueUpdateDraw(func() {
 				a.app.SetFocus(a.songList)
 				a.updateAllDisplays()
+				a.advanceAfterSong()
 			})
 			break
 		}
 
+		// Periodically remember where long-form audio is
+		if time.Since(a.lastResumeSave) > 10*time.Second {
+			a.saveResumePosition()
+		}
+		// and how far a scored run has got, in case the app goes down
+		if time.Since(a.lastJournalWrite) > journalInterval {
+			a.writeJournal()
+		}
+
 		a.app.QueueUpdateDraw(func() {
+			a.syncPosition()
 			a.updateNowPlaying()
 			a.updateProgress()
 			a.updateKaraokeLyrics()
+			a.scheduleLyricTransition()
+			a.checkLineCue()
+			a.checkPartyFlow()
+			a.fadeBeforeShutdown()
 			a.updateVisualizer()
 			a.updateScore()
 			a.updateSongList()
+			a.updateQueueView()
+			a.updateSyncEditor()
 		})
 	}
 }
 
 func (a *App) stop() {
+	a.saveResumePosition()
+	a.clearJournal()
+	a.recordPlay()
+	a.stopVolumeRamp()
+	a.autoDJ = false
+
 	// Ensure we stop cleanly to prevent corruption
 	if a.player != nil {
 		a.player.Stop()
@@ -1978,6 +2492,7 @@ func (a *App) stop() {
 	a.accuracy = 0.0
 	a.hitLyrics = 0
 	a.totalLyrics = 0
+	a.resetDuetScores()
 
 	// Reset visualizer bars
 	for i := range a.visualizerBars {
@@ -2000,24 +2515,59 @@ func (a *App) next() {
 	if len(a.songs) == 0 {
 		return
 	}
-	
-	a.currentSong = (a.currentSong + 1) % len(a.songs)
+	a.saveResumePosition()
+
+	if queued := a.queuedSongs(); len(queued) > 0 {
+		a.currentSong = queued[0]
+	} else {
+		a.currentSong = a.nextInOrder(a.currentSong, true)
+	}
 	a.updateSongList()
-	a.play()
+	a.playOrCue()
 }
 
 func (a *App) previous() {
 	if len(a.songs) == 0 {
 		return
 	}
-	
-	a.currentSong = (a.currentSong - 1 + len(a.songs)) % len(a.songs)
+
+	// Once a song is under way, previous goes back to its start. That is
+	// checked first so long-form audio doesn't save the position left.
+	if (a.isPlaying || a.isPaused) && a.appConfig.PreviousRestarts(a.position) {
+		a.restartSong()
+		return
+	}
+	a.saveResumePosition()
+
+	// Back to the song that actually played before, going by the list
+	// only when nothing did
+	if previous := a.previousPlayed(); previous >= 0 {
+		a.currentSong = previous
+	} else {
+		a.currentSong = (a.currentSong - 1 + len(a.songs)) % len(a.songs)
+	}
 	a.updateSongList()
-	a.play()
+	a.playOrCue()
+}
+
+// restartSong plays the current song again from the top
+func (a *App) restartSong() {
+	if isLyricsOnly(a.songs[a.currentSong]) {
+		a.playOrCue()
+		return
+	}
+	if err := a.player.SeekTo(0); err != nil {
+		a.handleError(err, "Restart Song")
+		return
+	}
+	a.position = 0
+	a.resetKaraoke()
+	a.updateAllDisplays()
 }
 
 // Volume control functions
 func (a *App) increaseVolume() {
+	a.stopVolumeRamp()
 	if a.volume < 1.0 {
 		a.volume = a.volume + 0.1
 		if a.volume > 1.0 {
@@ -2032,6 +2582,7 @@ func (a *App) increaseVolume() {
 }
 
 func (a *App) decreaseVolume() {
+	a.stopVolumeRamp()
 	if a.volume > 0.0 {
 		a.volume = a.volume - 0.1
 		if a.volume < 0.0 {
@@ -2045,24 +2596,41 @@ func (a *App) decreaseVolume() {
 	}
 }
 
+// toggleRepeat goes round the repeat modes: off, all, then one
 func (a *App) toggleRepeat() {
-	a.repeatMode = !a.repeatMode
-	a.updateNowPlaying()
+	switch {
+	case a.repeatOne:
+		a.repeatOne = false
+	case a.repeatMode:
+		a.repeatMode = false
+		a.repeatOne = true
+	default:
+		a.repeatMode = true
+	}
+	a.queueChanged()
 	a.saveConfig()
 }
 
+// toggleShuffle turns shuffle on with a new order, or back off
 func (a *App) toggleShuffle() {
 	a.shuffleMode = !a.shuffleMode
-	a.updateNowPlaying()
+	if a.shuffleMode {
+		a.reshuffle()
+		a.playQueue.Shuffle()
+	}
+	a.queueChanged()
 	a.saveConfig()
 }
 
 // saveConfig saves the current configuration to file
 func (a *App) saveConfig() {
-	if a.appConfig != nil {
+	// In read-only mode changes only last for the session, as they do
+	// while a damaged config waits to be recovered
+	if a.appConfig != nil && !a.readOnly && a.configDamage == nil {
 		a.appConfig.DefaultVolume = a.volume
 		a.appConfig.ShuffleMode = a.shuffleMode
 		a.appConfig.RepeatMode = a.repeatMode
+		a.appConfig.RepeatOne = a.repeatOne
 		a.appConfig.SaveConfig(config.GetConfigPath())
 	}
 }
@@ -2082,27 +2650,31 @@ func (a *App) loadPlaylist(playlistName string) error {
 	// Clear current songs
 	a.songs = []Song{}
 	a.currentSong = -1
+	a.selectedSong = -1
 
 	// Load songs from playlist
 	for _, path := range songPaths {
+		if a.overrides.IsHidden(path) {
+			continue
+		}
 		// Check if file exists
 		if _, err := os.Stat(path); err == nil {
+			if isLyricsOnly(Song{Path: path}) {
+				if song, ok := lyricsOnlySong(path); ok {
+					a.songs = append(a.songs, song)
+				}
+				continue
+			}
 			// Try to get metadata
 			meta, err := metadata.GetRealMetadata(path)
 			if err == nil {
-				song := Song{
-					Title:      meta.Title,
-					Artist:     meta.Artist,
-					Path:       meta.Path,
-					LyricsPath: a.findLyricsFile(meta.Path),
-					Duration:   meta.Duration,
-				}
-				a.songs = append(a.songs, song)
+				a.songs = append(a.songs, a.songFromMetadata(meta))
 			}
 		}
 	}
 
 	a.currentPlaylist = playlistName
+	a.autoDJ = false
 
 	// Update displays
 	a.updateAllDisplays()
@@ -2111,9 +2683,21 @@ func (a *App) loadPlaylist(playlistName string) error {
 }
 
 func (a *App) addSongToPlaylist(playlistName string) error {
-	if a.currentSong >= 0 && a.currentSong < len(a.songs) {
-		songPath := a.songs[a.currentSong].Path
-		return a.playlistManager.AddSongToPlaylist(playlistName, songPath)
+	if selected := a.selectedIndex(); selected >= 0 && selected < len(a.songs) {
+		song := a.songs[selected]
+		if err := a.playlistManager.AddSongToPlaylist(playlistName, song.Path); err != nil {
+			return err
+		}
+		a.undoStack.Push(undo.Action{
+			Description: fmt.Sprintf("add \"%s\" to %s", song.Title, playlistName),
+			Undo: func() error {
+				return a.playlistManager.RemoveSongFromPlaylist(playlistName, song.Path)
+			},
+			Redo: func() error {
+				return a.playlistManager.AddSongToPlaylist(playlistName, song.Path)
+			},
+		})
+		return nil
 	}
 	return fmt.Errorf("no song selected")
 }
@@ -2128,11 +2712,15 @@ func (a *App) getPlaylistList() []string {
 
 // Lyrics Editor functions
 func (a *App) openLyricsEditor() {
-	if a.currentSong < 0 || a.currentSong >= len(a.songs) {
+	if a.denyReadOnly("Editing lyrics") {
+		return
+	}
+	selected := a.selectedIndex()
+	if selected < 0 || selected >= len(a.songs) {
 		return
 	}
 
-	song := a.songs[a.currentSong]
+	song := a.songs[selected]
 
 	// Load existing lyrics if available
 	if song.LyricsPath != "" {
@@ -2149,33 +2737,29 @@ func (a *App) openLyricsEditor() {
 	a.showLyricsEditor(song)
 }
 
-func (a *App) saveLyrics() {
-	if a.currentSong < 0 || a.currentSong >= len(a.songs) {
-		return
-	}
-
-	song := a.songs[a.currentSong]
+func (a *App) saveLyrics(song Song) {
+	// Save over the lyrics file found, or start one next to the song
+	lyricsPath := a.lyricsSavePath(song)
 
-	// Generate lyrics file path
-	lyricsPath := a.findLyricsFile(song.Path)
-	if lyricsPath == "" {
-		// Create new lyrics file path
-		ext := filepath.Ext(song.Path)
-		lyricsPath = strings.TrimSuffix(song.Path, ext) + ".lrc"
-	}
-
-	// Save lyrics
-	if err := a.lyricsEditor.SaveLyricsToFile(lyricsPath); err != nil {
+	// Save lyrics, keeping the old version for undo
+	err := a.editFile("lyrics for "+song.Title, lyricsPath, func() error {
+		return a.lyricsEditor.SaveLyricsToFile(lyricsPath)
+	})
+	if err != nil {
 		a.handleError(err, "Lyrics Save")
 		return
 	}
 
 	// Update song's lyrics path
-	song.LyricsPath = lyricsPath
-	a.songs[a.currentSong] = song
+	if i := a.songIndex(song.Path); i >= 0 {
+		a.songs[i].LyricsPath = lyricsPath
+	}
 
-	// Reload lyrics in main display
-	a.loadLyricsFromFile(lyricsPath)
+	// Reload lyrics in main display when they belong to the current song
+	if a.currentSong >= 0 && a.currentSong < len(a.songs) && a.songs[a.currentSong].Path == song.Path {
+		a.loadLyricsFromFile(lyricsPath)
+	}
+	a.offerEmbedLyrics(song, lyricsPath, "")
 }
 
 // showLyricsEditor displays the lyrics editor modal
@@ -2196,13 +2780,16 @@ func (a *App) showLyricsEditor(song Song) {
 
 	lyricsEditorModal := tview.NewModal().
 		SetText(editorText).
-		AddButtons([]string{"Save", "Cancel"}).
+		AddButtons([]string{"Sync", "Save", "Cancel"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			if buttonLabel == "Save" {
-				a.saveLyrics()
+				a.saveLyrics(song)
 			}
 			a.pages.RemovePage("lyrics-editor")
 			a.app.SetFocus(a.songList)
+			if buttonLabel == "Sync" {
+				a.startSyncEditor(song, a.lyricsEditor.GetLyrics())
+			}
 		})
 
 	// Set modal title
@@ -2232,12 +2819,9 @@ func (a *App) createLyricsEditorContent(song Song, lyricsLines []interface{}) st
 
 				if hasTime && hasText {
 					// Format time as string for display
-					timeStr := "00:00.00" // Placeholder - would need actual time formatting
+					timeStr := lyrics.FormatTimestamp(0)
 					if timeDuration, ok := timeInterface.(time.Duration); ok {
-						minutes := int(timeDuration.Minutes())
-						seconds := int(timeDuration.Seconds()) % 60
-						centiseconds := int(timeDuration.Milliseconds()) % 1000 / 10
-						timeStr = fmt.Sprintf("[%02d:%02d.%02d]", minutes, seconds, centiseconds)
+						timeStr = lyrics.FormatTimestamp(timeDuration)
 					}
 
 					text := ""
@@ -2253,8 +2837,7 @@ func (a *App) createLyricsEditorContent(song Song, lyricsLines []interface{}) st
 	}
 
 	content.WriteString("[green]Instructions:[white]\n")
-	content.WriteString("• Edit the lyrics above with proper timing\n")
-	content.WriteString("• Use format [mm:ss.xx] for timing\n")
+	content.WriteString("• Press [yellow]Sync[white] to time the lines while the song plays\n")
 	content.WriteString("• Press [yellow]Save[white] to save changes\n")
 	content.WriteString("• Press [yellow]Cancel[white] to discard changes\n")
 
@@ -2263,7 +2846,7 @@ func (a *App) createLyricsEditorContent(song Song, lyricsLines []interface{}) st
 
 // File Management functions
 func (a *App) moveSongToDirectory(song Song, newDir string) error {
-	if a.currentSong < 0 || a.currentSong >= len(a.songs) {
+	if a.songIndex(song.Path) < 0 {
 		return fmt.Errorf("no song selected")
 	}
 
@@ -2280,29 +2863,20 @@ func (a *App) moveSongToDirectory(song Song, newDir string) error {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
-	// Move the file
-	if err := os.Rename(song.Path, newPath); err != nil {
-		return fmt.Errorf("failed to move file: %w", err)
-	}
-
-	// Update song path
-	a.songs[a.currentSong].Path = newPath
-
-	// Update lyrics path if it exists
+	// The lyrics go along with the song
+	target := song
+	target.Path = newPath
 	if song.LyricsPath != "" {
-		lyricsFileName := filepath.Base(song.LyricsPath)
-		newLyricsPath := filepath.Join(newDir, lyricsFileName)
-		if _, err := os.Stat(song.LyricsPath); err == nil {
-			os.Rename(song.LyricsPath, newLyricsPath)
-			a.songs[a.currentSong].LyricsPath = newLyricsPath
-		}
+		target.LyricsPath = filepath.Join(newDir, filepath.Base(song.LyricsPath))
 	}
 
+	// Move the files in the background, since another drive means a copy
+	a.moveSongInBackground(song, target, "move of "+song.Title, "✅ File moved successfully!")
 	return nil
 }
 
 func (a *App) renameSong(song Song, newName string) error {
-	if a.currentSong < 0 || a.currentSong >= len(a.songs) {
+	if a.songIndex(song.Path) < 0 {
 		return fmt.Errorf("no song selected")
 	}
 
@@ -2316,30 +2890,19 @@ func (a *App) renameSong(song Song, newName string) error {
 		return fmt.Errorf("file with that name already exists")
 	}
 
-	// Rename the file
-	if err := os.Rename(song.Path, newPath); err != nil {
-		return fmt.Errorf("failed to rename file: %w", err)
-	}
-
-	// Update song path
-	a.songs[a.currentSong].Path = newPath
-
-	// Update lyrics path if it exists
+	// The lyrics are renamed to match
+	target := song
+	target.Path = newPath
 	if song.LyricsPath != "" {
-		lyricsDir := filepath.Dir(song.LyricsPath)
-		lyricsExt := filepath.Ext(song.LyricsPath)
-		newLyricsPath := filepath.Join(lyricsDir, newName+lyricsExt)
-		if _, err := os.Stat(song.LyricsPath); err == nil {
-			os.Rename(song.LyricsPath, newLyricsPath)
-			a.songs[a.currentSong].LyricsPath = newLyricsPath
-		}
+		target.LyricsPath = filepath.Join(filepath.Dir(song.LyricsPath), newName+filepath.Ext(song.LyricsPath))
 	}
 
+	a.moveSongInBackground(song, target, "rename of "+song.Title, "✅ File renamed successfully!")
 	return nil
 }
 
 func (a *App) deleteSong(song Song) error {
-	if a.currentSong < 0 || a.currentSong >= len(a.songs) {
+	if a.songIndex(song.Path) < 0 {
 		return fmt.Errorf("no song selected")
 	}
 
@@ -2355,24 +2918,26 @@ func (a *App) deleteSong(song Song) error {
 		}
 	}
 
-	// Remove song from library
-	a.songs = append(a.songs[:a.currentSong], a.songs[a.currentSong+1:]...)
-
-	// Adjust current song index
-	if a.currentSong >= len(a.songs) {
-		a.currentSong = len(a.songs) - 1
-	}
+	// Remove song from library, stopping it first if it is playing
+	a.removeSongFromList(song.Path)
 
 	return nil
 }
 
 // showFileManager displays the file management modal
 func (a *App) showFileManager() {
-	if a.currentSong < 0 || a.currentSong >= len(a.songs) {
+	if a.denyReadOnly("File management") {
+		return
+	}
+	selected := a.selectedIndex()
+	if selected < 0 || selected >= len(a.songs) {
 		return
 	}
 
-	song := a.songs[a.currentSong]
+	song := a.songs[selected]
+	if a.denyReadOnlyMedia(filepath.Dir(song.Path), "File management") {
+		return
+	}
 
 	fileManagerModal := tview.NewModal().
 		SetText(a.createFileManagerContent(song)).
@@ -2402,7 +2967,7 @@ func (a *App) createFileManagerContent(song Song) string {
 	content.WriteString(fmt.Sprintf("[yellow]Managing file: %s[white]\n", song.Title))
 	content.WriteString(fmt.Sprintf("[white]Artist: %s[white]\n", song.Artist))
 	content.WriteString(fmt.Sprintf("[white]Current path: %s[white]\n", song.Path))
-	content.WriteString(fmt.Sprintf("[white]Duration: %s[white]\n", formatDuration(song.Duration)))
+	content.WriteString(fmt.Sprintf("[white]Duration: %s[white]\n", utils.FormatDuration(song.Duration)))
 
 	if song.LyricsPath != "" {
 		content.WriteString(fmt.Sprintf("[white]Lyrics: %s[white]\n", song.LyricsPath))
@@ -2430,9 +2995,6 @@ func (a *App) showMoveDialog(song Song) {
 			if directory != "" {
 				if err := a.moveSongToDirectory(song, directory); err != nil {
 					a.handleError(err, "Move File")
-				} else {
-					a.updateAllDisplays()
-					a.showMessage("✅ File moved successfully!")
 				}
 			} else {
 				a.showWarning("Please enter a destination directory")
@@ -2470,9 +3032,6 @@ func (a *App) showRenameDialog(song Song) {
 			}
 			if err := a.renameSong(song, newName); err != nil {
 				a.handleError(err, "Rename File")
-			} else {
-				a.updateAllDisplays()
-				a.showMessage("✅ File renamed successfully!")
 			}
 			a.pages.RemovePage("rename-dialog")
 			a.app.SetFocus(a.songList)
@@ -2536,12 +3095,12 @@ func (a *App) showDeleteConfirmation(song Song) {
 }
 
 // Export/Import functions
-func (a *App) exportPerformanceData(format string) error {
+func (a *App) exportPerformanceData(em *export.ExportManager, format string) error {
 	// Create performance data from current session
-	performanceData := []export.PerformanceData{}
+	performanceData := append([]export.PerformanceData{}, a.performances...)
 
 	// Add current performance if playing
-	if a.currentSong >= 0 && a.currentSong < len(a.songs) {
+	if !a.playStarted.IsZero() && a.currentSong >= 0 && a.currentSong < len(a.songs) {
 		song := a.songs[a.currentSong]
 		perf := export.PerformanceData{
 			Date:      time.Now(),
@@ -2550,15 +3109,15 @@ func (a *App) exportPerformanceData(format string) error {
 			Score:     a.karaokeScore,
 			Streak:    a.streak,
 			Accuracy:  a.accuracy,
-			Duration:  formatDuration(song.Duration),
+			Duration:  utils.FormatDuration(song.Duration),
 		}
 		performanceData = append(performanceData, perf)
 	}
 
-	return a.exportManager.ExportPerformanceData(performanceData, format)
+	return em.ExportPerformanceData(performanceData, format)
 }
 
-func (a *App) exportLibraryData(format string) error {
+func (a *App) exportLibraryData(em *export.ExportManager, format string) error {
 	// Convert songs to library data format
 	libraryData := make([]export.LibraryData, len(a.songs))
 	for i, song := range a.songs {
@@ -2567,41 +3126,41 @@ func (a *App) exportLibraryData(format string) error {
 			Artist:     song.Artist,
 			Path:       song.Path,
 			LyricsPath: song.LyricsPath,
-			Duration:   formatDuration(song.Duration),
+			Duration:   utils.FormatDuration(song.Duration),
 			Format:     "mp3", // Could be enhanced to detect actual format
 			Size:       0,     // Would need to get file size
 		}
 	}
 
-	return a.exportManager.ExportLibraryData(libraryData, format)
+	return em.ExportLibraryData(libraryData, format)
 }
 
 func (a *App) showExportDialog() {
 	exportModal := tview.NewModal().
 		SetText(a.createExportDialogContent()).
-		AddButtons([]string{"Performance JSON", "Performance CSV", "Library JSON", "Library CSV", "Cancel"}).
+		AddButtons([]string{"Performance JSON", "Performance CSV", "Library JSON", "Library CSV", "Full Stats", "Mixtape", "Save As", "Options", "Cancel"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			switch buttonLabel {
 			case "Performance JSON":
-				if err := a.exportPerformanceData("json"); err != nil {
+				if err := a.exportPerformanceData(a.exportManager, "json"); err != nil {
 					a.handleError(err, "Performance JSON Export")
 				} else {
 					a.showExportSuccess("Performance data exported as JSON")
 				}
 			case "Performance CSV":
-				if err := a.exportPerformanceData("csv"); err != nil {
+				if err := a.exportPerformanceData(a.exportManager, "csv"); err != nil {
 					a.handleError(err, "Performance CSV Export")
 				} else {
 					a.showExportSuccess("Performance data exported as CSV")
 				}
 			case "Library JSON":
-				if err := a.exportLibraryData("json"); err != nil {
+				if err := a.exportLibraryData(a.exportManager, "json"); err != nil {
 					a.handleError(err, "Library JSON Export")
 				} else {
 					a.showExportSuccess("Library data exported as JSON")
 				}
 			case "Library CSV":
-				if err := a.exportLibraryData("csv"); err != nil {
+				if err := a.exportLibraryData(a.exportManager, "csv"); err != nil {
 					a.handleError(err, "Library CSV Export")
 				} else {
 					a.showExportSuccess("Library data exported as CSV")
@@ -2609,6 +3168,16 @@ func (a *App) showExportDialog() {
 			}
 			a.pages.RemovePage("export-dialog")
 			a.app.SetFocus(a.songList)
+			switch buttonLabel {
+			case "Mixtape":
+				a.showMixtapeDialog()
+			case "Full Stats":
+				a.showStatsExportDialog()
+			case "Save As":
+				a.showExportSaveAs()
+			case "Options":
+				a.showExportOptions()
+			}
 		})
 
 	exportModal.SetTitle("Export Data")
@@ -2627,7 +3196,15 @@ func (a *App) createExportDialogContent() string {
 
 	content.WriteString("[cyan]Library Data:[white]\n")
 	content.WriteString("• [yellow]Library JSON[white] - Export music library information as JSON\n")
-	content.WriteString("• [yellow]Library CSV[white] - Export music library information as CSV\n\n")
+	content.WriteString("• [yellow]Library CSV[white] - Export music library information as CSV\n")
+	content.WriteString("• [yellow]Full Stats[white] - Plays, scores and lyric status per song, for a date range\n\n")
+
+	content.WriteString("[cyan]Audio:[white]\n")
+	content.WriteString("• [yellow]Mixtape[white] - Join the current song list into one WAV/MP3 file\n\n")
+
+	content.WriteString("[cyan]Files:[white]\n")
+	content.WriteString("• [yellow]Save As[white] - Export to a path of your choosing\n")
+	content.WriteString("• [yellow]Options[white] - Change the export folder, file naming and auto-export\n\n")
 
 	content.WriteString("[green]Files will be saved to:[white]\n")
 	content.WriteString(fmt.Sprintf("%s\n\n", a.exportManager.GetExportPath()))
@@ -2759,7 +3336,11 @@ func (a *App) handleError(err error, context string) {
 	}
 
 	errorMsg := fmt.Sprintf("Context: %s\nError: %s", context, err.Error())
+	if hint := utils.FileErrorHint(err); hint != "" {
+		errorMsg += "\n\n" + hint
+	}
 	a.showError(errorMsg)
+	a.errorLog.Add(context, err)
 
 	// Log error to status bar for debugging
 	a.statusBar.SetText(fmt.Sprintf("[red]Error in %s: %s[white]", context, err.Error()))
@@ -2778,7 +3359,7 @@ func (a *App) handleError(err error, context string) {
 
 // showJumpToTimeDialog shows a dialog for jumping to a specific time
 func (a *App) showJumpToTimeDialog() {
-	timeInput := tview.NewInputField().SetLabel("Jump to time (mm:ss)").SetText("").SetFieldWidth(10)
+	timeInput := tview.NewInputField().SetLabel("Jump to time (mm:ss or h:mm:ss)").SetText("").SetFieldWidth(10)
 
 	form := tview.NewForm().
 		AddFormItem(timeInput).
@@ -2790,9 +3371,9 @@ func (a *App) showJumpToTimeDialog() {
 			}
 
 			// Parse time string and jump to position
-			duration, err := parseTimeString(timeStr)
+			duration, err := utils.ParseDuration(timeStr)
 			if err != nil {
-				a.showError("Invalid time format. Use mm:ss (e.g., 01:30)")
+				a.showError("Invalid time format. Use mm:ss or h:mm:ss (e.g., 01:30)")
 				return
 			}
 
@@ -2800,7 +3381,7 @@ func (a *App) showJumpToTimeDialog() {
 			if a.currentSong >= 0 && a.currentSong < len(a.songs) {
 				songDuration := a.songs[a.currentSong].Duration
 				if duration > songDuration {
-					a.showWarning(fmt.Sprintf("Time exceeds song duration (%s)", formatDuration(songDuration)))
+					a.showWarning(fmt.Sprintf("Time exceeds song duration (%s)", utils.FormatDuration(songDuration)))
 					return
 				}
 			}
@@ -2830,39 +3411,20 @@ func (a *App) showJumpToTimeDialog() {
 	a.pages.AddPage("jump-dialog", form, true, true)
 }
 
-// parseTimeString parses a time string in mm:ss format
-func parseTimeString(timeStr string) (time.Duration, error) {
-	parts := strings.Split(timeStr, ":")
-	if len(parts) != 2 {
-		return 0, fmt.Errorf("invalid format")
-	}
-
-	minutes, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return 0, err
-	}
-
-	seconds, err := strconv.Atoi(parts[1])
-	if err != nil {
-		return 0, err
-	}
-
-	return time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second, nil
-}
-
 // showSongInfo displays detailed information about the current song
 func (a *App) showSongInfo() {
-	if a.currentSong < 0 || a.currentSong >= len(a.songs) {
+	selected := a.selectedIndex()
+	if selected < 0 || selected >= len(a.songs) {
 		return
 	}
 
-	song := a.songs[a.currentSong]
+	song := a.songs[selected]
 
 	var info strings.Builder
 	info.WriteString(fmt.Sprintf("[yellow]Song Information:[white]\n\n"))
 	info.WriteString(fmt.Sprintf("[cyan]Title:[white] %s\n", song.Title))
 	info.WriteString(fmt.Sprintf("[cyan]Artist:[white] %s\n", song.Artist))
-	info.WriteString(fmt.Sprintf("[cyan]Duration:[white] %s\n", formatDuration(song.Duration)))
+	info.WriteString(fmt.Sprintf("[cyan]Duration:[white] %s\n", utils.FormatDurationPrecise(song.Duration)))
 	info.WriteString(fmt.Sprintf("[cyan]File Path:[white] %s\n", song.Path))
 
 	if song.LyricsPath != "" {
@@ -2870,15 +3432,19 @@ func (a *App) showSongInfo() {
 	} else {
 		info.WriteString("[cyan]Lyrics:[white] [red]Not available[white]\n")
 	}
-
-	info.WriteString("\n[green]Press any key to close[white]")
+	if notes := strings.TrimSpace(a.overrides.Get(song.Path).Notes); notes != "" {
+		info.WriteString(fmt.Sprintf("\n[cyan]Notes:[white]\n%s\n", tview.Escape(notes)))
+	}
 
 	infoModal := tview.NewModal().
 		SetText(info.String()).
-		AddButtons([]string{"Close"}).
+		AddButtons([]string{"Close", "Edit notes"}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 			a.pages.RemovePage("song-info")
 			a.app.SetFocus(a.songList)
+			if buttonLabel == "Edit notes" {
+				a.showSongNotes(song)
+			}
 		})
 
 	a.pages.AddPage("song-info", infoModal, true, true)
@@ -2926,17 +3492,143 @@ func (a *App) seekBackward() {
 }
 
 func (a *App) quit() {
+	a.saveResumePosition()
+	a.writeJournal()
+	a.recordPlay()
+	a.autoExportPerformances()
+	a.saveInsights()
+	a.stopSharing()
+	a.leaveSharedPlaylist()
+	a.stopLyricsView()
+	if a.recorder != nil {
+		a.recorder.Close()
+	}
+	if a.watcher != nil {
+		a.watcher.Stop()
+	}
+	if a.oscServer != nil {
+		a.oscServer.Close()
+	}
 	if a.player != nil {
 		a.player.Stop()
 	}
 	a.app.Stop()
 }
 
-// Helper functions
-func formatDuration(d time.Duration) string {
-	minutes := int(d.Minutes())
-	seconds := int(d.Seconds()) % 60
-	return fmt.Sprintf("%02d:%02d", minutes, seconds)
+// isOverlayOpen reports whether a dialog or panel is shown on top of the main page
+func (a *App) isOverlayOpen() bool {
+	name, _ := a.pages.GetFrontPage()
+	return name != "main" && name != "preloader"
+}
+
+// isLongForm reports whether a song is long enough to remember its position
+func (a *App) isLongForm(song Song) bool {
+	minutes := a.appConfig.LongFormMinutes
+	return minutes > 0 && song.Duration >= time.Duration(minutes)*time.Minute
+}
+
+// resumePoint returns where long-form audio was left off, or 0 to start
+// from the beginning
+func (a *App) resumePoint(song Song) time.Duration {
+	if !a.isLongForm(song) || a.resumeStore == nil {
+		return 0
+	}
+	saved := a.resumeStore.Get(song.Path)
+	if saved > 0 && saved < song.Duration-5*time.Second {
+		return saved
+	}
+	return 0
+}
+
+// saveResumePosition remembers the position of the current long-form song
+func (a *App) saveResumePosition() {
+	a.lastResumeSave = time.Now()
+	if a.currentSong < 0 || a.currentSong >= len(a.songs) || a.position <= 0 {
+		return
+	}
+
+	song := a.songs[a.currentSong]
+	if a.isLongForm(song) && a.resumeStore != nil {
+		a.resumeStore.Set(song.Path, a.position)
+	}
+}
+
+// currentChapterIndex returns the chapter containing the playback position, or -1
+func (a *App) currentChapterIndex(song Song) int {
+	index := -1
+	for i, chapter := range song.Chapters {
+		if chapter.Start <= a.position {
+			index = i
+		}
+	}
+	return index
+}
+
+// chapterTitle returns a display title for a chapter
+func chapterTitle(chapter metadata.Chapter, index int) string {
+	if chapter.Title != "" {
+		return chapter.Title
+	}
+	return fmt.Sprintf("Chapter %d", index+1)
+}
+
+// showChapterList shows the chapters of the current song and seeks to the chosen one
+func (a *App) showChapterList() {
+	if a.currentSong < 0 || a.currentSong >= len(a.songs) {
+		return
+	}
+
+	song := a.songs[a.currentSong]
+	if len(song.Chapters) == 0 {
+		a.showMessage("📖 This song has no chapters")
+		return
+	}
+
+	chapterList := tview.NewList().ShowSecondaryText(false)
+	chapterList.SetBorder(true).
+		SetTitle(" Chapters - " + song.Title + " ").
+		SetTitleAlign(tview.AlignCenter)
+
+	for i, chapter := range song.Chapters {
+		chapterList.AddItem(fmt.Sprintf("%s  %s", utils.FormatDuration(chapter.Start), chapterTitle(chapter, i)), "", 0, nil)
+	}
+	if current := a.currentChapterIndex(song); current >= 0 {
+		chapterList.SetCurrentItem(current)
+	}
+
+	closeList := func() {
+		a.pages.RemovePage("chapters")
+		a.app.SetFocus(a.songList)
+	}
+
+	chapterList.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		closeList()
+		start := song.Chapters[index].Start
+		if !a.isPlaying && !a.isPaused {
+			a.play()
+		}
+		if err := a.player.SeekTo(start); err != nil {
+			a.handleError(err, "Seek to Chapter")
+			return
+		}
+		a.position = start
+		a.updateAllDisplays()
+	})
+	chapterList.SetDoneFunc(closeList)
+
+	a.pages.AddPage("chapters", centered(chapterList, 60, 20), true, true)
+	a.app.SetFocus(chapterList)
+}
+
+// centered wraps p in spacers so it floats in the middle of the screen
+func centered(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 1, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
+		AddItem(nil, 0, 1, false)
 }
 
 // Run starts the application
@@ -2953,8 +3645,20 @@ func main() {
 		}
 	}()
 	
+	options, err := runCLI(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "tuneminal:", err)
+		if hint := utils.FileErrorHint(err); hint != "" {
+			fmt.Fprintln(os.Stderr, hint)
+		}
+		os.Exit(1)
+	}
+	if options == nil {
+		return
+	}
+
 	// Create and run app
-	app := NewApp()
+	app := NewApp(options)
 	
 	if err := app.Run(); err != nil {
 		// Silent exit
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tuneminal/tuneminal/pkg/overrides"
+	"github.com/tuneminal/tuneminal/pkg/player"
+	"github.com/tuneminal/tuneminal/pkg/tempo"
+)
+
+// applyMetronome sets the click track for the current song, detecting its
+// tempo in the background the first time
+func (a *App) applyMetronome() {
+	if a.player == nil {
+		return
+	}
+	// Lyrics with no recording play against the click, unless it's off or
+	// a band's MIDI clock sets the time
+	if a.currentSong >= 0 && a.currentSong < len(a.songs) && isLyricsOnly(a.songs[a.currentSong]) {
+		if a.appConfig.LyricsOnlyClick && a.clock == nil {
+			a.player.SetMetronome(a.clickTrack(a.songs[a.currentSong]))
+		} else {
+			a.player.SetMetronome(nil)
+		}
+		return
+	}
+	if !a.appConfig.MetronomeEnabled || a.currentSong < 0 || a.currentSong >= len(a.songs) {
+		a.player.SetMetronome(nil)
+		return
+	}
+
+	song := a.songs[a.currentSong]
+	override := a.overrides.Get(song.Path)
+	if override.BPM > 0 {
+		a.player.SetMetronome(&player.MetronomeConfig{
+			BPM:         override.BPM,
+			Offset:      override.BeatOffset,
+			Volume:      a.appConfig.MetronomeVolume,
+			BeatsPerBar: a.appConfig.MetronomeBeatsPerBar,
+		})
+		return
+	}
+
+	a.player.SetMetronome(nil)
+	a.detectTempo(song.Path)
+}
+
+// detectTempo estimates a song's tempo in the background and saves it
+func (a *App) detectTempo(path string) {
+	if a.tempoDetecting[path] {
+		return
+	}
+	a.tempoDetecting[path] = true
+	a.showToast("[cyan]🥁 Detecting tempo...[white]")
+
+	go func() {
+		estimate, err := tempo.DetectFile(path)
+		if err == nil {
+			err = a.overrides.Update(path, func(o *overrides.Override) {
+				if !o.TempoTapped {
+					o.BPM = estimate.BPM
+					o.BeatOffset = estimate.Offset
+				}
+			})
+		}
+
+		a.app.QueueUpdateDraw(func() {
+			delete(a.tempoDetecting, path)
+			switch {
+			case errors.Is(err, tempo.ErrNoTempo):
+				a.showToast("[yellow]🥁 No steady beat found - tap the tempo with t[white]")
+				return
+			case err != nil:
+				a.showToast(fmt.Sprintf("[red]🥁 Tempo detection failed: %v[white]", err))
+				return
+			}
+
+			if a.currentSong >= 0 && a.currentSong < len(a.songs) && a.songs[a.currentSong].Path == path {
+				a.applyMetronome()
+				a.showToast(fmt.Sprintf("[green]🥁 Metronome at %.0f BPM[white]", estimate.BPM))
+			}
+		})
+	}()
+}
+
+// toggleMetronome turns the click track on or off
+func (a *App) toggleMetronome() {
+	a.appConfig.MetronomeEnabled = !a.appConfig.MetronomeEnabled
+	a.saveConfig()
+	a.applyMetronome()
+
+	if !a.appConfig.MetronomeEnabled {
+		a.showToast("🥁 Metronome off")
+	} else if metronome := a.player.Metronome(); metronome != nil {
+		a.showToast(fmt.Sprintf("[green]🥁 Metronome on at %.0f BPM[white]", metronome.BPM))
+	}
+	a.updateNowPlaying()
+}
+
+// tapTempo sets the current song's tempo from the user tapping along
+func (a *App) tapTempo() {
+	if !a.isPlaying || a.isPaused || a.currentSong < 0 || a.currentSong >= len(a.songs) {
+		a.showToast("[yellow]🥁 Play a song to tap its tempo[white]")
+		return
+	}
+
+	song := a.songs[a.currentSong]
+	if a.tapSong != song.Path {
+		a.tapper.Reset()
+		a.tapSong = song.Path
+	}
+
+	estimate, ok := a.tapper.Tap(a.player.GetPosition())
+	if !ok {
+		a.showToast(fmt.Sprintf("🥁 Tap %d - keep tapping on the beat", a.tapper.Count()))
+		return
+	}
+
+	err := a.overrides.Update(song.Path, func(o *overrides.Override) {
+		o.BPM = estimate.BPM
+		o.BeatOffset = estimate.Offset
+		o.TempoTapped = true
+	})
+	if err != nil {
+		a.handleError(err, "Save Tempo")
+		return
+	}
+
+	if !a.appConfig.MetronomeEnabled {
+		a.appConfig.MetronomeEnabled = true
+		a.saveConfig()
+	}
+	a.applyMetronome()
+	a.showToast(fmt.Sprintf("[green]🥁 Tapped tempo: %.1f BPM[white]", estimate.BPM))
+	a.updateNowPlaying()
+}
+
+// metronomeStatus describes the click track for Now Playing
+func (a *App) metronomeStatus() string {
+	if !a.appConfig.MetronomeEnabled || a.player == nil {
+		return "Off"
+	}
+	metronome := a.player.Metronome()
+	if metronome == nil {
+		return "On (no tempo yet)"
+	}
+	return fmt.Sprintf("%.0f BPM", metronome.BPM)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/player"
+)
+
+// micSingingLevel is the mic level, after the gain, taken as someone singing
+const micSingingLevel = 0.05
+
+// applyMicEffects sets the microphone gain and effects from the config
+func (a *App) applyMicEffects() {
+	if a.player == nil {
+		return
+	}
+	effects := &player.MicEffects{
+		Gain:     a.appConfig.MicGain,
+		AutoGain: a.appConfig.MicAutoGain,
+	}
+	if a.appConfig.MicEffects {
+		effects.Echo = a.appConfig.MicEcho
+		effects.EchoDelayMs = a.appConfig.MicEchoDelayMs
+		effects.Reverb = a.appConfig.MicReverb
+	}
+	a.player.SetMicEffects(effects)
+}
+
+// micMeter draws the microphone level on a -60 to 0 dB scale, or returns
+// "" when no mic is capturing
+func (a *App) micMeter() string {
+	if a.player == nil {
+		return ""
+	}
+	level, ok := a.player.MicLevel()
+	if !ok {
+		return ""
+	}
+
+	const width = 12
+	filled := 0
+	if level > 0 {
+		filled = int((20*math.Log10(level) + 60) / 60 * width)
+	}
+	filled = max(0, min(width, filled))
+	color := "green"
+	if filled > width*5/6 {
+		color = "red"
+	} else if filled > width*2/3 {
+		color = "yellow"
+	}
+	return fmt.Sprintf("🎤 [%s]%s[gray]%s[white]", color, strings.Repeat("█", filled), strings.Repeat("░", width-filled))
+}
+
+// micSinging reports whether the mic is live, and if so whether someone is
+// singing into it
+func (a *App) micSinging() (singing, live bool) {
+	if a.player == nil {
+		return false, false
+	}
+	level, ok := a.player.MicLevel()
+	return level >= micSingingLevel, ok
+}
+
+// showMicEffects shows the microphone panel: passing the mic through to the
+// speakers, and the gain, echo and reverb on it. Turning off automatic gain
+// sets the gain by hand.
+func (a *App) showMicEffects() {
+	passthrough := tview.NewCheckbox().
+		SetLabel("Mic through speakers").
+		SetChecked(a.appConfig.MicPassthrough)
+	micDevice := tview.NewInputField().
+		SetLabel("Mic device").
+		SetText(a.appConfig.MonitorMicDevice).
+		SetFieldWidth(24)
+	autoGain := tview.NewCheckbox().
+		SetLabel("Automatic gain").
+		SetChecked(a.appConfig.MicAutoGain)
+	effects := tview.NewCheckbox().
+		SetLabel("Echo and reverb").
+		SetChecked(a.appConfig.MicEffects)
+	percentField := func(label string, value float64) *tview.InputField {
+		return tview.NewInputField().
+			SetLabel(label).
+			SetText(strconv.Itoa(int(value * 100))).
+			SetFieldWidth(5).
+			SetAcceptanceFunc(tview.InputFieldInteger)
+	}
+	gain := percentField("Manual gain (%)", a.appConfig.MicGain)
+	echo := percentField("Echo (%)", a.appConfig.MicEcho)
+	echoDelay := tview.NewInputField().
+		SetLabel("Echo delay (ms)").
+		SetText(strconv.Itoa(a.appConfig.MicEchoDelayMs)).
+		SetFieldWidth(5).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	reverb := percentField("Reverb (%)", a.appConfig.MicReverb)
+
+	closePanel := func() {
+		a.pages.RemovePage("mic-effects")
+		a.app.SetFocus(a.songList)
+	}
+
+	form := tview.NewForm().
+		AddFormItem(passthrough).
+		AddFormItem(micDevice).
+		AddFormItem(autoGain).
+		AddFormItem(gain).
+		AddFormItem(effects).
+		AddFormItem(echo).
+		AddFormItem(echoDelay).
+		AddFormItem(reverb).
+		AddButton("Save", func() {
+			gainLevel, err := strconv.Atoi(gain.GetText())
+			if err != nil || gainLevel < 0 || gainLevel > 400 {
+				a.showWarning("Gain must be between 0 and 400")
+				return
+			}
+			echoLevel, err := strconv.Atoi(echo.GetText())
+			if err != nil || echoLevel < 0 || echoLevel > 90 {
+				a.showWarning("Echo must be between 0 and 90")
+				return
+			}
+			delay, err := strconv.Atoi(echoDelay.GetText())
+			if err != nil || delay < 20 || delay > 2000 {
+				a.showWarning("Echo delay must be between 20 and 2000 ms")
+				return
+			}
+			reverbLevel, err := strconv.Atoi(reverb.GetText())
+			if err != nil || reverbLevel < 0 || reverbLevel > 100 {
+				a.showWarning("Reverb must be between 0 and 100")
+				return
+			}
+			if passthrough.IsChecked() && micDevice.GetText() == "" {
+				a.showWarning("Please enter a mic device, e.g. hw:1,0")
+				return
+			}
+
+			passthroughChanged := passthrough.IsChecked() != a.appConfig.MicPassthrough ||
+				micDevice.GetText() != a.appConfig.MonitorMicDevice
+			a.appConfig.MicPassthrough = passthrough.IsChecked()
+			a.appConfig.MonitorMicDevice = micDevice.GetText()
+			a.appConfig.MicAutoGain = autoGain.IsChecked()
+			a.appConfig.MicEffects = effects.IsChecked()
+			a.appConfig.MicGain = float64(gainLevel) / 100
+			a.appConfig.MicEcho = float64(echoLevel) / 100
+			a.appConfig.MicEchoDelayMs = delay
+			a.appConfig.MicReverb = float64(reverbLevel) / 100
+			a.applyAudioSettings()
+			a.saveConfig()
+
+			closePanel()
+			if passthroughChanged && a.appConfig.MicPassthrough {
+				a.showMessage(fmt.Sprintf("🎤 %s will play through the speakers from the next song or seek", a.appConfig.MonitorMicDevice))
+			} else {
+				a.showToast("[green]🎤 Mic effects updated[white]")
+			}
+		}).
+		AddButton("Cancel", closePanel)
+	form.SetCancelFunc(closePanel)
+
+	form.SetTitle(" Mic Effects ").SetBorder(true)
+	a.pages.AddPage("mic-effects", centered(form, 56, 23), true, true)
+	a.app.SetFocus(form)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/export"
+	"github.com/tuneminal/tuneminal/pkg/jobs"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// showMixtapeDialog asks how to join the current song list into a mixtape
+func (a *App) showMixtapeDialog() {
+	if len(a.songs) == 0 {
+		a.showWarning("No songs to put on a mixtape")
+		return
+	}
+	if a.mixtapeJob != nil {
+		a.showToast("[yellow]Mixtape export already running[white]")
+		return
+	}
+
+	format := tview.NewDropDown().
+		SetLabel("Format").
+		SetOptions([]string{"WAV", "MP3"}, nil).
+		SetCurrentOption(0)
+	crossfade := tview.NewInputField().
+		SetLabel("Crossfade (seconds)").
+		SetText("3").
+		SetFieldWidth(5).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	normalize := tview.NewCheckbox().
+		SetLabel("Normalize levels").
+		SetChecked(true)
+
+	closeDialog := func() {
+		a.pages.RemovePage("mixtape-dialog")
+		a.app.SetFocus(a.songList)
+	}
+
+	form := tview.NewForm().
+		AddFormItem(format).
+		AddFormItem(crossfade).
+		AddFormItem(normalize).
+		AddButton("Export", func() {
+			seconds, err := strconv.Atoi(crossfade.GetText())
+			if err != nil || seconds < 0 || seconds > 30 {
+				a.showWarning("Crossfade must be between 0 and 30 seconds")
+				return
+			}
+			_, formatLabel := format.GetCurrentOption()
+
+			closeDialog()
+			a.exportMixtape(export.MixtapeOptions{
+				Format:    strings.ToLower(formatLabel),
+				Crossfade: time.Duration(seconds) * time.Second,
+				Normalize: normalize.IsChecked(),
+			})
+		}).
+		AddButton("Cancel", closeDialog)
+	form.SetCancelFunc(closeDialog)
+
+	title := "Current List"
+	if a.currentPlaylist != "" {
+		title = a.currentPlaylist
+	}
+	form.SetTitle(fmt.Sprintf(" Mixtape - %s (%d songs) ", title, len(a.songs))).SetBorder(true)
+	a.pages.AddPage("mixtape-dialog", centered(form, 60, 11), true, true)
+	a.app.SetFocus(form)
+}
+
+// exportMixtape renders the current song list to a single audio file in a
+// background job
+func (a *App) exportMixtape(options export.MixtapeOptions) {
+	paths := make([]string, len(a.songs))
+	for i, song := range a.songs {
+		paths[i] = song.Path
+	}
+
+	a.mixtapeJob = a.jobs.Start("Exporting mixtape", func(ctx context.Context, job *jobs.Job) {
+		options.OnProgress = func(done, total int, current string) {
+			job.SetProgress(done, total, filepath.Base(current))
+		}
+		result, err := a.exportManager.ExportMixtape(ctx, paths, options)
+
+		a.app.QueueUpdateDraw(func() {
+			a.mixtapeJob = nil
+			switch {
+			case errors.Is(err, context.Canceled):
+				a.showToast("[yellow]Mixtape export cancelled[white]")
+			case err != nil:
+				a.handleError(err, "Mixtape Export")
+			default:
+				a.showMessage(formatMixtapeResult(result))
+			}
+		})
+	})
+}
+
+// formatMixtapeResult describes a finished mixtape, including any skipped tracks
+func formatMixtapeResult(result *export.MixtapeResult) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("[green]✅ Mixtape saved: %d tracks, %s[white]\n\n%s",
+		result.Tracks, utils.FormatDuration(result.Duration), result.Path))
+
+	if len(result.Skipped) > 0 {
+		skipped := make([]string, 0, len(result.Skipped))
+		for path := range result.Skipped {
+			skipped = append(skipped, path)
+		}
+		sort.Strings(skipped)
+
+		content.WriteString(fmt.Sprintf("\n\n[yellow]Skipped %d unreadable tracks:[white]\n", len(skipped)))
+		for _, path := range skipped {
+			content.WriteString(fmt.Sprintf("%s: %v\n", filepath.Base(path), result.Skipped[path]))
+		}
+	}
+	return content.String()
+}
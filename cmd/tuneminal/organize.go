@@ -0,0 +1,213 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/added"
+	"github.com/tuneminal/tuneminal/pkg/organize"
+	"github.com/tuneminal/tuneminal/pkg/overrides"
+	"github.com/tuneminal/tuneminal/pkg/playlist"
+	"github.com/tuneminal/tuneminal/pkg/resume"
+)
+
+// planLibraryLayout previews moving every file in the library's first
+// folder to match template. Files in its other folders stay where they are,
+// rather than being gathered into the first.
+func planLibraryLayout(template string) (*organize.Plan, error) {
+	files, err := libraryFiles()
+	if err != nil {
+		return nil, err
+	}
+	root := homeRoot()
+	inRoot := files[:0]
+	for _, path := range files {
+		if isWithin(root, path) {
+			inRoot = append(inRoot, path)
+		}
+	}
+	return organize.PlanLayout(inRoot, root, template), nil
+}
+
+// applyLibraryLayout carries out a plan and points playlists, overrides,
+// resume positions and added dates at the new paths
+func applyLibraryLayout(plan *organize.Plan, playlists *playlist.PlaylistManager, songOverrides *overrides.Store, positions *resume.Store, dates *added.Store) (string, error) {
+	moved, moveErr := plan.Apply()
+
+	renames := make(map[string]string)
+	audioMoves := 0
+	for _, move := range moved {
+		renames[move.From] = move.To
+		if ext := filepath.Ext(move.From); ext != ".lrc" && ext != ".txt" {
+			audioMoves++
+			songOverrides.Rename(move.From, move.To)
+			positions.Rename(move.From, move.To)
+			dates.Rename(move.From, move.To)
+		}
+	}
+
+	changedPlaylists, err := playlists.RenameSongPaths(renames)
+	if err != nil && moveErr == nil {
+		moveErr = err
+	}
+
+	summary := fmt.Sprintf("Moved %d of %d songs (%d lyric files), updated %d playlist(s)",
+		audioMoves, len(plan.Moves), len(moved)-audioMoves, changedPlaylists)
+	return summary, moveErr
+}
+
+// formatLayoutPlan renders a dry-run preview; colors adds tview color tags
+func formatLayoutPlan(plan *organize.Plan, colors bool) string {
+	color := func(name string) string {
+		if colors {
+			return "[" + name + "]"
+		}
+		return ""
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("%s%d file(s) to move, %d already in place%s\n\n",
+		color("yellow"), len(plan.Moves), plan.Unchanged, color("white")))
+
+	for _, move := range plan.Moves {
+		from, _ := filepath.Rel(plan.Root, move.From)
+		to, _ := filepath.Rel(plan.Root, move.To)
+		content.WriteString(fmt.Sprintf("  %s\n    %s→ %s%s\n", from, color("green"), to, color("white")))
+	}
+
+	if len(plan.Skipped) > 0 {
+		content.WriteString(fmt.Sprintf("\n%sSkipped (unreadable):%s\n", color("red"), color("white")))
+		for _, skipped := range plan.Skipped {
+			content.WriteString("  " + skipped + "\n")
+		}
+	}
+
+	return content.String()
+}
+
+// showOrganizeLibrary previews a re-layout of the library and applies it on request
+func (a *App) showOrganizeLibrary() {
+	if a.denyReadOnlyMedia(homeRoot(), "Organizing the library") {
+		return
+	}
+	templateInput := tview.NewInputField().
+		SetLabel("Template ").
+		SetText(a.appConfig.ImportTemplate).
+		SetFieldWidth(50)
+	preview := tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	preview.SetBorder(true).SetTitle(" Dry run - nothing has been moved yet ")
+
+	var plan *organize.Plan
+	refresh := func() {
+		preview.SetText("[yellow]Reading tags...[white]")
+		var err error
+		plan, err = planLibraryLayout(templateInput.GetText())
+		if err != nil {
+			preview.SetText(fmt.Sprintf("[red]%v[white]", err))
+			return
+		}
+		preview.SetText(formatLayoutPlan(plan, true))
+		preview.ScrollToBeginning()
+	}
+
+	closeScreen := func() {
+		a.pages.RemovePage("organize-library")
+		a.app.SetFocus(a.songList)
+	}
+
+	buttons := tview.NewForm().
+		AddButton("Preview", refresh).
+		AddButton("Apply", func() {
+			if plan == nil || len(plan.Moves) == 0 {
+				a.showMessage("Nothing to move")
+				return
+			}
+			if a.isPlaying || a.isPaused {
+				a.stop()
+			}
+
+			summary, err := applyLibraryLayout(plan, a.playlistManager, a.overrides, a.resumeStore, a.added)
+			a.appConfig.ImportTemplate = templateInput.GetText()
+			a.saveConfig()
+			closeScreen()
+			if a.currentPlaylist != "" {
+				a.loadPlaylist(a.currentPlaylist)
+			} else {
+				a.loadSongs()
+			}
+
+			if err != nil {
+				a.handleError(fmt.Errorf("%s\n%w", summary, err), "Organize Library")
+				return
+			}
+			a.showMessage("✅ " + summary)
+		}).
+		AddButton("Cancel", closeScreen)
+	buttons.SetCancelFunc(closeScreen)
+
+	templateInput.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			refresh()
+		}
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(templateInput, 1, 0, true).
+		AddItem(preview, 0, 1, false).
+		AddItem(buttons, 3, 0, false)
+	layout.SetBorder(true).
+		SetTitle(" Organize Library - Tab to move between fields ").
+		SetTitleAlign(tview.AlignCenter)
+
+	// Tab cycles between the template, the preview and the buttons
+	focusables := []tview.Primitive{templateInput, preview, buttons}
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyTab {
+			return event
+		}
+		for i, item := range focusables {
+			if item.HasFocus() {
+				a.app.SetFocus(focusables[(i+1)%len(focusables)])
+				return nil
+			}
+		}
+		a.app.SetFocus(templateInput)
+		return nil
+	})
+
+	refresh()
+	a.pages.AddPage("organize-library", centered(layout, 90, 30), true, true)
+	a.app.SetFocus(templateInput)
+}
+
+// runOrganizeCommand handles "tuneminal organize"
+func runOrganizeCommand(args []string, readOnly bool) error {
+	flags := flag.NewFlagSet("organize", flag.ContinueOnError)
+	template := flags.String("template", organize.DefaultTemplate, "naming template")
+	apply := flags.Bool("apply", false, "move the files (default is a dry run)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	plan, err := planLibraryLayout(*template)
+	if err != nil {
+		return err
+	}
+	fmt.Print(formatLayoutPlan(plan, false))
+
+	if !*apply {
+		fmt.Println("\nDry run only; pass -apply to move the files.")
+		return nil
+	}
+	if readOnly {
+		return errReadOnly
+	}
+
+	summary, err := applyLibraryLayout(plan, playlist.NewPlaylistManager(), overrides.NewStore(), resume.NewStore(), added.NewStore())
+	fmt.Println("\n" + summary)
+	return err
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/osc"
+)
+
+// oscPrefix starts the address of every OSC message Tuneminal acts on
+const oscPrefix = "/tuneminal/"
+
+// startOSC (re)starts listening for OSC control messages on the configured
+// address, so a control surface can drive playback
+func (a *App) startOSC() {
+	if a.oscServer != nil {
+		a.oscServer.Close()
+		a.oscServer = nil
+	}
+	if a.appConfig.OSCAddress == "" {
+		return
+	}
+
+	server, err := osc.Listen(a.appConfig.OSCAddress, func(message osc.Message) {
+		a.app.QueueUpdateDraw(func() {
+			a.handleOSC(message)
+		})
+	})
+	if err != nil {
+		a.errorLog.Add("OSC", err)
+		a.showToast(fmt.Sprintf("[red]❌ OSC control is off: %v[white]", err))
+		return
+	}
+	a.oscServer = server
+}
+
+// handleOSC carries out an OSC message:
+//
+//	/tuneminal/play [title]       play, or play the song with that title or path
+//	/tuneminal/pause, /toggle, /stop, /next, /previous
+//	/tuneminal/volume 0-1         set the volume
+//	/tuneminal/seek seconds       go to a time in the song
+//	/tuneminal/seek/by seconds    move forward, or back when negative
+//	/tuneminal/position 0-1       go to a point in the song, for faders
+//	/tuneminal/queue title        play that song next
+//
+// Buttons send 1 when pressed and 0 when released, so commands without an
+// argument of their own ignore a 0.
+func (a *App) handleOSC(message osc.Message) {
+	command, ok := strings.CutPrefix(message.Address, oscPrefix)
+	if !ok {
+		return
+	}
+	if value, ok := message.Float(0); ok && value == 0 && oscButtons[command] {
+		return
+	}
+
+	switch command {
+	case "play":
+		if title, ok := message.String(0); ok {
+			if i := a.oscSong(title); i >= 0 {
+				a.currentSong = i
+				a.selectedSong = i
+				a.play()
+			}
+			return
+		}
+		if !a.isPlaying || a.isPaused {
+			a.togglePlayPause()
+		}
+	case "pause":
+		if a.isPlaying && !a.isPaused {
+			a.pause()
+		}
+	case "toggle":
+		a.togglePlayPause()
+	case "stop":
+		a.stop()
+	case "next":
+		a.next()
+	case "previous":
+		a.previous()
+	case "volume":
+		if value, ok := message.Float(0); ok {
+			a.setVolume(value)
+		}
+	case "seek":
+		if seconds, ok := message.Float(0); ok {
+			a.seekToPosition(time.Duration(seconds * float64(time.Second)))
+		}
+	case "seek/by":
+		if seconds, ok := message.Float(0); ok {
+			a.seekToPosition(a.position + time.Duration(seconds*float64(time.Second)))
+		}
+	case "position":
+		if fraction, ok := message.Float(0); ok {
+			a.seekToPosition(time.Duration(fraction * float64(a.duration)))
+		}
+	case "queue":
+		if title, ok := message.String(0); ok {
+			if i := a.oscSong(title); i >= 0 {
+				a.enqueueSong(i, true)
+			}
+		}
+	}
+}
+
+// oscButtons are the commands a button press triggers
+var oscButtons = map[string]bool{
+	"play":     true,
+	"pause":    true,
+	"toggle":   true,
+	"stop":     true,
+	"next":     true,
+	"previous": true,
+}
+
+// oscSong finds the song an OSC message names by path or title, adding it
+// from the library when it isn't in the song list. It returns -1 and says
+// so when there's no such song.
+func (a *App) oscSong(name string) int {
+	if i := a.songIndex(name); i >= 0 {
+		return i
+	}
+	song, ok := a.findLibrarySong(name, "")
+	if !ok {
+		a.showToast(fmt.Sprintf("[yellow]OSC: no song called %q[white]", name))
+		return -1
+	}
+	if i := a.songIndex(song.Path); i >= 0 {
+		return i
+	}
+	a.songs = append(a.songs, song)
+	a.updateSongList()
+	return len(a.songs) - 1
+}
+
+// setVolume sets the volume from 0 to 1. It isn't saved straight away, as
+// a fader sends a stream of values; the next settings save keeps it.
+func (a *App) setVolume(volume float64) {
+	a.stopVolumeRamp()
+	a.volume = max(0, min(1, volume))
+	if a.player != nil {
+		a.player.SetVolume(a.volume)
+	}
+	a.updateNowPlaying()
+}
+
+// seekToPosition moves the playing song to position
+func (a *App) seekToPosition(position time.Duration) {
+	if !a.isPlaying || a.player == nil || a.currentSong < 0 || a.currentSong >= len(a.songs) {
+		return
+	}
+	position = max(0, min(a.duration, position))
+	if err := a.player.SeekTo(position); err == nil {
+		a.position = position
+		a.updateAllDisplays()
+	}
+}
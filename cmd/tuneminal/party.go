@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/history"
+	"github.com/tuneminal/tuneminal/pkg/party"
+)
+
+// awardPartyRun credits a sung song to the singer whose turn it was
+func (a *App) awardPartyRun(play history.Play) {
+	run, ok := a.party.Record(play.Title, play.Score)
+	if !ok {
+		return
+	}
+
+	message := fmt.Sprintf("[yellow]🎤 %s scored %d", run.Singer, run.Score)
+	if run.Adjusted != run.Score {
+		message += fmt.Sprintf(" (%d with handicap)", run.Adjusted)
+	}
+	if next, ok := a.party.Current(); ok {
+		message += " - next up: " + next.Name
+	}
+	go a.app.QueueUpdateDraw(func() {
+		a.statusBar.SetText(message + "[white]")
+	})
+}
+
+// showParty lists the singers in the rotation; a adds one, d removes the
+// selected one, Enter makes it their turn, t toggles team mode and l shows
+// the leaderboard
+func (a *App) showParty() {
+	a.pages.RemovePage("party")
+
+	singers := a.party.Singers()
+	current, _ := a.party.Current()
+	mode := "singers"
+	if a.party.TeamMode() {
+		mode = "teams"
+	}
+
+	list := tview.NewList().ShowSecondaryText(true)
+	list.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Party (%s) - a add, d remove, Enter turn, t teams, l leaderboard ", mode)).
+		SetTitleAlign(tview.AlignCenter)
+	for _, singer := range singers {
+		marker := "  "
+		if singer.Name == current.Name {
+			marker = "▶ "
+		}
+		details := fmt.Sprintf("handicap ×%.2g", singer.Handicap)
+		if singer.Team != "" {
+			details = "team " + singer.Team + ", " + details
+		}
+		list.AddItem(marker+singer.Name, details, 0, nil)
+	}
+	if len(singers) == 0 {
+		list.AddItem("No singers - press a to add one", "Sung songs are credited to each singer in turn", 0, nil)
+	}
+
+	closeList := func() {
+		a.pages.RemovePage("party")
+		a.app.SetFocus(a.songList)
+	}
+
+	list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		if index < len(singers) {
+			a.party.SetCurrent(singers[index].Name)
+			a.updateStatus()
+			a.showParty()
+		}
+	})
+	list.SetDoneFunc(closeList)
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyRune {
+			return event
+		}
+		switch event.Rune() {
+		case 'a':
+			a.showAddSinger()
+			return nil
+		case 'd':
+			if index := list.GetCurrentItem(); index < len(singers) {
+				a.party.RemoveSinger(singers[index].Name)
+				a.updateStatus()
+				a.showParty()
+			}
+			return nil
+		case 't':
+			a.party.SetTeamMode(!a.party.TeamMode())
+			a.showParty()
+			return nil
+		case 'l':
+			a.showLeaderboard()
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("party", centered(list, 76, 20), true, true)
+	a.app.SetFocus(list)
+}
+
+// showAddSinger asks for a new singer's name, team and handicap
+func (a *App) showAddSinger() {
+	name := tview.NewInputField().
+		SetLabel("Name").
+		SetFieldWidth(24)
+	team := tview.NewInputField().
+		SetLabel("Team (optional)").
+		SetFieldWidth(24)
+	handicap := tview.NewInputField().
+		SetLabel(fmt.Sprintf("Handicap (×%.1f-%.1f)", party.MinHandicap, party.MaxHandicap)).
+		SetText("1.0").
+		SetFieldWidth(6).
+		SetAcceptanceFunc(tview.InputFieldFloat)
+
+	closeForm := func() {
+		a.pages.RemovePage("party-add")
+		a.showParty()
+	}
+
+	form := tview.NewForm().
+		AddFormItem(name).
+		AddFormItem(team).
+		AddFormItem(handicap).
+		AddButton("Add", func() {
+			multiplier, err := strconv.ParseFloat(handicap.GetText(), 64)
+			if err != nil {
+				a.showWarning("Handicap must be a number such as 1.5")
+				return
+			}
+			singer := party.Singer{Name: name.GetText(), Team: team.GetText(), Handicap: multiplier}
+			if err := a.party.AddSinger(singer); err != nil {
+				a.showWarning(err.Error())
+				return
+			}
+			a.updateStatus()
+			closeForm()
+		}).
+		AddButton("Cancel", closeForm)
+	form.SetCancelFunc(closeForm)
+
+	form.SetTitle(" Add Singer ").SetBorder(true)
+	a.pages.AddPage("party-add", centered(form, 56, 11), true, true)
+	a.app.SetFocus(form)
+}
+
+// formatLeaderboard renders the party standings
+func formatLeaderboard(standings []party.Standing, teams bool) string {
+	var content strings.Builder
+	if teams {
+		content.WriteString("[yellow]Team leaderboard[white]\n\n")
+	} else {
+		content.WriteString("[yellow]Singer leaderboard[white]\n\n")
+	}
+	if len(standings) == 0 {
+		content.WriteString("No singers yet\n")
+	}
+
+	medals := []string{"🥇", "🥈", "🥉"}
+	for i, standing := range standings {
+		place := fmt.Sprintf("%d.", i+1)
+		if i < len(medals) && standing.Total > 0 {
+			place = medals[i]
+		}
+		content.WriteString(fmt.Sprintf("%s [green]%s[white] - %d points (%d songs, best %d)\n",
+			place, standing.Name, standing.Total, standing.Runs, standing.Best))
+		if len(standing.Members) > 0 {
+			content.WriteString(fmt.Sprintf("    [dim]%s[white]\n", strings.Join(standing.Members, ", ")))
+		}
+	}
+	return content.String()
+}
+
+// showLeaderboard shows the party standings; r starts a new round
+func (a *App) showLeaderboard() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(formatLeaderboard(a.party.Leaderboard(), a.party.TeamMode()))
+	view.SetBorder(true).
+		SetTitle(" Leaderboard - r reset scores, Esc close ").
+		SetTitleAlign(tview.AlignCenter)
+
+	closeView := func() {
+		a.pages.RemovePage("leaderboard")
+		a.showParty()
+	}
+	view.SetDoneFunc(func(key tcell.Key) {
+		closeView()
+	})
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune && event.Rune() == 'r' {
+			a.party.Reset()
+			view.SetText(formatLeaderboard(a.party.Leaderboard(), a.party.TeamMode()))
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("leaderboard", centered(view, 64, 20), true, true)
+	a.app.SetFocus(view)
+}
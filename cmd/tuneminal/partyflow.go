@@ -0,0 +1,209 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/bigfont"
+)
+
+// partyFlowSkipAt returns where party flow moves on from the playing song:
+// the configured outro cut off the end, or a while after the last lyric
+// line, whichever comes first. It's false when neither applies.
+func (a *App) partyFlowSkipAt() (time.Duration, bool) {
+	cfg := a.appConfig
+	if !cfg.PartyFlow || a.duration <= 0 {
+		return 0, false
+	}
+
+	skipAt, ok := a.duration, false
+	if cfg.PartyFlowOutroSeconds > 0 {
+		skipAt, ok = a.duration-time.Duration(cfg.PartyFlowOutroSeconds)*time.Second, true
+	}
+	if cfg.PartyFlowAfterLyricsSeconds > 0 {
+		if last, found := a.lastLyricTime(); found {
+			afterLyrics := last + time.Duration(cfg.PartyFlowAfterLyricsSeconds)*time.Second
+			if afterLyrics < skipAt {
+				skipAt, ok = afterLyrics, true
+			}
+		}
+	}
+	return skipAt, ok
+}
+
+// lastLyricTime returns when the last line with words starts. Songs without
+// lyrics only have the "No lyrics available" placeholder, which isn't counted.
+func (a *App) lastLyricTime() (time.Duration, bool) {
+	if a.currentSong < 0 || a.currentSong >= len(a.songs) || a.songs[a.currentSong].LyricsPath == "" {
+		return 0, false
+	}
+	for i := len(a.lyricLines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(a.lyricLines[i].Text) != "" {
+			return a.lyricLines[i].Time, true
+		}
+	}
+	return 0, false
+}
+
+// partyFlowCountdown returns how long is left before party flow moves on,
+// while the countdown is showing
+func (a *App) partyFlowCountdown() (time.Duration, bool) {
+	if a.partyFlowCancelled || !a.isPlaying || a.isPaused {
+		return 0, false
+	}
+	skipAt, ok := a.partyFlowSkipAt()
+	if !ok {
+		return 0, false
+	}
+	left := skipAt - a.position
+	countdown := time.Duration(a.appConfig.PartyFlowCountdownSeconds) * time.Second
+	if left > countdown {
+		return 0, false
+	}
+	return max(left, 0), true
+}
+
+// checkPartyFlow moves on to the next song once the countdown runs out
+func (a *App) checkPartyFlow() {
+	if left, ok := a.partyFlowCountdown(); ok && left <= 0 {
+		a.partyFlowCancelled = true
+		a.next()
+	}
+}
+
+// startPartyFlow gets party flow ready for a song that has just started,
+// skipping a long intro to shortly before the first lyric line
+func (a *App) startPartyFlow() {
+	a.partyFlowCancelled = false
+	lead := time.Duration(a.appConfig.PartyFlowIntroSeconds) * time.Second
+	if !a.appConfig.PartyFlow || lead <= 0 || a.position > 0 {
+		return
+	}
+	if a.currentSong < 0 || a.currentSong >= len(a.songs) || a.songs[a.currentSong].LyricsPath == "" {
+		return
+	}
+	// A lyrics-only song keeps its count-in, or its timing with the band
+	if isLyricsOnly(a.songs[a.currentSong]) {
+		return
+	}
+
+	for _, line := range a.lyricLines {
+		if strings.TrimSpace(line.Text) == "" {
+			continue
+		}
+		if line.Time > lead {
+			if err := a.player.SeekTo(line.Time - lead); err == nil {
+				a.position = line.Time - lead
+			}
+		}
+		return
+	}
+}
+
+// cancelPartyFlow lets the playing song run to its end
+func (a *App) cancelPartyFlow() {
+	if _, ok := a.partyFlowCountdown(); !ok {
+		return
+	}
+	a.partyFlowCancelled = true
+	a.showToast("⏭ Party flow: playing this song to the end")
+	a.updateKaraokeLyrics()
+}
+
+// createPartyFlowDisplay shows the countdown to the next song in the lyrics
+// panel, or "" when there is none
+func (a *App) createPartyFlowDisplay() string {
+	left, ok := a.partyFlowCountdown()
+	if !ok {
+		return ""
+	}
+
+	seconds := strconv.Itoa(int((left + time.Second - 1) / time.Second))
+	lines := []string{"[yellow::b]⏭  NEXT SONG IN[white::-]", ""}
+	for _, row := range bigfont.Render(seconds, 40) {
+		lines = append(lines, "[yellow]"+row+"[white]")
+	}
+	lines = append(lines, "", "[gray]Shift+C to keep listening[white]")
+
+	_, _, _, height := a.lyrics.GetInnerRect()
+	padding := max(0, (height-len(lines))/2)
+	return strings.Repeat("\n", padding) + strings.Join(lines, "\n")
+}
+
+// showPartyFlow edits the party flow settings
+func (a *App) showPartyFlow() {
+	enabled := tview.NewCheckbox().
+		SetLabel("Party flow").
+		SetChecked(a.appConfig.PartyFlow)
+	secondsField := func(label string, value int) *tview.InputField {
+		return tview.NewInputField().
+			SetLabel(label).
+			SetText(strconv.Itoa(value)).
+			SetFieldWidth(4).
+			SetAcceptanceFunc(tview.InputFieldInteger)
+	}
+	intro := secondsField("Start before first line (s, 0 = off)", a.appConfig.PartyFlowIntroSeconds)
+	afterLyrics := secondsField("Move on after last line (s, 0 = off)", a.appConfig.PartyFlowAfterLyricsSeconds)
+	outro := secondsField("Cut from the end (s, 0 = off)", a.appConfig.PartyFlowOutroSeconds)
+	countdown := secondsField("Countdown (s)", a.appConfig.PartyFlowCountdownSeconds)
+
+	closePanel := func() {
+		a.pages.RemovePage("party-flow")
+		a.app.SetFocus(a.songList)
+	}
+
+	form := tview.NewForm().
+		AddFormItem(enabled).
+		AddFormItem(intro).
+		AddFormItem(afterLyrics).
+		AddFormItem(outro).
+		AddFormItem(countdown).
+		AddButton("Save", func() {
+			seconds := func(field *tview.InputField, name string) (int, bool) {
+				value, err := strconv.Atoi(field.GetText())
+				if err != nil || value < 0 || value > 300 {
+					a.showWarning(name + " must be between 0 and 300 seconds")
+					return 0, false
+				}
+				return value, true
+			}
+			introSeconds, ok := seconds(intro, "Start before first line")
+			if !ok {
+				return
+			}
+			afterSeconds, ok := seconds(afterLyrics, "Move on after last line")
+			if !ok {
+				return
+			}
+			outroSeconds, ok := seconds(outro, "Cut from the end")
+			if !ok {
+				return
+			}
+			countdownSeconds, ok := seconds(countdown, "Countdown")
+			if !ok {
+				return
+			}
+
+			a.appConfig.PartyFlow = enabled.IsChecked()
+			a.appConfig.PartyFlowIntroSeconds = introSeconds
+			a.appConfig.PartyFlowAfterLyricsSeconds = afterSeconds
+			a.appConfig.PartyFlowOutroSeconds = outroSeconds
+			a.appConfig.PartyFlowCountdownSeconds = countdownSeconds
+			a.saveConfig()
+
+			closePanel()
+			if a.appConfig.PartyFlow {
+				a.showToast("[green]⏭ Party flow on: songs move on by themselves[white]")
+			} else {
+				a.showToast("⏭ Party flow off")
+			}
+		}).
+		AddButton("Cancel", closePanel)
+	form.SetCancelFunc(closePanel)
+
+	form.SetTitle(" Party Flow ").SetBorder(true)
+	a.pages.AddPage("party-flow", centered(form, 56, 17), true, true)
+	a.app.SetFocus(form)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// sessionPlay is a song started this session
+type sessionPlay struct {
+	song    Song
+	started time.Time
+}
+
+// notePlayHistory remembers a song that just started, for previous to step
+// back to and for the history view. Stepping back to a song doesn't add it
+// again, so previous keeps going further back.
+func (a *App) notePlayHistory(song Song) {
+	a.sessionPlays = append(a.sessionPlays, sessionPlay{song: song, started: time.Now()})
+	if n := len(a.backStack); n == 0 || a.backStack[n-1] != song.Path {
+		a.backStack = append(a.backStack, song.Path)
+	}
+}
+
+// previousPlayed drops the current song from the back stack and returns the
+// song played before it, skipping any no longer in the list, or -1 when
+// nothing was
+func (a *App) previousPlayed() int {
+	if n := len(a.backStack); n > 0 && a.currentSong >= 0 && a.currentSong < len(a.songs) &&
+		a.backStack[n-1] == a.songs[a.currentSong].Path {
+		a.backStack = a.backStack[:n-1]
+	}
+	for len(a.backStack) > 0 {
+		if i := a.songIndex(a.backStack[len(a.backStack)-1]); i >= 0 {
+			return i
+		}
+		a.backStack = a.backStack[:len(a.backStack)-1]
+	}
+	return -1
+}
+
+// formatPlayHistory lists the songs played this session, latest first
+func (a *App) formatPlayHistory() string {
+	if len(a.sessionPlays) == 0 {
+		return "[gray]Nothing has played yet this session.[white]"
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "[yellow]%d plays this session • p steps back through them[white]\n\n", len(a.sessionPlays))
+	for i := len(a.sessionPlays) - 1; i >= 0; i-- {
+		play := a.sessionPlays[i]
+		label := play.song.Title
+		if play.song.Artist != "" {
+			label += " - " + play.song.Artist
+		}
+		fmt.Fprintf(&text, "[cyan]%s[white]  %s\n", play.started.Format("15:04"), tview.Escape(label))
+	}
+	return text.String()
+}
+
+// showPlayHistory shows the songs played this session
+func (a *App) showPlayHistory() {
+	view := tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	view.SetBorder(true).
+		SetTitle(" Played This Session - Esc to close ").
+		SetTitleAlign(tview.AlignCenter)
+	view.SetText(a.formatPlayHistory())
+
+	view.SetDoneFunc(func(key tcell.Key) {
+		a.pages.RemovePage("play-history")
+		a.app.SetFocus(a.songList)
+	})
+
+	a.pages.AddPage("play-history", centered(view, 76, 22), true, true)
+	a.app.SetFocus(view)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Playlist end modes, set by playlist_end in the config
+const (
+	endStop     = "stop"
+	endRepeat   = "repeat"
+	endAutoDJ   = "autodj"
+	endShutdown = "shutdown"
+)
+
+// playlistEndModes are the playlist end modes in the order the settings offer them
+var playlistEndModes = []string{endStop, endRepeat, endAutoDJ, endShutdown}
+
+// playlistEndLabels describe each playlist end mode for the status bar
+var playlistEndLabels = map[string]string{
+	endStop:     "⏹ stop",
+	endRepeat:   "🔁 repeat",
+	endAutoDJ:   "🎲 auto-DJ",
+	endShutdown: "🌙 fade & quit",
+}
+
+// shutdownFade is how long the last song fades out before the app quits
+const shutdownFade = 10 * time.Second
+
+// autoDJRecent is how many of the latest plays auto-DJ keeps from coming
+// round again, at most
+const autoDJRecent = 10
+
+// playlistEndMode returns the configured playlist end mode
+func (a *App) playlistEndMode() string {
+	if _, ok := playlistEndLabels[a.appConfig.PlaylistEnd]; ok {
+		return a.appConfig.PlaylistEnd
+	}
+	return endStop
+}
+
+// playlistEnded acts on the end of a playlist, once its last song has
+// played to the end. Songs played from the library stop as before.
+func (a *App) playlistEnded() {
+	if a.currentPlaylist == "" && !a.autoDJ {
+		return
+	}
+	switch a.playlistEndMode() {
+	case endAutoDJ:
+		next := a.autoDJPick()
+		if next < 0 {
+			a.autoDJ = false
+			return
+		}
+		if !a.autoDJ {
+			a.showToast("[cyan]🎲 Playlist done - auto-DJ takes over[white]")
+		}
+		a.autoDJ = true
+		a.currentSong = next
+		a.updateSongList()
+		a.play()
+	case endShutdown:
+		a.quit()
+	}
+}
+
+// autoDJPick chooses a song at random from the list, leaving out those
+// played lately and lyrics-only songs, which wait for the band. It is -1
+// when there is nothing to choose.
+func (a *App) autoDJPick() int {
+	recent := make(map[string]bool)
+	keep := min(autoDJRecent, len(a.songs)/2)
+	for i := len(a.sessionPlays) - 1; i >= 0 && len(recent) < keep; i-- {
+		recent[a.sessionPlays[i].song.Path] = true
+	}
+
+	var choices []int
+	for i, song := range a.songs {
+		if !recent[song.Path] && !isLyricsOnly(song) && i != a.currentSong {
+			choices = append(choices, i)
+		}
+	}
+	if len(choices) == 0 {
+		return -1
+	}
+	return choices[rand.Intn(len(choices))]
+}
+
+// fadeBeforeShutdown fades out the last song of the playlist when the app
+// is to quit after it. It runs on every playback tick.
+func (a *App) fadeBeforeShutdown() {
+	last := a.currentPlaylist != "" && a.playlistEndMode() == endShutdown && a.autoAdvanceIndex() < 0
+	left := a.duration - a.position
+	if last && left < shutdownFade {
+		a.player.SetLiveGain(float64(left) / float64(shutdownFade))
+		a.endFading = true
+	} else if a.endFading {
+		a.player.SetLiveGain(1)
+		a.endFading = false
+	}
+}
+
+// playlistEndStatus describes what happens when the playlist runs out, for
+// the status bar, or "" when no playlist is playing
+func (a *App) playlistEndStatus() string {
+	if a.currentPlaylist == "" && !a.autoDJ {
+		return ""
+	}
+	return "End: " + playlistEndLabels[a.playlistEndMode()]
+}
@@ -0,0 +1,42 @@
+package main
+
+import "github.com/tuneminal/tuneminal/pkg/remotefs"
+
+// loadSong loads a song into the player, from memory when it was prefetched
+// while the song before it played
+func (a *App) loadSong(song Song) error {
+	if isLyricsOnly(song) {
+		return a.player.LoadSilence(song.Path, song.Duration)
+	}
+	path := a.playbackPath(song)
+	if data, ok := a.prefetcher.Take(path); ok {
+		return a.player.LoadData(path, data)
+	}
+	return a.player.LoadFile(path)
+}
+
+// prefetchNext reads the song that plays after the current one into memory
+// in the background, and into the song cache when that is on for it
+func (a *App) prefetchNext() {
+	if a.appConfig.PrefetchMB <= 0 || len(a.songs) < 2 {
+		return
+	}
+	// The song that follows by itself, or else the likely next press
+	next := a.autoAdvanceIndex()
+	if next < 0 {
+		next = a.nextInOrder(a.currentSong, true)
+	}
+	if next < 0 || next == a.currentSong {
+		return
+	}
+	song := a.songs[next]
+	if isLyricsOnly(song) {
+		return
+	}
+	if remotefs.IsRemote(song.Path) {
+		a.prefetchRemoteSong(song)
+		return
+	}
+	limit := int64(a.appConfig.PrefetchMB) << 20
+	go a.prefetcher.Prefetch(a.playbackPath(song), limit)
+}
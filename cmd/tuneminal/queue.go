@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// queuePanel is the queue while it is shown, refreshed as songs play
+type queuePanel struct {
+	list *tview.List
+	info *tview.TextView
+}
+
+// queuedSongs returns the queued songs that are in the song list, in the
+// order they will play. Songs queued from another list wait until it is
+// loaded again.
+func (a *App) queuedSongs() []int {
+	var queued []int
+	for _, path := range a.playQueue.Songs() {
+		if i := a.songIndex(path); i >= 0 {
+			queued = append(queued, i)
+		}
+	}
+	return queued
+}
+
+// enqueueSong adds song i to the end of the queue, or to its front when next
+func (a *App) enqueueSong(i int, next bool) {
+	if i < 0 || i >= len(a.songs) {
+		return
+	}
+	song := a.songs[i]
+	if next {
+		a.playQueue.EnqueueNext(song.Path)
+		a.showToast(fmt.Sprintf("[green]⏭ %s plays next[white]", song.Title))
+	} else {
+		a.playQueue.Enqueue(song.Path)
+		a.showToast(fmt.Sprintf("[green]➕ %s queued (%d waiting)[white]", song.Title, a.playQueue.Len()))
+	}
+	a.queueChanged()
+}
+
+// takeFromQueue takes a song that has started out of the queue. In repeat
+// mode it goes back at the end, so the queue goes round.
+func (a *App) takeFromQueue(song Song) {
+	if a.playQueue.RemoveSong(song.Path) {
+		if a.repeatMode {
+			a.playQueue.Enqueue(song.Path)
+		}
+		a.updateQueueView()
+	}
+}
+
+// queueChanged hands the player the new next song and redraws the queue
+func (a *App) queueChanged() {
+	a.prefetchNext()
+	if a.isPlaying || a.isPaused {
+		a.queueNextSong()
+	}
+	a.updateQueueView()
+	a.updateNowPlaying()
+}
+
+// upcomingSongs returns the songs that will play after the current one by
+// themselves, in order: the queue, then the rest of the list after the last
+// of them in play order, and in repeat mode the songs before it after that. A queue in
+// repeat mode goes round by itself.
+func (a *App) upcomingSongs() []int {
+	if a.repeatOne {
+		return nil
+	}
+	upcoming := a.queuedSongs()
+	if len(upcoming) > 0 && a.repeatMode {
+		return upcoming
+	}
+	from := a.currentSong
+	if len(upcoming) > 0 {
+		from = upcoming[len(upcoming)-1]
+	}
+	if from < 0 || a.autoDJ || (!a.repeatMode && a.currentPlaylist == "") {
+		return upcoming
+	}
+	order := a.playOrder()
+	position := slices.Index(order, from)
+	upcoming = append(upcoming, order[position+1:]...)
+	if a.repeatMode || a.playlistEndMode() == endRepeat {
+		upcoming = append(upcoming, order[:max(0, position)]...)
+	}
+	return upcoming
+}
+
+// queueStarts returns when each upcoming song is expected to start, going by
+// what is left of the current song and the length of those before it. A
+// paused song holds everything back until it carries on, so the times are
+// worked out from now either way.
+func (a *App) queueStarts(upcoming []int, now time.Time) []time.Time {
+	at := now
+	if a.isPlaying || a.isPaused {
+		at = at.Add(max(0, a.duration-a.position))
+	}
+	starts := make([]time.Time, len(upcoming))
+	for i, index := range upcoming {
+		starts[i] = at
+		at = at.Add(a.songs[index].Duration)
+	}
+	return starts
+}
+
+// queueSummary describes how long the queue has left and when it ends, or ""
+// when nothing follows the current song
+func (a *App) queueSummary() string {
+	upcoming := a.upcomingSongs()
+	if len(upcoming) == 0 {
+		return ""
+	}
+	now := time.Now()
+	starts := a.queueStarts(upcoming, now)
+	end := starts[len(starts)-1].Add(a.songs[upcoming[len(upcoming)-1]].Duration)
+	songs := "songs"
+	if len(upcoming) == 1 {
+		songs = "song"
+	}
+	return fmt.Sprintf("%d %s, %s (until ~%s)", len(upcoming), songs, utils.FormatDuration(end.Sub(now)), end.Format("15:04"))
+}
+
+// songLabel names a song as "Title - Artist"
+func songLabel(song Song) string {
+	if song.Artist == "" {
+		return song.Title
+	}
+	return song.Title + " - " + song.Artist
+}
+
+// fillQueuePanel lists the queued songs with the clock time each should
+// start, and sums up what plays after them
+func (a *App) fillQueuePanel(panel *queuePanel) {
+	current := panel.list.GetCurrentItem()
+	panel.list.Clear()
+
+	upcoming := a.upcomingSongs()
+	starts := a.queueStarts(upcoming, time.Now())
+	next := 0
+	for _, path := range a.playQueue.Songs() {
+		i := a.songIndex(path)
+		if i < 0 {
+			panel.list.AddItem(fmt.Sprintf("[gray]  --:--  %s (not in this list)[white]", tview.Escape(path)), "", 0, nil)
+			continue
+		}
+		song := a.songs[i]
+		panel.list.AddItem(fmt.Sprintf("[cyan]~%s[white]  %s [gray](%s)[white]",
+			starts[next].Format("15:04"), tview.Escape(songLabel(song)), utils.FormatDuration(song.Duration)), "", 0, nil)
+		next++
+	}
+	if current < panel.list.GetItemCount() {
+		panel.list.SetCurrentItem(current)
+	}
+
+	var info strings.Builder
+	switch {
+	case len(upcoming) == 0:
+		info.WriteString("[gray]Nothing plays after this song by itself.\nPress Q on a song to queue it, or pick Play next from its menu (o).[white]")
+	case next < len(upcoming):
+		fmt.Fprintf(&info, "[yellow]Up next: %s[white]\n", a.queueSummary())
+		fmt.Fprintf(&info, "[gray]then on down the list from %s at ~%s[white]",
+			tview.Escape(songLabel(a.songs[upcoming[next]])), starts[next].Format("15:04"))
+	default:
+		fmt.Fprintf(&info, "[yellow]Up next: %s[white]", a.queueSummary())
+	}
+	panel.info.SetText(info.String())
+}
+
+// showQueue shows the queue, where the singing order is put together, and
+// when each song should start, so the host can tell singers when their turn
+// comes. It keeps up as songs play.
+func (a *App) showQueue() {
+	panel := &queuePanel{
+		list: tview.NewList().ShowSecondaryText(false),
+		info: tview.NewTextView().SetDynamicColors(true),
+	}
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(panel.list, 0, 1, true).
+		AddItem(panel.info, 3, 0, false)
+	layout.SetBorder(true).
+		SetTitle(" Queue - Shift+↑/↓ move • d remove • c clear • Enter play now • Esc close ").
+		SetTitleAlign(tview.AlignCenter)
+
+	closeQueue := func() {
+		a.pages.RemovePage("queue")
+		a.queuePanel = nil
+		a.app.SetFocus(a.songList)
+	}
+	panel.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		selected := panel.list.GetCurrentItem()
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			closeQueue()
+			return nil
+		case event.Key() == tcell.KeyUp && event.Modifiers()&tcell.ModShift != 0:
+			if a.playQueue.Move(selected, selected-1) {
+				panel.list.SetCurrentItem(selected - 1)
+				a.queueChanged()
+			}
+			return nil
+		case event.Key() == tcell.KeyDown && event.Modifiers()&tcell.ModShift != 0:
+			if a.playQueue.Move(selected, selected+1) {
+				panel.list.SetCurrentItem(selected + 1)
+				a.queueChanged()
+			}
+			return nil
+		case event.Key() == tcell.KeyDelete || event.Rune() == 'd':
+			if a.playQueue.Remove(selected) {
+				a.queueChanged()
+			}
+			return nil
+		case event.Rune() == 'c':
+			a.playQueue.Clear()
+			a.queueChanged()
+			return nil
+		case event.Key() == tcell.KeyEnter:
+			songs := a.playQueue.Songs()
+			if selected < len(songs) {
+				if i := a.songIndex(songs[selected]); i >= 0 {
+					closeQueue()
+					a.currentSong = i
+					a.updateSongList()
+					a.playOrCue()
+				}
+			}
+			return nil
+		}
+		return event
+	})
+
+	a.queuePanel = panel
+	a.fillQueuePanel(panel)
+	a.pages.AddPage("queue", centered(layout, 84, 22), true, true)
+	a.app.SetFocus(panel.list)
+}
+
+// updateQueueView refreshes the queue while it is shown
+func (a *App) updateQueueView() {
+	if a.queuePanel != nil {
+		a.fillQueuePanel(a.queuePanel)
+	}
+}
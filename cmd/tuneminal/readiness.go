@@ -0,0 +1,179 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+)
+
+// gradeColors colors readiness grades in the report
+var gradeColors = map[string]string{
+	"A": "green",
+	"B": "green",
+	"C": "yellow",
+	"D": "orange",
+	"F": "red",
+}
+
+// checkLibraryReadiness grades the lyrics of every library song, worst first
+func checkLibraryReadiness(opts lyrics.ReadinessOptions) ([]lyrics.Readiness, error) {
+	files, err := libraryFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]lyrics.Readiness, 0, len(files))
+	for _, path := range files {
+		results = append(results, lyrics.CheckReadiness(path, opts))
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Grade > results[j].Grade
+	})
+	return results, nil
+}
+
+// gradeCounts tallies how many songs got each grade
+func gradeCounts(results []lyrics.Readiness) string {
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Grade]++
+	}
+
+	var parts []string
+	for _, grade := range []string{"A", "B", "C", "D", "F"} {
+		if counts[grade] > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", grade, counts[grade]))
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// formatIssue renders one issue with its line number, if it has one
+func formatIssue(issue lyrics.Issue) string {
+	if issue.Line > 0 {
+		return fmt.Sprintf("line %d: %s", issue.Line, issue.Message)
+	}
+	return issue.Message
+}
+
+// formatReadiness renders the readiness report for the command line
+func formatReadiness(results []lyrics.Readiness) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Karaoke readiness for %d songs (%s)\n\n", len(results), gradeCounts(results)))
+	for _, result := range results {
+		content.WriteString(fmt.Sprintf("%s  %s\n", result.Grade, result.AudioPath))
+		for _, issue := range result.Issues {
+			content.WriteString("     " + formatIssue(issue) + "\n")
+		}
+	}
+	return content.String()
+}
+
+// readinessWidth is the widest lyric line that fits the lyrics panel
+func (a *App) readinessWidth() int {
+	_, _, width, _ := a.lyrics.GetInnerRect()
+	if width <= 0 {
+		return lyrics.DefaultReadinessOptions.MaxWidth
+	}
+	return width
+}
+
+// showReadiness lists each song's readiness grade and issues; Enter opens
+// the lyric editor on the song to fix them
+func (a *App) showReadiness() {
+	opts := lyrics.DefaultReadinessOptions
+	opts.MaxWidth = a.readinessWidth()
+
+	results, err := checkLibraryReadiness(opts)
+	if err != nil {
+		a.handleError(err, "Karaoke Readiness")
+		return
+	}
+
+	list := tview.NewList().ShowSecondaryText(true)
+	list.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Karaoke Readiness (%s) - Enter to fix, Esc close ", gradeCounts(results))).
+		SetTitleAlign(tview.AlignCenter)
+
+	for _, result := range results {
+		result := result
+		label := fmt.Sprintf("[%s]%s[white]  %s", gradeColors[result.Grade], result.Grade, filepath.Base(result.AudioPath))
+		secondary := "    ready to sing"
+		if len(result.Issues) > 0 {
+			secondary = "    " + formatIssue(result.Issues[0])
+			if len(result.Issues) > 1 {
+				secondary += fmt.Sprintf(" (+%d more)", len(result.Issues)-1)
+			}
+		}
+		list.AddItem(label, secondary, 0, func() {
+			a.pages.RemovePage("readiness")
+			a.fixLyrics(result.AudioPath)
+		})
+	}
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			a.pages.RemovePage("readiness")
+			a.app.SetFocus(a.songList)
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("readiness", centered(list, 90, 26), true, true)
+	a.app.SetFocus(list)
+}
+
+// fixLyrics selects a song and opens the lyric editor on it
+func (a *App) fixLyrics(path string) {
+	for i, song := range a.songs {
+		if song.Path == path {
+			a.selectSong(i)
+			a.openLyricsEditor()
+			return
+		}
+	}
+	a.showWarning("Song isn't in the current list: " + filepath.Base(path))
+	a.app.SetFocus(a.songList)
+}
+
+// runCheckCommand handles "tuneminal lyrics check"; it exits non-zero when a
+// song isn't singable
+func runCheckCommand(args []string) error {
+	opts := lyrics.DefaultReadinessOptions
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		opts.MaxWidth = columns
+	}
+
+	flags := flag.NewFlagSet("lyrics check", flag.ContinueOnError)
+	flags.IntVar(&opts.MaxWidth, "width", opts.MaxWidth, "widest lyric line that fits on screen")
+	flags.DurationVar(&opts.MaxGap, "gap", opts.MaxGap, "longest gap between lines without a break marker")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	results, err := checkLibraryReadiness(opts)
+	if err != nil {
+		return err
+	}
+	fmt.Print(formatReadiness(results))
+
+	failed := 0
+	for _, result := range results {
+		if result.Grade == "F" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d song(s) have nothing to sing along to", failed)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/tuneminal/tuneminal/pkg/config"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// errReadOnly is returned by commands that would change files in read-only mode
+var errReadOnly = errors.New("read-only mode: files and settings can't be changed")
+
+// configReadOnly reports whether the saved config asks for read-only mode,
+// or can't be read to say it doesn't. A missing config isn't created, since
+// that would be a write.
+func configReadOnly() bool {
+	readOnly, _ := config.Lockdown(config.GetConfigPath())
+	return readOnly
+}
+
+// denyReadOnly tells the user an action is turned off and returns true in
+// read-only mode, so callers can return straight away
+func (a *App) denyReadOnly(action string) bool {
+	if !a.readOnly {
+		return false
+	}
+	a.showToast("[yellow]🔒 " + action + " is turned off in read-only mode[white]")
+	return true
+}
+
+// readOnlyKeys are the main screen keys read-only mode turns off, named as
+// keyLabel names them, with what they do. The help leaves them out.
+var readOnlyKeys = map[string]string{
+	"e":       "Editing lyrics",
+	"f":       "File management",
+	"y":       "Importing lyrics",
+	"Shift+H": "Hiding songs",
+	"Shift+U": "Unhiding songs",
+	"Shift+W": "Watch folder import",
+	"Shift+O": "Organizing the library",
+	"Ctrl+Z":  "Undo",
+	"Ctrl+Y":  "Redo",
+}
+
+// readOnlyAction returns what a main screen key does when read-only mode
+// turns it off
+func readOnlyAction(event *tcell.EventKey) (string, bool) {
+	action, off := readOnlyKeys[keyLabel(event)]
+	return action, off
+}
+
+// keyLabel names a key the way the help does: "e", "Shift+H" or "Ctrl+Z"
+func keyLabel(event *tcell.EventKey) string {
+	if event.Key() != tcell.KeyRune {
+		return strings.Replace(tcell.KeyNames[event.Key()], "-", "+", 1)
+	}
+	if unicode.IsUpper(event.Rune()) {
+		return "Shift+" + string(event.Rune())
+	}
+	return string(event.Rune())
+}
+
+// readOnlyMediaHint says what to do about a folder that can't be written
+const readOnlyMediaHint = "It may be on a read-only drive, such as a CD or a locked SD card, or belong to another user. Remount the drive writable or fix the folder's permissions, then reload the library with r."
+
+// canWrite reports whether files can be changed in dir, or for a folder
+// that doesn't exist yet whether it could be made, remembering the answer
+// until the library is next loaded
+func (a *App) canWrite(dir string) bool {
+	if writable, ok := a.writableDirs[dir]; ok {
+		return writable
+	}
+	if a.writableDirs == nil {
+		a.writableDirs = make(map[string]bool)
+	}
+	existing := dir
+	for {
+		if _, err := os.Stat(existing); !os.IsNotExist(err) {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	writable := utils.DirWritable(existing)
+	a.writableDirs[dir] = writable
+	return writable
+}
+
+// checkLibraryWritable finds out up front which library folders can't be
+// written, so actions that would fail there are turned off before they're
+// tried, and says so once
+func (a *App) checkLibraryWritable() {
+	a.writableDirs = nil
+	var locked []string
+	for _, root := range libraryRoots() {
+		if _, err := os.Stat(root); err == nil && !a.canWrite(root) {
+			locked = append(locked, root)
+		}
+	}
+	wasReadOnly := a.readOnlyMedia
+	a.readOnlyMedia = len(locked) > 0
+	if a.readOnlyMedia && !wasReadOnly && !a.readOnly {
+		a.showToast("[yellow]🔒 " + strings.Join(locked, ", ") + " can't be written: moving, renaming and deleting songs there is off, and edited lyrics go to the lyrics folder[white]")
+	}
+}
+
+// denyReadOnlyMedia is denyReadOnly for actions that change the files in
+// dir, also turning them down when dir can't be written and saying what to
+// do about it
+func (a *App) denyReadOnlyMedia(dir, action string) bool {
+	if a.denyReadOnly(action) {
+		return true
+	}
+	if a.canWrite(dir) {
+		return false
+	}
+	a.showWarning("🔒 " + action + " is turned off: " + dir + " can't be written.\n\n" + readOnlyMediaHint)
+	return true
+}
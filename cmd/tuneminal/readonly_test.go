@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestReadOnlyAction(t *testing.T) {
+	tests := []struct {
+		event *tcell.EventKey
+		off   bool
+	}{
+		{tcell.NewEventKey(tcell.KeyRune, 'O', tcell.ModShift), true},
+		{tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone), false},
+		{tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone), true},
+		{tcell.NewEventKey(tcell.KeyRune, 'E', tcell.ModShift), false},
+		{tcell.NewEventKey(tcell.KeyRune, 'H', tcell.ModShift), true},
+		{tcell.NewEventKey(tcell.KeyRune, 'h', tcell.ModNone), false},
+		{tcell.NewEventKey(tcell.KeyCtrlZ, 0, tcell.ModCtrl), true},
+		{tcell.NewEventKey(tcell.KeyCtrlY, 0, tcell.ModCtrl), true},
+		{tcell.NewEventKey(tcell.KeyCtrlE, 0, tcell.ModCtrl), false},
+		{tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone), false},
+	}
+	for _, tt := range tests {
+		if _, off := readOnlyAction(tt.event); off != tt.off {
+			t.Errorf("%s: turned off %v, want %v", keyLabel(tt.event), off, tt.off)
+		}
+	}
+}
+
+func TestReadOnlyKeysInHelp(t *testing.T) {
+	// Every key read-only mode turns off is one the help lists, so it's
+	// named the same way and left out of the read-only help
+	listed := make(map[string]bool)
+	for _, section := range helpSections {
+		for _, entry := range section.entries {
+			listed[entry.keys] = true
+		}
+	}
+	for key := range readOnlyKeys {
+		if !listed[key] {
+			t.Errorf("%s is turned off in read-only mode but not in the help", key)
+		}
+	}
+}
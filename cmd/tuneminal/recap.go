@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/export"
+	"github.com/tuneminal/tuneminal/pkg/history"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// minRecordedPlay is how long a song must play before it counts in the history
+const minRecordedPlay = 30 * time.Second
+
+// recapLimit is how many top songs and best scores a recap lists
+const recapLimit = 5
+
+// startPlayRecord notes the song that just started, to log it once it stops
+func (a *App) startPlayRecord(song Song) {
+	a.playSong = song
+	a.playStarted = time.Now()
+}
+
+// recordPlay logs the song being played to the history, with its score if
+// it was sung, and credits a sung song to the party singer whose turn it
+// was and keeps it for performance exports. It is called whenever playback
+// of a song ends.
+func (a *App) recordPlay() {
+	started := a.playStarted
+	a.playStarted = time.Time{}
+	if started.IsZero() || a.position < minRecordedPlay {
+		return
+	}
+
+	song := a.playSong
+	play := history.Play{
+		Path:     song.Path,
+		Title:    song.Title,
+		Artist:   song.Artist,
+		Started:  started,
+		Listened: a.position,
+	}
+	if song.LyricsPath != "" && a.totalLyrics > 0 {
+		play.Sung = true
+		play.Score = a.karaokeScore
+		play.Accuracy = a.calculateAccuracy()
+		a.awardPartyRun(play)
+		a.performances = append(a.performances, export.PerformanceData{
+			Date:      started,
+			SongTitle: song.Title,
+			Artist:    song.Artist,
+			Score:     a.karaokeScore,
+			Streak:    a.streak,
+			Accuracy:  play.Accuracy,
+			Duration:  utils.FormatDuration(a.position),
+		})
+	}
+	a.history.Add(play)
+}
+
+// formatRecap renders this week's recap and the same day last year; colors
+// adds tview color tags
+func formatRecap(plays []history.Play, now time.Time, colors bool) string {
+	tag := func(color string) string {
+		if colors {
+			return "[" + color + "]"
+		}
+		return ""
+	}
+
+	var content strings.Builder
+	writeRecap := func(title string, recap history.Recap) {
+		content.WriteString(fmt.Sprintf("%s%s%s\n", tag("yellow"), title, tag("white")))
+		if recap.Plays == 0 {
+			content.WriteString("  Nothing played\n")
+			return
+		}
+
+		content.WriteString(fmt.Sprintf("  %d songs played, %d sung, %s listened\n",
+			recap.Plays, recap.Sung, utils.FormatDuration(recap.Listened)))
+
+		content.WriteString(fmt.Sprintf("\n  %sMost played:%s\n", tag("cyan"), tag("white")))
+		for i, song := range recap.Songs {
+			name := song.Title
+			if song.Artist != "" {
+				name = song.Artist + " - " + song.Title
+			}
+			content.WriteString(fmt.Sprintf("  %d. %s (%dx, %s)\n", i+1, name, song.Plays, utils.FormatDuration(song.Listened)))
+		}
+
+		if len(recap.Best) > 0 {
+			content.WriteString(fmt.Sprintf("\n  %sBest scores:%s\n", tag("green"), tag("white")))
+			for i, play := range recap.Best {
+				content.WriteString(fmt.Sprintf("  %d. %d - %s (%.0f%% accuracy, %s)\n",
+					i+1, play.Score, play.Title, play.Accuracy, play.Started.Format("Mon 15:04")))
+			}
+		}
+	}
+
+	weekStart, weekEnd := history.WeekRange(now)
+	writeRecap(fmt.Sprintf("This week (since %s)", weekStart.Format("Mon 2 Jan")),
+		history.Summarize(plays, weekStart, weekEnd, recapLimit))
+
+	content.WriteString("\n")
+	dayStart, dayEnd := history.LastYearRange(now)
+	writeRecap(fmt.Sprintf("On this day last year (%s)", dayStart.Format("Mon 2 Jan 2006")),
+		history.Summarize(plays, dayStart, dayEnd, recapLimit))
+
+	return content.String()
+}
+
+// showRecap shows what was played and sung this week and a year ago today
+func (a *App) showRecap() {
+	plays, err := a.history.Plays()
+	if err != nil {
+		a.handleError(err, "Recap")
+		return
+	}
+	plays = libraryPlays(plays)
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(formatRecap(plays, time.Now(), true))
+	view.SetBorder(true).
+		SetTitle(" Recap - Esc close ").
+		SetTitleAlign(tview.AlignCenter)
+	view.SetDoneFunc(func(key tcell.Key) {
+		a.pages.RemovePage("recap")
+		a.app.SetFocus(a.songList)
+	})
+
+	a.pages.AddPage("recap", centered(view, 70, 30), true, true)
+	a.app.SetFocus(view)
+}
+
+// runRecapCommand handles "tuneminal recap"
+func runRecapCommand(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: tuneminal recap")
+	}
+
+	plays, err := history.NewStore().Plays()
+	if err != nil {
+		return err
+	}
+	fmt.Print(formatRecap(libraryPlays(plays), time.Now(), false))
+	return nil
+}
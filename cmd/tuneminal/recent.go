@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/added"
+)
+
+// recentDays is how far back the Recently Added view goes
+const recentDays = 30
+
+// showRecentlyAdded lists the songs added to the library lately, newest
+// first under a heading for each day, and plays the one picked
+func (a *App) showRecentlyAdded() {
+	paths := make([]string, len(a.songs))
+	for i, song := range a.songs {
+		paths[i] = song.Path
+	}
+	now := time.Now()
+	recent := a.added.Recent(paths, now.AddDate(0, 0, -recentDays))
+	if len(recent) == 0 {
+		a.showMessage(fmt.Sprintf("🆕 Nothing was added in the last %d days", recentDays))
+		return
+	}
+
+	table := tview.NewTable().SetSelectable(true, false)
+	table.SetBorder(true).
+		SetTitle(fmt.Sprintf(" Recently Added - %d songs, Enter plays ", len(recent))).
+		SetTitleAlign(tview.AlignCenter)
+
+	group := ""
+	for _, entry := range recent {
+		if g := added.Group(entry.Added, now); g != group {
+			group = g
+			table.SetCell(table.GetRowCount(), 0, tview.NewTableCell("[yellow]"+group+"[white]").SetSelectable(false))
+		}
+		i := a.songIndex(entry.Path)
+		label := fmt.Sprintf("  %s - %s", a.songs[i].Title, a.songs[i].Artist)
+		table.SetCell(table.GetRowCount(), 0, tview.NewTableCell(label).SetReference(entry.Path).SetExpansion(1))
+		table.SetCell(table.GetRowCount()-1, 1, tview.NewTableCell(entry.Added.Format("Jan 2 15:04")).SetTextColor(tcell.ColorGray))
+	}
+	table.Select(1, 0)
+
+	closeTable := func() {
+		a.pages.RemovePage("recently-added")
+		a.app.SetFocus(a.songList)
+	}
+	table.SetSelectedFunc(func(row, column int) {
+		path, ok := table.GetCell(row, 0).GetReference().(string)
+		if !ok {
+			return
+		}
+		closeTable()
+		if i := a.songIndex(path); i >= 0 {
+			a.selectedSong = i
+			a.playSelectedSong()
+		}
+	})
+	table.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			closeTable()
+		}
+	})
+
+	a.pages.AddPage("recently-added", centered(table, 70, 20), true, true)
+	a.app.SetFocus(table)
+}
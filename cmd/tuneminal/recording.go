@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/tuneminal/tuneminal/pkg/asciicast"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// toggleRecording starts or stops recording the screen as an asciicast
+func (a *App) toggleRecording() {
+	if a.recorder != nil {
+		a.stopRecording()
+		return
+	}
+
+	title, songTitle := "Tuneminal karaoke", ""
+	if a.currentSong >= 0 && a.currentSong < len(a.songs) {
+		song := a.songs[a.currentSong]
+		title = fmt.Sprintf("%s - %s", song.Artist, song.Title)
+		songTitle = song.Title
+	}
+
+	path, err := a.exportManager.NewFilePath("karaoke", songTitle, "cast")
+	if err != nil {
+		a.handleError(err, "Start Recording")
+		return
+	}
+
+	recorder, err := asciicast.Create(path, title)
+	if err != nil {
+		a.handleError(err, "Start Recording")
+		return
+	}
+	a.recorder = recorder
+	a.showToast("[red]● Recording[white] - press Shift+G again to stop")
+}
+
+// stopRecording finishes the recording and says where it was saved
+func (a *App) stopRecording() {
+	recorder := a.recorder
+	a.recorder = nil
+	if err := recorder.Close(); err != nil {
+		a.handleError(err, "Save Recording")
+		return
+	}
+
+	a.showMessage(fmt.Sprintf("[green]✅ Recorded %s[white]\n\n%s\n\n[dim]Play it with 'asciinema play %s' or make a GIF with 'agg'[white]",
+		utils.FormatDuration(recorder.Elapsed()), recorder.Path(), filepath.Base(recorder.Path())))
+}
+
+// captureRecording adds the screen just drawn to the recording, if one is running
+func (a *App) captureRecording(screen tcell.Screen) {
+	if a.recorder == nil {
+		return
+	}
+	if err := a.recorder.Capture(screen); err != nil {
+		recorder := a.recorder
+		a.recorder = nil
+		recorder.Close()
+		a.statusBar.SetText(fmt.Sprintf("[red]Recording stopped: %v[white]", err))
+	}
+}
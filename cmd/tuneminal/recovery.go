@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// recoveryAttempts is how many times a failed audio output is restarted
+// before giving up and telling the user
+const recoveryAttempts = 3
+
+// kioskRecoveryInterval is the longest wait between attempts in kiosk mode,
+// which keeps trying until the device is plugged back in
+const kioskRecoveryInterval = 5 * time.Second
+
+// recoverPlayback restarts the audio output after the player reports a
+// failure, backing off between attempts, and resumes tracking playback. If
+// every attempt fails, the song is left paused so Space tries again; a kiosk
+// keeps trying.
+func (a *App) recoverPlayback(failure error) {
+	a.errorLog.Add("Audio Output", failure)
+	a.app.QueueUpdateDraw(func() {
+		a.showToast("[yellow]⚠ Audio output failed, reconnecting...[white]")
+	})
+
+	err := failure
+	for attempt := 1; attempt <= recoveryAttempts || a.kiosk; attempt++ {
+		time.Sleep(min(time.Duration(attempt)*500*time.Millisecond, kioskRecoveryInterval))
+		if err = a.player.Recover(); err == nil {
+			break
+		}
+	}
+
+	a.app.QueueUpdateDraw(func() {
+		if err != nil {
+			a.isPlaying = false
+			a.isPaused = true
+			a.handleError(fmt.Errorf("%w, press Space to try again", err), "Audio Recovery")
+			a.updateAllDisplays()
+			return
+		}
+		a.position = a.player.GetPosition()
+		a.showToast(fmt.Sprintf("[green]✓ Audio recovered, resuming at %s[white]", utils.FormatDuration(a.position)))
+	})
+	if err == nil {
+		a.trackRealPlayback()
+	}
+}
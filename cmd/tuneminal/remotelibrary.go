@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+	"github.com/tuneminal/tuneminal/pkg/paths"
+	"github.com/tuneminal/tuneminal/pkg/remotefs"
+)
+
+// remoteListTimeout bounds listing one remote library, so an unreachable
+// server doesn't leave a refresh hanging
+const remoteListTimeout = 2 * time.Minute
+
+// openRemoteLibraries sets up the remote libraries from the config. Bad
+// addresses go to the error log rather than stopping the app.
+func (a *App) openRemoteLibraries() {
+	a.remoteIndex = remotefs.NewIndex(paths.Cache("remote-index.json"))
+	a.remoteLibraries = nil
+	for _, address := range a.appConfig.RemoteLibraries {
+		library, err := remotefs.Open(address)
+		if err != nil {
+			a.errorLog.Add("Remote Library", err)
+			continue
+		}
+		a.remoteLibraries = append(a.remoteLibraries, library)
+	}
+}
+
+// remoteSong makes a library song from an indexed remote one
+func remoteSong(song remotefs.Song) Song {
+	return Song{
+		Title:    song.Title,
+		Artist:   song.Artist,
+		Album:    song.Album,
+		Path:     song.URL,
+		Duration: song.Duration,
+	}
+}
+
+// cachedRemoteSongs returns the remote songs as last listed, so they show
+// straight away while the libraries are listed again
+func (a *App) cachedRemoteSongs() []Song {
+	var songs []Song
+	for _, library := range a.remoteLibraries {
+		for _, song := range a.remoteIndex.Songs(library) {
+			if !a.overrides.IsHidden(song.URL) {
+				songs = append(songs, remoteSong(song))
+			}
+		}
+	}
+	return songs
+}
+
+// refreshRemoteLibraries lists every remote library again in the background
+// and swaps the new listings into the song list
+func (a *App) refreshRemoteLibraries() {
+	if len(a.remoteLibraries) == 0 {
+		return
+	}
+
+	libraries, index := a.remoteLibraries, a.remoteIndex
+	go func() {
+		for _, library := range libraries {
+			ctx, cancel := context.WithTimeout(context.Background(), remoteListTimeout)
+			entries, err := library.List(ctx)
+			cancel()
+			if err == nil {
+				_, err = index.Refresh(library, entries)
+			}
+			if err != nil {
+				a.app.QueueUpdateDraw(func() {
+					a.errorLog.Add("Remote Library", err)
+					a.showToast(fmt.Sprintf("[red]❌ %s: %v[white]", library.URL, err))
+				})
+			}
+		}
+
+		a.app.QueueUpdateDraw(func() {
+			a.replaceRemoteSongs(a.cachedRemoteSongs())
+		})
+	}()
+}
+
+// replaceRemoteSongs swaps the remote songs in the song list for songs,
+// keeping the playing and selected songs where they are
+func (a *App) replaceRemoteSongs(songs []Song) {
+	current, selected := "", ""
+	if a.currentSong >= 0 && a.currentSong < len(a.songs) {
+		current = a.songs[a.currentSong].Path
+	}
+	if a.selectedSong >= 0 && a.selectedSong < len(a.songs) {
+		selected = a.songs[a.selectedSong].Path
+	}
+
+	kept := a.songs[:0]
+	for _, song := range a.songs {
+		if !remotefs.IsRemote(song.Path) {
+			kept = append(kept, song)
+		}
+	}
+	a.songs = append(kept, songs...)
+
+	for i, song := range a.songs {
+		if song.Path == current {
+			a.currentSong = i
+		}
+		if song.Path == selected {
+			a.selectedSong = i
+		}
+	}
+	if a.currentSong >= len(a.songs) {
+		a.currentSong = len(a.songs) - 1
+	}
+	if a.selectedSong >= len(a.songs) {
+		a.selectedSong = a.currentSong
+	}
+	a.updateSongList()
+}
+
+// remoteLibraryFor returns the remote library a song address is in
+func (a *App) remoteLibraryFor(songURL string) (*remotefs.Library, error) {
+	for _, library := range a.remoteLibraries {
+		if library.Contains(songURL) {
+			return library, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not in any remote library in the config", songURL)
+}
+
+// fetchRemoteFile copies a remote file into the song cache, unless it is
+// there already, and returns the local copy
+func (a *App) fetchRemoteFile(entry remotefs.Entry) (string, error) {
+	name := remotefs.CacheName(entry)
+	if cached, ok := a.songCache.Get(name); ok {
+		return cached, nil
+	}
+
+	library, err := a.remoteLibraryFor(entry.URL)
+	if err != nil {
+		return "", err
+	}
+	err = a.songCache.Put(name, func(w io.Writer) error {
+		reader, err := library.Fetch(context.Background(), entry.URL)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, reader); err != nil {
+			reader.Close()
+			return err
+		}
+		return reader.Close()
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", entry.URL, err)
+	}
+
+	if cached, ok := a.songCache.Get(name); ok {
+		return cached, nil
+	}
+	return "", fmt.Errorf("no room in the song cache for %s", entry.URL)
+}
+
+// fetchRemoteSong brings a remote song and its lyrics into the song cache
+// before it plays, reading its tags the first time
+func (a *App) fetchRemoteSong(song *Song) error {
+	indexed, ok := a.remoteIndex.Get(song.Path)
+	if !ok {
+		return fmt.Errorf("%s is no longer in its remote library", song.Path)
+	}
+
+	local, err := a.fetchRemoteFile(indexed.Entry)
+	if err != nil {
+		return err
+	}
+	if indexed.Lyrics != nil {
+		if lyricsPath, err := a.fetchRemoteFile(*indexed.Lyrics); err == nil {
+			song.LyricsPath = lyricsPath
+		}
+	}
+
+	if !indexed.Tagged {
+		if meta, err := metadata.GetRealMetadata(local); err == nil {
+			if tagged, err := a.remoteIndex.SetTags(song.Path, meta); err == nil {
+				song.Title, song.Artist, song.Album = tagged.Title, tagged.Artist, tagged.Album
+				song.Duration = tagged.Duration
+				if a.currentSong >= 0 && a.currentSong < len(a.songs) && a.songs[a.currentSong].Path == song.Path {
+					a.songs[a.currentSong] = *song
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// remotePlaybackPath returns the local copy of a remote song when it has
+// been fetched
+func (a *App) remotePlaybackPath(song Song) (string, bool) {
+	indexed, ok := a.remoteIndex.Get(song.Path)
+	if !ok {
+		return "", false
+	}
+	return a.songCache.Get(remotefs.CacheName(indexed.Entry))
+}
+
+// prefetchRemoteSong fetches the remote song expected to play next in the
+// background, so it starts without waiting on the network
+func (a *App) prefetchRemoteSong(song Song) {
+	indexed, ok := a.remoteIndex.Get(song.Path)
+	if !ok {
+		return
+	}
+	go func() {
+		a.fetchRemoteFile(indexed.Entry)
+		if indexed.Lyrics != nil {
+			a.fetchRemoteFile(*indexed.Lyrics)
+		}
+	}()
+}
@@ -0,0 +1,56 @@
+package main
+
+// The song list has two pointers: currentSong is the song playing (or last
+// played), and selectedSong is the browse cursor. Moving the cursor never
+// touches playback; Enter plays the song under it.
+
+// selectedIndex returns the song under the browse cursor, falling back to
+// the current song before anything has been browsed
+func (a *App) selectedIndex() int {
+	if a.selectedSong >= 0 && a.selectedSong < len(a.songs) {
+		return a.selectedSong
+	}
+	return a.currentSong
+}
+
+// selectSong moves the browse cursor to song i
+func (a *App) selectSong(i int) {
+	if i < 0 || i >= len(a.songs) {
+		return
+	}
+	a.selectedSong = i
+	a.updateSongList()
+	a.app.SetFocus(a.songList)
+}
+
+// songIndex returns where the song at path is in the list, or -1
+func (a *App) songIndex(path string) int {
+	for i, song := range a.songs {
+		if song.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// onSongListChanged keeps the browse cursor on the list's own selection when
+// it moves by other means, such as Page Up/Down or the mouse. Filtered lists
+// don't line up with the library, so they're left alone.
+func (a *App) onSongListChanged(index int, mainText, secondaryText string, shortcut rune) {
+	if a.songList.GetItemCount() == len(a.songs) {
+		a.selectedSong = index
+	}
+}
+
+// songRemoved moves both pointers to follow the songs after i, which was
+// just removed from a.songs
+func (a *App) songRemoved(i int) {
+	for _, index := range []*int{&a.currentSong, &a.selectedSong} {
+		if *index > i {
+			*index--
+		}
+		if *index >= len(a.songs) {
+			*index = len(a.songs) - 1
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/config"
+	"github.com/tuneminal/tuneminal/pkg/player"
+)
+
+// audioOutputs are the choices for the main output; PipeWire and JACK are
+// played to directly, bypassing the default device routing
+var audioOutputs = []string{"default", player.OutputPipeWire, player.OutputJACK}
+
+// outputConfig returns the configured PipeWire or JACK output, or nil for
+// the default one
+func outputConfig(cfg *config.Config) *player.OutputConfig {
+	switch cfg.AudioOutput {
+	case player.OutputPipeWire, player.OutputJACK:
+		return &player.OutputConfig{
+			Backend:   cfg.AudioOutput,
+			Device:    cfg.AudioOutputDevice,
+			LatencyMs: cfg.AudioLatencyMs,
+		}
+	}
+	return nil
+}
+
+// applyAudioSettings pushes the audio settings from the config to the player
+func (a *App) applyAudioSettings() {
+	if a.player == nil {
+		return
+	}
+
+	a.player.SetOutput(outputConfig(a.appConfig))
+
+	// A passed-through mic reaches the monitor with the music, so it is
+	// only captured once
+	monitorMic := a.appConfig.MonitorMicDevice
+	if a.appConfig.MicPassthrough && monitorMic != "" {
+		a.player.SetMic(&player.MicConfig{
+			Device: monitorMic,
+			Level:  a.appConfig.MonitorMicLevel,
+		})
+		monitorMic = ""
+	} else {
+		a.player.SetMic(nil)
+	}
+
+	if a.appConfig.MonitorEnabled && a.appConfig.MonitorDevice != "" {
+		a.player.SetMonitor(&player.MonitorConfig{
+			Device:    a.appConfig.MonitorDevice,
+			MicDevice: monitorMic,
+			MicLevel:  a.appConfig.MonitorMicLevel,
+		})
+	} else {
+		a.player.SetMonitor(nil)
+	}
+	a.applyMicEffects()
+	a.applyEqualizer()
+}
+
+// showAudioSettings shows the audio settings screen
+func (a *App) showAudioSettings() {
+	outputIndex := 0
+	for i, output := range audioOutputs {
+		if output == a.appConfig.AudioOutput {
+			outputIndex = i
+		}
+	}
+	output := tview.NewDropDown().
+		SetLabel("Output").
+		SetOptions(audioOutputs, nil).
+		SetCurrentOption(outputIndex)
+	outputDevice := tview.NewInputField().
+		SetLabel("Output target/ports").
+		SetText(a.appConfig.AudioOutputDevice).
+		SetFieldWidth(24)
+	latency := tview.NewInputField().
+		SetLabel("PipeWire latency (ms, 0 = auto)").
+		SetText(strconv.Itoa(a.appConfig.AudioLatencyMs)).
+		SetFieldWidth(5).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	monitorEnabled := tview.NewCheckbox().
+		SetLabel("Monitor output").
+		SetChecked(a.appConfig.MonitorEnabled)
+	monitorDevice := tview.NewInputField().
+		SetLabel("Monitor device").
+		SetText(a.appConfig.MonitorDevice).
+		SetFieldWidth(24)
+	micDevice := tview.NewInputField().
+		SetLabel("Mic device (optional)").
+		SetText(a.appConfig.MonitorMicDevice).
+		SetFieldWidth(24)
+	micLevel := tview.NewInputField().
+		SetLabel("Mic level (%)").
+		SetText(strconv.Itoa(int(a.appConfig.MonitorMicLevel * 100))).
+		SetFieldWidth(5).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	metronomeVolume := tview.NewInputField().
+		SetLabel("Metronome volume (%)").
+		SetText(strconv.Itoa(int(a.appConfig.MetronomeVolume * 100))).
+		SetFieldWidth(5).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	beatsPerBar := tview.NewInputField().
+		SetLabel("Beats per bar (0 = no accent)").
+		SetText(strconv.Itoa(a.appConfig.MetronomeBeatsPerBar)).
+		SetFieldWidth(3).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	cueMode := 0
+	for i, mode := range cueModes {
+		if mode == a.appConfig.LineCue {
+			cueMode = i
+		}
+	}
+	lineCue := tview.NewDropDown().
+		SetLabel("Cue before each line").
+		SetOptions(cueModes, nil).
+		SetCurrentOption(cueMode)
+	cueLead := tview.NewInputField().
+		SetLabel("Cue lead (ms)").
+		SetText(strconv.Itoa(a.appConfig.LineCueLeadMs)).
+		SetFieldWidth(5).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	endMode := 0
+	for i, mode := range playlistEndModes {
+		if mode == a.playlistEndMode() {
+			endMode = i
+		}
+	}
+	playlistEnd := tview.NewDropDown().
+		SetLabel("When a playlist ends").
+		SetOptions(playlistEndModes, nil).
+		SetCurrentOption(endMode)
+	clockSync := tview.NewInputField().
+		SetLabel("MIDI clock input (optional)").
+		SetText(a.appConfig.ClockSync).
+		SetFieldWidth(24)
+
+	closeSettings := func() {
+		a.pages.RemovePage("audio-settings")
+		a.app.SetFocus(a.songList)
+	}
+
+	form := tview.NewForm().
+		AddFormItem(output).
+		AddFormItem(outputDevice).
+		AddFormItem(latency).
+		AddFormItem(monitorEnabled).
+		AddFormItem(monitorDevice).
+		AddFormItem(micDevice).
+		AddFormItem(micLevel).
+		AddFormItem(metronomeVolume).
+		AddFormItem(beatsPerBar).
+		AddFormItem(lineCue).
+		AddFormItem(cueLead).
+		AddFormItem(playlistEnd).
+		AddFormItem(clockSync).
+		AddButton("Save", func() {
+			latencyMs, err := strconv.Atoi(latency.GetText())
+			if err != nil || latencyMs < 0 || latencyMs > 1000 {
+				a.showWarning("PipeWire latency must be between 0 and 1000 ms")
+				return
+			}
+			level, err := strconv.Atoi(micLevel.GetText())
+			if err != nil || level < 0 || level > 100 {
+				a.showWarning("Mic level must be between 0 and 100")
+				return
+			}
+			clickLevel, err := strconv.Atoi(metronomeVolume.GetText())
+			if err != nil || clickLevel < 0 || clickLevel > 100 {
+				a.showWarning("Metronome volume must be between 0 and 100")
+				return
+			}
+			beats, err := strconv.Atoi(beatsPerBar.GetText())
+			if err != nil || beats < 0 || beats > 16 {
+				a.showWarning("Beats per bar must be between 0 and 16")
+				return
+			}
+			lead, err := strconv.Atoi(cueLead.GetText())
+			if err != nil || lead < 0 || lead > 5000 {
+				a.showWarning("Cue lead must be between 0 and 5000 ms")
+				return
+			}
+			if monitorEnabled.IsChecked() && monitorDevice.GetText() == "" {
+				a.showWarning("Please enter a monitor device, e.g. hw:1,0")
+				return
+			}
+
+			previousOutput := a.appConfig.AudioOutput
+			_, a.appConfig.AudioOutput = output.GetCurrentOption()
+			a.appConfig.AudioOutputDevice = outputDevice.GetText()
+			a.appConfig.AudioLatencyMs = latencyMs
+			a.appConfig.MonitorEnabled = monitorEnabled.IsChecked()
+			a.appConfig.MonitorDevice = monitorDevice.GetText()
+			a.appConfig.MonitorMicDevice = micDevice.GetText()
+			a.appConfig.MonitorMicLevel = float64(level) / 100
+			a.appConfig.MetronomeVolume = float64(clickLevel) / 100
+			a.appConfig.MetronomeBeatsPerBar = beats
+			_, a.appConfig.LineCue = lineCue.GetCurrentOption()
+			a.appConfig.LineCueLeadMs = lead
+			_, a.appConfig.PlaylistEnd = playlistEnd.GetCurrentOption()
+			clockChanged := clockSync.GetText() != a.appConfig.ClockSync
+			a.appConfig.ClockSync = clockSync.GetText()
+			if clockChanged {
+				a.startClockSync()
+			}
+			a.applyAudioSettings()
+			a.applyMetronome()
+			if a.isPlaying || a.isPaused {
+				a.queueNextSong()
+			}
+			a.updateStatus()
+			a.saveConfig()
+
+			closeSettings()
+			if _, err := a.player.OutputStatus(); err != nil {
+				a.handleError(err, "Audio Output")
+			} else if a.appConfig.AudioOutput != previousOutput {
+				a.showMessage(fmt.Sprintf("🔊 Playing through %s from the next song or seek", a.appConfig.AudioOutput))
+			} else if a.appConfig.MonitorEnabled {
+				a.showMessage(fmt.Sprintf("🎧 Monitor mix will play on %s from the next song or seek", a.appConfig.MonitorDevice))
+			}
+		}).
+		AddButton("Equalizer", func() {
+			closeSettings()
+			a.showEqualizer()
+		}).
+		AddButton("Measure Loudness", func() {
+			closeSettings()
+			a.scanLoudness()
+		}).
+		AddButton("Cancel", closeSettings)
+	form.SetCancelFunc(closeSettings)
+
+	form.SetTitle(" Audio Settings ").SetBorder(true)
+	a.pages.AddPage("audio-settings", centered(form, 60, 32), true, true)
+	a.app.SetFocus(form)
+}
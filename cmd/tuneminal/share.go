@@ -0,0 +1,455 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/remote"
+)
+
+// showShareMenu shows the LAN playlist sharing options for the current mode
+func (a *App) showShareMenu() {
+	var text string
+	var buttons []string
+
+	switch {
+	case a.sharedPlaylist != nil:
+		state := a.sharedPlaylist.State()
+		text = fmt.Sprintf("[green]Sharing playlist on %s[white]\n\nGuests can join with this address and propose songs.\n%d proposal(s) waiting.",
+			a.remoteServer.Addr(), len(state.Pending))
+		if state.SkipThreshold > 0 {
+			text += fmt.Sprintf("\n%d of %d vote(s) to skip the current song.", state.SkipVotes, state.SkipThreshold)
+		}
+		buttons = []string{"Proposals", "Stop Sharing", "Cancel"}
+	case a.shareClient != nil:
+		text = fmt.Sprintf("[green]Joined shared playlist at %s[white]\n\nPropose the selected song, vote to skip the one playing,\nor browse the host's playlist.", a.shareHost)
+		buttons = []string{"Propose Selected", "Vote Skip", "Shared Playlist", "Leave", "Cancel"}
+	default:
+		text = "[yellow]LAN Playlist Sharing[white]\n\nHost the playlist so a co-host can propose songs,\nor join another Tuneminal on the network."
+		buttons = []string{"Host", "Join", "Cancel"}
+	}
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("share-menu")
+			a.app.SetFocus(a.songList)
+
+			switch buttonLabel {
+			case "Host":
+				if err := a.startSharing(); err != nil {
+					a.handleError(err, "Share Playlist")
+				} else {
+					a.showMessage(fmt.Sprintf("📡 Sharing playlist on %s", a.remoteServer.Addr()))
+				}
+			case "Join":
+				a.showJoinDialog()
+			case "Proposals":
+				a.showProposals()
+			case "Stop Sharing":
+				a.stopSharing()
+			case "Propose Selected":
+				a.proposeSelectedSong()
+			case "Vote Skip":
+				a.voteSkip()
+			case "Shared Playlist":
+				a.showSharedPlaylist()
+			case "Leave":
+				a.leaveSharedPlaylist()
+			}
+		})
+
+	a.pages.AddPage("share-menu", modal, true, true)
+	a.app.SetFocus(modal)
+}
+
+// startSharing starts serving the current song list to guests
+func (a *App) startSharing() error {
+	if a.shareClient != nil {
+		return fmt.Errorf("leave the shared playlist you joined before hosting one")
+	}
+
+	if a.remoteServer == nil {
+		a.remoteServer = remote.NewServer()
+	}
+	shared := remote.NewSharedPlaylist()
+	shared.OnProposal = func(proposal remote.Proposal) {
+		a.app.QueueUpdateDraw(func() {
+			a.statusBar.SetText(fmt.Sprintf("[yellow]📨 %s proposed \"%s\" - press P to review[white]",
+				proposal.From, proposal.Entry.Title))
+		})
+	}
+	shared.OnSkipVote = func(vote remote.SkipVote) {
+		a.app.QueueUpdateDraw(func() {
+			a.handleSkipVote(vote)
+		})
+	}
+	shared.SetSkipThreshold(a.appConfig.SkipVoteThreshold)
+	shared.Register(a.remoteServer)
+
+	if err := a.remoteServer.Start(a.appConfig.ShareAddress); err != nil {
+		// Handlers are registered per server, so start from a fresh one next time
+		a.remoteServer = nil
+		return err
+	}
+
+	a.sharedPlaylist = shared
+	a.publishSharedPlaylist()
+	return nil
+}
+
+// stopSharing stops serving the playlist
+func (a *App) stopSharing() {
+	if a.remoteServer != nil {
+		a.remoteServer.Stop()
+		a.remoteServer = nil
+	}
+	a.sharedPlaylist = nil
+	a.updateStatus()
+}
+
+// publishSharedPlaylist pushes the current song list to guests
+func (a *App) publishSharedPlaylist() {
+	if a.sharedPlaylist == nil {
+		return
+	}
+
+	entries := make([]remote.Entry, len(a.songs))
+	for i, song := range a.songs {
+		entries[i] = remote.Entry{Title: song.Title, Artist: song.Artist}
+	}
+
+	name := a.currentPlaylist
+	if name == "" {
+		name = "Library"
+	}
+
+	current := -1
+	if a.isPlaying || a.isPaused {
+		current = a.currentSong
+	}
+	a.sharedPlaylist.Publish(name, entries, current)
+}
+
+// handleSkipVote reports a guest's skip vote in the status bar and, once
+// enough guests agree, skips the song or asks the host whether to
+func (a *App) handleSkipVote(vote remote.SkipVote) {
+	if a.sharedPlaylist == nil || a.currentSong < 0 || a.currentSong >= len(a.songs) {
+		return
+	}
+
+	title := a.songs[a.currentSong].Title
+	if !vote.Reached() {
+		a.statusBar.SetText(fmt.Sprintf("[yellow]🗳 %s voted to skip \"%s\" (%d/%d)[white]",
+			vote.From, title, vote.Votes, vote.Threshold))
+		return
+	}
+	if vote.Votes > vote.Threshold {
+		// The host was already asked when the threshold was reached
+		a.statusBar.SetText(fmt.Sprintf("[yellow]🗳 %d guests voted to skip \"%s\"[white]", vote.Votes, title))
+		return
+	}
+
+	if a.appConfig.SkipVoteAuto {
+		a.next()
+		a.statusBar.SetText(fmt.Sprintf("[yellow]⏭ Skipped \"%s\" - %d guests voted[white]", title, vote.Votes))
+		return
+	}
+
+	a.statusBar.SetText(fmt.Sprintf("[yellow]🗳 %d guests voted to skip \"%s\"[white]", vote.Votes, title))
+	song := a.songs[a.currentSong].Path
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("[yellow]%d guests voted to skip[white]\n\n%s", vote.Votes, title)).
+		AddButtons([]string{"Skip", "Keep Playing"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("skip-vote")
+			a.app.SetFocus(a.songList)
+			// Only skip the song the guests voted on
+			if buttonLabel == "Skip" && a.currentSong >= 0 && a.currentSong < len(a.songs) && a.songs[a.currentSong].Path == song {
+				a.next()
+			}
+		})
+
+	a.pages.AddPage("skip-vote", modal, true, true)
+	a.app.SetFocus(modal)
+}
+
+// showProposals lists guest proposals; Enter accepts, d rejects
+func (a *App) showProposals() {
+	if a.sharedPlaylist == nil {
+		return
+	}
+
+	proposals := a.sharedPlaylist.State().Pending
+	if len(proposals) == 0 {
+		a.showMessage("📭 No proposals waiting")
+		return
+	}
+
+	list := tview.NewList().ShowSecondaryText(true)
+	list.SetBorder(true).
+		SetTitle(" Proposals - Enter accept, d reject, Esc close ").
+		SetTitleAlign(tview.AlignCenter)
+	for _, proposal := range proposals {
+		list.AddItem(fmt.Sprintf("%s - %s", proposal.Entry.Title, proposal.Entry.Artist),
+			fmt.Sprintf("from %s at %s", proposal.From, proposal.Created.Format("15:04")), 0, nil)
+	}
+
+	closeList := func() {
+		a.pages.RemovePage("proposals")
+		a.app.SetFocus(a.songList)
+	}
+
+	list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		closeList()
+		if err := a.acceptProposal(proposals[index]); err != nil {
+			a.handleError(err, "Accept Proposal")
+		}
+	})
+	list.SetDoneFunc(closeList)
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune && event.Rune() == 'd' {
+			closeList()
+			a.sharedPlaylist.Resolve(proposals[list.GetCurrentItem()].ID)
+			a.showProposals()
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("proposals", centered(list, 70, 20), true, true)
+	a.app.SetFocus(list)
+}
+
+// acceptProposal adds a proposed song from the local library to the song list
+func (a *App) acceptProposal(proposal remote.Proposal) error {
+	song, ok := a.findLibrarySong(proposal.Entry.Title, proposal.Entry.Artist)
+	if !ok {
+		return fmt.Errorf("\"%s\" is not in this library", proposal.Entry.Title)
+	}
+
+	a.sharedPlaylist.Resolve(proposal.ID)
+
+	found := false
+	for _, existing := range a.songs {
+		if existing.Path == song.Path {
+			found = true
+			break
+		}
+	}
+	if !found {
+		a.songs = append(a.songs, song)
+		if a.currentPlaylist != "" {
+			if err := a.playlistManager.AddSongToPlaylist(a.currentPlaylist, song.Path); err != nil {
+				return err
+			}
+		}
+		a.recordPlaylistAdd(song, a.currentPlaylist)
+	}
+
+	a.updateSongList()
+	a.publishSharedPlaylist()
+	a.showMessage(fmt.Sprintf("✅ Added \"%s\" from %s", song.Title, proposal.From))
+	return nil
+}
+
+// findLibrarySong looks a song up by title (and artist, if given), first in the
+// current list and then in the library directory
+func (a *App) findLibrarySong(title, artist string) (Song, bool) {
+	matches := func(songTitle, songArtist string) bool {
+		return strings.EqualFold(songTitle, title) &&
+			(artist == "" || strings.EqualFold(songArtist, artist))
+	}
+
+	for _, song := range a.songs {
+		if matches(song.Title, song.Artist) {
+			return song, true
+		}
+	}
+
+	library, err := scanLibraryFolders()
+	if err != nil {
+		return Song{}, false
+	}
+	for _, meta := range library {
+		if matches(meta.Title, meta.Artist) && !a.overrides.IsHidden(meta.Path) {
+			return Song{
+				Title:      meta.Title,
+				Artist:     meta.Artist,
+				Album:      meta.Album,
+				Path:       meta.Path,
+				LyricsPath: a.findLyricsFile(meta.Path, meta.Title, meta.Artist),
+				Duration:   meta.Duration,
+				Chapters:   meta.Chapters,
+			}, true
+		}
+	}
+	return Song{}, false
+}
+
+// showJoinDialog asks for the host address and joins its shared playlist
+func (a *App) showJoinDialog() {
+	hostname, _ := os.Hostname()
+	addressInput := tview.NewInputField().SetLabel("Host address").SetPlaceholder("192.168.1.20:7777").SetFieldWidth(30)
+	nameInput := tview.NewInputField().SetLabel("Your name").SetText(hostname).SetFieldWidth(30)
+
+	closeDialog := func() {
+		a.pages.RemovePage("join-dialog")
+		a.app.SetFocus(a.songList)
+	}
+
+	form := tview.NewForm().
+		AddFormItem(addressInput).
+		AddFormItem(nameInput).
+		AddButton("Join", func() {
+			address := strings.TrimSpace(addressInput.GetText())
+			if address == "" {
+				a.showWarning("Please enter the host address")
+				return
+			}
+			closeDialog()
+			if err := a.joinSharedPlaylist(address, nameInput.GetText()); err != nil {
+				a.handleError(err, "Join Shared Playlist")
+			}
+		}).
+		AddButton("Cancel", closeDialog)
+	form.SetCancelFunc(closeDialog)
+
+	form.SetTitle(" Join Shared Playlist ").SetBorder(true)
+	a.pages.AddPage("join-dialog", centered(form, 60, 9), true, true)
+	a.app.SetFocus(form)
+}
+
+// joinSharedPlaylist connects to a host and keeps its playlist in sync
+func (a *App) joinSharedPlaylist(address, name string) error {
+	if a.sharedPlaylist != nil {
+		return fmt.Errorf("stop sharing your own playlist before joining another")
+	}
+
+	client := remote.NewClient(address, name)
+	state, err := client.State(-1)
+	if err != nil {
+		return err
+	}
+
+	a.shareClient = client
+	a.shareHost = address
+	a.sharedState = state
+	a.stopGuest = make(chan struct{})
+	go a.pollSharedPlaylist(client, a.stopGuest, state.Version)
+
+	a.showMessage(fmt.Sprintf("📡 Joined \"%s\" on %s (%d songs)", state.Name, address, len(state.Entries)))
+	return nil
+}
+
+// pollSharedPlaylist refreshes the host's playlist until stop is closed
+func (a *App) pollSharedPlaylist(client *remote.Client, stop chan struct{}, version int) {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			state, err := client.State(version)
+			if err != nil || state == nil {
+				continue
+			}
+			version = state.Version
+			a.app.QueueUpdateDraw(func() {
+				if a.shareClient != client {
+					return
+				}
+				a.sharedState = state
+				if a.pages.HasPage("shared-playlist") {
+					a.pages.RemovePage("shared-playlist")
+					a.showSharedPlaylist()
+				}
+			})
+		}
+	}
+}
+
+// leaveSharedPlaylist disconnects from the host
+func (a *App) leaveSharedPlaylist() {
+	if a.stopGuest != nil {
+		close(a.stopGuest)
+		a.stopGuest = nil
+	}
+	a.shareClient = nil
+	a.sharedState = nil
+	a.shareHost = ""
+}
+
+// proposeSelectedSong asks the host to add the selected song
+func (a *App) proposeSelectedSong() {
+	if a.shareClient == nil || a.currentSong < 0 || a.currentSong >= len(a.songs) {
+		return
+	}
+
+	song := a.songs[a.currentSong]
+	if err := a.shareClient.Propose(remote.Entry{Title: song.Title, Artist: song.Artist}); err != nil {
+		a.handleError(err, "Propose Song")
+		return
+	}
+	a.showMessage(fmt.Sprintf("📨 Proposed \"%s\" to the host", song.Title))
+}
+
+// voteSkip asks the host to skip the song playing there
+func (a *App) voteSkip() {
+	if a.shareClient == nil {
+		return
+	}
+
+	vote, err := a.shareClient.VoteSkip()
+	if err != nil {
+		a.handleError(err, "Vote Skip")
+		return
+	}
+	a.statusBar.SetText(fmt.Sprintf("[yellow]🗳 Voted to skip (%d/%d)[white]", vote.Votes, vote.Threshold))
+}
+
+// showSharedPlaylist shows the host's playlist as last synced
+func (a *App) showSharedPlaylist() {
+	if a.sharedState == nil {
+		return
+	}
+
+	view := tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	view.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s on %s - Esc to close ", a.sharedState.Name, a.shareHost)).
+		SetTitleAlign(tview.AlignCenter)
+
+	var content strings.Builder
+	if a.sharedState.SkipThreshold > 0 && a.sharedState.Current >= 0 {
+		content.WriteString(fmt.Sprintf("[yellow]Skip votes: %d/%d[white]\n\n", a.sharedState.SkipVotes, a.sharedState.SkipThreshold))
+	}
+	for i, entry := range a.sharedState.Entries {
+		marker := "  "
+		if i == a.sharedState.Current {
+			marker = "[green]▶[white] "
+		}
+		content.WriteString(fmt.Sprintf("%s%2d. %s - %s\n", marker, i+1, entry.Title, entry.Artist))
+	}
+	if len(a.sharedState.Pending) > 0 {
+		content.WriteString("\n[yellow]Waiting for the host:[white]\n")
+		for _, proposal := range a.sharedState.Pending {
+			content.WriteString(fmt.Sprintf("  • %s (from %s)\n", proposal.Entry.Title, proposal.From))
+		}
+	}
+	view.SetText(content.String())
+
+	view.SetDoneFunc(func(key tcell.Key) {
+		a.pages.RemovePage("shared-playlist")
+		a.app.SetFocus(a.songList)
+	})
+
+	a.pages.AddPage("shared-playlist", centered(view, 70, 22), true, true)
+	a.app.SetFocus(view)
+}
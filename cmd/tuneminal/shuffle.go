@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+)
+
+// Shuffle plays the list in an order drawn when shuffle is turned on. The
+// order holds until shuffle is turned on again, so repeat goes round the same
+// order and the queue view can say what comes next. Songs added while it is
+// on are slotted in at random among those still to come.
+
+// playOrder returns the song list's indices in the order they play: the
+// shuffled order in shuffle mode, else the list's own
+func (a *App) playOrder() []int {
+	if !a.shuffleMode {
+		order := make([]int, len(a.songs))
+		for i := range order {
+			order[i] = i
+		}
+		return order
+	}
+
+	a.syncShuffleOrder()
+	order := make([]int, 0, len(a.shuffleOrder))
+	for _, path := range a.shuffleOrder {
+		order = append(order, a.songIndex(path))
+	}
+	return order
+}
+
+// reshuffle draws a new order for the list, starting with the current song
+// so the order carries on from it
+func (a *App) reshuffle() {
+	a.shuffleOrder = a.shuffleOrder[:0]
+	for _, i := range rand.Perm(len(a.songs)) {
+		a.shuffleOrder = append(a.shuffleOrder, a.songs[i].Path)
+	}
+	if a.currentSong >= 0 && a.currentSong < len(a.songs) {
+		current := slices.Index(a.shuffleOrder, a.songs[a.currentSong].Path)
+		a.shuffleOrder[0], a.shuffleOrder[current] = a.shuffleOrder[current], a.shuffleOrder[0]
+	}
+}
+
+// syncShuffleOrder follows changes to the list: songs gone from it are
+// dropped from the order, and new ones slotted in after the current song
+func (a *App) syncShuffleOrder() {
+	inList := make(map[string]bool, len(a.songs))
+	for _, song := range a.songs {
+		inList[song.Path] = true
+	}
+	ordered := make(map[string]bool, len(a.shuffleOrder))
+	a.shuffleOrder = slices.DeleteFunc(a.shuffleOrder, func(path string) bool {
+		if !inList[path] || ordered[path] {
+			return true
+		}
+		ordered[path] = true
+		return false
+	})
+
+	after := 0
+	if a.currentSong >= 0 && a.currentSong < len(a.songs) {
+		after = slices.Index(a.shuffleOrder, a.songs[a.currentSong].Path) + 1
+	}
+	for _, song := range a.songs {
+		if !ordered[song.Path] {
+			at := after + rand.Intn(len(a.shuffleOrder)-after+1)
+			a.shuffleOrder = slices.Insert(a.shuffleOrder, at, song.Path)
+		}
+	}
+}
+
+// nextInOrder returns the song that plays after song from, going round to
+// the start of the order when wrap is set, or -1 at the end
+func (a *App) nextInOrder(from int, wrap bool) int {
+	order := a.playOrder()
+	if len(order) == 0 {
+		return -1
+	}
+	if position := slices.Index(order, from); position+1 < len(order) {
+		return order[position+1]
+	}
+	if wrap {
+		return order[0]
+	}
+	return -1
+}
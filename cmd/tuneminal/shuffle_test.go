@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/tuneminal/tuneminal/pkg/config"
+	"github.com/tuneminal/tuneminal/pkg/queue"
+)
+
+// newOrderApp returns an app with n songs in its list, the first playing
+func newOrderApp(n int) *App {
+	a := &App{appConfig: config.DefaultConfig(), playQueue: queue.New()}
+	for i := range n {
+		a.songs = append(a.songs, Song{Title: fmt.Sprint(i), Path: fmt.Sprintf("/music/%d.mp3", i)})
+	}
+	return a
+}
+
+// orderPaths returns the paths of the songs in order
+func orderPaths(a *App, order []int) []string {
+	paths := make([]string, len(order))
+	for i, index := range order {
+		paths[i] = a.songs[index].Path
+	}
+	return paths
+}
+
+func TestShuffleOrderKeptAcrossNext(t *testing.T) {
+	a := newOrderApp(10)
+	a.currentSong = 4
+	a.shuffleMode = true
+	a.reshuffle()
+
+	order := a.playOrder()
+	if order[0] != 4 {
+		t.Fatalf("Expected the order to start at the current song, got %v", order)
+	}
+	for i := 1; i < len(order); i++ {
+		next := a.nextInOrder(a.currentSong, true)
+		if next != order[i] {
+			t.Fatalf("Step %d: got song %d, want %d from %v", i, next, order[i], order)
+		}
+		a.currentSong = next
+	}
+	if next := a.nextInOrder(a.currentSong, true); next != order[0] {
+		t.Errorf("Expected repeat to go round the same order, got %d", next)
+	}
+	if next := a.nextInOrder(a.currentSong, false); next != -1 {
+		t.Errorf("Expected the end of the order without repeat, got %d", next)
+	}
+	if again := a.playOrder(); !slices.Equal(again, order) {
+		t.Errorf("Expected the order kept, got %v then %v", order, again)
+	}
+}
+
+func TestShuffleFollowsListChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(a *App)
+	}{
+		{"song removed", func(a *App) {
+			a.songs = slices.Delete(a.songs, 2, 3)
+			if a.currentSong > 2 {
+				a.currentSong--
+			}
+		}},
+		{"playing song removed", func(a *App) {
+			a.songs = slices.Delete(a.songs, a.currentSong, a.currentSong+1)
+			a.currentSong = -1
+		}},
+		{"songs added", func(a *App) {
+			a.songs = append(a.songs, Song{Path: "/music/new1.mp3"}, Song{Path: "/music/new2.mp3"})
+		}},
+		{"songs added and removed", func(a *App) {
+			a.songs = append(a.songs[1:], Song{Path: "/music/new.mp3"})
+			a.currentSong--
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newOrderApp(8)
+			a.currentSong = 3
+			a.shuffleMode = true
+			a.reshuffle()
+			before := orderPaths(a, a.playOrder())
+			a.nextInOrder(a.currentSong, true)
+
+			tt.change(a)
+			after := orderPaths(a, a.playOrder())
+
+			// Every song in the list once
+			if len(after) != len(a.songs) {
+				t.Fatalf("Expected %d songs in the order, got %v", len(a.songs), after)
+			}
+			for _, song := range a.songs {
+				if !slices.Contains(after, song.Path) {
+					t.Errorf("%s is missing from the order %v", song.Path, after)
+				}
+			}
+
+			// Songs that stayed keep their order, and new ones come after the
+			// playing song
+			kept := slices.DeleteFunc(slices.Clone(before), func(path string) bool { return !slices.Contains(after, path) })
+			remaining := slices.DeleteFunc(slices.Clone(after), func(path string) bool { return !slices.Contains(before, path) })
+			if !slices.Equal(kept, remaining) {
+				t.Errorf("Expected the order kept, got %v then %v", before, after)
+			}
+			if a.currentSong >= 0 {
+				playing := slices.Index(after, a.songs[a.currentSong].Path)
+				for i, path := range after {
+					if !slices.Contains(before, path) && i <= playing {
+						t.Errorf("Expected %s after the playing song in %v", path, after)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAutoAdvanceIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		repeat    bool
+		repeatOne bool
+		playlist  string
+		queued    int // -1 for none
+		current   int
+		want      int
+	}{
+		{name: "library stops after the song", queued: -1, current: 1, want: -1},
+		{name: "repeat carries on", repeat: true, queued: -1, current: 1, want: 2},
+		{name: "repeat goes round", repeat: true, queued: -1, current: 3, want: 0},
+		{name: "repeat one", repeat: true, repeatOne: true, queued: -1, current: 2, want: 2},
+		{name: "repeat one before the queue", repeatOne: true, queued: 0, current: 2, want: 2},
+		{name: "queue first", queued: 0, current: 2, want: 0},
+		{name: "playlist carries on", playlist: "Party", queued: -1, current: 1, want: 2},
+		{name: "playlist ends", playlist: "Party", queued: -1, current: 3, want: -1},
+	}
+	for _, tt := range tests {
+		a := newOrderApp(4)
+		a.currentSong = tt.current
+		a.repeatMode, a.repeatOne = tt.repeat, tt.repeatOne
+		a.currentPlaylist = tt.playlist
+		if tt.queued >= 0 {
+			a.playQueue.Enqueue(a.songs[tt.queued].Path)
+		}
+		if got := a.autoAdvanceIndex(); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUpcomingSongs(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int
+		repeat  bool
+		shuffle bool
+		want    []int // nil to check only that each song comes once
+	}{
+		{name: "rest of the list", current: 1, want: []int{2, 3}},
+		{name: "repeat goes round", current: 1, repeat: true, want: []int{2, 3, 0}},
+		{name: "current song not in the order", current: 7, repeat: true, want: []int{0, 1, 2, 3}},
+		{name: "shuffled", current: 2, repeat: true, shuffle: true},
+		{name: "shuffled, current song not in the order", current: 7, repeat: true, shuffle: true},
+	}
+	for _, tt := range tests {
+		a := newOrderApp(4)
+		a.currentSong = tt.current
+		a.repeatMode = tt.repeat
+		a.currentPlaylist = "Party"
+		if tt.shuffle {
+			a.shuffleMode = true
+			a.reshuffle()
+		}
+
+		got := a.upcomingSongs()
+		if tt.want != nil && !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+		seen := make(map[int]bool)
+		for _, index := range got {
+			if seen[index] || index == tt.current {
+				t.Errorf("%s: song %d listed again in %v", tt.name, index, got)
+			}
+			seen[index] = true
+		}
+	}
+}
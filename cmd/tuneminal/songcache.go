@@ -0,0 +1,57 @@
+package main
+
+import (
+	"github.com/tuneminal/tuneminal/pkg/paths"
+	"github.com/tuneminal/tuneminal/pkg/remotefs"
+	"github.com/tuneminal/tuneminal/pkg/songcache"
+)
+
+// Song cache modes, set by song_cache in the config
+const (
+	songCacheOff     = "off"
+	songCacheNetwork = "network" // only for songs on NFS, SMB and similar shares
+	songCacheAlways  = "always"
+)
+
+// newSongCache creates the song cache in the cache directory, sized from the config
+func (a *App) newSongCache() *songcache.Cache {
+	return songcache.New(paths.Cache("songs"), int64(a.appConfig.SongCacheMB)<<20)
+}
+
+// newDecodeCache creates the cache of decoded songs, or returns nil when the
+// config turns it off
+func (a *App) newDecodeCache() *songcache.Cache {
+	if a.appConfig.DecodeCacheMB <= 0 {
+		return nil
+	}
+	return songcache.New(paths.Cache("decoded"), int64(a.appConfig.DecodeCacheMB)<<20)
+}
+
+// playbackPath returns the file to play a song from: its local copy when one
+// is cached, or else the song itself, which is then copied in the background
+// so the next play doesn't read over the network. Remote songs are always
+// played from their fetched copy.
+func (a *App) playbackPath(song Song) string {
+	if remotefs.IsRemote(song.Path) {
+		if cached, ok := a.remotePlaybackPath(song); ok {
+			return cached
+		}
+		return song.Path
+	}
+
+	switch a.appConfig.SongCache {
+	case songCacheAlways:
+	case songCacheNetwork:
+		if !songcache.IsNetworkPath(song.Path) {
+			return song.Path
+		}
+	default:
+		return song.Path
+	}
+
+	if cached, ok := a.songCache.Lookup(song.Path); ok {
+		return cached
+	}
+	go a.songCache.Add(song.Path)
+	return song.Path
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/undo"
+)
+
+// showSongMenu lists everything that can be done with the selected song, so
+// the actions can be found without knowing their hotkeys
+func (a *App) showSongMenu() {
+	selected := a.selectedIndex()
+	if selected < 0 || selected >= len(a.songs) {
+		return
+	}
+	song := a.songs[selected]
+
+	menu := tview.NewList().ShowSecondaryText(false)
+	menu.SetBorder(true).
+		SetTitle(" " + song.Title + " ").
+		SetTitleAlign(tview.AlignCenter)
+
+	closeMenu := func() {
+		a.pages.RemovePage("song-menu")
+		a.app.SetFocus(a.songList)
+	}
+	// item adds an action that runs once the menu is closed
+	item := func(label string, shortcut rune, action func()) {
+		menu.AddItem(label, "", shortcut, func() {
+			closeMenu()
+			action()
+		})
+	}
+
+	item("Play", 'p', a.playSelectedSong)
+	if a.currentSong >= 0 && selected != a.currentSong {
+		item("Play next", 'n', func() { a.enqueueSong(selected, true) })
+	}
+	item("Add to queue", 'q', func() { a.enqueueSong(selected, false) })
+	item("More by this artist", 'r', func() { a.jumpToArtist(song) })
+	if song.Album != "" {
+		item("More from this album", 'l', func() { a.jumpToAlbum(song) })
+	}
+	// Read-only mode leaves out the actions that change files, as it turns
+	// off their keys
+	if !a.readOnly {
+		item("Add to playlist...", 'a', a.showAddToPlaylist)
+	}
+	item("Mark as favorite", 'm', func() { a.showMessage("⭐ Song marked as favorite!") })
+	item("Song information", 'i', a.showSongInfo)
+	item("Practice notes...", 'o', func() { a.showSongNotes(song) })
+	if !a.readOnly {
+		item("Edit lyrics", 'e', a.openLyricsEditor)
+		if embedded := a.embeddedLyrics(song.Path); hasTimedLyrics(embedded) {
+			item("Export embedded lyrics to .lrc", 'x', func() { a.exportEmbeddedLyrics(song) })
+		} else if embedded != "" && song.LyricsPath == "" {
+			item("Time embedded lyrics...", 't', func() { a.timeEmbeddedLyrics(song) })
+		}
+		if a.canWrite(filepath.Dir(song.Path)) {
+			item("Move, rename or delete...", 'f', a.showFileManager)
+		} else {
+			item("[gray]Move, rename or delete... (read-only media)[white]", 'f', a.showFileManager)
+		}
+		item("Hide from library", 'h', a.hideSelectedSong)
+	}
+	menu.SetDoneFunc(closeMenu)
+
+	a.pages.AddPage("song-menu", centered(menu, 44, menu.GetItemCount()+2), true, true)
+	a.app.SetFocus(menu)
+}
+
+// showAddToPlaylist lets the selected song be added to an existing playlist
+// or a new one
+func (a *App) showAddToPlaylist() {
+	if a.denyReadOnly("Editing playlists") {
+		return
+	}
+
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).
+		SetTitle(" Add to Playlist - r rename ").
+		SetTitleAlign(tview.AlignCenter)
+
+	closeList := func() {
+		a.pages.RemovePage("add-to-playlist")
+		a.app.SetFocus(a.songList)
+	}
+	add := func(name string) {
+		if err := a.addSongToPlaylist(name); err != nil {
+			a.handleError(err, "Add to Playlist")
+			return
+		}
+		a.showToast(fmt.Sprintf("[green]✓ Added to %s[white]", name))
+	}
+
+	for _, name := range a.getPlaylistList() {
+		name := name
+		list.AddItem(name, "", 0, func() {
+			closeList()
+			add(name)
+		})
+	}
+	list.AddItem("[yellow]New playlist...[white]", "", 0, func() {
+		closeList()
+		a.showNewPlaylistInput(add)
+	})
+	list.SetDoneFunc(closeList)
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() != 'r' {
+			return event
+		}
+		index := list.GetCurrentItem()
+		if names := a.getPlaylistList(); index < len(names) {
+			closeList()
+			a.showRenamePlaylistInput(names[index])
+		}
+		return nil
+	})
+
+	a.pages.AddPage("add-to-playlist", centered(list, 44, 14), true, true)
+	a.app.SetFocus(list)
+}
+
+// showNewPlaylistInput asks for a playlist name, creates the playlist and
+// passes the name on
+func (a *App) showNewPlaylistInput(created func(name string)) {
+	input := tview.NewInputField().SetLabel("Name: ")
+	input.SetBorder(true).
+		SetTitle(" New Playlist ").
+		SetTitleAlign(tview.AlignCenter)
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		a.pages.RemovePage("new-playlist")
+		a.app.SetFocus(a.songList)
+		name := strings.TrimSpace(input.GetText())
+		if key != tcell.KeyEnter || name == "" {
+			return
+		}
+		if err := a.createPlaylist(name, ""); err != nil {
+			a.handleError(err, "Create Playlist")
+			return
+		}
+		created(name)
+	})
+
+	a.pages.AddPage("new-playlist", centered(input, 44, 3), true, true)
+	a.app.SetFocus(input)
+}
+
+// showRenamePlaylistInput asks for a new name for a playlist and renames it
+func (a *App) showRenamePlaylistInput(name string) {
+	input := tview.NewInputField().
+		SetLabel("Name: ").
+		SetText(name)
+	input.SetBorder(true).
+		SetTitle(" Rename Playlist ").
+		SetTitleAlign(tview.AlignCenter)
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		a.pages.RemovePage("rename-playlist")
+		a.app.SetFocus(a.songList)
+		newName := strings.TrimSpace(input.GetText())
+		if key != tcell.KeyEnter || newName == "" || newName == name {
+			return
+		}
+		if err := a.renamePlaylist(name, newName); err != nil {
+			a.handleError(err, "Rename Playlist")
+			return
+		}
+		a.undoStack.Push(undo.Action{
+			Description: fmt.Sprintf("rename playlist %s to %s", name, newName),
+			Undo: func() error {
+				return a.renamePlaylist(newName, name)
+			},
+			Redo: func() error {
+				return a.renamePlaylist(name, newName)
+			},
+		})
+		a.showToast(fmt.Sprintf("[green]✓ Renamed %s to %s[white]", name, newName))
+	})
+
+	a.pages.AddPage("rename-playlist", centered(input, 44, 3), true, true)
+	a.app.SetFocus(input)
+}
+
+// renamePlaylist renames a playlist, following it if it's the one loaded
+func (a *App) renamePlaylist(oldName, newName string) error {
+	if err := a.playlistManager.RenamePlaylist(oldName, newName); err != nil {
+		return err
+	}
+	if a.currentPlaylist == oldName {
+		a.currentPlaylist = newName
+		a.updateAllDisplays()
+	}
+	return nil
+}
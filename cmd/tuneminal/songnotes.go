@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/overrides"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// songNotes returns the practice notes of the current song
+func (a *App) songNotes() string {
+	if a.currentSong < 0 || a.currentSong >= len(a.songs) {
+		return ""
+	}
+	return strings.TrimSpace(a.overrides.Get(a.songs[a.currentSong].Path).Notes)
+}
+
+// createSongNotesDisplay shows the current song's notes in the lyrics panel
+// during the intro, so they're read before the first line comes. It returns
+// "" the rest of the time.
+func (a *App) createSongNotesDisplay() string {
+	if !a.appConfig.SongNotes || (!a.isPlaying && !a.isPaused) || len(a.lyricLines) == 0 {
+		return ""
+	}
+	if a.position >= a.lyricLines[0].Time {
+		return ""
+	}
+	notes := a.songNotes()
+	if notes == "" {
+		return ""
+	}
+
+	lines := []string{"[yellow::b]📝 NOTES[white::-]", ""}
+	for _, line := range strings.Split(notes, "\n") {
+		lines = append(lines, wrapLyric(tview.Escape(line), a.lyricsWidth())...)
+	}
+	lines = append(lines, "", "[gray]First line in "+utils.FormatDuration(a.lyricLines[0].Time-a.position)+"[white]")
+
+	_, _, _, height := a.lyrics.GetInnerRect()
+	padding := max(0, (height-len(lines))/2)
+	return strings.Repeat("\n", padding) + strings.Join(lines, "\n")
+}
+
+// showSongNotes edits the practice notes of a song, such as key changes,
+// breath marks or "skip the bridge"
+func (a *App) showSongNotes(song Song) {
+	if a.denyReadOnly("Editing song notes") {
+		return
+	}
+
+	notes := tview.NewTextArea().
+		SetLabel("Notes").
+		SetText(a.overrides.Get(song.Path).Notes, true).
+		SetSize(8, 0)
+	showNotes := tview.NewCheckbox().
+		SetLabel("Show notes before the first line").
+		SetChecked(a.appConfig.SongNotes)
+
+	closeNotes := func() {
+		a.pages.RemovePage("song-notes")
+		a.app.SetFocus(a.songList)
+	}
+
+	form := tview.NewForm().
+		AddFormItem(notes).
+		AddFormItem(showNotes).
+		AddButton("Save", func() {
+			text := strings.TrimSpace(notes.GetText())
+			err := a.overrides.Update(song.Path, func(o *overrides.Override) {
+				o.Notes = text
+			})
+			if err != nil {
+				a.handleError(err, "Save Song Notes")
+				return
+			}
+			if showNotes.IsChecked() != a.appConfig.SongNotes {
+				a.appConfig.SongNotes = showNotes.IsChecked()
+				a.saveConfig()
+			}
+			closeNotes()
+			a.updateKaraokeLyrics()
+			a.showToast("[green]✓ Notes saved for " + song.Title + "[white]")
+		}).
+		AddButton("Cancel", closeNotes)
+	form.SetCancelFunc(closeNotes)
+	form.SetBorder(true).
+		SetTitle(" Notes - " + song.Title + " ").
+		SetTitleAlign(tview.AlignCenter)
+
+	a.pages.AddPage("song-notes", centered(form, 70, 18), true, true)
+	a.app.SetFocus(form)
+}
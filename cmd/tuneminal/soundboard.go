@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+	"github.com/tuneminal/tuneminal/pkg/paths"
+	"github.com/tuneminal/tuneminal/pkg/player"
+)
+
+// soundboardPads is how many sounds the soundboard has keys for, 1 to 9
+const soundboardPads = 9
+
+// soundboardDir is where the soundboard's sound files are kept
+func soundboardDir() string {
+	return paths.Data("soundboard")
+}
+
+// soundboardFiles returns the file on each pad: those named in the config,
+// in order, or else every sound in the soundboard directory from A to Z
+func (a *App) soundboardFiles() []string {
+	var files []string
+	if len(a.appConfig.Soundboard) > 0 {
+		for _, name := range a.appConfig.Soundboard {
+			files = append(files, filepath.Join(soundboardDir(), name))
+		}
+	} else {
+		files, _ = metadata.ListAudioFiles(soundboardDir())
+		sort.Strings(files)
+	}
+	if len(files) > soundboardPads {
+		files = files[:soundboardPads]
+	}
+	return files
+}
+
+// soundboardLabel names a pad's sound after its file
+func soundboardLabel(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// playSoundboardPad plays the sound on pad (from 0), over the music when a
+// song is playing
+func (a *App) playSoundboardPad(pad int) {
+	files := a.soundboardFiles()
+	if pad >= len(files) {
+		a.showToast(fmt.Sprintf("[yellow]Nothing on soundboard pad %d - press z to see the pads[white]", pad+1))
+		return
+	}
+	if a.player == nil {
+		return
+	}
+
+	path := files[pad]
+	sound, ok := a.sounds[path]
+	if !ok {
+		var err error
+		if sound, err = player.LoadSound(path); err != nil {
+			a.handleError(err, "Soundboard")
+			return
+		}
+		if a.sounds == nil {
+			a.sounds = make(map[string]*player.Sound)
+		}
+		a.sounds[path] = sound
+	}
+
+	if err := a.player.PlayClip(player.SourceSoundboard, sound, a.appConfig.SoundboardVolume); err != nil {
+		a.handleError(err, "Soundboard")
+		return
+	}
+	a.showToast(fmt.Sprintf("[cyan]🔊 %s[white]", tview.Escape(soundboardLabel(path))))
+}
+
+// showSoundboard shows which sound is on each pad. Pressing a number plays
+// it, x silences them and space pauses the music; Alt+1-9 play the pads
+// without opening it.
+func (a *App) showSoundboard() {
+	// Sounds are read again in case the files changed
+	a.sounds = nil
+	os.MkdirAll(soundboardDir(), 0755)
+
+	view := tview.NewTextView().SetDynamicColors(true)
+	view.SetBorder(true).
+		SetTitle(" Soundboard - 1-9 play, x silences, Esc closes ").
+		SetTitleAlign(tview.AlignCenter)
+
+	files := a.soundboardFiles()
+	var text strings.Builder
+	for i, path := range files {
+		label := tview.Escape(soundboardLabel(path))
+		if _, err := os.Stat(path); err != nil {
+			label = "[red]" + label + " (missing)[white]"
+		}
+		fmt.Fprintf(&text, " [yellow]%d[white]  %s\n", i+1, label)
+	}
+	if len(files) == 0 {
+		text.WriteString(" [gray]No sounds yet - add .wav or .mp3 files,\n such as applause, airhorn or drumroll, to:[white]\n")
+	}
+	fmt.Fprintf(&text, "\n [gray]%s[white]", tview.Escape(soundboardDir()))
+	view.SetText(text.String())
+
+	closeView := func() {
+		a.pages.RemovePage("soundboard")
+		a.app.SetFocus(a.songList)
+	}
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			closeView()
+			return nil
+		}
+		switch r := event.Rune(); {
+		case r >= '1' && r < '1'+soundboardPads:
+			a.playSoundboardPad(int(r - '1'))
+		case r == 'x':
+			if a.player != nil {
+				a.player.StopClips(player.SourceSoundboard)
+			}
+		case r == ' ':
+			a.togglePlayPause()
+		case r == 'z':
+			closeView()
+		}
+		return nil
+	})
+
+	width := len(soundboardDir()) + 6
+	width = max(48, min(width, 80))
+	a.pages.AddPage("soundboard", centered(view, width, max(len(files), 2)+5), true, true)
+	a.app.SetFocus(view)
+}
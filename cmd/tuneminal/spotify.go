@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/tuneminal/tuneminal/pkg/playlist"
+	"github.com/tuneminal/tuneminal/pkg/spotify"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// formatSpotifyImport reports how many tracks of each playlist were found,
+// listing the ones that weren't
+func formatSpotifyImport(results []spotify.Result) string {
+	var content strings.Builder
+	for i, result := range results {
+		if i > 0 {
+			content.WriteString("\n")
+		}
+		total := len(result.Songs) + len(result.Unmatched)
+		content.WriteString(fmt.Sprintf("%s: matched %d of %d tracks\n", result.Name, len(result.Songs), total))
+		for _, track := range result.Unmatched {
+			content.WriteString(fmt.Sprintf("  not found: %s - %s\n", track.Artist, track.Title))
+		}
+	}
+	return content.String()
+}
+
+// runSpotifyImportCommand handles "tuneminal import-spotify"
+func runSpotifyImportCommand(args []string, readOnly bool) error {
+	flags := flag.NewFlagSet("import-spotify", flag.ContinueOnError)
+	apply := flags.Bool("apply", false, "create the playlists (default is a dry run)")
+	name := flags.String("name", "", "local playlist name, for an export holding one playlist")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: tuneminal import-spotify [-name NAME] [-apply] playlist.csv|playlist.json")
+	}
+
+	playlists, err := spotify.Open(utils.ExpandHome(flags.Arg(0)))
+	if err != nil {
+		return err
+	}
+	if *name != "" {
+		if len(playlists) != 1 {
+			return fmt.Errorf("-name only works for an export holding one playlist, this one has %d", len(playlists))
+		}
+		playlists[0].Name = *name
+	}
+
+	songs, err := scanLibraryFolders()
+	if err != nil {
+		return err
+	}
+	results := make([]spotify.Result, len(playlists))
+	for i, exported := range playlists {
+		results[i] = spotify.Match(exported, songs)
+	}
+	fmt.Print(formatSpotifyImport(results))
+
+	if !*apply {
+		fmt.Println("\nDry run only; pass -apply to create the playlists.")
+		return nil
+	}
+	if readOnly {
+		return errReadOnly
+	}
+
+	manager := playlist.NewPlaylistManager()
+	added := 0
+	for _, result := range results {
+		if len(result.Songs) == 0 {
+			continue
+		}
+		count, err := mergeIntoPlaylist(manager, result.Name, "Imported from Spotify", result.Songs)
+		if err != nil {
+			return err
+		}
+		added += count
+	}
+	fmt.Printf("\nAdded %d song(s) to %d playlist(s)\n", added, len(results))
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/export"
+	"github.com/tuneminal/tuneminal/pkg/history"
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/overrides"
+)
+
+// statsDateLayout is how dates are given for the statistics range
+const statsDateLayout = "2006-01-02"
+
+// librarySongStats lists every visible library song with its tags and lyric
+// status, ready to be joined with the play history
+func librarySongStats() ([]export.SongStats, error) {
+	songs, err := scanLibraryFolders()
+	if err != nil {
+		return nil, err
+	}
+
+	store := overrides.NewStore()
+	var rows []export.SongStats
+	for _, meta := range songs {
+		if store.IsHidden(meta.Path) {
+			continue
+		}
+		status, _ := lyrics.CheckSong(meta.Path)
+		rows = append(rows, export.SongStats{
+			Path:   meta.Path,
+			Title:  meta.Title,
+			Artist: meta.Artist,
+			Album:  meta.Album,
+			Lyrics: status.String(),
+		})
+	}
+	return rows, nil
+}
+
+// parseDateRange reads an inclusive range of days; either end may be empty
+// to leave it open. The end comes back as the start of the day after.
+func parseDateRange(from, to string) (time.Time, time.Time, error) {
+	var start, end time.Time
+	var err error
+	if from != "" {
+		if start, err = time.ParseInLocation(statsDateLayout, from, time.Local); err != nil {
+			return start, end, fmt.Errorf("invalid start date %q, expected YYYY-MM-DD", from)
+		}
+	}
+	if to != "" {
+		if end, err = time.ParseInLocation(statsDateLayout, to, time.Local); err != nil {
+			return start, end, fmt.Errorf("invalid end date %q, expected YYYY-MM-DD", to)
+		}
+		end = end.AddDate(0, 0, 1)
+	}
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		return start, end, fmt.Errorf("the start date is after the end date")
+	}
+	return start, end, nil
+}
+
+// exportFullStats joins the library and the history for the range and
+// writes them to the export directory
+func exportFullStats(manager *export.ExportManager, from, to, format string) error {
+	start, end, err := parseDateRange(from, to)
+	if err != nil {
+		return err
+	}
+	library, err := librarySongStats()
+	if err != nil {
+		return err
+	}
+	plays, err := history.NewStore().Plays()
+	if err != nil {
+		return err
+	}
+	plays = libraryPlays(plays)
+	return manager.ExportStats(library, plays, start, end, format)
+}
+
+// showStatsExportDialog asks for the date range and format of a full
+// statistics export
+func (a *App) showStatsExportDialog() {
+	from := tview.NewInputField().
+		SetLabel("From (YYYY-MM-DD, empty for all)").
+		SetFieldWidth(11)
+	to := tview.NewInputField().
+		SetLabel("To (YYYY-MM-DD, empty for today)").
+		SetFieldWidth(11)
+	formats := []string{"csv", "json"}
+	format := tview.NewDropDown().
+		SetLabel("Format").
+		SetOptions(formats, nil).
+		SetCurrentOption(0)
+
+	closeForm := func() {
+		a.pages.RemovePage("stats-export")
+		a.app.SetFocus(a.songList)
+	}
+
+	form := tview.NewForm().
+		AddFormItem(from).
+		AddFormItem(to).
+		AddFormItem(format).
+		AddButton("Export", func() {
+			_, chosen := format.GetCurrentOption()
+			if err := exportFullStats(a.exportManager, from.GetText(), to.GetText(), chosen); err != nil {
+				a.handleError(err, "Full Stats Export")
+				return
+			}
+			closeForm()
+			a.showExportSuccess("Full statistics exported as " + chosen)
+		}).
+		AddButton("Cancel", closeForm)
+	form.SetCancelFunc(closeForm)
+
+	form.SetTitle(" Full Statistics Export ").SetBorder(true)
+	a.pages.AddPage("stats-export", centered(form, 60, 11), true, true)
+	a.app.SetFocus(form)
+}
+
+// runStatsCommand handles "tuneminal stats"
+func runStatsCommand(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	format := flags.String("format", "csv", "csv or json")
+	from := flags.String("from", "", "first day to include, YYYY-MM-DD")
+	to := flags.String("to", "", "last day to include, YYYY-MM-DD")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("usage: tuneminal stats [-format csv|json] [-from DATE] [-to DATE]")
+	}
+
+	manager := newExportManager(savedConfig())
+	if err := exportFullStats(manager, *from, *to, *format); err != nil {
+		return err
+	}
+	fmt.Printf("Exported full statistics to %s\n", manager.GetExportPath())
+	return nil
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+)
+
+// syncEditorRows is how many lines the sync editor shows around the cursor
+const syncEditorRows = 15
+
+// syncEditorSession is the state of the lyrics sync editor
+type syncEditorSession struct {
+	song     Song
+	sync     *lyrics.Sync
+	selected int
+	changed  bool
+	view     *tview.TextView
+}
+
+// startSyncEditor plays the song and opens its lyrics in the sync editor,
+// where each line is stamped with the playback time as it starts and then
+// fine-tuned while the lyrics play back
+func (a *App) startSyncEditor(song Song, track *lyrics.Lyrics) {
+	if a.denyReadOnly("Editing lyrics") {
+		return
+	}
+	for i := range a.songs {
+		if a.songs[i].Path == song.Path {
+			a.currentSong = i
+			break
+		}
+	}
+	a.stop()
+	a.play()
+	if !a.isPlaying {
+		return
+	}
+	if a.position > 0 {
+		a.seekTapSync(0)
+	}
+
+	session := &syncEditorSession{
+		song: song,
+		sync: lyrics.NewSync(track),
+		view: tview.NewTextView().SetDynamicColors(true),
+	}
+	if next := session.sync.NextUnstamped(0); next >= 0 {
+		session.selected = next
+	}
+	session.view.SetBorder(true).
+		SetTitle(" Sync Lyrics - " + song.Title + " ").
+		SetTitleAlign(tview.AlignCenter)
+	session.view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		a.handleSyncEditorKey(session, event)
+		a.renderSyncEditor(session)
+		return nil
+	})
+
+	a.syncEditor = session
+	a.renderSyncEditor(session)
+	a.pages.AddPage("sync-editor", centered(session.view, 84, syncEditorRows+9), true, true)
+	a.app.SetFocus(session.view)
+}
+
+// handleSyncEditorKey acts on a key pressed in the sync editor
+func (a *App) handleSyncEditorKey(session *syncEditorSession, event *tcell.EventKey) {
+	lines := len(session.sync.Lines)
+	switch event.Key() {
+	case tcell.KeyEscape:
+		a.closeSyncEditor()
+		if session.changed {
+			a.showMessage("Lyrics sync discarded")
+		}
+		return
+	case tcell.KeyUp:
+		session.selected = max(0, session.selected-1)
+	case tcell.KeyDown:
+		session.selected = min(max(0, lines-1), session.selected+1)
+	case tcell.KeyLeft:
+		a.seekTapSync(a.position - 5*time.Second)
+	case tcell.KeyRight:
+		a.seekTapSync(min(a.duration, a.position+5*time.Second))
+	case tcell.KeyEnter:
+		// Replay from a little before the selected line
+		if session.selected < lines && session.sync.Lines[session.selected].Stamped {
+			a.seekTapSync(session.sync.Lines[session.selected].Time - 2*time.Second)
+		}
+	case tcell.KeyDelete:
+		a.deleteSyncLine(session)
+	case tcell.KeyCtrlS:
+		a.finishSyncEditor(session)
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case ' ':
+			// Stamp the selected line and move on to the next
+			if session.selected < lines {
+				session.sync.Stamp(session.selected, a.player.GetPosition())
+				session.selected = min(lines-1, session.selected+1)
+				session.changed = true
+			}
+		case '+', '=':
+			a.nudgeSyncLine(session, lyrics.NudgeStep)
+		case '-', '_':
+			a.nudgeSyncLine(session, -lyrics.NudgeStep)
+		case 'k':
+			session.selected = max(0, session.selected-1)
+		case 'j':
+			session.selected = min(max(0, lines-1), session.selected+1)
+		case 'i':
+			a.showSyncInsertInput(session, session.selected)
+		case 'a':
+			a.showSyncInsertInput(session, min(lines, session.selected+1))
+		case 'd':
+			a.deleteSyncLine(session)
+		case 'p':
+			if a.isPaused {
+				a.resume()
+			} else {
+				a.pause()
+			}
+		}
+	}
+}
+
+// nudgeSyncLine moves the selected line's time earlier or later
+func (a *App) nudgeSyncLine(session *syncEditorSession, delta time.Duration) {
+	if err := session.sync.Nudge(session.selected, delta); err != nil {
+		a.showToast("[yellow]Stamp the line before nudging it[white]")
+		return
+	}
+	session.changed = true
+}
+
+// deleteSyncLine removes the selected line
+func (a *App) deleteSyncLine(session *syncEditorSession) {
+	if session.sync.Delete(session.selected) != nil {
+		return
+	}
+	session.selected = max(0, min(session.selected, len(session.sync.Lines)-1))
+	session.changed = true
+}
+
+// showSyncInsertInput asks for the text of a new line to put at index
+func (a *App) showSyncInsertInput(session *syncEditorSession, index int) {
+	input := tview.NewInputField().SetLabel("Line: ")
+	input.SetBorder(true).
+		SetTitle(" Insert Line ").
+		SetTitleAlign(tview.AlignCenter)
+
+	input.SetDoneFunc(func(key tcell.Key) {
+		a.pages.RemovePage("sync-insert")
+		a.app.SetFocus(session.view)
+		text := strings.TrimSpace(input.GetText())
+		if key != tcell.KeyEnter || text == "" {
+			return
+		}
+		session.sync.Insert(index, text)
+		session.selected = index
+		session.changed = true
+		a.renderSyncEditor(session)
+	})
+
+	a.pages.AddPage("sync-insert", centered(input, 70, 3), true, true)
+	a.app.SetFocus(input)
+}
+
+// renderSyncEditor draws the lines around the cursor, with the line playing
+// now marked so the timing can be checked as it is edited
+func (a *App) renderSyncEditor(session *syncEditorSession) {
+	sync := session.sync
+	playing := sync.IndexAt(a.position)
+
+	var content strings.Builder
+	content.WriteString("[dim]Space stamp • +/- nudge 100ms • ↑/↓ select • Enter replay line • ←/→ seek\n")
+	content.WriteString("i/a insert before/after • d delete • p pause • Ctrl+S save • Esc cancel[white]\n\n")
+
+	if len(sync.Lines) == 0 {
+		content.WriteString("[gray]No lyrics yet - press i to add the first line[white]\n")
+	}
+	first := max(0, min(session.selected-syncEditorRows/2, len(sync.Lines)-syncEditorRows))
+	for i := first; i < first+syncEditorRows; i++ {
+		if i >= len(sync.Lines) {
+			content.WriteString("\n")
+			continue
+		}
+		line := sync.Lines[i]
+		stamp := "[--:--.--]"
+		if line.Stamped {
+			stamp = lyrics.FormatTimestamp(line.Time)
+		}
+		cursor := "  "
+		if i == session.selected {
+			cursor = "▶ "
+		}
+		color := "gray"
+		switch {
+		case i == playing:
+			color = "green::b"
+		case i == session.selected:
+			color = "yellow"
+		case line.Stamped:
+			color = "white"
+		}
+		content.WriteString(fmt.Sprintf("[%s]%s%s %s[white::-]\n", color, cursor, stamp, tview.Escape(line.Text)))
+	}
+
+	state := "▶"
+	if a.isPaused {
+		state = "⏸"
+	}
+	content.WriteString(fmt.Sprintf("\n[cyan]%s %s • %d/%d lines timed[white]",
+		state, lyrics.FormatTimestamp(a.position), sync.StampedCount(), len(sync.Lines)))
+	session.view.SetText(content.String())
+}
+
+// updateSyncEditor follows playback in the sync editor while it is open
+func (a *App) updateSyncEditor() {
+	if a.syncEditor != nil {
+		a.renderSyncEditor(a.syncEditor)
+	}
+}
+
+// finishSyncEditor saves the edited timing as the song's LRC file
+func (a *App) finishSyncEditor(session *syncEditorSession) {
+	a.closeSyncEditor()
+	if err := a.saveTimedLyrics(session.song, session.sync.Lyrics()); err != nil {
+		a.handleError(err, "Save Lyrics")
+	}
+}
+
+// closeSyncEditor stops playback and removes the sync editor
+func (a *App) closeSyncEditor() {
+	a.syncEditor = nil
+	a.stop()
+	a.pages.RemovePage("sync-editor")
+	a.app.SetFocus(a.songList)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// tapSyncSession is the state of the tap-to-sync timing tool
+type tapSyncSession struct {
+	song  Song
+	lines []string
+	times []time.Duration // one entry per line tapped so far
+	view  *tview.TextView
+}
+
+// importLyricsFromClipboard times clipboard lyrics for the selected song
+func (a *App) importLyricsFromClipboard() {
+	if a.denyReadOnly("Importing lyrics") {
+		return
+	}
+	if a.currentSong < 0 || a.currentSong >= len(a.songs) {
+		a.showWarning("Select a song to import lyrics for")
+		return
+	}
+
+	text, err := utils.ReadClipboard()
+	if err != nil {
+		a.handleError(err, "Import Lyrics")
+		return
+	}
+	a.startTapSync(a.songs[a.currentSong], lyrics.PlainLines(text))
+}
+
+// startTapSync plays a song from the start and stamps each lyric line as the
+// user taps along
+func (a *App) startTapSync(song Song, lines []string) {
+	if len(lines) == 0 {
+		a.showWarning("No lyrics to import - copy the lyrics text first")
+		return
+	}
+
+	for i := range a.songs {
+		if a.songs[i].Path == song.Path {
+			a.currentSong = i
+			break
+		}
+	}
+	a.stop()
+	a.play()
+	if !a.isPlaying {
+		return
+	}
+	if a.position > 0 {
+		// Timing always starts from the top, even for long-form audio
+		a.seekTapSync(0)
+	}
+
+	session := &tapSyncSession{
+		song:  song,
+		lines: lines,
+		view:  tview.NewTextView().SetDynamicColors(true),
+	}
+	session.view.SetBorder(true).
+		SetTitle(" Tap to Sync - " + song.Title + " ").
+		SetTitleAlign(tview.AlignCenter)
+	session.view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.closeTapSync()
+			a.showMessage("Lyrics import cancelled")
+			return nil
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			// Undo the last tap and replay from a little before it
+			if len(session.times) > 0 {
+				last := session.times[len(session.times)-1]
+				session.times = session.times[:len(session.times)-1]
+				a.seekTapSync(last - 3*time.Second)
+			}
+		case tcell.KeyEnter:
+			a.tapLine(session)
+		case tcell.KeyRune:
+			if event.Rune() == ' ' {
+				a.tapLine(session)
+			}
+		}
+		a.renderTapSync(session)
+		return nil
+	})
+
+	a.renderTapSync(session)
+	a.pages.AddPage("tap-sync", centered(session.view, 80, 20), true, true)
+	a.app.SetFocus(session.view)
+}
+
+// tapLine stamps the next line with the current playback position
+func (a *App) tapLine(session *tapSyncSession) {
+	if len(session.times) >= len(session.lines) {
+		return
+	}
+
+	session.times = append(session.times, a.player.GetPosition())
+	if len(session.times) == len(session.lines) {
+		a.finishTapSync(session)
+	}
+}
+
+// seekTapSync moves playback while timing, clamped to the start of the song
+func (a *App) seekTapSync(position time.Duration) {
+	if position < 0 {
+		position = 0
+	}
+	if err := a.player.SeekTo(position); err == nil {
+		a.position = position
+	}
+}
+
+// renderTapSync draws the timing tool around the next line to tap
+func (a *App) renderTapSync(session *tapSyncSession) {
+	next := len(session.times)
+
+	var content strings.Builder
+	content.WriteString("[dim]Space/Enter at the start of each line • Backspace undo • Esc cancel[white]\n\n")
+
+	for i := next - 3; i <= next+5; i++ {
+		if i < 0 || i >= len(session.lines) {
+			content.WriteString("\n")
+			continue
+		}
+		switch {
+		case i < next:
+			content.WriteString(fmt.Sprintf("[green]%s %s[white]\n", lyrics.FormatTimestamp(session.times[i]), session.lines[i]))
+		case i == next:
+			content.WriteString(fmt.Sprintf("[yellow::b]▶ %s[white::-]\n", session.lines[i]))
+		default:
+			content.WriteString(fmt.Sprintf("[gray]  %s[white]\n", session.lines[i]))
+		}
+	}
+
+	content.WriteString(fmt.Sprintf("\n[cyan]%d/%d lines timed[white]", next, len(session.lines)))
+	session.view.SetText(content.String())
+}
+
+// finishTapSync saves the timed lines as the song's LRC file
+func (a *App) finishTapSync(session *tapSyncSession) {
+	a.closeTapSync()
+
+	sync := lyrics.NewPlainSync(session.lines)
+	for i, t := range session.times {
+		sync.Stamp(i, t)
+	}
+	if err := a.saveTimedLyrics(session.song, sync.Lyrics()); err != nil {
+		a.handleError(err, "Save Lyrics")
+	}
+}
+
+// saveTimedLyrics writes timed lyrics as the song's LRC file, keeping the old
+// version for undo, and shows them if the song is the current one
+func (a *App) saveTimedLyrics(song Song, track *lyrics.Lyrics) error {
+	if track.Tags["ti"] == "" {
+		track.Tags["ti"] = song.Title
+	}
+	if track.Tags["ar"] == "" {
+		track.Tags["ar"] = song.Artist
+	}
+
+	lyricsPath := song.LyricsPath
+	if lyricsPath == "" || a.lyricResolver().IsCached(lyricsPath) {
+		lyricsPath = strings.TrimSuffix(song.Path, filepath.Ext(song.Path)) + ".lrc"
+	}
+	lyricsPath = a.writableLyricsPath(song, lyricsPath)
+	err := a.editFile("timed lyrics for "+song.Title, lyricsPath, func() error {
+		return track.Save(lyricsPath)
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range a.songs {
+		if a.songs[i].Path == song.Path {
+			a.songs[i].LyricsPath = lyricsPath
+		}
+	}
+	a.loadLyricsFromFile(lyricsPath)
+	a.updateAllDisplays()
+	a.offerEmbedLyrics(song, lyricsPath, fmt.Sprintf("✅ Saved %d timed lines to %s", len(track.Lines), filepath.Base(lyricsPath)))
+	return nil
+}
+
+// closeTapSync stops playback and removes the timing tool
+func (a *App) closeTapSync() {
+	a.stop()
+	a.pages.RemovePage("tap-sync")
+	a.app.SetFocus(a.songList)
+}
+
+// handleLaunchOptions acts on requests passed on the command line
+func (a *App) handleLaunchOptions() {
+	options := a.launchOptions
+	a.launchOptions = nil
+	if options == nil || len(options.importLyrics) == 0 {
+		return
+	}
+	if a.denyReadOnly("Importing lyrics") {
+		return
+	}
+
+	song, ok := a.songForImport(options.importSong)
+	if !ok {
+		a.showWarning("Song not found for lyrics import: " + options.importSong)
+		return
+	}
+	a.startTapSync(song, options.importLyrics)
+}
+
+// songForImport finds the song named on the command line, or the selected one
+func (a *App) songForImport(path string) (Song, bool) {
+	if path == "" {
+		if a.currentSong >= 0 && a.currentSong < len(a.songs) {
+			return a.songs[a.currentSong], true
+		}
+		return Song{}, false
+	}
+
+	target, _ := filepath.Abs(path)
+	for _, song := range a.songs {
+		if songPath, _ := filepath.Abs(song.Path); songPath == target {
+			return song, true
+		}
+	}
+	return Song{}, false
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/overrides"
+)
+
+// lyricsTranspose returns the [transpose:] tag of the loaded lyrics
+func (a *App) lyricsTranspose() (int, bool) {
+	if a.lyricTrack == nil {
+		return 0, false
+	}
+	return a.lyricTrack.Transpose()
+}
+
+// songTranspose returns the pitch shift for a song: the user's own choice if
+// they made one, otherwise the lyrics' [transpose:] tag
+func (a *App) songTranspose(song Song) int {
+	if manual := a.overrides.Get(song.Path).Transpose; manual != nil {
+		return *manual
+	}
+	semitones, _ := a.lyricsTranspose()
+	return semitones
+}
+
+// adjustTranspose shifts the current song's pitch and remembers it for the song
+func (a *App) adjustTranspose(delta int) {
+	if a.player == nil || a.currentSong < 0 || a.currentSong >= len(a.songs) {
+		return
+	}
+	song := a.songs[a.currentSong]
+
+	semitones := a.songTranspose(song) + delta
+	if semitones < -12 || semitones > 12 {
+		return
+	}
+
+	// Going back to what the lyrics ask for drops the manual setting
+	tagged, _ := a.lyricsTranspose()
+	err := a.overrides.Update(song.Path, func(o *overrides.Override) {
+		if semitones == tagged {
+			o.Transpose = nil
+		} else {
+			o.Transpose = &semitones
+		}
+	})
+	if err != nil {
+		a.handleError(err, "Save Transpose")
+		return
+	}
+
+	// Heard straight away, without stopping the song to decode it again
+	a.player.SetTranspose(semitones)
+
+	a.showToast(fmt.Sprintf("🎼 Transpose %s", formatTranspose(semitones)))
+	a.updateNowPlaying()
+}
+
+// keyStatus describes the song's key and transpose for Now Playing, empty
+// when neither is set
+func (a *App) keyStatus() string {
+	semitones := 0
+	if a.player != nil {
+		semitones = a.player.GetPitch()
+	}
+
+	key := ""
+	if a.lyricTrack != nil {
+		key = a.lyricTrack.Key()
+	}
+
+	switch {
+	case key != "" && semitones != 0:
+		return fmt.Sprintf("%s → %s (%s)", key, lyrics.TransposeKey(key, semitones), formatTranspose(semitones))
+	case key != "":
+		return key
+	case semitones != 0:
+		return formatTranspose(semitones)
+	}
+	return ""
+}
+
+// formatTranspose writes a pitch shift as signed semitones
+func formatTranspose(semitones int) string {
+	if semitones == 0 {
+		return "0 (original key)"
+	}
+	return fmt.Sprintf("%+d semitones", semitones)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/tuneminal/tuneminal/pkg/lyrics"
+	"github.com/tuneminal/tuneminal/pkg/organize"
+	"github.com/tuneminal/tuneminal/pkg/undo"
+)
+
+// undoLimit is how many edits Ctrl+Z can take back
+const undoLimit = 50
+
+// undoEdit takes back the most recent edit
+func (a *App) undoEdit() {
+	if a.denyReadOnly("Undo") {
+		return
+	}
+	action, err := a.undoStack.Undo()
+	if err != nil {
+		if action.Description == "" {
+			a.showToast("[yellow]" + err.Error() + "[white]")
+			return
+		}
+		a.handleError(err, "Undo "+action.Description)
+		return
+	}
+	a.afterUndo()
+	a.showToast("[green]↶ Undid: " + action.Description + "[white]")
+}
+
+// redoEdit makes the most recently undone edit again
+func (a *App) redoEdit() {
+	if a.denyReadOnly("Redo") {
+		return
+	}
+	action, err := a.undoStack.Redo()
+	if err != nil {
+		if action.Description == "" {
+			a.showToast("[yellow]" + err.Error() + "[white]")
+			return
+		}
+		a.handleError(err, "Redo "+action.Description)
+		return
+	}
+	a.afterUndo()
+	a.showToast("[green]↷ Redid: " + action.Description + "[white]")
+}
+
+// afterUndo picks up files changed by an undo or redo: lyrics that appeared
+// or went away, and the current song's lyrics on screen
+func (a *App) afterUndo() {
+	for i := range a.songs {
+		a.songs[i].LyricsPath = a.findLyricsFile(a.songs[i].Path, a.songs[i].Title, a.songs[i].Artist)
+	}
+	if a.currentSong >= 0 && a.currentSong < len(a.songs) {
+		if path := a.songs[a.currentSong].LyricsPath; path != "" {
+			a.loadLyricsFromFile(path)
+		} else {
+			track := lyrics.New()
+			track.Lines = append(track.Lines, lyrics.LyricLine{Time: 0, Text: "No lyrics available"})
+			a.setLyrics(track)
+		}
+	}
+	a.updateAllDisplays()
+}
+
+// editFile runs edit, which writes the file at path, and records it so it
+// can be undone
+func (a *App) editFile(description, path string, edit func() error) error {
+	action, err := undo.FileEdit(description, path, edit)
+	if err != nil {
+		return err
+	}
+	a.undoStack.Push(action)
+	return nil
+}
+
+// recordMove records that a song's files moved from before to after, by a
+// rename or a move, so it can be undone
+func (a *App) recordMove(description string, before, after Song) {
+	a.undoStack.Push(undo.Action{
+		Description: description,
+		Undo: func() error {
+			return a.moveSongFiles(after, before)
+		},
+		Redo: func() error {
+			return a.moveSongFiles(before, after)
+		},
+	})
+}
+
+// moveSongFiles moves a song's audio file, and its lyrics when they moved
+// with it, and updates the library to match. Across drives it copies without
+// progress, as undo waits for it.
+func (a *App) moveSongFiles(from, to Song) error {
+	if err := organize.MoveFile(from.Path, to.Path); err != nil {
+		return err
+	}
+	if from.LyricsPath != "" && to.LyricsPath != from.LyricsPath {
+		if err := organize.MoveFile(from.LyricsPath, to.LyricsPath); err != nil {
+			return err
+		}
+	}
+
+	a.added.Rename(from.Path, to.Path)
+	for i := range a.songs {
+		if a.songs[i].Path == from.Path {
+			a.songs[i].Path = to.Path
+			a.songs[i].LyricsPath = to.LyricsPath
+		}
+	}
+	return nil
+}
+
+// recordPlaylistAdd records that song was added to the library list and,
+// when one is loaded, the current playlist, so it can be undone
+func (a *App) recordPlaylistAdd(song Song, playlistName string) {
+	a.undoStack.Push(undo.Action{
+		Description: fmt.Sprintf("add \"%s\"", song.Title),
+		Undo: func() error {
+			a.removeSongFromList(song.Path)
+			if playlistName != "" {
+				return a.playlistManager.RemoveSongFromPlaylist(playlistName, song.Path)
+			}
+			return nil
+		},
+		Redo: func() error {
+			a.songs = append(a.songs, song)
+			if playlistName != "" {
+				return a.playlistManager.AddSongToPlaylist(playlistName, song.Path)
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/update"
+)
+
+// version is the release this binary was built from, set by the release
+// build with -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+// updateCheckTimeout is how long the check at start-up may take
+const updateCheckTimeout = 15 * time.Second
+
+// startUpdateCheck looks for a new release in the background when update
+// checks are turned on, and mentions it in the status bar
+func (a *App) startUpdateCheck() {
+	if !a.appConfig.UpdateCheck {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+		defer cancel()
+		release, err := update.NewChecker().Latest(ctx)
+		if err != nil {
+			a.errorLog.Add("Update Check", err)
+			return
+		}
+		if !update.Newer(version, release.Tag) {
+			return
+		}
+		a.app.QueueUpdateDraw(func() {
+			a.latestRelease = release
+			a.showToast(fmt.Sprintf("[green]🆕 Tuneminal %s is out - Shift+I for what's new[white]", release.Tag))
+		})
+	}()
+}
+
+// showUpdate shows the release notes of the newest release, checking for
+// it first if that hasn't been done. d downloads and installs it.
+func (a *App) showUpdate() {
+	if a.latestRelease == nil {
+		a.showToast("🔎 Checking for updates...")
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+			defer cancel()
+			release, err := update.NewChecker().Latest(ctx)
+			a.app.QueueUpdateDraw(func() {
+				if err != nil {
+					a.handleError(err, "Update Check")
+					return
+				}
+				a.latestRelease = release
+				a.showUpdate()
+			})
+		}()
+		return
+	}
+
+	release := a.latestRelease
+	newer := update.Newer(version, release.Tag)
+	title := fmt.Sprintf(" Tuneminal %s - Esc close ", release.Tag)
+	if newer {
+		title = fmt.Sprintf(" Tuneminal %s - d download and install, Esc close ", release.Tag)
+	}
+
+	var content strings.Builder
+	if newer {
+		content.WriteString(fmt.Sprintf("[green]%s is out; this is %s.[white]\n\n", release.Tag, version))
+	} else {
+		content.WriteString(fmt.Sprintf("[green]This is the latest release (%s).[white]\n\n", version))
+	}
+	if release.Name != "" && release.Name != release.Tag {
+		content.WriteString("[yellow]" + tview.Escape(release.Name) + "[white]\n\n")
+	}
+	notes := strings.TrimSpace(release.Notes)
+	if notes == "" {
+		notes = "No release notes."
+	}
+	content.WriteString(tview.Escape(notes) + "\n\n[gray]" + release.URL + "[white]")
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWordWrap(true).
+		SetText(content.String())
+	view.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignCenter)
+
+	closeView := func() {
+		a.pages.RemovePage("update")
+		a.app.SetFocus(a.songList)
+	}
+	view.SetDoneFunc(func(key tcell.Key) {
+		closeView()
+	})
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'd' && newer {
+			closeView()
+			a.installUpdate(release)
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("update", centered(view, 76, 26), true, true)
+	a.app.SetFocus(view)
+}
+
+// installUpdate replaces the running binary with the release's build for
+// this platform, to be used from the next start
+func (a *App) installUpdate(release *update.Release) {
+	if a.denyReadOnly("Installing updates") {
+		return
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		a.handleError(err, "Install Update")
+		return
+	}
+
+	a.showToast(fmt.Sprintf("⬇ Downloading Tuneminal %s...", release.Tag))
+	go func() {
+		err := update.NewChecker().Install(context.Background(), release, executable)
+		a.app.QueueUpdateDraw(func() {
+			if err != nil {
+				a.handleError(err, "Install Update")
+				return
+			}
+			a.showMessage(fmt.Sprintf("🆕 Installed Tuneminal %s. Restart Tuneminal to use it.", release.Tag))
+		})
+	}()
+}
+
+// runUpdateCommand shows whether there is a newer release and its notes, and
+// with -apply installs it in place of this binary
+func runUpdateCommand(args []string, readOnly bool) error {
+	flags := flag.NewFlagSet("update", flag.ContinueOnError)
+	apply := flags.Bool("apply", false, "download and install the new release")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("usage: tuneminal update [-apply]")
+	}
+
+	checker := update.NewChecker()
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	release, err := checker.Latest(ctx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to check for updates: %w", err)
+	}
+	if !update.Newer(version, release.Tag) {
+		fmt.Printf("Tuneminal %s is up to date (latest release %s).\n", version, release.Tag)
+		return nil
+	}
+
+	fmt.Printf("Tuneminal %s is out; this is %s.\n\n%s\n\n%s\n", release.Tag, version, strings.TrimSpace(release.Notes), release.URL)
+	if !*apply {
+		fmt.Println("\nRun tuneminal update -apply to install it.")
+		return nil
+	}
+	if readOnly {
+		return errReadOnly
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the tuneminal executable: %w", err)
+	}
+	fmt.Printf("\nDownloading %s...\n", release.Tag)
+	if err := checker.Install(context.Background(), release, executable); err != nil {
+		return err
+	}
+	fmt.Printf("Installed Tuneminal %s in %s.\n", release.Tag, executable)
+	return nil
+}
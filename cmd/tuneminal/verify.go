@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/integrity"
+	"github.com/tuneminal/tuneminal/pkg/jobs"
+)
+
+// formatVerifyResult renders an integrity check result; colors adds tview color tags
+func formatVerifyResult(result *integrity.Result, colors bool) string {
+	if len(result.Problems) == 0 {
+		if colors {
+			return fmt.Sprintf("[green]✅ All %d files passed[white]\n", result.Checked)
+		}
+		return fmt.Sprintf("All %d files passed\n", result.Checked)
+	}
+
+	var content strings.Builder
+	if colors {
+		content.WriteString(fmt.Sprintf("[red]%d of %d files have problems[white]\n\n", len(result.Problems), result.Checked))
+	} else {
+		content.WriteString(fmt.Sprintf("%d of %d files have problems\n\n", len(result.Problems), result.Checked))
+	}
+	for _, problem := range result.Problems {
+		if colors {
+			content.WriteString(fmt.Sprintf("[yellow]%-10s[white] %s\n           [gray]%s[white]\n", problem.Kind, problem.Path, problem.Detail))
+		} else {
+			content.WriteString(fmt.Sprintf("%-10s %s\n           %s\n", problem.Kind, problem.Path, problem.Detail))
+		}
+	}
+	return content.String()
+}
+
+// verifyLibrary checks every library file in a background job and shows the
+// problems found
+func (a *App) verifyLibrary() {
+	if a.verifyJob != nil {
+		a.showToast("[yellow]Library check already running[white]")
+		return
+	}
+
+	files, err := libraryFiles()
+	if err != nil {
+		a.handleError(err, "Verify Library")
+		return
+	}
+
+	a.verifyJob = a.jobs.Start("Checking library", func(ctx context.Context, job *jobs.Job) {
+		store := integrity.NewStore()
+		result := integrity.Verify(ctx, files, store, func(done, total int, path string) {
+			job.SetProgress(done, total, filepath.Base(path))
+		})
+		// What was checked before a cancel is still worth keeping
+		saveErr := store.Save()
+
+		a.app.QueueUpdateDraw(func() {
+			a.verifyJob = nil
+			switch {
+			case saveErr != nil:
+				a.handleError(saveErr, "Save Checksums")
+			case ctx.Err() != nil:
+				a.showToast("[yellow]Library check cancelled[white]")
+			default:
+				a.showVerifyResult(result)
+			}
+		})
+	})
+}
+
+// showVerifyResult shows the problem files from a library check
+func (a *App) showVerifyResult(result *integrity.Result) {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(formatVerifyResult(result, true))
+	view.SetBorder(true).
+		SetTitle(" Library Check - Esc to close ").
+		SetTitleAlign(tview.AlignCenter)
+	view.SetDoneFunc(func(key tcell.Key) {
+		a.pages.RemovePage("verify-result")
+		a.app.SetFocus(a.songList)
+	})
+
+	a.pages.AddPage("verify-result", centered(view, 90, 24), true, true)
+	a.app.SetFocus(view)
+}
+
+// runVerifyCommand handles "tuneminal verify"; it exits non-zero when problems are found
+func runVerifyCommand(args []string) error {
+	files, err := libraryFiles()
+	if err != nil {
+		return err
+	}
+
+	store := integrity.NewStore()
+	result := integrity.Verify(context.Background(), files, store, func(done, total int, path string) {
+		if path != "" {
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", done+1, total, path)
+		}
+	})
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	fmt.Print(formatVerifyResult(result, false))
+	if len(result.Problems) > 0 {
+		return fmt.Errorf("%d problem file(s) found", len(result.Problems))
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// visualizerState is what the visualizer panel is showing
+type visualizerState int
+
+const (
+	visualizerIdle    visualizerState = iota // nothing playing: a slow wave
+	visualizerPaused                         // the bars from the pause, pulsing
+	visualizerPlaying                        // the live spectrum
+	visualizerOff                            // turned off to save CPU
+)
+
+// Timings of the idle and paused animations
+const (
+	visualizerAnimationTick = 250 * time.Millisecond
+	visualizerWavePeriod    = 4 * time.Second
+	visualizerPulsePeriod   = 2 * time.Second
+)
+
+// currentVisualizerState works out which state the visualizer should be in
+// from the playback state and the config
+func (a *App) currentVisualizerState() visualizerState {
+	switch {
+	case !a.appConfig.Visualizer:
+		return visualizerOff
+	case a.isPaused:
+		return visualizerPaused
+	case a.isPlaying:
+		return visualizerPlaying
+	default:
+		return visualizerIdle
+	}
+}
+
+// setVisualizerState moves the visualizer to state. Pausing keeps the bars
+// as they were so the pulse starts from the frame the song stopped on.
+func (a *App) setVisualizerState(state visualizerState) {
+	if state == a.visualizerState {
+		return
+	}
+	if state == visualizerPaused {
+		a.pausedBars = append(a.pausedBars[:0], a.visualizerBars...)
+	}
+	a.visualizerState = state
+	a.visualizerSince = time.Now()
+}
+
+// updateVisualizer draws the visualizer for the state it is in
+func (a *App) updateVisualizer() {
+	a.setVisualizerState(a.currentVisualizerState())
+	elapsed := time.Since(a.visualizerSince)
+
+	switch a.visualizerState {
+	case visualizerOff:
+		a.visualizer.SetText("\n[gray]Visualizer off - Shift+X turns it on[white]")
+	case visualizerIdle:
+		a.idleVisualizerBars(elapsed)
+		a.visualizer.SetText(a.createVisualizerDisplay("♪ Audio Spectrum ♪", "[dim]∙ Waiting for a song ∙[white]"))
+	case visualizerPaused:
+		a.pausedVisualizerBars(elapsed)
+		a.visualizer.SetText(a.createVisualizerDisplay("⏸ Audio Spectrum ⏸", "[dim]∙ Paused ∙[white]"))
+	case visualizerPlaying:
+		a.generateVisualizerData()
+		beatIndicator := "♪"
+		if a.beatPhase%2 == 0 {
+			beatIndicator = "♫"
+		}
+		title := beatIndicator + " Live Audio Spectrum " + beatIndicator
+		a.visualizer.SetText(a.createVisualizerDisplay(title, a.getIntensityStatus(a.calculateVisualizerIntensity())))
+	}
+}
+
+// idleVisualizerBars rolls a low wave across the bars
+func (a *App) idleVisualizerBars(elapsed time.Duration) {
+	phase := 2 * math.Pi * elapsed.Seconds() / visualizerWavePeriod.Seconds()
+	for i := range a.visualizerBars {
+		a.visualizerBars[i] = 1 + int(math.Round(1.5+1.5*math.Sin(phase-float64(i)*0.5)))
+	}
+}
+
+// pausedVisualizerBars lets the bars from the pause breathe between half and
+// their full height
+func (a *App) pausedVisualizerBars(elapsed time.Duration) {
+	phase := 2 * math.Pi * elapsed.Seconds() / visualizerPulsePeriod.Seconds()
+	scale := 0.75 + 0.25*math.Cos(phase)
+	for i := range a.visualizerBars {
+		if i < len(a.pausedBars) {
+			a.visualizerBars[i] = max(1, int(math.Round(float64(a.pausedBars[i])*scale)))
+		}
+	}
+}
+
+// startVisualizerAnimation redraws the idle and paused animations, which
+// have no playback ticks to drive them. Nothing is drawn while the
+// visualizer is off or its panel hidden.
+func (a *App) startVisualizerAnimation() {
+	go func() {
+		ticker := time.NewTicker(visualizerAnimationTick)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if a.isPlaying || !a.appConfig.Visualizer || !a.panelVisible(panelVisualizer) {
+				continue
+			}
+			a.app.QueueUpdateDraw(func() {
+				if !a.isPlaying {
+					a.updateVisualizer()
+				}
+			})
+		}
+	}()
+}
+
+// toggleVisualizer turns the visualizer on or off and remembers it
+func (a *App) toggleVisualizer() {
+	a.appConfig.Visualizer = !a.appConfig.Visualizer
+	a.saveConfig()
+	a.updateVisualizer()
+
+	if a.appConfig.Visualizer {
+		a.showToast("📊 Visualizer on")
+	} else {
+		a.showToast("📊 Visualizer off")
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+	"github.com/tuneminal/tuneminal/pkg/organize"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+	"github.com/tuneminal/tuneminal/pkg/watch"
+)
+
+// startWatching (re)starts watching the configured folders for new audio files
+func (a *App) startWatching() {
+	if a.watcher != nil {
+		a.watcher.Stop()
+		a.watcher = nil
+	}
+	if len(a.appConfig.WatchFolders) == 0 || a.readOnly {
+		return
+	}
+
+	a.watcher = watch.NewWatcher(a.appConfig.WatchFolders, 3*time.Second)
+	template, move := a.appConfig.ImportTemplate, a.appConfig.WatchMove
+	a.watcher.OnNewFile = func(path string) {
+		a.importWatchedFile(path, template, move)
+	}
+	a.watcher.Start()
+}
+
+// importWatchedFile brings a file from a watch folder into the library.
+// It runs on the watcher goroutine.
+func (a *App) importWatchedFile(path, template string, move bool) {
+	imported, err := organize.ImportFile(path, homeRoot(), template, move)
+	if err != nil {
+		a.app.QueueUpdateDraw(func() {
+			a.showToast(fmt.Sprintf("[red]❌ Auto-import failed: %v[white]", err))
+		})
+		return
+	}
+
+	a.added.Record([]string{imported})
+	meta, err := metadata.GetRealMetadata(imported)
+	a.app.QueueUpdateDraw(func() {
+		if err == nil && a.currentPlaylist == "" {
+			a.songs = append(a.songs, Song{
+				Title:      meta.Title,
+				Artist:     meta.Artist,
+				Album:      meta.Album,
+				Path:       meta.Path,
+				LyricsPath: a.findLyricsFile(meta.Path, meta.Title, meta.Artist),
+				Duration:   meta.Duration,
+				Chapters:   meta.Chapters,
+			})
+			a.updateSongList()
+		}
+
+		rel, _ := filepath.Rel(homeRoot(), imported)
+		a.showToast(fmt.Sprintf("[green]📥 Imported %s → %s[white]", filepath.Base(path), rel))
+	})
+}
+
+// showToast shows a short-lived message in the status bar
+func (a *App) showToast(message string) {
+	a.statusBar.SetText(message)
+
+	go func() {
+		time.Sleep(4 * time.Second)
+		a.app.QueueUpdateDraw(func() {
+			// Only clear if nothing else replaced the message
+			if a.statusBar.GetText(false) == message {
+				a.updateStatus()
+			}
+		})
+	}()
+}
+
+// showWatchSettings edits the watch folders and import rules
+func (a *App) showWatchSettings() {
+	if a.denyReadOnlyMedia(homeRoot(), "Watch folder import") {
+		return
+	}
+	folders := tview.NewInputField().
+		SetLabel("Watch folders (comma separated)").
+		SetText(strings.Join(a.appConfig.WatchFolders, ", ")).
+		SetFieldWidth(40)
+	action := tview.NewDropDown().
+		SetLabel("New files are").
+		SetOptions([]string{"copied", "moved"}, nil)
+	if a.appConfig.WatchMove {
+		action.SetCurrentOption(1)
+	} else {
+		action.SetCurrentOption(0)
+	}
+	template := tview.NewInputField().
+		SetLabel("Naming template").
+		SetText(a.appConfig.ImportTemplate).
+		SetFieldWidth(40)
+
+	closeSettings := func() {
+		a.pages.RemovePage("watch-settings")
+		a.app.SetFocus(a.songList)
+	}
+
+	form := tview.NewForm().
+		AddFormItem(folders).
+		AddFormItem(action).
+		AddFormItem(template).
+		AddButton("Save", func() {
+			var watchFolders []string
+			for _, folder := range strings.Split(folders.GetText(), ",") {
+				if folder = strings.TrimSpace(folder); folder != "" {
+					watchFolders = append(watchFolders, utils.ExpandHome(folder))
+				}
+			}
+			if !strings.Contains(template.GetText(), "{title}") && !strings.Contains(template.GetText(), "{filename}") {
+				a.showWarning("The naming template needs {title} or {filename}")
+				return
+			}
+
+			index, _ := action.GetCurrentOption()
+			a.appConfig.WatchFolders = watchFolders
+			a.appConfig.WatchMove = index == 1
+			a.appConfig.ImportTemplate = template.GetText()
+			a.saveConfig()
+			a.startWatching()
+
+			closeSettings()
+			a.showToast(fmt.Sprintf("[green]👀 Watching %d folder(s)[white]", len(watchFolders)))
+		}).
+		AddButton("Cancel", closeSettings)
+	form.SetCancelFunc(closeSettings)
+
+	form.SetTitle(" Watch Folders - fields: {artist} {album} {title} {filename} {ext} ").SetBorder(true)
+	a.pages.AddPage("watch-settings", centered(form, 80, 13), true, true)
+	a.app.SetFocus(form)
+}
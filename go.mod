@@ -3,13 +3,13 @@ module github.com/tuneminal/tuneminal
 go 1.24.0
 
 require (
+	github.com/ebitengine/oto/v3 v3.3.3
 	github.com/faiface/beep v1.1.0
 	github.com/gdamore/tcell/v2 v2.6.1-0.20231203215052-2917c3801e73
 	github.com/rivo/tview v0.0.0-20240101144852-b3bd1aa5e9f2
 )
 
 require (
-	github.com/ebitengine/oto/v3 v3.3.3 // indirect
 	github.com/ebitengine/purego v0.8.4 // indirect
 	github.com/gdamore/encoding v1.0.0 // indirect
 	github.com/hajimehoshi/go-mp3 v0.3.0 // indirect
@@ -0,0 +1,143 @@
+// Package added remembers when each song first showed up in the library, so
+// new downloads can be listed without searching for them
+package added
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/paths"
+)
+
+// Song is a song in the library and when it was added
+type Song struct {
+	Path  string
+	Added time.Time
+}
+
+// Store is the added-time database, keyed by song path
+type Store struct {
+	path    string
+	mutex   sync.Mutex
+	entries map[string]time.Time
+}
+
+// NewStore creates a store backed by added.json in the data directory
+func NewStore() *Store {
+	store := &Store{
+		path:    paths.Data("added.json"),
+		entries: make(map[string]time.Time),
+	}
+	store.load()
+	return store
+}
+
+// load reads the database, starting empty if the file is missing or invalid
+func (s *Store) load() {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return
+	}
+	json.Unmarshal(data, &s.entries)
+}
+
+// save writes the database to disk (caller must hold the mutex)
+func (s *Store) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// Record notes the songs in the library that haven't been seen before. New
+// songs are dated now, except on the first run, when the file modification
+// time stands in so an existing library doesn't all look new.
+func (s *Store) Record(songs []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	firstRun := len(s.entries) == 0
+	now := time.Now()
+	changed := false
+	for _, path := range songs {
+		if _, ok := s.entries[path]; ok {
+			continue
+		}
+		added := now
+		if info, err := os.Stat(path); err == nil && firstRun {
+			added = info.ModTime()
+		}
+		s.entries[path] = added
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return s.save()
+}
+
+// Added returns when a song was added to the library
+func (s *Store) Added(path string) (time.Time, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	added, ok := s.entries[path]
+	return added, ok
+}
+
+// Recent returns the songs among paths added since the given time, newest
+// first
+func (s *Store) Recent(paths []string, since time.Time) []Song {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var songs []Song
+	for _, path := range paths {
+		if added, ok := s.entries[path]; ok && !added.Before(since) {
+			songs = append(songs, Song{Path: path, Added: added})
+		}
+	}
+	sort.SliceStable(songs, func(i, j int) bool {
+		return songs[i].Added.After(songs[j].Added)
+	})
+	return songs
+}
+
+// Rename moves a song's added time to its new path
+func (s *Store) Rename(oldPath, newPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	added, ok := s.entries[oldPath]
+	if !ok {
+		return nil
+	}
+	delete(s.entries, oldPath)
+	s.entries[newPath] = added
+	return s.save()
+}
+
+// Group names the stretch of time a song added at the given time falls in,
+// for headings in a list sorted by date
+func Group(added, now time.Time) string {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case !added.Before(today):
+		return "Today"
+	case !added.Before(today.AddDate(0, 0, -1)):
+		return "Yesterday"
+	case !added.Before(today.AddDate(0, 0, -6)):
+		return added.Format("Monday")
+	case added.Year() == now.Year():
+		return added.Format("January")
+	default:
+		return added.Format("January 2006")
+	}
+}
@@ -0,0 +1,66 @@
+package added
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordAndRecent(t *testing.T) {
+	dir := t.TempDir()
+	store := &Store{path: filepath.Join(dir, "added.json"), entries: make(map[string]time.Time)}
+
+	old := filepath.Join(dir, "old.mp3")
+	os.WriteFile(old, nil, 0644)
+	lastYear := time.Now().AddDate(-1, 0, 0)
+	os.Chtimes(old, lastYear, lastYear)
+
+	// The first run dates existing songs by their files
+	if err := store.Record([]string{old}); err != nil {
+		t.Fatal(err)
+	}
+	if added, _ := store.Added(old); !added.Equal(lastYear) {
+		t.Errorf("Expected the first run to use the file time, got %v", added)
+	}
+
+	// Songs found later are dated when they were found
+	fresh := filepath.Join(dir, "fresh.mp3")
+	os.WriteFile(fresh, nil, 0644)
+	os.Chtimes(fresh, lastYear, lastYear)
+	store.Record([]string{old, fresh})
+
+	recent := store.Recent([]string{old, fresh}, time.Now().AddDate(0, 0, -30))
+	if len(recent) != 1 || recent[0].Path != fresh {
+		t.Fatalf("Expected only the new song to be recent, got %v", recent)
+	}
+
+	// The store is saved and survives a rename
+	if err := store.Rename(fresh, fresh+".moved"); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := &Store{path: store.path, entries: make(map[string]time.Time)}
+	reloaded.load()
+	if _, ok := reloaded.Added(fresh + ".moved"); !ok {
+		t.Error("Expected the renamed song to be saved")
+	}
+}
+
+func TestGroup(t *testing.T) {
+	now := time.Date(2024, time.March, 14, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		added time.Time
+		want  string
+	}{
+		{now.Add(-time.Hour), "Today"},
+		{time.Date(2024, time.March, 13, 23, 0, 0, 0, time.UTC), "Yesterday"},
+		{time.Date(2024, time.March, 9, 12, 0, 0, 0, time.UTC), "Saturday"},
+		{time.Date(2024, time.February, 2, 12, 0, 0, 0, time.UTC), "February"},
+		{time.Date(2023, time.December, 30, 12, 0, 0, 0, time.UTC), "December 2023"},
+	}
+	for _, test := range tests {
+		if got := Group(test.added, now); got != test.want {
+			t.Errorf("Group(%v) = %q, want %q", test.added, got, test.want)
+		}
+	}
+}
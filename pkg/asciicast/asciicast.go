@@ -0,0 +1,234 @@
+// Package asciicast records a tcell screen as an asciicast v2 file, which can
+// be played back with asciinema or converted to a GIF with tools such as agg
+package asciicast
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// header is the first line of an asciicast v2 file
+type header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// cell is what the recorder remembers about one screen position
+type cell struct {
+	text  string
+	style tcell.Style
+	width int
+}
+
+// Recorder writes screen frames to an asciicast file. Only the cells that
+// changed since the previous frame are written.
+type Recorder struct {
+	path   string
+	title  string
+	file   *os.File
+	out    *bufio.Writer
+	start  time.Time
+	width  int
+	height int
+	screen [][]cell
+}
+
+// Create starts a recording at path; the header is written with the screen
+// size on the first capture
+func Create(path, title string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{
+		path:  path,
+		title: title,
+		file:  file,
+		out:   bufio.NewWriter(file),
+		start: time.Now(),
+	}, nil
+}
+
+// writeHeader writes the first line of the file
+func (r *Recorder) writeHeader() error {
+	line, err := json.Marshal(header{
+		Version:   2,
+		Width:     r.width,
+		Height:    r.height,
+		Timestamp: r.start.Unix(),
+		Title:     r.title,
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.out.Write(append(line, '\n'))
+	return err
+}
+
+// Path returns the file being written
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Elapsed returns how long the recording has been running
+func (r *Recorder) Elapsed() time.Duration {
+	return time.Since(r.start)
+}
+
+// Capture records the current contents of screen
+func (r *Recorder) Capture(screen tcell.Screen) error {
+	width, height := screen.Size()
+	switch {
+	case r.screen == nil:
+		r.width, r.height = width, height
+		if err := r.writeHeader(); err != nil {
+			return err
+		}
+	case width != r.width || height != r.height:
+		if err := r.event("r", fmt.Sprintf("%dx%d", width, height)); err != nil {
+			return err
+		}
+		r.width, r.height = width, height
+		r.screen = nil
+	}
+
+	current := make([][]cell, height)
+	for y := range current {
+		current[y] = make([]cell, width)
+		for x := range current[y] {
+			mainc, combc, style, cellWidth := screen.GetContent(x, y)
+			if mainc == 0 {
+				mainc = ' '
+			}
+			current[y][x] = cell{text: string(mainc) + string(combc), style: style, width: cellWidth}
+		}
+	}
+
+	frame := render(r.screen, current)
+	r.screen = current
+	if frame == "" {
+		return nil
+	}
+	return r.event("o", frame)
+}
+
+// event appends one timed event to the file
+func (r *Recorder) event(kind, data string) error {
+	line, err := json.Marshal([]interface{}{time.Since(r.start).Seconds(), kind, data})
+	if err != nil {
+		return err
+	}
+	_, err = r.out.Write(append(line, '\n'))
+	return err
+}
+
+// Close finishes the recording
+func (r *Recorder) Close() error {
+	if err := r.out.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// render returns the escape sequences that turn previous into current; a nil
+// previous redraws everything
+func render(previous, current [][]cell) string {
+	var out strings.Builder
+	if previous == nil {
+		out.WriteString("\x1b[0m\x1b[2J")
+	}
+
+	var style tcell.Style
+	styled := false
+	for y, row := range current {
+		cursor := -1 // column the terminal cursor is at, -1 when unknown
+		for x := 0; x < len(row); x++ {
+			c := row[x]
+			if previous != nil && c == previous[y][x] {
+				continue
+			}
+
+			if cursor != x {
+				fmt.Fprintf(&out, "\x1b[%d;%dH", y+1, x+1)
+			}
+			if !styled || c.style != style {
+				out.WriteString(sgr(c.style))
+				style, styled = c.style, true
+			}
+			out.WriteString(c.text)
+
+			cursor = x + 1
+			if c.width > 1 {
+				// The following cell is covered by this wide character
+				x += c.width - 1
+				cursor = x + 1
+			}
+		}
+	}
+
+	if out.Len() == 0 {
+		return ""
+	}
+	return out.String()
+}
+
+// sgr returns the escape sequence selecting a style
+func sgr(style tcell.Style) string {
+	fg, bg, attrs := style.Decompose()
+
+	params := []string{"0"}
+	for _, attr := range []struct {
+		mask tcell.AttrMask
+		code string
+	}{
+		{tcell.AttrBold, "1"},
+		{tcell.AttrDim, "2"},
+		{tcell.AttrItalic, "3"},
+		{tcell.AttrUnderline, "4"},
+		{tcell.AttrBlink, "5"},
+		{tcell.AttrReverse, "7"},
+		{tcell.AttrStrikeThrough, "9"},
+	} {
+		if attrs&attr.mask != 0 {
+			params = append(params, attr.code)
+		}
+	}
+	if code := colorCode(fg, 38); code != "" {
+		params = append(params, code)
+	}
+	if code := colorCode(bg, 48); code != "" {
+		params = append(params, code)
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
+
+// colorCode returns the SGR parameters for a color, base being 38 for the
+// foreground and 48 for the background
+func colorCode(color tcell.Color, base int) string {
+	switch {
+	case !color.Valid():
+		return ""
+	case color.IsRGB():
+		r, g, b := color.RGB()
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, r, g, b)
+	case color-tcell.ColorValid < 256:
+		return fmt.Sprintf("%d;5;%d", base, color-tcell.ColorValid)
+	}
+	if r, g, b := color.RGB(); r >= 0 {
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, r, g, b)
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package asciicast
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRecorder(t *testing.T) {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(20, 4)
+
+	path := filepath.Join(t.TempDir(), "run.cast")
+	recorder, err := Create(path, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	screen.SetContent(0, 0, 'A', nil, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+	screen.Show()
+	if err := recorder.Capture(screen); err != nil {
+		t.Fatal(err)
+	}
+	// An unchanged screen adds nothing
+	if err := recorder.Capture(screen); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetContent(5, 2, 'B', nil, tcell.StyleDefault)
+	screen.Show()
+	if err := recorder.Capture(screen); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 frames, got %d lines", len(lines))
+	}
+
+	var h header
+	if err := json.Unmarshal([]byte(lines[0]), &h); err != nil || h.Version != 2 || h.Width != 20 || h.Height != 4 {
+		t.Errorf("Bad header %q: %v", lines[0], err)
+	}
+
+	var event []interface{}
+	if err := json.Unmarshal([]byte(lines[2]), &event); err != nil || len(event) != 3 || event[1] != "o" {
+		t.Fatalf("Bad event %q: %v", lines[2], err)
+	}
+	if data := event[2].(string); !strings.Contains(data, "\x1b[3;6H") || !strings.HasSuffix(data, "B") {
+		t.Errorf("Expected only the changed cell to be redrawn, got %q", data)
+	}
+}
@@ -0,0 +1,205 @@
+// Package bigfont draws text in large block letters, so a lyric line can be
+// read from across a room on a TV showing a plain text console
+package bigfont
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Height is how many rows a line of big text takes
+const Height = 5
+
+// letterGap is the blank columns between letters
+const letterGap = 1
+
+// glyphs are the letters, '#' for a filled cell. Each has Height rows of the
+// same width.
+var glyphs = map[rune][Height]string{
+	'A':  {".##.", "#..#", "####", "#..#", "#..#"},
+	'B':  {"###.", "#..#", "###.", "#..#", "###."},
+	'C':  {".###", "#...", "#...", "#...", ".###"},
+	'D':  {"###.", "#..#", "#..#", "#..#", "###."},
+	'E':  {"####", "#...", "###.", "#...", "####"},
+	'F':  {"####", "#...", "###.", "#...", "#..."},
+	'G':  {".###", "#...", "#.##", "#..#", ".###"},
+	'H':  {"#..#", "#..#", "####", "#..#", "#..#"},
+	'I':  {"###", ".#.", ".#.", ".#.", "###"},
+	'J':  {"..##", "...#", "...#", "#..#", ".##."},
+	'K':  {"#..#", "#.#.", "##..", "#.#.", "#..#"},
+	'L':  {"#...", "#...", "#...", "#...", "####"},
+	'M':  {"#...#", "##.##", "#.#.#", "#...#", "#...#"},
+	'N':  {"#...#", "##..#", "#.#.#", "#..##", "#...#"},
+	'O':  {".##.", "#..#", "#..#", "#..#", ".##."},
+	'P':  {"###.", "#..#", "###.", "#...", "#..."},
+	'Q':  {".##.", "#..#", "#..#", "#.##", ".###"},
+	'R':  {"###.", "#..#", "###.", "#.#.", "#..#"},
+	'S':  {".###", "#...", ".##.", "...#", "###."},
+	'T':  {"#####", "..#..", "..#..", "..#..", "..#.."},
+	'U':  {"#..#", "#..#", "#..#", "#..#", ".##."},
+	'V':  {"#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W':  {"#...#", "#...#", "#.#.#", "##.##", "#...#"},
+	'X':  {"#...#", ".#.#.", "..#..", ".#.#.", "#...#"},
+	'Y':  {"#...#", ".#.#.", "..#..", "..#..", "..#.."},
+	'Z':  {"####", "...#", ".##.", "#...", "####"},
+	'0':  {".##.", "#.##", "##.#", "#..#", ".##."},
+	'1':  {".#.", "##.", ".#.", ".#.", "###"},
+	'2':  {"###.", "...#", ".##.", "#...", "####"},
+	'3':  {"###.", "...#", ".##.", "...#", "###."},
+	'4':  {"#..#", "#..#", "####", "...#", "...#"},
+	'5':  {"####", "#...", "###.", "...#", "###."},
+	'6':  {".##.", "#...", "###.", "#..#", ".##."},
+	'7':  {"####", "...#", "..#.", ".#..", ".#.."},
+	'8':  {".##.", "#..#", ".##.", "#..#", ".##."},
+	'9':  {".##.", "#..#", ".###", "...#", ".##."},
+	' ':  {"..", "..", "..", "..", ".."},
+	'.':  {".", ".", ".", ".", "#"},
+	',':  {"..", "..", "..", ".#", "#."},
+	'!':  {"#", "#", "#", ".", "#"},
+	'?':  {"###.", "...#", ".##.", "....", ".#.."},
+	'\'': {"#", "#", ".", ".", "."},
+	'"':  {"#.#", "#.#", "...", "...", "..."},
+	'-':  {"...", "...", "###", "...", "..."},
+	':':  {".", "#", ".", "#", "."},
+	';':  {"..", ".#", "..", ".#", "#."},
+	'(':  {".#", "#.", "#.", "#.", ".#"},
+	')':  {"#.", ".#", ".#", ".#", "#."},
+	'&':  {".##..", "#..#.", ".##.#", "#..#.", ".##.#"},
+	'/':  {"...#", "..#.", ".#..", "#...", "...."},
+}
+
+// lookalikes are drawn as the letter they resemble: accented Latin letters
+// and typographic punctuation
+var lookalikes = map[rune]rune{
+	'À': 'A', 'Á': 'A', 'Â': 'A', 'Ã': 'A', 'Ä': 'A', 'Å': 'A',
+	'Ç': 'C', 'È': 'E', 'É': 'E', 'Ê': 'E', 'Ë': 'E',
+	'Ì': 'I', 'Í': 'I', 'Î': 'I', 'Ï': 'I', 'Ñ': 'N',
+	'Ò': 'O', 'Ó': 'O', 'Ô': 'O', 'Õ': 'O', 'Ö': 'O', 'Ø': 'O',
+	'Ù': 'U', 'Ú': 'U', 'Û': 'U', 'Ü': 'U', 'Ý': 'Y', 'Ÿ': 'Y',
+	'‘': '\'', '’': '\'', '“': '"', '”': '"', '–': '-', '—': '-', '…': '.',
+}
+
+// glyph returns the letter drawn for r
+func glyph(r rune) ([Height]string, bool) {
+	r = unicode.ToUpper(r)
+	if alike, ok := lookalikes[r]; ok {
+		r = alike
+	}
+	if unicode.IsSpace(r) {
+		r = ' '
+	}
+	g, ok := glyphs[r]
+	return g, ok
+}
+
+// Supported reports whether every character of text can be drawn. Text in
+// other scripts should be shown as it is.
+func Supported(text string) bool {
+	for _, r := range text {
+		if _, ok := glyph(r); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Width returns how many columns text takes in big letters
+func Width(text string) int {
+	width := 0
+	for _, r := range text {
+		if g, ok := glyph(r); ok {
+			if width > 0 {
+				width += letterGap
+			}
+			width += len(g[0])
+		}
+	}
+	return width
+}
+
+// draw renders text on one band of Height rows, every row as wide as the text
+func draw(text string) []string {
+	rows := make([]strings.Builder, Height)
+	first := true
+	for _, r := range text {
+		g, ok := glyph(r)
+		if !ok {
+			continue
+		}
+		for row := range rows {
+			if !first {
+				rows[row].WriteString(strings.Repeat(" ", letterGap))
+			}
+			for _, cell := range g[row] {
+				if cell == '#' {
+					rows[row].WriteString("█")
+				} else {
+					rows[row].WriteByte(' ')
+				}
+			}
+		}
+		first = false
+	}
+
+	lines := make([]string, Height)
+	for row := range rows {
+		lines[row] = rows[row].String()
+	}
+	return lines
+}
+
+// Render draws text in big letters, wrapped between words to fit in width
+// columns. Each band of Height rows is followed by a blank row, except the
+// last. Characters that aren't Supported are left out.
+func Render(text string, width int) []string {
+	var rows []string
+	for i, band := range wrap(strings.Fields(text), width) {
+		if i > 0 {
+			rows = append(rows, "")
+		}
+		rows = append(rows, draw(band)...)
+	}
+	return rows
+}
+
+// wrap groups words into bands no wider than width, splitting words that
+// are too long on their own
+func wrap(words []string, width int) []string {
+	var bands []string
+	current := ""
+	for _, word := range words {
+		for Width(word) > width && width > 0 {
+			// Break an overlong word at the last letter that fits
+			cut := 0
+			for i := range word {
+				if i > 0 && Width(word[:i]) > width {
+					break
+				}
+				cut = i
+			}
+			if cut == 0 {
+				break
+			}
+			if current != "" {
+				bands = append(bands, current)
+				current = ""
+			}
+			bands = append(bands, word[:cut])
+			word = word[cut:]
+		}
+
+		switch {
+		case current == "":
+			current = word
+		case Width(current+" "+word) <= width:
+			current += " " + word
+		default:
+			bands = append(bands, current)
+			current = word
+		}
+	}
+	if current != "" {
+		bands = append(bands, current)
+	}
+	return bands
+}
@@ -0,0 +1,46 @@
+package bigfont
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGlyphs(t *testing.T) {
+	for r, g := range glyphs {
+		for row := range g {
+			if len(g[row]) != len(g[0]) {
+				t.Errorf("Glyph %q has rows of different widths", r)
+			}
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	rows := Render("Hi", 80)
+	want := []string{
+		"█  █ ███",
+		"█  █  █ ",
+		"████  █ ",
+		"█  █  █ ",
+		"█  █ ███",
+	}
+	if strings.Join(rows, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Render() =\n%s", strings.Join(rows, "\n"))
+	}
+
+	// Words wrap onto more bands when the line is too wide
+	rows = Render("Mamma mia, here I go again", 30)
+	if len(rows) <= Height || (len(rows)+1)%(Height+1) != 0 {
+		t.Fatalf("Expected several bands separated by blank rows, got %d rows", len(rows))
+	}
+	for _, row := range rows {
+		if width := utf8.RuneCountInString(row); width > 30 {
+			t.Errorf("Row is %d columns wide, want at most 30", width)
+		}
+	}
+
+	if !Supported("Déjà vu!") || Supported("夜に駆ける") {
+		t.Error("Supported() misjudged a line")
+	}
+}
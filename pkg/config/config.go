@@ -2,8 +2,14 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/paths"
+	"github.com/tuneminal/tuneminal/pkg/utils"
 )
 
 // Config represents the application configuration
@@ -12,17 +18,175 @@ type Config struct {
 	DefaultVolume float64 `json:"default_volume"`
 	ShuffleMode   bool    `json:"shuffle_mode"`
 	RepeatMode    bool    `json:"repeat_mode"`
+	RepeatOne     bool    `json:"repeat_one"` // repeat the current song, over repeat_mode
 
 	// UI settings
 	Theme string `json:"theme"`
 
+	// How the line being sung is drawn, for scripts and tastes the default
+	// capitals and notes don't suit
+	LyricsCase     string `json:"lyrics_case"`     // "upper", or "original" to keep the lyrics' own case
+	LyricsNotes    bool   `json:"lyrics_notes"`    // beat notes (♪ ♫) either side of the line
+	LyricsEmphasis string `json:"lyrics_emphasis"` // "bold", or "color" for color only
+	LyricsBidi     bool   `json:"lyrics_bidi"`     // reorder right-to-left lines; off for terminals that do it themselves
+
 	// Library settings
 	MusicDirectory string `json:"music_directory"`
 	AutoLoadLast   bool   `json:"auto_load_last"`
 
+	// Named libraries to switch between, each with its own folders; none
+	// means the one built-in library
+	Libraries     []Library `json:"libraries"`
+	ActiveLibrary string    `json:"active_library"` // name of the library in use, empty for the first
+
+	// Where lyrics are looked for, in order: "sidecar" (next to the song),
+	// "folder" (lyrics_folder), "embedded" (timed lyrics in the song's tags)
+	// and "cache" (fetched lyrics that couldn't be saved next to the song)
+	LyricSources  []string `json:"lyric_sources"`
+	LyricPatterns []string `json:"lyric_patterns"` // file names tried after the song's own, with {name}, {title} and {artist}
+	LyricsFolder  string   `json:"lyrics_folder"`  // central lyrics directory, empty for "lyrics" in the data directory
+	EmbedLyrics   string   `json:"embed_lyrics"`   // write saved lyrics into MP3 tags too: "ask", "always" or "never"
+
 	// Performance settings
 	BufferSize     int    `json:"buffer_size"`
 	SeekStep       int    `json:"seek_step"` // seconds
+	PreviousRestartSeconds int `json:"previous_restart_seconds"` // past this, previous restarts the song; 0 always goes back
+
+	// What happens when a playlist runs out: "stop", "repeat", "autodj"
+	// (random songs from the list) or "shutdown" (fade out and quit)
+	PlaylistEnd string `json:"playlist_end"`
+
+	// Main output settings
+	AudioOutput       string `json:"audio_output"`        // "default", "pipewire" or "jack" (Linux)
+	AudioOutputDevice string `json:"audio_output_device"` // PipeWire node or JACK ports, empty for the default
+	AudioLatencyMs    int    `json:"audio_latency_ms"`    // PipeWire latency, 0 for the server's default
+
+	// Monitor output settings (secondary device, e.g. singer headphones)
+	MonitorEnabled   bool    `json:"monitor_enabled"`
+	MonitorDevice    string  `json:"monitor_device"`     // ALSA playback device
+	MonitorMicDevice string  `json:"monitor_mic_device"` // ALSA capture device, empty for music only
+	MonitorMicLevel  float64 `json:"monitor_mic_level"`
+
+	// Microphone pass-through and effects; the mic is the monitor mic device
+	MicPassthrough bool    `json:"mic_passthrough"` // mix the mic into the main output
+	MicEffects     bool    `json:"mic_effects"`
+	MicAutoGain    bool    `json:"mic_auto_gain"` // even out the level; MicGain is used when off
+	MicGain        float64 `json:"mic_gain"`
+	MicEcho        float64 `json:"mic_echo"` // 0 for no echo
+	MicEchoDelayMs int     `json:"mic_echo_delay_ms"`
+	MicReverb      float64 `json:"mic_reverb"`
+
+	// Metronome settings
+	MetronomeEnabled     bool    `json:"metronome_enabled"`
+	MetronomeVolume      float64 `json:"metronome_volume"`
+	MetronomeBeatsPerBar int     `json:"metronome_beats_per_bar"` // accented every bar, 0 for none
+
+	// Equalizer gain in dB for each band from 31 Hz to 16 kHz, empty for flat
+	Equalizer []float64 `json:"equalizer"`
+
+	// Click track for lyrics with no recording
+	LyricsOnlyClick       bool    `json:"lyrics_only_click"`        // off to prompt alongside an outside sound system
+	ClickTrackBPM         float64 `json:"click_track_bpm"`          // when the song has no tempo of its own
+	ClickTrackCountInBars int     `json:"click_track_count_in_bars"` // bars of clicks before the first line
+	ClockSync             string  `json:"clock_sync"`                // raw MIDI input whose clock lyrics-only songs follow, e.g. /dev/snd/midiC1D0
+
+	// Soundboard of effects played over the music
+	Soundboard       []string `json:"soundboard"`        // files in the soundboard directory for pads 1-9, in order; empty for every file A-Z
+	SoundboardVolume float64  `json:"soundboard_volume"` // relative to the music
+
+	// Watch folder settings
+	WatchFolders   []string `json:"watch_folders"`   // folders checked for new audio files
+	WatchMove      bool     `json:"watch_move"`      // move instead of copy into the library
+	ImportTemplate string   `json:"import_template"` // e.g. "{artist}/{album}/{title}{ext}"
+
+	// LAN sharing settings
+	ShareAddress      string `json:"share_address"`       // host:port to serve the shared playlist on
+	OSCAddress        string `json:"osc_address"`         // UDP host:port for OSC control surfaces, e.g. :9000; empty for off
+	SkipVoteThreshold int    `json:"skip_vote_threshold"` // guest votes needed to skip a song, 0 to turn voting off
+	SkipVoteAuto      bool   `json:"skip_vote_auto"`      // skip as soon as enough votes are in, without asking
+
+	// Loudness normalization, using gains measured by "tuneminal gain-scan"
+	NormalizeVolume bool `json:"normalize_volume"`
+
+	// Cue before each lyric line, for singers who can't watch the screen
+	LineCue       string `json:"line_cue"`         // "off", "flash" (lyrics border), "bell" or "both"
+	LineCueLeadMs int    `json:"line_cue_lead_ms"` // how long before the line the cue comes
+
+	// Split the lyrics panel between singers for duet lyrics (P1:/P2: or M:/F: lines)
+	DuetLayout bool `json:"duet_layout"`
+
+	// Lyrics display style: "standard", or "big" for block letters readable
+	// across a room
+	LyricsStyle string `json:"lyrics_style"`
+
+	// Local copies of songs from network shares, so they only play over the network once
+	SongCache   string `json:"song_cache"`    // "off", "network" (NFS/SMB shares only) or "always"
+	SongCacheMB int    `json:"song_cache_mb"` // size limit, least recently played songs go first
+
+	// Read the next song into memory as soon as one starts, so slow disks and
+	// shares don't hold up the start of it. Songs bigger than this aren't read
+	// ahead, 0 turns it off.
+	PrefetchMB int `json:"prefetch_mb"`
+
+	// Decoded songs kept between plays, so replays start without decoding
+	DecodeCacheMB int `json:"decode_cache_mb"` // size limit, 0 turns it off
+
+	// Music folders on other machines, listed beside the local library and
+	// fetched when played: sftp://[user@]host/path or webdav[s]://[user:password@]host/path
+	RemoteLibraries []string `json:"remote_libraries"`
+
+	// Where exports go and how they're named, using {type}, {date}, {time}
+	// and {song}; empty directory means the data directory's exports folder
+	ExportDirectory    string `json:"export_directory"`
+	ExportNameTemplate string `json:"export_name_template"`
+
+	// Performance data exported on quit, so a session isn't lost if nobody
+	// exports it: "off", "json" or "csv"
+	AutoExport string `json:"auto_export"`
+
+	// Panels hidden to give the lyrics more room: "header", "search", "score" or "visualizer"
+	HiddenPanels []string `json:"hidden_panels"`
+
+	// Show a song's practice notes in the lyrics panel until its first line
+	SongNotes bool `json:"song_notes"`
+
+	// Animate the visualizer panel; off saves CPU on slow machines
+	Visualizer bool `json:"visualizer"`
+
+	// Read-only mode for shared machines: no file changes and no saved settings
+	ReadOnly bool `json:"read_only"`
+
+	// Party flow keeps the queue moving between singers: long intros are
+	// skipped to shortly before the first line, and songs move on after the
+	// last line or with the outro cut, after a countdown. 0 turns each off.
+	PartyFlow                   bool `json:"party_flow"`
+	PartyFlowIntroSeconds       int  `json:"party_flow_intro_seconds"`        // start this long before the first line
+	PartyFlowAfterLyricsSeconds int  `json:"party_flow_after_lyrics_seconds"` // move on this long after the last line
+	PartyFlowOutroSeconds       int  `json:"party_flow_outro_seconds"`        // cut this much off the end
+	PartyFlowCountdownSeconds   int  `json:"party_flow_countdown_seconds"`
+
+	// Kiosk mode for dedicated karaoke boxes: read-only, sharing the playlist
+	// for guest requests and playing KioskPlaylist (or the library) on start
+	Kiosk         bool   `json:"kiosk"`
+	KioskPlaylist string `json:"kiosk_playlist"`
+
+	// Count which features are used, kept on this machine for the insights
+	// panel and never sent anywhere
+	Insights bool `json:"insights"`
+
+	// Look for a new release on GitHub at start-up
+	UpdateCheck bool `json:"update_check"`
+
+	// Long-form audio settings
+	LongFormMinutes int `json:"long_form_minutes"` // tracks at least this long remember their position
+}
+
+// Library is a named set of folders the song list can be switched to, such
+// as one for karaoke and one for the kids
+type Library struct {
+	Name            string   `json:"name"`
+	Roots           []string `json:"roots"`                      // folders scanned for songs; imports go into the first
+	DefaultPlaylist string   `json:"default_playlist,omitempty"` // playlist loaded on switching to the library
 }
 
 // DefaultConfig returns the default configuration
@@ -34,10 +198,52 @@ func DefaultConfig() *Config {
 		ShuffleMode:    false,
 		RepeatMode:     false,
 		Theme:          "default",
+		LyricsCase:     "upper",
+		LyricsNotes:    true,
+		LyricsEmphasis: "bold",
+		LyricsBidi:     true,
 		MusicDirectory: filepath.Join(homeDir, "Music"),
 		AutoLoadLast:   true,
+		LyricSources:   []string{"sidecar", "folder", "embedded", "cache"},
+		LyricPatterns:  []string{"{artist} - {title}.lrc", "{title}.lrc"},
+		EmbedLyrics:    "ask",
 		BufferSize:     1024,
 		SeekStep:       10, // 10 seconds
+		PreviousRestartSeconds: 3,
+		PlaylistEnd:    "stop",
+		AudioOutput:     "default",
+		MonitorDevice:   "default",
+		MonitorMicLevel: 0.8,
+		MicAutoGain:     true,
+		MicGain:         1.0,
+		MicEchoDelayMs:  250,
+		MicReverb:       0.2,
+		MetronomeVolume:      0.5,
+		MetronomeBeatsPerBar: 4,
+		LyricsOnlyClick:       true,
+		ClickTrackBPM:         90,
+		SoundboardVolume:      0.8,
+		ClickTrackCountInBars: 1,
+		ImportTemplate:  "{artist}/{album}/{title}{ext}",
+		ShareAddress:    ":7777",
+		SkipVoteThreshold: 3,
+		LongFormMinutes: 20,
+		NormalizeVolume: true,
+		LineCue:         "off",
+		LineCueLeadMs:   1000,
+		DuetLayout:      true,
+		LyricsStyle:     "standard",
+		SongCache:       "off",
+		SongCacheMB:     2048,
+		PrefetchMB:      64,
+		DecodeCacheMB:   1024,
+		ExportNameTemplate: "{type}_{date}_{time}",
+		AutoExport:      "off",
+		Visualizer:      true,
+		SongNotes:       true,
+		PartyFlowIntroSeconds:       5,
+		PartyFlowAfterLyricsSeconds: 10,
+		PartyFlowCountdownSeconds:   5,
 	}
 }
 
@@ -58,14 +264,40 @@ func LoadConfig(configPath string) (*Config, error) {
 		return DefaultConfig(), err
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		// If config is corrupted, return default
-		defaultConfig := DefaultConfig()
-		return defaultConfig, nil
+	// Start from defaults so settings missing from older files keep sane values
+	config := DefaultConfig()
+	if err := json.Unmarshal(data, config); err != nil {
+		// A damaged config runs on the defaults, but the caller has to say
+		// what to do with the file rather than have it overwritten
+		return DefaultConfig(), fmt.Errorf("%w: %s: %v", ErrDamaged, configPath, err)
+	}
+
+	return config, nil
+}
+
+// ErrDamaged is returned by LoadConfig for a config file that can't be parsed
+var ErrDamaged = errors.New("config file is damaged")
+
+// ReadConfig reads the configuration from an existing file without creating
+// or resetting it, so a file that can't be read or parsed is an error
+func ReadConfig(configPath string) (*Config, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
 	}
 
-	return &config, nil
+	config := DefaultConfig()
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", configPath, err)
+	}
+	return config, nil
+}
+
+// PreviousRestarts reports whether previous, pressed position into the song
+// playing, goes back to the start of that song rather than to the one before
+func (c *Config) PreviousRestarts(position time.Duration) bool {
+	restart := time.Duration(c.PreviousRestartSeconds) * time.Second
+	return restart > 0 && position >= restart
 }
 
 // SaveConfig saves configuration to file
@@ -81,11 +313,52 @@ func (c *Config) SaveConfig(configPath string) error {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0644)
+	// Written in one step so a crash can't leave half a config, with the
+	// previous one kept to go back to
+	return utils.WriteFileWithBackup(configPath, data)
+}
+
+// RestoreBackup puts the config saved before the last one back in place
+// and returns it
+func RestoreBackup(configPath string) (*Config, error) {
+	config, err := ReadConfig(utils.BackupPath(configPath))
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(utils.BackupPath(configPath))
+	if err != nil {
+		return nil, err
+	}
+	return config, utils.WriteFileAtomic(configPath, data)
+}
+
+// SetAside moves a damaged config out of the way, to configPath.damaged,
+// so the defaults can be saved in its place without losing it
+func SetAside(configPath string) (string, error) {
+	damaged := configPath + ".damaged"
+	return damaged, os.Rename(configPath, damaged)
+}
+
+// Lockdown reports whether the config at configPath turns on read-only and
+// kiosk mode, without creating or changing it. When the config can't be
+// read, its backup's settings are used, and failing that read-only mode is
+// on: a machine an admin locked down mustn't come unlocked because its
+// config was damaged. A missing config turns neither on.
+func Lockdown(configPath string) (readOnly, kiosk bool) {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return false, false
+	}
+	config, err := ReadConfig(configPath)
+	if err != nil {
+		config, err = ReadConfig(utils.BackupPath(configPath))
+	}
+	if err != nil {
+		return true, false
+	}
+	return config.ReadOnly, config.Kiosk
 }
 
 // GetConfigPath returns the path to the config file
 func GetConfigPath() string {
-	homeDir, _ := os.UserHomeDir()
-	return filepath.Join(homeDir, ".tuneminal", "config.json")
+	return paths.Config("config.json")
 }
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+func TestLockdown(t *testing.T) {
+	tests := []struct {
+		name           string
+		config, backup string // "" for no file
+		readOnly       bool
+		kiosk          bool
+	}{
+		{name: "missing"},
+		{name: "default", config: `{"default_volume": 0.5}`},
+		{name: "read-only", config: `{"read_only": true}`, readOnly: true},
+		{name: "kiosk", config: `{"kiosk": true}`, kiosk: true},
+		{name: "damaged, locked backup", config: `{"read_only": tr`, backup: `{"read_only": true, "kiosk": true}`, readOnly: true, kiosk: true},
+		{name: "damaged, open backup", config: `{"read_only": tr`, backup: `{"read_only": false}`},
+		{name: "damaged, no backup", config: `{"read_only": tr`, readOnly: true},
+		{name: "damaged, damaged backup", config: `{`, backup: `{`, readOnly: true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if tt.config != "" {
+			os.WriteFile(path, []byte(tt.config), 0644)
+		}
+		if tt.backup != "" {
+			os.WriteFile(utils.BackupPath(path), []byte(tt.backup), 0644)
+		}
+
+		readOnly, kiosk := Lockdown(path)
+		if readOnly != tt.readOnly || kiosk != tt.kiosk {
+			t.Errorf("%s: got read-only %v, kiosk %v; want %v, %v", tt.name, readOnly, kiosk, tt.readOnly, tt.kiosk)
+		}
+		if data, _ := os.ReadFile(path); string(data) != tt.config {
+			t.Errorf("%s: the config was changed to %q", tt.name, data)
+		}
+	}
+}
+
+func TestPreviousRestarts(t *testing.T) {
+	tests := []struct {
+		seconds  int
+		position time.Duration
+		want     bool
+	}{
+		{3, 0, false},
+		{3, 2900 * time.Millisecond, false},
+		{3, 3 * time.Second, true},
+		{3, time.Hour, true},
+		{0, 0, false},
+		{0, time.Hour, false}, // 0 always goes back a song
+	}
+	for _, tt := range tests {
+		config := &Config{PreviousRestartSeconds: tt.seconds}
+		if got := config.PreviousRestarts(tt.position); got != tt.want {
+			t.Errorf("PreviousRestarts(%v) with %ds = %v, want %v", tt.position, tt.seconds, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package diagnostics
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestErrorLog(t *testing.T) {
+	log := &ErrorLog{path: filepath.Join(t.TempDir(), "errors.jsonl")}
+	if len(log.Recent()) != 0 {
+		t.Fatal("Expected an empty log before anything is added")
+	}
+
+	for i := 0; i < maxLoggedErrors+5; i++ {
+		if err := log.Add("Play", fmt.Errorf("error %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recent := log.Recent()
+	if len(recent) != maxLoggedErrors {
+		t.Fatalf("Expected %d errors kept, got %d", maxLoggedErrors, len(recent))
+	}
+	if recent[0].Message != "error 5" || recent[len(recent)-1].Message != fmt.Sprintf("error %d", maxLoggedErrors+4) {
+		t.Errorf("Expected the oldest errors dropped, got %q to %q", recent[0].Message, recent[len(recent)-1].Message)
+	}
+
+	// Errors are kept across sessions
+	reopened := &ErrorLog{path: log.path}
+	reopened.Add("Load", errors.New("missing file"))
+	if last := reopened.Recent()[maxLoggedErrors-1]; last.Context != "Load" || last.Message != "missing file" {
+		t.Errorf("Unexpected last error %+v", last)
+	}
+}
+
+func TestDataFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "config.json"), make([]byte, 10), 0644)
+	os.WriteFile(filepath.Join(dir, "history.jsonl"), make([]byte, 300), 0644)
+	os.MkdirAll(filepath.Join(dir, "playlists"), 0755)
+	os.WriteFile(filepath.Join(dir, "playlists", "a.json"), make([]byte, 40), 0644)
+	os.WriteFile(filepath.Join(dir, "playlists", "b.json"), make([]byte, 60), 0644)
+
+	files, err := DataFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DataFile{{"history.jsonl", 300}, {"playlists/", 100}, {"config.json", 10}}
+	if fmt.Sprint(files) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, files)
+	}
+}
+
+func TestParseDevices(t *testing.T) {
+	output := `**** List of PLAYBACK Hardware Devices ****
+card 0: PCH [HDA Intel PCH], device 0: ALC257 Analog [ALC257 Analog]
+  Subdevices: 1/1
+  Subdevice #0: subdevice #0
+card 1: Device [USB Audio Device], device 0: USB Audio [USB Audio]
+  Subdevices: 1/1
+`
+	devices := parseDevices(output)
+	want := []SoundDevice{
+		{ID: "hw:0,0", Name: "HDA Intel PCH - ALC257 Analog"},
+		{ID: "hw:1,0", Name: "USB Audio Device - USB Audio"},
+	}
+	if fmt.Sprint(devices) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, devices)
+	}
+}
@@ -0,0 +1,88 @@
+// Package diagnostics gathers what is needed to look into a problem: recent
+// errors, the size of the data Tuneminal keeps and the sound devices it sees
+package diagnostics
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/paths"
+)
+
+// maxLoggedErrors is how many errors the log keeps
+const maxLoggedErrors = 20
+
+// LoggedError is an error shown to the user, kept for bug reports
+type LoggedError struct {
+	Time    time.Time `json:"time"`
+	Context string    `json:"context"`
+	Message string    `json:"message"`
+}
+
+// ErrorLog keeps the most recent errors, backed by a JSON lines file so the
+// command line can report errors from the last session
+type ErrorLog struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewErrorLog creates an error log backed by errors.jsonl in the data directory
+func NewErrorLog() *ErrorLog {
+	return &ErrorLog{path: paths.Data("errors.jsonl")}
+}
+
+// Add records an error, dropping the oldest once the log is full
+func (l *ErrorLog) Add(context string, err error) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entries := l.read()
+	entries = append(entries, LoggedError{Time: time.Now(), Context: context, Message: err.Error()})
+	if len(entries) > maxLoggedErrors {
+		entries = entries[len(entries)-maxLoggedErrors:]
+	}
+
+	var data strings.Builder
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		data.Write(line)
+		data.WriteByte('\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(l.path, []byte(data.String()), 0644)
+}
+
+// Recent returns the logged errors, oldest first
+func (l *ErrorLog) Recent() []LoggedError {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.read()
+}
+
+// read loads the log, skipping lines that can't be read (caller must hold
+// the mutex)
+func (l *ErrorLog) read() []LoggedError {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return nil
+	}
+
+	var entries []LoggedError
+	for _, line := range strings.Split(string(data), "\n") {
+		var entry LoggedError
+		if line != "" && json.Unmarshal([]byte(line), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
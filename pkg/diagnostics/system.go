@@ -0,0 +1,91 @@
+package diagnostics
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DataFile is one of the files Tuneminal keeps its data in
+type DataFile struct {
+	Name string
+	Size int64
+}
+
+// DataFiles lists the files in a data directory with their sizes, biggest
+// first. Files in subdirectories such as playlists count towards the
+// subdirectory.
+func DataFiles(dir string) ([]DataFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []DataFile
+	for _, entry := range entries {
+		file := DataFile{Name: entry.Name()}
+		if entry.IsDir() {
+			file.Name += "/"
+			filepath.WalkDir(filepath.Join(dir, entry.Name()), func(path string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					if info, err := d.Info(); err == nil {
+						file.Size += info.Size()
+					}
+				}
+				return nil
+			})
+		} else if info, err := entry.Info(); err == nil {
+			file.Size = info.Size()
+		}
+		files = append(files, file)
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Size > files[j].Size
+	})
+	return files, nil
+}
+
+// alsaDeviceRegex matches a device in "aplay -l" or "arecord -l" output
+var alsaDeviceRegex = regexp.MustCompile(`(?m)^card (\d+): [^[]*\[([^\]]*)\], device (\d+): [^[]*\[([^\]]*)\]`)
+
+// SoundDevice is an ALSA playback or capture device
+type SoundDevice struct {
+	ID   string // As given to aplay -D, e.g. "hw:1,0"
+	Name string
+}
+
+// PlaybackDevices lists the ALSA playback devices, using aplay
+func PlaybackDevices() ([]SoundDevice, error) {
+	return listDevices("aplay")
+}
+
+// CaptureDevices lists the ALSA capture devices such as microphones, using
+// arecord
+func CaptureDevices() ([]SoundDevice, error) {
+	return listDevices("arecord")
+}
+
+// listDevices runs an ALSA tool with -l and reads the devices it lists
+func listDevices(tool string) ([]SoundDevice, error) {
+	output, err := exec.Command(tool, "-l").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseDevices(string(output)), nil
+}
+
+// parseDevices reads devices from "aplay -l" style output
+func parseDevices(output string) []SoundDevice {
+	var devices []SoundDevice
+	for _, m := range alsaDeviceRegex.FindAllStringSubmatch(output, -1) {
+		devices = append(devices, SoundDevice{
+			ID:   "hw:" + m[1] + "," + m[3],
+			Name: strings.TrimSpace(m[2] + " - " + m[4]),
+		})
+	}
+	return devices
+}
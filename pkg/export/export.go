@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/paths"
+	"github.com/tuneminal/tuneminal/pkg/utils"
 )
 
 // PerformanceData represents karaoke performance statistics
@@ -31,32 +35,101 @@ type LibraryData struct {
 	Size       int64  `json:"size" csv:"size"`
 }
 
+// DefaultNameTemplate names exports by type and time, e.g.
+// music_library_2024-05-01_20-15-00.csv
+const DefaultNameTemplate = "{type}_{date}_{time}"
+
 // ExportManager handles data export functionality
 type ExportManager struct {
-	exportDir string
+	exportDir    string
+	nameTemplate string
+	savePath     string // Exact file for the next export, set by SaveAs
 }
 
 // NewExportManager creates a new export manager
 func NewExportManager() *ExportManager {
-	homeDir, _ := os.UserHomeDir()
-	exportDir := filepath.Join(homeDir, ".tuneminal", "exports")
+	exportDir := paths.Data("exports")
 
 	return &ExportManager{
-		exportDir: exportDir,
+		exportDir:    exportDir,
+		nameTemplate: DefaultNameTemplate,
+	}
+}
+
+// SetDirectory changes where exports are written; empty keeps the default
+func (em *ExportManager) SetDirectory(dir string) {
+	if dir != "" {
+		em.exportDir = dir
+	}
+}
+
+// SetNameTemplate changes how export files are named. The template may use
+// {type} (e.g. music_library), {date}, {time} and {song}; the extension is
+// added. Empty keeps the default.
+func (em *ExportManager) SetNameTemplate(template string) {
+	if template != "" {
+		em.nameTemplate = template
+	}
+}
+
+// SaveAs returns a manager that writes its export to exactly path instead
+// of a templated name in the export directory
+func (em *ExportManager) SaveAs(path string) *ExportManager {
+	saving := *em
+	saving.savePath = path
+	return &saving
+}
+
+// outputPath returns where an export of the given type goes, creating the
+// directory if needed. song names the song the export is about, if any.
+func (em *ExportManager) outputPath(kind, song, ext string) (string, error) {
+	path := em.savePath
+	if path == "" {
+		path = filepath.Join(em.exportDir, expandNameTemplate(em.nameTemplate, kind, song, time.Now())+"."+ext)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// expandNameTemplate fills in a filename template. Separators left dangling
+// by an empty {song} are tidied away, and characters that can't be in a
+// filename are replaced.
+func expandNameTemplate(template, kind, song string, now time.Time) string {
+	song = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, song)
+
+	name := strings.NewReplacer(
+		"{type}", kind,
+		"{date}", now.Format("2006-01-02"),
+		"{time}", now.Format("15-04-05"),
+		"{song}", song,
+	).Replace(template)
+
+	for _, sep := range []string{"_", "-", " "} {
+		for strings.Contains(name, sep+sep) {
+			name = strings.ReplaceAll(name, sep+sep, sep)
+		}
 	}
+	name = strings.Trim(name, "_- .")
+	if name == "" {
+		name = kind
+	}
+	return name
 }
 
 // ExportPerformanceData exports karaoke performance statistics
 func (em *ExportManager) ExportPerformanceData(performances []PerformanceData, format string) error {
-	// Create export directory if it doesn't exist
-	if err := os.MkdirAll(em.exportDir, 0755); err != nil {
+	filepath, err := em.outputPath("karaoke_performance", "", format)
+	if err != nil {
 		return err
 	}
 
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	filename := fmt.Sprintf("karaoke_performance_%s.%s", timestamp, format)
-	filepath := filepath.Join(em.exportDir, filename)
-
 	switch format {
 	case "json":
 		return em.exportPerformanceAsJSON(performances, filepath)
@@ -69,15 +142,11 @@ func (em *ExportManager) ExportPerformanceData(performances []PerformanceData, f
 
 // ExportLibraryData exports music library information
 func (em *ExportManager) ExportLibraryData(library []LibraryData, format string) error {
-	// Create export directory if it doesn't exist
-	if err := os.MkdirAll(em.exportDir, 0755); err != nil {
+	filepath, err := em.outputPath("music_library", "", format)
+	if err != nil {
 		return err
 	}
 
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	filename := fmt.Sprintf("music_library_%s.%s", timestamp, format)
-	filepath := filepath.Join(em.exportDir, filename)
-
 	switch format {
 	case "json":
 		return em.exportLibraryAsJSON(library, filepath)
@@ -96,7 +165,7 @@ func (em *ExportManager) exportPerformanceAsJSON(performances []PerformanceData,
 		"performances": performances,
 	}
 
-	file, err := os.Create(filepath)
+	file, err := utils.CreateAtomic(filepath)
 	if err != nil {
 		return err
 	}
@@ -104,19 +173,21 @@ func (em *ExportManager) exportPerformanceAsJSON(performances []PerformanceData,
 
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(data)
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+	return file.Commit()
 }
 
 // exportPerformanceAsCSV exports performance data as CSV
 func (em *ExportManager) exportPerformanceAsCSV(performances []PerformanceData, filepath string) error {
-	file, err := os.Create(filepath)
+	file, err := utils.CreateAtomic(filepath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
 
 	// Write header
 	header := []string{"date", "song_title", "artist", "score", "streak", "accuracy", "duration"}
@@ -140,7 +211,11 @@ func (em *ExportManager) exportPerformanceAsCSV(performances []PerformanceData,
 		}
 	}
 
-	return nil
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Commit()
 }
 
 // exportLibraryAsJSON exports library data as JSON
@@ -151,7 +226,7 @@ func (em *ExportManager) exportLibraryAsJSON(library []LibraryData, filepath str
 		"library": library,
 	}
 
-	file, err := os.Create(filepath)
+	file, err := utils.CreateAtomic(filepath)
 	if err != nil {
 		return err
 	}
@@ -159,19 +234,21 @@ func (em *ExportManager) exportLibraryAsJSON(library []LibraryData, filepath str
 
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(data)
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+	return file.Commit()
 }
 
 // exportLibraryAsCSV exports library data as CSV
 func (em *ExportManager) exportLibraryAsCSV(library []LibraryData, filepath string) error {
-	file, err := os.Create(filepath)
+	file, err := utils.CreateAtomic(filepath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
 
 	// Write header
 	header := []string{"title", "artist", "path", "lyrics_path", "duration", "format", "size"}
@@ -195,7 +272,11 @@ func (em *ExportManager) exportLibraryAsCSV(library []LibraryData, filepath stri
 		}
 	}
 
-	return nil
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Commit()
 }
 
 // ListExports returns a list of all exported files
@@ -222,3 +303,10 @@ func (em *ExportManager) ListExports() ([]string, error) {
 func (em *ExportManager) GetExportPath() string {
 	return em.exportDir
 }
+
+// NewFilePath returns a templated path in the export directory for a file
+// written elsewhere, such as a recording, creating the directory if needed.
+// song is the song playing, if any.
+func (em *ExportManager) NewFilePath(prefix, song, ext string) (string, error) {
+	return em.outputPath(prefix, song, ext)
+}
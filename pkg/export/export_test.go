@@ -0,0 +1,38 @@
+package export
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExpandNameTemplate(t *testing.T) {
+	now := time.Date(2024, time.May, 1, 20, 15, 0, 0, time.UTC)
+	tests := []struct {
+		template, song, want string
+	}{
+		{DefaultNameTemplate, "", "music_library_2024-05-01_20-15-00"},
+		{"{date} {song} {type}", "AC/DC: Live", "2024-05-01 AC_DC_ Live music_library"},
+		{"{type}_{song}_{date}", "", "music_library_2024-05-01"},
+		{"{song}", "", "music_library"},
+	}
+	for _, test := range tests {
+		if got := expandNameTemplate(test.template, "music_library", test.song, now); got != test.want {
+			t.Errorf("expandNameTemplate(%q, %q) = %q, want %q", test.template, test.song, got, test.want)
+		}
+	}
+}
+
+func TestSaveAs(t *testing.T) {
+	dir := t.TempDir()
+	manager := &ExportManager{exportDir: dir, nameTemplate: DefaultNameTemplate}
+	target := filepath.Join(dir, "nested", "mine.csv")
+
+	path, err := manager.SaveAs(target).outputPath("music_library", "", "csv")
+	if err != nil || path != target {
+		t.Fatalf("Expected the save-as path, got %q %v", path, err)
+	}
+	if path, _ := manager.outputPath("music_library", "", "csv"); filepath.Dir(path) != dir {
+		t.Errorf("Expected SaveAs to leave the manager alone, got %q", path)
+	}
+}
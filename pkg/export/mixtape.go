@@ -0,0 +1,358 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/tuneminal/tuneminal/pkg/player"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// mixtapeSampleRate is the rate every track is resampled to before mixing
+const mixtapeSampleRate = beep.SampleRate(44100)
+
+// normalizePeak is the level tracks are scaled to when normalizing (about -1 dBFS)
+const normalizePeak = 0.89
+
+// MixtapeOptions controls how tracks are joined into a mixtape
+type MixtapeOptions struct {
+	Format    string        // "wav" or "mp3"
+	Crossfade time.Duration // Overlap between consecutive tracks, 0 for none
+	Normalize bool          // Scale each track to the same peak level
+
+	// OnProgress is called before each track is mixed
+	OnProgress func(done, total int, current string)
+}
+
+// MixtapeResult describes a finished mixtape
+type MixtapeResult struct {
+	Path     string
+	Tracks   int
+	Duration time.Duration
+	Skipped  map[string]error // Tracks that could not be decoded
+}
+
+// ExportMixtape decodes the given tracks and writes them back to back into a
+// single audio file in the export directory. Cancelling ctx stops it between
+// tracks, leaving no file behind.
+func (em *ExportManager) ExportMixtape(ctx context.Context, paths []string, opts MixtapeOptions) (*MixtapeResult, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("no tracks to export")
+	}
+	if opts.Format == "" {
+		opts.Format = "wav"
+	}
+	if opts.Format != "wav" && opts.Format != "mp3" {
+		return nil, fmt.Errorf("unsupported format: %s", opts.Format)
+	}
+
+	path, err := em.outputPath("mixtape", "", opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	wavPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".wav"
+
+	result, err := writeMixtape(ctx, wavPath, paths, opts)
+	if err != nil {
+		os.Remove(wavPath)
+		return nil, err
+	}
+
+	if opts.Format == "mp3" {
+		err := encodeMP3(ctx, wavPath, path)
+		os.Remove(wavPath)
+		if err != nil {
+			return nil, err
+		}
+		result.Path = path
+	}
+
+	return result, nil
+}
+
+// writeMixtape mixes the tracks into a 16-bit stereo WAV file
+func writeMixtape(ctx context.Context, path string, paths []string, opts MixtapeOptions) (*MixtapeResult, error) {
+	file, err := utils.CreateAtomic(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	out, err := newWavWriter(file, mixtapeSampleRate)
+	if err != nil {
+		return nil, err
+	}
+	mixer := &mixtapeMixer{out: out, fade: mixtapeSampleRate.N(opts.Crossfade)}
+
+	result := &MixtapeResult{Path: path, Skipped: make(map[string]error)}
+	for i, track := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(i, len(paths), track)
+		}
+
+		gain := 1.0
+		if opts.Normalize {
+			peak, err := trackPeak(track)
+			if err != nil {
+				result.Skipped[track] = err
+				continue
+			}
+			if peak > 0 {
+				gain = normalizePeak / peak
+			}
+		}
+
+		if err := mixTrack(mixer, track, gain); err != nil {
+			var writeErr *mixtapeWriteError
+			if errors.As(err, &writeErr) {
+				return nil, writeErr.err
+			}
+			result.Skipped[track] = err
+			continue
+		}
+		result.Tracks++
+	}
+	if opts.OnProgress != nil {
+		opts.OnProgress(len(paths), len(paths), "")
+	}
+
+	if result.Tracks == 0 {
+		return nil, errors.New("none of the tracks could be decoded")
+	}
+	if err := mixer.finish(); err != nil {
+		return nil, err
+	}
+	if err := file.Commit(); err != nil {
+		return nil, err
+	}
+	result.Duration = mixtapeSampleRate.D(out.frames)
+	return result, nil
+}
+
+// openTrack decodes a track resampled to the mixtape rate
+func openTrack(path string) (beep.Streamer, io.Closer, error) {
+	streamer, format, err := player.Decode(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if format.SampleRate == mixtapeSampleRate {
+		return streamer, streamer, nil
+	}
+	return beep.Resample(4, format.SampleRate, mixtapeSampleRate, streamer), streamer, nil
+}
+
+// trackPeak decodes a whole track and returns its highest sample level
+func trackPeak(path string) (float64, error) {
+	streamer, closer, err := openTrack(path)
+	if err != nil {
+		return 0, err
+	}
+	defer closer.Close()
+
+	peak := 0.0
+	buf := make([][2]float64, 4096)
+	for {
+		n, ok := streamer.Stream(buf)
+		for _, frame := range buf[:n] {
+			peak = math.Max(peak, math.Max(math.Abs(frame[0]), math.Abs(frame[1])))
+		}
+		if !ok {
+			break
+		}
+	}
+	return peak, streamer.Err()
+}
+
+// mixTrack streams one decoded track into the mixer
+func mixTrack(mixer *mixtapeMixer, path string, gain float64) error {
+	streamer, closer, err := openTrack(path)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	if err := mixer.add(streamer, gain); err != nil {
+		return err
+	}
+	return streamer.Err()
+}
+
+// mixtapeWriteError marks a failure writing the output, which ends the export
+// rather than skipping a track
+type mixtapeWriteError struct {
+	err error
+}
+
+func (e *mixtapeWriteError) Error() string {
+	return e.err.Error()
+}
+
+// mixtapeMixer appends tracks to the output, holding back the last fade
+// frames of each track so the next one can crossfade over them
+type mixtapeMixer struct {
+	out     *wavWriter
+	fade    int
+	pending [][2]float64
+}
+
+// add mixes a streamer into the output, fading in over the previous track's tail
+func (m *mixtapeMixer) add(streamer beep.Streamer, gain float64) error {
+	tail := m.pending
+	m.pending = nil
+
+	buf := make([][2]float64, 4096)
+	k := 0
+	for {
+		n, ok := streamer.Stream(buf)
+		for _, frame := range buf[:n] {
+			frame[0] *= gain
+			frame[1] *= gain
+			if k < len(tail) {
+				t := float64(k) / float64(len(tail))
+				frame[0] = tail[k][0]*(1-t) + frame[0]*t
+				frame[1] = tail[k][1]*(1-t) + frame[1]*t
+			}
+			m.pending = append(m.pending, frame)
+			k++
+		}
+
+		// Write out everything but the tail, in large enough batches that
+		// shifting the tail down stays cheap
+		if len(m.pending) >= 2*m.fade+len(buf) {
+			if err := m.flush(m.fade); err != nil {
+				return err
+			}
+		}
+		if !ok {
+			break
+		}
+	}
+
+	if k < len(tail) {
+		// The track was shorter than the crossfade; keep the rest of the previous one
+		m.pending = append(m.pending, tail[k:]...)
+	}
+	return m.flush(m.fade)
+}
+
+// flush writes all but the last keep pending frames
+func (m *mixtapeMixer) flush(keep int) error {
+	if len(m.pending) <= keep {
+		return nil
+	}
+	split := len(m.pending) - keep
+	if err := m.out.write(m.pending[:split]); err != nil {
+		return &mixtapeWriteError{err: err}
+	}
+	m.pending = append(m.pending[:0], m.pending[split:]...)
+	return nil
+}
+
+// finish writes the remaining frames and completes the WAV header
+func (m *mixtapeMixer) finish() error {
+	if err := m.flush(0); err != nil {
+		return err
+	}
+	return m.out.close()
+}
+
+// wavWriter writes 16-bit stereo PCM with a header patched on close
+type wavWriter struct {
+	file   io.WriteSeeker
+	buf    *bufio.Writer
+	rate   beep.SampleRate
+	frames int
+}
+
+func newWavWriter(file io.WriteSeeker, rate beep.SampleRate) (*wavWriter, error) {
+	w := &wavWriter{file: file, buf: bufio.NewWriter(file), rate: rate}
+	if err := w.writeHeader(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *wavWriter) writeHeader() error {
+	const channels, bytesPerSample = 2, 2
+	dataSize := uint32(w.frames * channels * bytesPerSample)
+
+	header := []interface{}{
+		[4]byte{'R', 'I', 'F', 'F'}, 36 + dataSize, [4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '}, uint32(16), uint16(1), uint16(channels),
+		uint32(w.rate), uint32(int(w.rate) * channels * bytesPerSample),
+		uint16(channels * bytesPerSample), uint16(8 * bytesPerSample),
+		[4]byte{'d', 'a', 't', 'a'}, dataSize,
+	}
+	for _, field := range header {
+		if err := binary.Write(w.buf, binary.LittleEndian, field); err != nil {
+			return err
+		}
+	}
+	return w.buf.Flush()
+}
+
+func (w *wavWriter) write(frames [][2]float64) error {
+	var sample [4]byte
+	for _, frame := range frames {
+		for c := 0; c < 2; c++ {
+			value := int16(math.Max(-1, math.Min(1, frame[c])) * math.MaxInt16)
+			binary.LittleEndian.PutUint16(sample[2*c:], uint16(value))
+		}
+		if _, err := w.buf.Write(sample[:]); err != nil {
+			return err
+		}
+	}
+	w.frames += len(frames)
+	return nil
+}
+
+func (w *wavWriter) close() error {
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return w.writeHeader()
+}
+
+// encodeMP3 converts a WAV file with whichever MP3 encoder is installed
+func encodeMP3(ctx context.Context, wavPath, mp3Path string) error {
+	var cmd *exec.Cmd
+	switch {
+	case commandExists("lame"):
+		cmd = exec.CommandContext(ctx, "lame", "--quiet", "-V2", wavPath, mp3Path)
+	case commandExists("ffmpeg"):
+		cmd = exec.CommandContext(ctx, "ffmpeg", "-y", "-loglevel", "error", "-i", wavPath, "-codec:a", "libmp3lame", "-q:a", "2", mp3Path)
+	default:
+		return errors.New("MP3 export needs lame or ffmpeg installed - export as WAV instead")
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(mp3Path)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("MP3 encoding failed: %v: %s", err, output)
+	}
+	return nil
+}
+
+func commandExists(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+// constant returns a streamer of n frames at the given level
+func constant(n int, level float64) beep.Streamer {
+	return beep.Take(n, beep.StreamerFunc(func(samples [][2]float64) (int, bool) {
+		for i := range samples {
+			samples[i] = [2]float64{level, level}
+		}
+		return len(samples), true
+	}))
+}
+
+func TestMixtapeCrossfade(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mix.wav")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := newWavWriter(file, mixtapeSampleRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mixer := &mixtapeMixer{out: out, fade: 2000}
+	if err := mixer.add(constant(10000, 0.5), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := mixer.add(constant(10000, 0.25), 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := mixer.finish(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	// Read the samples back directly; the WAV decoder in beep v1.1.0
+	// scales 16-bit samples by half
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate := binary.LittleEndian.Uint32(data[24:]); rate != uint32(mixtapeSampleRate) {
+		t.Fatalf("got %d Hz, want %d Hz", rate, mixtapeSampleRate)
+	}
+	frames := int(binary.LittleEndian.Uint32(data[40:])) / 4
+	if frames != 18000 || len(data) != 44+frames*4 {
+		t.Fatalf("got %d frames in %d bytes, want 18000", frames, len(data))
+	}
+	sample := func(frame int) float64 {
+		return float64(int16(binary.LittleEndian.Uint16(data[44+frame*4:]))) / math.MaxInt16
+	}
+
+	for _, check := range []struct {
+		frame int
+		want  float64
+	}{
+		{0, 0.5},
+		{8000, 0.5},  // start of the fade, still all first track
+		{9000, 0.5},  // halfway: both tracks at 0.5 after gain
+		{17999, 0.5}, // second track doubled by gain
+	} {
+		if got := sample(check.frame); got < check.want-0.01 || got > check.want+0.01 {
+			t.Errorf("frame %d: got %.3f, want %.3f", check.frame, got, check.want)
+		}
+	}
+}
+
+func TestWriteMixtapeCancelled(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := writeMixtape(ctx, filepath.Join(dir, "mix.wav"), []string{"one.mp3", "two.mp3"}, MixtapeOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the export to be cancelled, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected no file left behind, got %v", entries)
+	}
+}
@@ -0,0 +1,193 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/history"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// SongStats is one song's row in the full statistics export: what the
+// library knows about it joined with its plays in the date range
+type SongStats struct {
+	Path        string    `json:"path"`
+	Title       string    `json:"title"`
+	Artist      string    `json:"artist"`
+	Album       string    `json:"album,omitempty"`
+	Lyrics      string    `json:"lyrics"` // synced, unsynced or missing; empty if no longer in the library
+	Plays       int       `json:"plays"`
+	Sung        int       `json:"sung"`
+	Listened    float64   `json:"listened_seconds"`
+	BestScore   int       `json:"best_score"`
+	AvgAccuracy float64   `json:"avg_accuracy"` // Over sung plays, in percent
+	FirstPlayed time.Time `json:"first_played,omitzero"`
+	LastPlayed  time.Time `json:"last_played,omitzero"`
+}
+
+// statsHeader names the CSV columns, in SongStats order
+var statsHeader = []string{"path", "title", "artist", "album", "lyrics", "plays", "sung",
+	"listened_seconds", "best_score", "avg_accuracy", "first_played", "last_played"}
+
+// inRange reports whether t falls in [from, to); a zero bound is open
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+}
+
+// BuildStats joins the library with the plays started in [from, to). A zero
+// from or to leaves that end open. Every library song gets a row, played or
+// not; songs that were played but have since left the library are added
+// from the history. Rows come back most played first.
+func BuildStats(library []SongStats, plays []history.Play, from, to time.Time) []SongStats {
+	rows := make([]SongStats, len(library))
+	copy(rows, library)
+	index := make(map[string]int, len(rows))
+	for i, row := range rows {
+		index[row.Path] = i
+	}
+
+	accuracy := make([]float64, len(rows))
+	for _, play := range plays {
+		if !inRange(play.Started, from, to) {
+			continue
+		}
+		i, ok := index[play.Path]
+		if !ok {
+			i = len(rows)
+			index[play.Path] = i
+			rows = append(rows, SongStats{Path: play.Path, Title: play.Title, Artist: play.Artist})
+			accuracy = append(accuracy, 0)
+		}
+
+		row := &rows[i]
+		row.Plays++
+		row.Listened += play.Listened.Seconds()
+		if row.FirstPlayed.IsZero() || play.Started.Before(row.FirstPlayed) {
+			row.FirstPlayed = play.Started
+		}
+		if play.Started.After(row.LastPlayed) {
+			row.LastPlayed = play.Started
+		}
+		if play.Sung {
+			row.Sung++
+			accuracy[i] += play.Accuracy
+			if play.Score > row.BestScore {
+				row.BestScore = play.Score
+			}
+		}
+	}
+
+	for i := range rows {
+		if rows[i].Sung > 0 {
+			rows[i].AvgAccuracy = accuracy[i] / float64(rows[i].Sung)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Plays > rows[j].Plays
+	})
+	return rows
+}
+
+// ExportStats writes the full statistics for [from, to) as one dataset.
+// JSON also carries the individual plays; CSV has one row per song.
+func (em *ExportManager) ExportStats(library []SongStats, plays []history.Play, from, to time.Time, format string) error {
+	path, err := em.outputPath("full_stats", "", format)
+	if err != nil {
+		return err
+	}
+	rows := BuildStats(library, plays, from, to)
+
+	switch format {
+	case "json":
+		var inRangePlays []history.Play
+		for _, play := range plays {
+			if inRange(play.Started, from, to) {
+				inRangePlays = append(inRangePlays, play)
+			}
+		}
+		return writeStatsJSON(path, rows, inRangePlays, from, to)
+	case "csv":
+		return writeStatsCSV(path, rows)
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// writeStatsJSON writes the rows and plays with the range they cover
+func writeStatsJSON(path string, rows []SongStats, plays []history.Play, from, to time.Time) error {
+	data := map[string]interface{}{
+		"export_date": time.Now(),
+		"songs":       rows,
+		"plays":       plays,
+	}
+	if !from.IsZero() {
+		data["from"] = from
+	}
+	if !to.IsZero() {
+		data["to"] = to
+	}
+
+	file, err := utils.CreateAtomic(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+	return file.Commit()
+}
+
+// writeStatsCSV writes one row per song, with times in RFC 3339 so
+// spreadsheets parse them
+func writeStatsCSV(path string, rows []SongStats) error {
+	file, err := utils.CreateAtomic(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(statsHeader); err != nil {
+		return err
+	}
+
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	for _, row := range rows {
+		record := []string{
+			row.Path,
+			row.Title,
+			row.Artist,
+			row.Album,
+			row.Lyrics,
+			strconv.Itoa(row.Plays),
+			strconv.Itoa(row.Sung),
+			strconv.FormatFloat(row.Listened, 'f', 0, 64),
+			strconv.Itoa(row.BestScore),
+			strconv.FormatFloat(row.AvgAccuracy, 'f', 1, 64),
+			formatTime(row.FirstPlayed),
+			formatTime(row.LastPlayed),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Commit()
+}
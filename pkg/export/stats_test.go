@@ -0,0 +1,53 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/history"
+)
+
+func TestBuildStats(t *testing.T) {
+	day := time.Date(2024, time.May, 1, 20, 0, 0, 0, time.UTC)
+	library := []SongStats{
+		{Path: "a.mp3", Title: "A", Lyrics: "synced"},
+		{Path: "b.mp3", Title: "B", Lyrics: "missing"},
+	}
+	plays := []history.Play{
+		{Path: "a.mp3", Title: "A", Started: day, Listened: time.Minute, Sung: true, Score: 900, Accuracy: 80},
+		{Path: "a.mp3", Title: "A", Started: day.Add(time.Hour), Listened: time.Minute, Sung: true, Score: 700, Accuracy: 60},
+		{Path: "gone.mp3", Title: "Gone", Started: day, Listened: 30 * time.Second},
+		{Path: "b.mp3", Title: "B", Started: day.AddDate(0, -1, 0)}, // Before the range
+	}
+
+	rows := BuildStats(library, plays, day.Add(-time.Hour), time.Time{})
+	if len(rows) != 3 {
+		t.Fatalf("Expected the library plus the removed song, got %d rows", len(rows))
+	}
+
+	a := rows[0]
+	if a.Path != "a.mp3" || a.Plays != 2 || a.Sung != 2 || a.BestScore != 900 {
+		t.Errorf("Unexpected totals for the most played song: %+v", a)
+	}
+	if a.AvgAccuracy != 70 || a.Listened != 120 {
+		t.Errorf("Expected 70%% accuracy over 120s, got %.1f over %.0f", a.AvgAccuracy, a.Listened)
+	}
+	if !a.FirstPlayed.Equal(day) || !a.LastPlayed.Equal(day.Add(time.Hour)) {
+		t.Errorf("Unexpected play dates %v - %v", a.FirstPlayed, a.LastPlayed)
+	}
+
+	for _, row := range rows[1:] {
+		switch row.Path {
+		case "b.mp3":
+			if row.Plays != 0 || row.Lyrics != "missing" {
+				t.Errorf("Expected the out-of-range play to be left out: %+v", row)
+			}
+		case "gone.mp3":
+			if row.Plays != 1 || row.Lyrics != "" {
+				t.Errorf("Unexpected row for the removed song: %+v", row)
+			}
+		default:
+			t.Errorf("Unexpected row %q", row.Path)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Package history keeps a log of the songs played and sung, and sums it up
+// into recaps such as "this week" and "on this day last year"
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/paths"
+)
+
+// Play is one song played through part or all of the way
+type Play struct {
+	Path     string        `json:"path"`
+	Title    string        `json:"title"`
+	Artist   string        `json:"artist,omitempty"`
+	Started  time.Time     `json:"started"`
+	Listened time.Duration `json:"listened"`
+	Sung     bool          `json:"sung,omitempty"`     // Played with synced lyrics
+	Score    int           `json:"score,omitempty"`    // Karaoke score, when sung
+	Accuracy float64       `json:"accuracy,omitempty"` // Lyric hit rate in percent, when sung
+}
+
+// Store is the play history, backed by a JSON lines file that is only ever
+// appended to
+type Store struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewStore creates a history store backed by history.jsonl in the data directory
+func NewStore() *Store {
+	return &Store{path: paths.Data("history.jsonl")}
+}
+
+// Add appends a play to the history
+func (s *Store) Add(play Play) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(play)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Plays returns the whole history, oldest first. A missing file is an empty
+// history, and lines that can't be read are skipped.
+func (s *Store) Plays() ([]Play, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var plays []Play
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var play Play
+		if json.Unmarshal(scanner.Bytes(), &play) == nil {
+			plays = append(plays, play)
+		}
+	}
+	sort.SliceStable(plays, func(i, j int) bool { return plays[i].Started.Before(plays[j].Started) })
+	return plays, scanner.Err()
+}
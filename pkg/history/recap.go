@@ -0,0 +1,100 @@
+package history
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// SongTotal sums up the plays of one song within a recap
+type SongTotal struct {
+	Title     string
+	Artist    string
+	Plays     int
+	Listened  time.Duration
+	BestScore int // Highest karaoke score, 0 if never sung
+}
+
+// Recap sums up the plays between From and To
+type Recap struct {
+	From     time.Time
+	To       time.Time
+	Plays    int
+	Sung     int
+	Listened time.Duration
+	Songs    []SongTotal // Most played first
+	Best     []Play      // Highest scoring karaoke runs first
+}
+
+// Summarize builds a recap of the plays started in [from, to), keeping the
+// top songs and best scores up to limit each
+func Summarize(plays []Play, from, to time.Time, limit int) Recap {
+	recap := Recap{From: from, To: to}
+	totals := make(map[string]*SongTotal)
+	var order []string
+
+	for _, play := range plays {
+		if play.Started.Before(from) || !play.Started.Before(to) {
+			continue
+		}
+		recap.Plays++
+		recap.Listened += play.Listened
+
+		// Songs are told apart by name so renamed or moved files still add up
+		key := strings.ToLower(play.Artist + "\x00" + play.Title)
+		total, ok := totals[key]
+		if !ok {
+			total = &SongTotal{Title: play.Title, Artist: play.Artist}
+			totals[key] = total
+			order = append(order, key)
+		}
+		total.Plays++
+		total.Listened += play.Listened
+
+		if play.Sung {
+			recap.Sung++
+			recap.Best = append(recap.Best, play)
+			if play.Score > total.BestScore {
+				total.BestScore = play.Score
+			}
+		}
+	}
+
+	for _, key := range order {
+		recap.Songs = append(recap.Songs, *totals[key])
+	}
+	sort.SliceStable(recap.Songs, func(i, j int) bool {
+		if recap.Songs[i].Plays != recap.Songs[j].Plays {
+			return recap.Songs[i].Plays > recap.Songs[j].Plays
+		}
+		return recap.Songs[i].Listened > recap.Songs[j].Listened
+	})
+	sort.SliceStable(recap.Best, func(i, j int) bool { return recap.Best[i].Score > recap.Best[j].Score })
+
+	if len(recap.Songs) > limit {
+		recap.Songs = recap.Songs[:limit]
+	}
+	if len(recap.Best) > limit {
+		recap.Best = recap.Best[:limit]
+	}
+	return recap
+}
+
+// WeekRange returns the start of the week containing now (Monday at
+// midnight) and the start of the next one
+func WeekRange(now time.Time) (time.Time, time.Time) {
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	start := time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday, 0, 0, 0, 0, now.Location())
+	return start, start.AddDate(0, 0, 7)
+}
+
+// LastYearRange returns the day a year before now, from midnight to
+// midnight. 29 February looks back to the 28th.
+func LastYearRange(now time.Time) (time.Time, time.Time) {
+	day := now.Day()
+	if now.Month() == time.February && day == 29 {
+		day = 28
+	}
+	start := time.Date(now.Year()-1, now.Month(), day, 0, 0, 0, 0, now.Location())
+	return start, start.AddDate(0, 0, 1)
+}
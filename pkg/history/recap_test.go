@@ -0,0 +1,59 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreAddAndPlays(t *testing.T) {
+	store := &Store{path: filepath.Join(t.TempDir(), "history.jsonl")}
+	if plays, err := store.Plays(); err != nil || len(plays) != 0 {
+		t.Fatalf("Expected an empty history, got %v, %v", plays, err)
+	}
+
+	now := time.Date(2024, 3, 10, 20, 0, 0, 0, time.Local)
+	store.Add(Play{Title: "Later", Started: now})
+	store.Add(Play{Title: "Earlier", Started: now.Add(-time.Hour), Sung: true, Score: 1200})
+
+	plays, err := store.Plays()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plays) != 2 || plays[0].Title != "Earlier" || plays[0].Score != 1200 {
+		t.Errorf("Expected both plays, oldest first, got %+v", plays)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	now := time.Date(2024, 3, 13, 20, 0, 0, 0, time.Local) // a Wednesday
+	from, to := WeekRange(now)
+	if from.Weekday() != time.Monday || from.Day() != 11 || !to.Equal(from.AddDate(0, 0, 7)) {
+		t.Fatalf("Unexpected week %v to %v", from, to)
+	}
+
+	plays := []Play{
+		{Title: "Old", Started: from.Add(-time.Hour), Listened: time.Minute},
+		{Title: "Song A", Artist: "X", Started: from.Add(time.Hour), Listened: 3 * time.Minute, Sung: true, Score: 900},
+		{Title: "Song B", Started: from.Add(2 * time.Hour), Listened: 2 * time.Minute},
+		{Title: "song a", Artist: "x", Started: now, Listened: 3 * time.Minute, Sung: true, Score: 2500},
+	}
+
+	recap := Summarize(plays, from, to, 5)
+	if recap.Plays != 3 || recap.Sung != 2 || recap.Listened != 8*time.Minute {
+		t.Errorf("Unexpected totals: %+v", recap)
+	}
+	if len(recap.Songs) != 2 || recap.Songs[0].Title != "Song A" || recap.Songs[0].Plays != 2 || recap.Songs[0].BestScore != 2500 {
+		t.Errorf("Expected Song A on top with two plays, got %+v", recap.Songs)
+	}
+	if len(recap.Best) != 2 || recap.Best[0].Score != 2500 {
+		t.Errorf("Expected the best score first, got %+v", recap.Best)
+	}
+}
+
+func TestLastYearRange(t *testing.T) {
+	from, to := LastYearRange(time.Date(2024, 2, 29, 9, 0, 0, 0, time.Local))
+	if !from.Equal(time.Date(2023, 2, 28, 0, 0, 0, 0, time.Local)) || !to.Equal(from.AddDate(0, 0, 1)) {
+		t.Errorf("Unexpected range %v to %v", from, to)
+	}
+}
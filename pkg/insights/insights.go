@@ -0,0 +1,137 @@
+// Package insights counts how often each feature is used, for the user's own
+// look at their habits. Nothing leaves the machine: the counts are kept in
+// the data directory and only exported when asked.
+package insights
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/paths"
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// Usage is how often a feature has been used
+type Usage struct {
+	Feature string    `json:"feature"`
+	Count   int       `json:"count"`
+	First   time.Time `json:"first"`
+	Last    time.Time `json:"last"`
+}
+
+// Store keeps feature usage counts in insights.json. Uses are counted in
+// memory and written out by Save, so counting costs no disk access.
+type Store struct {
+	path  string
+	mutex sync.Mutex
+	usage map[string]Usage
+	dirty bool // counted since the last save
+}
+
+// NewStore creates a store backed by insights.json in the data directory
+func NewStore() *Store {
+	store := &Store{
+		path:  paths.Data("insights.json"),
+		usage: make(map[string]Usage),
+	}
+	store.load()
+	return store
+}
+
+// load reads saved counts, starting empty if the file is missing or invalid
+func (s *Store) load() {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return
+	}
+	json.Unmarshal(data, &s.usage)
+}
+
+// save writes all counts to disk
+func (s *Store) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s.usage, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// Record counts one use of a feature; Save writes it to disk
+func (s *Store) Record(feature string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	usage, ok := s.usage[feature]
+	if !ok {
+		usage = Usage{Feature: feature, First: now}
+	}
+	usage.Count++
+	usage.Last = now
+	s.usage[feature] = usage
+	s.dirty = true
+}
+
+// Save writes the counts to disk if any were recorded since the last save
+func (s *Store) Save() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+	if err := s.save(); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// Usage returns the features used, most used first
+func (s *Store) Usage() []Usage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	usage := make([]Usage, 0, len(s.usage))
+	for _, u := range s.usage {
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Count != usage[j].Count {
+			return usage[i].Count > usage[j].Count
+		}
+		return usage[i].Feature < usage[j].Feature
+	})
+	return usage
+}
+
+// Export writes the counts, most used first, as JSON to path
+func (s *Store) Export(path string) error {
+	data, err := json.MarshalIndent(s.Usage(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, data)
+}
+
+// Clear forgets all counts
+func (s *Store) Clear() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.usage = make(map[string]Usage)
+	s.dirty = false
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
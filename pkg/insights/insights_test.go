@@ -0,0 +1,58 @@
+package insights
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	store := &Store{path: filepath.Join(dir, "insights.json"), usage: make(map[string]Usage)}
+
+	for _, feature := range []string{"shuffle", "lyrics editor", "shuffle", "party", "shuffle", "party"} {
+		store.Record(feature)
+	}
+	if _, err := os.Stat(store.path); !os.IsNotExist(err) {
+		t.Error("Expected nothing written before Save")
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	usage := store.Usage()
+	if len(usage) != 3 || usage[0].Feature != "shuffle" || usage[0].Count != 3 || usage[1].Feature != "party" {
+		t.Fatalf("Unexpected usage: %+v", usage)
+	}
+	if usage[0].First.After(usage[0].Last) {
+		t.Errorf("Expected first use before last, got %+v", usage[0])
+	}
+
+	// Counts survive a restart
+	reloaded := &Store{path: store.path, usage: make(map[string]Usage)}
+	reloaded.load()
+	if got := reloaded.Usage(); len(got) != 3 || got[2].Feature != "lyrics editor" {
+		t.Errorf("Unexpected reloaded usage: %+v", got)
+	}
+
+	exported := filepath.Join(dir, "export.json")
+	if err := store.Export(exported); err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	var written []Usage
+	data, _ := os.ReadFile(exported)
+	if err := json.Unmarshal(data, &written); err != nil || len(written) != 3 || written[0].Count != 3 {
+		t.Errorf("Unexpected export %s (%v)", data, err)
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Clear() error: %v", err)
+	}
+	if len(store.Usage()) != 0 {
+		t.Error("Expected no usage after Clear")
+	}
+	if _, err := os.Stat(store.path); !os.IsNotExist(err) {
+		t.Error("Expected the file to be removed by Clear")
+	}
+}
@@ -0,0 +1,146 @@
+package integrity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+	"github.com/tuneminal/tuneminal/pkg/paths"
+)
+
+// Problem kinds reported by Verify
+const (
+	KindUnreadable = "unreadable"
+	KindCorrupt    = "corrupt" // The audio doesn't decode cleanly
+	KindChanged    = "changed" // Content changed although the file wasn't modified
+)
+
+// Problem is a library file that failed verification
+type Problem struct {
+	Path   string
+	Kind   string
+	Detail string
+}
+
+// Result summarises a verification run
+type Result struct {
+	Checked  int
+	Problems []Problem
+}
+
+// Record is the last known good state of a file
+type Record struct {
+	SHA256   string    `json:"sha256"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Verified time.Time `json:"verified"`
+}
+
+// Store remembers checksums between runs
+type Store struct {
+	path    string
+	mutex   sync.Mutex
+	records map[string]Record
+}
+
+// NewStore creates a checksum store backed by checksums.json in the cache directory
+func NewStore() *Store {
+	store := &Store{
+		path:    paths.Cache("checksums.json"),
+		records: make(map[string]Record),
+	}
+	if data, err := os.ReadFile(store.path); err == nil {
+		json.Unmarshal(data, &store.records)
+	}
+	return store
+}
+
+// Save writes the checksums to disk
+func (s *Store) Save() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s.records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// HashFile returns the SHA-256 of a file as hex
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Verify hashes and decode-probes every file. A file whose checksum changed
+// while its size and modification time stayed the same is reported as
+// changed, since that points at disk corruption rather than an edit.
+// onProgress, if set, is called before each file.
+func Verify(ctx context.Context, files []string, store *Store, onProgress func(done, total int, path string)) *Result {
+	result := &Result{}
+
+	for i, path := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		if onProgress != nil {
+			onProgress(i, len(files), path)
+		}
+		result.Checked++
+
+		info, err := os.Stat(path)
+		if err != nil {
+			result.Problems = append(result.Problems, Problem{Path: path, Kind: KindUnreadable, Detail: err.Error()})
+			continue
+		}
+		sum, err := HashFile(path)
+		if err != nil {
+			result.Problems = append(result.Problems, Problem{Path: path, Kind: KindUnreadable, Detail: err.Error()})
+			continue
+		}
+
+		store.mutex.Lock()
+		previous, known := store.records[path]
+		store.mutex.Unlock()
+		if known && previous.SHA256 != sum && previous.Size == info.Size() && previous.Modified.Equal(info.ModTime()) {
+			result.Problems = append(result.Problems, Problem{Path: path, Kind: KindChanged,
+				Detail: "content changed without the file being modified"})
+			// Keep the old record so the problem keeps being reported
+			continue
+		}
+
+		if err := metadata.VerifyDecode(path); err != nil {
+			result.Problems = append(result.Problems, Problem{Path: path, Kind: KindCorrupt, Detail: err.Error()})
+			continue
+		}
+
+		store.mutex.Lock()
+		store.records[path] = Record{SHA256: sum, Size: info.Size(), Modified: info.ModTime(), Verified: time.Now()}
+		store.mutex.Unlock()
+	}
+
+	if onProgress != nil {
+		onProgress(result.Checked, len(files), "")
+	}
+	return result
+}
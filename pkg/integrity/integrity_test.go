@@ -0,0 +1,32 @@
+package integrity
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestVerifyReportsBitRot(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "song.ogg")
+	os.WriteFile(path, []byte("not really audio"), 0644)
+	info, _ := os.Stat(path)
+
+	// A checksum from a previous run, for a file with the same size and mtime
+	store := &Store{path: filepath.Join(dir, "checksums.json"), records: map[string]Record{
+		path: {SHA256: "0000", Size: info.Size(), Modified: info.ModTime(), Verified: time.Now()},
+	}}
+
+	result := Verify(context.Background(), []string{path, filepath.Join(dir, "missing.mp3")}, store, nil)
+	if result.Checked != 2 || len(result.Problems) != 2 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if result.Problems[0].Kind != KindChanged {
+		t.Errorf("Expected %s, got %+v", KindChanged, result.Problems[0])
+	}
+	if result.Problems[1].Kind != KindUnreadable {
+		t.Errorf("Expected %s, got %+v", KindUnreadable, result.Problems[1])
+	}
+}
@@ -0,0 +1,206 @@
+// Package itunes reads an iTunes or Music "Library.xml" export and maps its
+// playlists, ratings and play counts onto the songs in a local library
+package itunes
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Track is one song in the iTunes library
+type Track struct {
+	ID        int
+	Name      string
+	Artist    string
+	Album     string
+	Location  string // Local path decoded from the file:// URL, empty if none
+	Rating    int    // Stars, 0 to 5; ratings iTunes worked out from the album are left out
+	PlayCount int
+}
+
+// Playlist is a user playlist, its tracks in order
+type Playlist struct {
+	Name   string
+	Tracks []int // Track IDs
+}
+
+// Library is the part of a Library.xml that carries over
+type Library struct {
+	Tracks    map[int]Track
+	Playlists []Playlist
+}
+
+// Open reads a Library.xml file
+func Open(path string) (*Library, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse reads a Library.xml export. The whole library, built-in lists such
+// as "Music" and playlist folders are left out of the playlists.
+func Parse(r io.Reader) (*Library, error) {
+	decoder := xml.NewDecoder(r)
+	var root any
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid library file: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "dict" {
+			if root, err = decodeValue(decoder, start); err != nil {
+				return nil, fmt.Errorf("invalid library file: %w", err)
+			}
+			break
+		}
+	}
+
+	top, ok := root.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("not an iTunes library file")
+	}
+
+	library := &Library{Tracks: make(map[int]Track)}
+	tracks, _ := top["Tracks"].(map[string]any)
+	for _, value := range tracks {
+		entry, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		track := Track{
+			ID:        intValue(entry["Track ID"]),
+			Name:      stringValue(entry["Name"]),
+			Artist:    stringValue(entry["Artist"]),
+			Album:     stringValue(entry["Album"]),
+			Location:  fileURLPath(stringValue(entry["Location"])),
+			PlayCount: intValue(entry["Play Count"]),
+		}
+		if computed, _ := entry["Rating Computed"].(bool); !computed {
+			track.Rating = intValue(entry["Rating"]) / 20
+		}
+		library.Tracks[track.ID] = track
+	}
+
+	playlists, _ := top["Playlists"].([]any)
+	for _, value := range playlists {
+		entry, ok := value.(map[string]any)
+		if !ok || isBuiltIn(entry) {
+			continue
+		}
+		playlist := Playlist{Name: stringValue(entry["Name"])}
+		items, _ := entry["Playlist Items"].([]any)
+		for _, item := range items {
+			if item, ok := item.(map[string]any); ok {
+				playlist.Tracks = append(playlist.Tracks, intValue(item["Track ID"]))
+			}
+		}
+		library.Playlists = append(library.Playlists, playlist)
+	}
+
+	return library, nil
+}
+
+// isBuiltIn reports whether a playlist is one iTunes keeps by itself rather
+// than one the user made
+func isBuiltIn(entry map[string]any) bool {
+	for _, key := range []string{"Master", "Folder"} {
+		if flag, _ := entry[key].(bool); flag {
+			return true
+		}
+	}
+	_, distinguished := entry["Distinguished Kind"]
+	return distinguished
+}
+
+// decodeValue reads one plist value whose start element has been read
+func decodeValue(decoder *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := make(map[string]any)
+		key := ""
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch token := token.(type) {
+			case xml.StartElement:
+				if token.Name.Local == "key" {
+					if err := decoder.DecodeElement(&key, &token); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				value, err := decodeValue(decoder, token)
+				if err != nil {
+					return nil, err
+				}
+				dict[key] = value
+			case xml.EndElement:
+				return dict, nil
+			}
+		}
+	case "array":
+		var array []any
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch token := token.(type) {
+			case xml.StartElement:
+				value, err := decodeValue(decoder, token)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+			case xml.EndElement:
+				return array, nil
+			}
+		}
+	case "true", "false":
+		if err := decoder.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	default:
+		// Strings, integers, dates and data, kept as text
+		var text string
+		if err := decoder.DecodeElement(&text, &start); err != nil {
+			return nil, err
+		}
+		return text, nil
+	}
+}
+
+// stringValue returns a plist value as a string, empty if it isn't one
+func stringValue(value any) string {
+	text, _ := value.(string)
+	return text
+}
+
+// intValue returns a plist value as an integer, 0 if it isn't one
+func intValue(value any) int {
+	number, _ := strconv.Atoi(strings.TrimSpace(stringValue(value)))
+	return number
+}
+
+// fileURLPath turns a file:// location into a local path
+func fileURLPath(location string) string {
+	parsed, err := url.Parse(location)
+	if err != nil || parsed.Scheme != "file" {
+		return ""
+	}
+	return parsed.Path
+}
@@ -0,0 +1,113 @@
+package itunes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+)
+
+const sampleLibrary = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple Computer//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Major Version</key><integer>1</integer>
+	<key>Tracks</key>
+	<dict>
+		<key>101</key>
+		<dict>
+			<key>Track ID</key><integer>101</integer>
+			<key>Name</key><string>Dancing Queen</string>
+			<key>Artist</key><string>ABBA</string>
+			<key>Play Count</key><integer>12</integer>
+			<key>Rating</key><integer>80</integer>
+			<key>Location</key><string>file:///Users/me/Music/iTunes/ABBA/Dancing%20Queen.mp3</string>
+		</dict>
+		<key>102</key>
+		<dict>
+			<key>Track ID</key><integer>102</integer>
+			<key>Name</key><string>Africa</string>
+			<key>Artist</key><string>Toto</string>
+			<key>Rating</key><integer>60</integer>
+			<key>Rating Computed</key><true/>
+		</dict>
+		<key>103</key>
+		<dict>
+			<key>Track ID</key><integer>103</integer>
+			<key>Name</key><string>Gone</string>
+			<key>Artist</key><string>Nobody</string>
+		</dict>
+	</dict>
+	<key>Playlists</key>
+	<array>
+		<dict>
+			<key>Name</key><string>Library</string>
+			<key>Master</key><true/>
+			<key>Playlist Items</key>
+			<array>
+				<dict><key>Track ID</key><integer>101</integer></dict>
+			</array>
+		</dict>
+		<dict>
+			<key>Name</key><string>Music</string>
+			<key>Distinguished Kind</key><integer>4</integer>
+		</dict>
+		<dict>
+			<key>Name</key><string>Party</string>
+			<key>Playlist Items</key>
+			<array>
+				<dict><key>Track ID</key><integer>102</integer></dict>
+				<dict><key>Track ID</key><integer>103</integer></dict>
+				<dict><key>Track ID</key><integer>101</integer></dict>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>`
+
+func TestParse(t *testing.T) {
+	library, err := Parse(strings.NewReader(sampleLibrary))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	queen := library.Tracks[101]
+	if queen.Location != "/Users/me/Music/iTunes/ABBA/Dancing Queen.mp3" || queen.Rating != 4 || queen.PlayCount != 12 {
+		t.Errorf("Unexpected track: %+v", queen)
+	}
+	if library.Tracks[102].Rating != 0 {
+		t.Errorf("Expected computed ratings to be left out, got %d", library.Tracks[102].Rating)
+	}
+	if len(library.Playlists) != 1 || library.Playlists[0].Name != "Party" || len(library.Playlists[0].Tracks) != 3 {
+		t.Errorf("Expected only the Party playlist, got %+v", library.Playlists)
+	}
+
+	if _, err := Parse(strings.NewReader("<html></html>")); err == nil {
+		t.Error("Expected an error for a file that isn't a library")
+	}
+}
+
+func TestPlan(t *testing.T) {
+	library, err := Parse(strings.NewReader(sampleLibrary))
+	if err != nil {
+		t.Fatal(err)
+	}
+	songs := []*metadata.SongMetadata{
+		{Path: "/music/abba/dancing queen.mp3", Title: "Dancing Queen", Artist: "ABBA"},
+		{Path: "/music/toto/01.mp3", Title: "Africa ", Artist: "TOTO"},
+	}
+
+	migration := Plan(library, songs)
+	if migration.Matched != 2 || len(migration.Unmatched) != 1 || migration.Unmatched[0].ID != 103 {
+		t.Errorf("Expected 103 to be the only unmatched track, got %+v", migration)
+	}
+	if migration.Ratings["/music/abba/dancing queen.mp3"] != 4 || migration.PlayCounts["/music/abba/dancing queen.mp3"] != 12 {
+		t.Errorf("Expected the rating and plays to carry over, got %v %v", migration.Ratings, migration.PlayCounts)
+	}
+
+	party := migration.Playlists[0]
+	want := []string{"/music/toto/01.mp3", "/music/abba/dancing queen.mp3"}
+	if strings.Join(party.Songs, ",") != strings.Join(want, ",") || party.Missing != 1 {
+		t.Errorf("Party = %+v, want songs %v and 1 missing", party, want)
+	}
+}
@@ -0,0 +1,117 @@
+package itunes
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+)
+
+// ImportedPlaylist is an iTunes playlist with its tracks found locally
+type ImportedPlaylist struct {
+	Name    string
+	Songs   []string // Local paths, in playlist order
+	Missing int      // Tracks with no local file
+}
+
+// Migration is what an iTunes library brings to the local one, keyed by
+// local song path
+type Migration struct {
+	Playlists  []ImportedPlaylist
+	Ratings    map[string]int // Stars
+	PlayCounts map[string]int
+	Matched    int
+	Unmatched  []Track
+}
+
+// Match finds the local file for each iTunes track: the same path, then the
+// same file name, then the same artist and title. Tracks matching more than
+// one local song by name or tags are left unmatched rather than guessed.
+func Match(library *Library, songs []*metadata.SongMetadata) map[int]string {
+	byPath := make(map[string]string)
+	byName := make(map[string][]string)
+	byTags := make(map[string][]string)
+	for _, song := range songs {
+		byPath[filepath.Clean(song.Path)] = song.Path
+		name := strings.ToLower(filepath.Base(song.Path))
+		byName[name] = append(byName[name], song.Path)
+		if song.Title != "" {
+			tags := tagKey(song.Artist, song.Title)
+			byTags[tags] = append(byTags[tags], song.Path)
+		}
+	}
+
+	matches := make(map[int]string)
+	for id, track := range library.Tracks {
+		if track.Location != "" {
+			if local, ok := byPath[filepath.Clean(track.Location)]; ok {
+				matches[id] = local
+				continue
+			}
+			// Locations are URLs, so they always use forward slashes
+			if found := byName[strings.ToLower(path.Base(track.Location))]; len(found) == 1 {
+				matches[id] = found[0]
+				continue
+			}
+		}
+		if track.Name != "" {
+			if found := byTags[tagKey(track.Artist, track.Name)]; len(found) == 1 {
+				matches[id] = found[0]
+			}
+		}
+	}
+	return matches
+}
+
+// tagKey is how artist and title are compared, ignoring case and spacing
+func tagKey(artist, title string) string {
+	normalize := func(s string) string {
+		return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	}
+	return normalize(artist) + "\x00" + normalize(title)
+}
+
+// Plan works out what importing library into the local songs would change,
+// without touching anything
+func Plan(library *Library, songs []*metadata.SongMetadata) *Migration {
+	matches := Match(library, songs)
+	migration := &Migration{
+		Ratings:    make(map[string]int),
+		PlayCounts: make(map[string]int),
+		Matched:    len(matches),
+	}
+
+	for id, track := range library.Tracks {
+		local, ok := matches[id]
+		if !ok {
+			migration.Unmatched = append(migration.Unmatched, track)
+			continue
+		}
+		if track.Rating > 0 {
+			migration.Ratings[local] = track.Rating
+		}
+		if track.PlayCount > 0 {
+			migration.PlayCounts[local] += track.PlayCount
+		}
+	}
+
+	sort.Slice(migration.Unmatched, func(i, j int) bool {
+		return migration.Unmatched[i].ID < migration.Unmatched[j].ID
+	})
+
+	for _, playlist := range library.Playlists {
+		imported := ImportedPlaylist{Name: playlist.Name}
+		for _, id := range playlist.Tracks {
+			if local, ok := matches[id]; ok {
+				imported.Songs = append(imported.Songs, local)
+			} else {
+				imported.Missing++
+			}
+		}
+		migration.Playlists = append(migration.Playlists, imported)
+	}
+
+	return migration
+}
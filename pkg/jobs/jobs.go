@@ -0,0 +1,142 @@
+// Package jobs runs long tasks in the background, each reporting how far it
+// has got and each able to be cancelled, so they can all be followed and
+// stopped from one place
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Progress is how far a job has got: Done of Total items, working on
+// Current. Total is 0 while it isn't known.
+type Progress struct {
+	Done    int
+	Total   int
+	Current string
+}
+
+// Job is one task running in the background
+type Job struct {
+	ID      int
+	Name    string
+	Started time.Time
+
+	manager   *Manager
+	cancel    context.CancelFunc
+	done      chan struct{}
+	progress  Progress // guarded by the manager's mutex
+	cancelled bool
+}
+
+// SetProgress records how far the job has got. It may be called from any
+// goroutine.
+func (j *Job) SetProgress(done, total int, current string) {
+	j.manager.mutex.Lock()
+	j.progress = Progress{Done: done, Total: total, Current: current}
+	j.manager.mutex.Unlock()
+	j.manager.changed()
+}
+
+// Progress returns how far the job has got
+func (j *Job) Progress() Progress {
+	j.manager.mutex.Lock()
+	defer j.manager.mutex.Unlock()
+	return j.progress
+}
+
+// Cancel asks the job to stop: its context is cancelled, and the job
+// finishes once it notices
+func (j *Job) Cancel() {
+	j.manager.mutex.Lock()
+	already := j.cancelled
+	j.cancelled = true
+	j.manager.mutex.Unlock()
+
+	j.cancel()
+	if !already {
+		j.manager.changed()
+	}
+}
+
+// Done is closed once the job has finished
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Cancelled reports whether the job has been asked to stop
+func (j *Job) Cancelled() bool {
+	j.manager.mutex.Lock()
+	defer j.manager.mutex.Unlock()
+	return j.cancelled
+}
+
+// Manager starts jobs and keeps track of the ones still running
+type Manager struct {
+	mutex    sync.Mutex
+	nextID   int
+	running  []*Job
+	onChange func()
+}
+
+// NewManager creates a manager that calls onChange, from whichever
+// goroutine made the change, whenever a job starts, reports progress, is
+// cancelled or finishes. onChange may be nil.
+func NewManager(onChange func()) *Manager {
+	return &Manager{onChange: onChange}
+}
+
+// Start runs run in the background as a job called name. run should return
+// soon after ctx is cancelled; the job is listed as running until it does.
+func (m *Manager) Start(name string, run func(ctx context.Context, job *Job)) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.mutex.Lock()
+	m.nextID++
+	job := &Job{ID: m.nextID, Name: name, Started: time.Now(), manager: m, cancel: cancel, done: make(chan struct{})}
+	m.running = append(m.running, job)
+	m.mutex.Unlock()
+	m.changed()
+
+	go func() {
+		defer m.finish(job)
+		run(ctx, job)
+	}()
+	return job
+}
+
+// finish takes a job that has returned off the running list
+func (m *Manager) finish(job *Job) {
+	job.cancel()
+	m.mutex.Lock()
+	for i, running := range m.running {
+		if running == job {
+			m.running = append(m.running[:i], m.running[i+1:]...)
+			break
+		}
+	}
+	m.mutex.Unlock()
+	m.changed()
+	close(job.done)
+}
+
+// Running returns the jobs still running, oldest first
+func (m *Manager) Running() []*Job {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]*Job(nil), m.running...)
+}
+
+// CancelAll asks every running job to stop
+func (m *Manager) CancelAll() {
+	for _, job := range m.Running() {
+		job.Cancel()
+	}
+}
+
+func (m *Manager) changed() {
+	if m.onChange != nil {
+		m.onChange()
+	}
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestManagerStartAndCancel(t *testing.T) {
+	var changes atomic.Int32
+	manager := NewManager(func() { changes.Add(1) })
+
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	job := manager.Start("Reading library", func(ctx context.Context, job *Job) {
+		job.SetProgress(1, 3, "one.mp3")
+		close(started)
+		<-ctx.Done()
+	})
+	<-started
+
+	if running := manager.Running(); len(running) != 1 || running[0] != job {
+		t.Fatalf("Expected the job to be running, got %v", running)
+	}
+	if progress := job.Progress(); progress != (Progress{Done: 1, Total: 3, Current: "one.mp3"}) {
+		t.Errorf("Expected the progress reported, got %+v", progress)
+	}
+
+	// The job is listed until it returns, after being cancelled
+	other := manager.Start("Measuring loudness", func(ctx context.Context, job *Job) {
+		<-finished
+	})
+	job.Cancel()
+	if !job.Cancelled() || other.Cancelled() {
+		t.Error("Expected only the cancelled job to be marked cancelled")
+	}
+	<-job.Done()
+	if running := manager.Running(); running[0] != other {
+		t.Errorf("Expected the other job to carry on, got %v", running)
+	}
+
+	close(finished)
+	<-other.Done()
+	if running := manager.Running(); len(running) != 0 {
+		t.Errorf("Expected no jobs left running, got %v", running)
+	}
+	// Started twice, progress, cancelled, finished twice
+	if got := changes.Load(); got != 6 {
+		t.Errorf("Expected 6 changes reported, got %d", got)
+	}
+}
+
+func TestManagerCancelAll(t *testing.T) {
+	manager := NewManager(nil)
+	var started []*Job
+	for range 3 {
+		started = append(started, manager.Start("wait", func(ctx context.Context, job *Job) {
+			<-ctx.Done()
+		}))
+	}
+	manager.CancelAll()
+	for _, job := range started {
+		<-job.Done()
+	}
+	if running := manager.Running(); len(running) != 0 {
+		t.Errorf("Expected every job to stop, got %v", running)
+	}
+}
@@ -0,0 +1,90 @@
+// Package journal keeps the state of the song being sung on disk while it
+// plays, so a scored run cut short by a crash or quit can be picked up again
+package journal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/paths"
+)
+
+// MaxAge is how old a journal may be and still be offered for resuming
+const MaxAge = 7 * 24 * time.Hour
+
+// Entry is a scored run in progress
+type Entry struct {
+	Path     string        `json:"path"`
+	Title    string        `json:"title"`
+	Position time.Duration `json:"position"`
+	Score    int           `json:"score"`
+	Streak   int           `json:"streak"`
+	Hits     []int         `json:"hits,omitempty"` // Indexes of the lyric lines hit so far
+	Updated  time.Time     `json:"updated"`
+}
+
+// Journal is the file the run in progress is written to
+type Journal struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// New opens the journal in the data directory
+func New() *Journal {
+	return &Journal{path: paths.Data("session.json")}
+}
+
+// Write replaces the journal with entry. It goes to a temporary file first,
+// so a crash while writing leaves the previous entry intact.
+func (j *Journal) Write(entry Entry) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return err
+	}
+	entry.Updated = time.Now()
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp := j.path + ".tmp"
+	if err := os.WriteFile(temp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp, j.path)
+}
+
+// Read returns the run left in the journal, or nil when there is none or it
+// is older than MaxAge
+func (j *Journal) Read() *Entry {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	data, err := os.ReadFile(j.path)
+	if err != nil {
+		return nil
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Path == "" {
+		return nil
+	}
+	if time.Since(entry.Updated) > MaxAge {
+		return nil
+	}
+	return &entry
+}
+
+// Clear empties the journal, e.g. once the song has been sung to the end
+func (j *Journal) Clear() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package journal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	j := &Journal{path: filepath.Join(t.TempDir(), "session.json")}
+	if entry := j.Read(); entry != nil {
+		t.Fatalf("Expected an empty journal, got %+v", entry)
+	}
+
+	err := j.Write(Entry{Path: "/music/waterloo.mp3", Position: 83 * time.Second, Score: 4500, Streak: 3, Hits: []int{0, 2, 3}})
+	if err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	entry := j.Read()
+	if entry == nil || entry.Position != 83*time.Second || entry.Score != 4500 || len(entry.Hits) != 3 {
+		t.Fatalf("Unexpected entry: %+v", entry)
+	}
+
+	// A journal left long ago isn't offered
+	entry.Updated = time.Now().Add(-MaxAge - time.Hour)
+	data, _ := json.Marshal(entry)
+	os.WriteFile(j.path, data, 0644)
+	if entry := j.Read(); entry != nil {
+		t.Errorf("Expected an old journal to be ignored, got %+v", entry)
+	}
+
+	if err := j.Clear(); err != nil {
+		t.Fatalf("Clear() error: %v", err)
+	}
+	if err := j.Clear(); err != nil {
+		t.Errorf("Expected clearing an empty journal to succeed, got %v", err)
+	}
+}
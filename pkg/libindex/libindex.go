@@ -0,0 +1,144 @@
+// Package libindex keeps the metadata of every library file between runs,
+// so a rescan only reads the files that are new or changed since the last
+package libindex
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+	"github.com/tuneminal/tuneminal/pkg/paths"
+)
+
+// readerVersion changes when metadata reads more from files, such as
+// synchronised lyrics, so files indexed before are read again
+const readerVersion = 2
+
+// entry is a file's metadata as it was read, with what identifies that
+// version of the file
+type entry struct {
+	Size    int64                 `json:"size"`
+	ModTime time.Time             `json:"mod_time"`
+	Reader  int                   `json:"reader,omitempty"` // readerVersion it was read with
+	Song    metadata.SongMetadata `json:"song"`
+}
+
+// Index is the metadata database, keyed by file path
+type Index struct {
+	path    string
+	mutex   sync.Mutex
+	entries map[string]entry
+	changed bool
+}
+
+// New opens the index kept as library.json in the cache directory
+func New() *Index {
+	return Open(paths.Cache("library.json"))
+}
+
+// Open opens the index kept at path, starting empty if the file is missing
+// or invalid
+func Open(path string) *Index {
+	index := &Index{path: path, entries: make(map[string]entry)}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &index.entries)
+	}
+	return index
+}
+
+// Scan lists the audio files under dir, returning the metadata of those the
+// index holds for their current size and modification time, and the paths of
+// the rest, which Read brings up to date. Files no longer under dir are
+// forgotten.
+func (x *Index) Scan(dir string) (known []*metadata.SongMetadata, stale []string, err error) {
+	files, err := metadata.ListAudioFiles(dir)
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	found := make(map[string]bool, len(files))
+	for _, path := range files {
+		found[path] = true
+		if song, ok := x.lookup(path); ok {
+			known = append(known, song)
+		} else {
+			stale = append(stale, path)
+		}
+	}
+
+	prefix := filepath.Clean(dir) + string(filepath.Separator)
+	for path := range x.entries {
+		if strings.HasPrefix(path, prefix) && !found[path] {
+			delete(x.entries, path)
+			x.changed = true
+		}
+	}
+	return known, stale, err
+}
+
+// lookup returns the metadata held for path if the file hasn't changed
+// since it was read, nor has what is read (caller must hold the mutex)
+func (x *Index) lookup(path string) (*metadata.SongMetadata, bool) {
+	cached, ok := x.entries[path]
+	if !ok {
+		return nil, false
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() != cached.Size || !info.ModTime().Equal(cached.ModTime) || cached.Reader != readerVersion {
+		return nil, false
+	}
+	song := cached.Song
+	return &song, true
+}
+
+// Cached returns the metadata held for path if the file hasn't changed since
+// it was read, without reading the file
+func (x *Index) Cached(path string) (*metadata.SongMetadata, bool) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	return x.lookup(path)
+}
+
+// Read reads the metadata of the file at path and keeps it in the index
+func (x *Index) Read(path string) (*metadata.SongMetadata, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	song, err := metadata.GetRealMetadata(path)
+	if err != nil {
+		return nil, err
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	x.entries[path] = entry{Size: info.Size(), ModTime: info.ModTime(), Reader: readerVersion, Song: *song}
+	x.changed = true
+	return song, nil
+}
+
+// Save writes the index to disk if anything changed since it was read
+func (x *Index) Save() error {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if !x.changed {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(x.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(x.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(x.path, data, 0644); err != nil {
+		return err
+	}
+	x.changed = false
+	return nil
+}
@@ -0,0 +1,57 @@
+package libindex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScanReadsOnlyChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	library := filepath.Join(dir, "library")
+	os.Mkdir(library, 0755)
+	song := filepath.Join(library, "Artist - Song.aac")
+
+	// Two 44.1kHz ADTS frames
+	frame := []byte{0xFF, 0xF1, 0x50, 0x80, 0x00, 0xFF, 0xFC}
+	if err := os.WriteFile(song, append(frame, frame...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	index := Open(filepath.Join(dir, "library.json"))
+	known, stale, err := index.Scan(library)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(known) != 0 || len(stale) != 1 {
+		t.Fatalf("Expected the new file to need reading, got %d known and %v", len(known), stale)
+	}
+	if _, err := index.Read(song); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh start finds it without reading it again
+	index = Open(filepath.Join(dir, "library.json"))
+	known, stale, _ = index.Scan(library)
+	if len(known) != 1 || len(stale) != 0 || known[0].Title != "Song" || known[0].Artist != "Artist" {
+		t.Fatalf("Expected the song from the index, got %+v and %v", known, stale)
+	}
+
+	// A changed file is read again
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(song, later, later)
+	if _, stale, _ = index.Scan(library); len(stale) != 1 {
+		t.Errorf("Expected the changed file to need reading, got %v", stale)
+	}
+
+	// A deleted file is forgotten
+	os.Remove(song)
+	index.Scan(library)
+	if len(index.entries) != 0 {
+		t.Errorf("Expected the deleted file to be dropped, got %v", index.entries)
+	}
+}
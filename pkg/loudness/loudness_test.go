@@ -0,0 +1,93 @@
+package loudness
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+// sine is a stereo test tone
+type sine struct {
+	amplitude float64
+	rate      float64
+	frames    int
+	pos       int
+}
+
+func (s *sine) Stream(samples [][2]float64) (int, bool) {
+	if s.pos >= s.frames {
+		return 0, false
+	}
+	n := 0
+	for ; n < len(samples) && s.pos < s.frames; n++ {
+		value := s.amplitude * math.Sin(2*math.Pi*1000*float64(s.pos)/s.rate)
+		samples[n] = [2]float64{value, value}
+		s.pos++
+	}
+	return n, true
+}
+
+func (s *sine) Err() error       { return nil }
+func (s *sine) Len() int         { return s.frames }
+func (s *sine) Position() int    { return s.pos }
+func (s *sine) Seek(p int) error { s.pos = p; return nil }
+func (s *sine) Close() error     { return nil }
+
+func TestMeasureSine(t *testing.T) {
+	// A full-scale 1kHz sine in both channels reads 0 LUFS, so half scale is -6
+	format := beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}
+	result, err := Measure(&sine{amplitude: 0.5, rate: 48000, frames: 48000 * 5}, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(result.Loudness+6.02) > 0.1 {
+		t.Errorf("Loudness = %.2f LUFS, want about -6.02", result.Loudness)
+	}
+	if math.Abs(result.Gain-(ReferenceLUFS+6.02)) > 0.1 {
+		t.Errorf("Gain = %.2f dB, want about %.2f", result.Gain, ReferenceLUFS+6.02)
+	}
+}
+
+func TestFactorAvoidsClipping(t *testing.T) {
+	if factor := (Result{Gain: 6, Peak: 0.9}).Factor(); math.Abs(factor-1/0.9) > 1e-9 {
+		t.Errorf("Factor() = %v, want it limited to the peak (%v)", factor, 1/0.9)
+	}
+	if factor := (Result{Gain: -6, Peak: 1}).Factor(); math.Abs(factor-0.501) > 0.001 {
+		t.Errorf("Factor() = %v, want about 0.501", factor)
+	}
+}
+
+func TestScanSkipsCurrentFiles(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.wav")
+	bad := filepath.Join(dir, "bad.wav")
+	os.WriteFile(good, []byte("audio"), 0644)
+	os.WriteFile(bad, []byte("audio"), 0644)
+
+	decodes := 0
+	decode := func(path string) (beep.StreamSeekCloser, beep.Format, error) {
+		if path == bad {
+			return nil, beep.Format{}, errors.New("corrupt")
+		}
+		decodes++
+		return &sine{amplitude: 0.25, rate: 44100, frames: 44100}, beep.Format{SampleRate: 44100, NumChannels: 2}, nil
+	}
+
+	store := &Store{path: filepath.Join(dir, "loudness.json"), entries: make(map[string]Entry)}
+	result := Scan(t.Context(), []string{good, bad}, store, decode, ScanOptions{Workers: 2})
+	if result.Measured != 1 || len(result.Failed) != 1 || result.Failed[bad] == nil {
+		t.Fatalf("Unexpected scan result %+v", result)
+	}
+	if _, ok := store.Get(good); !ok {
+		t.Error("Measurement was not stored")
+	}
+
+	result = Scan(t.Context(), []string{good}, store, decode, ScanOptions{})
+	if result.Current != 1 || decodes != 1 {
+		t.Errorf("Unchanged file was measured again: %+v", result)
+	}
+}
@@ -0,0 +1,184 @@
+// Package loudness measures how loud tracks are (EBU R128, as used by
+// ReplayGain 2.0) and remembers the gain each one needs so the library plays
+// at an even level
+package loudness
+
+import (
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// ReferenceLUFS is the level tracks are brought to, the ReplayGain 2.0 reference
+const ReferenceLUFS = -18.0
+
+// maxGain limits how far a track is turned up or down, in dB
+const maxGain = 24.0
+
+// Gating thresholds from EBU R128
+const (
+	absoluteGate = -70.0 // LUFS
+	relativeGate = -10.0 // LU below the ungated loudness
+)
+
+// Result is the measured loudness of a track and the gain it needs
+type Result struct {
+	Loudness float64 `json:"loudness"` // Integrated loudness in LUFS
+	Peak     float64 `json:"peak"`     // Highest sample level, 1.0 is full scale
+	Gain     float64 `json:"gain"`     // dB to reach ReferenceLUFS
+}
+
+// Factor returns the gain as a linear multiplier, lowered if needed so the
+// track's peak doesn't clip
+func (r Result) Factor() float64 {
+	factor := math.Pow(10, r.Gain/20)
+	if r.Peak > 0 && r.Peak*factor > 1 {
+		factor = 1 / r.Peak
+	}
+	return factor
+}
+
+// biquad is one second-order IIR filter stage
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// kWeighting returns the two BS.1770 K-weighting stages (a high shelf
+// modelling the head, then a high pass) for a sample rate
+func kWeighting(sampleRate float64) [2]biquad {
+	// Shelf
+	f0, gain, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / sampleRate)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	// High pass
+	f0, q = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / sampleRate)
+	a0 = 1 + k/q + k*k
+	highPass := biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	return [2]biquad{shelf, highPass}
+}
+
+// Meter measures integrated loudness from frames written to it
+type Meter struct {
+	channels int
+	filters  [2][2]biquad // Per channel
+	peak     float64
+
+	// Energy is summed over 100ms steps; a gating block is four steps
+	stepFrames int
+	stepCount  int
+	stepSum    float64
+	steps      []float64 // Mean square of the last four steps
+	blocks     []float64 // Mean square of every 400ms block
+}
+
+// NewMeter creates a meter for audio at sampleRate with 1 or 2 channels
+func NewMeter(sampleRate beep.SampleRate, channels int) *Meter {
+	if channels < 1 || channels > 2 {
+		channels = 2
+	}
+	filters := kWeighting(float64(sampleRate))
+	return &Meter{
+		channels:   channels,
+		filters:    [2][2]biquad{filters, filters},
+		stepFrames: sampleRate.N(100 * time.Millisecond),
+	}
+}
+
+// Write adds frames to the measurement
+func (m *Meter) Write(frames [][2]float64) {
+	for _, frame := range frames {
+		for c := 0; c < m.channels; c++ {
+			m.peak = math.Max(m.peak, math.Abs(frame[c]))
+			sample := m.filters[c][0].process(frame[c])
+			sample = m.filters[c][1].process(sample)
+			m.stepSum += sample * sample
+		}
+
+		m.stepCount++
+		if m.stepCount < m.stepFrames {
+			continue
+		}
+		m.steps = append(m.steps, m.stepSum/float64(m.stepFrames))
+		m.stepSum, m.stepCount = 0, 0
+		if len(m.steps) > 4 {
+			m.steps = m.steps[1:]
+		}
+		if len(m.steps) == 4 {
+			m.blocks = append(m.blocks, (m.steps[0]+m.steps[1]+m.steps[2]+m.steps[3])/4)
+		}
+	}
+}
+
+// blockLoudness converts a block's mean square to LUFS
+func blockLoudness(meanSquare float64) float64 {
+	if meanSquare <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(meanSquare)
+}
+
+// Result returns the gated integrated loudness, the peak and the gain to the
+// reference level. A silent or very short track gets no gain.
+func (m *Meter) Result() Result {
+	gated := func(threshold float64) (float64, int) {
+		sum, count := 0.0, 0
+		for _, block := range m.blocks {
+			if blockLoudness(block) > threshold {
+				sum += block
+				count++
+			}
+		}
+		return sum, count
+	}
+
+	sum, count := gated(absoluteGate)
+	if count == 0 {
+		return Result{Loudness: absoluteGate, Peak: m.peak}
+	}
+	threshold := blockLoudness(sum/float64(count)) + relativeGate
+	sum, count = gated(threshold)
+	loudness := blockLoudness(sum / float64(count))
+
+	gain := math.Max(-maxGain, math.Min(maxGain, ReferenceLUFS-loudness))
+	return Result{Loudness: loudness, Peak: m.peak, Gain: gain}
+}
+
+// Measure streams a whole track through a meter
+func Measure(streamer beep.Streamer, format beep.Format) (Result, error) {
+	meter := NewMeter(format.SampleRate, format.NumChannels)
+	buf := make([][2]float64, 4096)
+	for {
+		n, ok := streamer.Stream(buf)
+		meter.Write(buf[:n])
+		if !ok {
+			break
+		}
+	}
+	return meter.Result(), streamer.Err()
+}
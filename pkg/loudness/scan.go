@@ -0,0 +1,109 @@
+package loudness
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/faiface/beep"
+)
+
+// Decoder opens an audio file as a stream, e.g. player.Decode
+type Decoder func(path string) (beep.StreamSeekCloser, beep.Format, error)
+
+// ScanOptions controls a library scan
+type ScanOptions struct {
+	Workers int  // Files measured at once, 0 for one per CPU
+	Force   bool // Measure files again even if they haven't changed
+
+	// OnProgress is called after each file, from the worker that measured it
+	OnProgress func(done, total int, path string)
+}
+
+// ScanResult summarises a library scan
+type ScanResult struct {
+	Measured int
+	Current  int              // Already measured and unchanged
+	Failed   map[string]error // Files that couldn't be read or decoded
+}
+
+// Scan measures every file that hasn't been measured since it last changed,
+// several at a time, and records the results in store
+func Scan(ctx context.Context, files []string, store *Store, decode Decoder, opts ScanOptions) *ScanResult {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	result := &ScanResult{Failed: make(map[string]error)}
+	var mutex sync.Mutex
+	done := 0
+	finish := func(path string, err error, measured bool) {
+		mutex.Lock()
+		switch {
+		case err != nil:
+			result.Failed[path] = err
+		case measured:
+			result.Measured++
+		default:
+			result.Current++
+		}
+		done++
+		progress := done
+		mutex.Unlock()
+
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress, len(files), path)
+		}
+	}
+
+	paths := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				measured, err := scanFile(path, store, decode, opts.Force)
+				finish(path, err, measured)
+			}
+		}()
+	}
+
+	for _, path := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		paths <- path
+	}
+	close(paths)
+	wg.Wait()
+	return result
+}
+
+// scanFile measures one file unless its stored measurement is still current
+func scanFile(path string, store *Store, decode Decoder, force bool) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if !force {
+		if _, ok := store.Get(path); ok {
+			return false, nil
+		}
+	}
+
+	streamer, format, err := decode(path)
+	if err != nil {
+		return false, err
+	}
+	defer streamer.Close()
+
+	measurement, err := Measure(streamer, format)
+	if err != nil {
+		return false, err
+	}
+	store.set(path, measurement, info)
+	return true, nil
+}
@@ -0,0 +1,126 @@
+package loudness
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/paths"
+)
+
+// Entry is a stored measurement and the file state it was taken from
+type Entry struct {
+	Result
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Scanned  time.Time `json:"scanned"`
+}
+
+// Store remembers measurements between runs. A store picks up changes
+// saved by another process, e.g. a scan run from the command line while the
+// player is open.
+type Store struct {
+	path    string
+	mutex   sync.Mutex
+	entries map[string]Entry
+	loaded  time.Time // Modification time of the file when last read or written
+}
+
+// NewStore creates a loudness store backed by loudness.json in the cache directory
+func NewStore() *Store {
+	store := &Store{
+		path:    paths.Cache("loudness.json"),
+		entries: make(map[string]Entry),
+	}
+	store.reload()
+	return store
+}
+
+// reload reads the file again if it changed since it was last read or
+// written (caller must hold the mutex unless the store isn't shared yet)
+func (s *Store) reload() {
+	info, err := os.Stat(s.path)
+	if err != nil || info.ModTime().Equal(s.loaded) {
+		return
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return
+	}
+	entries := make(map[string]Entry)
+	if json.Unmarshal(data, &entries) == nil {
+		s.entries = entries
+		s.loaded = info.ModTime()
+	}
+}
+
+// Save writes the measurements to disk
+func (s *Store) Save() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return err
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.loaded = info.ModTime()
+	}
+	return nil
+}
+
+// Get returns the measurement for a file, if there is one and the file
+// hasn't changed since
+func (s *Store) Get(path string) (Result, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Result{}, false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.reload()
+	entry, ok := s.entries[path]
+	if !ok || !entry.current(info) {
+		return Result{}, false
+	}
+	return entry.Result, true
+}
+
+// set records a measurement for a file in the state given by info
+func (s *Store) set(path string, result Result, info os.FileInfo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries[path] = Entry{
+		Result:   result,
+		Size:     info.Size(),
+		Modified: info.ModTime(),
+		Scanned:  time.Now(),
+	}
+}
+
+// current reports whether the entry was measured from the file as it is now
+func (e Entry) current(info os.FileInfo) bool {
+	return e.Size == info.Size() && e.Modified.Equal(info.ModTime())
+}
+
+// Rename moves a measurement to a file's new path
+func (s *Store) Rename(oldPath, newPath string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if entry, ok := s.entries[oldPath]; ok {
+		delete(s.entries, oldPath)
+		s.entries[newPath] = entry
+	}
+}
@@ -0,0 +1,151 @@
+package lyrics
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// BatchProgress reports how far a batch fetch has got
+type BatchProgress struct {
+	Total     int
+	Done      int
+	Current   string // Title of the song being fetched
+	Succeeded int
+	NotFound  int
+	Failed    int
+}
+
+// BatchResult is the outcome of fetching lyrics for one song
+type BatchResult struct {
+	Song     SongInfo
+	Path     string // Lyrics file written, if any
+	Provider string
+	Err      error
+}
+
+// BatchFetcher fetches lyrics for many songs, politely
+type BatchFetcher struct {
+	Providers  []Provider
+	Interval   time.Duration // Minimum time between requests
+	Retries    int           // Extra attempts for retryable failures
+	RetryDelay time.Duration // First retry delay, doubled on each attempt
+	OnProgress func(BatchProgress)
+	CacheDir   string // Where synced lyrics go when the song's folder can't be written, "" for nowhere
+
+	lastRequest time.Time
+}
+
+// NewBatchFetcher creates a batch fetcher with conservative rate limits
+func NewBatchFetcher(providers ...Provider) *BatchFetcher {
+	return &BatchFetcher{
+		Providers:  providers,
+		Interval:   time.Second,
+		Retries:    2,
+		RetryDelay: 2 * time.Second,
+	}
+}
+
+// Run fetches lyrics for each song in turn and saves them next to the audio
+// file, or in CacheDir when that can't be written. It stops early when ctx
+// is cancelled.
+func (b *BatchFetcher) Run(ctx context.Context, songs []SongInfo) []BatchResult {
+	progress := BatchProgress{Total: len(songs)}
+	results := make([]BatchResult, 0, len(songs))
+
+	for _, song := range songs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		progress.Current = song.Title
+		b.report(progress)
+
+		result := b.fetchSong(ctx, song)
+		switch {
+		case result.Err == nil:
+			progress.Succeeded++
+		case errors.Is(result.Err, ErrNotFound):
+			progress.NotFound++
+		default:
+			progress.Failed++
+		}
+		results = append(results, result)
+
+		progress.Done++
+		b.report(progress)
+	}
+
+	progress.Current = ""
+	b.report(progress)
+	return results
+}
+
+// fetchSong tries each provider in order until one has lyrics
+func (b *BatchFetcher) fetchSong(ctx context.Context, song SongInfo) BatchResult {
+	result := BatchResult{Song: song, Err: ErrNotFound}
+
+	for _, provider := range b.Providers {
+		fetched, err := b.fetchWithRetry(ctx, provider, song)
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				result.Err = err
+			}
+			continue
+		}
+
+		path, err := SaveFetched(song.Path, fetched)
+		if err != nil && b.CacheDir != "" && fetched.Synced != "" {
+			path, err = CacheFetched(b.CacheDir, song, fetched)
+		}
+		return BatchResult{Song: song, Path: path, Provider: provider.Name(), Err: err}
+	}
+
+	return result
+}
+
+// fetchWithRetry calls a provider, waiting for the rate limit and retrying
+// retryable failures with exponential backoff
+func (b *BatchFetcher) fetchWithRetry(ctx context.Context, provider Provider, song SongInfo) (*FetchResult, error) {
+	delay := b.RetryDelay
+	for attempt := 0; ; attempt++ {
+		if err := b.wait(ctx, time.Until(b.lastRequest.Add(b.Interval))); err != nil {
+			return nil, err
+		}
+		b.lastRequest = time.Now()
+
+		result, err := provider.Fetch(ctx, song)
+		var retryable *RetryableError
+		if err == nil || !errors.As(err, &retryable) || attempt >= b.Retries {
+			return result, err
+		}
+
+		if err := b.wait(ctx, delay); err != nil {
+			return nil, err
+		}
+		delay *= 2
+	}
+}
+
+// wait sleeps for d unless ctx is cancelled first
+func (b *BatchFetcher) wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// report passes progress to the callback, if any
+func (b *BatchFetcher) report(progress BatchProgress) {
+	if b.OnProgress != nil {
+		b.OnProgress(progress)
+	}
+}
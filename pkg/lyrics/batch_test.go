@@ -0,0 +1,67 @@
+package lyrics
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeProvider fails a set number of times before answering
+type fakeProvider struct {
+	failures int
+	calls    int
+	result   *FetchResult
+}
+
+func (f *fakeProvider) Name() string { return "fake" }
+
+func (f *fakeProvider) Fetch(ctx context.Context, song SongInfo) (*FetchResult, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, &RetryableError{Err: errors.New("busy")}
+	}
+	if f.result == nil {
+		return nil, ErrNotFound
+	}
+	return f.result, nil
+}
+
+func TestBatchFetcher(t *testing.T) {
+	dir := t.TempDir()
+	songs := []SongInfo{
+		{Path: filepath.Join(dir, "one.mp3"), Title: "One"},
+	}
+
+	provider := &fakeProvider{failures: 2, result: &FetchResult{Synced: "[00:01.00]Hello\n"}}
+	fetcher := NewBatchFetcher(provider)
+	fetcher.Interval = time.Millisecond
+	fetcher.RetryDelay = time.Millisecond
+
+	var last BatchProgress
+	fetcher.OnProgress = func(p BatchProgress) { last = p }
+
+	results := fetcher.Run(context.Background(), songs)
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	if provider.calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", provider.calls)
+	}
+	if last.Done != 1 || last.Succeeded != 1 {
+		t.Errorf("Unexpected final progress: %+v", last)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "one.lrc")); err != nil {
+		t.Errorf("Lyrics file not written: %v", err)
+	}
+
+	// Not found is reported but not retried
+	missing := &fakeProvider{}
+	fetcher.Providers = []Provider{missing}
+	results = fetcher.Run(context.Background(), songs)
+	if !errors.Is(results[0].Err, ErrNotFound) || missing.calls != 1 {
+		t.Errorf("Expected a single not-found attempt, got %+v after %d calls", results[0], missing.calls)
+	}
+}
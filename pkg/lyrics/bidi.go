@@ -0,0 +1,212 @@
+package lyrics
+
+import (
+	"slices"
+	"unicode"
+)
+
+// Terminals put characters down left to right in the order they come, so
+// Hebrew and Arabic lyrics, stored in reading order, come out backwards and
+// Arabic letters stand apart instead of joining. Visual does the reordering
+// and joining a bidi-aware renderer would do, for one line at a time.
+
+// rtlScripts are the scripts written right to left
+var rtlScripts = []*unicode.RangeTable{unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko}
+
+// mirrored are the characters drawn the other way round in right-to-left text
+var mirrored = map[rune]rune{'(': ')', ')': '(', '<': '>', '>': '<', '{': '}', '}': '{', '«': '»', '»': '«'}
+
+// isRTLRune reports whether r is a letter of a right-to-left script
+func isRTLRune(r rune) bool {
+	return unicode.IsLetter(r) && unicode.In(r, rtlScripts...)
+}
+
+// IsRTL reports whether a line reads right to left: its first letter is
+// from a right-to-left script such as Hebrew or Arabic
+func IsRTL(text string) bool {
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			return isRTLRune(r)
+		}
+	}
+	return false
+}
+
+// Visual returns a right-to-left line in the order a terminal should draw
+// it, left to right, with Arabic letters joined. Runs of left-to-right text
+// such as numbers and Latin words keep their own order within it.
+// Left-to-right lines come back as they are.
+func Visual(text string) string {
+	if !IsRTL(text) {
+		return text
+	}
+	runes := []rune(shapeArabic(text))
+
+	// Each character is right to left, left to right or neutral; neutrals
+	// between two runs of the same direction go with them, and the rest with
+	// the line
+	rtl := make([]bool, len(runes))
+	strong := make([]int, len(runes)) // 1 right to left, -1 left to right, 0 neutral
+	for i, r := range runes {
+		switch {
+		case isRTLRune(r) || unicode.Is(unicode.Mn, r) && i > 0 && strong[i-1] == 1:
+			strong[i] = 1
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			strong[i] = -1
+		}
+	}
+	for i := range runes {
+		if strong[i] != 0 {
+			rtl[i] = strong[i] == 1
+			continue
+		}
+		before, after := 1, 1
+		for j := i - 1; j >= 0; j-- {
+			if strong[j] != 0 {
+				before = strong[j]
+				break
+			}
+		}
+		for j := i + 1; j < len(runes); j++ {
+			if strong[j] != 0 {
+				after = strong[j]
+				break
+			}
+		}
+		rtl[i] = before == 1 || after == 1
+	}
+
+	// The runs go right to left, and the characters in a right-to-left run too
+	visual := make([]rune, 0, len(runes))
+	for end := len(runes); end > 0; {
+		start := end - 1
+		for start > 0 && rtl[start-1] == rtl[end-1] {
+			start--
+		}
+		run := slices.Clone(runes[start:end])
+		if rtl[start] {
+			slices.Reverse(run)
+			for i, r := range run {
+				if m, ok := mirrored[r]; ok {
+					run[i] = m
+				}
+			}
+		}
+		visual = append(visual, run...)
+		end = start
+	}
+	return string(visual)
+}
+
+// arabicForms are the isolated, final, initial and medial presentation forms
+// of the Arabic and Persian letters, 0 where a letter has none. Letters with
+// no initial form only join to the letter before them.
+var arabicForms = map[rune][4]rune{
+	'ء': {0xFE80, 0, 0, 0},
+	'آ': {0xFE81, 0xFE82, 0, 0},
+	'أ': {0xFE83, 0xFE84, 0, 0},
+	'ؤ': {0xFE85, 0xFE86, 0, 0},
+	'إ': {0xFE87, 0xFE88, 0, 0},
+	'ئ': {0xFE89, 0xFE8A, 0xFE8B, 0xFE8C},
+	'ا': {0xFE8D, 0xFE8E, 0, 0},
+	'ب': {0xFE8F, 0xFE90, 0xFE91, 0xFE92},
+	'ة': {0xFE93, 0xFE94, 0, 0},
+	'ت': {0xFE95, 0xFE96, 0xFE97, 0xFE98},
+	'ث': {0xFE99, 0xFE9A, 0xFE9B, 0xFE9C},
+	'ج': {0xFE9D, 0xFE9E, 0xFE9F, 0xFEA0},
+	'ح': {0xFEA1, 0xFEA2, 0xFEA3, 0xFEA4},
+	'خ': {0xFEA5, 0xFEA6, 0xFEA7, 0xFEA8},
+	'د': {0xFEA9, 0xFEAA, 0, 0},
+	'ذ': {0xFEAB, 0xFEAC, 0, 0},
+	'ر': {0xFEAD, 0xFEAE, 0, 0},
+	'ز': {0xFEAF, 0xFEB0, 0, 0},
+	'س': {0xFEB1, 0xFEB2, 0xFEB3, 0xFEB4},
+	'ش': {0xFEB5, 0xFEB6, 0xFEB7, 0xFEB8},
+	'ص': {0xFEB9, 0xFEBA, 0xFEBB, 0xFEBC},
+	'ض': {0xFEBD, 0xFEBE, 0xFEBF, 0xFEC0},
+	'ط': {0xFEC1, 0xFEC2, 0xFEC3, 0xFEC4},
+	'ظ': {0xFEC5, 0xFEC6, 0xFEC7, 0xFEC8},
+	'ع': {0xFEC9, 0xFECA, 0xFECB, 0xFECC},
+	'غ': {0xFECD, 0xFECE, 0xFECF, 0xFED0},
+	'ـ': {0x0640, 0x0640, 0x0640, 0x0640},
+	'ف': {0xFED1, 0xFED2, 0xFED3, 0xFED4},
+	'ق': {0xFED5, 0xFED6, 0xFED7, 0xFED8},
+	'ك': {0xFED9, 0xFEDA, 0xFEDB, 0xFEDC},
+	'ل': {0xFEDD, 0xFEDE, 0xFEDF, 0xFEE0},
+	'م': {0xFEE1, 0xFEE2, 0xFEE3, 0xFEE4},
+	'ن': {0xFEE5, 0xFEE6, 0xFEE7, 0xFEE8},
+	'ه': {0xFEE9, 0xFEEA, 0xFEEB, 0xFEEC},
+	'و': {0xFEED, 0xFEEE, 0, 0},
+	'ى': {0xFEEF, 0xFEF0, 0, 0},
+	'ي': {0xFEF1, 0xFEF2, 0xFEF3, 0xFEF4},
+	'پ': {0xFB56, 0xFB57, 0xFB58, 0xFB59},
+	'چ': {0xFB7A, 0xFB7B, 0xFB7C, 0xFB7D},
+	'ژ': {0xFB8A, 0xFB8B, 0, 0},
+	'ک': {0xFB8E, 0xFB8F, 0xFB90, 0xFB91},
+	'گ': {0xFB92, 0xFB93, 0xFB94, 0xFB95},
+	'ی': {0xFBFC, 0xFBFD, 0xFBFE, 0xFBFF},
+}
+
+// lamAlef are the isolated and final forms of lam joined with each alef
+var lamAlef = map[rune][2]rune{
+	'آ': {0xFEF5, 0xFEF6},
+	'أ': {0xFEF7, 0xFEF8},
+	'إ': {0xFEF9, 0xFEFA},
+	'ا': {0xFEFB, 0xFEFC},
+}
+
+// shapeArabic replaces Arabic letters with the forms that join them to their
+// neighbors, still in reading order. Vowel marks don't break a join.
+func shapeArabic(text string) string {
+	runes := []rune(text)
+	// neighbor returns the letter step places along from i, past any marks
+	neighbor := func(i, step int) rune {
+		for j := i + step; j >= 0 && j < len(runes); j += step {
+			if !unicode.Is(unicode.Mn, runes[j]) {
+				return runes[j]
+			}
+		}
+		return 0
+	}
+	joinsForward := func(r rune) bool {
+		return arabicForms[r][2] != 0
+	}
+
+	shaped := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		forms, ok := arabicForms[r]
+		if !ok {
+			shaped = append(shaped, r)
+			continue
+		}
+		joinsPrevious := joinsForward(neighbor(i, -1)) && forms[1] != 0
+
+		// Lam followed straight by alef is written as one letter
+		if r == 'ل' && i+1 < len(runes) {
+			if ligature, ok := lamAlef[runes[i+1]]; ok {
+				if joinsPrevious {
+					shaped = append(shaped, ligature[1])
+				} else {
+					shaped = append(shaped, ligature[0])
+				}
+				i++
+				continue
+			}
+		}
+
+		_, nextIsArabic := arabicForms[neighbor(i, 1)]
+		joinsNext := joinsForward(r) && nextIsArabic
+		switch {
+		case joinsPrevious && joinsNext:
+			shaped = append(shaped, forms[3])
+		case joinsPrevious:
+			shaped = append(shaped, forms[1])
+		case joinsNext:
+			shaped = append(shaped, forms[2])
+		default:
+			shaped = append(shaped, forms[0])
+		}
+	}
+	return string(shaped)
+}
@@ -0,0 +1,125 @@
+package lyrics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Status describes what kind of lyrics a song has
+type Status int
+
+const (
+	// Missing means no lyrics file was found
+	Missing Status = iota
+	// Unsynced means there are lyrics but no timestamps
+	Unsynced
+	// Synced means there is an LRC file with timed lines
+	Synced
+)
+
+// String returns a human-readable status
+func (s Status) String() string {
+	switch s {
+	case Synced:
+		return "synced"
+	case Unsynced:
+		return "unsynced"
+	default:
+		return "missing"
+	}
+}
+
+// FindSidecar finds a lyrics file with the given extension (e.g. ".lrc") next
+// to an audio file, trying common naming variations
+func FindSidecar(audioPath, ext string) string {
+	audioExt := filepath.Ext(audioPath)
+	baseName := strings.TrimSuffix(filepath.Base(audioPath), audioExt)
+	dir := filepath.Dir(audioPath)
+
+	candidates := []string{
+		strings.TrimSuffix(audioPath, audioExt) + ext,
+		filepath.Join(dir, strings.ReplaceAll(baseName, "_", " ")+ext),
+		filepath.Join(dir, strings.ReplaceAll(baseName, "_", "-")+ext),
+	}
+
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// CheckSong reports the lyric status of an audio file and the lyrics file found
+func CheckSong(audioPath string) (Status, string) {
+	if lrcPath := FindSidecar(audioPath, ".lrc"); lrcPath != "" {
+		l, err := LoadFile(lrcPath)
+		if err == nil && len(l.Lines) > 0 {
+			return Synced, lrcPath
+		}
+		// An LRC without time tags is plain text in disguise
+		if data, err := os.ReadFile(lrcPath); err == nil && len(PlainLines(string(data))) > 0 {
+			return Unsynced, lrcPath
+		}
+	}
+
+	if txtPath := FindSidecar(audioPath, ".txt"); txtPath != "" {
+		if data, err := os.ReadFile(txtPath); err == nil && len(PlainLines(string(data))) > 0 {
+			return Unsynced, txtPath
+		}
+	}
+
+	return Missing, ""
+}
+
+// CoverageReport groups songs by lyric status
+type CoverageReport struct {
+	Synced   []string
+	Unsynced []string
+	Missing  []string
+
+	// Songs with lyrics per language code, "" for undetected
+	Languages map[string]int
+}
+
+// Total returns the number of songs in the report
+func (r *CoverageReport) Total() int {
+	return len(r.Synced) + len(r.Unsynced) + len(r.Missing)
+}
+
+// Percent returns the share of songs with the given status, 0-100
+func (r *CoverageReport) Percent(status Status) float64 {
+	if r.Total() == 0 {
+		return 0
+	}
+
+	count := len(r.Missing)
+	switch status {
+	case Synced:
+		count = len(r.Synced)
+	case Unsynced:
+		count = len(r.Unsynced)
+	}
+	return float64(count) * 100 / float64(r.Total())
+}
+
+// Coverage checks the lyric status of every audio file
+func Coverage(audioPaths []string) *CoverageReport {
+	report := &CoverageReport{Languages: make(map[string]int)}
+	for _, path := range audioPaths {
+		status, lyricsPath := CheckSong(path)
+		if status != Missing {
+			report.Languages[FileLanguage(lyricsPath)]++
+		}
+		switch status {
+		case Synced:
+			report.Synced = append(report.Synced, path)
+		case Unsynced:
+			report.Unsynced = append(report.Unsynced, path)
+		default:
+			report.Missing = append(report.Missing, path)
+		}
+	}
+	return report
+}
@@ -0,0 +1,91 @@
+package lyrics
+
+import (
+	"regexp"
+	"strings"
+)
+
+// roleRegex matches the singer prefix duet LRC files put before a line:
+// P1:/P2: or M:/F: for one singer and D:/Both: for both
+var roleRegex = regexp.MustCompile(`^(?i)(p1|p2|m|f|d|both):\s*`)
+
+// Role says who sings a line of a duet
+type Role int
+
+const (
+	// BothRoles lines are sung together, as are lines before any prefix
+	BothRoles Role = iota
+	// FirstRole lines are sung by the first singer (P1: or M:)
+	FirstRole
+	// SecondRole lines are sung by the second singer (P2: or F:)
+	SecondRole
+)
+
+// Duet is lyrics split by singer. All has every line with the prefixes
+// removed; Parts[0] and Parts[1] hold each singer's lines, with lines sung
+// together in both, so each part can be followed on its own.
+type Duet struct {
+	Names [2]string
+	Roles []Role // Role of each line in All
+	All   *Lyrics
+	Parts [2]*Lyrics
+}
+
+// SplitDuet splits lyrics by their singer prefixes, or returns nil when no
+// line names a singer. A prefix holds for the following lines until the next
+// one, so only the lines where the singer changes need it.
+func SplitDuet(l *Lyrics) *Duet {
+	duet := &Duet{
+		Names: [2]string{"Singer 1", "Singer 2"},
+		All:   &Lyrics{Tags: l.Tags, Offset: l.Offset},
+		Parts: [2]*Lyrics{
+			{Tags: l.Tags, Offset: l.Offset},
+			{Tags: l.Tags, Offset: l.Offset},
+		},
+	}
+
+	role, found := BothRoles, false
+	for _, line := range l.Lines {
+		if m := roleRegex.FindStringSubmatch(line.Text); m != nil {
+			found = true
+			line.Text = strings.TrimSpace(line.Text[len(m[0]):])
+			switch strings.ToLower(m[1]) {
+			case "p1":
+				role = FirstRole
+			case "m":
+				role = FirstRole
+				duet.Names[0] = "Male"
+			case "p2":
+				role = SecondRole
+			case "f":
+				role = SecondRole
+				duet.Names[1] = "Female"
+			default:
+				role = BothRoles
+			}
+		}
+
+		// Breaks clear both parts
+		lineRole := role
+		if line.Text == "" {
+			lineRole = BothRoles
+		}
+
+		duet.All.Lines = append(duet.All.Lines, line)
+		duet.Roles = append(duet.Roles, lineRole)
+		for part := range duet.Parts {
+			if lineRole == BothRoles || lineRole == Role(part+1) {
+				duet.Parts[part].Lines = append(duet.Parts[part].Lines, line)
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	duet.All.sortLines()
+	for _, part := range duet.Parts {
+		part.sortLines()
+	}
+	return duet
+}
@@ -0,0 +1,133 @@
+package lyrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by providers that have no lyrics for a song
+var ErrNotFound = errors.New("lyrics not found")
+
+// SongInfo identifies a song to a lyrics provider
+type SongInfo struct {
+	Path     string
+	Title    string
+	Artist   string
+	Album    string
+	Duration time.Duration
+}
+
+// FetchResult is what a provider returns: synced LRC text, plain text, or both
+type FetchResult struct {
+	Synced string
+	Plain  string
+}
+
+// Provider is an online lyrics source
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context, song SongInfo) (*FetchResult, error)
+}
+
+// LRCLibProvider fetches lyrics from lrclib.net
+type LRCLibProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewLRCLibProvider creates a provider for the public lrclib.net API
+func NewLRCLibProvider() *LRCLibProvider {
+	return &LRCLibProvider{
+		BaseURL: "https://lrclib.net",
+		Client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name returns the provider name
+func (p *LRCLibProvider) Name() string {
+	return "lrclib"
+}
+
+// Fetch looks a song up by title, artist and duration
+func (p *LRCLibProvider) Fetch(ctx context.Context, song SongInfo) (*FetchResult, error) {
+	query := url.Values{}
+	query.Set("track_name", song.Title)
+	query.Set("artist_name", song.Artist)
+	if song.Album != "" {
+		query.Set("album_name", song.Album)
+	}
+	if song.Duration > 0 {
+		query.Set("duration", strconv.Itoa(int(song.Duration.Seconds())))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.BaseURL+"/api/get?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Tuneminal (https://github.com/heza-ru/Tuneminal)")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &RetryableError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &RetryableError{Err: fmt.Errorf("%s returned %s", p.Name(), resp.Status)}
+	}
+
+	var body struct {
+		SyncedLyrics string `json:"syncedLyrics"`
+		PlainLyrics  string `json:"plainLyrics"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid response from %s: %w", p.Name(), err)
+	}
+	if body.SyncedLyrics == "" && body.PlainLyrics == "" {
+		return nil, ErrNotFound
+	}
+
+	return &FetchResult{Synced: body.SyncedLyrics, Plain: body.PlainLyrics}, nil
+}
+
+// RetryableError marks a failure worth retrying, such as a server error or rate limit
+type RetryableError struct {
+	Err error
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// SaveFetched writes a fetch result next to the audio file: an .lrc when
+// timestamps are available, otherwise a .txt that can be timed later
+func SaveFetched(audioPath string, result *FetchResult) (string, error) {
+	base := strings.TrimSuffix(audioPath, filepath.Ext(audioPath))
+
+	if result.Synced != "" {
+		if _, err := Parse(strings.NewReader(result.Synced)); err != nil {
+			return "", err
+		}
+		return base + ".lrc", os.WriteFile(base+".lrc", []byte(result.Synced), 0644)
+	}
+	return base + ".txt", os.WriteFile(base+".txt", []byte(result.Plain), 0644)
+}
@@ -0,0 +1,66 @@
+package lyrics
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Extension tags for singers:
+//
+//	[key:G]        the key the song is recorded in
+//	[transpose:-2] semitones to shift playback by
+
+var sharpNotes = []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+var flatNotes = []string{"C", "Db", "D", "Eb", "E", "F", "Gb", "G", "Ab", "A", "Bb", "B"}
+
+// Key returns the song's key from the [key:] tag, empty if not set
+func (l *Lyrics) Key() string {
+	return l.Tags["key"]
+}
+
+// Transpose returns the pitch shift from the [transpose:] tag, limited to an
+// octave either way; ok is false when the tag is missing or invalid
+func (l *Lyrics) Transpose() (semitones int, ok bool) {
+	raw, found := l.Tags["transpose"]
+	if !found {
+		return 0, false
+	}
+	semitones, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(raw), "+"))
+	if err != nil || semitones < -12 || semitones > 12 {
+		return 0, false
+	}
+	return semitones, true
+}
+
+// TransposeKey moves a key such as "F#m" or "Bb" by a number of semitones,
+// keeping any suffix. Keys it can't read are returned unchanged.
+func TransposeKey(key string, semitones int) string {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return key
+	}
+
+	root := strings.ToUpper(key[:1])
+	note := strings.Index("C D EF G A B", root)
+	if note < 0 {
+		return key
+	}
+	rest := key[1:]
+
+	flats := false
+	switch {
+	case strings.HasPrefix(rest, "#") || strings.HasPrefix(rest, "♯"):
+		note++
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, "#"), "♯")
+	case strings.HasPrefix(rest, "b") || strings.HasPrefix(rest, "♭"):
+		note--
+		flats = true
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, "b"), "♭")
+	}
+
+	note = ((note+semitones)%12 + 12) % 12
+	if flats {
+		return flatNotes[note] + rest
+	}
+	return sharpNotes[note] + rest
+}
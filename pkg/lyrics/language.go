@@ -0,0 +1,181 @@
+package lyrics
+
+import (
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Language tags an LRC file can set to skip detection, e.g. [la:es]
+var languageTags = []string{"la", "lang", "language"}
+
+// languageNames maps the ISO 639-1 codes DetectLanguage returns to names
+var languageNames = map[string]string{
+	"ar": "Arabic",
+	"de": "German",
+	"el": "Greek",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"he": "Hebrew",
+	"hi": "Hindi",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"nl": "Dutch",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"sv": "Swedish",
+	"th": "Thai",
+	"uk": "Ukrainian",
+	"zh": "Chinese",
+}
+
+// stopwords are common short words that tell Latin-script languages apart
+var stopwords = map[string][]string{
+	"en": {"the", "and", "you", "i", "to", "my", "me", "is", "it", "of", "that", "your", "for", "on", "with", "be", "are", "was", "don't", "i'm", "what", "this", "all", "just", "know", "we", "can", "like", "when", "love", "baby", "oh"},
+	"es": {"el", "los", "las", "que", "y", "mi", "tu", "yo", "es", "por", "con", "una", "un", "te", "lo", "del", "para", "más", "pero", "como", "amor", "corazón", "quiero", "sin", "eres", "estoy", "cuando"},
+	"fr": {"le", "les", "et", "je", "il", "elle", "est", "une", "des", "du", "qui", "pas", "ne", "mon", "moi", "toi", "dans", "pour", "sur", "avec", "c'est", "j'ai", "au", "nous", "vous", "mais", "suis", "tout"},
+	"de": {"der", "die", "das", "und", "ich", "du", "nicht", "ist", "ein", "eine", "mit", "mich", "dich", "mir", "dir", "auf", "zu", "sie", "wir", "was", "wie", "den", "dem", "noch", "auch", "nur", "bist", "immer"},
+	"it": {"il", "di", "e", "che", "non", "mi", "ti", "io", "per", "sono", "è", "ma", "della", "ho", "ancora", "amore", "cuore", "più", "questo", "sei", "gli", "anche", "tutto"},
+	"pt": {"o", "os", "não", "eu", "você", "meu", "minha", "um", "uma", "com", "do", "da", "em", "na", "é", "mas", "coração", "amor", "vou", "quero", "sem", "tudo", "seu", "ao"},
+	"nl": {"het", "een", "en", "ik", "je", "niet", "van", "dat", "die", "op", "met", "mijn", "jij", "wij", "maar", "zijn", "voor", "ook", "nog", "wat", "als", "naar", "mij", "jou"},
+	"sv": {"och", "jag", "det", "att", "inte", "är", "ett", "som", "på", "mig", "dig", "har", "vi", "för", "till", "av", "om", "så", "men", "kan", "du", "min", "aldrig"},
+}
+
+// stopwordIndex maps each stopword to the languages using it
+var stopwordIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+// minLanguageWords is how many different stopwords a Latin-script text needs
+// before its language is trusted
+const minLanguageWords = 3
+
+// LanguageName returns the English name of a language code, or the code
+// itself when it isn't known
+func LanguageName(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// DetectLanguage guesses the language of lyric lines, returning an ISO 639-1
+// code or "" when there is too little to go on. Non-Latin scripts are told
+// apart by their characters, Latin-script languages by common words.
+func DetectLanguage(lines []string) string {
+	text := strings.ToLower(strings.Join(lines, "\n"))
+
+	if language := scriptLanguage(text); language != "" {
+		return language
+	}
+
+	// Each stopword counts once, so a repeated "na na na" doesn't decide it
+	scores := make(map[string]int)
+	seen := make(map[string]bool)
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\'' && r != '’'
+	})
+	for _, word := range words {
+		word = strings.ReplaceAll(strings.Trim(word, "'’"), "’", "'")
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		for _, language := range stopwordIndex[word] {
+			scores[language]++
+		}
+	}
+
+	best, bestScore, runnerUp := "", 0, 0
+	for language, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, runnerUp = language, score, bestScore
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+	if bestScore < minLanguageWords || bestScore == runnerUp {
+		return ""
+	}
+	return best
+}
+
+// scriptLanguage returns the language of a text written mostly in a
+// non-Latin script, or "" for Latin or mixed text
+func scriptLanguage(text string) string {
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["ja"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["ru"]++
+			if strings.ContainsRune("іїєґ", r) {
+				counts["uk"]++
+			}
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+
+	switch {
+	case counts["ja"] > 0 && counts["ja"]+counts["zh"] > letters/2:
+		// Japanese mixes kana with kanji
+		return "ja"
+	case counts["uk"] > 0 && counts["ru"] > letters/2:
+		return "uk"
+	}
+	for _, language := range []string{"zh", "ko", "ru", "ar", "he", "hi", "el", "th"} {
+		if counts[language] > letters/2 {
+			return language
+		}
+	}
+	return ""
+}
+
+// FileLanguage returns the language of a lyrics file: the one its [la:] tag
+// names, if any, or else the one detected from its text
+func FileLanguage(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	if l, err := Parse(strings.NewReader(string(data))); err == nil {
+		for _, tag := range languageTags {
+			if code := strings.ToLower(strings.TrimSpace(l.Tags[tag])); code != "" {
+				return code
+			}
+		}
+	}
+	return DetectLanguage(PlainLines(string(data)))
+}
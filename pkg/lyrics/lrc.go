@@ -0,0 +1,234 @@
+package lyrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeTagRegex matches LRC time tags: [mm:ss], [mm:ss.xx] or [mm:ss.xxx]
+var timeTagRegex = regexp.MustCompile(`\[(\d{1,3}):(\d{1,2})(?:[.:](\d{1,3}))?\]`)
+
+// metaTagRegex matches LRC header tags such as [ti:Title] or [offset:+250]
+var metaTagRegex = regexp.MustCompile(`^\[([A-Za-z#]+):(.*)\]$`)
+
+// Lyrics is a parsed LRC document
+//
+// Line times are effective times: the [offset:] tag has already been applied,
+// so callers can compare them directly against the playback position.
+type Lyrics struct {
+	Lines  []LyricLine
+	Tags   map[string]string
+	Offset time.Duration
+}
+
+// New creates an empty lyrics document
+func New() *Lyrics {
+	return &Lyrics{
+		Lines: make([]LyricLine, 0),
+		Tags:  make(map[string]string),
+	}
+}
+
+// LoadFile loads and parses an LRC file
+func LoadFile(filename string) (*Lyrics, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lyrics file: %w", err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads an LRC document from r
+func Parse(r io.Reader) (*Lyrics, error) {
+	l := New()
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		matches := timeTagRegex.FindAllStringSubmatch(line, -1)
+		if len(matches) == 0 {
+			// Header tag like [ar:Artist]
+			if m := metaTagRegex.FindStringSubmatch(line); m != nil {
+				l.Tags[strings.ToLower(strings.TrimSpace(m[1]))] = strings.TrimSpace(m[2])
+			}
+			continue
+		}
+
+		// Text is whatever remains after all time tags
+		text := strings.TrimSpace(timeTagRegex.ReplaceAllString(line, ""))
+
+		// A line may carry several time tags when it repeats
+		for _, match := range matches {
+			l.Lines = append(l.Lines, LyricLine{
+				Time: parseTimeTag(match),
+				Text: text,
+			})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading lyrics: %w", err)
+	}
+
+	if raw, ok := l.Tags["offset"]; ok {
+		if ms, err := strconv.Atoi(strings.TrimPrefix(raw, "+")); err == nil {
+			l.Offset = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	// A positive offset makes lyrics appear sooner. Lines it moves before
+	// the start keep their negative time, so Write gives back the file's
+	// own, and show from the start.
+	for i := range l.Lines {
+		l.Lines[i].Time -= l.Offset
+	}
+
+	l.sortLines()
+	return l, nil
+}
+
+// parseTimeTag converts a time tag match into a duration
+func parseTimeTag(match []string) time.Duration {
+	minutes, _ := strconv.Atoi(match[1])
+	seconds, _ := strconv.Atoi(match[2])
+
+	var fraction time.Duration
+	if match[3] != "" {
+		value, _ := strconv.Atoi(match[3])
+		switch len(match[3]) {
+		case 1:
+			fraction = time.Duration(value) * 100 * time.Millisecond
+		case 2:
+			fraction = time.Duration(value) * 10 * time.Millisecond
+		default:
+			fraction = time.Duration(value) * time.Millisecond
+		}
+	}
+
+	return time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second +
+		fraction
+}
+
+// sortLines orders lines by time and renumbers their indices
+func (l *Lyrics) sortLines() {
+	sort.SliceStable(l.Lines, func(i, j int) bool {
+		return l.Lines[i].Time < l.Lines[j].Time
+	})
+	for i := range l.Lines {
+		l.Lines[i].Index = i
+	}
+}
+
+// SetOffset changes the lyric offset, shifting every line accordingly.
+// Setting the old offset back restores every line.
+func (l *Lyrics) SetOffset(offset time.Duration) {
+	delta := offset - l.Offset
+	for i := range l.Lines {
+		l.Lines[i].Time -= delta
+	}
+	l.Offset = offset
+}
+
+// IndexAt returns the index of the line active at t, or -1 before the first line
+func (l *Lyrics) IndexAt(t time.Duration) int {
+	// First line starting after t, minus one
+	return sort.Search(len(l.Lines), func(i int) bool {
+		return l.Lines[i].Time > t
+	}) - 1
+}
+
+// LineAt returns the line active at t, or nil before the first line
+func (l *Lyrics) LineAt(t time.Duration) *LyricLine {
+	index := l.IndexAt(t)
+	if index < 0 {
+		return nil
+	}
+	return &l.Lines[index]
+}
+
+// Validate checks that the lyrics contain timed lines in chronological order
+func (l *Lyrics) Validate() error {
+	if len(l.Lines) == 0 {
+		return fmt.Errorf("no lyrics found")
+	}
+
+	for i := 1; i < len(l.Lines); i++ {
+		if l.Lines[i].Time < l.Lines[i-1].Time {
+			return fmt.Errorf("lyrics are not in chronological order at line %d", i+1)
+		}
+	}
+
+	return nil
+}
+
+// Write writes the lyrics in LRC format
+func (l *Lyrics) Write(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+
+	// Header tags in a stable order, offset last
+	keys := make([]string, 0, len(l.Tags))
+	for key := range l.Tags {
+		if key != "offset" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(writer, "[%s:%s]\n", key, l.Tags[key])
+	}
+	if l.Offset != 0 {
+		fmt.Fprintf(writer, "[offset:%+d]\n", l.Offset.Milliseconds())
+	}
+	if len(keys) > 0 || l.Offset != 0 {
+		writer.WriteString("\n")
+	}
+
+	// Timestamps are stored without the offset applied
+	for _, line := range l.Lines {
+		writer.WriteString(FormatTimestamp(line.Time+l.Offset) + line.Text + "\n")
+	}
+
+	return writer.Flush()
+}
+
+// Save writes the lyrics to an LRC file
+func (l *Lyrics) Save(filename string) error {
+	// Create directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return l.Write(file)
+}
+
+// FormatTimestamp formats a duration as an LRC time tag [mm:ss.xx]
+func FormatTimestamp(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	minutes := int(d / time.Minute)
+	seconds := int(d/time.Second) % 60
+	centiseconds := int(d/(10*time.Millisecond)) % 100
+	return fmt.Sprintf("[%02d:%02d.%02d]", minutes, seconds, centiseconds)
+}
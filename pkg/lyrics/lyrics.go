@@ -1,19 +1,17 @@
 package lyrics
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/utils"
 )
 
 // LyricEditor handles lyrics editing functionality
 type LyricEditor struct {
-	lines []LyricLine
+	lyrics *Lyrics
 }
 
 // LyricLine represents a single lyric line with timing
@@ -26,91 +24,52 @@ type LyricLine struct {
 // NewLyricEditor creates a new lyrics editor
 func NewLyricEditor() *LyricEditor {
 	return &LyricEditor{
-		lines: make([]LyricLine, 0),
+		lyrics: New(),
 	}
 }
 
 // LoadLyricsFromFile loads lyrics from an LRC file
 func (le *LyricEditor) LoadLyricsFromFile(filename string) error {
-	file, err := os.Open(filename)
+	lyrics, err := LoadFile(filename)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	le.lines = []LyricLine{}
-	scanner := bufio.NewScanner(file)
-	index := 0
-
-	// Regex to match LRC time format [mm:ss.xx]
-	timeRegex := regexp.MustCompile(`\[(\d{2}):(\d{2})\.(\d{2})\]`)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Skip empty lines and metadata lines
-		if line == "" || strings.HasPrefix(line, "[") && !timeRegex.MatchString(line) {
-			continue
-		}
-
-		// Extract time and text
-		matches := timeRegex.FindStringSubmatch(line)
-		if len(matches) == 4 {
-			minutes, _ := strconv.Atoi(matches[1])
-			seconds, _ := strconv.Atoi(matches[2])
-			centiseconds, _ := strconv.Atoi(matches[3])
-
-			time := time.Duration(minutes)*time.Minute +
-					time.Duration(seconds)*time.Second +
-					time.Duration(centiseconds)*10*time.Millisecond
-
-			// Extract text after the time tag
-			text := timeRegex.ReplaceAllString(line, "")
-			text = strings.TrimSpace(text)
-
-			le.lines = append(le.lines, LyricLine{
-				Time:  time,
-				Text:  text,
-				Index: index,
-			})
-			index++
-		}
-	}
 
+	le.lyrics = lyrics
 	return nil
 }
 
 // AddLyricLine adds a new lyric line at the specified time
 func (le *LyricEditor) AddLyricLine(time time.Duration, text string) {
-	le.lines = append(le.lines, LyricLine{
+	le.lyrics.Lines = append(le.lyrics.Lines, LyricLine{
 		Time:  time,
 		Text:  text,
-		Index: len(le.lines),
+		Index: len(le.lyrics.Lines),
 	})
 }
 
 // UpdateLyricLine updates an existing lyric line
 func (le *LyricEditor) UpdateLyricLine(index int, time time.Duration, text string) error {
-	if index < 0 || index >= len(le.lines) {
+	if index < 0 || index >= len(le.lyrics.Lines) {
 		return fmt.Errorf("line index out of range")
 	}
 
-	le.lines[index].Time = time
-	le.lines[index].Text = text
+	le.lyrics.Lines[index].Time = time
+	le.lyrics.Lines[index].Text = text
 	return nil
 }
 
 // DeleteLyricLine removes a lyric line
 func (le *LyricEditor) DeleteLyricLine(index int) error {
-	if index < 0 || index >= len(le.lines) {
+	if index < 0 || index >= len(le.lyrics.Lines) {
 		return fmt.Errorf("line index out of range")
 	}
 
-	le.lines = append(le.lines[:index], le.lines[index+1:]...)
+	le.lyrics.Lines = append(le.lyrics.Lines[:index], le.lyrics.Lines[index+1:]...)
 
 	// Update indices
-	for i := index; i < len(le.lines); i++ {
-		le.lines[i].Index = i
+	for i := index; i < len(le.lyrics.Lines); i++ {
+		le.lyrics.Lines[i].Index = i
 	}
 
 	return nil
@@ -118,51 +77,35 @@ func (le *LyricEditor) DeleteLyricLine(index int) error {
 
 // GetLyricsLines returns all lyric lines
 func (le *LyricEditor) GetLyricsLines() []LyricLine {
-	return le.lines
+	return le.lyrics.Lines
+}
+
+// GetLyrics returns the lyrics document being edited
+func (le *LyricEditor) GetLyrics() *Lyrics {
+	return le.lyrics
 }
 
 // SaveLyricsToFile saves lyrics to an LRC file
 func (le *LyricEditor) SaveLyricsToFile(filename string) error {
-	// Create directory if it doesn't exist
-	dir := filepath.Dir(filename)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
+	// Fill in a header for lyrics written from scratch
+	defaults := map[string]string{
+		"ti": "Custom Lyrics",
+		"ar": "Unknown Artist",
+		"al": "Unknown Album",
 	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-
-	// Write header
-	writer.WriteString("[ti:Custom Lyrics]\n")
-	writer.WriteString("[ar:Unknown Artist]\n")
-	writer.WriteString("[al:Unknown Album]\n")
-	writer.WriteString("\n")
-
-	// Write lyrics
-	for _, line := range le.lines {
-		if line.Text != "" {
-			minutes := int(line.Time.Minutes())
-			seconds := int(line.Time.Seconds()) % 60
-			centiseconds := int(line.Time.Milliseconds()) % 1000 / 10
-
-			timeStr := fmt.Sprintf("[%02d:%02d.%02d]", minutes, seconds, centiseconds)
-			writer.WriteString(timeStr + line.Text + "\n")
+	for key, value := range defaults {
+		if _, ok := le.lyrics.Tags[key]; !ok {
+			le.lyrics.Tags[key] = value
 		}
 	}
 
-	return writer.Flush()
+	le.lyrics.sortLines()
+	return le.lyrics.Save(filename)
 }
 
-// FormatDuration formats duration for display
+// FormatDuration formats duration for display, as mm:ss or h:mm:ss
 func FormatDuration(d time.Duration) string {
-	minutes := int(d.Minutes())
-	seconds := int(d.Seconds()) % 60
-	return fmt.Sprintf("%02d:%02d", minutes, seconds)
+	return utils.FormatDuration(d)
 }
 
 // ParseTime parses time string in mm:ss.xx format
@@ -0,0 +1,394 @@
+package lyrics
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleLRC = `[ti:Test Song]
+[ar:Test Artist]
+[offset:+500]
+
+[00:01.00]First line
+[00:03.50][00:10.25]Repeated line
+[00:05.123]Millisecond line
+[00:07]No fraction
+`
+
+func TestParse(t *testing.T) {
+	l, err := Parse(strings.NewReader(sampleLRC))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	if l.Tags["ti"] != "Test Song" || l.Tags["ar"] != "Test Artist" {
+		t.Errorf("Header tags not parsed: %v", l.Tags)
+	}
+
+	if l.Offset != 500*time.Millisecond {
+		t.Errorf("Expected offset 500ms, got %v", l.Offset)
+	}
+
+	if len(l.Lines) != 5 {
+		t.Fatalf("Expected 5 lines, got %d", len(l.Lines))
+	}
+
+	// Offset is applied to every line
+	expected := []time.Duration{
+		500 * time.Millisecond,
+		3000 * time.Millisecond,
+		4623 * time.Millisecond,
+		6500 * time.Millisecond,
+		9750 * time.Millisecond,
+	}
+	for i, want := range expected {
+		if l.Lines[i].Time != want {
+			t.Errorf("Line %d: expected %v, got %v", i, want, l.Lines[i].Time)
+		}
+		if l.Lines[i].Index != i {
+			t.Errorf("Line %d has index %d", i, l.Lines[i].Index)
+		}
+	}
+
+	if err := l.Validate(); err != nil {
+		t.Errorf("Validate() returned error: %v", err)
+	}
+}
+
+func TestIndexAt(t *testing.T) {
+	l, _ := Parse(strings.NewReader("[00:01.00]a\n[00:02.00]b\n[00:03.00]c\n"))
+
+	cases := map[time.Duration]int{
+		0:                       -1,
+		time.Second:             0,
+		1500 * time.Millisecond: 0,
+		2 * time.Second:         1,
+		10 * time.Second:        2,
+	}
+	for at, want := range cases {
+		if got := l.IndexAt(at); got != want {
+			t.Errorf("IndexAt(%v) = %d, want %d", at, got, want)
+		}
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	l, _ := Parse(strings.NewReader(sampleLRC))
+	l.SetOffset(-250 * time.Millisecond)
+
+	var buf bytes.Buffer
+	if err := l.Write(&buf); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+
+	again, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("Parse() of written lyrics failed: %v", err)
+	}
+
+	if again.Offset != l.Offset {
+		t.Errorf("Offset not preserved: %v != %v", again.Offset, l.Offset)
+	}
+	for i := range l.Lines {
+		if again.Lines[i].Time != l.Lines[i].Time/(10*time.Millisecond)*(10*time.Millisecond) ||
+			again.Lines[i].Text != l.Lines[i].Text {
+			t.Errorf("Line %d changed: %+v != %+v", i, again.Lines[i], l.Lines[i])
+		}
+	}
+}
+
+func TestOffsetRoundTrip(t *testing.T) {
+	const lrc = "[offset:+500]\n\n[00:00.20]Early line\n[00:02.00]Later line\n"
+	l, err := Parse(strings.NewReader(lrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index := l.IndexAt(0); index != 0 {
+		t.Errorf("Expected the early line to show from the start, got index %d", index)
+	}
+
+	var buf bytes.Buffer
+	l.Write(&buf)
+	if buf.String() != lrc {
+		t.Errorf("Write changed the lyrics:\n%s\nwant:\n%s", buf.String(), lrc)
+	}
+
+	// Shifting far past the early line and back again leaves it as it was
+	l.SetOffset(3 * time.Second)
+	l.SetOffset(500 * time.Millisecond)
+	buf.Reset()
+	l.Write(&buf)
+	if buf.String() != lrc {
+		t.Errorf("SetOffset couldn't be undone:\n%s\nwant:\n%s", buf.String(), lrc)
+	}
+}
+
+func TestLyricLine(t *testing.T) {
+	lyric := LyricLine{
+		Time: 30 * time.Second,
+		Text: "Test lyric",
+	}
+
+	if lyric.Time != 30*time.Second {
+		t.Error("Lyric time not set correctly")
+	}
+
+	if lyric.Text != "Test lyric" {
+		t.Error("Lyric text not set correctly")
+	}
+}
+
+func TestPlainLines(t *testing.T) {
+	text := "[ar:Someone]\r\nFirst line\r\n\r\n  [00:12.00]Second line  \n\n[Chorus]\n"
+
+	lines := PlainLines(text)
+	expected := []string{"First line", "Second line", "[Chorus]"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d: %q", len(expected), len(lines), lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Line %d: expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestTransposeTags(t *testing.T) {
+	l, err := Parse(strings.NewReader("[key:G]\n[transpose:-2]\n[00:01.00]Line\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	semitones, ok := l.Transpose()
+	if !ok || semitones != -2 {
+		t.Errorf("Transpose() = %d, %v; want -2, true", semitones, ok)
+	}
+	if got := TransposeKey(l.Key(), semitones); got != "F" {
+		t.Errorf("G down 2 = %q, want F", got)
+	}
+
+	for _, test := range []struct {
+		key       string
+		semitones int
+		want      string
+	}{
+		{"Am", 3, "Cm"},
+		{"F#m", -1, "Fm"},
+		{"Bb", 2, "C"},
+		{"Eb", -1, "D"},
+		{"B", 1, "C"},
+		{"unknown", 2, "unknown"},
+	} {
+		if got := TransposeKey(test.key, test.semitones); got != test.want {
+			t.Errorf("TransposeKey(%q, %d) = %q, want %q", test.key, test.semitones, got, test.want)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	for _, test := range []struct {
+		lines []string
+		want  string
+	}{
+		{[]string{"I know that you love me", "And the night is young"}, "en"},
+		{[]string{"Te quiero con todo mi corazón", "Y no puedo vivir sin tu amor"}, "es"},
+		{[]string{"Je suis dans la nuit", "Et tu es avec moi, c'est tout"}, "fr"},
+		{[]string{"Ich liebe dich, du bist nicht allein", "Und wir sind immer da"}, "de"},
+		{[]string{"君の名前を呼んでいる", "夜空に"}, "ja"},
+		{[]string{"Я люблю тебя", "Ночь и звезды"}, "ru"},
+		{[]string{"Na na na", "Oh yeah"}, ""},
+	} {
+		if got := DetectLanguage(test.lines); got != test.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", test.lines, got, test.want)
+		}
+	}
+}
+
+func TestCheckReadiness(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		audio := filepath.Join(dir, name+".mp3")
+		os.WriteFile(audio, nil, 0644)
+		if content != "" {
+			os.WriteFile(filepath.Join(dir, name+".lrc"), []byte(content), 0644)
+		}
+		return audio
+	}
+
+	ready := write("ready", "[00:01.00]First\n[00:04.00]Second\n[00:08.00]\n[00:45.00]After the break\n[00:50.00]\n")
+	if r := CheckReadiness(ready, DefaultReadinessOptions); r.Grade != "A" || len(r.Issues) != 0 {
+		t.Errorf("Expected grade A, got %s %+v", r.Grade, r.Issues)
+	}
+
+	gappy := write("gappy", "[00:01.00]First\n[00:40.00]"+strings.Repeat("la ", 30)+"\n")
+	r := CheckReadiness(gappy, DefaultReadinessOptions)
+	if r.Grade != "C" || len(r.Issues) != 3 {
+		t.Errorf("Expected a gap, a long line and no end marker (grade C), got %s %+v", r.Grade, r.Issues)
+	}
+
+	unordered := write("unordered", "[00:05.00]Second\n[00:01.00]First\n[00:09.00]\n")
+	if r := CheckReadiness(unordered, DefaultReadinessOptions); r.Grade != "D" || r.Issues[0].Line != 2 {
+		t.Errorf("Expected an out-of-order line 2 (grade D), got %s %+v", r.Grade, r.Issues)
+	}
+
+	if r := CheckReadiness(write("missing", ""), DefaultReadinessOptions); r.Grade != "F" {
+		t.Errorf("Expected grade F without lyrics, got %s", r.Grade)
+	}
+}
+
+func TestSplitDuet(t *testing.T) {
+	l, err := Parse(strings.NewReader("[00:01.00]Intro together\n[00:02.00]M: First verse\n[00:03.00]Still him\n[00:04.00]F: Her answer\n[00:05.00]\n[00:06.00]D: Both sing\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	duet := SplitDuet(l)
+	if duet == nil {
+		t.Fatal("Expected prefixed lyrics to be a duet")
+	}
+	if duet.Names != [2]string{"Male", "Female"} {
+		t.Errorf("Unexpected names %v", duet.Names)
+	}
+	if duet.All.Lines[1].Text != "First verse" || len(duet.All.Lines) != 6 {
+		t.Errorf("Expected prefixes stripped from all 6 lines, got %+v", duet.All.Lines)
+	}
+
+	texts := func(part *Lyrics) []string {
+		var out []string
+		for _, line := range part.Lines {
+			out = append(out, line.Text)
+		}
+		return out
+	}
+	if got := strings.Join(texts(duet.Parts[0]), "|"); got != "Intro together|First verse|Still him||Both sing" {
+		t.Errorf("Unexpected first part %q", got)
+	}
+	if got := strings.Join(texts(duet.Parts[1]), "|"); got != "Intro together|Her answer||Both sing" {
+		t.Errorf("Unexpected second part %q", got)
+	}
+
+	plain, _ := Parse(strings.NewReader("[00:01.00]Mind the gap\n"))
+	if SplitDuet(plain) != nil {
+		t.Error("Expected lyrics without prefixes not to be a duet")
+	}
+}
+
+func TestSync(t *testing.T) {
+	s := NewPlainSync([]string{"One", "Two", "Three"})
+
+	s.Stamp(0, 2*time.Second)
+	s.Stamp(1, 5*time.Second)
+	if err := s.Nudge(2, NudgeStep); err == nil {
+		t.Error("Expected nudging an untimed line to fail")
+	}
+	s.Nudge(1, -NudgeStep)
+	if s.Lines[1].Time != 4900*time.Millisecond {
+		t.Errorf("Expected the nudge to move the line to 4.9s, got %v", s.Lines[1].Time)
+	}
+	if got := s.NextUnstamped(0); got != 2 {
+		t.Errorf("NextUnstamped(0) = %d, want 2", got)
+	}
+	if got := s.IndexAt(3 * time.Second); got != 0 {
+		t.Errorf("IndexAt(3s) = %d, want 0", got)
+	}
+
+	s.Insert(1, "One and a half")
+	s.Delete(3)
+	if len(s.Lines) != 3 || s.Lines[1].Text != "One and a half" || s.StampedCount() != 2 {
+		t.Fatalf("Unexpected lines after insert and delete: %+v", s.Lines)
+	}
+
+	// The untimed line keeps its place behind the line before it
+	l := s.Lyrics()
+	want := []string{"One", "One and a half", "Two"}
+	for i, line := range l.Lines {
+		if line.Text != want[i] {
+			t.Errorf("Line %d = %q, want %q", i, line.Text, want[i])
+		}
+	}
+	if l.Lines[1].Time != 2*time.Second {
+		t.Errorf("Expected the untimed line at 2s, got %v", l.Lines[1].Time)
+	}
+}
+
+func TestVisual(t *testing.T) {
+	if IsRTL("Hello world") || Visual("Hello world") != "Hello world" {
+		t.Error("Expected a left-to-right line to be left as it is")
+	}
+	if !IsRTL("  שלום עולם") {
+		t.Error("Expected a Hebrew line to read right to left")
+	}
+
+	tests := []struct {
+		text, want string
+	}{
+		{"שלום עולם", "םלוע םולש"},
+		// Numbers keep their order and brackets turn round
+		{"שיר 42 (אהבה)", "(הבהא) 42 ריש"},
+		// Arabic letters take their joined forms, lam and alef as one
+		{"سلام", "ﻡﻼﺳ"},
+	}
+	for _, tt := range tests {
+		if got := Visual(tt.text); got != tt.want {
+			t.Errorf("Visual(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestResolver(t *testing.T) {
+	dir := t.TempDir()
+	songDir, folder, cache := filepath.Join(dir, "songs"), filepath.Join(dir, "lyrics"), filepath.Join(dir, "cache")
+	for _, d := range []string{songDir, folder} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	song := SongInfo{Path: filepath.Join(songDir, "track_01.mp3"), Title: "Test Song", Artist: "Test Artist"}
+	embedded := ""
+	r := &Resolver{
+		Sources:  DefaultSources,
+		Patterns: DefaultPatterns,
+		Folder:   folder,
+		CacheDir: cache,
+		Embedded: func(string) string { return embedded },
+	}
+
+	if got := r.Find(song); got != "" {
+		t.Errorf("Expected no lyrics yet, got %s", got)
+	}
+
+	// Timed lyrics in the tags are copied to the cache; plain ones aren't used
+	embedded = "Just words"
+	if got := r.Find(song); got != "" {
+		t.Errorf("Expected untimed embedded lyrics to be skipped, got %s", got)
+	}
+	embedded = sampleLRC
+	got := r.Find(song)
+	if got == "" || !r.IsCached(got) {
+		t.Fatalf("Expected embedded lyrics in the cache, got %q", got)
+	}
+
+	// The central folder comes before the tags, by artist and title
+	inFolder := filepath.Join(folder, "Test Artist - Test Song.lrc")
+	os.WriteFile(inFolder, []byte(sampleLRC), 0644)
+	if got := r.Find(song); got != inFolder {
+		t.Errorf("Expected %s, got %s", inFolder, got)
+	}
+
+	// A sidecar comes first, and may write underscores as spaces
+	sidecar := filepath.Join(songDir, "track 01.lrc")
+	os.WriteFile(sidecar, []byte(sampleLRC), 0644)
+	if got := r.Find(song); got != sidecar {
+		t.Errorf("Expected %s, got %s", sidecar, got)
+	}
+
+	// The order can be changed
+	r.Sources = []string{SourceFolder, SourceSidecar}
+	if got := r.Find(song); got != inFolder {
+		t.Errorf("Expected the folder first, got %s", got)
+	}
+}
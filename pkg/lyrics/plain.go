@@ -0,0 +1,19 @@
+package lyrics
+
+import (
+	"strings"
+)
+
+// PlainLines splits pasted plain-text lyrics into lines ready for timing.
+// Blank lines and any existing LRC time tags are dropped.
+func PlainLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(timeTagRegex.ReplaceAllString(line, ""))
+		if line == "" || metaTagRegex.MatchString(line) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
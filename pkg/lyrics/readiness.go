@@ -0,0 +1,179 @@
+package lyrics
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Severity says how much an issue gets in the way of singing along
+type Severity int
+
+const (
+	// Minor issues are noticeable but the song is singable
+	Minor Severity = iota
+	// Major issues show the wrong line at the wrong time
+	Major
+	// Critical issues mean there is nothing to sing along to
+	Critical
+)
+
+// Issue is one problem found in a song's lyrics
+type Issue struct {
+	Severity Severity
+	Line     int // Line number in the lyrics file, 0 when it applies to the whole file
+	Message  string
+}
+
+// Readiness is how well a song's lyrics work for karaoke
+type Readiness struct {
+	AudioPath  string
+	LyricsPath string
+	Grade      string // A (no issues) to F (nothing to sing)
+	Issues     []Issue
+}
+
+// ReadinessOptions sets the limits lyrics are checked against
+type ReadinessOptions struct {
+	MaxGap   time.Duration // Longest wait between lines before the screen looks stuck
+	MaxWidth int           // Widest line that fits the lyrics panel, in characters
+}
+
+// DefaultReadinessOptions are the limits used when none are given
+var DefaultReadinessOptions = ReadinessOptions{
+	MaxGap:   20 * time.Second,
+	MaxWidth: 60,
+}
+
+// timedLine is a lyric line as it appears in the file, before sorting
+type timedLine struct {
+	number int
+	time   time.Duration
+	text   string
+}
+
+// CheckReadiness grades the lyrics of an audio file
+func CheckReadiness(audioPath string, opts ReadinessOptions) Readiness {
+	status, lyricsPath := CheckSong(audioPath)
+	readiness := Readiness{AudioPath: audioPath, LyricsPath: lyricsPath}
+
+	switch status {
+	case Missing:
+		readiness.Issues = []Issue{{Severity: Critical, Message: "no lyrics file"}}
+	case Unsynced:
+		readiness.Issues = []Issue{{Severity: Critical, Message: "lyrics have no timestamps"}}
+	default:
+		lines, err := readTimedLines(lyricsPath)
+		if err != nil {
+			readiness.Issues = []Issue{{Severity: Critical, Message: err.Error()}}
+		} else {
+			readiness.Issues = checkTimedLines(lines, opts)
+		}
+	}
+
+	readiness.Grade = grade(readiness.Issues)
+	return readiness
+}
+
+// readTimedLines reads the timed lines of an LRC file in file order, which
+// Parse doesn't keep
+func readTimedLines(path string) ([]timedLine, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lyrics file: %w", err)
+	}
+	defer file.Close()
+
+	var lines []timedLine
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		matches := timeTagRegex.FindAllStringSubmatch(line, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		// Lines repeated with several tags are checked at their first time
+		lines = append(lines, timedLine{
+			number: number,
+			time:   parseTimeTag(matches[0]),
+			text:   strings.TrimSpace(timeTagRegex.ReplaceAllString(line, "")),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading lyrics: %w", err)
+	}
+	return lines, nil
+}
+
+// checkTimedLines finds the issues in timed lines given in file order
+func checkTimedLines(lines []timedLine, opts ReadinessOptions) []Issue {
+	if len(lines) == 0 {
+		return []Issue{{Severity: Critical, Message: "no timed lines"}}
+	}
+
+	var issues []Issue
+	for i, line := range lines {
+		if i > 0 {
+			previous := lines[i-1]
+			switch {
+			case line.time < previous.time:
+				issues = append(issues, Issue{Severity: Major, Line: line.number,
+					Message: fmt.Sprintf("timestamp %s is before the previous line's %s",
+						FormatTimestamp(line.time), FormatTimestamp(previous.time))})
+			case line.time-previous.time > opts.MaxGap && previous.text != "":
+				// A blank line marks an intended break, so only gaps after sung lines count
+				issues = append(issues, Issue{Severity: Minor, Line: previous.number,
+					Message: fmt.Sprintf("%s gap after this line - add a blank timed line for the break",
+						(line.time - previous.time).Round(time.Second))})
+			}
+		}
+
+		if width := utf8.RuneCountInString(line.text); opts.MaxWidth > 0 && width > opts.MaxWidth {
+			issues = append(issues, Issue{Severity: Minor, Line: line.number,
+				Message: fmt.Sprintf("line is %d characters, wider than %d", width, opts.MaxWidth)})
+		}
+	}
+
+	// The last line in time should be a blank one, so the final lyric clears
+	last := lines[0]
+	for _, line := range lines {
+		if line.time >= last.time {
+			last = line
+		}
+	}
+	if last.text != "" {
+		issues = append(issues, Issue{Severity: Minor, Line: last.number,
+			Message: "no blank final line to clear the last lyric"})
+	}
+	return issues
+}
+
+// grade turns issues into a letter: A for none, B for a couple of minor
+// ones, C for more, D for major ones and F for critical ones
+func grade(issues []Issue) string {
+	minor, major := 0, 0
+	for _, issue := range issues {
+		switch issue.Severity {
+		case Critical:
+			return "F"
+		case Major:
+			major++
+		default:
+			minor++
+		}
+	}
+
+	switch {
+	case major > 0:
+		return "D"
+	case minor > 2:
+		return "C"
+	case minor > 0:
+		return "B"
+	default:
+		return "A"
+	}
+}
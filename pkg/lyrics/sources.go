@@ -0,0 +1,180 @@
+package lyrics
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Lyric sources a Resolver can look in
+const (
+	// SourceSidecar is a lyrics file next to the audio file
+	SourceSidecar = "sidecar"
+	// SourceFolder is a lyrics file in one central lyrics directory
+	SourceFolder = "folder"
+	// SourceEmbedded is timed lyrics in the audio file's own tags
+	SourceEmbedded = "embedded"
+	// SourceCache is lyrics fetched earlier that couldn't be saved next to the song
+	SourceCache = "cache"
+)
+
+// DefaultSources is the order the sources are tried in unless set otherwise
+var DefaultSources = []string{SourceSidecar, SourceFolder, SourceEmbedded, SourceCache}
+
+// DefaultPatterns are the file names looked for besides the audio file's own
+var DefaultPatterns = []string{"{artist} - {title}.lrc", "{title}.lrc"}
+
+// Resolver finds the lyrics file for a song, trying each source in turn
+type Resolver struct {
+	Sources  []string // Order to try the sources in; unknown names are skipped
+	Patterns []string // File names to look for after the song's own, with {name}, {title} and {artist}
+	Folder   string   // Central lyrics directory, "" for none
+	CacheDir string   // Where fetched and embedded lyrics are kept, "" for none
+
+	// Embedded returns the lyrics in an audio file's tags, "" for none
+	Embedded func(audioPath string) string
+}
+
+// Find returns the lyrics file for song from the first source that has one,
+// or "" when none does
+func (r *Resolver) Find(song SongInfo) string {
+	for _, source := range r.Sources {
+		if path := r.findIn(source, song); path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// findIn looks for song's lyrics in one source
+func (r *Resolver) findIn(source string, song SongInfo) string {
+	switch source {
+	case SourceSidecar:
+		return findNamed(filepath.Dir(song.Path), r.names(song))
+	case SourceFolder:
+		if r.Folder == "" {
+			return ""
+		}
+		return findNamed(r.Folder, r.names(song))
+	case SourceEmbedded:
+		return r.extractEmbedded(song.Path)
+	case SourceCache:
+		if r.CacheDir == "" {
+			return ""
+		}
+		return findNamed(filepath.Join(r.CacheDir, "fetched"), []string{cachedName(song)})
+	}
+	return ""
+}
+
+// names lists the file names song's lyrics may go by: the audio file's own
+// name with .lrc, then the patterns filled in. Patterns that need a title or
+// artist the song doesn't have are left out.
+func (r *Resolver) names(song SongInfo) []string {
+	name := strings.TrimSuffix(filepath.Base(song.Path), filepath.Ext(song.Path))
+	names := []string{name + ".lrc"}
+	for _, pattern := range r.Patterns {
+		if (strings.Contains(pattern, "{title}") && song.Title == "") ||
+			(strings.Contains(pattern, "{artist}") && song.Artist == "") {
+			continue
+		}
+		names = append(names, strings.NewReplacer(
+			"{name}", name,
+			"{title}", safeFileName(song.Title),
+			"{artist}", safeFileName(song.Artist),
+		).Replace(pattern))
+	}
+	return names
+}
+
+// findNamed returns the first of names found in dir, also trying each with
+// underscores written as spaces and as dashes, as FindSidecar does
+func findNamed(dir string, names []string) string {
+	for _, name := range names {
+		for _, candidate := range []string{
+			name,
+			strings.ReplaceAll(name, "_", " "),
+			strings.ReplaceAll(name, "_", "-"),
+		} {
+			path := filepath.Join(dir, candidate)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// extractEmbedded copies timed lyrics from the tags of the audio file into
+// the cache, so they can be loaded like any other lyrics file. Untimed
+// lyrics are left for the tap sync, as they can't be sung along to yet.
+func (r *Resolver) extractEmbedded(audioPath string) string {
+	if r.Embedded == nil || r.CacheDir == "" {
+		return ""
+	}
+	text := r.Embedded(audioPath)
+	if track, err := Parse(strings.NewReader(text)); err != nil || len(track.Lines) == 0 {
+		return ""
+	}
+
+	sum := sha1.Sum([]byte(audioPath))
+	path := filepath.Join(r.CacheDir, "embedded", hex.EncodeToString(sum[:8])+".lrc")
+	if existing, err := os.ReadFile(path); err == nil && string(existing) == text {
+		return path
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return ""
+	}
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		return ""
+	}
+	return path
+}
+
+// IsCached reports whether path is one of the resolver's cached copies,
+// which edits shouldn't be saved over
+func (r *Resolver) IsCached(path string) bool {
+	if r.CacheDir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(r.CacheDir, path)
+	return err == nil && !strings.HasPrefix(rel, "..")
+}
+
+// CacheFetched writes a synced fetch result to the fetched lyrics in
+// cacheDir, for songs whose own folder can't be written
+func CacheFetched(cacheDir string, song SongInfo, result *FetchResult) (string, error) {
+	if _, err := Parse(strings.NewReader(result.Synced)); err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cacheDir, "fetched")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, cachedName(song))
+	return path, os.WriteFile(path, []byte(result.Synced), 0644)
+}
+
+// cachedName is the file name fetched lyrics are cached under
+func cachedName(song SongInfo) string {
+	title := song.Title
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(song.Path), filepath.Ext(song.Path))
+	}
+	if song.Artist == "" {
+		return safeFileName(title) + ".lrc"
+	}
+	return safeFileName(song.Artist) + " - " + safeFileName(title) + ".lrc"
+}
+
+// safeFileName replaces the characters that can't go in a file name
+func safeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
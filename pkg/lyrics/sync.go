@@ -0,0 +1,139 @@
+package lyrics
+
+import (
+	"fmt"
+	"time"
+)
+
+// NudgeStep is how far one nudge moves a line's time in sync mode
+const NudgeStep = 100 * time.Millisecond
+
+// SyncLine is a line being timed in sync mode
+type SyncLine struct {
+	Time    time.Duration
+	Text    string
+	Stamped bool // whether the line has a time yet
+}
+
+// Sync is a lyrics document being timed against the song: each line is
+// stamped with the playback position when it starts, then fine-tuned
+type Sync struct {
+	Lines  []SyncLine
+	Tags   map[string]string
+	Offset time.Duration
+}
+
+// NewSync starts timing an existing document, keeping its times
+func NewSync(l *Lyrics) *Sync {
+	s := &Sync{Tags: make(map[string]string), Offset: l.Offset}
+	for key, value := range l.Tags {
+		s.Tags[key] = value
+	}
+	for _, line := range l.Lines {
+		s.Lines = append(s.Lines, SyncLine{Time: line.Time, Text: line.Text, Stamped: true})
+	}
+	return s
+}
+
+// NewPlainSync starts timing lines that have no times yet
+func NewPlainSync(lines []string) *Sync {
+	s := &Sync{Tags: make(map[string]string)}
+	for _, text := range lines {
+		s.Lines = append(s.Lines, SyncLine{Text: text})
+	}
+	return s
+}
+
+// Stamp sets the time of line i
+func (s *Sync) Stamp(i int, t time.Duration) error {
+	if i < 0 || i >= len(s.Lines) {
+		return fmt.Errorf("line index out of range")
+	}
+	s.Lines[i].Time = max(0, t)
+	s.Lines[i].Stamped = true
+	return nil
+}
+
+// Nudge moves the time of stamped line i by delta, no earlier than the start
+func (s *Sync) Nudge(i int, delta time.Duration) error {
+	if i < 0 || i >= len(s.Lines) {
+		return fmt.Errorf("line index out of range")
+	}
+	if !s.Lines[i].Stamped {
+		return fmt.Errorf("line %d has no time yet", i+1)
+	}
+	s.Lines[i].Time = max(0, s.Lines[i].Time+delta)
+	return nil
+}
+
+// Insert adds an untimed line before line i, or at the end when i is the
+// number of lines
+func (s *Sync) Insert(i int, text string) error {
+	if i < 0 || i > len(s.Lines) {
+		return fmt.Errorf("line index out of range")
+	}
+	s.Lines = append(s.Lines[:i], append([]SyncLine{{Text: text}}, s.Lines[i:]...)...)
+	return nil
+}
+
+// Delete removes line i
+func (s *Sync) Delete(i int) error {
+	if i < 0 || i >= len(s.Lines) {
+		return fmt.Errorf("line index out of range")
+	}
+	s.Lines = append(s.Lines[:i], s.Lines[i+1:]...)
+	return nil
+}
+
+// StampedCount returns how many lines have a time
+func (s *Sync) StampedCount() int {
+	count := 0
+	for _, line := range s.Lines {
+		if line.Stamped {
+			count++
+		}
+	}
+	return count
+}
+
+// NextUnstamped returns the first line from i on without a time, or -1
+func (s *Sync) NextUnstamped(i int) int {
+	for ; i < len(s.Lines); i++ {
+		if i >= 0 && !s.Lines[i].Stamped {
+			return i
+		}
+	}
+	return -1
+}
+
+// IndexAt returns the line that would be showing at t, the last stamped line
+// starting at or before it, or -1 before the first
+func (s *Sync) IndexAt(t time.Duration) int {
+	index := -1
+	for i, line := range s.Lines {
+		if line.Stamped && line.Time <= t && (index < 0 || line.Time >= s.Lines[index].Time) {
+			index = i
+		}
+	}
+	return index
+}
+
+// Lyrics returns the timed document. Lines without a time yet take the time
+// of the line before them so they keep their place.
+func (s *Sync) Lyrics() *Lyrics {
+	l := New()
+	for key, value := range s.Tags {
+		l.Tags[key] = value
+	}
+	l.Offset = s.Offset
+
+	var previous time.Duration
+	for _, line := range s.Lines {
+		if line.Stamped {
+			previous = line.Time
+		}
+		l.Lines = append(l.Lines, LyricLine{Time: previous, Text: line.Text})
+	}
+	l.sortLines()
+	return l
+}
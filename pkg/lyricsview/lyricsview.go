@@ -0,0 +1,165 @@
+// Package lyricsview sends the lyrics being sung to other processes over a
+// local socket, so a second terminal facing the singer can show just the
+// lyrics while the host keeps the full interface
+package lyricsview
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// State is what a lyrics view shows
+type State struct {
+	Title    string   `json:"title,omitempty"`
+	Artist   string   `json:"artist,omitempty"`
+	Previous string   `json:"previous,omitempty"` // The line just sung
+	Current  string   `json:"current,omitempty"`  // The line to sing now
+	Next     []string `json:"next,omitempty"`     // The lines coming up
+	Playing  bool     `json:"playing"`
+	Paused   bool     `json:"paused,omitempty"`
+	Lyrics   bool     `json:"lyrics"` // False when the song has none
+}
+
+// writeTimeout drops a view that stops reading, so it can't hold up the player
+const writeTimeout = time.Second
+
+// DefaultSocketPath is where the player listens for lyrics views: in
+// $XDG_RUNTIME_DIR when set, otherwise the temporary directory
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "tuneminal-lyrics.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("tuneminal-lyrics-%d.sock", os.Getuid()))
+}
+
+// Server sends the lyrics state to every connected view, one JSON object a
+// line, whenever it changes
+type Server struct {
+	path     string
+	listener net.Listener
+	mutex    sync.Mutex
+	clients  map[net.Conn]bool
+	last     []byte
+}
+
+// Listen starts serving views on the socket at path. A socket left behind by
+// a player that exited is replaced; one another player is serving is not.
+func Listen(path string) (*Server, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("another Tuneminal is serving lyrics views on %s", path)
+		}
+		os.Remove(path)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for lyrics views: %w", err)
+	}
+	s := &Server{path: path, listener: listener, clients: make(map[net.Conn]bool)}
+	go s.accept()
+	return s, nil
+}
+
+// accept takes new views, sending each the current state straight away
+func (s *Server) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mutex.Lock()
+		if s.last == nil || s.send(conn, s.last) {
+			s.clients[conn] = true
+		}
+		s.mutex.Unlock()
+	}
+}
+
+// send writes a message to one view, closing it on failure (caller must
+// hold the mutex)
+func (s *Server) send(conn net.Conn, message []byte) bool {
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := conn.Write(message); err != nil {
+		conn.Close()
+		return false
+	}
+	return true
+}
+
+// Publish sends state to the connected views if it changed
+func (s *Server) Publish(state State) {
+	message, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	message = append(message, '\n')
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if bytes.Equal(message, s.last) {
+		return
+	}
+	s.last = message
+	for conn := range s.clients {
+		if !s.send(conn, message) {
+			delete(s.clients, conn)
+		}
+	}
+}
+
+// Views returns how many views are connected
+func (s *Server) Views() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.clients)
+}
+
+// Close disconnects the views and removes the socket
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.mutex.Lock()
+	for conn := range s.clients {
+		conn.Close()
+	}
+	s.clients = nil
+	s.mutex.Unlock()
+	os.Remove(s.path)
+	return err
+}
+
+// Dial connects a view to the player serving on path
+func Dial(path string) (net.Conn, error) {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("no Tuneminal is serving lyrics views on %s", path)
+	}
+	return conn, nil
+}
+
+// Read calls onState with each state the player sends until the connection
+// ends
+func Read(r io.Reader, onState func(State)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var state State
+		if err := json.Unmarshal(scanner.Bytes(), &state); err != nil {
+			return fmt.Errorf("unexpected message from the player: %w", err)
+		}
+		onState(state)
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package lyricsview
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServerPublish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lyrics.sock")
+	server, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error: %v", err)
+	}
+	defer server.Close()
+
+	if _, err := Listen(path); err == nil {
+		t.Error("Expected a second player not to take over the socket")
+	}
+
+	server.Publish(State{Title: "Waterloo", Current: "My my", Playing: true, Lyrics: true})
+
+	conn, err := Dial(path)
+	if err != nil {
+		t.Fatalf("Dial() error: %v", err)
+	}
+	states := make(chan State, 4)
+	go Read(conn, func(state State) { states <- state })
+
+	next := func() State {
+		select {
+		case state := <-states:
+			return state
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a state")
+			return State{}
+		}
+	}
+
+	// A new view gets the current state straight away, then each change once
+	if state := next(); state.Current != "My my" || state.Title != "Waterloo" {
+		t.Errorf("Unexpected first state: %+v", state)
+	}
+	server.Publish(State{Title: "Waterloo", Current: "My my", Playing: true, Lyrics: true})
+	server.Publish(State{Title: "Waterloo", Previous: "My my", Current: "At Waterloo", Playing: true, Lyrics: true})
+	if state := next(); state.Current != "At Waterloo" || state.Previous != "My my" {
+		t.Errorf("Expected the repeated state to be skipped, got %+v", state)
+	}
+	if server.Views() != 1 {
+		t.Errorf("Views() = %d, want 1", server.Views())
+	}
+
+	server.Close()
+	conn.Close()
+	again, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Expected the socket to be free after Close, got %v", err)
+	}
+	again.Close()
+}
@@ -0,0 +1,336 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// id3Frame is a single raw ID3v2 frame
+type id3Frame struct {
+	ID    string
+	Flags [2]byte
+	Data  []byte
+}
+
+// readID3Tag reads the raw ID3v2 tag (header included) from the start of r
+func readID3Tag(r io.ReaderAt) []byte {
+	header := make([]byte, 10)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil
+	}
+	size := id3v2Size(header)
+	if size == 0 || size > 64<<20 {
+		return nil
+	}
+
+	tag := make([]byte, size)
+	if _, err := r.ReadAt(tag, 0); err != nil && err != io.EOF {
+		return nil
+	}
+	return tag
+}
+
+// parseID3Frames splits an ID3v2.3/2.4 tag into frames
+func parseID3Frames(tag []byte) []id3Frame {
+	if len(tag) < 10 || string(tag[:3]) != "ID3" {
+		return nil
+	}
+	version := tag[3]
+	if version == 2 {
+		return splitID3v22Frames(tag[10:])
+	}
+	if version < 2 {
+		return nil
+	}
+
+	body := tag[10:]
+	if tag[5]&0x40 != 0 && len(body) >= 4 {
+		// Skip the extended header
+		extSize := int(binary.BigEndian.Uint32(body[:4]))
+		if version == 4 {
+			extSize = syncsafe(body[:4])
+		} else {
+			extSize += 4
+		}
+		if extSize > len(body) {
+			return nil
+		}
+		body = body[extSize:]
+	}
+
+	return splitID3Frames(body, version)
+}
+
+// splitID3Frames walks a sequence of frames, as found in a tag body or
+// embedded inside a CHAP frame
+func splitID3Frames(body []byte, version byte) []id3Frame {
+	var frames []id3Frame
+	for len(body) >= 10 && body[0] != 0 {
+		id := string(body[:4])
+		size := int(binary.BigEndian.Uint32(body[4:8]))
+		if version == 4 {
+			size = syncsafe(body[4:8])
+		}
+		if size < 0 || 10+size > len(body) {
+			break
+		}
+		frames = append(frames, id3Frame{ID: id, Flags: [2]byte{body[8], body[9]}, Data: body[10 : 10+size]})
+		body = body[10+size:]
+	}
+	return frames
+}
+
+// id3v22Frames maps the three-character frame IDs of ID3v2.2 onto the
+// later ones they became, for the frames that are read
+var id3v22Frames = map[string]string{
+	"TT2": "TIT2",
+	"TP1": "TPE1",
+	"TAL": "TALB",
+	"TRK": "TRCK",
+	"TYE": "TYER",
+	"ULT": "USLT",
+	"SLT": "SYLT",
+}
+
+// splitID3v22Frames walks the frames of an ID3v2.2 tag body, which have
+// three-character IDs and sizes, keeping those that are read
+func splitID3v22Frames(body []byte) []id3Frame {
+	var frames []id3Frame
+	for len(body) >= 6 && body[0] != 0 {
+		size := int(body[3])<<16 | int(body[4])<<8 | int(body[5])
+		if 6+size > len(body) {
+			break
+		}
+		if id, ok := id3v22Frames[string(body[:3])]; ok {
+			frames = append(frames, id3Frame{ID: id, Data: body[6 : 6+size]})
+		}
+		body = body[6+size:]
+	}
+	return frames
+}
+
+// syncsafe decodes a 28-bit syncsafe integer
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7F)<<21 | int(b[1]&0x7F)<<14 | int(b[2]&0x7F)<<7 | int(b[3]&0x7F)
+}
+
+// decodeID3Text decodes a text frame body (encoding byte followed by text)
+func decodeID3Text(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	return strings.TrimRight(decodeID3String(data[0], data[1:]), "\x00")
+}
+
+// decodeID3String decodes text in the given ID3 encoding
+func decodeID3String(encoding byte, data []byte) string {
+	switch encoding {
+	case 1, 2:
+		// UTF-16 with BOM, or UTF-16BE without one
+		order := binary.ByteOrder(binary.BigEndian)
+		if len(data) >= 2 {
+			if data[0] == 0xFF && data[1] == 0xFE {
+				order, data = binary.LittleEndian, data[2:]
+			} else if data[0] == 0xFE && data[1] == 0xFF {
+				data = data[2:]
+			}
+		}
+		units := make([]uint16, 0, len(data)/2)
+		for i := 0; i+1 < len(data); i += 2 {
+			units = append(units, order.Uint16(data[i:i+2]))
+		}
+		return string(utf16.Decode(units))
+	case 3:
+		return string(data)
+	default:
+		// ISO-8859-1 maps directly onto the first 256 code points
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	}
+}
+
+// splitTerminated splits data at the first string terminator for the encoding
+func splitTerminated(encoding byte, data []byte) (head, rest []byte) {
+	if encoding == 1 || encoding == 2 {
+		for i := 0; i+1 < len(data); i += 2 {
+			if data[i] == 0 && data[i+1] == 0 {
+				return data[:i], data[i+2:]
+			}
+		}
+		return data, nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return data[:i], data[i+1:]
+	}
+	return data, nil
+}
+
+// id3Chapters extracts CHAP frames as chapters
+func id3Chapters(frames []id3Frame, version byte) []Chapter {
+	var chapters []Chapter
+	for _, frame := range frames {
+		if frame.ID != "CHAP" {
+			continue
+		}
+		// Element ID, then start/end times and byte offsets
+		_, rest := splitTerminated(0, frame.Data)
+		if len(rest) < 16 {
+			continue
+		}
+		start := time.Duration(binary.BigEndian.Uint32(rest[:4])) * time.Millisecond
+		chapter := Chapter{Start: start}
+
+		for _, sub := range splitID3Frames(rest[16:], version) {
+			if sub.ID == "TIT2" {
+				chapter.Title = decodeID3Text(sub.Data)
+			}
+		}
+		chapters = append(chapters, chapter)
+	}
+	return chapters
+}
+
+// id3TagKeys maps ID3v2 text frames to normalized tag keys
+var id3TagKeys = map[string]string{
+	"TIT2": "title",
+	"TPE1": "artist",
+	"TPE2": "albumartist",
+	"TALB": "album",
+	"TRCK": "tracknumber",
+	"TYER": "date",
+	"TDRC": "date",
+	"TCON": "genre",
+}
+
+// id3Tags reads the text frames and lyrics of a tag into normalized tags,
+// as probeFile gives them for other formats
+func id3Tags(frames []id3Frame) map[string]string {
+	tags := make(map[string]string)
+	for _, frame := range frames {
+		if frame.ID == "SYLT" {
+			if tags["syncedlyrics"] == "" {
+				tags["syncedlyrics"] = syltLRC(frame.Data)
+			}
+			continue
+		}
+		if frame.ID == "USLT" {
+			// Encoding, language and a description come before the lyrics
+			if len(frame.Data) < 4 || tags["lyrics"] != "" {
+				continue
+			}
+			encoding := frame.Data[0]
+			_, text := splitTerminated(encoding, frame.Data[4:])
+			tags["lyrics"] = strings.TrimRight(decodeID3String(encoding, text), "\x00")
+			continue
+		}
+		key, ok := id3TagKeys[frame.ID]
+		if !ok || tags[key] != "" {
+			continue
+		}
+		// ID3v2.4 separates several values with nulls
+		value := strings.ReplaceAll(decodeID3Text(frame.Data), "\x00", "/")
+		tags[key] = strings.TrimSpace(value)
+	}
+	return tags
+}
+
+// syltLRC writes the synchronised lyrics of a SYLT frame as LRC. Each sync
+// point is often a syllable or word, with a new line starting at a point
+// whose text begins with a line break; when no point has one, each is a line
+// of its own. Only timestamps in milliseconds are read, as the other kind
+// counts MPEG frames.
+func syltLRC(data []byte) string {
+	// Encoding, language, timestamp format and content type, then a description
+	if len(data) < 6 || data[4] != 2 {
+		return ""
+	}
+	encoding := data[0]
+	_, rest := splitTerminated(encoding, data[6:])
+
+	type syncPoint struct {
+		text string
+		at   time.Duration
+	}
+	var points []syncPoint
+	breaks := false
+	for len(rest) > 0 {
+		text, after := splitTerminated(encoding, rest)
+		if len(after) < 4 {
+			break
+		}
+		point := syncPoint{
+			text: decodeID3String(encoding, text),
+			at:   time.Duration(binary.BigEndian.Uint32(after[:4])) * time.Millisecond,
+		}
+		breaks = breaks || strings.TrimLeft(point.text, "\r\n") != point.text
+		points = append(points, point)
+		rest = after[4:]
+	}
+
+	var lrc, line strings.Builder
+	var start time.Duration
+	endLine := func() {
+		if text := strings.TrimSpace(line.String()); text != "" {
+			fmt.Fprintf(&lrc, "[%02d:%05.2f]%s\n", int(start.Minutes()), (start % time.Minute).Seconds(), text)
+		}
+		line.Reset()
+	}
+	for i, point := range points {
+		text := strings.TrimLeft(point.text, "\r\n")
+		if !breaks || i == 0 || text != point.text {
+			endLine()
+			start = point.at
+		}
+		line.WriteString(text)
+	}
+	endLine()
+	return lrc.String()
+}
+
+// id3v1Size is the length of the ID3v1 tag at the end of an MP3
+const id3v1Size = 128
+
+// readID3v1 fills in tags the ID3v2 tag left empty from an ID3v1 tag at the
+// end of file, which older rippers wrote on its own
+func readID3v1(file *os.File, tags map[string]string) {
+	info, err := file.Stat()
+	if err != nil || info.Size() < id3v1Size {
+		return
+	}
+	tag := make([]byte, id3v1Size)
+	if _, err := file.ReadAt(tag, info.Size()-id3v1Size); err != nil || string(tag[:3]) != "TAG" {
+		return
+	}
+
+	field := func(b []byte) string {
+		if i := bytes.IndexByte(b, 0); i >= 0 {
+			b = b[:i]
+		}
+		return strings.TrimSpace(decodeID3String(0, b))
+	}
+	values := map[string]string{
+		"title":  field(tag[3:33]),
+		"artist": field(tag[33:63]),
+		"album":  field(tag[63:93]),
+		"date":   field(tag[93:97]),
+	}
+	// ID3v1.1 keeps the track number in the last byte of the comment
+	if tag[125] == 0 && tag[126] != 0 {
+		values["tracknumber"] = strconv.Itoa(int(tag[126]))
+	}
+	for key, value := range values {
+		if tags[key] == "" && value != "" {
+			tags[key] = value
+		}
+	}
+}
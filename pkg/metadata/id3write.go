@@ -0,0 +1,169 @@
+package metadata
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// ErrCannotEmbed is returned for files whose tags lyrics can't be written into
+var ErrCannotEmbed = errors.New("lyrics can only be written into the ID3v2.3 or ID3v2.4 tags of MP3 files")
+
+// id3Padding is the free space left in a rewritten tag, so the next edit
+// can often be made in place by other taggers
+const id3Padding = 2048
+
+// TimedLine is a line of lyrics and when it is sung, to be written into tags
+type TimedLine struct {
+	Time time.Duration
+	Text string
+}
+
+// CanEmbedLyrics reports whether lyrics can be written into the tags of the
+// file at path, going by its format
+func CanEmbedLyrics(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".mp3")
+}
+
+// EmbedLyrics writes lyrics into the ID3v2 tag of an MP3: as USLT, which
+// every player that shows lyrics reads, and with synced as SYLT too, with
+// each line's time. Lyrics frames already there are replaced and the rest of
+// the tag is kept; a file without a tag gets an ID3v2.3 one. The file is
+// written anew beside the old one and moved over it, so a failure leaves it
+// as it was.
+func EmbedLyrics(path string, lines []TimedLine, synced bool) error {
+	if !CanEmbedLyrics(path) {
+		return ErrCannotEmbed
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	version := byte(3)
+	var kept []id3Frame
+	tag := readID3Tag(file)
+	if tag != nil {
+		version = tag[3]
+		if version != 3 && version != 4 {
+			return ErrCannotEmbed
+		}
+		if tag[5]&0x80 != 0 {
+			return fmt.Errorf("the ID3 tag of %s is unsynchronised, which can't be rewritten", filepath.Base(path))
+		}
+		for _, frame := range parseID3Frames(tag) {
+			if frame.ID != "USLT" && frame.ID != "SYLT" {
+				kept = append(kept, frame)
+			}
+		}
+	}
+
+	encoding := byte(1) // UTF-16, as ID3v2.3 has no UTF-8
+	if version == 4 {
+		encoding = 3
+	}
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.Text
+	}
+	frames := append(kept, id3Frame{ID: "USLT", Data: usltData(encoding, strings.Join(texts, "\n"))})
+	if synced {
+		frames = append(frames, id3Frame{ID: "SYLT", Data: syltData(encoding, lines)})
+	}
+
+	var body []byte
+	for _, frame := range frames {
+		body = append(body, frame.ID...)
+		if version == 4 {
+			body = append(body, syncsafeBytes(len(frame.Data))...)
+		} else {
+			body = binary.BigEndian.AppendUint32(body, uint32(len(frame.Data)))
+		}
+		body = append(body, frame.Flags[:]...)
+		body = append(body, frame.Data...)
+	}
+	body = append(body, make([]byte, id3Padding)...)
+	header := append([]byte{'I', 'D', '3', version, 0, 0}, syncsafeBytes(len(body))...)
+
+	// Write the new tag and the audio after the old one to a file beside it
+	if _, err := file.Seek(int64(len(tag)), io.SeekStart); err != nil {
+		return err
+	}
+	out, err := os.CreateTemp(filepath.Dir(path), ".tuneminal-tag-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name())
+	if _, err := out.Write(append(header, body...)); err != nil {
+		out.Close()
+		return err
+	}
+	if _, err := io.Copy(out, file); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(out.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), path)
+}
+
+// usltData builds the body of a USLT frame: encoding, unknown language, an
+// empty description and the text
+func usltData(encoding byte, text string) []byte {
+	data := append([]byte{encoding}, "XXX"...)
+	data = append(data, encodeID3String(encoding, "", true)...)
+	return append(data, encodeID3String(encoding, text, false)...)
+}
+
+// syltData builds the body of a SYLT frame with one sync point per line,
+// timed in milliseconds
+func syltData(encoding byte, lines []TimedLine) []byte {
+	data := append([]byte{encoding}, "XXX"...)
+	data = append(data, 2, 1) // milliseconds, lyrics
+	data = append(data, encodeID3String(encoding, "", true)...)
+	for _, line := range lines {
+		data = append(data, encodeID3String(encoding, line.Text, true)...)
+		data = binary.BigEndian.AppendUint32(data, uint32(max(line.Time, 0).Milliseconds()))
+	}
+	return data
+}
+
+// encodeID3String encodes text as UTF-8 or as UTF-16 with a byte order mark,
+// ending it with a terminator when terminated
+func encodeID3String(encoding byte, text string, terminated bool) []byte {
+	if encoding != 1 {
+		data := []byte(text)
+		if terminated {
+			data = append(data, 0)
+		}
+		return data
+	}
+	data := []byte{0xFF, 0xFE}
+	for _, unit := range utf16.Encode([]rune(text)) {
+		data = binary.LittleEndian.AppendUint16(data, unit)
+	}
+	if terminated {
+		data = append(data, 0, 0)
+	}
+	return data
+}
+
+// syncsafeBytes encodes n as a 28-bit syncsafe integer
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+}
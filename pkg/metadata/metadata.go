@@ -4,22 +4,56 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/faiface/beep"
 	"github.com/faiface/beep/mp3"
 	"github.com/faiface/beep/wav"
+	"github.com/tuneminal/tuneminal/pkg/utils"
 )
 
 // SongMetadata contains real metadata from audio files
 type SongMetadata struct {
-	Title    string
-	Artist   string
-	Duration time.Duration
-	Format   string
-	Path     string
-	Size     int64
+	Title        string
+	Artist       string
+	Album        string
+	TrackNumber  int    // 0 when not tagged
+	Year         int    // 0 when not tagged
+	Lyrics       string // unsynchronised lyrics embedded in the file
+	SyncedLyrics string // synchronised lyrics embedded in the file, as LRC
+	Duration     time.Duration
+	Format       string
+	Path         string
+	Size         int64
+	Chapters     []Chapter
+}
+
+// Chapter marks a named position within a long track
+type Chapter struct {
+	Title string
+	Start time.Duration
+}
+
+// supportedFormats lists the extensions ScanDirectory picks up
+var supportedFormats = map[string]bool{
+	".mp3":  true,
+	".wav":  true,
+	".flac": true,
+	".m4a":  true,
+	".m4b":  true,
+	".mp4":  true,
+	".aac":  true,
+	".ogg":  true,
+	".oga":  true,
+	".opus": true,
+}
+
+// IsSupportedFormat reports whether files with the given extension can be scanned
+func IsSupportedFormat(ext string) bool {
+	return supportedFormats[strings.ToLower(ext)]
 }
 
 // GetRealMetadata reads actual metadata from audio files
@@ -39,56 +73,110 @@ func GetRealMetadata(filePath string) (*SongMetadata, error) {
 
 	// Determine file type
 	ext := strings.ToLower(filepath.Ext(filePath))
-	var streamer beep.StreamSeeker
-	var format beep.Format
+	var duration time.Duration
+	var tags map[string]string
+	var chapters []Chapter
 
 	switch ext {
-	case ".mp3":
-		streamer, format, err = mp3.Decode(file)
-		if err != nil {
-			return nil, fmt.Errorf("cannot decode MP3: %w", err)
+	case ".mp3", ".wav":
+		var streamer beep.StreamSeekCloser
+		var format beep.Format
+		if ext == ".mp3" {
+			tags = make(map[string]string)
+			if tag := readID3Tag(file); tag != nil {
+				frames := parseID3Frames(tag)
+				chapters = id3Chapters(frames, tag[3])
+				tags = id3Tags(frames)
+			}
+			readID3v1(file, tags)
+			streamer, format, err = mp3.Decode(file)
+			if err != nil {
+				return nil, fmt.Errorf("cannot decode MP3: %w", err)
+			}
+		} else {
+			streamer, format, err = wav.Decode(file)
+			if err != nil {
+				return nil, fmt.Errorf("cannot decode WAV: %w", err)
+			}
 		}
-	case ".wav":
-		streamer, format, err = wav.Decode(file)
+
+		// Calculate real duration from samples
+		samples := streamer.Len()
+		duration = utils.FramesDuration(int64(samples), int(format.SampleRate))
+		streamer.Close()
+	default:
+		// Other containers are probed from their headers only
+		result, err := probeFile(file, ext)
 		if err != nil {
-			return nil, fmt.Errorf("cannot decode WAV: %w", err)
+			return nil, fmt.Errorf("cannot read %s: %w", ext, err)
 		}
-	default:
-		return nil, fmt.Errorf("unsupported format: %s", ext)
+		duration = result.Duration
+		tags = result.Tags
+		chapters = result.Chapters
 	}
 
-	// Calculate real duration from samples
-	samples := streamer.Len()
-	duration := time.Duration(samples) * time.Second / time.Duration(format.SampleRate)
+	sort.Slice(chapters, func(i, j int) bool {
+		return chapters[i].Start < chapters[j].Start
+	})
 
-	// Extract title and artist from filename
+	// Prefer tags, fall back to the filename
 	title, artist := extractFromFilename(filepath.Base(filePath))
+	if tags["title"] != "" {
+		title = tags["title"]
+	}
+	if tags["artist"] != "" {
+		artist = tags["artist"]
+	}
 
-	// Close streamer if it implements Closer
-	if closer, ok := streamer.(interface{ Close() error }); ok {
-		closer.Close()
+	lyrics := tags["lyrics"]
+	if lyrics == "" {
+		lyrics = tags["unsyncedlyrics"]
 	}
 
 	return &SongMetadata{
-		Title:    title,
-		Artist:   artist,
-		Duration: duration,
-		Format:   ext,
-		Path:     filePath,
-		Size:     fileInfo.Size(),
+		Title:        title,
+		Artist:       artist,
+		Album:        tags["album"],
+		TrackNumber:  leadingNumber(tags["tracknumber"]),
+		Year:         leadingNumber(tags["date"]),
+		Lyrics:       lyrics,
+		SyncedLyrics: tags["syncedlyrics"],
+		Duration:     duration,
+		Format:       ext,
+		Path:         filePath,
+		Size:         fileInfo.Size(),
+		Chapters:     chapters,
 	}, nil
 }
 
+// leadingNumber reads the number a tag value starts with, such as the track
+// in "3/12" or the year in "1999-05-01", or 0 when there is none
+func leadingNumber(value string) int {
+	value = strings.TrimSpace(value)
+	end := 0
+	for end < len(value) && value[end] >= '0' && value[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(value[:end])
+	return n
+}
+
+// GuessFromFilename returns the title and artist a file name suggests, for
+// files whose tags can't be read yet
+func GuessFromFilename(filename string) (title, artist string) {
+	return extractFromFilename(filename)
+}
+
 // extractFromFilename extracts title and artist from filename
 func extractFromFilename(filename string) (title, artist string) {
 	// Remove extension
 	name := strings.TrimSuffix(filename, filepath.Ext(filename))
-	
+
 	// Try different patterns
 	patterns := []string{
-		" - ",  // "Artist - Title"
-		" – ",  // "Artist – Title" (en dash)
-		"_",    // "Artist_Title" or "Title_With_Underscores"
+		" - ", // "Artist - Title"
+		" – ", // "Artist – Title" (en dash)
+		"_",   // "Artist_Title" or "Title_With_Underscores"
 	}
 
 	for _, pattern := range patterns {
@@ -116,34 +204,99 @@ func extractFromFilename(filename string) (title, artist string) {
 	return name, "Unknown"
 }
 
-// ScanDirectory scans directory for audio files and returns real metadata
-func ScanDirectory(dir string) ([]*SongMetadata, error) {
-	var songs []*SongMetadata
+// ListAudioFiles returns the supported audio files under dir without reading them
+func ListAudioFiles(dir string) ([]string, error) {
+	var files []string
 
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if info.IsDir() {
-			return nil
+		if !info.IsDir() && IsSupportedFormat(filepath.Ext(path)) {
+			files = append(files, path)
 		}
+		return nil
+	})
 
-		ext := strings.ToLower(filepath.Ext(path))
-		if ext != ".mp3" && ext != ".wav" {
-			return nil
-		}
+	return files, err
+}
 
+// ScanDirectory scans directory for audio files and returns real metadata
+func ScanDirectory(dir string) ([]*SongMetadata, error) {
+	var songs []*SongMetadata
+
+	files, err := ListAudioFiles(dir)
+	for _, path := range files {
 		// Get real metadata from file
 		metadata, err := GetRealMetadata(path)
 		if err != nil {
 			// Skip files that can't be read
-			return nil
+			continue
 		}
 
 		songs = append(songs, metadata)
-		return nil
-	})
+	}
 
 	return songs, err
 }
+
+// VerifyDecode decodes a whole file to catch corruption and truncation that
+// header-only reads miss
+func VerifyDecode(filePath string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		return fmt.Errorf("file is empty")
+	}
+
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if ext != ".mp3" && ext != ".wav" {
+		// Only the container can be checked for other formats
+		meta, err := GetRealMetadata(filePath)
+		if err != nil {
+			return err
+		}
+		if meta.Duration <= 0 {
+			return fmt.Errorf("no audio found")
+		}
+		return nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var streamer beep.StreamSeekCloser
+	if ext == ".mp3" {
+		streamer, _, err = mp3.Decode(file)
+	} else {
+		streamer, _, err = wav.Decode(file)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot decode: %w", err)
+	}
+	defer streamer.Close()
+
+	expected := streamer.Len()
+	decoded := 0
+	buffer := make([][2]float64, 4096)
+	for {
+		n, ok := streamer.Stream(buffer)
+		decoded += n
+		if !ok {
+			break
+		}
+	}
+	if err := streamer.Err(); err != nil {
+		return fmt.Errorf("decode failed after %d samples: %w", decoded, err)
+	}
+	if expected > 0 && decoded < expected*99/100 {
+		return fmt.Errorf("truncated: decoded %d of %d samples", decoded, expected)
+	}
+	return nil
+}
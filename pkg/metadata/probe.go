@@ -0,0 +1,474 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// probeResult holds what could be learned from a container header
+// without decoding any audio
+type probeResult struct {
+	Duration time.Duration
+	Tags     map[string]string // normalized keys: title, artist, album, ...
+	Chapters []Chapter
+}
+
+// probeFile reads duration and tags from formats beep cannot decode
+func probeFile(file *os.File, ext string) (*probeResult, error) {
+	switch ext {
+	case ".m4a", ".mp4", ".m4b":
+		return probeMP4(file)
+	case ".aac":
+		return probeADTS(file)
+	case ".ogg", ".oga", ".opus":
+		return probeOgg(file)
+	case ".flac":
+		return probeFLAC(file)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", ext)
+	}
+}
+
+// mp4TagKeys maps iTunes-style ilst atoms to normalized tag keys
+var mp4TagKeys = map[string]string{
+	"\xa9nam": "title",
+	"\xa9ART": "artist",
+	"aART":    "albumartist",
+	"\xa9alb": "album",
+	"\xa9day": "date",
+	"\xa9gen": "genre",
+	"\xa9lyr": "lyrics",
+	"trkn":    "tracknumber",
+}
+
+// probeMP4 reads the movie header and iTunes metadata from an MP4 container
+func probeMP4(file *os.File) (*probeResult, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// Locate the moov atom among the top-level atoms
+	var moov []byte
+	var offset int64
+	for offset < info.Size() {
+		size, kind, headerLen, err := readAtomHeader(file, offset)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			size = info.Size() - offset
+		}
+		if size < headerLen {
+			return nil, fmt.Errorf("invalid atom size")
+		}
+		if kind == "moov" {
+			if size > 64<<20 {
+				return nil, fmt.Errorf("moov atom too large")
+			}
+			moov = make([]byte, size-headerLen)
+			if _, err := file.ReadAt(moov, offset+headerLen); err != nil {
+				return nil, err
+			}
+			break
+		}
+		offset += size
+	}
+	if moov == nil {
+		return nil, fmt.Errorf("no moov atom found")
+	}
+
+	result := &probeResult{Tags: make(map[string]string)}
+
+	if mvhd := findAtom(moov, "mvhd"); len(mvhd) >= 20 {
+		var timescale, duration uint64
+		if mvhd[0] == 1 && len(mvhd) >= 32 {
+			timescale = uint64(binary.BigEndian.Uint32(mvhd[20:24]))
+			duration = binary.BigEndian.Uint64(mvhd[24:32])
+		} else {
+			timescale = uint64(binary.BigEndian.Uint32(mvhd[12:16]))
+			duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+		}
+		if timescale > 0 {
+			result.Duration = utils.FramesDuration(int64(duration), int(timescale))
+		}
+	}
+
+	if meta := findAtom(moov, "udta", "meta"); len(meta) > 4 {
+		// meta is a full atom: skip version and flags
+		if ilst := findAtom(meta[4:], "ilst"); ilst != nil {
+			parseILST(ilst, result.Tags)
+		}
+	}
+
+	if chpl := findAtom(moov, "udta", "chpl"); chpl != nil {
+		result.Chapters = parseCHPL(chpl)
+	}
+
+	return result, nil
+}
+
+// parseCHPL reads Nero-style chapter markers from a chpl atom
+func parseCHPL(chpl []byte) []Chapter {
+	if len(chpl) < 5 {
+		return nil
+	}
+	pos := 4 // version and flags
+	if chpl[0] != 0 {
+		pos += 4 // reserved
+	}
+	if pos >= len(chpl) {
+		return nil
+	}
+	count := int(chpl[pos])
+	pos++
+
+	var chapters []Chapter
+	for i := 0; i < count && pos+9 <= len(chpl); i++ {
+		// Start times are in 100ns units
+		start := time.Duration(binary.BigEndian.Uint64(chpl[pos:pos+8])) * 100
+		titleLen := int(chpl[pos+8])
+		pos += 9
+		if pos+titleLen > len(chpl) {
+			break
+		}
+		chapters = append(chapters, Chapter{
+			Title: string(chpl[pos : pos+titleLen]),
+			Start: start,
+		})
+		pos += titleLen
+	}
+	return chapters
+}
+
+// readAtomHeader reads an atom header at offset, handling 64-bit sizes
+func readAtomHeader(r io.ReaderAt, offset int64) (size int64, kind string, headerLen int64, err error) {
+	header := make([]byte, 16)
+	if _, err := r.ReadAt(header[:8], offset); err != nil {
+		return 0, "", 0, err
+	}
+	size = int64(binary.BigEndian.Uint32(header[:4]))
+	kind = string(header[4:8])
+	headerLen = 8
+	if size == 1 {
+		if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+			return 0, "", 0, err
+		}
+		size = int64(binary.BigEndian.Uint64(header[8:16]))
+		headerLen = 16
+	}
+	return size, kind, headerLen, nil
+}
+
+// findAtom walks a path of nested atoms inside data and returns the payload
+func findAtom(data []byte, path ...string) []byte {
+	for _, name := range path {
+		found := false
+		for len(data) >= 8 {
+			size := int(binary.BigEndian.Uint32(data[:4]))
+			if size < 8 || size > len(data) {
+				return nil
+			}
+			if string(data[4:8]) == name {
+				data = data[8:size]
+				found = true
+				break
+			}
+			data = data[size:]
+		}
+		if !found {
+			return nil
+		}
+	}
+	return data
+}
+
+// parseILST extracts known text items from an ilst atom
+func parseILST(ilst []byte, tags map[string]string) {
+	for len(ilst) >= 8 {
+		size := int(binary.BigEndian.Uint32(ilst[:4]))
+		if size < 8 || size > len(ilst) {
+			return
+		}
+		kind := string(ilst[4:8])
+		item := ilst[8:size]
+		ilst = ilst[size:]
+
+		key, ok := mp4TagKeys[kind]
+		if !ok {
+			continue
+		}
+		data := findAtom(item, "data")
+		if len(data) < 8 {
+			continue
+		}
+		value := data[8:] // skip type indicator and locale
+		if kind == "trkn" {
+			if len(value) >= 4 {
+				tags[key] = fmt.Sprintf("%d", binary.BigEndian.Uint16(value[2:4]))
+			}
+			continue
+		}
+		tags[key] = strings.TrimSpace(string(value))
+	}
+}
+
+// adtsSampleRates indexes the ADTS sampling_frequency_index field
+var adtsSampleRates = []int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+// probeADTS computes the duration of a raw AAC stream by walking its frames
+func probeADTS(file *os.File) (*probeResult, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip a leading ID3v2 tag if present
+	pos := id3v2Size(data)
+
+	sampleRate := 0
+	var samples int64
+	for pos+7 <= len(data) {
+		if data[pos] != 0xFF || data[pos+1]&0xF0 != 0xF0 {
+			return nil, fmt.Errorf("invalid ADTS frame at offset %d", pos)
+		}
+		rateIndex := int(data[pos+2]>>2) & 0x0F
+		if rateIndex >= len(adtsSampleRates) {
+			return nil, fmt.Errorf("invalid ADTS sample rate index")
+		}
+		sampleRate = adtsSampleRates[rateIndex]
+		frameLength := int(data[pos+3]&0x03)<<11 | int(data[pos+4])<<3 | int(data[pos+5])>>5
+		if frameLength < 7 {
+			return nil, fmt.Errorf("invalid ADTS frame length")
+		}
+		blocks := int(data[pos+6]&0x03) + 1
+		samples += int64(blocks * 1024)
+		pos += frameLength
+	}
+
+	if sampleRate == 0 {
+		return nil, fmt.Errorf("no ADTS frames found")
+	}
+
+	return &probeResult{
+		Duration: utils.FramesDuration(int64(samples), int(sampleRate)),
+		Tags:     make(map[string]string),
+	}, nil
+}
+
+// id3v2Size returns the length of an ID3v2 tag at the start of data, or 0
+func id3v2Size(data []byte) int {
+	if len(data) < 10 || string(data[:3]) != "ID3" {
+		return 0
+	}
+	size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+	if data[5]&0x10 != 0 {
+		size += 10 // footer present
+	}
+	return 10 + size
+}
+
+// probeOgg reads Opus or Vorbis headers and the final granule position
+func probeOgg(file *os.File) (*probeResult, error) {
+	packets, err := readOggPackets(file, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(packets) < 2 {
+		return nil, fmt.Errorf("missing Ogg header packets")
+	}
+
+	result := &probeResult{Tags: make(map[string]string)}
+	ident, comments := packets[0], packets[1]
+
+	var sampleRate, preSkip int64
+	switch {
+	case bytes.HasPrefix(ident, []byte("OpusHead")) && len(ident) >= 19:
+		// Opus granule positions always count 48kHz samples
+		sampleRate = 48000
+		preSkip = int64(binary.LittleEndian.Uint16(ident[10:12]))
+		if bytes.HasPrefix(comments, []byte("OpusTags")) {
+			parseVorbisComments(comments[8:], result.Tags)
+		}
+	case bytes.HasPrefix(ident, []byte("\x01vorbis")) && len(ident) >= 16:
+		sampleRate = int64(binary.LittleEndian.Uint32(ident[12:16]))
+		if bytes.HasPrefix(comments, []byte("\x03vorbis")) {
+			parseVorbisComments(comments[7:], result.Tags)
+		}
+	default:
+		return nil, fmt.Errorf("unknown Ogg codec")
+	}
+
+	granule, err := lastOggGranule(file)
+	if err != nil {
+		return nil, err
+	}
+	if sampleRate > 0 && granule > preSkip {
+		result.Duration = utils.FramesDuration(int64(granule-preSkip), int(sampleRate))
+	}
+
+	return result, nil
+}
+
+// readOggPackets reassembles the first n packets of the first logical stream
+func readOggPackets(r io.ReaderAt, n int) ([][]byte, error) {
+	var packets [][]byte
+	var current []byte
+	var offset int64
+
+	for len(packets) < n {
+		header := make([]byte, 27)
+		if _, err := r.ReadAt(header, offset); err != nil {
+			return packets, err
+		}
+		if string(header[:4]) != "OggS" {
+			return packets, fmt.Errorf("invalid Ogg page at offset %d", offset)
+		}
+		segments := make([]byte, header[26])
+		if _, err := r.ReadAt(segments, offset+27); err != nil {
+			return packets, err
+		}
+		offset += 27 + int64(len(segments))
+
+		for _, length := range segments {
+			data := make([]byte, length)
+			if _, err := r.ReadAt(data, offset); err != nil {
+				return packets, err
+			}
+			offset += int64(length)
+			current = append(current, data...)
+			if length < 255 {
+				packets = append(packets, current)
+				current = nil
+				if len(packets) == n {
+					break
+				}
+			}
+		}
+	}
+
+	return packets, nil
+}
+
+// lastOggGranule returns the granule position of the last page in the file
+func lastOggGranule(file *os.File) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	tailSize := int64(64 * 1024)
+	if tailSize > info.Size() {
+		tailSize = info.Size()
+	}
+	tail := make([]byte, tailSize)
+	if _, err := file.ReadAt(tail, info.Size()-tailSize); err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	index := bytes.LastIndex(tail, []byte("OggS"))
+	if index < 0 || index+14 > len(tail) {
+		return 0, fmt.Errorf("no Ogg page found at end of file")
+	}
+	return int64(binary.LittleEndian.Uint64(tail[index+6 : index+14])), nil
+}
+
+// parseVorbisComments parses a Vorbis comment block (vendor string and
+// KEY=value pairs) into tags with lowercase keys
+func parseVorbisComments(data []byte, tags map[string]string) {
+	if len(data) < 4 {
+		return
+	}
+	vendorLen := int(binary.LittleEndian.Uint32(data[:4]))
+	data = data[4:]
+	if vendorLen > len(data) {
+		return
+	}
+	data = data[vendorLen:]
+	if len(data) < 4 {
+		return
+	}
+	count := int(binary.LittleEndian.Uint32(data[:4]))
+	data = data[4:]
+
+	for i := 0; i < count && len(data) >= 4; i++ {
+		length := int(binary.LittleEndian.Uint32(data[:4]))
+		data = data[4:]
+		if length > len(data) {
+			return
+		}
+		comment := string(data[:length])
+		data = data[length:]
+
+		if key, value, ok := strings.Cut(comment, "="); ok {
+			tags[strings.ToLower(key)] = value
+		}
+	}
+}
+
+// FLAC metadata block types that are read
+const (
+	flacStreamInfo    = 0
+	flacVorbisComment = 4
+)
+
+// probeFLAC reads the stream info and Vorbis comments from the metadata
+// blocks at the start of a FLAC file
+func probeFLAC(file *os.File) (*probeResult, error) {
+	// A leading ID3v2 tag is allowed, though not in the spec
+	head := make([]byte, 10)
+	if _, err := file.ReadAt(head, 0); err != nil {
+		return nil, err
+	}
+	offset := int64(id3v2Size(head))
+
+	magic := make([]byte, 4)
+	if _, err := file.ReadAt(magic, offset); err != nil {
+		return nil, err
+	}
+	if string(magic) != "fLaC" {
+		return nil, fmt.Errorf("not a FLAC file")
+	}
+	offset += 4
+
+	result := &probeResult{Tags: make(map[string]string)}
+	for {
+		header := make([]byte, 4)
+		if _, err := file.ReadAt(header, offset); err != nil {
+			return nil, err
+		}
+		last := header[0]&0x80 != 0
+		kind := header[0] & 0x7F
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+		offset += 4
+
+		if kind == flacStreamInfo || kind == flacVorbisComment {
+			block := make([]byte, length)
+			if _, err := file.ReadAt(block, offset); err != nil {
+				return nil, err
+			}
+			if kind == flacVorbisComment {
+				parseVorbisComments(block, result.Tags)
+			} else if len(block) >= 18 {
+				// 20 bits of sample rate, then channels and bit depth, then
+				// 36 bits of total samples
+				sampleRate := int(block[10])<<12 | int(block[11])<<4 | int(block[12])>>4
+				samples := int64(block[13]&0x0F)<<32 | int64(binary.BigEndian.Uint32(block[14:18]))
+				if sampleRate > 0 {
+					result.Duration = utils.FramesDuration(samples, sampleRate)
+				}
+			}
+		}
+		offset += length
+		if last {
+			return result, nil
+		}
+	}
+}
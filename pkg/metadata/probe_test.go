@@ -0,0 +1,210 @@
+package metadata
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProbeADTS(t *testing.T) {
+	// Ten 44.1kHz frames of 1024 samples each
+	frame := []byte{0xFF, 0xF1, 0x50, 0x80, 0x01, 0x00, 0xFC}
+	frame[3] |= byte(len(frame) >> 11)
+	frame[4] = byte(len(frame) >> 3)
+	frame[5] = byte(len(frame)<<5) | 0x1F
+
+	var data []byte
+	for i := 0; i < 10; i++ {
+		data = append(data, frame...)
+	}
+
+	path := filepath.Join(t.TempDir(), "test.aac")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, _ := os.Open(path)
+	defer file.Close()
+
+	result, err := probeADTS(file)
+	if err != nil {
+		t.Fatalf("probeADTS() returned error: %v", err)
+	}
+
+	want := time.Duration(10*1024) * time.Second / 44100
+	if result.Duration != want {
+		t.Errorf("Expected duration %v, got %v", want, result.Duration)
+	}
+}
+
+func TestParseVorbisComments(t *testing.T) {
+	var data []byte
+	appendString := func(s string) {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(s)))
+		data = append(data, s...)
+	}
+
+	appendString("test vendor")
+	data = binary.LittleEndian.AppendUint32(data, 2)
+	appendString("TITLE=Heroes Tonight")
+	appendString("Artist=Janji")
+
+	tags := make(map[string]string)
+	parseVorbisComments(data, tags)
+
+	if tags["title"] != "Heroes Tonight" || tags["artist"] != "Janji" {
+		t.Errorf("Unexpected tags: %v", tags)
+	}
+}
+
+func TestID3Tags(t *testing.T) {
+	frame := func(id string, data []byte) []byte {
+		b := append([]byte(id), binary.BigEndian.AppendUint32(nil, uint32(len(data)))...)
+		return append(append(b, 0, 0), data...)
+	}
+	var body []byte
+	body = append(body, frame("TIT2", []byte("\x03Heroes Tonight"))...)
+	body = append(body, frame("TPE1", []byte("\x00Janji"))...)
+	body = append(body, frame("TRCK", []byte("\x034/12"))...)
+	body = append(body, frame("TYER", []byte("\x032015"))...)
+	body = append(body, frame("USLT", []byte("\x03eng\x00First line\nSecond line"))...)
+
+	tag := append([]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, byte(len(body))}, body...)
+	tags := id3Tags(parseID3Frames(tag))
+
+	if tags["title"] != "Heroes Tonight" || tags["artist"] != "Janji" {
+		t.Errorf("Unexpected tags: %v", tags)
+	}
+	if leadingNumber(tags["tracknumber"]) != 4 || leadingNumber(tags["date"]) != 2015 {
+		t.Errorf("Expected track 4 from 2015, got %q and %q", tags["tracknumber"], tags["date"])
+	}
+	if tags["lyrics"] != "First line\nSecond line" {
+		t.Errorf("Expected the embedded lyrics, got %q", tags["lyrics"])
+	}
+}
+
+func TestSYLTLyrics(t *testing.T) {
+	// UTF-8, English, milliseconds, lyrics, no description, then one point
+	// per word with a line break starting the second line
+	data := []byte("\x03eng\x02\x01\x00")
+	for _, point := range []struct {
+		text string
+		ms   uint32
+	}{{"Hello", 1000}, {" world", 1500}, {"\nSecond", 62250}, {" line", 63000}} {
+		data = append(append(data, point.text...), 0)
+		data = binary.BigEndian.AppendUint32(data, point.ms)
+	}
+
+	want := "[00:01.00]Hello world\n[01:02.25]Second line\n"
+	if got := syltLRC(data); got != want {
+		t.Errorf("syltLRC() = %q, want %q", got, want)
+	}
+
+	// Timestamps in MPEG frames aren't read
+	data[4] = 1
+	if got := syltLRC(data); got != "" {
+		t.Errorf("Expected nothing for frame timestamps, got %q", got)
+	}
+}
+
+func TestEmbedLyrics(t *testing.T) {
+	// An ID3v2.3 tag with a title and old lyrics, then the audio
+	frame := func(id string, data []byte) []byte {
+		b := append([]byte(id), binary.BigEndian.AppendUint32(nil, uint32(len(data)))...)
+		return append(append(b, 0, 0), data...)
+	}
+	body := append(frame("TIT2", []byte("\x03Heroes Tonight")), frame("USLT", []byte("\x03eng\x00Old words"))...)
+	audio := []byte("\xFF\xFBnot really audio")
+	path := filepath.Join(t.TempDir(), "song.mp3")
+	data := append(append([]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, byte(len(body))}, body...), audio...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := []TimedLine{{Time: time.Second, Text: "Héroes"}, {Time: 62250 * time.Millisecond, Text: "Tonight"}}
+	if err := EmbedLyrics(path, lines, true); err != nil {
+		t.Fatalf("EmbedLyrics() error = %v", err)
+	}
+
+	written, _ := os.ReadFile(path)
+	tag := written[:id3v2Size(written)]
+	tags := id3Tags(parseID3Frames(tag))
+	if tags["title"] != "Heroes Tonight" {
+		t.Errorf("Expected the title to be kept, got %q", tags["title"])
+	}
+	if tags["lyrics"] != "Héroes\nTonight" {
+		t.Errorf("Expected the new lyrics, got %q", tags["lyrics"])
+	}
+	if want := "[00:01.00]Héroes\n[01:02.25]Tonight\n"; tags["syncedlyrics"] != want {
+		t.Errorf("Expected synced lyrics %q, got %q", want, tags["syncedlyrics"])
+	}
+	if string(written[len(tag):]) != string(audio) {
+		t.Error("Expected the audio after the tag to be unchanged")
+	}
+
+	if err := EmbedLyrics(filepath.Join(t.TempDir(), "song.ogg"), lines, true); err != ErrCannotEmbed {
+		t.Errorf("Expected ErrCannotEmbed for Ogg, got %v", err)
+	}
+}
+
+func TestReadID3v1(t *testing.T) {
+	tag := make([]byte, id3v1Size)
+	copy(tag, "TAG")
+	copy(tag[3:], "Title")
+	copy(tag[33:], "Artist")
+	copy(tag[93:], "1999")
+	tag[126] = 7
+
+	path := filepath.Join(t.TempDir(), "old.mp3")
+	if err := os.WriteFile(path, append(make([]byte, 64), tag...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, _ := os.Open(path)
+	defer file.Close()
+
+	// The ID3v2 tag wins where it has a value
+	tags := map[string]string{"title": "Newer Title"}
+	readID3v1(file, tags)
+	if tags["title"] != "Newer Title" || tags["artist"] != "Artist" || tags["date"] != "1999" || tags["tracknumber"] != "7" {
+		t.Errorf("Unexpected tags: %v", tags)
+	}
+}
+
+func TestProbeFLAC(t *testing.T) {
+	// Ten seconds at 44.1kHz
+	info := make([]byte, 34)
+	info[10], info[11], info[12] = 44100>>12, 44100>>4&0xFF, 44100<<4&0xF0
+	binary.BigEndian.PutUint32(info[14:18], 441000)
+
+	var comments []byte
+	comments = binary.LittleEndian.AppendUint32(comments, 0)
+	comments = binary.LittleEndian.AppendUint32(comments, 1)
+	comments = binary.LittleEndian.AppendUint32(comments, uint32(len("TITLE=Flac Song")))
+	comments = append(comments, "TITLE=Flac Song"...)
+
+	data := []byte("fLaC")
+	data = append(data, flacStreamInfo, 0, 0, byte(len(info)))
+	data = append(data, info...)
+	data = append(data, 0x80|flacVorbisComment, 0, 0, byte(len(comments)))
+	data = append(data, comments...)
+
+	path := filepath.Join(t.TempDir(), "test.flac")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, _ := os.Open(path)
+	defer file.Close()
+
+	result, err := probeFLAC(file)
+	if err != nil {
+		t.Fatalf("probeFLAC() returned error: %v", err)
+	}
+	if result.Duration != 10*time.Second {
+		t.Errorf("Expected duration 10s, got %v", result.Duration)
+	}
+	if result.Tags["title"] != "Flac Song" {
+		t.Errorf("Unexpected tags: %v", result.Tags)
+	}
+}
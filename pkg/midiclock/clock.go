@@ -0,0 +1,208 @@
+// Package midiclock follows the MIDI clock, start/stop and song position
+// messages that a sequencer, drum machine or DJ software sends, so lyrics
+// can be kept in time with a live band
+package midiclock
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	clocksPerBeat      = 24
+	clocksPerSixteenth = 6
+)
+
+// MIDI messages the clock acts on
+const (
+	songPosition = 0xF2
+	sysExStart   = 0xF0
+	sysExEnd     = 0xF7
+	timingClock  = 0xF8
+	start        = 0xFA
+	resume       = 0xFB
+	stop         = 0xFC
+)
+
+// Transport is where the clock is at a moment
+type Transport struct {
+	Running bool
+	Beats   float64 // since the start of the song
+	BPM     float64 // 0 until clocks have arrived
+}
+
+// Clock follows a stream of MIDI messages. Its methods may be called from
+// any goroutine.
+type Clock struct {
+	mutex     sync.Mutex
+	running   bool
+	clocks    int64         // since the start of the song
+	lastClock time.Time     // when the last clock arrived
+	interval  time.Duration // smoothed time between clocks
+	status    byte          // the message whose data bytes are expected
+	data      []byte
+	input     io.Closer
+
+	// OnTransport is called when the clock starts, continues or stops. It
+	// runs on the goroutine reading the input.
+	OnTransport func(running bool)
+}
+
+// New returns a clock that follows what is passed to Feed
+func New() *Clock {
+	return &Clock{}
+}
+
+// Open follows the raw MIDI input at path, such as /dev/snd/midiC1D0
+func Open(path string) (*Clock, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	c := New()
+	c.input = file
+	go c.follow(file)
+	return c, nil
+}
+
+// follow feeds the clock from r until it fails or is closed
+func (c *Clock) follow(r io.Reader) {
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		c.Feed(buf[:n], time.Now())
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Close stops following the input
+func (c *Clock) Close() error {
+	if c.input == nil {
+		return nil
+	}
+	return c.input.Close()
+}
+
+// Feed handles MIDI bytes that arrived at the given time
+func (c *Clock) Feed(data []byte, at time.Time) {
+	var changes []bool
+
+	c.mutex.Lock()
+	for _, b := range data {
+		switch {
+		case b >= timingClock:
+			// Real-time messages may come between the bytes of others
+			if running, changed := c.realTime(b, at); changed {
+				changes = append(changes, running)
+			}
+		case b == sysExEnd:
+			c.status = 0
+		case b >= 0x80:
+			c.status = b
+			c.data = c.data[:0]
+		case c.status != 0:
+			c.dataByte(b)
+		}
+	}
+	c.mutex.Unlock()
+
+	if c.OnTransport != nil {
+		for _, running := range changes {
+			c.OnTransport(running)
+		}
+	}
+}
+
+// realTime handles a real-time message, reporting whether it started or
+// stopped the clock
+func (c *Clock) realTime(b byte, at time.Time) (running, changed bool) {
+	switch b {
+	case timingClock:
+		// Clocks are often sent while stopped too, which keeps the tempo known
+		if !c.lastClock.IsZero() {
+			if gap := at.Sub(c.lastClock); gap > 0 && gap < time.Second {
+				if c.interval == 0 {
+					c.interval = gap
+				} else {
+					c.interval += (gap - c.interval) / 8
+				}
+			}
+		}
+		c.lastClock = at
+		if c.running {
+			c.clocks++
+		}
+	case start:
+		c.clocks = 0
+		c.running = true
+		return true, true
+	case resume:
+		c.running = true
+		return true, true
+	case stop:
+		c.running = false
+		return false, true
+	}
+	return c.running, false
+}
+
+// dataByte adds a data byte to the message being read
+func (c *Clock) dataByte(b byte) {
+	if c.status == sysExStart {
+		return
+	}
+	c.data = append(c.data, b)
+	if len(c.data) < dataLength(c.status) {
+		return
+	}
+
+	if c.status == songPosition {
+		sixteenths := int64(c.data[0]) | int64(c.data[1])<<7
+		c.clocks = sixteenths * clocksPerSixteenth
+	}
+	c.data = c.data[:0]
+	// Channel messages may leave out a repeated status byte; system ones
+	// can't
+	if c.status >= sysExStart {
+		c.status = 0
+	}
+}
+
+// dataLength returns how many data bytes follow status
+func dataLength(status byte) int {
+	switch {
+	case status == songPosition:
+		return 2
+	case status == 0xF1 || status == 0xF3:
+		return 1
+	case status >= sysExStart:
+		return 0
+	case status&0xF0 == 0xC0 || status&0xF0 == 0xD0:
+		return 1
+	}
+	return 2
+}
+
+// Transport returns where the clock is at the given time, moving on
+// smoothly between clocks
+func (c *Clock) Transport(at time.Time) Transport {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	t := Transport{
+		Running: c.running,
+		Beats:   float64(c.clocks) / clocksPerBeat,
+	}
+	if c.interval > 0 {
+		t.BPM = float64(time.Minute) / float64(c.interval*clocksPerBeat)
+		if c.running && !c.lastClock.IsZero() {
+			since := min(max(at.Sub(c.lastClock), 0), c.interval)
+			t.Beats += float64(since) / float64(c.interval) / clocksPerBeat
+		}
+	}
+	return t
+}
@@ -0,0 +1,59 @@
+package midiclock
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestClockFollowsTempoAndPosition(t *testing.T) {
+	c := New()
+	var changes []bool
+	c.OnTransport = func(running bool) {
+		changes = append(changes, running)
+	}
+
+	// Two beats at 120 BPM, with a note played in between
+	at := time.Unix(0, 0)
+	interval := time.Minute / (120 * clocksPerBeat)
+	c.Feed([]byte{start}, at)
+	for i := 0; i < 2*clocksPerBeat; i++ {
+		at = at.Add(interval)
+		c.Feed([]byte{timingClock}, at)
+		if i == 10 {
+			c.Feed([]byte{0x90, 60, timingClock, 100, 61, 0}, at)
+		}
+	}
+
+	transport := c.Transport(at)
+	if !transport.Running {
+		t.Error("expected the clock to be running")
+	}
+	if math.Abs(transport.BPM-120) > 0.5 {
+		t.Errorf("got %.1f BPM, want 120", transport.BPM)
+	}
+	// The clock in the middle of the note message counts too
+	if want := 2 + 1.0/clocksPerBeat; math.Abs(transport.Beats-want) > 0.001 {
+		t.Errorf("got %.3f beats, want %.3f", transport.Beats, want)
+	}
+	if halfway := c.Transport(at.Add(interval / 2)); math.Abs(halfway.Beats-transport.Beats-0.5/clocksPerBeat) > 0.001 {
+		t.Errorf("expected the position to move on between clocks, got %.4f beats", halfway.Beats)
+	}
+
+	// Stop, then jump to bar 3 (sixteenth 32) and continue
+	c.Feed([]byte{stop, songPosition, 32, 0, resume}, at)
+	if transport := c.Transport(at); transport.Beats != 8 {
+		t.Errorf("got %.3f beats after the song position, want 8", transport.Beats)
+	}
+	if len(changes) != 3 || !changes[0] || changes[1] || !changes[2] {
+		t.Errorf("got transport changes %v, want start, stop, continue", changes)
+	}
+}
+
+func TestClockIgnoresSysEx(t *testing.T) {
+	c := New()
+	c.Feed([]byte{sysExStart, 0x7E, songPosition & 0x7F, 0x10, 0x20, sysExEnd, songPosition, 4, 0}, time.Unix(0, 0))
+	if transport := c.Transport(time.Unix(0, 0)); transport.Beats != 1 {
+		t.Errorf("got %.3f beats, want 1", transport.Beats)
+	}
+}
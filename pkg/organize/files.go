@@ -0,0 +1,150 @@
+package organize
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tuneminal/tuneminal/pkg/integrity"
+)
+
+// rename is os.Rename, replaced in tests to move files the way they move
+// between drives
+var rename = os.Rename
+
+// Progress is told how many bytes of a copy are done so far, out of total
+type Progress func(done, total int64)
+
+// CopyFile copies src to dst, creating dst's directory
+func CopyFile(src, dst string) error {
+	return CopyFileProgress(context.Background(), src, dst, nil)
+}
+
+// CopyFileProgress is CopyFile reporting progress as it goes. Cancelling ctx
+// stops the copy and removes what was copied so far. The copy is read back
+// and checked against the original before it counts as done.
+func CopyFileProgress(ctx context.Context, src, dst string, progress Progress) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	reader := &progressReader{ctx: ctx, reader: io.TeeReader(in, hash), total: info.Size(), progress: progress}
+	if _, err := io.Copy(out, reader); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	// Flushed to the drive first, so the check below reads back what was
+	// written there rather than what is still cached in memory
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+
+	// Catch copies that went wrong on the way, such as on a flaky share
+	copied, err := integrity.HashFile(dst)
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	if copied != hex.EncodeToString(hash.Sum(nil)) {
+		os.Remove(dst)
+		return fmt.Errorf("copy of %s doesn't match the original", filepath.Base(src))
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+// progressReader reports each read to progress, and fails once ctx is done
+type progressReader struct {
+	ctx      context.Context
+	reader   io.Reader
+	done     int64
+	total    int64
+	progress Progress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.reader.Read(p)
+	r.done += int64(n)
+	if r.progress != nil {
+		r.progress(r.done, r.total)
+	}
+	return n, err
+}
+
+// MoveFile renames src to dst, falling back to copy and delete when they are
+// on different filesystems
+func MoveFile(src, dst string) error {
+	return MoveFileProgress(context.Background(), src, dst, nil)
+}
+
+// MoveFileProgress is MoveFile reporting the progress of the copy when one is
+// needed. Cancelling ctx stops the copy and leaves src where it was, and src
+// is only deleted once the copy has been verified.
+func MoveFileProgress(ctx context.Context, src, dst string, progress Progress) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("destination already exists: %s", dst)
+	}
+
+	if err := rename(src, dst); err == nil {
+		return nil
+	}
+
+	// Across drives the file has to be copied
+	if err := CopyFileProgress(ctx, src, dst, progress); err != nil {
+		return err
+	}
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("copied to %s but couldn't remove the original: %w", dst, err)
+	}
+	return nil
+}
+
+// UniquePath returns path, or path with a " (n)" suffix if it is taken
+func UniquePath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
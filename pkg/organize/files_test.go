@@ -0,0 +1,90 @@
+package organize
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestMoveFileProgress(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "song.mp3")
+	os.WriteFile(src, make([]byte, 100000), 0644)
+
+	// A cancelled copy leaves the song where it was and nothing behind
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	partial := filepath.Join(dir, "cancelled", "song.mp3")
+	if err := CopyFileProgress(ctx, src, partial, nil); err == nil {
+		t.Error("Expected a cancelled copy to fail")
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Error("Expected the partial copy to be removed")
+	}
+
+	var done, total int64
+	copied := filepath.Join(dir, "copy", "song.mp3")
+	err := CopyFileProgress(context.Background(), src, copied, func(d, t int64) {
+		done, total = d, t
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done != 100000 || total != 100000 {
+		t.Errorf("Expected progress to end at 100000 of 100000 bytes, got %d of %d", done, total)
+	}
+
+	dst := filepath.Join(dir, "moved", "song.mp3")
+	if err := MoveFileProgress(context.Background(), src, dst, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("Expected the source to be gone after the move")
+	}
+}
+
+func TestMoveFileAcrossDrives(t *testing.T) {
+	defer func(original func(string, string) error) { rename = original }(rename)
+	rename = func(src, dst string) error {
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: syscall.EXDEV}
+	}
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "song.mp3")
+	data := []byte("audio that has to be copied")
+	os.WriteFile(src, data, 0600)
+	modified := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	os.Chtimes(src, modified, modified)
+
+	// An existing file is never copied over
+	taken := filepath.Join(dir, "taken.mp3")
+	os.WriteFile(taken, []byte("keep"), 0644)
+	if err := CopyFile(src, taken); err == nil {
+		t.Error("Expected copying onto an existing file to fail")
+	}
+	if got, _ := os.ReadFile(taken); string(got) != "keep" {
+		t.Errorf("Expected the existing file untouched, got %q", got)
+	}
+
+	dst := filepath.Join(dir, "other drive", "song.mp3")
+	if err := MoveFile(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(dst); !bytes.Equal(got, data) {
+		t.Errorf("Expected the copy to match, got %q", got)
+	}
+	if info.Mode().Perm() != 0600 || !info.ModTime().Equal(modified) {
+		t.Errorf("Expected the mode and time kept, got %v, %v", info.Mode().Perm(), info.ModTime())
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("Expected the original removed once the copy was checked")
+	}
+}
@@ -0,0 +1,36 @@
+package organize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+)
+
+// ImportFile copies (or moves) an audio file into root following the naming
+// template, bringing an .lrc sidecar along. It returns the new audio path.
+func ImportFile(src, root, template string, move bool) (string, error) {
+	meta, err := metadata.GetRealMetadata(src)
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s: %w", filepath.Base(src), err)
+	}
+
+	dst := UniquePath(filepath.Join(root, RenderTemplate(template, meta)))
+	transfer := CopyFile
+	if move {
+		transfer = MoveFile
+	}
+	if err := transfer(src, dst); err != nil {
+		return "", fmt.Errorf("cannot import %s: %w", filepath.Base(src), err)
+	}
+
+	// Lyrics travel with the song when they sit next to it
+	lrc := strings.TrimSuffix(src, filepath.Ext(src)) + ".lrc"
+	if _, err := os.Stat(lrc); err == nil {
+		transfer(lrc, strings.TrimSuffix(dst, filepath.Ext(dst))+".lrc")
+	}
+
+	return dst, nil
+}
@@ -0,0 +1,121 @@
+package organize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+)
+
+// sidecarExts are files that travel with a song when it is moved
+var sidecarExts = []string{".lrc", ".txt"}
+
+// Move is one planned file move
+type Move struct {
+	From string
+	To   string
+}
+
+// Plan is a dry run of a library re-layout
+type Plan struct {
+	Root      string
+	Moves     []Move   // Audio files that change location
+	Unchanged int      // Files already in the right place
+	Skipped   []string // Files that couldn't be read, with the reason
+}
+
+// PlanLayout works out where every file should go under root according to
+// template, without touching the disk
+func PlanLayout(files []string, root, template string) *Plan {
+	plan := &Plan{Root: root}
+	taken := make(map[string]bool)
+
+	for _, file := range files {
+		meta, err := metadata.GetRealMetadata(file)
+		if err != nil {
+			plan.Skipped = append(plan.Skipped, fmt.Sprintf("%s: %v", file, err))
+			continue
+		}
+
+		target := filepath.Join(root, RenderTemplate(template, meta))
+		if samePath(file, target) {
+			plan.Unchanged++
+			taken[target] = true
+			continue
+		}
+
+		// Avoid clashing with existing files and with other planned moves
+		ext := filepath.Ext(target)
+		base := strings.TrimSuffix(target, ext)
+		for i := 2; taken[target] || exists(target); i++ {
+			target = fmt.Sprintf("%s (%d)%s", base, i, ext)
+		}
+		taken[target] = true
+
+		plan.Moves = append(plan.Moves, Move{From: file, To: target})
+	}
+
+	return plan
+}
+
+// Apply performs the planned moves, bringing lyric sidecars along. It returns
+// the moves that succeeded, including sidecars, and the first error if any.
+func (p *Plan) Apply() ([]Move, error) {
+	var done []Move
+	var firstErr error
+
+	for _, move := range p.Moves {
+		if err := MoveFile(move.From, move.To); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to move %s: %w", move.From, err)
+			}
+			continue
+		}
+		done = append(done, move)
+
+		for _, ext := range sidecarExts {
+			from := strings.TrimSuffix(move.From, filepath.Ext(move.From)) + ext
+			if !exists(from) {
+				continue
+			}
+			to := strings.TrimSuffix(move.To, filepath.Ext(move.To)) + ext
+			if err := MoveFile(from, to); err == nil {
+				done = append(done, Move{From: from, To: to})
+			}
+		}
+
+		removeEmptyDirs(filepath.Dir(move.From), p.Root)
+	}
+
+	return done, firstErr
+}
+
+// removeEmptyDirs deletes dir and its parents while they are empty, stopping at root
+func removeEmptyDirs(dir, root string) {
+	// Either may be relative, so compare absolute paths
+	root, errRoot := filepath.Abs(root)
+	dir, errDir := filepath.Abs(dir)
+	if errRoot != nil || errDir != nil {
+		return
+	}
+	for ; dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
+
+// samePath reports whether two paths refer to the same location
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// exists reports whether a file exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,111 @@
+package organize
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeWAV writes a short silent WAV file, creating its folder
+func writeWAV(t *testing.T, path string) {
+	t.Helper()
+	const frames = 100
+	header := make([]byte, 44)
+	copy(header, "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], 36+frames*4)
+	copy(header[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)
+	binary.LittleEndian.PutUint16(header[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(header[22:], 2)
+	binary.LittleEndian.PutUint32(header[24:], 44100)
+	binary.LittleEndian.PutUint32(header[28:], 44100*4)
+	binary.LittleEndian.PutUint16(header[32:], 4)
+	binary.LittleEndian.PutUint16(header[34:], 16)
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], frames*4)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, append(header, make([]byte, frames*4)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlanLayoutCollisions(t *testing.T) {
+	root := t.TempDir()
+	first := filepath.Join(root, "ABBA - Waterloo.wav")
+	second := filepath.Join(root, "old", "ABBA - Waterloo.wav")
+	clash := filepath.Join(root, "Queen - Innuendo.wav")
+	broken := filepath.Join(root, "broken.wav")
+	writeWAV(t, first)
+	writeWAV(t, second)
+	writeWAV(t, clash)
+	writeWAV(t, filepath.Join(root, "Queen", "Innuendo.wav")) // not part of the plan
+	os.WriteFile(broken, []byte("not audio"), 0644)
+	os.WriteFile(filepath.Join(root, "old", "ABBA - Waterloo.lrc"), []byte("[00:01.00]Waterloo"), 0644)
+
+	plan := PlanLayout([]string{first, second, clash, broken}, root, "{artist}/{title}{ext}")
+	if len(plan.Skipped) != 1 {
+		t.Errorf("Expected the broken file skipped, got %v", plan.Skipped)
+	}
+	want := []Move{
+		{From: first, To: filepath.Join(root, "ABBA", "Waterloo.wav")},
+		{From: second, To: filepath.Join(root, "ABBA", "Waterloo (2).wav")},
+		{From: clash, To: filepath.Join(root, "Queen", "Innuendo (2).wav")},
+	}
+	if len(plan.Moves) != len(want) {
+		t.Fatalf("Expected %d moves, got %+v", len(want), plan.Moves)
+	}
+	for i := range want {
+		if plan.Moves[i] != want[i] {
+			t.Errorf("Move %d: got %+v, want %+v", i, plan.Moves[i], want[i])
+		}
+	}
+
+	done, err := plan.Apply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 4 {
+		t.Errorf("Expected 3 songs and a lyric file moved, got %+v", done)
+	}
+	if _, err := os.Stat(filepath.Join(root, "ABBA", "Waterloo (2).lrc")); err != nil {
+		t.Errorf("Expected the lyrics to follow their song: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "old")); !os.IsNotExist(err) {
+		t.Error("Expected the emptied folder to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(root, "Queen", "Innuendo.wav")); err != nil {
+		t.Errorf("Expected the file already there kept: %v", err)
+	}
+}
+
+func TestPlanLayoutRelativeRoot(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeWAV(t, filepath.Join("library", "in", "ABBA - Waterloo.wav"))
+	writeWAV(t, filepath.Join("library", "ABBA", "SOS.wav"))
+
+	// The library root is relative while files may be listed either way
+	moving := filepath.Join("library", "in", "ABBA - Waterloo.wav")
+	placed := filepath.Join(dir, "library", "ABBA", "SOS.wav")
+	plan := PlanLayout([]string{moving, placed}, "library", "ABBA/{filename}{ext}")
+	if plan.Unchanged != 1 {
+		t.Errorf("Expected the absolute path already in place under the relative root, got %+v", plan)
+	}
+	if len(plan.Moves) != 1 || plan.Moves[0].To != filepath.Join("library", "ABBA", "ABBA - Waterloo.wav") {
+		t.Fatalf("Expected the relative path moved under the root, got %+v", plan.Moves)
+	}
+
+	if _, err := plan.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "library", "ABBA", "ABBA - Waterloo.wav")); err != nil {
+		t.Errorf("Expected the file moved: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "library", "in")); !os.IsNotExist(err) {
+		t.Error("Expected the emptied folder under the relative root to be removed")
+	}
+}
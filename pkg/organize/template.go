@@ -0,0 +1,68 @@
+package organize
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+)
+
+// DefaultTemplate lays files out as Artist/Album/Title.ext
+const DefaultTemplate = "{artist}/{album}/{title}{ext}"
+
+// unsafeChars are replaced in path components so names work on every filesystem
+var unsafeChars = strings.NewReplacer(
+	"/", "-", "\\", "-", ":", "-", "*", "", "?", "", "\"", "'", "<", "", ">", "", "|", "-",
+)
+
+// SanitizeComponent makes a string safe to use as a single file or directory name
+func SanitizeComponent(name string) string {
+	name = strings.TrimSpace(unsafeChars.Replace(name))
+	name = strings.Trim(name, ". ")
+	if name == "" {
+		return "Unknown"
+	}
+	return name
+}
+
+// RenderTemplate builds a relative path for a song from a template such as
+// "{artist}/{album}/{title}{ext}". Supported fields are {artist}, {album},
+// {title}, {ext} and {filename} (the original name without extension).
+func RenderTemplate(template string, meta *metadata.SongMetadata) string {
+	if template == "" {
+		template = DefaultTemplate
+	}
+
+	ext := strings.ToLower(filepath.Ext(meta.Path))
+	fields := map[string]string{
+		"{artist}":   valueOr(meta.Artist, "Unknown Artist"),
+		"{album}":    valueOr(meta.Album, "Unknown Album"),
+		"{title}":    valueOr(meta.Title, "Unknown Title"),
+		"{filename}": strings.TrimSuffix(filepath.Base(meta.Path), filepath.Ext(meta.Path)),
+	}
+
+	// Render each path component separately so field values can't add directories
+	parts := strings.Split(filepath.ToSlash(template), "/")
+	for i, part := range parts {
+		hasExt := strings.HasSuffix(part, "{ext}")
+		part = strings.TrimSuffix(part, "{ext}")
+		for field, value := range fields {
+			part = strings.ReplaceAll(part, field, value)
+		}
+		part = SanitizeComponent(part)
+		if hasExt {
+			part += ext
+		}
+		parts[i] = part
+	}
+
+	return filepath.Join(parts...)
+}
+
+// valueOr returns value, or fallback when value is empty or "Unknown"
+func valueOr(value, fallback string) string {
+	if value == "" || value == "Unknown" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,27 @@
+package organize
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	meta := &metadata.SongMetadata{
+		Title:  "What? Now",
+		Artist: "AC/DC",
+		Path:   "/downloads/track01.MP3",
+	}
+
+	got := RenderTemplate(DefaultTemplate, meta)
+	want := filepath.Join("AC-DC", "Unknown Album", "What Now.mp3")
+	if got != want {
+		t.Errorf("RenderTemplate() = %q, want %q", got, want)
+	}
+
+	got = RenderTemplate("{artist} - {filename}{ext}", meta)
+	if got != "AC-DC - track01.mp3" {
+		t.Errorf("RenderTemplate() with filename = %q", got)
+	}
+}
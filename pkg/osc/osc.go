@@ -0,0 +1,260 @@
+// Package osc receives Open Sound Control messages over UDP, as control
+// surfaces such as TouchOSC send them
+package osc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+)
+
+// Message is an OSC message. Args hold int32, int64, float32, float64,
+// string, []byte, bool or nil values.
+type Message struct {
+	Address string
+	Args    []any
+}
+
+// Float returns argument i as a number. Integers and booleans count, since
+// buttons send either depending on the surface.
+func (m Message) Float(i int) (float64, bool) {
+	if i >= len(m.Args) {
+		return 0, false
+	}
+	switch value := m.Args[i].(type) {
+	case float32:
+		return float64(value), true
+	case float64:
+		return value, true
+	case int32:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case bool:
+		if value {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// String returns argument i as a string
+func (m Message) String(i int) (string, bool) {
+	if i >= len(m.Args) {
+		return "", false
+	}
+	value, ok := m.Args[i].(string)
+	return value, ok
+}
+
+// ErrMalformed is returned for packets that aren't valid OSC
+var ErrMalformed = errors.New("malformed OSC packet")
+
+// bundleTag starts a packet holding several messages
+const bundleTag = "#bundle"
+
+// Parse reads the messages in an OSC packet, which is one message or a
+// bundle of them. Bundles are delivered straight away whatever their time
+// tag.
+func Parse(packet []byte) ([]Message, error) {
+	if len(packet) == 0 || len(packet)%4 != 0 {
+		return nil, ErrMalformed
+	}
+	if packet[0] != '#' {
+		message, err := parseMessage(packet)
+		if err != nil {
+			return nil, err
+		}
+		return []Message{message}, nil
+	}
+
+	r := &reader{data: packet}
+	if tag, err := r.string(); err != nil || tag != bundleTag {
+		return nil, ErrMalformed
+	}
+	if _, err := r.bytes(8); err != nil { // time tag
+		return nil, err
+	}
+	var messages []Message
+	for !r.done() {
+		size, err := r.int32()
+		if err != nil || size < 0 {
+			return nil, ErrMalformed
+		}
+		element, err := r.bytes(int(size))
+		if err != nil {
+			return nil, err
+		}
+		inner, err := Parse(element)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, inner...)
+	}
+	return messages, nil
+}
+
+// parseMessage reads a single OSC message
+func parseMessage(packet []byte) (Message, error) {
+	r := &reader{data: packet}
+	address, err := r.string()
+	if err != nil || !strings.HasPrefix(address, "/") {
+		return Message{}, ErrMalformed
+	}
+	message := Message{Address: address}
+	if r.done() {
+		// Very old senders leave the type tags out
+		return message, nil
+	}
+
+	tags, err := r.string()
+	if err != nil || !strings.HasPrefix(tags, ",") {
+		return Message{}, ErrMalformed
+	}
+	for _, tag := range tags[1:] {
+		var value any
+		switch tag {
+		case 'i':
+			value, err = r.int32()
+		case 'f':
+			var bits int32
+			bits, err = r.int32()
+			value = math.Float32frombits(uint32(bits))
+		case 'h':
+			value, err = r.int64()
+		case 'd':
+			var bits int64
+			bits, err = r.int64()
+			value = math.Float64frombits(uint64(bits))
+		case 's', 'S':
+			value, err = r.string()
+		case 'b':
+			value, err = r.blob()
+		case 'T':
+			value = true
+		case 'F':
+			value = false
+		case 'N', 'I':
+			value = nil
+		default:
+			return Message{}, fmt.Errorf("%w: unknown type tag %q", ErrMalformed, tag)
+		}
+		if err != nil {
+			return Message{}, err
+		}
+		message.Args = append(message.Args, value)
+	}
+	return message, nil
+}
+
+// reader walks the 4-byte aligned fields of a packet
+type reader struct {
+	data []byte
+	pos  int
+}
+
+func (r *reader) done() bool {
+	return r.pos >= len(r.data)
+}
+
+func (r *reader) bytes(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, ErrMalformed
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *reader) int32() (int32, error) {
+	b, err := r.bytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+func (r *reader) int64() (int64, error) {
+	b, err := r.bytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+// string reads a null-terminated string padded to 4 bytes
+func (r *reader) string() (string, error) {
+	end := bytes.IndexByte(r.data[r.pos:], 0)
+	if end < 0 {
+		return "", ErrMalformed
+	}
+	s := string(r.data[r.pos : r.pos+end])
+	r.pos += (end + 4) &^ 3
+	return s, nil
+}
+
+// blob reads a size-prefixed blob padded to 4 bytes
+func (r *reader) blob() ([]byte, error) {
+	size, err := r.int32()
+	if err != nil {
+		return nil, err
+	}
+	b, err := r.bytes(int(size))
+	if err != nil {
+		return nil, err
+	}
+	r.pos += (4 - int(size)%4) % 4
+	return append([]byte(nil), b...), nil
+}
+
+// Server receives OSC packets on a UDP address
+type Server struct {
+	conn net.PacketConn
+}
+
+// Listen receives OSC on address, such as ":9000", and calls handle for each
+// message from a goroutine of its own. Malformed packets are dropped.
+func Listen(address string, handle func(Message)) (*Server, error) {
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	s := &Server{conn: conn}
+	go s.serve(handle)
+	return s, nil
+}
+
+func (s *Server) serve(handle func(Message)) {
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		messages, err := Parse(buf[:n])
+		if err != nil {
+			continue
+		}
+		for _, message := range messages {
+			handle(message)
+		}
+	}
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// Close stops listening
+func (s *Server) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,118 @@
+package osc
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"testing"
+	"time"
+)
+
+// pad adds a null terminator and pads s to 4 bytes
+func pad(s string) []byte {
+	b := append([]byte(s), 0)
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func message(address, tags string, args ...[]byte) []byte {
+	packet := append(pad(address), pad(tags)...)
+	for _, arg := range args {
+		packet = append(packet, arg...)
+	}
+	return packet
+}
+
+func float32Arg(f float32) []byte {
+	return binary.BigEndian.AppendUint32(nil, math.Float32bits(f))
+}
+
+func TestParseMessage(t *testing.T) {
+	packet := message("/tuneminal/queue", ",sfiT", pad("Hello"), float32Arg(0.5), binary.BigEndian.AppendUint32(nil, 7))
+
+	messages, err := Parse(packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].Address != "/tuneminal/queue" {
+		t.Fatalf("got %+v, want one /tuneminal/queue message", messages)
+	}
+	m := messages[0]
+	if s, ok := m.String(0); !ok || s != "Hello" {
+		t.Errorf("got %q, want Hello", s)
+	}
+	if f, ok := m.Float(1); !ok || f != 0.5 {
+		t.Errorf("got %v, want 0.5", f)
+	}
+	if f, ok := m.Float(2); !ok || f != 7 {
+		t.Errorf("got %v, want 7", f)
+	}
+	if f, ok := m.Float(3); !ok || f != 1 {
+		t.Errorf("got %v, want 1 for true", f)
+	}
+	if _, ok := m.Float(4); ok {
+		t.Error("expected no fifth argument")
+	}
+}
+
+func TestParseBundle(t *testing.T) {
+	first := message("/tuneminal/play", ",")
+	second := message("/tuneminal/volume", ",f", float32Arg(0.25))
+
+	packet := append(pad("#bundle"), make([]byte, 8)...)
+	for _, element := range [][]byte{first, second} {
+		packet = binary.BigEndian.AppendUint32(packet, uint32(len(element)))
+		packet = append(packet, element...)
+	}
+
+	messages, err := Parse(packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 || messages[0].Address != "/tuneminal/play" || messages[1].Address != "/tuneminal/volume" {
+		t.Errorf("got %+v, want play then volume", messages)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	for _, packet := range [][]byte{
+		nil,
+		[]byte("abc"),
+		pad("no-slash"),
+		message("/x", ",f"),
+		message("/x", ",q", []byte{0, 0, 0, 0}),
+	} {
+		if _, err := Parse(packet); err == nil {
+			t.Errorf("expected an error for %q", packet)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	received := make(chan Message, 1)
+	server, err := Listen("127.0.0.1:0", func(m Message) {
+		received <- m
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	conn, err := net.Dial("udp", server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write(message("/tuneminal/next", ",f", float32Arg(1)))
+
+	select {
+	case m := <-received:
+		if m.Address != "/tuneminal/next" {
+			t.Errorf("got %s, want /tuneminal/next", m.Address)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+	}
+}
@@ -0,0 +1,166 @@
+package overrides
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/paths"
+)
+
+// Override holds per-song settings that take precedence over what is read
+// from the file itself
+type Override struct {
+	Hidden bool `json:"hidden,omitempty"` // Excluded from the library and shuffles
+
+	// Tempo for the metronome, detected from the audio or tapped by the user
+	BPM         float64       `json:"bpm,omitempty"`
+	BeatOffset  time.Duration `json:"beat_offset,omitempty"`
+	TempoTapped bool          `json:"tempo_tapped,omitempty"`
+
+	// Pitch shift in semitones chosen by the user, overriding the lyrics' [transpose:] tag
+	Transpose *int `json:"transpose,omitempty"`
+
+	// How much later the lyrics show than their timestamps say, on top of the
+	// lyrics' [offset:] tag, for files that are off by a constant amount
+	LyricShift time.Duration `json:"lyric_shift,omitempty"`
+
+	// Curation carried over from another player such as iTunes
+	Rating        int `json:"rating,omitempty"`         // Stars, 1 to 5
+	ImportedPlays int `json:"imported_plays,omitempty"` // Plays counted before Tuneminal
+
+	// Practice notes, such as key changes, breath marks or "skip the bridge"
+	Notes string `json:"notes,omitempty"`
+}
+
+// isZero reports whether the override carries no settings
+func (o Override) isZero() bool {
+	return o == Override{}
+}
+
+// Store is the overrides database, keyed by song path
+type Store struct {
+	path     string
+	mutex    sync.Mutex
+	entries  map[string]Override
+	inMemory bool // changes are kept for this run only
+}
+
+// NewStore creates an overrides store backed by overrides.json in the data directory
+func NewStore() *Store {
+	store := &Store{
+		path:    paths.Data("overrides.json"),
+		entries: make(map[string]Override),
+	}
+	store.load()
+	return store
+}
+
+// load reads the database, starting empty if the file is missing or invalid
+func (s *Store) load() {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return
+	}
+	json.Unmarshal(data, &s.entries)
+}
+
+// KeepInMemory stops changes from being written to disk: they last until
+// the program exits, for read-only mode
+func (s *Store) KeepInMemory() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.inMemory = true
+}
+
+// save writes the database to disk
+func (s *Store) save() error {
+	if s.inMemory {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// key normalises a song path so relative and absolute paths match
+func key(songPath string) string {
+	if abs, err := filepath.Abs(songPath); err == nil {
+		return abs
+	}
+	return songPath
+}
+
+// Get returns the overrides for a song
+func (s *Store) Get(songPath string) Override {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.entries[key(songPath)]
+}
+
+// Update changes the overrides for a song and saves the database
+func (s *Store) Update(songPath string, change func(*Override)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	k := key(songPath)
+	override := s.entries[k]
+	change(&override)
+	if override.isZero() {
+		delete(s.entries, k)
+	} else {
+		s.entries[k] = override
+	}
+	return s.save()
+}
+
+// IsHidden reports whether a song is hidden from the library
+func (s *Store) IsHidden(songPath string) bool {
+	return s.Get(songPath).Hidden
+}
+
+// SetHidden hides or unhides a song
+func (s *Store) SetHidden(songPath string, hidden bool) error {
+	return s.Update(songPath, func(o *Override) {
+		o.Hidden = hidden
+	})
+}
+
+// Hidden returns the paths of all hidden songs, sorted
+func (s *Store) Hidden() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var paths []string
+	for path, override := range s.entries {
+		if override.Hidden {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Rename moves a song's overrides to its new path
+func (s *Store) Rename(oldPath, newPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	override, ok := s.entries[key(oldPath)]
+	if !ok {
+		return nil
+	}
+	delete(s.entries, key(oldPath))
+	s.entries[key(newPath)] = override
+	return s.save()
+}
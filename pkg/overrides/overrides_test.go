@@ -0,0 +1,77 @@
+package overrides
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestStore opens a store kept in path, the way NewStore does
+func newTestStore(path string) *Store {
+	store := &Store{path: path, entries: make(map[string]Override)}
+	store.load()
+	return store
+}
+
+func TestHideAndUnhide(t *testing.T) {
+	store := newTestStore(filepath.Join(t.TempDir(), "overrides.json"))
+
+	store.SetHidden("/music/b.mp3", true)
+	store.SetHidden("/music/a.mp3", true)
+	if !store.IsHidden("/music/a.mp3") || store.IsHidden("/music/c.mp3") {
+		t.Error("Expected only the songs hidden to be hidden")
+	}
+	if hidden := store.Hidden(); len(hidden) != 2 || hidden[0] != "/music/a.mp3" {
+		t.Errorf("Expected both hidden songs, sorted, got %v", hidden)
+	}
+
+	// Clearing the last setting drops the song's entry
+	store.SetHidden("/music/a.mp3", false)
+	if _, ok := store.entries["/music/a.mp3"]; ok {
+		t.Error("Expected an override with nothing set to be removed")
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	path := filepath.Join(dir, "overrides.json")
+	store := newTestStore(path)
+
+	semitones := -2
+	store.Update("song.mp3", func(o *Override) {
+		o.BPM = 120
+		o.BeatOffset = 250 * time.Millisecond
+		o.Transpose = &semitones
+	})
+	store.Update(filepath.Join(dir, "other.mp3"), func(o *Override) { o.Notes = "skip the bridge" })
+	store.Rename("other.mp3", "moved.mp3")
+
+	// Relative and absolute paths are the same song, after a restart too
+	again := newTestStore(path)
+	got := again.Get(filepath.Join(dir, "song.mp3"))
+	if got.BPM != 120 || got.BeatOffset != 250*time.Millisecond || got.Transpose == nil || *got.Transpose != -2 {
+		t.Errorf("Expected the tempo and transpose saved, got %+v", got)
+	}
+	if again.Get("moved.mp3").Notes != "skip the bridge" || again.Get("other.mp3").Notes != "" {
+		t.Errorf("Expected the notes moved with the song, got %+v", again.entries)
+	}
+}
+
+func TestKeepInMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overrides.json")
+	newTestStore(path).SetHidden("/music/a.mp3", true)
+
+	store := newTestStore(path)
+	store.KeepInMemory()
+	store.SetHidden("/music/a.mp3", false)
+	store.Update("/music/b.mp3", func(o *Override) { o.LyricShift = time.Second })
+	if store.IsHidden("/music/a.mp3") || store.Get("/music/b.mp3").LyricShift != time.Second {
+		t.Error("Expected changes to apply for this run")
+	}
+
+	again := newTestStore(path)
+	if !again.IsHidden("/music/a.mp3") || again.Get("/music/b.mp3").LyricShift != 0 {
+		t.Errorf("Expected the file untouched, got %+v", again.entries)
+	}
+}
@@ -0,0 +1,226 @@
+// Package party keeps score for several singers taking turns, with
+// per-singer handicaps and optional teams
+package party
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Handicap limits, as score multipliers
+const (
+	MinHandicap = 0.5
+	MaxHandicap = 3.0
+)
+
+// Singer is one person in the rotation
+type Singer struct {
+	Name     string
+	Team     string  // Empty when not on a team
+	Handicap float64 // Multiplier applied to the singer's scores
+}
+
+// Run is one song sung by a singer
+type Run struct {
+	Singer   string
+	Song     string
+	Score    int // As scored by the karaoke engine
+	Adjusted int // After the singer's handicap
+}
+
+// Standing is a singer's or team's place on the leaderboard
+type Standing struct {
+	Name    string
+	Members []string // Team members, for team standings
+	Runs    int
+	Total   int // Sum of adjusted scores
+	Best    int // Best adjusted score
+}
+
+// Session is a party's singers, whose turn it is and the runs sung so far
+type Session struct {
+	mutex    sync.Mutex
+	singers  []Singer
+	current  int
+	runs     []Run
+	teamMode bool
+}
+
+// NewSession creates a session with no singers
+func NewSession() *Session {
+	return &Session{}
+}
+
+// AddSinger adds a singer to the end of the rotation
+func (s *Session) AddSinger(singer Singer) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	singer.Name = strings.TrimSpace(singer.Name)
+	singer.Team = strings.TrimSpace(singer.Team)
+	if singer.Name == "" {
+		return fmt.Errorf("singer name is required")
+	}
+	if singer.Handicap < MinHandicap || singer.Handicap > MaxHandicap {
+		return fmt.Errorf("handicap must be between %.1f and %.1f", MinHandicap, MaxHandicap)
+	}
+	for _, existing := range s.singers {
+		if strings.EqualFold(existing.Name, singer.Name) {
+			return fmt.Errorf("%s is already singing", existing.Name)
+		}
+	}
+
+	s.singers = append(s.singers, singer)
+	return nil
+}
+
+// RemoveSinger takes a singer out of the rotation; their runs still count
+func (s *Session) RemoveSinger(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, singer := range s.singers {
+		if singer.Name != name {
+			continue
+		}
+		s.singers = append(s.singers[:i], s.singers[i+1:]...)
+		if i < s.current {
+			s.current--
+		}
+		if s.current >= len(s.singers) {
+			s.current = 0
+		}
+		return
+	}
+}
+
+// Singers returns the rotation in order
+func (s *Session) Singers() []Singer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Singer{}, s.singers...)
+}
+
+// Current returns whose turn it is, if there are any singers
+func (s *Session) Current() (Singer, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.singers) == 0 {
+		return Singer{}, false
+	}
+	return s.singers[s.current], true
+}
+
+// SetCurrent makes it a singer's turn
+func (s *Session) SetCurrent(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, singer := range s.singers {
+		if singer.Name == name {
+			s.current = i
+			return
+		}
+	}
+}
+
+// Record credits a score to the current singer, with their handicap
+// applied, and passes the turn on to the next singer
+func (s *Session) Record(song string, score int) (Run, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.singers) == 0 {
+		return Run{}, false
+	}
+
+	singer := s.singers[s.current]
+	run := Run{
+		Singer:   singer.Name,
+		Song:     song,
+		Score:    score,
+		Adjusted: int(math.Round(float64(score) * singer.Handicap)),
+	}
+	s.runs = append(s.runs, run)
+	s.current = (s.current + 1) % len(s.singers)
+	return run, true
+}
+
+// SetTeamMode switches the leaderboard between singers and teams
+func (s *Session) SetTeamMode(on bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.teamMode = on
+}
+
+// TeamMode reports whether the leaderboard ranks teams
+func (s *Session) TeamMode() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.teamMode
+}
+
+// Reset forgets all runs, keeping the singers
+func (s *Session) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.runs = nil
+	s.current = 0
+}
+
+// Leaderboard ranks the singers by total score, or in team mode the teams
+// by the sum of their members' scores. Singers without a team stand on
+// their own.
+func (s *Session) Leaderboard() []Standing {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	teamOf := make(map[string]string)
+	for _, singer := range s.singers {
+		teamOf[singer.Name] = singer.Team
+	}
+
+	standings := make(map[string]*Standing)
+	var order []string
+	standing := func(name string) *Standing {
+		if existing, ok := standings[name]; ok {
+			return existing
+		}
+		standings[name] = &Standing{Name: name}
+		order = append(order, name)
+		return standings[name]
+	}
+	key := func(singer string) string {
+		if s.teamMode && teamOf[singer] != "" {
+			return teamOf[singer]
+		}
+		return singer
+	}
+
+	// Everyone in the rotation is listed, even before their first run
+	for _, singer := range s.singers {
+		entry := standing(key(singer.Name))
+		if entry.Name != singer.Name {
+			entry.Members = append(entry.Members, singer.Name)
+		}
+	}
+	for _, run := range s.runs {
+		entry := standing(key(run.Singer))
+		entry.Runs++
+		entry.Total += run.Adjusted
+		if run.Adjusted > entry.Best {
+			entry.Best = run.Adjusted
+		}
+	}
+
+	result := make([]Standing, 0, len(order))
+	for _, name := range order {
+		result = append(result, *standings[name])
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Total > result[j].Total })
+	return result
+}
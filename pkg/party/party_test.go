@@ -0,0 +1,54 @@
+package party
+
+import "testing"
+
+func TestRecordAppliesHandicapAndRotates(t *testing.T) {
+	session := NewSession()
+	session.AddSinger(Singer{Name: "Ana", Handicap: 1})
+	session.AddSinger(Singer{Name: "Ben", Handicap: 1.5})
+	if err := session.AddSinger(Singer{Name: "ana", Handicap: 1}); err == nil {
+		t.Error("Expected an error for a duplicate singer")
+	}
+	if err := session.AddSinger(Singer{Name: "Cy", Handicap: 10}); err == nil {
+		t.Error("Expected an error for an out-of-range handicap")
+	}
+
+	run, _ := session.Record("Song A", 1000)
+	if run.Singer != "Ana" || run.Adjusted != 1000 {
+		t.Errorf("Unexpected first run %+v", run)
+	}
+	run, _ = session.Record("Song B", 1000)
+	if run.Singer != "Ben" || run.Adjusted != 1500 {
+		t.Errorf("Expected Ben's handicap applied, got %+v", run)
+	}
+	if current, _ := session.Current(); current.Name != "Ana" {
+		t.Errorf("Expected the turn back with Ana, got %s", current.Name)
+	}
+}
+
+func TestTeamLeaderboard(t *testing.T) {
+	session := NewSession()
+	session.AddSinger(Singer{Name: "Ana", Team: "Red", Handicap: 1})
+	session.AddSinger(Singer{Name: "Ben", Team: "Blue", Handicap: 1})
+	session.AddSinger(Singer{Name: "Cy", Team: "Red", Handicap: 2})
+	session.AddSinger(Singer{Name: "Di", Handicap: 1})
+
+	session.Record("A", 3000) // Ana
+	session.Record("B", 4000) // Ben
+	session.Record("C", 1000) // Cy, doubled
+	session.Record("D", 500)  // Di
+
+	singers := session.Leaderboard()
+	if len(singers) != 4 || singers[0].Name != "Ben" || singers[0].Total != 4000 {
+		t.Errorf("Unexpected singer leaderboard %+v", singers)
+	}
+
+	session.SetTeamMode(true)
+	teams := session.Leaderboard()
+	if len(teams) != 3 || teams[0].Name != "Red" || teams[0].Total != 5000 || len(teams[0].Members) != 2 {
+		t.Errorf("Expected Red on top with both members, got %+v", teams)
+	}
+	if teams[2].Name != "Di" || teams[2].Total != 500 {
+		t.Errorf("Expected Di to stand alone, got %+v", teams[2])
+	}
+}
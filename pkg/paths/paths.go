@@ -0,0 +1,110 @@
+// Package paths decides where Tuneminal keeps its files, following the XDG
+// base directory conventions
+package paths
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// HomeEnv names the variable that puts every Tuneminal file in one directory,
+// e.g. a volume mounted into a container
+const HomeEnv = "TUNEMINAL_HOME"
+
+// appName is the subdirectory used inside each XDG base directory
+const appName = "tuneminal"
+
+// PortableDirName is the directory beside the executable that portable mode
+// keeps every file in, e.g. on a USB stick
+const PortableDirName = "tuneminal-data"
+
+// portableDir holds every file when set, see SetPortable
+var portableDir string
+
+// SetPortable keeps every file in dir, ahead of TUNEMINAL_HOME and the XDG
+// directories, so nothing is written to the home directory. An empty dir
+// turns portable mode off.
+func SetPortable(dir string) {
+	portableDir = dir
+}
+
+// Portable returns the directory portable mode keeps files in, or "" when
+// it is off
+func Portable() string {
+	return portableDir
+}
+
+// BesideExecutable returns the portable directory next to the running
+// executable
+func BesideExecutable() (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	return filepath.Join(filepath.Dir(executable), PortableDirName), nil
+}
+
+// legacyDir returns ~/.tuneminal, where every file lived before XDG support
+func legacyDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".tuneminal")
+}
+
+// baseDir picks the directory for one kind of file: the portable directory
+// or TUNEMINAL_HOME when set, then an existing ~/.tuneminal so older setups
+// keep their files, then the XDG directory from xdgEnv or its default under
+// the home directory
+func baseDir(xdgEnv string, xdgDefault ...string) string {
+	if portableDir != "" {
+		return portableDir
+	}
+	if dir := os.Getenv(HomeEnv); dir != "" {
+		return dir
+	}
+	if info, err := os.Stat(legacyDir()); err == nil && info.IsDir() {
+		return legacyDir()
+	}
+	if dir := os.Getenv(xdgEnv); filepath.IsAbs(dir) {
+		// The spec says relative paths are invalid and should be ignored
+		return filepath.Join(dir, appName)
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(append(append([]string{homeDir}, xdgDefault...), appName)...)
+}
+
+// ConfigDir is where settings are kept: $XDG_CONFIG_HOME/tuneminal, by
+// default ~/.config/tuneminal
+func ConfigDir() string {
+	return baseDir("XDG_CONFIG_HOME", ".config")
+}
+
+// DataDir is where playlists, history, exports and other user data are
+// kept: $XDG_DATA_HOME/tuneminal, by default ~/.local/share/tuneminal
+func DataDir() string {
+	return baseDir("XDG_DATA_HOME", ".local", "share")
+}
+
+// CacheDir is where data that can be worked out again, such as loudness
+// measurements and checksums, is kept: $XDG_CACHE_HOME/tuneminal, by default
+// ~/.cache/tuneminal
+func CacheDir() string {
+	return baseDir("XDG_CACHE_HOME", ".cache")
+}
+
+// Config returns the path of a file in the config directory
+func Config(name string) string {
+	return filepath.Join(ConfigDir(), name)
+}
+
+// Data returns the path of a file in the data directory
+func Data(name string) string {
+	return filepath.Join(DataDir(), name)
+}
+
+// Cache returns the path of a file in the cache directory
+func Cache(name string) string {
+	return filepath.Join(CacheDir(), name)
+}
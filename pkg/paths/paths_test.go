@@ -0,0 +1,77 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirectories(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(HomeEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	// XDG defaults
+	if got, want := Config("config.json"), filepath.Join(home, ".config", "tuneminal", "config.json"); got != want {
+		t.Errorf("Expected config at %s, got %s", want, got)
+	}
+	if got, want := DataDir(), filepath.Join(home, ".local", "share", "tuneminal"); got != want {
+		t.Errorf("Expected data in %s, got %s", want, got)
+	}
+	if got, want := CacheDir(), filepath.Join(home, ".cache", "tuneminal"); got != want {
+		t.Errorf("Expected cache in %s, got %s", want, got)
+	}
+
+	// XDG variables, ignoring relative ones
+	t.Setenv("XDG_DATA_HOME", "/srv/data")
+	t.Setenv("XDG_CACHE_HOME", "relative/cache")
+	if got := DataDir(); got != "/srv/data/tuneminal" {
+		t.Errorf("Expected XDG_DATA_HOME to be used, got %s", got)
+	}
+	if got, want := CacheDir(), filepath.Join(home, ".cache", "tuneminal"); got != want {
+		t.Errorf("Expected a relative XDG_CACHE_HOME to be ignored, got %s", got)
+	}
+
+	// An existing ~/.tuneminal keeps everything in one place
+	legacy := filepath.Join(home, ".tuneminal")
+	os.Mkdir(legacy, 0755)
+	for _, dir := range []string{ConfigDir(), DataDir(), CacheDir()} {
+		if dir != legacy {
+			t.Errorf("Expected the existing %s to be used, got %s", legacy, dir)
+		}
+	}
+
+	// TUNEMINAL_HOME overrides everything
+	t.Setenv(HomeEnv, "/data")
+	for _, dir := range []string{ConfigDir(), DataDir(), CacheDir()} {
+		if dir != "/data" {
+			t.Errorf("Expected TUNEMINAL_HOME to be used, got %s", dir)
+		}
+	}
+}
+
+func TestPortable(t *testing.T) {
+	t.Setenv(HomeEnv, "/data")
+	defer SetPortable("")
+
+	SetPortable("/media/usb/tuneminal-data")
+	if got := Data("history.jsonl"); got != "/media/usb/tuneminal-data/history.jsonl" {
+		t.Errorf("Expected portable mode to win over TUNEMINAL_HOME, got %s", got)
+	}
+	if Portable() != "/media/usb/tuneminal-data" {
+		t.Errorf("Unexpected portable directory %q", Portable())
+	}
+
+	SetPortable("")
+	if got := ConfigDir(); got != "/data" {
+		t.Errorf("Expected portable mode off to fall back to TUNEMINAL_HOME, got %s", got)
+	}
+
+	dir, err := BesideExecutable()
+	if err != nil || filepath.Base(dir) != PortableDirName {
+		t.Errorf("Unexpected directory beside the executable %q (%v)", dir, err)
+	}
+}
@@ -0,0 +1,64 @@
+package player
+
+import (
+	"math"
+)
+
+// Automatic gain control settings. Levels are RMS as a fraction of full scale.
+const (
+	agcTarget     = 0.2  // Level singing is brought to
+	agcNoiseFloor = 0.01 // Below this is taken as silence, and the gain is held
+	agcMaxGain    = 8.0
+	agcMinGain    = 0.25
+	agcAttack     = 0.5  // How much of the way to a lower gain each chunk moves, so shouts are caught quickly
+	agcRelease    = 0.05 // Likewise to a higher gain, slowly so pauses don't pump up the noise
+)
+
+// autoGain evens out the microphone level, so quiet and loud singers come
+// through alike
+type autoGain struct {
+	gain float64
+}
+
+// rmsLevel returns the RMS of 16-bit little-endian samples as a fraction of full scale
+func rmsLevel(pcm []byte) float64 {
+	var sum float64
+	count := 0
+	for i := 0; i+1 < len(pcm); i += 2 {
+		value := float64(int16(uint16(pcm[i])|uint16(pcm[i+1])<<8)) / 32768
+		sum += value * value
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return math.Sqrt(sum / float64(count))
+}
+
+// process applies the gain to a chunk in place, moving it towards the gain
+// that would bring the chunk to the target level. The change is spread over
+// the chunk so it doesn't click.
+func (g *autoGain) process(pcm []byte) {
+	if g.gain == 0 {
+		g.gain = 1
+	}
+	start := g.gain
+	if level := rmsLevel(pcm); level > agcNoiseFloor {
+		wanted := math.Max(agcMinGain, math.Min(agcMaxGain, agcTarget/level))
+		if wanted < g.gain {
+			g.gain += (wanted - g.gain) * agcAttack
+		} else {
+			g.gain += (wanted - g.gain) * agcRelease
+		}
+	}
+
+	samples := len(pcm) / 2
+	for i := 0; i < samples; i++ {
+		gain := start + (g.gain-start)*float64(i+1)/float64(samples)
+		value := float64(int16(uint16(pcm[2*i])|uint16(pcm[2*i+1])<<8)) * gain
+		value = math.Max(math.MinInt16, math.Min(math.MaxInt16, value))
+		sample := uint16(int16(value))
+		pcm[2*i] = byte(sample)
+		pcm[2*i+1] = byte(sample >> 8)
+	}
+}
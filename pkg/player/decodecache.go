@@ -0,0 +1,142 @@
+package player
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// DecodeCache keeps decoded songs between plays, so replaying one, as in
+// practice mode, skips decoding it again
+type DecodeCache interface {
+	// Get returns the path of the entry called name if there is one
+	Get(name string) (string, bool)
+	// Put stores an entry called name, written by write
+	Put(name string, write func(io.Writer) error) error
+}
+
+// decodeCacheMagic starts every decoded song in the cache, and changes
+// whenever the layout does
+const decodeCacheMagic = "TPCM1"
+
+// SetDecodeCache sets where decoded songs are kept, nil for nowhere
+func (p *AudioPlayer) SetDecodeCache(cache DecodeCache) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.decodeCache = cache
+}
+
+// decodeKey names the decoded samples of the song read from r, by its
+// content and the current pitch. It is empty without a decode cache.
+func (p *AudioPlayer) decodeKey(r io.Reader) string {
+	if p.decodeCache == nil {
+		return ""
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x%+d.pcm", hash.Sum(nil)[:16], p.pitch)
+}
+
+// decodeKeyForFile is decodeKey for the song in filename
+func (p *AudioPlayer) decodeKeyForFile(filename string) string {
+	if p.decodeCache == nil {
+		return ""
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	return p.decodeKey(file)
+}
+
+// cachedSamples returns the decoded samples kept under key, with their
+// sample rate and channel count
+func (p *AudioPlayer) cachedSamples(key string) ([][2]float64, int, int, bool) {
+	if key == "" {
+		return nil, 0, 0, false
+	}
+	path, ok := p.decodeCache.Get(key)
+	if !ok {
+		return nil, 0, 0, false
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, 0, false
+	}
+	defer file.Close()
+
+	samples, sampleRate, channels, err := readSamples(bufio.NewReader(file))
+	if err != nil {
+		return nil, 0, 0, false
+	}
+	return samples, sampleRate, channels, true
+}
+
+// storeSamples keeps decoded samples under key in the background. The cache
+// only saves time, so a failed write just means decoding again next play.
+func (p *AudioPlayer) storeSamples(key string, samples [][2]float64, sampleRate, channels int) {
+	if key == "" {
+		return
+	}
+	cache := p.decodeCache
+	go cache.Put(key, func(w io.Writer) error {
+		return writeSamples(w, samples, sampleRate, channels)
+	})
+}
+
+// writeSamples writes samples as 16-bit stereo frames after a short header
+func writeSamples(w io.Writer, samples [][2]float64, sampleRate, channels int) error {
+	buffered := bufio.NewWriter(w)
+	buffered.WriteString(decodeCacheMagic)
+	binary.Write(buffered, binary.LittleEndian, [2]uint32{uint32(sampleRate), uint32(channels)})
+
+	frame := make([]byte, 4)
+	for _, sample := range samples {
+		binary.LittleEndian.PutUint16(frame, uint16(sampleToInt16(sample[0])))
+		binary.LittleEndian.PutUint16(frame[2:], uint16(sampleToInt16(sample[1])))
+		if _, err := buffered.Write(frame); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
+
+// readSamples reads samples written by writeSamples
+func readSamples(r io.Reader) ([][2]float64, int, int, error) {
+	magic := make([]byte, len(decodeCacheMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != decodeCacheMagic {
+		return nil, 0, 0, errors.New("not a decoded song")
+	}
+	var header [2]uint32
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, 0, 0, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	samples := make([][2]float64, len(data)/4)
+	for i := range samples {
+		samples[i][0] = float64(int16(binary.LittleEndian.Uint16(data[i*4:]))) / 32767
+		samples[i][1] = float64(int16(binary.LittleEndian.Uint16(data[i*4+2:]))) / 32767
+	}
+	return samples, int(header[0]), int(header[1]), nil
+}
+
+// sampleToInt16 converts a sample to 16 bits, clamping it to the valid range
+func sampleToInt16(value float64) int16 {
+	if value > 1.0 {
+		value = 1.0
+	} else if value < -1.0 {
+		value = -1.0
+	}
+	return int16(value * 32767)
+}
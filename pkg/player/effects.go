@@ -0,0 +1,160 @@
+package player
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// MicEffects are the karaoke effects applied to the microphone before it is
+// mixed into the music
+type MicEffects struct {
+	Gain        float64 // Input gain, 1.0 for unchanged; ignored with AutoGain
+	AutoGain    bool    // Adjust the gain to keep the level steady
+	Echo        float64 // Echo level from 0.0 (off) to 0.9; each repeat is this much quieter
+	EchoDelayMs int     // Time between repeats
+	Reverb      float64 // Reverb level from 0.0 (dry) to 1.0
+}
+
+// DefaultMicEffects returns the effects a new setup starts with: automatic
+// gain, a little reverb and no echo
+func DefaultMicEffects() MicEffects {
+	return MicEffects{Gain: 1, AutoGain: true, EchoDelayMs: 250, Reverb: 0.2}
+}
+
+// Comb and allpass filter delays for the reverb, in milliseconds, from the
+// classic Schroeder design; the comb delays are mutually prime in samples at
+// common rates so their echoes don't pile up
+var (
+	reverbCombDelays    = []float64{29.7, 37.1, 41.1, 43.7}
+	reverbAllpassDelays = []float64{5.0, 1.7}
+)
+
+const (
+	reverbFeedback = 0.8
+	allpassGain    = 0.7
+	maxEchoDelayMs = 2000
+)
+
+// delayLine is a circular buffer of past samples
+type delayLine struct {
+	buffer []float64
+	pos    int
+}
+
+func newDelayLine(ms float64, sampleRate int) *delayLine {
+	length := int(ms * float64(sampleRate) / 1000)
+	if length < 1 {
+		length = 1
+	}
+	return &delayLine{buffer: make([]float64, length)}
+}
+
+// next returns the oldest sample and replaces it with value
+func (d *delayLine) next(value float64) float64 {
+	out := d.buffer[d.pos]
+	d.buffer[d.pos] = value
+	d.pos = (d.pos + 1) % len(d.buffer)
+	return out
+}
+
+// channelEffects is the effect state for one channel
+type channelEffects struct {
+	echo      *delayLine
+	echoMs    int
+	combs     []*delayLine
+	allpasses []*delayLine
+}
+
+// effectChain applies MicEffects to 16-bit PCM. The settings are read on
+// every call, so they can change while the mic is live; nil leaves it dry.
+type effectChain struct {
+	effects    *atomic.Pointer[MicEffects]
+	sampleRate int
+	channels   []*channelEffects
+	agc        autoGain
+}
+
+func newEffectChain(effects *atomic.Pointer[MicEffects], sampleRate, channels int) *effectChain {
+	c := &effectChain{effects: effects, sampleRate: sampleRate}
+	for i := 0; i < channels; i++ {
+		ch := &channelEffects{}
+		for _, ms := range reverbCombDelays {
+			ch.combs = append(ch.combs, newDelayLine(ms, sampleRate))
+		}
+		for _, ms := range reverbAllpassDelays {
+			ch.allpasses = append(ch.allpasses, newDelayLine(ms, sampleRate))
+		}
+		c.channels = append(c.channels, ch)
+	}
+	return c
+}
+
+// Process applies the effects to interleaved 16-bit little-endian samples in
+// place. It returns the voice's level after the gain, without the echo and
+// reverb tails, for metering.
+func (c *effectChain) Process(pcm []byte) float64 {
+	effects := c.effects.Load()
+	if effects == nil {
+		return rmsLevel(pcm)
+	}
+	echo := math.Max(0, math.Min(0.9, effects.Echo))
+	delayMs := effects.EchoDelayMs
+	if delayMs < 1 {
+		delayMs = 1
+	} else if delayMs > maxEchoDelayMs {
+		delayMs = maxEchoDelayMs
+	}
+
+	gain := effects.Gain
+	if effects.AutoGain {
+		c.agc.process(pcm)
+		gain = 1
+	}
+	level := rmsLevel(pcm) * gain
+
+	for i := 0; i+1 < len(pcm); i += 2 {
+		ch := c.channels[(i/2)%len(c.channels)]
+		value := float64(int16(uint16(pcm[i])|uint16(pcm[i+1])<<8)) * gain
+
+		if echo > 0 {
+			if ch.echo == nil || ch.echoMs != delayMs {
+				ch.echo = newDelayLine(float64(delayMs), c.sampleRate)
+				ch.echoMs = delayMs
+			}
+			value += echo * ch.echo.buffer[ch.echo.pos]
+			ch.echo.next(value)
+		} else {
+			ch.echo = nil
+		}
+
+		if effects.Reverb > 0 {
+			value += effects.Reverb * ch.reverb(value)
+		}
+
+		value = math.Max(math.MinInt16, math.Min(math.MaxInt16, value))
+		sample := uint16(int16(value))
+		pcm[i] = byte(sample)
+		pcm[i+1] = byte(sample >> 8)
+	}
+	return level
+}
+
+// reverb returns the reverberated part of one sample: parallel feedback
+// combs for the decay, then allpass filters to thicken it
+func (ch *channelEffects) reverb(value float64) float64 {
+	var wet float64
+	for _, comb := range ch.combs {
+		delayed := comb.buffer[comb.pos]
+		comb.next(value + delayed*reverbFeedback)
+		wet += delayed
+	}
+	wet /= float64(len(ch.combs))
+
+	for _, allpass := range ch.allpasses {
+		delayed := allpass.buffer[allpass.pos]
+		stored := wet + delayed*allpassGain
+		allpass.next(stored)
+		wet = delayed - stored*allpassGain
+	}
+	return wet
+}
@@ -0,0 +1,148 @@
+package player
+
+import (
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+// EQBands are the centre frequencies of the equalizer's bands, an octave
+// apart, in Hz
+var EQBands = [10]float64{31, 62, 125, 250, 500, 1000, 2000, 4000, 8000, 16000}
+
+// EQGains are the boost (positive) or cut (negative) of each band, in dB
+type EQGains [len(EQBands)]float64
+
+// MaxEQGain is the most a band can be boosted or cut, in dB
+const MaxEQGain = 12
+
+// EQ presets for common setups
+var EQPresets = map[string]EQGains{
+	"flat":       {},
+	"bass boost": {6, 6, 5, 3, 1, 0, 0, 0, 0, 0},
+	"vocal":      {-3, -3, -2, 0, 2, 4, 4, 3, 1, 0},
+	"treble":     {0, 0, 0, 0, 0, 0, 2, 4, 5, 6},
+}
+
+// EQPresetNames lists the presets in the order they are offered
+var EQPresetNames = []string{"flat", "bass boost", "vocal", "treble"}
+
+// SetEqualizer sets the gain of each equalizer band from now on; nil or all
+// zero turns the equalizer off
+func (p *AudioPlayer) SetEqualizer(gains *EQGains) {
+	if gains == nil || *gains == (EQGains{}) {
+		p.equalizer.Store(nil)
+		return
+	}
+	clamped := *gains
+	for band, gain := range clamped {
+		clamped[band] = math.Max(-MaxEQGain, math.Min(MaxEQGain, gain))
+	}
+	p.equalizer.Store(&clamped)
+}
+
+// eqQ is the filter quality that makes each band about an octave wide
+const eqQ = 1.41
+
+// biquad is a second-order filter with its state for one channel
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+// setPeak makes the filter a peaking EQ at freq boosting or cutting by gain
+// dB, from the Audio EQ Cookbook. The state is kept, so the sound doesn't
+// click when a band is moved during playback.
+func (f *biquad) setPeak(freq, gain float64, sampleRate int) {
+	a := math.Pow(10, gain/40)
+	w0 := 2 * math.Pi * freq / float64(sampleRate)
+	alpha := math.Sin(w0) / (2 * eqQ)
+	a0 := 1 + alpha/a
+	f.b0 = (1 + alpha*a) / a0
+	f.b1 = -2 * math.Cos(w0) / a0
+	f.b2 = (1 - alpha*a) / a0
+	f.a1 = f.b1
+	f.a2 = (1 - alpha/a) / a0
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// eqReader runs 16-bit PCM through the equalizer as it is read. The gains
+// are checked on every read so they can change during playback; nil leaves
+// the sound as it is.
+type eqReader struct {
+	reader     io.Reader
+	gains      *atomic.Pointer[EQGains]
+	sampleRate int
+	channels   int
+	offset     int64 // byte offset of the next read, to keep samples aligned
+
+	applied *EQGains               // gains the filters were last set for
+	filters [len(EQBands)][]biquad // each band's filter for each channel
+	active  [len(EQBands)]bool     // bands that change the sound
+}
+
+func newEQReader(reader io.Reader, gains *atomic.Pointer[EQGains], sampleRate, channels int, offset int64) *eqReader {
+	e := &eqReader{reader: reader, gains: gains, sampleRate: sampleRate, channels: channels, offset: offset}
+	for band := range e.filters {
+		e.filters[band] = make([]biquad, channels)
+	}
+	return e
+}
+
+// setGains points the filters at new gains. Bands at 0 dB, or too close to
+// the top of the sample rate to filter, are skipped.
+func (e *eqReader) setGains(gains *EQGains) {
+	e.applied = gains
+	for band, freq := range EQBands {
+		e.active[band] = gains[band] != 0 && freq < 0.45*float64(e.sampleRate)
+		if !e.active[band] {
+			continue
+		}
+		for channel := range e.filters[band] {
+			e.filters[band][channel].setPeak(freq, gains[band], e.sampleRate)
+		}
+	}
+}
+
+func (e *eqReader) Read(p []byte) (int, error) {
+	n, err := e.reader.Read(p)
+
+	gains := e.gains.Load()
+	if gains == nil || e.channels < 1 {
+		e.applied = nil
+		e.offset += int64(n)
+		return n, err
+	}
+	if gains != e.applied {
+		if e.applied == nil {
+			// Start clean rather than from where the filters were last off
+			for band := range e.filters {
+				clear(e.filters[band])
+			}
+		}
+		e.setGains(gains)
+	}
+
+	start := int(e.offset % 2)
+	for i := start; i+1 < n; i += 2 {
+		channel := int((e.offset+int64(i))/2) % e.channels
+		value := float64(int16(uint16(p[i]) | uint16(p[i+1])<<8))
+		for band := range e.filters {
+			if e.active[band] {
+				value = e.filters[band][channel].process(value)
+			}
+		}
+		value = math.Max(math.MinInt16, math.Min(math.MaxInt16, value))
+		sample := uint16(int16(value))
+		p[i] = byte(sample)
+		p[i+1] = byte(sample >> 8)
+	}
+	e.offset += int64(n)
+	return n, err
+}
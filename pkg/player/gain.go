@@ -0,0 +1,32 @@
+package player
+
+import (
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+// gainReader scales 16-bit PCM as it is read, so the level can change
+// during playback, as the volume and fades do
+type gainReader struct {
+	reader io.Reader
+	gain   *atomic.Pointer[float64]
+	offset int64 // byte offset of the next read, to keep samples aligned
+}
+
+func (g *gainReader) Read(p []byte) (int, error) {
+	n, err := g.reader.Read(p)
+
+	if gain := g.gain.Load(); gain != nil && *gain != 1 {
+		start := int(g.offset % 2)
+		for i := start; i+1 < n; i += 2 {
+			value := float64(int16(uint16(p[i])|uint16(p[i+1])<<8)) * *gain
+			value = math.Max(math.MinInt16, math.Min(math.MaxInt16, value))
+			sample := uint16(int16(value))
+			p[i] = byte(sample)
+			p[i+1] = byte(sample >> 8)
+		}
+	}
+	g.offset += int64(n)
+	return n, err
+}
@@ -0,0 +1,226 @@
+package player
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// queuedSong is a song readied to follow the playing one without a gap
+type queuedSong struct {
+	name       string
+	source     songSource
+	pitch      int // pitch shift it was decoded with
+	duration   time.Duration
+	sampleRate int
+	channels   int
+}
+
+// songQueue hands the queued song to the output when the playing one runs
+// out. It has a mutex of its own because the output reads while the
+// player's is held.
+type songQueue struct {
+	mutex   sync.Mutex
+	gen     int         // changes whenever the queue is cleared, so late songs are dropped
+	next    *queuedSong // ready, not reached yet
+	started *queuedSong // being read by the output, not the current song yet
+}
+
+// reset empties the queue, returning the generation a new song is queued for
+func (q *songQueue) reset() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, song := range []*queuedSong{q.next, q.started} {
+		if song != nil {
+			song.source.close()
+		}
+	}
+	q.next, q.started = nil, nil
+	q.gen++
+	return q.gen
+}
+
+// set queues song if the queue wasn't reset since gen, reporting whether it was
+func (q *songQueue) set(gen int, song *queuedSong) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if gen != q.gen {
+		return false
+	}
+	q.next = song
+	return true
+}
+
+// take moves the queued song to started for the output to read next, if
+// it is in the format the output is playing
+func (q *songQueue) take(sampleRate, channels int) *queuedSong {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	next := q.next
+	if next == nil || next.sampleRate != sampleRate || next.channels != channels {
+		return nil
+	}
+	q.next, q.started = nil, next
+	return next
+}
+
+// takeStarted returns the song the output has moved on to, if any
+func (q *songQueue) takeStarted() *queuedSong {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	started := q.started
+	q.started = nil
+	return started
+}
+
+// unstart puts a started song back in the queue when the output reading it
+// is replaced, as by a seek
+func (q *songQueue) unstart() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.started != nil {
+		q.next, q.started = q.started, nil
+	}
+}
+
+// chainReader reads the playing song and then carries straight on into the
+// queued one
+type chainReader struct {
+	reader     io.Reader
+	queue      *songQueue
+	sampleRate int
+	channels   int
+	onSwitch   func() // called when the queued song starts, before any of it is read
+}
+
+func (r *chainReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err != io.EOF || n > 0 {
+		if err == io.EOF {
+			err = nil
+		}
+		return n, err
+	}
+
+	next := r.queue.take(r.sampleRate, r.channels)
+	if next == nil {
+		return 0, io.EOF
+	}
+	r.reader = next.source.reader(0)
+	if r.onSwitch != nil {
+		r.onSwitch()
+	}
+	return r.reader.Read(p)
+}
+
+// QueueNext readies the song in filename to follow the playing one without
+// a gap, decoding what it can in the background. pitch and trackGain are
+// the song's own, as SetPitch and SetTrackGain would give it. Loading
+// another song drops the queued one, and a song in a different format from
+// the playing one isn't queued, as the output can't change format under it.
+func (p *AudioPlayer) QueueNext(filename string, pitch int, trackGain float64) {
+	p.mutex.RLock()
+	outputRate, gain := p.outputRate, trackGain
+	p.mutex.RUnlock()
+	gen := p.queue.reset()
+
+	go func() {
+		song, err := prepareSong(filename, pitch, outputRate, gain)
+		if err != nil {
+			return
+		}
+
+		p.mutex.RLock()
+		fits := song.sampleRate == p.sampleRate && song.channels == p.channels
+		p.mutex.RUnlock()
+		if !fits || !p.queue.set(gen, song) {
+			song.source.close()
+		}
+	}()
+}
+
+// ClearNext drops the song queued to play next
+func (p *AudioPlayer) ClearNext() {
+	p.queue.reset()
+}
+
+// TakeAdvance reports, once, that playback moved on to the queued song,
+// with the song's file name
+func (p *AudioPlayer) TakeAdvance() (string, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	name, ok := p.advancedTo, p.advanced
+	p.advancedTo, p.advanced = "", false
+	return name, ok
+}
+
+// advance makes the song the output moved on to the current one, timed from
+// where the previous one ended (caller must hold the mutex)
+func (p *AudioPlayer) advance(next *queuedSong) {
+	if music, ok := p.player.(*musicStream); ok {
+		p.mixer.mutex.Lock()
+		p.songStart = music.switchedAt
+		p.mixer.mutex.Unlock()
+	}
+	p.source.close()
+	p.source = next.source
+	p.duration = next.duration
+	p.currentFile = next.name
+	p.pitch, p.loadedPitch = next.pitch, next.pitch
+	p.position = p.playedPosition()
+	p.advancedTo, p.advanced = next.name, true
+}
+
+// prepareSong decodes the song in filename to follow another. As with
+// load, it is decoded as it plays unless it has to be processed as a whole.
+func prepareSong(filename string, pitch, outputRate int, gain float64) (*queuedSong, error) {
+	streamer, format, err := Decode(filename)
+	if err != nil {
+		return nil, err
+	}
+	sampleRate, channels := int(format.SampleRate), format.NumChannels
+	if pitch == 0 && (outputRate == 0 || outputRate == sampleRate) {
+		return &queuedSong{
+			name:       filename,
+			source:     newDecodingSource(streamer, channels, gain),
+			duration:   utils.FramesDuration(int64(streamer.Len()), sampleRate),
+			sampleRate: sampleRate,
+			channels:   channels,
+		}, nil
+	}
+	defer streamer.Close()
+
+	samples := pitchShift(streamAll(streamer), sampleRate, pitch)
+	if outputRate > 0 && sampleRate != outputRate {
+		ratio := float64(sampleRate) / float64(outputRate)
+		samples = resample(samples, ratio, int(float64(len(samples))/ratio))
+		sampleRate = outputRate
+	}
+	data := make([]byte, 0, len(samples)*2*channels)
+	for _, sample := range samples {
+		data = appendFrame(data, sample, gain, channels)
+	}
+	return &queuedSong{
+		name:       filename,
+		source:     &memorySource{data: data},
+		pitch:      pitch,
+		duration:   utils.FramesDuration(int64(len(samples)), sampleRate),
+		sampleRate: sampleRate,
+		channels:   channels,
+	}, nil
+}
+
+// HasQueued reports whether a song is queued to follow the playing one
+func (p *AudioPlayer) HasQueued() bool {
+	p.queue.mutex.Lock()
+	defer p.queue.mutex.Unlock()
+	return p.queue.next != nil || p.queue.started != nil
+}
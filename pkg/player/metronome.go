@@ -0,0 +1,87 @@
+package player
+
+import (
+	"io"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// MetronomeConfig describes a click track mixed into playback
+type MetronomeConfig struct {
+	BPM         float64
+	Offset      time.Duration // Position of the first beat
+	Volume      float64       // Click level from 0.0 to 1.0
+	BeatsPerBar int           // Accent the first beat of each bar, 0 for no accent
+}
+
+const (
+	clickLength   = 30 * time.Millisecond
+	clickDecay    = 0.008 // seconds
+	clickFreq     = 1000.0
+	clickAccentHz = 1500.0
+)
+
+// clickSample returns the click level t seconds into a beat
+func clickSample(t float64, accent bool) float64 {
+	if t < 0 || t >= clickLength.Seconds() {
+		return 0
+	}
+	freq := clickFreq
+	if accent {
+		freq = clickAccentHz
+	}
+	return math.Sin(2*math.Pi*freq*t) * math.Exp(-t/clickDecay)
+}
+
+// metronomeReader mixes the metronome click into 16-bit PCM as it is read, so
+// the clicks stay locked to the audio whatever the output latency
+type metronomeReader struct {
+	reader     io.Reader
+	config     *atomic.Pointer[MetronomeConfig]
+	sampleRate int
+	channels   int
+	offset     int64 // byte offset of the next read within the track
+}
+
+func (m *metronomeReader) Read(p []byte) (int, error) {
+	n, err := m.reader.Read(p)
+
+	config := m.config.Load()
+	if config != nil && config.BPM > 0 && config.Volume > 0 {
+		m.mix(p[:n], config)
+	}
+	m.offset += int64(n)
+	return n, err
+}
+
+// mix adds clicks to whole samples in buf, which starts at m.offset
+func (m *metronomeReader) mix(buf []byte, config *MetronomeConfig) {
+	frameSize := int64(2 * m.channels)
+	beatLength := 60 / config.BPM
+	start := config.Offset.Seconds()
+
+	for i := int64(0); i+1 < int64(len(buf)); i++ {
+		position := m.offset + i
+		if position%2 != 0 {
+			continue
+		}
+
+		t := float64(position/frameSize)/float64(m.sampleRate) - start
+		if t < 0 {
+			continue
+		}
+		beat := math.Floor(t / beatLength)
+		accent := config.BeatsPerBar > 0 && int64(beat)%int64(config.BeatsPerBar) == 0
+		click := clickSample(t-beat*beatLength, accent)
+		if click == 0 {
+			continue
+		}
+
+		value := float64(int16(uint16(buf[i])|uint16(buf[i+1])<<8)) + click*config.Volume*math.MaxInt16
+		value = math.Max(math.MinInt16, math.Min(math.MaxInt16, value))
+		sample := uint16(int16(value))
+		buf[i] = byte(sample)
+		buf[i+1] = byte(sample >> 8)
+	}
+}
@@ -0,0 +1,126 @@
+package player
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// MicConfig passes a microphone through to the main output, so the singer
+// is heard over the same speakers as the music while a song plays
+type MicConfig struct {
+	Device string  // ALSA capture device
+	Level  float64 // Microphone gain in the mix (0.0 to 1.0)
+}
+
+// maxMicBuffer bounds buffered microphone audio so the mix stays close to real time
+const maxMicBuffer = 16384
+
+// micChunk is how much is captured at a time; a whole number of frames in
+// mono and stereo, about 20ms at 48kHz stereo
+const micChunk = 4096
+
+// micInput captures a microphone through arecord, applying the mic effects
+// as it goes, and buffers it for mixing into music
+type micInput struct {
+	device     string
+	sampleRate int
+	channels   int
+
+	cmd   *exec.Cmd
+	mutex sync.Mutex
+	data  []byte
+	level atomic.Uint64 // math.Float64bits of the last chunk's RMS level
+}
+
+// newMicInput starts capturing device in the given PCM format. effects may
+// be nil for none.
+func newMicInput(device string, sampleRate, channels int, effects *atomic.Pointer[MicEffects]) (*micInput, error) {
+	m := &micInput{device: device, sampleRate: sampleRate, channels: channels}
+	m.cmd = exec.Command("arecord", pcmArgs(device, sampleRate, channels)...)
+	stdout, err := m.cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open microphone: %w", err)
+	}
+	if err := m.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start microphone capture on %s: %w", device, err)
+	}
+
+	var chain *effectChain
+	if effects != nil {
+		chain = newEffectChain(effects, sampleRate, channels)
+	}
+	go func() {
+		buffer := make([]byte, micChunk)
+		for {
+			// Whole chunks keep the effects on sample boundaries
+			if _, err := io.ReadFull(stdout, buffer); err != nil {
+				return
+			}
+			var level float64
+			if chain != nil {
+				level = chain.Process(buffer)
+			} else {
+				level = rmsLevel(buffer)
+			}
+			m.level.Store(math.Float64bits(level))
+			m.mutex.Lock()
+			m.data = append(m.data, buffer...)
+			if len(m.data) > maxMicBuffer {
+				// Drop the oldest audio rather than fall behind
+				m.data = m.data[len(m.data)-maxMicBuffer:]
+			}
+			m.mutex.Unlock()
+		}
+	}()
+	return m, nil
+}
+
+// pcmArgs returns aplay/arecord arguments for raw 16-bit PCM in the given format
+func pcmArgs(device string, sampleRate, channels int) []string {
+	return []string{
+		"-q",
+		"-D", device,
+		"-t", "raw",
+		"-f", "S16_LE",
+		"-r", strconv.Itoa(sampleRate),
+		"-c", strconv.Itoa(channels),
+	}
+}
+
+// Level returns the mic's current RMS level, after the gain, as a fraction
+// of full scale
+func (m *micInput) Level() float64 {
+	return math.Float64frombits(m.level.Load())
+}
+
+// Matches reports whether the mic is capturing device in the given format
+func (m *micInput) Matches(device string, sampleRate, channels int) bool {
+	return m.device == device && m.sampleRate == sampleRate && m.channels == channels
+}
+
+// mixInto adds as many whole frames of buffered mic audio as fit into dst
+// at level, and drops them from the buffer. Taking part of a frame would put
+// the left channel on the right from then on.
+func (m *micInput) mixInto(dst []byte, level float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	frame := 2 * m.channels
+	n := min(len(dst), len(m.data))
+	n -= n % frame
+	mixPCM(dst[:n], m.data[:n], level)
+	m.data = m.data[n:]
+}
+
+// Close stops capturing
+func (m *micInput) Close() error {
+	if m.cmd.Process != nil {
+		m.cmd.Process.Kill()
+		m.cmd.Wait()
+	}
+	return nil
+}
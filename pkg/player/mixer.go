@@ -0,0 +1,341 @@
+package player
+
+import (
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// Source is one kind of audio the mixer sums into the output, each with a
+// gain of its own
+type Source int
+
+const (
+	SourceMusic      Source = iota // the playing song, with its click track
+	SourceSoundboard               // effects played over the music
+	SourcePreview                  // short clips auditioned while browsing
+	SourceMic                      // the microphone passed through
+	SourceSpeech                   // spoken announcements
+	sourceCount
+)
+
+// mixer sums everything that plays at once into the PCM the output reads:
+// the song while one is playing, one-off clips such as soundboard effects,
+// and the microphone. It has a mutex of its own because the output reads
+// while the player's is held.
+type mixer struct {
+	mutex      sync.Mutex
+	gains      [sourceCount]float64
+	music      *musicStream // the song attached to the output, nil for none
+	clips      []mixerClip
+	mic        *micInput // nil when the mic isn't passed through
+	micLevel   float64
+	sampleRate int // format of the clips, which is the output's
+	channels   int
+	offset     int64 // byte offset of the next read, to keep to whole samples
+}
+
+// mixerClip is PCM still to play from a one-off clip, in the output format
+// with its volume applied
+type mixerClip struct {
+	source Source
+	pcm    []byte
+}
+
+// newMixer returns a mixer with every source at full gain
+func newMixer() *mixer {
+	m := &mixer{}
+	for i := range m.gains {
+		m.gains[i] = 1
+	}
+	return m
+}
+
+func (m *mixer) Read(p []byte) (int, error) {
+	// Read whole samples where possible so none is split between reads
+	if len(p) > 1 {
+		p = p[:len(p)&^1]
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	n := 0
+	if music := m.music; music != nil && music.playing && !music.ended {
+		var err error
+		n, err = music.reader.Read(p)
+		music.read += int64(n)
+		if n > 0 {
+			music.mixEnd = m.offset + int64(n)
+		}
+		if err != nil && n == 0 {
+			// The rest keeps playing after the song
+			music.ended = true
+			if err != io.EOF {
+				music.err = err
+			}
+		}
+		if gain := m.gains[SourceMusic]; gain != 1 && n > 0 {
+			scaleSamples(p[m.offset%2:n], gain)
+		}
+	}
+	if n == 0 {
+		n = len(p)
+		clear(p)
+	}
+
+	buf := p[m.offset%2 : n]
+	m.mixClips(buf)
+	if m.mic != nil {
+		m.mic.mixInto(buf, m.micLevel*m.gains[SourceMic])
+	}
+	m.offset += int64(n)
+	return n, nil
+}
+
+// mixClips adds the playing clips to buf, which starts on a whole sample,
+// dropping those that finish
+func (m *mixer) mixClips(buf []byte) {
+	playing := m.clips[:0]
+	for _, clip := range m.clips {
+		gain := m.gains[clip.source]
+		pcm := clip.pcm
+		for i := 0; i+1 < len(buf) && len(pcm) >= 2; i += 2 {
+			if gain > 0 {
+				value := float64(int16(uint16(buf[i])|uint16(buf[i+1])<<8)) + gain*float64(int16(uint16(pcm[0])|uint16(pcm[1])<<8))
+				putSample(buf[i:], value)
+			}
+			pcm = pcm[2:]
+		}
+		if len(pcm) >= 2 {
+			playing = append(playing, mixerClip{source: clip.source, pcm: pcm})
+		}
+	}
+	m.clips = playing
+}
+
+// scaleSamples multiplies each 16-bit sample in buf by gain
+func scaleSamples(buf []byte, gain float64) {
+	for i := 0; i+1 < len(buf); i += 2 {
+		putSample(buf[i:], gain*float64(int16(uint16(buf[i])|uint16(buf[i+1])<<8)))
+	}
+}
+
+// putSample writes value to the start of buf as a clipped 16-bit sample
+func putSample(buf []byte, value float64) {
+	value = math.Max(math.MinInt16, math.Min(math.MaxInt16, value))
+	sample := uint16(int16(value))
+	buf[0] = byte(sample)
+	buf[1] = byte(sample >> 8)
+}
+
+// addClip starts pcm playing as source. Clips in a format other than the
+// output's are dropped when the format changes.
+func (m *mixer) addClip(source Source, pcm []byte) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.clips = append(m.clips, mixerClip{source: source, pcm: pcm})
+}
+
+// stopClips silences the clips from source
+func (m *mixer) stopClips(source Source) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	playing := m.clips[:0]
+	for _, clip := range m.clips {
+		if clip.source != source {
+			playing = append(playing, clip)
+		}
+	}
+	m.clips = playing
+}
+
+// setOutput readies the mixer for an output in the given format with the mic
+// passed through at level, or no mic when nil. Clips in another format can't
+// carry on and are dropped.
+func (m *mixer) setOutput(sampleRate, channels int, mic *micInput, level float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if sampleRate != m.sampleRate || channels != m.channels {
+		m.clips = nil
+	}
+	m.sampleRate, m.channels = sampleRate, channels
+	m.mic, m.micLevel = mic, level
+	m.offset = 0
+}
+
+// setGain sets the gain of source, 1 for as it is
+func (m *mixer) setGain(source Source, gain float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.gains[source] = max(0, gain)
+}
+
+// gain returns the gain of source
+func (m *mixer) gain(source Source) float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.gains[source]
+}
+
+// idle reports whether nothing but the mic would be heard
+func (m *mixer) idle() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.clips) == 0 && (m.music == nil || !m.music.playing || m.music.ended)
+}
+
+// musicStream is a song playing through the mixer. The player starts,
+// pauses and closes it as it would an output of its own, though the output
+// belongs to the mixer and carries on for clips while the song is paused.
+// Its methods are called with the player's mutex held.
+type musicStream struct {
+	player  *AudioPlayer
+	reader  io.Reader
+	playing bool // the rest are guarded by the mixer's mutex
+	ended   bool
+	err     error
+
+	// Positions in the music, in bytes from the start of the song the
+	// stream began in, so a stream started by a seek begins part way
+	start      int64 // where the stream began
+	read       int64 // how far the mixer has read
+	switchedAt int64 // where the song queued after it began, once it has
+	mixEnd     int64 // the mixer's offset just after the last music read
+}
+
+func (s *musicStream) Play() {
+	m := s.player.mixer
+	m.mutex.Lock()
+	attached := m.music == s
+	s.playing = attached
+	m.mutex.Unlock()
+
+	if attached {
+		s.player.openMixOutput().Play()
+	}
+}
+
+func (s *musicStream) Pause() {
+	m := s.player.mixer
+	m.mutex.Lock()
+	s.playing = false
+	attached := m.music == s
+	m.mutex.Unlock()
+
+	if attached && m.idle() && s.player.mixOut != nil {
+		s.player.mixOut.Pause()
+	}
+}
+
+func (s *musicStream) Close() error {
+	m := s.player.mixer
+	m.mutex.Lock()
+	s.playing = false
+	attached := m.music == s
+	if attached {
+		m.music = nil
+	}
+	m.mutex.Unlock()
+
+	// With nothing else playing, what the output still holds of the song
+	// goes with it
+	if attached && m.idle() {
+		s.player.closeMixOutput()
+	}
+	return nil
+}
+
+func (s *musicStream) Err() error {
+	if out := s.player.mixOut; out != nil {
+		if err := out.Err(); err != nil {
+			return err
+		}
+	}
+	m := s.player.mixer
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return s.err
+}
+
+// openMixOutput returns the output the mixer plays on, opening it if it
+// isn't open: the mix goes to the monitor output too when one is configured
+// (caller must hold the mutex)
+func (p *AudioPlayer) openMixOutput() stream {
+	if p.mixOut != nil {
+		return p.mixOut
+	}
+	if err := p.openOutput(); err != nil {
+		p.mixOut = &failedStream{err: err}
+		return p.mixOut
+	}
+	p.lastRead.Store(time.Now().UnixNano())
+
+	mic := p.openMic()
+	level := 0.0
+	if mic != nil {
+		level = p.micConfig.Level
+	}
+	p.mixer.setOutput(p.sampleRate, p.channels, mic, level)
+
+	var reader io.Reader = p.mixer
+	if monitor := p.openMonitor(); monitor != nil {
+		reader = &teeReader{reader: reader, monitor: monitor}
+	}
+	p.mixOut = p.outputStream(&activityReader{reader: reader, last: &p.lastRead})
+	return p.mixOut
+}
+
+// closeMixOutput closes the mixer's output, dropping what it still holds
+// (caller must hold the mutex)
+func (p *AudioPlayer) closeMixOutput() {
+	if p.mixOut != nil {
+		p.mixOut.Pause()
+		p.mixOut.Close()
+		p.mixOut = nil
+	}
+}
+
+// SetSourceGain sets the gain of one source in the mix, from 0.0 (silent) to
+// 1.0 (as it is), on top of the volume. The change is heard immediately.
+func (p *AudioPlayer) SetSourceGain(source Source, gain float64) {
+	p.mixer.setGain(source, gain)
+}
+
+// SourceGain returns the gain of one source in the mix
+func (p *AudioPlayer) SourceGain(source Source) float64 {
+	return p.mixer.gain(source)
+}
+
+// PlayClip plays sound over whatever else is playing as source, at volume
+// (0.0 to 1.0) scaled by the player's own volume. Several can play at once,
+// with or without a song; they carry on across seeks and pauses.
+func (p *AudioPlayer) PlayClip(source Source, sound *Sound, volume float64) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	out := p.openMixOutput()
+	if err := out.Err(); err != nil {
+		p.mixOut = nil
+		return err
+	}
+
+	samples := sound.samples
+	if sound.sampleRate != p.sampleRate {
+		ratio := float64(sound.sampleRate) / float64(p.sampleRate)
+		samples = resample(samples, ratio, int(float64(len(samples))/ratio))
+	}
+	pcm := make([]byte, 0, len(samples)*2*p.channels)
+	for _, sample := range samples {
+		pcm = appendFrame(pcm, sample, p.volume*volume, p.channels)
+	}
+	p.mixer.addClip(source, pcm)
+	out.Play()
+	return nil
+}
+
+// StopClips silences the clips playing as source
+func (p *AudioPlayer) StopClips(source Source) {
+	p.mixer.stopClips(source)
+}
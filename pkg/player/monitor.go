@@ -0,0 +1,163 @@
+package player
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"sync/atomic"
+)
+
+// MonitorConfig describes a secondary output that receives the backing track,
+// optionally mixed with a microphone, e.g. for a singer's headphones
+type MonitorConfig struct {
+	Device    string  // ALSA playback device, e.g. "hw:1,0"
+	MicDevice string  // ALSA capture device to mix in, empty for music only
+	MicLevel  float64 // Microphone gain in the monitor mix (0.0 to 1.0)
+}
+
+// MonitorOutput streams PCM to a secondary playback device through aplay
+type MonitorOutput struct {
+	config     MonitorConfig
+	sampleRate int
+	channels   int
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	chunks chan []byte
+	done   chan struct{}
+
+	mic *micInput
+}
+
+// NewMonitorOutput starts a monitor output for the given PCM format. The mic
+// effects, which may be nil, are applied to its microphone.
+func NewMonitorOutput(config MonitorConfig, sampleRate, channels int, effects *atomic.Pointer[MicEffects]) (*MonitorOutput, error) {
+	m := &MonitorOutput{
+		config:     config,
+		sampleRate: sampleRate,
+		channels:   channels,
+		chunks:     make(chan []byte, 64),
+		done:       make(chan struct{}),
+	}
+
+	m.cmd = exec.Command("aplay", pcmArgs(config.Device, sampleRate, channels)...)
+	stdin, err := m.cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open monitor output: %w", err)
+	}
+	m.stdin = stdin
+	if err := m.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start monitor output on %s: %w", config.Device, err)
+	}
+
+	if config.MicDevice != "" {
+		mic, err := newMicInput(config.MicDevice, sampleRate, channels, effects)
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		m.mic = mic
+	}
+
+	go m.writeLoop()
+	return m, nil
+}
+
+// Write queues music PCM for the monitor without ever blocking the main output
+func (m *MonitorOutput) Write(data []byte) (int, error) {
+	chunk := make([]byte, len(data))
+	copy(chunk, data)
+
+	select {
+	case m.chunks <- chunk:
+	default:
+		// Monitor device is falling behind; skip rather than stall playback
+	}
+	return len(data), nil
+}
+
+// writeLoop mixes in the microphone and feeds the monitor device
+func (m *MonitorOutput) writeLoop() {
+	for {
+		select {
+		case chunk := <-m.chunks:
+			if m.mic != nil {
+				m.mic.mixInto(chunk, m.config.MicLevel)
+			}
+			if _, err := m.stdin.Write(chunk); err != nil {
+				return
+			}
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// MicLevel returns the monitor microphone's current level, and false when
+// it has none
+func (m *MonitorOutput) MicLevel() (float64, bool) {
+	if m.mic == nil {
+		return 0, false
+	}
+	return m.mic.Level(), true
+}
+
+// Matches reports whether the monitor is running with the given settings and format
+func (m *MonitorOutput) Matches(config MonitorConfig, sampleRate, channels int) bool {
+	return m.config == config && m.sampleRate == sampleRate && m.channels == channels
+}
+
+// Close stops the monitor output and microphone capture
+func (m *MonitorOutput) Close() error {
+	select {
+	case <-m.done:
+		return nil
+	default:
+		close(m.done)
+	}
+
+	if m.stdin != nil {
+		m.stdin.Close()
+	}
+	if m.cmd != nil && m.cmd.Process != nil {
+		m.cmd.Process.Kill()
+		m.cmd.Wait()
+	}
+	if m.mic != nil {
+		m.mic.Close()
+	}
+	return nil
+}
+
+// mixPCM adds 16-bit little-endian samples from src into dst at the given level
+func mixPCM(dst, src []byte, level float64) {
+	for i := 0; i+1 < len(dst) && i+1 < len(src); i += 2 {
+		music := float64(int16(uint16(dst[i]) | uint16(dst[i+1])<<8))
+		voice := float64(int16(uint16(src[i]) | uint16(src[i+1])<<8))
+
+		mixed := music + voice*level
+		if mixed > 32767 {
+			mixed = 32767
+		} else if mixed < -32768 {
+			mixed = -32768
+		}
+
+		value := int16(mixed)
+		dst[i] = byte(value)
+		dst[i+1] = byte(value >> 8)
+	}
+}
+
+// teeReader copies everything read from the main output to the monitor
+type teeReader struct {
+	reader  io.Reader
+	monitor *MonitorOutput
+}
+
+func (t *teeReader) Read(p []byte) (int, error) {
+	n, err := t.reader.Read(p)
+	if n > 0 {
+		t.monitor.Write(p[:n])
+	}
+	return n, err
+}
@@ -0,0 +1,266 @@
+package player
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Output backends for OutputConfig.Backend
+const (
+	OutputDefault  = ""         // Oto, through ALSA on Linux
+	OutputPipeWire = "pipewire" // pw-cat
+	OutputJACK     = "jack"     // jack-stdin, at the JACK server's sample rate
+)
+
+// OutputConfig sends playback to PipeWire or JACK directly instead of the
+// default output, for live rigs that need lower latency or their own routing
+type OutputConfig struct {
+	Backend   string
+	Device    string // PipeWire target node, or JACK ports separated by commas; empty for the default
+	LatencyMs int    // PipeWire node latency, 0 for the server's default
+}
+
+// defaultJACKPorts are the ports played to when none are configured
+var defaultJACKPorts = []string{"system:playback_1", "system:playback_2"}
+
+// stream is one track playing on an output; *oto.Player is one
+type stream interface {
+	Play()
+	Pause()
+	Close() error
+	Err() error
+}
+
+// outputCommand returns the program and arguments that play raw 16-bit
+// little-endian PCM in the given format from stdin
+func outputCommand(config OutputConfig, sampleRate, channels int) (string, []string, error) {
+	switch config.Backend {
+	case OutputPipeWire:
+		args := []string{
+			"--playback", "--raw",
+			"--format", "s16",
+			"--rate", strconv.Itoa(sampleRate),
+			"--channels", strconv.Itoa(channels),
+		}
+		if config.LatencyMs > 0 {
+			args = append(args, "--latency", strconv.Itoa(config.LatencyMs)+"ms")
+		}
+		if config.Device != "" {
+			args = append(args, "--target", config.Device)
+		}
+		return "pw-cat", append(args, "-"), nil
+	case OutputJACK:
+		ports := defaultJACKPorts
+		if config.Device != "" {
+			ports = strings.Split(config.Device, ",")
+		}
+		if len(ports) > channels {
+			ports = ports[:channels]
+		}
+		args := []string{"-q", "-b", "16", "-e", "signed", "-L"}
+		for _, port := range ports {
+			args = append(args, strings.TrimSpace(port))
+		}
+		return "jack-stdin", args, nil
+	default:
+		return "", nil, fmt.Errorf("unknown audio output %q", config.Backend)
+	}
+}
+
+// jackSampleRate asks the running JACK server for its sample rate, which
+// songs are converted to since jack-stdin doesn't resample
+func jackSampleRate() (int, error) {
+	output, err := exec.Command("jack_samplerate").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to ask JACK for its sample rate (is it running?): %w", err)
+	}
+	rate, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil || rate <= 0 {
+		return 0, fmt.Errorf("unexpected JACK sample rate %q", strings.TrimSpace(string(output)))
+	}
+	return rate, nil
+}
+
+// pipeOutput is a running pw-cat or jack-stdin that tracks are written to.
+// It stays open between tracks of the same format, so starting one doesn't
+// wait for the program to connect.
+type pipeOutput struct {
+	config     OutputConfig
+	sampleRate int
+	channels   int
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr lockedBuffer
+	mutex  sync.Mutex // One writer at a time
+}
+
+// lockedBuffer collects what the output program writes to stderr. exec copies
+// into it from its own goroutine while write may be reading it.
+type lockedBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
+
+// newPipeOutput starts the output program for the given PCM format
+func newPipeOutput(config OutputConfig, sampleRate, channels int) (*pipeOutput, error) {
+	name, args, err := outputCommand(config, sampleRate, channels)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &pipeOutput{config: config, sampleRate: sampleRate, channels: channels}
+	o.cmd = exec.Command(name, args...)
+	o.cmd.Stderr = &o.stderr
+	stdin, err := o.cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s output: %w", config.Backend, err)
+	}
+	o.stdin = stdin
+	if err := o.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", name, err)
+	}
+
+	// A small pipe keeps what is queued, and so pause and seek latency, low
+	if file, ok := stdin.(*os.File); ok {
+		shrinkPipe(file)
+	}
+	return o, nil
+}
+
+// write sends PCM to the output program
+func (o *pipeOutput) write(data []byte) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if _, err := o.stdin.Write(data); err != nil {
+		if message := strings.TrimSpace(o.stderr.String()); message != "" {
+			return fmt.Errorf("%s output failed: %s", o.config.Backend, message)
+		}
+		return fmt.Errorf("%s output failed: %w", o.config.Backend, err)
+	}
+	return nil
+}
+
+// Matches reports whether the output is running with the given settings and format
+func (o *pipeOutput) Matches(config OutputConfig, sampleRate, channels int) bool {
+	return o.config == config && o.sampleRate == sampleRate && o.channels == channels
+}
+
+// Close stops the output program
+func (o *pipeOutput) Close() error {
+	o.stdin.Close()
+	if o.cmd.Process != nil {
+		o.cmd.Process.Kill()
+		o.cmd.Wait()
+	}
+	return nil
+}
+
+// pipeStream copies one track into a pipe output while it is playing
+type pipeStream struct {
+	output  *pipeOutput
+	reader  io.Reader
+	mutex   sync.Mutex
+	wake    *sync.Cond
+	playing bool
+	closed  bool
+	started bool
+	err     error
+}
+
+// newPipeStream creates a paused stream of reader on output
+func newPipeStream(output *pipeOutput, reader io.Reader) *pipeStream {
+	s := &pipeStream{output: output, reader: reader}
+	s.wake = sync.NewCond(&s.mutex)
+	return s
+}
+
+func (s *pipeStream) Play() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.playing = true
+	if !s.started {
+		s.started = true
+		go s.copyLoop()
+	}
+	s.wake.Broadcast()
+}
+
+func (s *pipeStream) Pause() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.playing = false
+}
+
+func (s *pipeStream) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	s.wake.Broadcast()
+	return nil
+}
+
+func (s *pipeStream) Err() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.err
+}
+
+// failedStream stands in for a track whose output couldn't start, so the
+// failure is reported the way a device error during playback is
+type failedStream struct {
+	err error
+}
+
+func (s *failedStream) Play()        {}
+func (s *failedStream) Pause()       {}
+func (s *failedStream) Close() error { return nil }
+func (s *failedStream) Err() error   { return s.err }
+
+// copyLoop feeds the track to the output in small chunks, waiting while
+// paused, until it ends or the stream is closed
+func (s *pipeStream) copyLoop() {
+	buffer := make([]byte, 4096)
+	for {
+		s.mutex.Lock()
+		for !s.playing && !s.closed {
+			s.wake.Wait()
+		}
+		closed := s.closed
+		s.mutex.Unlock()
+		if closed {
+			return
+		}
+
+		n, readErr := s.reader.Read(buffer)
+		if n > 0 {
+			if err := s.output.write(buffer[:n]); err != nil {
+				s.mutex.Lock()
+				s.err = err
+				s.mutex.Unlock()
+				return
+			}
+		}
+		if readErr != nil {
+			return
+		}
+	}
+}
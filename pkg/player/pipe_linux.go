@@ -0,0 +1,15 @@
+package player
+
+import (
+	"os"
+	"syscall"
+)
+
+// fSetPipeSize is F_SETPIPE_SZ, which the syscall package doesn't name
+const fSetPipeSize = 1031
+
+// shrinkPipe makes a pipe hold one page instead of the default 64 KiB, which
+// is over a third of a second of CD audio
+func shrinkPipe(file *os.File) {
+	syscall.Syscall(syscall.SYS_FCNTL, file.Fd(), fSetPipeSize, 4096)
+}
@@ -0,0 +1,211 @@
+package player
+
+import (
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+// Pitch shifting works in two steps: stretch the audio in time by the pitch
+// ratio without changing its pitch (WSOLA: overlap-add of windows taken from
+// wherever lines up best with the previous one), then resample it back to the
+// original length, which moves the pitch by the ratio.
+
+const (
+	pitchWindow = 40 * 44100 / 1000 // window length in frames at 44.1 kHz
+	pitchSearch = pitchWindow / 8   // how far a window may move to line up
+)
+
+// pitchShift returns samples shifted by a number of semitones, keeping the
+// tempo and length unchanged
+func pitchShift(samples [][2]float64, sampleRate int, semitones int) [][2]float64 {
+	if semitones == 0 || len(samples) == 0 {
+		return samples
+	}
+
+	ratio := math.Pow(2, float64(semitones)/12)
+	scale := float64(sampleRate) / 44100
+	window := int(pitchWindow * scale)
+	search := int(pitchSearch * scale)
+
+	stretched := timeStretch(samples, ratio, window, search)
+	return resample(stretched, ratio, len(samples))
+}
+
+// timeStretch makes samples ratio times longer without changing the pitch
+func timeStretch(samples [][2]float64, ratio float64, window, search int) [][2]float64 {
+	synthesisHop := window / 2
+	analysisHop := float64(synthesisHop) / ratio
+
+	hann := make([]float64, window)
+	for i := range hann {
+		hann[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(window))
+	}
+
+	outLength := int(float64(len(samples)) * ratio)
+	out := make([][2]float64, outLength+window)
+
+	previous := 0 // where the last window was taken from
+	for k := 0; k*synthesisHop < outLength; k++ {
+		nominal := int(float64(k) * analysisHop)
+		start := nominal
+		if k > 0 {
+			// Continue the waveform the previous window would have gone on to
+			start = bestAlignment(samples, previous+synthesisHop, nominal, search, synthesisHop)
+		}
+
+		offset := k * synthesisHop
+		for i := 0; i < window && start+i < len(samples); i++ {
+			if start+i < 0 {
+				continue
+			}
+			out[offset+i][0] += samples[start+i][0] * hann[i]
+			out[offset+i][1] += samples[start+i][1] * hann[i]
+		}
+		previous = start
+	}
+
+	return out[:outLength]
+}
+
+// bestAlignment finds the start within search of nominal whose samples best
+// match those at target, comparing a length of mono samples
+func bestAlignment(samples [][2]float64, target, nominal, search, length int) int {
+	best, bestScore := nominal, math.Inf(-1)
+	for candidate := nominal - search; candidate <= nominal+search; candidate += 2 {
+		if candidate < 0 || candidate+length > len(samples) || target+length > len(samples) {
+			continue
+		}
+
+		score := 0.0
+		for i := 0; i < length; i += 4 {
+			a := samples[target+i][0] + samples[target+i][1]
+			b := samples[candidate+i][0] + samples[candidate+i][1]
+			score += a * b
+		}
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	return best
+}
+
+// resample plays samples ratio times faster by linear interpolation, giving
+// length frames
+func resample(samples [][2]float64, ratio float64, length int) [][2]float64 {
+	out := make([][2]float64, length)
+	for i := range out {
+		position := float64(i) * ratio
+		index := int(position)
+		if index+1 >= len(samples) {
+			if index < len(samples) {
+				out[i] = samples[index]
+			}
+			continue
+		}
+		fraction := position - float64(index)
+		out[i][0] = samples[index][0]*(1-fraction) + samples[index+1][0]*fraction
+		out[i][1] = samples[index][1]*(1-fraction) + samples[index+1][1]*fraction
+	}
+	return out
+}
+
+// pitchReader shifts 16-bit PCM by a number of semitones as it is read, so
+// the key can change while a song plays. It is rougher than pitchShift, which
+// lines its windows up with the waveform, so the player only uses it for the
+// change since the song was loaded.
+type pitchReader struct {
+	reader    io.Reader
+	semitones *atomic.Int32
+	channels  int
+	offset    int64 // byte offset of the next read, to keep samples aligned
+	shifter   *liveShifter
+	window    int // shifter delay line length in frames
+}
+
+func newPitchReader(reader io.Reader, semitones *atomic.Int32, sampleRate, channels int, offset int64) *pitchReader {
+	return &pitchReader{
+		reader:    reader,
+		semitones: semitones,
+		channels:  channels,
+		offset:    offset,
+		window:    pitchWindow * sampleRate / 44100,
+	}
+}
+
+func (r *pitchReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+
+	semitones := int(r.semitones.Load())
+	if semitones == 0 {
+		// Start afresh when the shift comes back
+		r.shifter = nil
+	} else {
+		if r.shifter == nil {
+			r.shifter = newLiveShifter(r.window, r.channels)
+		}
+		ratio := math.Pow(2, float64(semitones)/12)
+		start := int(r.offset % 2)
+		for i := start; i+1 < n; i += 2 {
+			channel := int((r.offset+int64(i))/2) % r.channels
+			value := float64(int16(uint16(p[i])|uint16(p[i+1])<<8)) / 32768
+			value = r.shifter.next(channel, value, ratio)
+			sample := uint16(sampleToInt16(value))
+			p[i] = byte(sample)
+			p[i+1] = byte(sample >> 8)
+		}
+	}
+	r.offset += int64(n)
+	return n, err
+}
+
+// liveShifter shifts the pitch one sample at a time: each channel is written
+// into a delay line and read back from two points that drift through it at
+// the pitch ratio, half a line apart. Each point fades out as it wraps
+// round, while the other is at full level, so the jumps aren't heard.
+type liveShifter struct {
+	lines [][]float64
+	write int
+	delay float64 // how far behind the write position the first point reads
+}
+
+func newLiveShifter(window, channels int) *liveShifter {
+	s := &liveShifter{lines: make([][]float64, channels)}
+	for i := range s.lines {
+		s.lines[i] = make([]float64, max(window, 2))
+	}
+	return s
+}
+
+// next takes the next sample of channel and returns the shifted one. The
+// line moves on after the last channel of each frame.
+func (s *liveShifter) next(channel int, value, ratio float64) float64 {
+	line := s.lines[channel]
+	length := float64(len(line))
+	line[s.write] = value
+
+	out := 0.0
+	for _, delay := range []float64{s.delay, math.Mod(s.delay+length/2, length)} {
+		// A Hann window over the delay, so the two points sum to full level
+		weight := 0.5 - 0.5*math.Cos(2*math.Pi*delay/length)
+		out += weight * s.tap(line, delay)
+	}
+
+	if channel == len(s.lines)-1 {
+		s.write = (s.write + 1) % len(line)
+		s.delay = math.Mod(s.delay+1-ratio+length, length)
+	}
+	return out
+}
+
+// tap reads line delay samples behind the write position, between samples
+// by linear interpolation
+func (s *liveShifter) tap(line []float64, delay float64) float64 {
+	position := float64(s.write) - delay
+	if position < 0 {
+		position += float64(len(line))
+	}
+	index := int(position) % len(line)
+	fraction := position - math.Floor(position)
+	return line[index]*(1-fraction) + line[(index+1)%len(line)]*fraction
+}
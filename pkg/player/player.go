@@ -3,40 +3,65 @@ package player
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ebitengine/oto/v3"
 	"github.com/faiface/beep/mp3"
 	"github.com/faiface/beep/wav"
 	"github.com/faiface/beep"
+	"github.com/tuneminal/tuneminal/pkg/utils"
 )
 
 // AudioPlayer handles audio playback using stable Oto library
 type AudioPlayer struct {
 	otoContext   *oto.Context
-	player       *oto.Player
+	player       stream
 	mutex        sync.RWMutex
 	isLoaded     bool
 	isPlaying    bool
 	isPaused     bool
 	currentFile  string
-	audioData    []byte
+	source       songSource // PCM of the loaded song
 	sampleRate   int
 	channels     int
 	duration     time.Duration
 	position     time.Duration
+	songStart    int64 // where the current song began in the stream's music, in bytes
+	trackGen     int   // invalidates stale position trackers
 	playbackDone chan struct{}
-	volume       float64 // Volume level from 0.0 to 1.0
-}
-
-// LyricEntry represents a single lyric entry with timing
-type LyricEntry struct {
-	Time time.Duration
-	Text string
+	volume       float64 // Volume level from 0.0 to 1.0, applied during playback
+	trackGain    float64 // Loudness normalization for the next file, applied when it is loaded
+	pitch        int     // Pitch shift in semitones, applied when a file is loaded
+	loadedPitch  int     // Pitch shift the loaded song was decoded with
+	decodeCache  DecodeCache // decoded songs kept between plays, nil for none
+	monitorConfig *MonitorConfig // secondary output settings, nil when disabled
+	monitor       *MonitorOutput
+	micConfig     *MicConfig // microphone pass-through, nil when off
+	mic           *micInput
+	micEffects    atomic.Pointer[MicEffects]
+	outputConfig  *OutputConfig // PipeWire or JACK instead of Oto, nil for the default
+	output        *pipeOutput
+	outputRate    int // sample rate songs are converted to, 0 to keep their own
+	metronome     atomic.Pointer[MetronomeConfig] // click track, nil when off
+	equalizer     atomic.Pointer[EQGains]         // band gains, nil when flat
+	liveGain      atomic.Pointer[float64]         // extra gain applied during playback, nil for none
+	liveVolume    atomic.Pointer[float64]         // the volume, applied during playback; nil at full
+	livePitch     atomic.Int32                    // semitones shifted during playback, on top of loadedPitch
+	outputErr     error                           // why the audio output last failed to open
+	failure       error                           // why playback last failed, until recovered
+	lastRead      atomic.Int64                    // when the output last pulled audio, to spot stalls
+	queue         songQueue                       // song to carry on into without a gap
+	advancedTo    string                          // song playback moved on to, until TakeAdvance
+	advanced      bool
+	mixer         *mixer // sums the song, clips and mic for the output
+	mixOut        stream // the output the mixer plays on, nil when closed
 }
 
 // NewAudioPlayer creates a new audio player using Oto
@@ -46,6 +71,8 @@ func NewAudioPlayer() *AudioPlayer {
 		sampleRate:   44100,
 		channels:     2,
 		volume:       1.0, // Default volume (100%)
+		trackGain:    1.0,
+		mixer:        newMixer(),
 	}
 }
 
@@ -65,6 +92,7 @@ func (p *AudioPlayer) initializeOto() error {
 
 	ctx, readyChan, err := oto.NewContext(op)
 	if err != nil {
+		p.outputErr = err
 		return fmt.Errorf("failed to create Oto context: %w", err)
 	}
 
@@ -75,6 +103,67 @@ func (p *AudioPlayer) initializeOto() error {
 	return nil
 }
 
+// openOutput readies the configured audio output (caller must hold the
+// mutex). PipeWire and JACK are started when a track plays, so here only
+// their programs are looked for.
+func (p *AudioPlayer) openOutput() error {
+	if p.outputConfig == nil {
+		return p.initializeOto()
+	}
+	if p.outputErr != nil {
+		return p.outputErr
+	}
+	name, _, err := outputCommand(*p.outputConfig, p.sampleRate, p.channels)
+	if err == nil {
+		_, err = exec.LookPath(name)
+	}
+	if err != nil {
+		p.outputErr = err
+		return fmt.Errorf("%s output unavailable: %w", p.outputConfig.Backend, err)
+	}
+	return nil
+}
+
+// OpenOutput opens the audio output at the default format if no song has
+// opened it yet, to check that sound works
+func (p *AudioPlayer) OpenOutput() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.openOutput()
+}
+
+// OutputStatus reports whether the audio output is open, and why it last
+// failed to open if it did
+func (p *AudioPlayer) OutputStatus() (bool, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	if p.outputConfig != nil {
+		return p.outputErr == nil, p.outputErr
+	}
+	return p.otoContext != nil, p.outputErr
+}
+
+// SetOutput sends playback to PipeWire or JACK; nil goes back to the
+// default output. The change applies from the next Play or seek.
+func (p *AudioPlayer) SetOutput(config *OutputConfig) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if config != nil && p.outputConfig != nil && *config == *p.outputConfig {
+		return
+	}
+	if p.output != nil {
+		p.output.Close()
+		p.output = nil
+	}
+	p.outputConfig = config
+	p.outputErr = nil
+	p.outputRate = 0
+	if config != nil && config.Backend == OutputJACK {
+		p.outputRate, p.outputErr = jackSampleRate()
+	}
+}
+
 // LoadFile loads an audio file using Oto for stable playback
 func (p *AudioPlayer) LoadFile(filename string) error {
 	p.mutex.Lock()
@@ -82,62 +171,153 @@ func (p *AudioPlayer) LoadFile(filename string) error {
 
 	// Stop any current playback
 	p.stopInternal()
+	p.unload()
 
 	// Check if file exists
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		return fmt.Errorf("audio file not found: %s", filename)
 	}
 
-	// Open the audio file
-	file, err := os.Open(filename)
+	// Skip decoding when the song was decoded before at this pitch
+	key := ""
+	if p.decodesAhead() {
+		key = p.decodeKeyForFile(filename)
+	}
+	if samples, sampleRate, channels, ok := p.cachedSamples(key); ok {
+		return p.loadSamples(filename, samples, sampleRate, channels)
+	}
+
+	// Open and decode the audio file
+	streamer, format, err := Decode(filename)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return err
 	}
-	defer file.Close()
+	return p.load(filename, key, streamer, format)
+}
 
-	// Determine file type and decode
-	ext := strings.ToLower(filepath.Ext(filename))
-	var streamer beep.StreamSeekCloser
-	var format beep.Format
+// LoadData loads a song already read into memory, such as a prefetched one.
+// The name is only used for its extension and as the current file.
+func (p *AudioPlayer) LoadData(name string, data []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 
-	switch ext {
-	case ".mp3":
-		streamer, format, err = mp3.Decode(file)
-		if err != nil {
-			return fmt.Errorf("failed to decode MP3: %w", err)
-		}
-	case ".wav":
-		streamer, format, err = wav.Decode(file)
-		if err != nil {
-			return fmt.Errorf("failed to decode WAV: %w", err)
-		}
-	default:
-		return fmt.Errorf("unsupported file format: %s", ext)
+	// Stop any current playback
+	p.stopInternal()
+	p.unload()
+
+	key := ""
+	if p.decodesAhead() {
+		key = p.decodeKey(bytes.NewReader(data))
+	}
+	if samples, sampleRate, channels, ok := p.cachedSamples(key); ok {
+		return p.loadSamples(name, samples, sampleRate, channels)
+	}
+
+	streamer, format, err := DecodeReader(name, memoryFile{bytes.NewReader(data)})
+	if err != nil {
+		return err
+	}
+	return p.load(name, key, streamer, format)
+}
+
+// silenceSampleRate is the format of the silence LoadSilence plays
+const silenceSampleRate = 44100
+
+// LoadSilence loads duration of silence as the song called name, so the
+// metronome can be played on its own, as for practising lyrics that have
+// no recording
+func (p *AudioPlayer) LoadSilence(name string, duration time.Duration) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.stopInternal()
+	p.unload()
+
+	p.sampleRate = silenceSampleRate
+	p.channels = 2
+	if p.outputRate > 0 {
+		p.sampleRate = p.outputRate
+	}
+	if err := p.openOutput(); err != nil {
+		return fmt.Errorf("failed to initialize audio: %w", err)
+	}
+
+	frames := int64(duration) * int64(p.sampleRate) / int64(time.Second)
+	p.duration = utils.FramesDuration(frames, p.sampleRate)
+	p.source = &silentSource{length: frames * int64(2*p.channels)}
+	p.setLoadedPitch(p.pitch)
+	p.isLoaded = true
+	p.currentFile = name
+	p.position = 0
+	return nil
+}
+
+// memoryFile lets an in-memory song be decoded like an open file
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
+
+// decodesAhead reports whether songs may have to be decoded in full before
+// they play, which is when the decode cache is worth looking in (caller
+// must hold the mutex)
+func (p *AudioPlayer) decodesAhead() bool {
+	return p.pitch != 0 || p.outputRate > 0
+}
+
+// load readies a decoded song to play. It is decoded while it plays unless
+// it has to be processed as a whole first, for a pitch shift or an output
+// that can't take its sample rate; then it is decoded up front, keeping the
+// samples in the decode cache under key when there is one (caller must hold
+// the mutex).
+func (p *AudioPlayer) load(filename, key string, streamer beep.StreamSeekCloser, format beep.Format) error {
+	sampleRate := int(format.SampleRate)
+	if p.pitch == 0 && (p.outputRate == 0 || p.outputRate == sampleRate) {
+		return p.loadStream(filename, streamer, sampleRate, format.NumChannels)
 	}
 	defer streamer.Close()
 
+	samples := p.decodeSamples(streamer, int(format.SampleRate))
+	p.storeSamples(key, samples, int(format.SampleRate), format.NumChannels)
+	return p.loadSamples(filename, samples, int(format.SampleRate), format.NumChannels)
+}
+
+// loadSamples makes decoded samples the song ready to play (caller must hold
+// the mutex)
+func (p *AudioPlayer) loadSamples(filename string, samples [][2]float64, sampleRate, channels int) error {
+	// Outputs that can't resample get the song at their own rate
+	if p.outputRate > 0 && sampleRate != p.outputRate {
+		ratio := float64(sampleRate) / float64(p.outputRate)
+		samples = resample(samples, ratio, int(float64(len(samples))/ratio))
+		sampleRate = p.outputRate
+	}
+
 	// Set audio parameters from the decoded format
-	p.sampleRate = int(format.SampleRate)
-	p.channels = format.NumChannels
+	p.sampleRate = sampleRate
+	p.channels = channels
 
-	// Initialize Oto with the correct format
-	if err := p.initializeOto(); err != nil {
+	// Initialize the output with the correct format
+	if err := p.openOutput(); err != nil {
 		return fmt.Errorf("failed to initialize audio: %w", err)
 	}
 
 	// Convert beep samples to raw PCM data
-	audioData, err := p.convertToRawPCM(streamer, format)
+	audioData, err := p.convertToRawPCM(samples)
 	if err != nil {
 		return fmt.Errorf("failed to convert audio data: %w", err)
 	}
 
-	// Calculate duration
-	samplesPerSecond := p.sampleRate * p.channels
-	totalSamples := len(audioData) / 2 // 16-bit samples = 2 bytes each
-	p.duration = time.Duration(totalSamples/samplesPerSecond) * time.Second
+	// Calculate duration from the frame count, keeping sub-second precision
+	frameSize := 2 * p.channels // 16-bit samples = 2 bytes each
+	totalFrames := len(audioData) / frameSize
+	p.duration = utils.FramesDuration(int64(totalFrames), p.sampleRate)
 
 	// Store audio data
-	p.audioData = audioData
+	p.source = &memorySource{data: audioData}
+	p.setLoadedPitch(p.pitch)
 	p.isLoaded = true
 	p.currentFile = filename
 	p.position = 0
@@ -145,12 +325,88 @@ func (p *AudioPlayer) LoadFile(filename string) error {
 	return nil
 }
 
-// convertToRawPCM converts beep streamer to raw PCM data for Oto
-func (p *AudioPlayer) convertToRawPCM(streamer beep.StreamSeekCloser, format beep.Format) ([]byte, error) {
-	// Create a buffer to hold all samples
+// loadStream makes a song that is decoded as it plays the one ready to
+// play; the source closes streamer when the song is unloaded (caller must
+// hold the mutex)
+func (p *AudioPlayer) loadStream(filename string, streamer beep.StreamSeekCloser, sampleRate, channels int) error {
+	p.sampleRate = sampleRate
+	p.channels = channels
+
+	if err := p.openOutput(); err != nil {
+		streamer.Close()
+		return fmt.Errorf("failed to initialize audio: %w", err)
+	}
+
+	p.duration = utils.FramesDuration(int64(streamer.Len()), p.sampleRate)
+	p.source = newDecodingSource(streamer, channels, p.trackGain)
+	p.setLoadedPitch(p.pitch)
+	p.isLoaded = true
+	p.currentFile = filename
+	p.position = 0
+
+	return nil
+}
+
+// unload closes the loaded song and drops the one queued after it (caller
+// must hold the mutex)
+func (p *AudioPlayer) unload() {
+	p.queue.reset()
+	p.advancedTo, p.advanced = "", false
+	if p.source != nil {
+		p.source.close()
+		p.source = nil
+	}
+	p.isLoaded = false
+}
+
+// Decode opens and decodes an audio file. Closing the returned streamer
+// closes the file.
+func Decode(filename string) (beep.StreamSeekCloser, beep.Format, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, beep.Format{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	return DecodeReader(filename, file)
+}
+
+// DecodeReader decodes an open audio file, picking the decoder from the
+// extension of name. Closing the returned streamer closes file.
+func DecodeReader(name string, file io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	var err error
+	var streamer beep.StreamSeekCloser
+	var format beep.Format
+
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".mp3":
+		streamer, format, err = mp3.Decode(file)
+		if err != nil {
+			err = fmt.Errorf("failed to decode MP3: %w", err)
+		}
+	case ".wav":
+		streamer, format, err = wav.Decode(file)
+		if err != nil {
+			err = fmt.Errorf("failed to decode WAV: %w", err)
+		}
+	default:
+		err = fmt.Errorf("unsupported file format: %s", ext)
+	}
+	if err != nil {
+		file.Close()
+		return nil, beep.Format{}, err
+	}
+
+	return streamer, format, nil
+}
+
+// decodeSamples reads every sample from streamer, shifted to the current pitch
+func (p *AudioPlayer) decodeSamples(streamer beep.StreamSeekCloser, sampleRate int) [][2]float64 {
+	// Shift the pitch before converting
+	return pitchShift(streamAll(streamer), sampleRate, p.pitch)
+}
+
+// streamAll reads every sample from streamer
+func streamAll(streamer beep.Streamer) [][2]float64 {
 	var samples [][2]float64
-	
-	// Read all samples from the streamer
 	for {
 		sampleBuffer := make([][2]float64, 512)
 		n, ok := streamer.Stream(sampleBuffer)
@@ -159,52 +415,35 @@ func (p *AudioPlayer) convertToRawPCM(streamer beep.StreamSeekCloser, format bee
 		}
 		samples = append(samples, sampleBuffer[:n]...)
 	}
+	return samples
+}
 
-	// Convert float64 samples to 16-bit PCM with volume scaling
-	pcmData := make([]byte, len(samples)*2*p.channels)
-	for i, sample := range samples {
-		// Apply volume scaling
-		left := sample[0] * p.volume
-		right := sample[1] * p.volume
-
-		// Clamp values to prevent distortion
-		if left > 1.0 {
-			left = 1.0
-		} else if left < -1.0 {
-			left = -1.0
-		}
-		if right > 1.0 {
-			right = 1.0
-		} else if right < -1.0 {
-			right = -1.0
-		}
-
-		// Convert left channel
-		leftInt := int16(left * 32767)
-		pcmData[i*4] = byte(leftInt)
-		pcmData[i*4+1] = byte(leftInt >> 8)
-
-		// Convert right channel (or duplicate left if mono)
-		rightInt := int16(right * 32767)
-		if p.channels > 1 {
-			pcmData[i*4+2] = byte(rightInt)
-			pcmData[i*4+3] = byte(rightInt >> 8)
-		}
+// convertToRawPCM converts decoded samples to raw PCM data for Oto, with
+// the track gain applied
+func (p *AudioPlayer) convertToRawPCM(samples [][2]float64) ([]byte, error) {
+	pcmData := make([]byte, 0, len(samples)*2*p.channels)
+	for _, sample := range samples {
+		pcmData = appendFrame(pcmData, sample, p.trackGain, p.channels)
 	}
-
 	return pcmData, nil
 }
 
 // Play starts audio playback using Oto with optimized responsiveness
 func (p *AudioPlayer) Play() error {
+	return p.PlayFrom(0)
+}
+
+// PlayFrom starts playback of the loaded file at position, so none of what
+// comes before it is heard
+func (p *AudioPlayer) PlayFrom(position time.Duration) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	if !p.isLoaded || len(p.audioData) == 0 {
+	if !p.isLoaded || p.source == nil {
 		return fmt.Errorf("no audio file loaded")
 	}
 
-	if p.otoContext == nil {
+	if p.outputConfig == nil && p.otoContext == nil {
 		return fmt.Errorf("audio context not initialized")
 	}
 
@@ -212,42 +451,72 @@ func (p *AudioPlayer) Play() error {
 	p.stopInternal()
 
 	// Create a new player with the raw PCM data
-	p.player = p.otoContext.NewPlayer(bytes.NewReader(p.audioData))
+	position, offset := p.seekOffset(position)
+	p.player = p.newStream(p.source.reader(offset), offset)
 	
 	// Start playback immediately
 	p.player.Play()
 	p.isPlaying = true
 	p.isPaused = false
-	p.position = 0
+	p.position = position
+	p.songStart = 0
 
 	// Create new done channel
 	p.playbackDone = make(chan struct{})
 
 	// Start position tracking in background (don't wait)
-	go p.trackPosition()
+	p.trackGen++
+	go p.trackPosition(p.trackGen)
 
 	return nil
 }
 
-// trackPosition tracks the playback position
-func (p *AudioPlayer) trackPosition() {
+// seekOffset clamps position to the song and returns it with the
+// frame-aligned byte offset it starts at (caller must hold the mutex)
+func (p *AudioPlayer) seekOffset(position time.Duration) (time.Duration, int64) {
+	if position < 0 {
+		position = 0
+	} else if position > p.duration {
+		position = p.duration
+	}
+	sampleSize := 2 * p.channels // 16-bit samples
+	targetFrames := int64(position) * int64(p.sampleRate) / int64(time.Second)
+	return position, targetFrames * int64(sampleSize)
+}
+
+// trackPosition tracks the playback position until playback stops or a
+// newer tracker (gen) takes over
+func (p *AudioPlayer) trackPosition(gen int) {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
-	startTime := time.Now()
-
 	for {
 		select {
 		case <-ticker.C:
 			p.mutex.Lock()
-			if !p.isPlaying || p.isPaused {
+			if !p.isPlaying || p.isPaused || gen != p.trackGen {
 				p.mutex.Unlock()
 				return
 			}
 
-			// Update position based on elapsed time
-			elapsed := time.Since(startTime)
-			p.position = elapsed
+			// Update position from the audio the output has played
+			p.position = p.playedPosition()
+
+			// Stop at a device error or stall, so the app can recover
+			if err := p.checkOutput(); err != nil {
+				p.fail(err)
+				p.mutex.Unlock()
+				return
+			}
+
+			// Carry on into the queued song once the output has reached it
+			if p.position >= p.duration {
+				if next := p.queue.takeStarted(); next != nil {
+					p.advance(next)
+					p.mutex.Unlock()
+					continue
+				}
+			}
 
 			// Check if playback is finished
 			if p.position >= p.duration {
@@ -272,6 +541,7 @@ func (p *AudioPlayer) Pause() {
 	defer p.mutex.Unlock()
 
 	if p.isPlaying && p.player != nil {
+		p.position = p.playedPosition()
 		p.player.Pause()
 		p.isPaused = true
 		p.isPlaying = false
@@ -287,7 +557,10 @@ func (p *AudioPlayer) Resume() {
 		p.player.Play()
 		p.isPaused = false
 		p.isPlaying = true
-		// Note: Position tracking should be handled by the calling application
+		p.lastRead.Store(time.Now().UnixNano())
+
+		p.trackGen++
+		go p.trackPosition(p.trackGen)
 	}
 }
 
@@ -301,6 +574,7 @@ func (p *AudioPlayer) stopInternal() {
 	p.isPlaying = false
 	p.isPaused = false
 	p.position = 0
+	p.failure = nil
 }
 
 // Stop stops audio playback
@@ -321,6 +595,11 @@ func (p *AudioPlayer) IsPlaying() bool {
 func (p *AudioPlayer) GetPosition() time.Duration {
 	p.mutex.RLock()
 	defer p.mutex.RUnlock()
+
+	// Between tracker ticks, work it out from the output for tap timing
+	if p.isPlaying && !p.isPaused {
+		return min(p.playedPosition(), p.duration)
+	}
 	return p.position
 }
 
@@ -338,7 +617,8 @@ func (p *AudioPlayer) WaitForCompletion() {
 	}
 }
 
-// SetVolume sets the audio volume (0.0 to 1.0)
+// SetVolume sets the audio volume (0.0 to 1.0). It is applied as the audio
+// is streamed, so a change is heard within a buffer, song playing or not.
 func (p *AudioPlayer) SetVolume(volume float64) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
@@ -351,6 +631,66 @@ func (p *AudioPlayer) SetVolume(volume float64) {
 	}
 
 	p.volume = volume
+	if volume == 1 {
+		p.liveVolume.Store(nil)
+	} else {
+		p.liveVolume.Store(&volume)
+	}
+}
+
+// SetPitch sets the pitch shift in semitones (-12 to 12). It is applied
+// when the next file is loaded; see SetTranspose to shift the playing one.
+func (p *AudioPlayer) SetPitch(semitones int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if semitones < -12 {
+		semitones = -12
+	} else if semitones > 12 {
+		semitones = 12
+	}
+
+	p.pitch = semitones
+}
+
+// SetTranspose shifts the pitch by semitones (-12 to 12) straight away,
+// keeping the tempo: the playing song is shifted as it plays, and songs
+// loaded after it are shifted when they are decoded, as with SetPitch.
+func (p *AudioPlayer) SetTranspose(semitones int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	semitones = min(max(semitones, -12), 12)
+	p.pitch = semitones
+	if p.isLoaded {
+		p.livePitch.Store(int32(semitones - p.loadedPitch))
+	}
+}
+
+// setLoadedPitch records the pitch shift of the song just loaded, which
+// needs none live (caller must hold the mutex)
+func (p *AudioPlayer) setLoadedPitch(semitones int) {
+	p.loadedPitch = semitones
+	p.livePitch.Store(0)
+}
+
+// SetTrackGain sets the loudness normalization factor for the next file
+// loaded, 1 for none. It is applied when the file is loaded.
+func (p *AudioPlayer) SetTrackGain(gain float64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if gain <= 0 {
+		gain = 1
+	}
+	p.trackGain = gain
+}
+
+// GetPitch returns the pitch shift in semitones
+func (p *AudioPlayer) GetPitch() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.pitch
 }
 
 // GetVolume returns the current volume level (0.0 to 1.0)
@@ -369,51 +709,255 @@ func (p *AudioPlayer) SeekTo(position time.Duration) error {
 		return fmt.Errorf("no audio file loaded or player not available")
 	}
 
-	if position < 0 {
-		position = 0
-	} else if position > p.duration {
-		position = p.duration
-	}
-
-	// Calculate the byte position based on the duration
-	samplesPerSecond := p.sampleRate * p.channels
-	targetSamples := int(position.Seconds()) * samplesPerSecond
+	position, bytesToSkip := p.seekOffset(position)
 
 	// For Oto v3, seeking requires restarting the player
 	// Store the current playback state
 	wasPlaying := p.isPlaying
+	wasPaused := p.isPaused
 
 	// Stop current playback
 	p.stopInternal()
 
-	// Recreate the player with the original audio data
-	p.player = p.otoContext.NewPlayer(bytes.NewReader(p.audioData))
-
-	// Skip to the target position by consuming samples
-	sampleSize := 2 * p.channels // 16-bit samples
-	bytesToSkip := targetSamples * sampleSize
-
-	// Create a limited reader that skips the initial bytes
-	audioReader := bytes.NewReader(p.audioData)
-	audioReader.Seek(int64(bytesToSkip), 0)
-
 	// Create a new player starting from the seek position
-	p.player = p.otoContext.NewPlayer(audioReader)
+	p.player = p.newStream(p.source.reader(bytesToSkip), bytesToSkip)
 	p.position = position
+	p.songStart = 0
 
 	// Restore playback state
 	if wasPlaying {
 		p.isPlaying = true
 		p.isPaused = false
 		p.player.Play()
+
+		p.trackGen++
+		go p.trackPosition(p.trackGen)
+	} else if wasPaused {
+		p.isPaused = true
 	}
 
 	return nil
 }
 
+// GetAudioSamples returns a window of mono samples (-1.0 to 1.0) at the
+// current playback position, for visualization
+func (p *AudioPlayer) GetAudioSamples() []float64 {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	samples := make([]float64, 1024)
+	if p.source == nil || p.channels == 0 {
+		return samples
+	}
+
+	frameSize := 2 * p.channels
+	start := int64(p.position.Seconds()*float64(p.sampleRate)) * int64(frameSize)
+	window := p.source.window(start, len(samples)*frameSize)
+	for i := range samples {
+		offset := i * frameSize
+		if offset+1 >= len(window) {
+			break
+		}
+		value := int16(uint16(window[offset]) | uint16(window[offset+1])<<8)
+		samples[i] = float64(value) / 32768.0
+	}
+
+	return samples
+}
+
+// SetMonitor configures the secondary monitor output; nil disables it.
+// The change applies from the next Play or seek.
+func (p *AudioPlayer) SetMonitor(config *MonitorConfig) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.monitorConfig = config
+	if config == nil && p.monitor != nil {
+		p.monitor.Close()
+		p.monitor = nil
+	}
+}
+
+// SetMic passes a microphone through to the main output; nil turns it off.
+// The change applies from the next Play or seek.
+func (p *AudioPlayer) SetMic(config *MicConfig) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.micConfig = config
+	if config == nil && p.mic != nil {
+		p.mic.Close()
+		p.mic = nil
+	}
+}
+
+// SetMicEffects sets the effects on the microphone, whether passed through
+// or mixed into the monitor; nil leaves it dry. The change is heard
+// immediately.
+func (p *AudioPlayer) SetMicEffects(effects *MicEffects) {
+	p.micEffects.Store(effects)
+}
+
+// MicLevel returns the level of the microphone, passed through or in the
+// monitor mix, as a fraction of full scale; false when none is capturing
+func (p *AudioPlayer) MicLevel() (float64, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	if p.mic != nil {
+		return p.mic.Level(), true
+	}
+	if p.monitor != nil {
+		return p.monitor.MicLevel()
+	}
+	return 0, false
+}
+
+// MicEffects returns the current microphone effects, nil when dry
+func (p *AudioPlayer) MicEffects() *MicEffects {
+	return p.micEffects.Load()
+}
+
+// SetMetronome mixes a click track into playback; nil turns it off.
+// The change is heard immediately.
+func (p *AudioPlayer) SetMetronome(config *MetronomeConfig) {
+	p.metronome.Store(config)
+}
+
+// SetLiveGain scales playback by gain (0.0 to 1.0) from now on, for fades
+// and ramps; 1 removes it
+func (p *AudioPlayer) SetLiveGain(gain float64) {
+	if gain >= 1 {
+		p.liveGain.Store(nil)
+		return
+	}
+	if gain < 0 {
+		gain = 0
+	}
+	p.liveGain.Store(&gain)
+}
+
+// Metronome returns the current click track settings, nil when off
+func (p *AudioPlayer) Metronome() *MetronomeConfig {
+	return p.metronome.Load()
+}
+
+// newStream creates a paused stream of reader through the mixer, which
+// starts offset bytes into the track and carries on into the queued song.
+// The live pitch shift, equalizer, live gain, volume and metronome are applied to it, and it gets a fresh output
+// so settings changed since the last one apply (caller must hold the mutex)
+func (p *AudioPlayer) newStream(reader io.Reader, offset int64) stream {
+	// A song the replaced stream had moved on to starts again from the top
+	p.queue.unstart()
+	chain := &chainReader{reader: reader, queue: &p.queue, sampleRate: p.sampleRate, channels: p.channels}
+	reader = newPitchReader(chain, &p.livePitch, p.sampleRate, p.channels, offset)
+	reader = newEQReader(reader, &p.equalizer, p.sampleRate, p.channels, offset)
+	reader = &gainReader{reader: reader, gain: &p.liveGain, offset: offset}
+	reader = &gainReader{reader: reader, gain: &p.liveVolume, offset: offset}
+	metronome := &metronomeReader{
+		reader:     reader,
+		config:     &p.metronome,
+		sampleRate: p.sampleRate,
+		channels:   p.channels,
+		offset:     offset,
+	}
+	// The clicks of the next song count from its own start, and it was
+	// decoded at its own pitch
+	music := &musicStream{player: p, reader: metronome, start: offset, read: offset}
+	chain.onSwitch = func() {
+		metronome.offset = 0
+		p.livePitch.Store(0)
+		// Each stage passes on what it reads, so the mixer has read all of
+		// the last song and none of the next
+		music.switchedAt = music.read
+	}
+
+	p.closeMixOutput()
+	p.mixer.mutex.Lock()
+	p.mixer.music = music
+	p.mixer.mutex.Unlock()
+	if out := p.openMixOutput(); !p.mixer.idle() {
+		// Clips playing over the last stream carry on
+		out.Play()
+	}
+	return music
+}
+
+// openMonitor starts or restarts the monitor output for the track's format,
+// returning nil when there is none or it can't be opened (caller must hold
+// the mutex)
+func (p *AudioPlayer) openMonitor() *MonitorOutput {
+	if p.monitorConfig == nil {
+		return nil
+	}
+	if p.monitor != nil && !p.monitor.Matches(*p.monitorConfig, p.sampleRate, p.channels) {
+		p.monitor.Close()
+		p.monitor = nil
+	}
+	if p.monitor == nil {
+		monitor, err := NewMonitorOutput(*p.monitorConfig, p.sampleRate, p.channels, &p.micEffects)
+		if err != nil {
+			// The main output keeps working without the monitor
+			return nil
+		}
+		p.monitor = monitor
+	}
+	return p.monitor
+}
+
+// openMic starts or restarts the pass-through microphone for the track's
+// format, returning nil when it is off or can't be opened (caller must hold
+// the mutex)
+func (p *AudioPlayer) openMic() *micInput {
+	if p.micConfig == nil {
+		return nil
+	}
+	if p.mic != nil && !p.mic.Matches(p.micConfig.Device, p.sampleRate, p.channels) {
+		p.mic.Close()
+		p.mic = nil
+	}
+	if p.mic == nil {
+		mic, err := newMicInput(p.micConfig.Device, p.sampleRate, p.channels, &p.micEffects)
+		if err != nil {
+			// The music keeps playing without the mic
+			return nil
+		}
+		p.mic = mic
+	}
+	return p.mic
+}
+
+// outputStream plays reader on PipeWire or JACK when one is configured, or
+// else through Oto (caller must hold the mutex)
+func (p *AudioPlayer) outputStream(reader io.Reader) stream {
+	if p.outputConfig == nil {
+		return p.otoContext.NewPlayer(reader)
+	}
+
+	// Restart the output program when its settings or the track format changed
+	if p.output != nil && !p.output.Matches(*p.outputConfig, p.sampleRate, p.channels) {
+		p.output.Close()
+		p.output = nil
+	}
+	if p.output == nil {
+		output, err := newPipeOutput(*p.outputConfig, p.sampleRate, p.channels)
+		if err != nil {
+			return &failedStream{err: err}
+		}
+		p.output = output
+	}
+	return newPipeStream(p.output, reader)
+}
+
 // Close cleans up the audio player
 func (p *AudioPlayer) Close() error {
 	p.Stop()
+	p.mutex.Lock()
+	p.unload()
+	p.closeMixOutput()
+	p.mutex.Unlock()
+	p.SetMonitor(nil)
+	p.SetMic(nil)
+	p.SetOutput(nil)
 	// Oto v3 context doesn't need explicit closing
 	return nil
 }
\ No newline at end of file
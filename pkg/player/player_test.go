@@ -1,6 +1,12 @@
 package player
 
 import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -12,7 +18,7 @@ func TestNewAudioPlayer(t *testing.T) {
 		t.Fatal("NewAudioPlayer() returned nil")
 	}
 	
-	if player.done == nil {
+	if player.playbackDone == nil {
 		t.Error("Audio player done channel is nil")
 	}
 }
@@ -38,19 +44,514 @@ func TestGetAudioSamples(t *testing.T) {
 	}
 }
 
-func TestLyricEntry(t *testing.T) {
-	lyric := LyricEntry{
-		Time: 30 * time.Second,
-		Text: "Test lyric",
+func TestMixPCM(t *testing.T) {
+	// Two samples: 1000 + 0.5*2000, and a sum that must clip
+	music := []byte{0xE8, 0x03, 0xFF, 0x7F}
+	voice := []byte{0xD0, 0x07, 0xFF, 0x7F}
+
+	mixPCM(music, voice, 0.5)
+
+	first := int16(uint16(music[0]) | uint16(music[1])<<8)
+	if first != 2000 {
+		t.Errorf("Expected mixed sample 2000, got %d", first)
 	}
-	
-	if lyric.Time != 30*time.Second {
-		t.Error("Lyric time not set correctly")
+	second := int16(uint16(music[2]) | uint16(music[3])<<8)
+	if second != 32767 {
+		t.Errorf("Expected clipped sample 32767, got %d", second)
 	}
-	
-	if lyric.Text != "Test lyric" {
-		t.Error("Lyric text not set correctly")
+}
+
+func TestMetronomeReader(t *testing.T) {
+	// Half a second of stereo silence at 8 kHz, read from half a second in
+	const sampleRate = 8000
+	silence := make([]byte, sampleRate*2)
+
+	config := &atomic.Pointer[MetronomeConfig]{}
+	config.Store(&MetronomeConfig{BPM: 120, Offset: 0, Volume: 1})
+	reader := &metronomeReader{
+		reader:     bytes.NewReader(silence),
+		config:     config,
+		sampleRate: sampleRate,
+		channels:   2,
+		offset:     sampleRate * 2,
+	}
+
+	buf := make([]byte, len(silence))
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// A beat falls on 0.5s, the start of buf; frame 1 of the click is audible
+	click := int16(uint16(buf[4]) | uint16(buf[5])<<8)
+	if click == 0 {
+		t.Error("Expected a click at the start of the beat")
+	}
+	offBeat := int16(uint16(buf[4000]) | uint16(buf[4001])<<8)
+	if offBeat != 0 {
+		t.Errorf("Expected silence between beats, got %d", offBeat)
+	}
+}
+
+func TestPitchShift(t *testing.T) {
+	// One second of 440 Hz, shifted up an octave
+	const sampleRate = 44100
+	samples := make([][2]float64, sampleRate)
+	for i := range samples {
+		value := 0.5 * math.Sin(2*math.Pi*440*float64(i)/sampleRate)
+		samples[i] = [2]float64{value, value}
+	}
+
+	shifted := pitchShift(samples, sampleRate, 12)
+	if len(shifted) != len(samples) {
+		t.Fatalf("Expected %d frames, got %d", len(samples), len(shifted))
+	}
+
+	// Count rising zero crossings over the middle half second
+	crossings := 0
+	for i := sampleRate/4 + 1; i < 3*sampleRate/4; i++ {
+		if shifted[i-1][0] < 0 && shifted[i][0] >= 0 {
+			crossings++
+		}
+	}
+	if crossings < 430 || crossings > 450 {
+		t.Errorf("Expected about 440 cycles in half a second (880 Hz), got %d", crossings)
+	}
+}
+
+func TestPitchReader(t *testing.T) {
+	// One second of 440 Hz as stereo PCM, shifted up an octave as it is read
+	const sampleRate = 44100
+	data := make([]byte, 0, sampleRate*4)
+	for i := 0; i < sampleRate; i++ {
+		value := 0.5 * math.Sin(2*math.Pi*440*float64(i)/sampleRate)
+		data = appendFrame(data, [2]float64{value, value}, 1, 2)
+	}
+
+	var semitones atomic.Int32
+	semitones.Store(12)
+	shifted, err := io.ReadAll(newPitchReader(bytes.NewReader(data), &semitones, sampleRate, 2, 0))
+	if err != nil || len(shifted) != len(data) {
+		t.Fatalf("Expected %d bytes, got %d (%v)", len(data), len(shifted), err)
+	}
+
+	// Count rising zero crossings of the left channel over the middle half second
+	left := func(frame int) int16 {
+		return int16(uint16(shifted[frame*4]) | uint16(shifted[frame*4+1])<<8)
+	}
+	crossings := 0
+	for i := sampleRate/4 + 1; i < 3*sampleRate/4; i++ {
+		if left(i-1) < 0 && left(i) >= 0 {
+			crossings++
+		}
+	}
+	if crossings < 420 || crossings > 460 {
+		t.Errorf("Expected about 440 cycles in half a second (880 Hz), got %d", crossings)
+	}
+}
+
+func TestVolumeAppliesMidStream(t *testing.T) {
+	// A constant level, read a chunk at a time while the volume changes
+	data := make([]byte, 0, 4096)
+	for len(data) < 4096 {
+		data = appendFrame(data, [2]float64{0.5, 0.5}, 1, 2)
+	}
+	player := NewAudioPlayer()
+	reader := &gainReader{reader: bytes.NewReader(data), gain: &player.liveVolume}
+	sample := func(chunk []byte) int16 {
+		return int16(uint16(chunk[0]) | uint16(chunk[1])<<8)
+	}
+
+	chunk := make([]byte, 1024)
+	reader.Read(chunk)
+	full := sample(chunk)
+
+	player.SetVolume(0.5)
+	reader.Read(chunk)
+	if half := sample(chunk); half < full/2-1 || half > full/2+1 {
+		t.Errorf("Expected the next chunk at half volume (%d), got %d", full/2, half)
+	}
+
+	player.SetVolume(1)
+	reader.Read(chunk)
+	if restored := sample(chunk); restored != full {
+		t.Errorf("Expected full volume back (%d), got %d", full, restored)
 	}
 }
 
+func TestEQReader(t *testing.T) {
+	const sampleRate = 44100
+	// tone is a second of stereo PCM at freq
+	tone := func(freq float64) []byte {
+		data := make([]byte, 0, sampleRate*4)
+		for i := 0; i < sampleRate; i++ {
+			value := 0.25 * math.Sin(2*math.Pi*freq*float64(i)/sampleRate)
+			data = appendFrame(data, [2]float64{value, value}, 1, 2)
+		}
+		return data
+	}
+	// level is the RMS of the left channel over the second half, once the
+	// filters have settled
+	level := func(data []byte) float64 {
+		sum := 0.0
+		frames := len(data) / 4
+		for i := frames / 2; i < frames; i++ {
+			value := float64(int16(uint16(data[i*4]) | uint16(data[i*4+1])<<8))
+			sum += value * value
+		}
+		return math.Sqrt(sum / float64(frames-frames/2))
+	}
+	filter := func(data []byte, gains *EQGains) []byte {
+		var setting atomic.Pointer[EQGains]
+		setting.Store(gains)
+		out, err := io.ReadAll(newEQReader(bytes.NewReader(append([]byte(nil), data...)), &setting, sampleRate, 2, 0))
+		if err != nil || len(out) != len(data) {
+			t.Fatalf("Expected %d bytes, got %d (%v)", len(data), len(out), err)
+		}
+		return out
+	}
+
+	kHz := tone(1000)
+	if out := filter(kHz, nil); !bytes.Equal(out, kHz) {
+		t.Error("Expected no equalizer to leave the sound unchanged")
+	}
+
+	boost := EQGains{}
+	boost[5] = 6 // 1 kHz band
+	if ratio := level(filter(kHz, &boost)) / level(kHz); ratio < 1.9 || ratio > 2.1 {
+		t.Errorf("Expected 6 dB at 1 kHz to double the level, got %.2fx", ratio)
+	}
+
+	treble := EQPresets["treble"]
+	low := tone(100)
+	if ratio := level(filter(low, &treble)) / level(low); ratio < 0.95 || ratio > 1.05 {
+		t.Errorf("Expected the treble preset to leave 100 Hz alone, got %.2fx", ratio)
+	}
+}
+
+func TestCheckOutputStall(t *testing.T) {
+	player := NewAudioPlayer()
+	player.duration = time.Minute
+	player.position = 10 * time.Second
+
+	if err := player.checkOutput(); err == nil {
+		t.Error("Expected a stall when the output never pulled audio")
+	}
+
+	reader := &activityReader{reader: bytes.NewReader(make([]byte, 64)), last: &player.lastRead}
+	io.ReadAll(reader)
+	if err := player.checkOutput(); err != nil {
+		t.Errorf("Expected no failure right after a read, got %v", err)
+	}
+
+	// Silence is expected at the end of the song
+	player.lastRead.Store(0)
+	player.position = player.duration - time.Second
+	if err := player.checkOutput(); err != nil {
+		t.Errorf("Expected no failure near the end, got %v", err)
+	}
+}
+
+func TestDecodeCacheSamples(t *testing.T) {
+	samples := [][2]float64{{0, 0}, {0.5, -0.5}, {1.5, -1.5}}
+
+	var buffer bytes.Buffer
+	if err := writeSamples(&buffer, samples, 44100, 2); err != nil {
+		t.Fatal(err)
+	}
+	read, sampleRate, channels, err := readSamples(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sampleRate != 44100 || channels != 2 || len(read) != len(samples) {
+		t.Fatalf("Expected 3 frames at 44100 Hz in stereo, got %d at %d Hz, %d channels", len(read), sampleRate, channels)
+	}
+	if math.Abs(read[1][0]-0.5) > 0.001 || math.Abs(read[1][1]+0.5) > 0.001 {
+		t.Errorf("Expected {0.5 -0.5}, got %v", read[1])
+	}
+	if read[2] != [2]float64{1, -1} {
+		t.Errorf("Expected out of range samples to be clamped, got %v", read[2])
+	}
+
+	if _, _, _, err := readSamples(bytes.NewReader([]byte("junk data"))); err == nil {
+		t.Error("Expected an error reading something that isn't a decoded song")
+	}
+}
+
+func TestOutputCommand(t *testing.T) {
+	name, args, err := outputCommand(OutputConfig{Backend: OutputPipeWire, Device: "usb-mixer", LatencyMs: 10}, 48000, 2)
+	if err != nil || name != "pw-cat" {
+		t.Fatalf("Expected pw-cat, got %q, %v", name, err)
+	}
+	if got := strings.Join(args, " "); got != "--playback --raw --format s16 --rate 48000 --channels 2 --latency 10ms --target usb-mixer -" {
+		t.Errorf("Unexpected pw-cat arguments: %s", got)
+	}
+
+	name, args, _ = outputCommand(OutputConfig{Backend: OutputJACK, Device: "mixer:in_1, mixer:in_2, mixer:in_3"}, 48000, 2)
+	if got := strings.Join(args, " "); name != "jack-stdin" || !strings.HasSuffix(got, "-L mixer:in_1 mixer:in_2") {
+		t.Errorf("Expected one JACK port per channel, got %s %s", name, got)
+	}
+
+	if _, _, err := outputCommand(OutputConfig{Backend: "pulse"}, 44100, 2); err == nil {
+		t.Error("Expected an error for an unknown output")
+	}
+}
 
+func TestMicEffects(t *testing.T) {
+	var effects atomic.Pointer[MicEffects]
+	chain := newEffectChain(&effects, 1000, 1)
+
+	// An impulse followed by silence, 10ms a sample at 1kHz
+	pcm := make([]byte, 200)
+	pcm[0], pcm[1] = 0x00, 0x40 // 16384
+	dry := append([]byte(nil), pcm...)
+	chain.Process(pcm)
+	if !bytes.Equal(pcm, dry) {
+		t.Error("Expected no effects to leave the mic dry")
+	}
+
+	effects.Store(&MicEffects{Gain: 1, Echo: 0.5, EchoDelayMs: 20})
+	chain.Process(pcm)
+	sample := func(i int) int16 { return int16(uint16(pcm[2*i]) | uint16(pcm[2*i+1])<<8) }
+	if sample(0) != 16384 || sample(20) != 8192 || sample(40) != 4096 || sample(10) != 0 {
+		t.Errorf("Expected echoes at half the level every 20 samples, got %d, %d, %d", sample(0), sample(20), sample(40))
+	}
+
+	effects.Store(&MicEffects{Gain: 4})
+	chain.Process(pcm[:2])
+	if sample(0) != math.MaxInt16 {
+		t.Errorf("Expected gain to clip at the maximum, got %d", sample(0))
+	}
+}
+
+func TestAutoGain(t *testing.T) {
+	tone := func(amplitude float64) []byte {
+		pcm := make([]byte, 2048)
+		for i := 0; i < len(pcm)/2; i++ {
+			sample := uint16(int16(amplitude * 32767 * math.Sin(float64(i)/5)))
+			pcm[2*i], pcm[2*i+1] = byte(sample), byte(sample>>8)
+		}
+		return pcm
+	}
+
+	// A quiet and a loud singer both end up near the target level
+	for _, amplitude := range []float64{0.05, 0.9} {
+		var agc autoGain
+		var pcm []byte
+		for i := 0; i < 200; i++ {
+			pcm = tone(amplitude)
+			agc.process(pcm)
+		}
+		if level := rmsLevel(pcm); math.Abs(level-agcTarget) > 0.02 {
+			t.Errorf("Expected a tone at %.2f to settle near %.2f, got %.3f", amplitude, agcTarget, level)
+		}
+	}
+
+	// Silence doesn't pump the gain up
+	var agc autoGain
+	agc.process(make([]byte, 2048))
+	if agc.gain != 1 {
+		t.Errorf("Expected silence to hold the gain, got %.2f", agc.gain)
+	}
+}
+
+// rampStreamer is a beep streamer of frames counting up by 1/1000
+type rampStreamer struct {
+	position, length int
+}
+
+func (r *rampStreamer) Stream(samples [][2]float64) (int, bool) {
+	n := 0
+	for n < len(samples) && r.position < r.length {
+		value := float64(r.position) / 1000
+		samples[n] = [2]float64{value, -value}
+		n++
+		r.position++
+	}
+	return n, n > 0
+}
+
+func (r *rampStreamer) Err() error    { return nil }
+func (r *rampStreamer) Len() int      { return r.length }
+func (r *rampStreamer) Position() int { return r.position }
+func (r *rampStreamer) Close() error  { return nil }
+
+func (r *rampStreamer) Seek(p int) error {
+	r.position = p
+	return nil
+}
+
+func TestDecodingSource(t *testing.T) {
+	source := newDecodingSource(&rampStreamer{length: 5000}, 2, 1)
+
+	// Read from frame 1000 on, across several decoded chunks
+	pcm, err := io.ReadAll(source.reader(1000 * 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pcm) != 4000*4 {
+		t.Fatalf("Expected 4000 frames, got %d bytes", len(pcm))
+	}
+	frame := func(i int) (int16, int16) {
+		return int16(uint16(pcm[i*4]) | uint16(pcm[i*4+1])<<8), int16(uint16(pcm[i*4+2]) | uint16(pcm[i*4+3])<<8)
+	}
+	if left, right := frame(0); left != 32767 || right != -32767 {
+		t.Errorf("Expected the first frame at full scale, got %d, %d", left, right)
+	}
+
+	// A seek leaves the reader before it with nothing more to read
+	old := source.reader(0)
+	source.reader(0)
+	if n, err := old.Read(make([]byte, 16)); n != 0 || err != io.EOF {
+		t.Errorf("Expected a replaced reader to end, got %d bytes, %v", n, err)
+	}
+
+	source.close()
+	if _, err := source.reader(0).Read(make([]byte, 16)); err != io.EOF {
+		t.Errorf("Expected a closed source to end, got %v", err)
+	}
+}
+
+func TestSilentSource(t *testing.T) {
+	source := &silentSource{length: 64}
+
+	data, err := io.ReadAll(source.reader(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 48 {
+		t.Errorf("Expected 48 bytes from offset 16, got %d", len(data))
+	}
+	for _, b := range data {
+		if b != 0 {
+			t.Fatal("Expected only silence")
+		}
+	}
+	if data, _ := io.ReadAll(source.reader(100)); len(data) != 0 {
+		t.Errorf("Expected nothing past the end, got %d bytes", len(data))
+	}
+}
+
+func TestChainReader(t *testing.T) {
+	var queue songQueue
+	gen := queue.reset()
+	queue.set(gen, &queuedSong{
+		name:       "next.mp3",
+		source:     &memorySource{data: []byte{5, 6, 7, 8}},
+		sampleRate: 44100,
+		channels:   2,
+	})
+
+	switched := false
+	chain := &chainReader{
+		reader:     bytes.NewReader([]byte{1, 2, 3, 4}),
+		queue:      &queue,
+		sampleRate: 44100,
+		channels:   2,
+		onSwitch:   func() { switched = true },
+	}
+	data, err := io.ReadAll(chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Errorf("Expected both songs back to back, got %v", data)
+	}
+	if !switched {
+		t.Error("Expected the switch to the queued song to be reported")
+	}
+	if started := queue.takeStarted(); started == nil || started.name != "next.mp3" {
+		t.Errorf("Expected next.mp3 to have started, got %+v", started)
+	}
+
+	// A song in another format can't follow on the same output
+	gen = queue.reset()
+	queue.set(gen, &queuedSong{source: &memorySource{data: []byte{9, 9}}, sampleRate: 48000, channels: 2})
+	chain = &chainReader{reader: bytes.NewReader([]byte{1, 2}), queue: &queue, sampleRate: 44100, channels: 2}
+	if data, _ := io.ReadAll(chain); len(data) != 2 {
+		t.Errorf("Expected playback to stop at the end of the song, got %v", data)
+	}
+}
+
+// bufferingStream is an output holding a fixed amount it hasn't played
+type bufferingStream struct {
+	failedStream
+	buffered int
+}
+
+func (s *bufferingStream) BufferedSize() int {
+	return s.buffered
+}
+
+func TestPlayedPosition(t *testing.T) {
+	// A second of mono audio at 1 kHz, started half a second in
+	player := NewAudioPlayer()
+	player.sampleRate, player.channels = 1000, 1
+	player.duration = time.Second
+	music := &musicStream{player: player, reader: bytes.NewReader(make([]byte, 1000)), playing: true, start: 1000, read: 1000}
+	player.mixer.music = music
+	player.player = music
+	out := &bufferingStream{buffered: 200}
+	player.mixOut = out
+
+	buf := make([]byte, 600)
+	player.mixer.Read(buf)
+	if got, want := player.playedPosition(), 700*time.Millisecond; got != want {
+		t.Errorf("Expected the output to have played to %v, got %v", want, got)
+	}
+
+	// Once the song ends, the silence after it comes out last
+	player.mixer.Read(buf)
+	player.mixer.Read(buf)
+	if got := player.playedPosition(); got != time.Second {
+		t.Errorf("Expected the whole song played, got %v", got)
+	}
+
+	// GetPosition works it out the same way while playing
+	player.isPlaying = true
+	if got := player.GetPosition(); got != time.Second {
+		t.Errorf("Expected GetPosition to match, got %v", got)
+	}
+}
+
+func TestMixer(t *testing.T) {
+	music := make([]byte, 0, 8)
+	for range 4 {
+		music = append(music, 100, 0)
+	}
+	m := newMixer()
+	m.music = &musicStream{reader: bytes.NewReader(music), playing: true}
+	m.addClip(SourceSoundboard, []byte{50, 0, 50, 0})
+	m.setGain(SourceMusic, 0.5)
+
+	// Odd-sized reads still mix whole samples
+	buf := make([]byte, 3)
+	var data []byte
+	for len(data) < 12 {
+		n, _ := m.Read(buf)
+		data = append(data, buf[:n]...)
+	}
+	want := []byte{100, 0, 100, 0, 50, 0, 50, 0, 0, 0, 0, 0}
+	if !bytes.Equal(data, want) {
+		t.Errorf("Expected %v, got %v", want, data)
+	}
+	if !m.music.ended || len(m.clips) != 0 {
+		t.Error("Expected the song to end and the finished clip to be dropped")
+	}
+	if !m.idle() {
+		t.Error("Expected the mixer to be idle after the song and clip")
+	}
+}
+
+func TestMicMixWholeFrames(t *testing.T) {
+	// Three stereo samples, one and a half frames: left 1000, right 2000, left 1000
+	mic := &micInput{channels: 2, data: []byte{0xe8, 0x03, 0xd0, 0x07, 0xe8, 0x03}}
+	dst := make([]byte, 8)
+	mic.mixInto(dst, 1)
+
+	if left, right := int16(binary.LittleEndian.Uint16(dst)), int16(binary.LittleEndian.Uint16(dst[2:])); left != 1000 || right != 2000 {
+		t.Errorf("Expected the whole frame mixed in, got %d, %d", left, right)
+	}
+	if !bytes.Equal(dst[4:], make([]byte, 4)) || len(mic.data) != 2 {
+		t.Errorf("Expected the half frame kept for later, got %v with %d bytes left", dst, len(mic.data))
+	}
+}
@@ -0,0 +1,47 @@
+package player
+
+import (
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// bufferedStream is an output that can say how much it has taken but not yet
+// played; *oto.Player is one. Outputs that can't are taken to hold nothing.
+type bufferedStream interface {
+	BufferedSize() int
+}
+
+// heardBytes returns how far through the stream's music the output has
+// played, in bytes from the start of the song it began in: what the mixer
+// has read of the music, less what of it the output still holds (caller
+// must hold the mutex)
+func (p *AudioPlayer) heardBytes(music *musicStream) int64 {
+	buffered := 0
+	if out, ok := p.mixOut.(bufferedStream); ok {
+		buffered = out.BufferedSize()
+	}
+
+	m := p.mixer
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// The output holds the last of the mix, which after a pause or the end
+	// of the song is silence rather than music
+	unheard := music.mixEnd - (m.offset - int64(buffered))
+	if unheard < 0 {
+		unheard = 0
+	}
+	return music.read - min(unheard, music.read-music.start)
+}
+
+// playedPosition works out the position in the current song from the audio
+// the output has played (caller must hold the mutex)
+func (p *AudioPlayer) playedPosition() time.Duration {
+	music, ok := p.player.(*musicStream)
+	if !ok || p.channels == 0 {
+		return p.position
+	}
+	frames := (p.heardBytes(music) - p.songStart) / int64(2*p.channels)
+	return utils.FramesDuration(max(frames, 0), p.sampleRate)
+}
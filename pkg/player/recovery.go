@@ -0,0 +1,126 @@
+package player
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// stallTimeout is how long the output may go without pulling audio
+	// before playback counts as stuck, e.g. when another program grabbed the
+	// device
+	stallTimeout = 3 * time.Second
+
+	// recoverRewind is how far before the failure playback resumes, so the
+	// line that was cut off is heard again
+	recoverRewind = 2 * time.Second
+)
+
+// activityReader records when the output last pulled audio, so a stalled
+// device can be told apart from one that is playing
+type activityReader struct {
+	reader io.Reader
+	last   *atomic.Int64 // unix nanoseconds of the last read
+}
+
+func (r *activityReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.last.Store(time.Now().UnixNano())
+	return n, err
+}
+
+// checkOutput returns why playback has failed, or nil while it is fine
+// (caller must hold the mutex)
+func (p *AudioPlayer) checkOutput() error {
+	if p.otoContext != nil {
+		if err := p.otoContext.Err(); err != nil {
+			return fmt.Errorf("audio device failed: %w", err)
+		}
+	}
+	if p.player != nil {
+		if err := p.player.Err(); err != nil {
+			return fmt.Errorf("playback failed: %w", err)
+		}
+	}
+
+	// Near the end the output has had all the audio, so silence is expected
+	idle := time.Since(time.Unix(0, p.lastRead.Load()))
+	if idle > stallTimeout && p.position+stallTimeout < p.duration {
+		return errors.New("audio output stopped responding")
+	}
+	return nil
+}
+
+// fail stops playback after a failure, keeping the position so Recover can
+// pick up from it (caller must hold the mutex)
+func (p *AudioPlayer) fail(err error) {
+	if p.player != nil {
+		p.player.Pause()
+		p.player.Close()
+		p.player = nil
+	}
+	p.closeMixOutput()
+	p.failure = err
+	p.isPlaying = false
+	p.isPaused = false
+}
+
+// Failure returns why playback failed, or nil if it hasn't. Playback stays
+// stopped until Recover succeeds or another file is played.
+func (p *AudioPlayer) Failure() error {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.failure
+}
+
+// Recover restarts the audio output after a failure and resumes a little
+// before where playback stopped. Failures of the device itself can't be
+// undone without restarting the program, so those are returned.
+func (p *AudioPlayer) Recover() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.failure == nil {
+		return nil
+	}
+	if !p.isLoaded || (p.outputConfig == nil && p.otoContext == nil) {
+		return p.failure
+	}
+
+	if p.outputConfig != nil {
+		// Start the output program afresh on the next stream
+		if p.output != nil {
+			p.output.Close()
+			p.output = nil
+		}
+	} else {
+		// Wake the output loop in case it is blocked on the device
+		p.otoContext.Suspend()
+		if err := p.otoContext.Resume(); err != nil {
+			return fmt.Errorf("failed to reopen audio output: %w", err)
+		}
+	}
+
+	position := p.position - recoverRewind
+	if position < 0 {
+		position = 0
+	}
+	frameSize := int64(2 * p.channels)
+	offset := int64(position) * int64(p.sampleRate) / int64(time.Second) * frameSize
+
+	p.player = p.newStream(p.source.reader(offset), offset)
+	p.player.Play()
+
+	p.failure = nil
+	p.position = position
+	p.songStart = 0
+	p.isPlaying = true
+	p.isPaused = false
+
+	p.trackGen++
+	go p.trackPosition(p.trackGen)
+	return nil
+}
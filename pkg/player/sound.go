@@ -0,0 +1,29 @@
+package player
+
+import (
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/utils"
+)
+
+// Sound is a short clip, such as a soundboard effect, decoded in full so it
+// starts the moment it is played
+type Sound struct {
+	samples    [][2]float64
+	sampleRate int
+}
+
+// LoadSound decodes the clip in filename
+func LoadSound(filename string) (*Sound, error) {
+	streamer, format, err := Decode(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer streamer.Close()
+	return &Sound{samples: streamAll(streamer), sampleRate: int(format.SampleRate)}, nil
+}
+
+// Duration returns how long the sound lasts
+func (s *Sound) Duration() time.Duration {
+	return utils.FramesDuration(int64(len(s.samples)), s.sampleRate)
+}
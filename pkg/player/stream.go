@@ -0,0 +1,185 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/faiface/beep"
+)
+
+// songSource is where a loaded song's PCM comes from: held in memory when
+// it had to be processed as a whole first, such as for a pitch shift, or
+// else decoded from the file while it plays
+type songSource interface {
+	// reader returns the song's PCM from offset bytes in. A source only
+	// feeds its newest reader; older ones stop at the next read.
+	reader(offset int64) io.Reader
+	// window returns up to n bytes of PCM around offset, for visualization
+	window(offset int64, n int) []byte
+	close()
+}
+
+// memorySource is a song converted to PCM ahead of playback
+type memorySource struct {
+	data []byte
+}
+
+func (s *memorySource) reader(offset int64) io.Reader {
+	reader := bytes.NewReader(s.data)
+	reader.Seek(offset, io.SeekStart)
+	return reader
+}
+
+func (s *memorySource) window(offset int64, n int) []byte {
+	if offset < 0 || offset >= int64(len(s.data)) {
+		return nil
+	}
+	return s.data[offset:min(int64(len(s.data)), offset+int64(n))]
+}
+
+func (s *memorySource) close() {}
+
+// silentSource is a song of nothing but silence, for a click track to be
+// mixed into when there's no audio
+type silentSource struct {
+	length int64 // in bytes
+}
+
+func (s *silentSource) reader(offset int64) io.Reader {
+	return io.LimitReader(zeroReader{}, max(0, s.length-offset))
+}
+
+func (s *silentSource) window(offset int64, n int) []byte {
+	return nil
+}
+
+func (s *silentSource) close() {}
+
+// zeroReader reads zeros forever
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// streamChunkFrames is how many frames a decodingSource decodes at a time
+const streamChunkFrames = 2048
+
+// decodingSource decodes a song as the output asks for it, so playback
+// starts straight away and only a chunk of the song is in memory at once
+type decodingSource struct {
+	mutex    sync.Mutex
+	streamer beep.StreamSeekCloser
+	channels int
+	gain     float64 // track gain, fixed when the song is loaded
+	gen      int     // the reader being fed; older readers get io.EOF
+	closed   bool
+	samples  [][2]float64
+	recent   []byte // the PCM last handed out, for visualization
+}
+
+func newDecodingSource(streamer beep.StreamSeekCloser, channels int, gain float64) *decodingSource {
+	return &decodingSource{
+		streamer: streamer,
+		channels: channels,
+		gain:     gain,
+		samples:  make([][2]float64, streamChunkFrames),
+	}
+}
+
+func (s *decodingSource) reader(offset int64) io.Reader {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.gen++
+	if s.closed {
+		return &decodingReader{source: s, gen: s.gen, err: io.EOF}
+	}
+	frameSize := int64(2 * s.channels)
+	frame := int(offset / frameSize)
+	if frame > s.streamer.Len() {
+		frame = s.streamer.Len()
+	}
+	if err := s.streamer.Seek(frame); err != nil {
+		return &decodingReader{source: s, gen: s.gen, err: err}
+	}
+	return &decodingReader{source: s, gen: s.gen}
+}
+
+func (s *decodingSource) window(offset int64, n int) []byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]byte(nil), s.recent[:min(n, len(s.recent))]...)
+}
+
+func (s *decodingSource) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.closed {
+		s.closed = true
+		s.streamer.Close()
+	}
+}
+
+// decode fills the pending PCM of r with the next chunk of the song
+func (s *decodingSource) decode(r *decodingReader) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed || r.gen != s.gen {
+		return io.EOF
+	}
+	n, ok := s.streamer.Stream(s.samples)
+	if !ok || n == 0 {
+		if err := s.streamer.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+
+	frameSize := 2 * s.channels
+	r.buffer = r.buffer[:0]
+	for _, sample := range s.samples[:n] {
+		r.buffer = appendFrame(r.buffer, sample, s.gain, s.channels)
+	}
+	r.pending = r.buffer
+	s.recent = append(s.recent[:0], r.pending[:min(len(r.pending), 1024*frameSize)]...)
+	return nil
+}
+
+// decodingReader reads PCM from a decodingSource, from where it was seeked to
+type decodingReader struct {
+	source  *decodingSource
+	gen     int
+	err     error
+	pending []byte // decoded PCM not read yet
+	buffer  []byte // reused for each chunk
+}
+
+func (r *decodingReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.err = r.source.decode(r); r.err != nil {
+			return 0, r.err
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// appendFrame adds a sample to buf as one 16-bit little-endian PCM frame,
+// scaled by gain and clamped, with the left channel only for mono
+func appendFrame(buf []byte, sample [2]float64, gain float64, channels int) []byte {
+	left := uint16(sampleToInt16(sample[0] * gain))
+	buf = append(buf, byte(left), byte(left>>8))
+	if channels > 1 {
+		right := uint16(sampleToInt16(sample[1] * gain))
+		buf = append(buf, byte(right), byte(right>>8))
+	}
+	return buf
+}
@@ -5,7 +5,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tuneminal/tuneminal/pkg/paths"
+	"github.com/tuneminal/tuneminal/pkg/utils"
 )
 
 // Playlist represents a music playlist
@@ -24,16 +31,157 @@ type PlaylistManager struct {
 
 // NewPlaylistManager creates a new playlist manager
 func NewPlaylistManager() *PlaylistManager {
-	homeDir, _ := os.UserHomeDir()
-	playlistDir := filepath.Join(homeDir, ".tuneminal", "playlists")
+	playlistDir := paths.Data("playlists")
 
 	return &PlaylistManager{
 		playlistDir: playlistDir,
 	}
 }
 
+// maxFileNameLength caps a playlist's file name, before the .json
+const maxFileNameLength = 64
+
+// reservedFileNames can't be used as file names on Windows, whatever the
+// extension
+var reservedFileNames = map[string]bool{
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true, "com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true, "lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// FileName turns a playlist's name into one that is safe as a file name on
+// any system: lower-case ASCII letters, digits and underscores, with dashes
+// for everything else. The name shown stays in the playlist itself.
+func FileName(name string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'):
+			slug.WriteRune(r)
+			dash = false
+		case slug.Len() > 0 && !dash:
+			slug.WriteByte('-')
+			dash = true
+		}
+	}
+
+	fileName := strings.TrimRight(slug.String(), "-")
+	if len(fileName) > maxFileNameLength {
+		fileName = strings.TrimRight(fileName[:maxFileNameLength], "-")
+	}
+	if fileName == "" {
+		fileName = "playlist"
+	}
+	if reservedFileNames[fileName] {
+		fileName += "-playlist"
+	}
+	return fileName
+}
+
+// checkName rejects empty names and names already taken by another
+// playlist, ignoring case, so two playlists can't look the same in a list
+func checkName(files map[string]string, name, except string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("playlist name is empty")
+	}
+	for existing := range files {
+		if existing != except && strings.EqualFold(existing, name) {
+			return fmt.Errorf("a playlist called %q already exists", existing)
+		}
+	}
+	return nil
+}
+
+// files maps each playlist's name to its file. Playlists saved before file
+// names were made safe are named after their file.
+func (pm *PlaylistManager) files() (map[string]string, error) {
+	entries, err := os.ReadDir(pm.playlistDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(pm.playlistDir, entry.Name())
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		if playlist, err := readPlaylist(path); err == nil && playlist.Name != "" {
+			name = playlist.Name
+		}
+		files[name] = path
+	}
+	return files, nil
+}
+
+// newFilePath picks a file for a new playlist name, numbering it when the
+// safe name is already used by a different playlist
+func (pm *PlaylistManager) newFilePath(name string) string {
+	base := FileName(name)
+	path := filepath.Join(pm.playlistDir, base+".json")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = filepath.Join(pm.playlistDir, fmt.Sprintf("%s-%d.json", base, i))
+	}
+}
+
+// filePath returns the file of the playlist called name
+func (pm *PlaylistManager) filePath(name string) (string, error) {
+	files, err := pm.files()
+	if err != nil {
+		return "", err
+	}
+	path, ok := files[name]
+	if !ok {
+		return "", fmt.Errorf("playlist %q: %w", name, os.ErrNotExist)
+	}
+	return path, nil
+}
+
+// readPlaylist reads a playlist file
+func readPlaylist(path string) (*Playlist, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var playlist Playlist
+	if err := json.Unmarshal(data, &playlist); err != nil {
+		if _, statErr := os.Stat(utils.BackupPath(path)); statErr == nil {
+			return nil, fmt.Errorf("playlist %s is damaged, the previous version is in %s: %w", path, utils.BackupPath(path), err)
+		}
+		return nil, fmt.Errorf("playlist %s is damaged: %w", path, err)
+	}
+	return &playlist, nil
+}
+
+// writePlaylist writes a playlist file in one step, keeping the version it
+// replaces as a backup
+func writePlaylist(path string, playlist *Playlist) error {
+	data, err := json.MarshalIndent(playlist, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileWithBackup(path, data)
+}
+
 // CreatePlaylist creates a new playlist
 func (pm *PlaylistManager) CreatePlaylist(name, description string) (*Playlist, error) {
+	files, err := pm.files()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkName(files, name, ""); err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 
 	playlist := &Playlist{
@@ -50,20 +198,11 @@ func (pm *PlaylistManager) CreatePlaylist(name, description string) (*Playlist,
 
 // LoadPlaylist loads a playlist by name
 func (pm *PlaylistManager) LoadPlaylist(name string) (*Playlist, error) {
-	filename := fmt.Sprintf("%s.json", name)
-	filepath := filepath.Join(pm.playlistDir, filename)
-
-	data, err := os.ReadFile(filepath)
+	path, err := pm.filePath(name)
 	if err != nil {
 		return nil, err
 	}
-
-	var playlist Playlist
-	if err := json.Unmarshal(data, &playlist); err != nil {
-		return nil, err
-	}
-
-	return &playlist, nil
+	return readPlaylist(path)
 }
 
 // SavePlaylist saves a playlist to file
@@ -76,43 +215,80 @@ func (pm *PlaylistManager) SavePlaylist(playlist *Playlist) error {
 	// Update modified time
 	playlist.Modified = time.Now()
 
-	filename := fmt.Sprintf("%s.json", playlist.Name)
-	filepath := filepath.Join(pm.playlistDir, filename)
+	files, err := pm.files()
+	if err != nil {
+		return err
+	}
+	path, ok := files[playlist.Name]
+	if !ok {
+		path = pm.newFilePath(playlist.Name)
+	}
+	return writePlaylist(path, playlist)
+}
 
-	data, err := json.MarshalIndent(playlist, "", "  ")
+// RenamePlaylist gives a playlist a new name, moving it to a file named
+// after it
+func (pm *PlaylistManager) RenamePlaylist(oldName, newName string) error {
+	files, err := pm.files()
 	if err != nil {
 		return err
 	}
+	oldPath, ok := files[oldName]
+	if !ok {
+		return fmt.Errorf("playlist %q: %w", oldName, os.ErrNotExist)
+	}
+	if err := checkName(files, newName, oldName); err != nil {
+		return err
+	}
+
+	playlist, err := readPlaylist(oldPath)
+	if err != nil {
+		return err
+	}
+	playlist.Name = newName
+	playlist.Modified = time.Now()
 
-	return os.WriteFile(filepath, data, 0644)
+	// A name that maps to the same file is saved in place
+	newPath := oldPath
+	if FileName(newName) != FileName(oldName) {
+		newPath = pm.newFilePath(newName)
+	}
+	if err := writePlaylist(newPath, playlist); err != nil {
+		return err
+	}
+	if newPath != oldPath {
+		os.Remove(utils.BackupPath(oldPath))
+		return os.Remove(oldPath)
+	}
+	return nil
 }
 
 // DeletePlaylist deletes a playlist
 func (pm *PlaylistManager) DeletePlaylist(name string) error {
-	filename := fmt.Sprintf("%s.json", name)
-	filepath := filepath.Join(pm.playlistDir, filename)
-	return os.Remove(filepath)
+	path, err := pm.filePath(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
 }
 
-// ListPlaylists returns all available playlists
+// ListPlaylists returns the names of all playlists
 func (pm *PlaylistManager) ListPlaylists() ([]string, error) {
 	// Create playlist directory if it doesn't exist
 	if err := os.MkdirAll(pm.playlistDir, 0755); err != nil {
 		return nil, err
 	}
 
-	files, err := os.ReadDir(pm.playlistDir)
+	files, err := pm.files()
 	if err != nil {
 		return nil, err
 	}
 
-	var playlists []string
-	for _, file := range files {
-		if filepath.Ext(file.Name()) == ".json" {
-			name := file.Name()[:len(file.Name())-5] // Remove .json extension
-			playlists = append(playlists, name)
-		}
+	playlists := make([]string, 0, len(files))
+	for name := range files {
+		playlists = append(playlists, name)
 	}
+	sort.Strings(playlists)
 
 	return playlists, nil
 }
@@ -161,3 +337,53 @@ func (pm *PlaylistManager) GetPlaylistSongs(playlistName string) ([]string, erro
 
 	return playlist.Songs, nil
 }
+
+// RenameSongPaths rewrites song paths in every playlist after files were
+// moved, returning how many playlists changed
+func (pm *PlaylistManager) RenameSongPaths(renames map[string]string) (int, error) {
+	names, err := pm.ListPlaylists()
+	if err != nil {
+		return 0, err
+	}
+
+	// Playlists may hold paths relative to the working directory, so match
+	// on absolute paths
+	absRenames := make(map[string]string, len(renames))
+	for from, to := range renames {
+		absRenames[absPath(from)] = to
+	}
+
+	changed := 0
+	for _, name := range names {
+		playlist, err := pm.LoadPlaylist(name)
+		if err != nil {
+			continue
+		}
+
+		modified := false
+		for i, song := range playlist.Songs {
+			if newPath, ok := absRenames[absPath(song)]; ok {
+				playlist.Songs[i] = newPath
+				modified = true
+			}
+		}
+		if !modified {
+			continue
+		}
+
+		if err := pm.SavePlaylist(playlist); err != nil {
+			return changed, err
+		}
+		changed++
+	}
+
+	return changed, nil
+}
+
+// absPath returns path made absolute, or unchanged if that fails
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
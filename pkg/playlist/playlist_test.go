@@ -0,0 +1,155 @@
+package playlist
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileName(t *testing.T) {
+	tests := map[string]string{
+		"Party Mix":          "party-mix",
+		"../../etc/passwd":   "etc-passwd",
+		"Rock/Pop: 80's":     "rock-pop-80-s",
+		"日本語":                "playlist",
+		"Café Classics":      "caf-classics",
+		"con":                "con-playlist",
+		"  __spaced__  ":     "__spaced__",
+		"a ... b":            "a-b",
+		"------":             "playlist",
+		"track_list.v2 (HQ)": "track_list-v2-hq",
+	}
+	for name, want := range tests {
+		if got := FileName(name); got != want {
+			t.Errorf("FileName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCreateKeepsNameAndUsesSafeFile(t *testing.T) {
+	pm := &PlaylistManager{playlistDir: t.TempDir()}
+
+	if _, err := pm.CreatePlaylist("Rock/Pop", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(pm.playlistDir, "rock-pop.json")); err != nil {
+		t.Errorf("expected rock-pop.json: %v", err)
+	}
+	if _, err := pm.CreatePlaylist("Rock:Pop", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(pm.playlistDir, "rock-pop-2.json")); err != nil {
+		t.Errorf("expected rock-pop-2.json for a second name with the same file name: %v", err)
+	}
+
+	names, err := pm.ListPlaylists()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "Rock/Pop" || names[1] != "Rock:Pop" {
+		t.Errorf("ListPlaylists() = %v", names)
+	}
+
+	if err := pm.AddSongToPlaylist("Rock/Pop", "/music/song.mp3"); err != nil {
+		t.Fatal(err)
+	}
+	songs, err := pm.GetPlaylistSongs("Rock/Pop")
+	if err != nil || len(songs) != 1 {
+		t.Errorf("GetPlaylistSongs() = %v, %v", songs, err)
+	}
+}
+
+func TestCreateRejectsDuplicateNames(t *testing.T) {
+	pm := &PlaylistManager{playlistDir: t.TempDir()}
+
+	if _, err := pm.CreatePlaylist("Favourites", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pm.CreatePlaylist("favourites", ""); err == nil {
+		t.Error("expected an error for a name differing only in case")
+	}
+	if _, err := pm.CreatePlaylist("  ", ""); err == nil {
+		t.Error("expected an error for an empty name")
+	}
+}
+
+func TestRenamePlaylist(t *testing.T) {
+	pm := &PlaylistManager{playlistDir: t.TempDir()}
+
+	if _, err := pm.CreatePlaylist("Old", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pm.CreatePlaylist("Other", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.RenamePlaylist("Old", "other"); err == nil {
+		t.Error("expected an error renaming onto another playlist's name")
+	}
+	if err := pm.RenamePlaylist("Old", "New Name"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(pm.playlistDir, "old.json")); !os.IsNotExist(err) {
+		t.Error("expected the old file to be gone")
+	}
+	if _, err := pm.LoadPlaylist("New Name"); err != nil {
+		t.Errorf("LoadPlaylist after rename: %v", err)
+	}
+	if err := pm.RenamePlaylist("New Name", "NEW NAME"); err != nil {
+		t.Errorf("renaming to a different case of the same name: %v", err)
+	}
+}
+
+func TestRenameSongPaths(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	pm := &PlaylistManager{playlistDir: filepath.Join(dir, "playlists")}
+
+	if _, err := pm.CreatePlaylist("Mix", ""); err != nil {
+		t.Fatal(err)
+	}
+	pm.AddSongToPlaylist("Mix", filepath.Join("music", "a.mp3"))
+	pm.AddSongToPlaylist("Mix", filepath.Join(dir, "music", "b.mp3"))
+	pm.AddSongToPlaylist("Mix", filepath.Join("music", "c.mp3"))
+
+	// Relative paths in the playlist match absolute ones moved, and the
+	// other way round
+	renames := map[string]string{
+		filepath.Join(dir, "music", "a.mp3"): filepath.Join(dir, "music", "A", "a.mp3"),
+		filepath.Join("music", "b.mp3"):      filepath.Join("music", "B", "b.mp3"),
+	}
+	changed, err := pm.RenameSongPaths(renames)
+	if err != nil || changed != 1 {
+		t.Fatalf("Expected 1 playlist changed, got %d, %v", changed, err)
+	}
+	songs, _ := pm.GetPlaylistSongs("Mix")
+	want := []string{filepath.Join(dir, "music", "A", "a.mp3"), filepath.Join("music", "B", "b.mp3"), filepath.Join("music", "c.mp3")}
+	if len(songs) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, songs)
+	}
+	for i := range want {
+		if songs[i] != want[i] {
+			t.Errorf("Song %d: got %q, want %q", i, songs[i], want[i])
+		}
+	}
+}
+
+func TestLegacyFileNames(t *testing.T) {
+	pm := &PlaylistManager{playlistDir: t.TempDir()}
+	os.WriteFile(filepath.Join(pm.playlistDir, "Party Mix.json"), []byte(`{"name":"Party Mix","songs":["/a.mp3"]}`), 0644)
+
+	if err := pm.AddSongToPlaylist("Party Mix", "/b.mp3"); err != nil {
+		t.Fatal(err)
+	}
+	songs, err := pm.GetPlaylistSongs("Party Mix")
+	if err != nil || len(songs) != 2 {
+		t.Errorf("GetPlaylistSongs() = %v, %v", songs, err)
+	}
+	entries, _ := os.ReadDir(pm.playlistDir)
+	if len(entries) != 2 || entries[0].Name() != "Party Mix.json" || entries[1].Name() != "Party Mix.json.bak" {
+		t.Errorf("expected the legacy file to be saved in place with a backup, got %v", entries)
+	}
+	if _, err := pm.LoadPlaylist("Missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadPlaylist of a missing playlist = %v, want os.ErrNotExist", err)
+	}
+}
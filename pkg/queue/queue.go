@@ -0,0 +1,101 @@
+// Package queue holds the songs lined up to play next, an ad-hoc singing
+// order kept apart from the library list and playlists
+package queue
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+)
+
+// Queue is the songs waiting to play, by path, first to play first
+type Queue struct {
+	mutex sync.Mutex
+	paths []string
+}
+
+// New creates an empty queue
+func New() *Queue {
+	return &Queue{}
+}
+
+// Len returns how many songs are waiting
+func (q *Queue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.paths)
+}
+
+// Songs returns the waiting songs in the order they will play
+func (q *Queue) Songs() []string {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return slices.Clone(q.paths)
+}
+
+// Enqueue adds a song to the end of the queue
+func (q *Queue) Enqueue(path string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.paths = append(q.paths, path)
+}
+
+// EnqueueNext adds a song to the front of the queue, to play before the rest
+func (q *Queue) EnqueueNext(path string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.paths = slices.Insert(q.paths, 0, path)
+}
+
+// Remove takes out the song at position i, reporting whether there was one
+func (q *Queue) Remove(i int) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if i < 0 || i >= len(q.paths) {
+		return false
+	}
+	q.paths = slices.Delete(q.paths, i, i+1)
+	return true
+}
+
+// RemoveSong takes out the first place the song at path has in the queue,
+// reporting whether it was queued
+func (q *Queue) RemoveSong(path string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	i := slices.Index(q.paths, path)
+	if i < 0 {
+		return false
+	}
+	q.paths = slices.Delete(q.paths, i, i+1)
+	return true
+}
+
+// Move moves the song at position from to position to, reporting whether
+// both are in the queue
+func (q *Queue) Move(from, to int) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if from < 0 || from >= len(q.paths) || to < 0 || to >= len(q.paths) {
+		return false
+	}
+	path := q.paths[from]
+	q.paths = slices.Insert(slices.Delete(q.paths, from, from+1), to, path)
+	return true
+}
+
+// Shuffle puts the waiting songs in a random order
+func (q *Queue) Shuffle() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	rand.Shuffle(len(q.paths), func(i, j int) {
+		q.paths[i], q.paths[j] = q.paths[j], q.paths[i]
+	})
+}
+
+// Clear empties the queue
+func (q *Queue) Clear() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.paths = nil
+}
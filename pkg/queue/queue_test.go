@@ -0,0 +1,43 @@
+package queue
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestQueueOrder(t *testing.T) {
+	q := New()
+	q.Enqueue("a")
+	q.Enqueue("b")
+	q.EnqueueNext("c")
+	if got := q.Songs(); !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Fatalf("Expected c to jump the queue, got %v", got)
+	}
+
+	if !q.Move(0, 2) {
+		t.Error("Expected the move to succeed")
+	}
+	if got := q.Songs(); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("Expected c moved to the end, got %v", got)
+	}
+	if q.Move(0, 3) {
+		t.Error("Expected a move past the end to fail")
+	}
+
+	q.Enqueue("a")
+	if !q.RemoveSong("a") || !slices.Equal(q.Songs(), []string{"b", "c", "a"}) {
+		t.Errorf("Expected only the first a removed, got %v", q.Songs())
+	}
+	if !q.Remove(1) || q.Remove(5) || !slices.Equal(q.Songs(), []string{"b", "a"}) {
+		t.Errorf("Unexpected queue after removing by position: %v", q.Songs())
+	}
+
+	q.Shuffle()
+	if q.Len() != 2 {
+		t.Errorf("Expected shuffling to keep both songs, got %v", q.Songs())
+	}
+	q.Clear()
+	if q.Len() != 0 {
+		t.Errorf("Expected an empty queue, got %v", q.Songs())
+	}
+}
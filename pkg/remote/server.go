@@ -0,0 +1,100 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Server is the HTTP layer other Tuneminal instances and remotes talk to
+type Server struct {
+	mux        *http.ServeMux
+	httpServer *http.Server
+	listener   net.Listener
+	mutex      sync.Mutex
+}
+
+// NewServer creates a server with no handlers registered
+func NewServer() *Server {
+	return &Server{
+		mux: http.NewServeMux(),
+	}
+}
+
+// Handle registers a handler, e.g. Handle("GET /api/playlist", ...)
+func (s *Server) Handle(pattern string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// Handler returns the server's request router
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// Start listens on addr (host:port) and serves requests in the background
+func (s *Server) Start(addr string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.listener != nil {
+		return fmt.Errorf("server already running on %s", s.listener.Addr())
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s.listener = listener
+	s.httpServer = &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go s.httpServer.Serve(listener)
+	return nil
+}
+
+// Addr returns the address the server listens on, or "" when stopped
+func (s *Server) Addr() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Running reports whether the server is listening
+func (s *Server) Running() bool {
+	return s.Addr() != ""
+}
+
+// Stop shuts the server down
+func (s *Server) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.httpServer == nil {
+		return nil
+	}
+	err := s.httpServer.Close()
+	s.httpServer = nil
+	s.listener = nil
+	return err
+}
+
+// writeJSON sends v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError sends an error message as a JSON response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,269 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits on proposals waiting for the host, so guests can't pile them up
+const (
+	maxPendingPerGuest = 5
+	maxPending         = 50
+)
+
+// ErrTooManyProposals is returned when a guest, or the guests together, have
+// as many proposals waiting as the host takes
+var ErrTooManyProposals = errors.New("too many proposals waiting for the host")
+
+// Entry is a song in a shared playlist. Songs are known to guests by title
+// and artist only: where the host keeps them isn't shared.
+type Entry struct {
+	Title   string `json:"title"`
+	Artist  string `json:"artist"`
+	AddedBy string `json:"added_by,omitempty"`
+}
+
+// Proposal is a guest's request to add a song to the shared playlist
+type Proposal struct {
+	ID      int       `json:"id"`
+	Entry   Entry     `json:"entry"`
+	From    string    `json:"from"`
+	Created time.Time `json:"created"`
+
+	addr string // Address the guest proposed from
+}
+
+// SharedState is the playlist as seen by guests
+type SharedState struct {
+	Version int        `json:"version"`
+	Name    string     `json:"name"`
+	Current int        `json:"current"` // Index of the playing entry, -1 if none
+	Entries []Entry    `json:"entries"`
+	Pending []Proposal `json:"pending"`
+
+	// Votes to skip the playing entry, and how many it takes
+	SkipVotes     int `json:"skip_votes"`
+	SkipThreshold int `json:"skip_threshold"`
+}
+
+// SharedPlaylist is the host-authoritative playlist that guests can read and
+// propose additions to
+type SharedPlaylist struct {
+	mutex  sync.Mutex
+	state  SharedState
+	nextID int
+	voters map[string]bool // Guests who voted to skip the playing entry
+
+	// OnProposal is called (from a server goroutine) when a guest proposes a song
+	OnProposal func(Proposal)
+
+	// OnSkipVote is called (from a server goroutine) when a guest votes to skip
+	OnSkipVote func(SkipVote)
+}
+
+// NewSharedPlaylist creates an empty shared playlist
+func NewSharedPlaylist() *SharedPlaylist {
+	return &SharedPlaylist{
+		state:  SharedState{Current: -1, Entries: []Entry{}, Pending: []Proposal{}},
+		nextID: 1,
+		voters: make(map[string]bool),
+	}
+}
+
+// Register adds the shared playlist endpoints to a server
+func (p *SharedPlaylist) Register(s *Server) {
+	s.Handle("GET /api/playlist", p.handleState)
+	s.Handle("POST /api/proposals", p.handlePropose)
+	s.Handle("POST /api/skip", p.handleSkip)
+}
+
+// Publish replaces the playlist guests see. Skip votes are cleared when a
+// different entry starts playing.
+func (p *SharedPlaylist) Publish(name string, entries []Entry, current int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if current != p.state.Current || entryAt(entries, current) != entryAt(p.state.Entries, current) {
+		p.voters = make(map[string]bool)
+		p.state.SkipVotes = 0
+	}
+
+	p.state.Name = name
+	p.state.Entries = append([]Entry{}, entries...)
+	p.state.Current = current
+	p.state.Version++
+}
+
+// State returns a copy of the current shared state
+func (p *SharedPlaylist) State() SharedState {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	state := p.state
+	state.Entries = append([]Entry{}, p.state.Entries...)
+	state.Pending = append([]Proposal{}, p.state.Pending...)
+	return state
+}
+
+// Propose queues a proposal from the guest called from at addr for the host
+// to accept or reject. It returns ErrTooManyProposals once that guest, by
+// name or address, or all guests together have as many waiting as allowed.
+func (p *SharedPlaylist) Propose(entry Entry, from, addr string) (Proposal, error) {
+	p.mutex.Lock()
+	waiting := 0
+	for _, pending := range p.state.Pending {
+		if pending.From == from || (addr != "" && pending.addr == addr) {
+			waiting++
+		}
+	}
+	if waiting >= maxPendingPerGuest || len(p.state.Pending) >= maxPending {
+		p.mutex.Unlock()
+		return Proposal{}, ErrTooManyProposals
+	}
+
+	proposal := Proposal{
+		ID:      p.nextID,
+		Entry:   entry,
+		From:    from,
+		Created: time.Now(),
+		addr:    addr,
+	}
+	p.nextID++
+	p.state.Pending = append(p.state.Pending, proposal)
+	p.state.Version++
+	callback := p.OnProposal
+	p.mutex.Unlock()
+
+	if callback != nil {
+		callback(proposal)
+	}
+	return proposal, nil
+}
+
+// Resolve removes a pending proposal and returns it, whether it was accepted
+// or rejected; accepted entries are added by the host through Publish
+func (p *SharedPlaylist) Resolve(id int) (Proposal, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i, proposal := range p.state.Pending {
+		if proposal.ID == id {
+			p.state.Pending = append(p.state.Pending[:i], p.state.Pending[i+1:]...)
+			p.state.Version++
+			return proposal, nil
+		}
+	}
+	return Proposal{}, fmt.Errorf("proposal %d not found", id)
+}
+
+func (p *SharedPlaylist) handleState(w http.ResponseWriter, r *http.Request) {
+	// Guests poll with ?since=<version> and get 304 when nothing changed
+	state := p.State()
+	if since, err := strconv.Atoi(r.URL.Query().Get("since")); err == nil && since == state.Version {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+func (p *SharedPlaylist) handlePropose(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Entry Entry  `json:"entry"`
+		From  string `json:"from"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid proposal")
+		return
+	}
+	if strings.TrimSpace(request.Entry.Title) == "" {
+		writeError(w, http.StatusBadRequest, "title is required")
+		return
+	}
+
+	// The name is the guest's to choose, so the address counts too
+	addr, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		addr = r.RemoteAddr
+	}
+	if request.From == "" {
+		request.From = addr
+	}
+	proposal, err := p.Propose(request.Entry, request.From, addr)
+	if err != nil {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, proposal)
+}
+
+// Client is a guest connection to another instance's shared playlist
+type Client struct {
+	baseURL string
+	name    string
+	http    *http.Client
+}
+
+// NewClient creates a client for the host at addr (host:port)
+func NewClient(addr, name string) *Client {
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "http://" + addr
+	}
+	return &Client{
+		baseURL: strings.TrimRight(addr, "/"),
+		name:    name,
+		http:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// State fetches the shared playlist; it returns nil without error when the
+// host's version still equals since
+func (c *Client) State(since int) (*SharedState, error) {
+	resp, err := c.http.Get(fmt.Sprintf("%s/api/playlist?since=%d", c.baseURL, since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach host: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("host returned %s", resp.Status)
+	}
+
+	var state SharedState
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return nil, fmt.Errorf("invalid response from host: %w", err)
+	}
+	return &state, nil
+}
+
+// Propose asks the host to add a song
+func (c *Client) Propose(entry Entry) error {
+	body, err := json.Marshal(map[string]interface{}{"entry": entry, "from": c.name})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Post(c.baseURL+"/api/proposals", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to reach host: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return ErrTooManyProposals
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("host rejected proposal: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSharedPlaylistProposals(t *testing.T) {
+	server := NewServer()
+	shared := NewSharedPlaylist()
+	shared.Register(server)
+	shared.Publish("Party", []Entry{{Title: "First", Artist: "Host"}}, 0)
+
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	client := NewClient(ts.URL, "guest")
+	state, err := client.State(-1)
+	if err != nil {
+		t.Fatalf("State() returned error: %v", err)
+	}
+	if state.Name != "Party" || len(state.Entries) != 1 {
+		t.Fatalf("Unexpected state: %+v", state)
+	}
+
+	// Unchanged playlists are not sent again
+	if again, err := client.State(state.Version); err != nil || again != nil {
+		t.Errorf("Expected no update, got %+v (err %v)", again, err)
+	}
+
+	if err := client.Propose(Entry{Title: "Second"}); err != nil {
+		t.Fatalf("Propose() returned error: %v", err)
+	}
+
+	pending := shared.State().Pending
+	if len(pending) != 1 || pending[0].From != "guest" {
+		t.Fatalf("Unexpected pending proposals: %+v", pending)
+	}
+
+	if _, err := shared.Resolve(pending[0].ID); err != nil {
+		t.Errorf("Resolve() returned error: %v", err)
+	}
+	if len(shared.State().Pending) != 0 {
+		t.Error("Proposal still pending after Resolve()")
+	}
+}
+
+func TestSkipVotes(t *testing.T) {
+	server := NewServer()
+	shared := NewSharedPlaylist()
+	shared.Register(server)
+	entries := []Entry{{Title: "First"}, {Title: "Second"}}
+	shared.Publish("Party", entries, 0)
+
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	client := NewClient(ts.URL, "guest")
+	if _, err := client.VoteSkip(); err == nil {
+		t.Error("Expected an error while skip voting is off")
+	}
+
+	shared.SetSkipThreshold(2)
+	var reached []SkipVote
+	shared.OnSkipVote = func(vote SkipVote) {
+		if vote.Reached() {
+			reached = append(reached, vote)
+		}
+	}
+
+	vote, err := client.VoteSkip()
+	if err != nil || vote.Votes != 1 || vote.Reached() {
+		t.Fatalf("Unexpected first vote %+v (err %v)", vote, err)
+	}
+	// Voting again from the same machine doesn't count twice
+	if vote, _ := client.VoteSkip(); vote.Votes != 1 {
+		t.Errorf("Repeated vote was counted: %+v", vote)
+	}
+
+	shared.VoteSkip("10.0.0.2", "other guest")
+	if len(reached) != 1 || reached[0].From != "other guest" {
+		t.Errorf("Expected the threshold to be reached once, got %+v", reached)
+	}
+
+	// A new song starts with no votes
+	shared.Publish("Party", entries, 1)
+	if votes := shared.State().SkipVotes; votes != 0 {
+		t.Errorf("Votes carried over to the next song: %d", votes)
+	}
+}
+
+func TestProposalLimits(t *testing.T) {
+	server := NewServer()
+	shared := NewSharedPlaylist()
+	shared.Register(server)
+
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	// One guest can only have a few waiting, whatever name they give
+	for i := range maxPendingPerGuest {
+		if err := NewClient(ts.URL, fmt.Sprintf("guest%d", i)).Propose(Entry{Title: "Song"}); err != nil {
+			t.Fatalf("Proposal %d: %v", i, err)
+		}
+	}
+	if err := NewClient(ts.URL, "another name").Propose(Entry{Title: "Song"}); !errors.Is(err, ErrTooManyProposals) {
+		t.Errorf("Expected ErrTooManyProposals for the same address, got %v", err)
+	}
+
+	// And all guests together only so many
+	for i := maxPendingPerGuest; i < maxPending; i++ {
+		if _, err := shared.Propose(Entry{Title: "Song"}, fmt.Sprintf("guest%d", i), fmt.Sprintf("10.0.0.%d", i)); err != nil {
+			t.Fatalf("Proposal %d: %v", i, err)
+		}
+	}
+	if _, err := shared.Propose(Entry{Title: "Song"}, "late", "10.0.1.1"); !errors.Is(err, ErrTooManyProposals) {
+		t.Errorf("Expected ErrTooManyProposals once %d are waiting, got %v", maxPending, err)
+	}
+
+	// Resolving one makes room again
+	shared.Resolve(shared.State().Pending[0].ID)
+	if _, err := shared.Propose(Entry{Title: "Song"}, "late", "10.0.1.1"); err != nil {
+		t.Errorf("Expected room after a proposal was resolved, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// SkipVote is the tally after a guest votes to skip the playing entry
+type SkipVote struct {
+	From      string `json:"from"`
+	Votes     int    `json:"votes"`
+	Threshold int    `json:"threshold"`
+}
+
+// Reached reports whether enough guests have voted to skip
+func (v SkipVote) Reached() bool {
+	return v.Threshold > 0 && v.Votes >= v.Threshold
+}
+
+// entryAt returns the entry at index, or an empty one when out of range
+func entryAt(entries []Entry, index int) Entry {
+	if index < 0 || index >= len(entries) {
+		return Entry{}
+	}
+	return entries[index]
+}
+
+// SetSkipThreshold sets how many guest votes it takes to skip; 0 turns
+// voting off
+func (p *SharedPlaylist) SetSkipThreshold(threshold int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.state.SkipThreshold = threshold
+	p.state.Version++
+}
+
+// VoteSkip records a vote to skip the playing entry. Each voter counts once
+// per entry; from is the name shown to the host.
+func (p *SharedPlaylist) VoteSkip(voter, from string) (SkipVote, error) {
+	p.mutex.Lock()
+	if p.state.SkipThreshold <= 0 {
+		p.mutex.Unlock()
+		return SkipVote{}, fmt.Errorf("skip voting is turned off")
+	}
+	if p.state.Current < 0 {
+		p.mutex.Unlock()
+		return SkipVote{}, fmt.Errorf("nothing is playing")
+	}
+
+	counted := !p.voters[voter]
+	if counted {
+		p.voters[voter] = true
+		p.state.SkipVotes = len(p.voters)
+		p.state.Version++
+	}
+	vote := SkipVote{From: from, Votes: p.state.SkipVotes, Threshold: p.state.SkipThreshold}
+	callback := p.OnSkipVote
+	p.mutex.Unlock()
+
+	if counted && callback != nil {
+		callback(vote)
+	}
+	return vote, nil
+}
+
+func (p *SharedPlaylist) handleSkip(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		From string `json:"from"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4<<10)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid vote")
+		return
+	}
+
+	// One vote per machine, whatever name it sends
+	voter, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		voter = r.RemoteAddr
+	}
+	if request.From == "" {
+		request.From = voter
+	}
+
+	vote, err := p.VoteSkip(voter, request.From)
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, vote)
+}
+
+// VoteSkip asks the host to skip the playing song and returns the tally
+func (c *Client) VoteSkip() (SkipVote, error) {
+	body, err := json.Marshal(map[string]string{"from": c.name})
+	if err != nil {
+		return SkipVote{}, err
+	}
+
+	resp, err := c.http.Post(c.baseURL+"/api/skip", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return SkipVote{}, fmt.Errorf("failed to reach host: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&failure) == nil && failure.Error != "" {
+			return SkipVote{}, fmt.Errorf("host rejected vote: %s", failure.Error)
+		}
+		return SkipVote{}, fmt.Errorf("host rejected vote: %s", resp.Status)
+	}
+
+	var vote SkipVote
+	if err := json.NewDecoder(resp.Body).Decode(&vote); err != nil {
+		return SkipVote{}, fmt.Errorf("invalid response from host: %w", err)
+	}
+	return vote, nil
+}
@@ -0,0 +1,166 @@
+package remotefs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+)
+
+// Song is a remote song as last listed, with what is known of its tags
+type Song struct {
+	Entry
+	Title    string        `json:"title"`
+	Artist   string        `json:"artist"`
+	Album    string        `json:"album,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+	Tagged   bool          `json:"tagged,omitempty"` // Tags read from the file, not guessed from its name
+	Lyrics   *Entry        `json:"lyrics,omitempty"` // The .lrc beside it, if any
+}
+
+// Index caches remote libraries' listings and song tags on disk, so they
+// show at startup without contacting the server and files are only read
+// for their tags once
+type Index struct {
+	path  string
+	mutex sync.Mutex
+	songs map[string]Song // By URL
+}
+
+// NewIndex opens the index stored at path, starting empty if the file is
+// missing or invalid
+func NewIndex(path string) *Index {
+	index := &Index{path: path, songs: make(map[string]Song)}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &index.songs)
+	}
+	return index
+}
+
+// save writes the index to disk
+func (x *Index) save() error {
+	if err := os.MkdirAll(filepath.Dir(x.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(x.songs)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(x.path, data, 0644)
+}
+
+// Songs returns the songs last listed for a library, sorted by address
+func (x *Index) Songs(library *Library) []Song {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	var songs []Song
+	for songURL, song := range x.songs {
+		if library.Contains(songURL) {
+			songs = append(songs, song)
+		}
+	}
+	sort.Slice(songs, func(i, j int) bool { return songs[i].URL < songs[j].URL })
+	return songs
+}
+
+// Get returns the indexed song at an address
+func (x *Index) Get(songURL string) (Song, bool) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+	song, ok := x.songs[songURL]
+	return song, ok
+}
+
+// Refresh replaces a library's songs with a new listing and saves the
+// index. Tags read before are kept for files that haven't changed; new
+// files get a title and artist guessed from their names.
+func (x *Index) Refresh(library *Library, entries []Entry) ([]Song, error) {
+	lyrics := make(map[string]Entry)
+	for _, entry := range entries {
+		if strings.EqualFold(path.Ext(entry.URL), ".lrc") {
+			lyrics[strings.TrimSuffix(entry.URL, path.Ext(entry.URL))] = entry
+		}
+	}
+
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	fresh := make(map[string]Song)
+	for _, entry := range entries {
+		if !metadata.IsSupportedFormat(path.Ext(entry.URL)) {
+			continue
+		}
+		song, known := x.songs[entry.URL]
+		if !known || song.Size != entry.Size || !song.ModTime.Equal(entry.ModTime) {
+			song = Song{Entry: entry}
+			song.Title, song.Artist = metadata.GuessFromFilename(fileName(entry.URL))
+		}
+		song.Lyrics = nil
+		if lrc, ok := lyrics[strings.TrimSuffix(entry.URL, path.Ext(entry.URL))]; ok {
+			song.Lyrics = &lrc
+		}
+		fresh[entry.URL] = song
+	}
+
+	for songURL := range x.songs {
+		if library.Contains(songURL) {
+			delete(x.songs, songURL)
+		}
+	}
+	songs := make([]Song, 0, len(fresh))
+	for songURL, song := range fresh {
+		x.songs[songURL] = song
+		songs = append(songs, song)
+	}
+	sort.Slice(songs, func(i, j int) bool { return songs[i].URL < songs[j].URL })
+	return songs, x.save()
+}
+
+// SetTags stores the tags read from a downloaded copy of a song. A title
+// and artist that only came from the copy's file name are left out, keeping
+// the ones guessed from the remote name.
+func (x *Index) SetTags(songURL string, meta *metadata.SongMetadata) (Song, error) {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	song, ok := x.songs[songURL]
+	if !ok {
+		return song, nil
+	}
+	if guessed, _ := metadata.GuessFromFilename(filepath.Base(meta.Path)); meta.Title != guessed {
+		song.Title, song.Artist = meta.Title, meta.Artist
+	}
+	song.Album = meta.Album
+	song.Duration = meta.Duration
+	song.Tagged = true
+	x.songs[songURL] = song
+	return song, x.save()
+}
+
+// fileName is the unescaped last part of a file's address
+func fileName(songURL string) string {
+	name := path.Base(songURL)
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		return unescaped
+	}
+	return name
+}
+
+// CacheName names the local copy of a remote file. It includes the size and
+// modification time, so a changed file gets a fresh copy.
+func CacheName(entry Entry) string {
+	sum := sha256.Sum256([]byte(entry.URL + "\x00" + strconv.FormatInt(entry.Size, 10) +
+		"\x00" + strconv.FormatInt(entry.ModTime.UnixNano(), 10)))
+	return hex.EncodeToString(sum[:12]) + strings.ToLower(path.Ext(fileName(entry.URL)))
+}
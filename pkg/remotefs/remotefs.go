@@ -0,0 +1,131 @@
+// Package remotefs lists and fetches music from folders on other machines,
+// such as a NAS, over SFTP (through the system ssh client) or WebDAV, for
+// libraries that aren't mounted locally
+package remotefs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Entry is one file in a remote library
+type Entry struct {
+	URL     string    `json:"url"` // Song address, without any password
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// backend is how one kind of server is listed and read. Paths are absolute
+// paths on the server.
+type backend interface {
+	list(ctx context.Context, root string) ([]file, error)
+	open(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+// file is a listed file before it is turned into an entry
+type file struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// Library is a remote music folder
+type Library struct {
+	URL     string // The folder's address, without any password
+	server  string // The address up to the path
+	root    string
+	backend backend
+}
+
+// IsRemote reports whether a song path is the address of a remote file
+// rather than a local path
+func IsRemote(path string) bool {
+	for _, scheme := range []string{"sftp://", "ssh://", "webdav://", "webdavs://", "http://", "https://"} {
+		if strings.HasPrefix(path, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// Open sets up a remote library from its address: sftp://[user@]host[:port]/path
+// for SSH, or webdav[s]://[user:password@]host/path (also http[s]://) for
+// WebDAV. Nothing is contacted until it is listed.
+func Open(rawURL string) (*Library, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote library %q: %w", rawURL, err)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("remote library %q has no host", rawURL)
+	}
+
+	root := "/" + strings.Trim(parsed.Path, "/")
+	library := &Library{root: root}
+	switch parsed.Scheme {
+	case "sftp", "ssh":
+		library.backend = &sshBackend{user: parsed.User.Username(), host: parsed.Hostname(), port: parsed.Port()}
+	case "webdav", "webdavs", "http", "https":
+		scheme := "https"
+		if parsed.Scheme == "webdav" || parsed.Scheme == "http" {
+			scheme = "http"
+		}
+		password, _ := parsed.User.Password()
+		library.backend = &webdavBackend{
+			base:     scheme + "://" + parsed.Host,
+			user:     parsed.User.Username(),
+			password: password,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported remote library %q, expected sftp:// or webdav://", rawURL)
+	}
+
+	// The password stays with the backend, out of song paths and the screen
+	if _, hasPassword := parsed.User.Password(); hasPassword {
+		parsed.User = url.User(parsed.User.Username())
+	}
+	parsed.Path, parsed.RawPath, parsed.RawQuery, parsed.Fragment = "", "", "", ""
+	library.server = parsed.String()
+	library.URL = library.songURL(strings.TrimSuffix(root, "/"))
+	return library, nil
+}
+
+// songURL is the address of a file in the library
+func (l *Library) songURL(path string) string {
+	return l.server + (&url.URL{Path: path}).EscapedPath()
+}
+
+// Contains reports whether a song address is in this library
+func (l *Library) Contains(songURL string) bool {
+	return strings.HasPrefix(songURL, l.URL+"/")
+}
+
+// List returns every file under the library folder
+func (l *Library) List(ctx context.Context) ([]Entry, error) {
+	files, err := l.backend.list(ctx, l.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", l.URL, err)
+	}
+
+	entries := make([]Entry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, Entry{URL: l.songURL(f.path), Size: f.size, ModTime: f.modTime})
+	}
+	return entries, nil
+}
+
+// Fetch opens a file in the library for reading
+func (l *Library) Fetch(ctx context.Context, songURL string) (io.ReadCloser, error) {
+	if !l.Contains(songURL) {
+		return nil, fmt.Errorf("%s is not in %s", songURL, l.URL)
+	}
+	parsed, err := url.Parse(songURL)
+	if err != nil {
+		return nil, err
+	}
+	return l.backend.open(ctx, parsed.Path)
+}
@@ -0,0 +1,118 @@
+package remotefs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sshBackend reads a library over SSH with the system ssh client, so keys,
+// agents and ~/.ssh/config work as they do in a terminal. The server needs
+// a POSIX shell with find and cat, as NAS systems have.
+type sshBackend struct {
+	user string
+	host string
+	port string
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// command builds an ssh command running remote on the server. BatchMode
+// keeps ssh from asking for a password in the middle of the player's screen.
+func (s *sshBackend) command(ctx context.Context, remote string) *exec.Cmd {
+	args := []string{"-o", "BatchMode=yes"}
+	if s.port != "" {
+		args = append(args, "-p", s.port)
+	}
+	target := s.host
+	if s.user != "" {
+		target = s.user + "@" + s.host
+	}
+	args = append(args, target, "--", remote)
+	return exec.CommandContext(ctx, "ssh", args...)
+}
+
+// list runs find on the server, one "size<TAB>mtime<TAB>path" line a file
+func (s *sshBackend) list(ctx context.Context, root string) ([]file, error) {
+	var stderr bytes.Buffer
+	cmd := s.command(ctx, "find "+shellQuote(root)+` -type f -printf '%s\t%T@\t%p\n'`)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, sshError(err, &stderr)
+	}
+	return parseFindOutput(output), nil
+}
+
+// parseFindOutput reads the lines list asks find for
+func parseFindOutput(output []byte) []file {
+	var files []file
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		seconds, _ := strconv.ParseFloat(fields[1], 64)
+		whole, fraction := math.Modf(seconds)
+		files = append(files, file{
+			path:    fields[2],
+			size:    size,
+			modTime: time.Unix(int64(whole), int64(fraction*1e9)).Truncate(time.Second),
+		})
+	}
+	return files
+}
+
+// open streams a file with cat
+func (s *sshBackend) open(ctx context.Context, path string) (io.ReadCloser, error) {
+	var stderr bytes.Buffer
+	cmd := s.command(ctx, "cat -- "+shellQuote(path))
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run ssh: %w", err)
+	}
+	return &sshReader{ReadCloser: stdout, cmd: cmd, stderr: &stderr}, nil
+}
+
+// sshReader is a file being read through ssh; closing it waits for ssh and
+// reports whether the whole file came through
+type sshReader struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+}
+
+func (r *sshReader) Close() error {
+	r.ReadCloser.Close()
+	if err := r.cmd.Wait(); err != nil {
+		return sshError(err, r.stderr)
+	}
+	return nil
+}
+
+// sshError adds what ssh printed to a failed run's error
+func sshError(err error, stderr *bytes.Buffer) error {
+	if message := strings.TrimSpace(stderr.String()); message != "" {
+		return fmt.Errorf("ssh: %s", message)
+	}
+	return fmt.Errorf("ssh: %w", err)
+}
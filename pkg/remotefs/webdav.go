@@ -0,0 +1,127 @@
+package remotefs
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// propfindBody asks a WebDAV server for what List needs about each file
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:"><d:prop><d:resourcetype/><d:getcontentlength/><d:getlastmodified/></d:prop></d:propfind>`
+
+// webdavBackend reads a library from a WebDAV server
+type webdavBackend struct {
+	base     string // scheme://host[:port]
+	user     string
+	password string
+	client   http.Client
+}
+
+// multistatus is a PROPFIND response
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				Collection    *struct{} `xml:"DAV: resourcetype>collection"`
+				ContentLength string    `xml:"DAV: getcontentlength"`
+				LastModified  string    `xml:"DAV: getlastmodified"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// request sends a request for path with the library's credentials
+func (w *webdavBackend) request(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, w.base+(&url.URL{Path: path}).EscapedPath(), body)
+	if err != nil {
+		return nil, err
+	}
+	if w.user != "" {
+		request.SetBasicAuth(w.user, w.password)
+	}
+	if method == "PROPFIND" {
+		request.Header.Set("Depth", "1")
+		request.Header.Set("Content-Type", "application/xml")
+	}
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode >= 300 {
+		response.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s", method, path, response.Status)
+	}
+	return response, nil
+}
+
+// list walks the folders under root one level at a time, since many
+// servers refuse "Depth: infinity"
+func (w *webdavBackend) list(ctx context.Context, root string) ([]file, error) {
+	var files []file
+	pending := []string{strings.TrimSuffix(root, "/") + "/"}
+	visited := make(map[string]bool)
+
+	for len(pending) > 0 {
+		dir := pending[0]
+		pending = pending[1:]
+		if visited[dir] {
+			continue
+		}
+		visited[dir] = true
+
+		response, err := w.request(ctx, "PROPFIND", dir, strings.NewReader(propfindBody))
+		if err != nil {
+			return nil, err
+		}
+		var status multistatus
+		err = xml.NewDecoder(response.Body).Decode(&status)
+		response.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid WebDAV response for %s: %w", dir, err)
+		}
+
+		for _, entry := range status.Responses {
+			path := entry.Href
+			if parsed, err := url.Parse(entry.Href); err == nil {
+				path = parsed.Path // Some servers give full URLs
+			}
+			if strings.TrimSuffix(path, "/") == strings.TrimSuffix(dir, "/") {
+				continue // The folder itself
+			}
+
+			for _, propstat := range entry.Propstat {
+				if !strings.Contains(propstat.Status, " 200 ") {
+					continue
+				}
+				prop := propstat.Prop
+				if prop.Collection != nil {
+					pending = append(pending, strings.TrimSuffix(path, "/")+"/")
+					break
+				}
+				size, _ := strconv.ParseInt(strings.TrimSpace(prop.ContentLength), 10, 64)
+				modTime, _ := http.ParseTime(strings.TrimSpace(prop.LastModified))
+				files = append(files, file{path: path, size: size, modTime: modTime})
+				break
+			}
+		}
+	}
+	return files, nil
+}
+
+// open downloads a file
+func (w *webdavBackend) open(ctx context.Context, path string) (io.ReadCloser, error) {
+	response, err := w.request(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
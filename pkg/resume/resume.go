@@ -0,0 +1,102 @@
+package resume
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/paths"
+)
+
+// Entry records where playback of a long track stopped
+type Entry struct {
+	Position time.Duration `json:"position"`
+	Updated  time.Time     `json:"updated"`
+}
+
+// Store remembers resume positions for long-form audio
+type Store struct {
+	path    string
+	mutex   sync.Mutex
+	entries map[string]Entry
+}
+
+// NewStore creates a resume store backed by resume.json in the data directory
+func NewStore() *Store {
+	store := &Store{
+		path:    paths.Data("resume.json"),
+		entries: make(map[string]Entry),
+	}
+	store.load()
+	return store
+}
+
+// load reads saved positions, starting empty if the file is missing or invalid
+func (s *Store) load() {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return
+	}
+	json.Unmarshal(data, &s.entries)
+}
+
+// save writes all positions to disk
+func (s *Store) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// Get returns the saved position for a song, or 0 if there is none
+func (s *Store) Get(songPath string) time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.entries[songPath].Position
+}
+
+// Set records the position for a song
+func (s *Store) Set(songPath string, position time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries[songPath] = Entry{
+		Position: position,
+		Updated:  time.Now(),
+	}
+	return s.save()
+}
+
+// Clear forgets the position for a song, e.g. once it has been played to the end
+func (s *Store) Clear(songPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.entries[songPath]; !ok {
+		return nil
+	}
+	delete(s.entries, songPath)
+	return s.save()
+}
+
+// Rename moves a saved position to a song's new path
+func (s *Store) Rename(oldPath, newPath string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.entries[oldPath]
+	if !ok {
+		return nil
+	}
+	delete(s.entries, oldPath)
+	s.entries[newPath] = entry
+	return s.save()
+}
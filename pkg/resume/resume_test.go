@@ -0,0 +1,51 @@
+package resume
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestStore opens a store kept in path, the way NewStore does
+func newTestStore(path string) *Store {
+	store := &Store{path: path, entries: make(map[string]Entry)}
+	store.load()
+	return store
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resume.json")
+	store := newTestStore(path)
+
+	if got := store.Get("/books/one.mp3"); got != 0 {
+		t.Errorf("Expected no position before one is set, got %v", got)
+	}
+	if err := store.Set("/books/one.mp3", 90*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	store.Set("/books/two.mp3", time.Hour)
+	store.Set("/books/one.mp3", 95*time.Second)
+	if got := store.Get("/books/one.mp3"); got != 95*time.Second {
+		t.Errorf("Expected the latest position, got %v", got)
+	}
+
+	if err := store.Clear("/books/two.mp3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Clear("/books/never.mp3"); err != nil {
+		t.Errorf("Clearing a song with no position: %v", err)
+	}
+	store.Rename("/books/one.mp3", "/books/Author/one.mp3")
+
+	// Positions survive a restart
+	again := newTestStore(path)
+	if got := again.Get("/books/Author/one.mp3"); got != 95*time.Second {
+		t.Errorf("Expected the renamed position to be saved, got %v", got)
+	}
+	if got := again.Get("/books/one.mp3"); got != 0 {
+		t.Errorf("Expected the old path forgotten, got %v", got)
+	}
+	if got := again.Get("/books/two.mp3"); got != 0 {
+		t.Errorf("Expected the cleared position to stay cleared, got %v", got)
+	}
+}
@@ -0,0 +1,204 @@
+// Package schedule stores alarms that start playback at a set time
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/paths"
+)
+
+// Alarm starts a playlist at a given time, ramping the volume up
+type Alarm struct {
+	ID       int           `json:"id"`
+	Next     time.Time     `json:"next"`               // When it goes off next
+	Daily    bool          `json:"daily,omitempty"`    // Repeat every day at the same time
+	Playlist string        `json:"playlist,omitempty"` // Empty plays the library
+	Volume   float64       `json:"volume"`             // Volume to ramp up to (0.0 to 1.0)
+	Ramp     time.Duration `json:"ramp,omitempty"`     // How long the ramp up takes
+}
+
+// Describe returns a one-line summary of the alarm
+func (a Alarm) Describe() string {
+	when := a.Next.Format("Mon 2 Jan 15:04")
+	if a.Daily {
+		when = "daily at " + a.Next.Format("15:04")
+	}
+	playlist := a.Playlist
+	if playlist == "" {
+		playlist = "library"
+	}
+	ramp := ""
+	if a.Ramp > 0 {
+		ramp = fmt.Sprintf(", ramp %s", a.Ramp)
+	}
+	return fmt.Sprintf("#%d %s - %s at %d%%%s", a.ID, when, playlist, int(a.Volume*100+0.5), ramp)
+}
+
+// Store is the alarm list, backed by a JSON file. Every call reads the file
+// again so alarms added from the command line reach a running app.
+type Store struct {
+	path   string
+	mutex  sync.Mutex
+	memory []Alarm // the alarms once they are kept in memory, nil until then
+}
+
+// NewStore creates an alarm store backed by alarms.json in the data directory
+func NewStore() *Store {
+	return &Store{path: paths.Data("alarms.json")}
+}
+
+// KeepInMemory stops changes from being written to disk: the alarms are
+// read once more and changed in memory from then on, for read-only mode
+func (s *Store) KeepInMemory() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.memory = append([]Alarm{}, s.load()...)
+}
+
+// load reads the alarms, returning none if the file is missing or invalid
+func (s *Store) load() []Alarm {
+	if s.memory != nil {
+		return append([]Alarm(nil), s.memory...)
+	}
+	var alarms []Alarm
+	if data, err := os.ReadFile(s.path); err == nil {
+		json.Unmarshal(data, &alarms)
+	}
+	return alarms
+}
+
+// save writes the alarms to disk, soonest first
+func (s *Store) save(alarms []Alarm) error {
+	sort.Slice(alarms, func(i, j int) bool { return alarms[i].Next.Before(alarms[j].Next) })
+	if s.memory != nil {
+		s.memory = append([]Alarm{}, alarms...)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(alarms, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// List returns all alarms, soonest first
+func (s *Store) List() []Alarm {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	alarms := s.load()
+	sort.Slice(alarms, func(i, j int) bool { return alarms[i].Next.Before(alarms[j].Next) })
+	return alarms
+}
+
+// Add saves a new alarm and returns it with its ID set
+func (s *Store) Add(alarm Alarm) (Alarm, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	alarms := s.load()
+	alarm.ID = 1
+	for _, existing := range alarms {
+		if existing.ID >= alarm.ID {
+			alarm.ID = existing.ID + 1
+		}
+	}
+	return alarm, s.save(append(alarms, alarm))
+}
+
+// Remove deletes an alarm
+func (s *Store) Remove(id int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	alarms := s.load()
+	for i, alarm := range alarms {
+		if alarm.ID == id {
+			return s.save(append(alarms[:i], alarms[i+1:]...))
+		}
+	}
+	return fmt.Errorf("alarm #%d not found", id)
+}
+
+// Due returns the alarms that have gone off by now. One-off alarms are
+// removed and daily ones moved to their next day; alarms missed by more than
+// grace (the app wasn't running) are skipped rather than returned.
+func (s *Store) Due(now time.Time, grace time.Duration) ([]Alarm, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	alarms := s.load()
+	var due, remaining []Alarm
+	changed := false
+	for _, alarm := range alarms {
+		if alarm.Next.After(now) {
+			remaining = append(remaining, alarm)
+			continue
+		}
+
+		changed = true
+		if now.Sub(alarm.Next) <= grace {
+			due = append(due, alarm)
+		}
+		if alarm.Daily {
+			for !alarm.Next.After(now) {
+				alarm.Next = alarm.Next.AddDate(0, 0, 1)
+			}
+			remaining = append(remaining, alarm)
+		}
+	}
+
+	if !changed {
+		return nil, nil
+	}
+	if remaining == nil {
+		remaining = []Alarm{}
+	}
+	return due, s.save(remaining)
+}
+
+// ParseTime reads "15:04" (the next time it comes round after now) or
+// "2006-01-02 15:04" in local time
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if at, err := time.ParseInLocation("2006-01-02 15:04", value, now.Location()); err == nil {
+		if !at.After(now) {
+			return time.Time{}, fmt.Errorf("%s is in the past", value)
+		}
+		return at, nil
+	}
+
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use HH:MM or YYYY-MM-DD HH:MM", value)
+	}
+	at := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !at.After(now) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at, nil
+}
+
+// RampVolume returns the volume elapsed into an alarm's ramp, rising from
+// start to the alarm's volume
+func (a Alarm) RampVolume(start float64, elapsed time.Duration) float64 {
+	if a.Ramp <= 0 || elapsed >= a.Ramp {
+		return a.Volume
+	}
+	if elapsed < 0 {
+		return start
+	}
+	return start + (a.Volume-start)*float64(elapsed)/float64(a.Ramp)
+}
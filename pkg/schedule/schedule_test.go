@@ -0,0 +1,82 @@
+package schedule
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 3, 10, 8, 0, 0, 0, time.Local)
+
+	at, err := ParseTime("07:30", now)
+	if err != nil || !at.Equal(time.Date(2024, 3, 11, 7, 30, 0, 0, time.Local)) {
+		t.Errorf("07:30 after 08:00 should be tomorrow, got %v, %v", at, err)
+	}
+	at, err = ParseTime("21:15", now)
+	if err != nil || !at.Equal(time.Date(2024, 3, 10, 21, 15, 0, 0, time.Local)) {
+		t.Errorf("21:15 should be today, got %v, %v", at, err)
+	}
+	if _, err := ParseTime("2024-03-09 10:00", now); err == nil {
+		t.Error("Expected an error for a date in the past")
+	}
+	if _, err := ParseTime("soon", now); err == nil {
+		t.Error("Expected an error for an invalid time")
+	}
+}
+
+func TestDue(t *testing.T) {
+	store := &Store{path: filepath.Join(t.TempDir(), "alarms.json")}
+	now := time.Date(2024, 3, 10, 7, 0, 0, 0, time.Local)
+
+	once, _ := store.Add(Alarm{Next: now.Add(time.Minute), Volume: 0.8})
+	daily, _ := store.Add(Alarm{Next: now.Add(time.Minute), Daily: true, Volume: 0.5})
+	store.Add(Alarm{Next: now.Add(-2 * time.Hour)}) // missed while the app was closed
+
+	due, err := store.Due(now.Add(90*time.Second), 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 2 || due[0].ID != once.ID || due[1].ID != daily.ID {
+		t.Fatalf("Expected both alarms due, got %+v", due)
+	}
+
+	alarms := store.List()
+	if len(alarms) != 1 || alarms[0].ID != daily.ID || !alarms[0].Next.Equal(daily.Next.AddDate(0, 0, 1)) {
+		t.Errorf("Expected only the daily alarm left, moved to tomorrow, got %+v", alarms)
+	}
+
+	if due, _ := store.Due(now.Add(2*time.Minute), 5*time.Minute); len(due) != 0 {
+		t.Errorf("Alarms went off twice: %+v", due)
+	}
+}
+
+func TestKeepInMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alarms.json")
+	now := time.Date(2024, 3, 10, 7, 0, 0, 0, time.Local)
+	(&Store{path: path}).Add(Alarm{Next: now.Add(time.Minute)})
+
+	store := &Store{path: path}
+	store.KeepInMemory()
+	if due, _ := store.Due(now.Add(2*time.Minute), 5*time.Minute); len(due) != 1 {
+		t.Fatalf("Expected the saved alarm to go off, got %+v", due)
+	}
+	store.Add(Alarm{Next: now.Add(time.Hour)})
+	if alarms := store.List(); len(alarms) != 1 || !alarms[0].Next.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected only the alarm added in memory, got %+v", alarms)
+	}
+
+	if alarms := (&Store{path: path}).List(); len(alarms) != 1 || !alarms[0].Next.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected the file untouched, got %+v", alarms)
+	}
+}
+
+func TestRampVolume(t *testing.T) {
+	alarm := Alarm{Volume: 0.8, Ramp: 4 * time.Minute}
+	if got := alarm.RampVolume(0, 2*time.Minute); got != 0.4 {
+		t.Errorf("Halfway through the ramp: got %v, want 0.4", got)
+	}
+	if got := alarm.RampVolume(0, 10*time.Minute); got != 0.8 {
+		t.Errorf("After the ramp: got %v, want 0.8", got)
+	}
+}
@@ -0,0 +1,22 @@
+package songcache
+
+import "syscall"
+
+// networkFilesystems are the statfs magic numbers of network filesystems
+var networkFilesystems = map[uint32]bool{
+	0x6969:     true, // NFS
+	0x517b:     true, // SMB
+	0xff534d42: true, // CIFS
+	0xfe534d42: true, // SMB2
+	0x65735546: true, // FUSE, e.g. sshfs
+	0x564c:     true, // NCP
+}
+
+// IsNetworkPath reports whether path is on a network filesystem
+func IsNetworkPath(path string) bool {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return false
+	}
+	return networkFilesystems[uint32(stat.Type)]
+}
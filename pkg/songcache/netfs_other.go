@@ -0,0 +1,9 @@
+//go:build !linux
+
+package songcache
+
+// IsNetworkPath reports whether path is on a network filesystem. Outside
+// Linux this isn't detected, so set the song cache to "always" for shares.
+func IsNetworkPath(path string) bool {
+	return false
+}
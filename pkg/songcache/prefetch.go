@@ -0,0 +1,67 @@
+package songcache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Prefetcher reads the song expected to play next into memory, so starting
+// it doesn't wait on a slow disk or share. It holds one song at a time.
+type Prefetcher struct {
+	mutex   sync.Mutex
+	src     string
+	size    int64
+	modTime time.Time
+	data    []byte
+}
+
+// Prefetch reads src into memory in place of the song held before. Songs
+// bigger than limit aren't read.
+func (p *Prefetcher) Prefetch(src string, limit int64) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if info.Size() > limit {
+		return fmt.Errorf("%s is bigger than the prefetch limit", filepath.Base(src))
+	}
+
+	p.mutex.Lock()
+	if p.src == src && p.size == info.Size() && p.modTime.Equal(info.ModTime()) {
+		p.mutex.Unlock()
+		return nil
+	}
+	p.mutex.Unlock()
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.src, p.size, p.modTime, p.data = src, info.Size(), info.ModTime(), data
+	return nil
+}
+
+// Take returns the prefetched contents of src and lets them go, if src was
+// prefetched and hasn't changed since
+func (p *Prefetcher) Take(src string) ([]byte, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.src != src || p.data == nil {
+		return nil, false
+	}
+	data := p.data
+	p.src, p.data = "", nil
+
+	info, err := os.Stat(src)
+	if err != nil || info.Size() != p.size || !info.ModTime().Equal(p.modTime) {
+		return nil, false
+	}
+	return data, true
+}
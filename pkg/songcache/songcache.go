@@ -0,0 +1,232 @@
+// Package songcache keeps local copies of songs from slow libraries, such as
+// ones on NFS or SMB shares, so only the first play reads over the network.
+// The same size-limited store also holds other files made from songs, such as
+// decoded audio.
+package songcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Cache is a size-limited directory of song copies, or other entries made
+// from songs. The least recently used entries are removed first when it is
+// full.
+type Cache struct {
+	dir     string
+	limit   int64
+	mutex   sync.Mutex
+	copying map[string]bool // Entries being written now
+}
+
+// New creates a cache in dir holding at most limit bytes
+func New(dir string, limit int64) *Cache {
+	return &Cache{dir: dir, limit: limit, copying: make(map[string]bool)}
+}
+
+// key names the copy of src. It includes the size and modification time, so
+// a changed song gets a fresh copy and the stale one ages out.
+func key(src string, info os.FileInfo) string {
+	abs, err := filepath.Abs(src)
+	if err != nil {
+		abs = src
+	}
+	sum := sha256.Sum256([]byte(abs + "\x00" + strconv.FormatInt(info.Size(), 10) +
+		"\x00" + strconv.FormatInt(info.ModTime().UnixNano(), 10)))
+	return hex.EncodeToString(sum[:12]) + filepath.Ext(src)
+}
+
+// Lookup returns the local copy of src if there is an up to date one,
+// marking it as just used
+func (c *Cache) Lookup(src string) (string, bool) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", false
+	}
+	path, ok := c.Get(key(src, info))
+	if !ok {
+		return "", false
+	}
+	if cached, err := os.Stat(path); err != nil || cached.Size() != info.Size() {
+		return "", false
+	}
+	return path, true
+}
+
+// Get returns the path of the entry called name if the cache has it,
+// marking it as just used
+func (c *Cache) Get(name string) (string, bool) {
+	path := filepath.Join(c.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return path, true
+}
+
+// Add copies src into the cache, then removes the least recently used
+// copies until the cache fits its limit. Songs bigger than the whole cache
+// aren't copied.
+func (c *Cache) Add(src string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if info.Size() > c.limit {
+		return fmt.Errorf("%s is bigger than the song cache", filepath.Base(src))
+	}
+
+	return c.Put(key(src, info), func(w io.Writer) error {
+		in, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		if _, err := io.Copy(w, in); err != nil {
+			return fmt.Errorf("failed to cache %s: %w", filepath.Base(src), err)
+		}
+		return nil
+	})
+}
+
+// Put stores an entry called name, written by write, unless the cache has
+// it already. The least recently used entries are then removed until the
+// cache fits its limit.
+func (c *Cache) Put(name string, write func(io.Writer) error) error {
+	c.mutex.Lock()
+	if c.copying[name] {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.copying[name] = true
+	c.mutex.Unlock()
+	defer func() {
+		c.mutex.Lock()
+		delete(c.copying, name)
+		c.mutex.Unlock()
+	}()
+
+	path := filepath.Join(c.dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := writeFile(path, write); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.evict()
+}
+
+// writeFile writes dst through a temporary file, so an entry cut short is
+// never mistaken for a cached one
+func writeFile(dst string, write func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.CreateTemp(filepath.Dir(dst), ".copy-*")
+	if err != nil {
+		return err
+	}
+	if err := write(out); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(out.Name())
+		return err
+	}
+	return os.Rename(out.Name(), dst)
+}
+
+// cachedFile is a copy in the cache directory
+type cachedFile struct {
+	path    string
+	size    int64
+	lastUse time.Time
+}
+
+// files lists the copies in the cache, least recently used first
+func (c *Cache) files() []cachedFile {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil
+	}
+
+	var files []cachedFile
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || entry.Name()[0] == '.' {
+			continue
+		}
+		files = append(files, cachedFile{
+			path:    filepath.Join(c.dir, entry.Name()),
+			size:    info.Size(),
+			lastUse: info.ModTime(),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].lastUse.Before(files[j].lastUse)
+	})
+	return files
+}
+
+// evict removes the least recently used copies until the cache fits its
+// limit (caller must hold the mutex)
+func (c *Cache) evict() error {
+	files := c.files()
+	var total int64
+	for _, file := range files {
+		total += file.size
+	}
+	for _, file := range files {
+		if total <= c.limit {
+			break
+		}
+		if err := os.Remove(file.path); err != nil {
+			return err
+		}
+		total -= file.size
+	}
+	return nil
+}
+
+// Usage returns how many songs the cache holds and their total size
+func (c *Cache) Usage() (int, int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	files := c.files()
+	var total int64
+	for _, file := range files {
+		total += file.size
+	}
+	return len(files), total
+}
+
+// Clear removes every copy from the cache
+func (c *Cache) Clear() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, file := range c.files() {
+		if err := os.Remove(file.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
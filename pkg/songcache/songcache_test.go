@@ -0,0 +1,148 @@
+package songcache
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeSong(t *testing.T, dir, name string, size int) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCacheLookupAndAdd(t *testing.T) {
+	library := t.TempDir()
+	cache := New(filepath.Join(t.TempDir(), "songs"), 1000)
+	song := writeSong(t, library, "song.mp3", 100)
+
+	if _, ok := cache.Lookup(song); ok {
+		t.Fatal("Expected a miss before the song is cached")
+	}
+	if err := cache.Add(song); err != nil {
+		t.Fatal(err)
+	}
+	path, ok := cache.Lookup(song)
+	if !ok || filepath.Ext(path) != ".mp3" {
+		t.Fatalf("Expected a cached .mp3 copy, got %q %v", path, ok)
+	}
+	if count, size := cache.Usage(); count != 1 || size != 100 {
+		t.Errorf("Expected 1 song of 100 bytes, got %d of %d", count, size)
+	}
+
+	// A changed song isn't served from the old copy
+	os.WriteFile(song, make([]byte, 150), 0644)
+	if _, ok := cache.Lookup(song); ok {
+		t.Error("Expected a changed song to miss the cache")
+	}
+
+	// Songs bigger than the cache aren't copied
+	big := writeSong(t, library, "big.flac", 2000)
+	if err := cache.Add(big); err == nil {
+		t.Error("Expected an error caching a song bigger than the cache")
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	library := t.TempDir()
+	cache := New(t.TempDir(), 250)
+	first := writeSong(t, library, "first.mp3", 100)
+	second := writeSong(t, library, "second.mp3", 100)
+	third := writeSong(t, library, "third.mp3", 100)
+
+	cache.Add(first)
+	cache.Add(second)
+
+	// Make first older, then play it so second is the least recently used
+	old := time.Now().Add(-time.Hour)
+	for _, file := range cache.files() {
+		os.Chtimes(file.path, old, old)
+	}
+	cache.Lookup(first)
+
+	if err := cache.Add(third); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Lookup(second); ok {
+		t.Error("Expected the least recently used song to be evicted")
+	}
+	for _, song := range []string{first, third} {
+		if _, ok := cache.Lookup(song); !ok {
+			t.Errorf("Expected %s to stay cached", filepath.Base(song))
+		}
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := cache.Usage(); count != 0 {
+		t.Errorf("Expected an empty cache after clearing, got %d songs", count)
+	}
+}
+
+func TestPrefetcher(t *testing.T) {
+	library := t.TempDir()
+	song := writeSong(t, library, "next.mp3", 100)
+	other := writeSong(t, library, "other.mp3", 100)
+
+	var prefetcher Prefetcher
+	if err := prefetcher.Prefetch(song, 50); err == nil {
+		t.Error("Expected an error prefetching a song over the limit")
+	}
+	if err := prefetcher.Prefetch(song, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := prefetcher.Take(other); ok {
+		t.Error("Expected no data for a song that wasn't prefetched")
+	}
+	data, ok := prefetcher.Take(song)
+	if !ok || len(data) != 100 {
+		t.Fatalf("Expected 100 prefetched bytes, got %d %v", len(data), ok)
+	}
+	if _, ok := prefetcher.Take(song); ok {
+		t.Error("Expected prefetched data to be handed out once")
+	}
+
+	// A song changed after it was read isn't served from memory
+	prefetcher.Prefetch(song, 1000)
+	os.WriteFile(song, make([]byte, 150), 0644)
+	if _, ok := prefetcher.Take(song); ok {
+		t.Error("Expected a changed song to miss the prefetch")
+	}
+}
+
+func TestCachePutAndGet(t *testing.T) {
+	cache := New(t.TempDir(), 1000)
+
+	if _, ok := cache.Get("song.pcm"); ok {
+		t.Fatal("Expected a miss before anything is stored")
+	}
+	err := cache.Put("song.pcm", func(w io.Writer) error {
+		_, err := w.Write(make([]byte, 100))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get("song.pcm"); !ok {
+		t.Error("Expected the stored entry to be found")
+	}
+
+	// A failed write leaves nothing behind
+	cache.Put("broken.pcm", func(w io.Writer) error {
+		return errors.New("decode failed")
+	})
+	if _, ok := cache.Get("broken.pcm"); ok {
+		t.Error("Expected a failed write not to be cached")
+	}
+	if count, _ := cache.Usage(); count != 1 {
+		t.Errorf("Expected 1 entry, got %d", count)
+	}
+}
@@ -0,0 +1,136 @@
+package spotify
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+)
+
+// minTitleSimilarity is how close two normalized titles must be, from 0 to 1,
+// to count as the same song
+const minTitleSimilarity = 0.85
+
+// Result is a playlist with its tracks found in the local library
+type Result struct {
+	Name      string
+	Songs     []string // Local paths, in playlist order
+	Unmatched []Track
+}
+
+// versionSuffix matches the parts of a title that differ between releases of
+// the same song, such as "(Remastered 2011)", "[Live]" or "- Radio Edit"
+var versionSuffix = regexp.MustCompile(`\s*(\([^)]*\)|\[[^\]]*\]|\s-\s.*)`)
+
+// featuring matches a featured artist credit in a title
+var featuring = regexp.MustCompile(`(?i)\s*\(?\b(feat|ft|featuring)\b\.?.*$`)
+
+// normalize lowercases s and keeps only letters and digits, single spaced
+func normalize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, strings.ReplaceAll(s, "&", " and "))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// normalizeTitle drops version and featuring notes before normalizing
+func normalizeTitle(title string) string {
+	stripped := featuring.ReplaceAllString(versionSuffix.ReplaceAllString(title, ""), "")
+	if normalized := normalize(stripped); normalized != "" {
+		return normalized
+	}
+	return normalize(title)
+}
+
+// splitArtists breaks a credit such as "Queen, David Bowie" or "A; B" into
+// normalized names
+func splitArtists(credit string) []string {
+	var artists []string
+	for _, name := range strings.FieldsFunc(credit, func(r rune) bool { return r == ',' || r == ';' }) {
+		if name = normalize(name); name != "" {
+			artists = append(artists, name)
+		}
+	}
+	return artists
+}
+
+// artistMatches reports whether any of a track's artists is the local
+// song's artist or part of its credit. Songs with no artist tag match any.
+func artistMatches(track Track, local string) bool {
+	local = normalize(local)
+	if local == "" || local == "unknown artist" {
+		return true
+	}
+	for _, artist := range splitArtists(track.Artist) {
+		if artist == local || strings.Contains(local, artist) || strings.Contains(artist, local) {
+			return true
+		}
+	}
+	return len(splitArtists(track.Artist)) == 0
+}
+
+// similarity is 1 minus the edit distance between a and b relative to the
+// longer one
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return 1 - float64(previous[len(rb)])/float64(longest)
+}
+
+// MatchTrack finds the local song most like track: the artist must agree
+// and the title be close enough once version notes are dropped. It returns
+// "" when nothing is close.
+func MatchTrack(track Track, songs []*metadata.SongMetadata) string {
+	title := normalizeTitle(track.Title)
+	best, bestScore := "", 0.0
+	for _, song := range songs {
+		if !artistMatches(track, song.Artist) {
+			continue
+		}
+		score := similarity(title, normalizeTitle(song.Title))
+		if score >= minTitleSimilarity && score > bestScore {
+			best, bestScore = song.Path, score
+		}
+	}
+	return best
+}
+
+// Match finds each of the playlist's tracks in the local songs
+func Match(playlist Playlist, songs []*metadata.SongMetadata) Result {
+	result := Result{Name: playlist.Name}
+	for _, track := range playlist.Tracks {
+		if path := MatchTrack(track, songs); path != "" {
+			result.Songs = append(result.Songs, path)
+		} else {
+			result.Unmatched = append(result.Unmatched, track)
+		}
+	}
+	return result
+}
@@ -0,0 +1,195 @@
+// Package spotify reads Spotify playlist exports and matches their tracks to
+// songs in the local library
+package spotify
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Track is one entry of an exported playlist
+type Track struct {
+	Title  string
+	Artist string // All artists, comma separated
+	Album  string
+}
+
+// Playlist is an exported playlist, its tracks in order
+type Playlist struct {
+	Name   string
+	Tracks []Track
+}
+
+// Open reads a playlist export, picking the format from the extension. A CSV
+// export holds one playlist, named after the file.
+func Open(path string) ([]Playlist, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		playlist, err := ParseCSV(file, name)
+		if err != nil {
+			return nil, err
+		}
+		return []Playlist{*playlist}, nil
+	case ".json":
+		return ParseJSON(file)
+	default:
+		return nil, fmt.Errorf("unsupported playlist export %q, expected .csv or .json", filepath.Base(path))
+	}
+}
+
+// csvColumns are the header names tried for each field, as written by
+// Exportify and similar tools
+var csvColumns = map[string][]string{
+	"title":  {"track name", "track", "title", "name", "song"},
+	"artist": {"artist name(s)", "artist name", "artists", "artist"},
+	"album":  {"album name", "album"},
+}
+
+// ParseCSV reads a CSV export with a header row
+func ParseCSV(r io.Reader, name string) (*Playlist, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read playlist: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for field, names := range csvColumns {
+		columns[field] = -1
+		for _, candidate := range names {
+			for i, column := range header {
+				if strings.EqualFold(strings.TrimSpace(column), candidate) && columns[field] < 0 {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	if columns["title"] < 0 {
+		return nil, fmt.Errorf("no track name column in the playlist")
+	}
+
+	cell := func(record []string, field string) string {
+		if i := columns[field]; i >= 0 && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	playlist := &Playlist{Name: name}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read playlist: %w", err)
+		}
+		track := Track{
+			Title:  cell(record, "title"),
+			Artist: cell(record, "artist"),
+			Album:  cell(record, "album"),
+		}
+		if track.Title != "" {
+			playlist.Tracks = append(playlist.Tracks, track)
+		}
+	}
+	return playlist, nil
+}
+
+// accountExport is Playlist1.json from a Spotify account data download
+type accountExport struct {
+	Playlists []struct {
+		Name  string `json:"name"`
+		Items []struct {
+			Track *struct {
+				TrackName  string `json:"trackName"`
+				ArtistName string `json:"artistName"`
+				AlbumName  string `json:"albumName"`
+			} `json:"track"`
+		} `json:"items"`
+	} `json:"playlists"`
+}
+
+// apiPlaylist is a playlist as the Spotify Web API returns it
+type apiPlaylist struct {
+	Name   string `json:"name"`
+	Tracks struct {
+		Items []struct {
+			Track *struct {
+				Name    string `json:"name"`
+				Artists []struct {
+					Name string `json:"name"`
+				} `json:"artists"`
+				Album struct {
+					Name string `json:"name"`
+				} `json:"album"`
+			} `json:"track"`
+		} `json:"items"`
+	} `json:"tracks"`
+}
+
+// ParseJSON reads the playlists from an account data download or a single
+// playlist saved from the Web API. Podcast episodes and local files Spotify
+// couldn't describe are left out.
+func ParseJSON(r io.Reader) ([]Playlist, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var account accountExport
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("invalid playlist export: %w", err)
+	}
+	if len(account.Playlists) > 0 {
+		playlists := make([]Playlist, 0, len(account.Playlists))
+		for _, exported := range account.Playlists {
+			playlist := Playlist{Name: exported.Name}
+			for _, item := range exported.Items {
+				if item.Track != nil && item.Track.TrackName != "" {
+					playlist.Tracks = append(playlist.Tracks, Track{
+						Title:  item.Track.TrackName,
+						Artist: item.Track.ArtistName,
+						Album:  item.Track.AlbumName,
+					})
+				}
+			}
+			playlists = append(playlists, playlist)
+		}
+		return playlists, nil
+	}
+
+	var api apiPlaylist
+	if err := json.Unmarshal(data, &api); err != nil || api.Name == "" {
+		return nil, fmt.Errorf("not a Spotify playlist export")
+	}
+	playlist := Playlist{Name: api.Name}
+	for _, item := range api.Tracks.Items {
+		if item.Track == nil || item.Track.Name == "" {
+			continue
+		}
+		var artists []string
+		for _, artist := range item.Track.Artists {
+			artists = append(artists, artist.Name)
+		}
+		playlist.Tracks = append(playlist.Tracks, Track{
+			Title:  item.Track.Name,
+			Artist: strings.Join(artists, ", "),
+			Album:  item.Track.Album.Name,
+		})
+	}
+	return []Playlist{playlist}, nil
+}
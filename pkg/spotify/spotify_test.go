@@ -0,0 +1,75 @@
+package spotify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+)
+
+func TestParseCSV(t *testing.T) {
+	export := "Track URI,Track Name,Artist Name(s),Album Name\n" +
+		"spotify:track:1,Bohemian Rhapsody - Remastered 2011,Queen,A Night at the Opera\n" +
+		"spotify:track:2,Under Pressure,\"Queen, David Bowie\",Hot Space\n" +
+		"spotify:track:3,,Nobody,\n"
+
+	playlist, err := ParseCSV(strings.NewReader(export), "Road Trip")
+	if err != nil {
+		t.Fatalf("ParseCSV() error: %v", err)
+	}
+	if playlist.Name != "Road Trip" || len(playlist.Tracks) != 2 {
+		t.Fatalf("Expected two tracks in Road Trip, got %+v", playlist)
+	}
+	if track := playlist.Tracks[1]; track.Title != "Under Pressure" || track.Artist != "Queen, David Bowie" || track.Album != "Hot Space" {
+		t.Errorf("Unexpected track: %+v", track)
+	}
+
+	if _, err := ParseCSV(strings.NewReader("a,b\n1,2\n"), "x"); err == nil {
+		t.Error("Expected an error without a track name column")
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	account := `{"playlists":[{"name":"Karaoke","items":[
+		{"track":{"trackName":"Africa","artistName":"Toto","albumName":"Toto IV"}},
+		{"track":null,"episode":{"episodeName":"A podcast"}}]}]}`
+	playlists, err := ParseJSON(strings.NewReader(account))
+	if err != nil || len(playlists) != 1 || len(playlists[0].Tracks) != 1 || playlists[0].Tracks[0].Artist != "Toto" {
+		t.Errorf("Unexpected account export: %+v %v", playlists, err)
+	}
+
+	api := `{"name":"Duets","tracks":{"items":[{"track":{"name":"Under Pressure",
+		"artists":[{"name":"Queen"},{"name":"David Bowie"}],"album":{"name":"Hot Space"}}}]}}`
+	playlists, err = ParseJSON(strings.NewReader(api))
+	if err != nil || len(playlists) != 1 || playlists[0].Tracks[0].Artist != "Queen, David Bowie" {
+		t.Errorf("Unexpected API playlist: %+v %v", playlists, err)
+	}
+
+	if _, err := ParseJSON(strings.NewReader(`{"other":1}`)); err == nil {
+		t.Error("Expected an error for JSON that isn't a playlist")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	songs := []*metadata.SongMetadata{
+		{Path: "/music/queen/bohemian.mp3", Title: "Bohemian Rhapsody", Artist: "Queen"},
+		{Path: "/music/bowie/pressure.mp3", Title: "Under Presure", Artist: "David Bowie"},
+		{Path: "/music/cover/bohemian.mp3", Title: "Bohemian Rhapsody", Artist: "Panic! At The Disco"},
+		{Path: "/music/untagged.mp3", Title: "Dont Stop Me Now", Artist: "Unknown Artist"},
+	}
+	playlist := Playlist{Name: "Queen", Tracks: []Track{
+		{Title: "Bohemian Rhapsody - Remastered 2011", Artist: "Queen"},
+		{Title: "Under Pressure", Artist: "Queen, David Bowie"},
+		{Title: "Don't Stop Me Now (feat. Nobody)", Artist: "Queen"},
+		{Title: "Radio Ga Ga", Artist: "Queen"},
+	}}
+
+	result := Match(playlist, songs)
+	want := []string{"/music/queen/bohemian.mp3", "/music/bowie/pressure.mp3", "/music/untagged.mp3"}
+	if strings.Join(result.Songs, ",") != strings.Join(want, ",") {
+		t.Errorf("Songs = %v, want %v", result.Songs, want)
+	}
+	if len(result.Unmatched) != 1 || result.Unmatched[0].Title != "Radio Ga Ga" {
+		t.Errorf("Expected only Radio Ga Ga unmatched, got %+v", result.Unmatched)
+	}
+}
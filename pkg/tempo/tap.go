@@ -0,0 +1,72 @@
+package tempo
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	minTaps   = 4
+	maxTaps   = 16
+	tapWindow = 2 * time.Second // a longer gap starts a new tempo
+)
+
+// Tapper turns taps at playback positions into a tempo
+type Tapper struct {
+	taps []time.Duration
+}
+
+// Tap records a tap at a playback position and returns the tempo once
+// enough taps have been made
+func (t *Tapper) Tap(position time.Duration) (Estimate, bool) {
+	if n := len(t.taps); n > 0 {
+		gap := position - t.taps[n-1]
+		if gap <= 0 || gap > tapWindow {
+			t.taps = t.taps[:0]
+		}
+	}
+	t.taps = append(t.taps, position)
+	if len(t.taps) > maxTaps {
+		t.taps = t.taps[len(t.taps)-maxTaps:]
+	}
+
+	if len(t.taps) < minTaps {
+		return Estimate{}, false
+	}
+	return t.estimate(), true
+}
+
+// Count returns the number of taps towards the current tempo
+func (t *Tapper) Count() int {
+	return len(t.taps)
+}
+
+// Reset forgets all taps
+func (t *Tapper) Reset() {
+	t.taps = t.taps[:0]
+}
+
+// estimate fits a straight beat grid through the taps by least squares
+func (t *Tapper) estimate() Estimate {
+	n := float64(len(t.taps))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, tap := range t.taps {
+		x, y := float64(i), tap.Seconds()
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	period := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	intercept := (sumY - period*sumX) / n
+
+	// Report the first beat of the grid within the song
+	offset := math.Mod(intercept, period)
+	if offset < 0 {
+		offset += period
+	}
+	return Estimate{
+		BPM:    60 / period,
+		Offset: time.Duration(offset * float64(time.Second)),
+	}
+}
@@ -0,0 +1,146 @@
+// Package tempo estimates the beat of a song, either from the audio or from
+// the user tapping along
+package tempo
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/player"
+)
+
+// ErrNoTempo is returned when no steady beat can be found
+var ErrNoTempo = errors.New("no steady beat found")
+
+// Estimate is a tempo and the position of a beat to line the metronome up with
+type Estimate struct {
+	BPM    float64
+	Offset time.Duration
+}
+
+const (
+	minBPM      = 60
+	maxBPM      = 200
+	hopSize     = 512
+	maxAnalysis = 120 * time.Second
+)
+
+// DetectFile decodes up to the first two minutes of an audio file and
+// estimates its tempo
+func DetectFile(path string) (Estimate, error) {
+	streamer, format, err := player.Decode(path)
+	if err != nil {
+		return Estimate{}, err
+	}
+	defer streamer.Close()
+
+	limit := format.SampleRate.N(maxAnalysis)
+	samples := make([]float64, 0, limit)
+	buf := make([][2]float64, 4096)
+	for len(samples) < limit {
+		n, ok := streamer.Stream(buf)
+		for _, frame := range buf[:n] {
+			samples = append(samples, (frame[0]+frame[1])/2)
+		}
+		if !ok {
+			break
+		}
+	}
+	if err := streamer.Err(); err != nil {
+		return Estimate{}, err
+	}
+
+	return Detect(samples, int(format.SampleRate))
+}
+
+// Detect estimates the tempo of mono samples by autocorrelating an onset
+// envelope, then finds the beat phase that lines up with the most onsets
+func Detect(samples []float64, sampleRate int) (Estimate, error) {
+	onsets := onsetEnvelope(samples)
+	framesPerSecond := float64(sampleRate) / hopSize
+
+	minLag := int(math.Floor(60 * framesPerSecond / maxBPM))
+	maxLag := int(math.Ceil(60 * framesPerSecond / minBPM))
+	if minLag < 1 || len(onsets) < 4*maxLag {
+		return Estimate{}, ErrNoTempo
+	}
+
+	correlation := make([]float64, maxLag+2)
+	for lag := minLag - 1; lag <= maxLag+1; lag++ {
+		sum := 0.0
+		for i := 0; i+lag < len(onsets); i++ {
+			sum += onsets[i] * onsets[i+lag]
+		}
+		correlation[lag] = sum / float64(len(onsets)-lag)
+	}
+
+	best, bestScore := 0, 0.0
+	for lag := minLag; lag <= maxLag; lag++ {
+		// Favour tempos near 120 BPM so half- and double-time peaks lose ties
+		bpm := 60 * framesPerSecond / float64(lag)
+		weight := math.Exp(-0.5 * math.Pow(math.Log2(bpm/120), 2))
+		if score := correlation[lag] * weight; score > bestScore {
+			best, bestScore = lag, score
+		}
+	}
+	if best == 0 {
+		return Estimate{}, ErrNoTempo
+	}
+
+	// Refine the period and find the phase together: the grid that collects
+	// the most onset strength wins
+	period, phase, bestScore := float64(best), 0.0, -1.0
+	for candidate := float64(best) - 1; candidate <= float64(best)+1; candidate += 0.02 {
+		candidatePhase, score := bestPhase(onsets, candidate)
+		if score > bestScore {
+			period, phase, bestScore = candidate, candidatePhase, score
+		}
+	}
+
+	// An onset lands somewhere inside its hop; take the middle
+	phase += 0.5
+	return Estimate{
+		BPM:    60 * framesPerSecond / period,
+		Offset: time.Duration(phase / framesPerSecond * float64(time.Second)),
+	}, nil
+}
+
+// onsetEnvelope returns the rise in energy from one hop to the next
+func onsetEnvelope(samples []float64) []float64 {
+	hops := len(samples) / hopSize
+	if hops < 2 {
+		return nil
+	}
+
+	energy := make([]float64, hops)
+	for h := range energy {
+		sum := 0.0
+		for _, sample := range samples[h*hopSize : (h+1)*hopSize] {
+			sum += sample * sample
+		}
+		energy[h] = math.Log(1e-6 + sum/hopSize)
+	}
+
+	onsets := make([]float64, hops)
+	for h := 1; h < hops; h++ {
+		onsets[h] = math.Max(0, energy[h]-energy[h-1])
+	}
+	return onsets
+}
+
+// bestPhase returns the offset within one period, in envelope frames, whose
+// beat grid collects the most onset strength, along with that strength
+func bestPhase(onsets []float64, period float64) (float64, float64) {
+	best, bestScore := 0.0, -1.0
+	for phase := 0.0; phase < period; phase += 0.1 {
+		score := 0.0
+		for t := phase; int(math.Round(t)) < len(onsets); t += period {
+			score += onsets[int(math.Round(t))]
+		}
+		if score > bestScore {
+			best, bestScore = phase, score
+		}
+	}
+	return best, bestScore / math.Ceil(float64(len(onsets))/period)
+}
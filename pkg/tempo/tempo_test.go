@@ -0,0 +1,65 @@
+package tempo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDetect(t *testing.T) {
+	const sampleRate = 22050
+	const bpm = 100
+	offset := 300 * time.Millisecond
+
+	// 30 seconds of short noise bursts on every beat
+	samples := make([]float64, 30*sampleRate)
+	beat := 60.0 / bpm
+	for start := offset.Seconds(); start < 30; start += beat {
+		first := int(start * sampleRate)
+		for i := 0; i < sampleRate/50 && first+i < len(samples); i++ {
+			samples[first+i] = math.Sin(float64(i)*0.7) * math.Exp(-float64(i)/200)
+		}
+	}
+
+	estimate, err := Detect(samples, sampleRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(estimate.BPM-bpm) > 1 {
+		t.Errorf("got %.1f BPM, want %d", estimate.BPM, bpm)
+	}
+	if diff := estimate.Offset - offset; diff < -30*time.Millisecond || diff > 30*time.Millisecond {
+		t.Errorf("got offset %v, want about %v", estimate.Offset, offset)
+	}
+}
+
+func TestDetectSilence(t *testing.T) {
+	if _, err := Detect(make([]float64, 10*22050), 22050); err != ErrNoTempo {
+		t.Errorf("got %v, want ErrNoTempo", err)
+	}
+}
+
+func TestTapper(t *testing.T) {
+	var tapper Tapper
+	for i, position := range []time.Duration{10500, 11000, 11510, 11990} {
+		estimate, ok := tapper.Tap(position * time.Millisecond)
+		if ok != (i == 3) {
+			t.Fatalf("tap %d: ok = %v", i, ok)
+		}
+		if ok {
+			if math.Abs(estimate.BPM-120) > 1 {
+				t.Errorf("got %.1f BPM, want 120", estimate.BPM)
+			}
+			// The beat grid should pass through the first tap
+			beats := (10.5 - estimate.Offset.Seconds()) * estimate.BPM / 60
+			if math.Abs(beats-math.Round(beats)) > 0.05 {
+				t.Errorf("offset %v puts the first tap %.2f beats off the grid", estimate.Offset, beats-math.Round(beats))
+			}
+		}
+	}
+
+	// A long pause starts over
+	if _, ok := tapper.Tap(20 * time.Second); ok || tapper.Count() != 1 {
+		t.Errorf("expected taps to reset after a pause, have %d", tapper.Count())
+	}
+}
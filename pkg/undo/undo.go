@@ -0,0 +1,114 @@
+// Package undo keeps a history of edits that can be undone and redone, so a
+// slip during a live event is one key press away from being fixed
+package undo
+
+import (
+	"errors"
+	"os"
+	"sync"
+)
+
+// Action is an edit that has been made, with how to take it back and how to
+// make it again
+type Action struct {
+	Description string
+	Undo        func() error
+	Redo        func() error
+}
+
+// Stack holds the edits made, most recent last, and the ones undone since
+type Stack struct {
+	mutex  sync.Mutex
+	limit  int
+	done   []Action
+	undone []Action
+}
+
+// NewStack creates a stack remembering at most limit edits
+func NewStack(limit int) *Stack {
+	return &Stack{limit: limit}
+}
+
+// Push records an edit that was just made. Anything undone before can no
+// longer be redone.
+func (s *Stack) Push(action Action) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.done = append(s.done, action)
+	if len(s.done) > s.limit {
+		s.done = s.done[len(s.done)-s.limit:]
+	}
+	s.undone = nil
+}
+
+// Undo takes back the most recent edit. If that fails, the edit stays where
+// it was so it can be tried again.
+func (s *Stack) Undo() (Action, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.done) == 0 {
+		return Action{}, errors.New("nothing to undo")
+	}
+	action := s.done[len(s.done)-1]
+	if err := action.Undo(); err != nil {
+		return action, err
+	}
+	s.done = s.done[:len(s.done)-1]
+	s.undone = append(s.undone, action)
+	return action, nil
+}
+
+// Redo makes the most recently undone edit again
+func (s *Stack) Redo() (Action, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.undone) == 0 {
+		return Action{}, errors.New("nothing to redo")
+	}
+	action := s.undone[len(s.undone)-1]
+	if err := action.Redo(); err != nil {
+		return action, err
+	}
+	s.undone = s.undone[:len(s.undone)-1]
+	s.done = append(s.done, action)
+	return action, nil
+}
+
+// Snapshot is the contents of a file at one moment, or the fact that it
+// didn't exist
+type Snapshot struct {
+	path   string
+	data   []byte
+	exists bool
+}
+
+// TakeSnapshot records the current contents of path
+func TakeSnapshot(path string) Snapshot {
+	data, err := os.ReadFile(path)
+	return Snapshot{path: path, data: data, exists: err == nil}
+}
+
+// Restore puts the file back the way it was, removing it if it didn't exist
+func (s Snapshot) Restore() error {
+	if !s.exists {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(s.path, s.data, 0644)
+}
+
+// FileEdit runs edit, which changes the file at path, and returns an action
+// that switches the file between its contents before and after
+func FileEdit(description, path string, edit func() error) (Action, error) {
+	before := TakeSnapshot(path)
+	if err := edit(); err != nil {
+		return Action{}, err
+	}
+	after := TakeSnapshot(path)
+	return Action{Description: description, Undo: before.Restore, Redo: after.Restore}, nil
+}
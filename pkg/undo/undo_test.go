@@ -0,0 +1,73 @@
+package undo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStackUndoRedo(t *testing.T) {
+	value := 0
+	set := func(n int) func() error {
+		return func() error {
+			value = n
+			return nil
+		}
+	}
+
+	stack := NewStack(2)
+	for n := 1; n <= 3; n++ {
+		value = n
+		stack.Push(Action{Description: "set", Undo: set(n - 1), Redo: set(n)})
+	}
+
+	// Only the last two edits are remembered
+	stack.Undo()
+	stack.Undo()
+	if value != 1 {
+		t.Errorf("Expected 1 after two undos, got %d", value)
+	}
+	if _, err := stack.Undo(); err == nil {
+		t.Error("Expected an error undoing past the limit")
+	}
+
+	if _, err := stack.Redo(); err != nil || value != 2 {
+		t.Errorf("Expected 2 after redo, got %d (%v)", value, err)
+	}
+
+	// A new edit drops what could be redone
+	stack.Push(Action{Description: "set", Undo: set(2), Redo: set(5)})
+	if _, err := stack.Redo(); err == nil {
+		t.Error("Expected nothing to redo after a new edit")
+	}
+}
+
+func TestFileEdit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "song.lrc")
+
+	// Creating a file is undone by removing it
+	action, err := FileEdit("create", path, func() error {
+		return os.WriteFile(path, []byte("[00:01.00]first"), 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := action.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected undo to remove the created file")
+	}
+	if err := action.Redo(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changing a file is undone by putting the old contents back
+	action, _ = FileEdit("change", path, func() error {
+		return os.WriteFile(path, []byte("[00:01.00]second"), 0644)
+	})
+	action.Undo()
+	if data, _ := os.ReadFile(path); string(data) != "[00:01.00]first" {
+		t.Errorf("Expected the first version back, got %q", data)
+	}
+}
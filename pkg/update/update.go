@@ -0,0 +1,257 @@
+// Package update checks GitHub for new Tuneminal releases and installs the
+// binary built for this platform
+package update
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Repository is where releases are published
+const Repository = "heza-ru/Tuneminal"
+
+// checksumsAsset lists the SHA-256 of every binary in a release
+const checksumsAsset = "checksums.txt"
+
+// ErrNoAsset is returned when a release has no binary for this platform
+var ErrNoAsset = errors.New("no binary for this platform in the release")
+
+// ErrNoChecksums is returned when a release lists no checksums, so its
+// binary can't be checked and isn't installed
+var ErrNoChecksums = errors.New("the release has no " + checksumsAsset + " to check the download against")
+
+// Release is a published version
+type Release struct {
+	Tag    string  `json:"tag_name"`
+	Name   string  `json:"name"`
+	Notes  string  `json:"body"`
+	URL    string  `json:"html_url"`
+	Assets []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Checker looks up releases with the GitHub API
+type Checker struct {
+	BaseURL    string
+	Repository string
+	Client     *http.Client
+}
+
+// NewChecker creates a checker for the public GitHub API
+func NewChecker() *Checker {
+	return &Checker{
+		BaseURL:    "https://api.github.com",
+		Repository: Repository,
+		Client:     &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// get sends a GET request, failing on anything but 200 OK
+func (c *Checker) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Tuneminal (https://github.com/"+Repository+")")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+// Latest returns the newest published release
+func (c *Checker) Latest(ctx context.Context) (*Release, error) {
+	resp, err := c.get(ctx, fmt.Sprintf("%s/repos/%s/releases/latest", c.BaseURL, c.Repository))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("invalid release from GitHub: %w", err)
+	}
+	if release.Tag == "" {
+		return nil, errors.New("invalid release from GitHub: no tag")
+	}
+	return &release, nil
+}
+
+// parseVersion splits a tag such as "v1.4.2" into its numbers. Anything after
+// a "-" (a pre-release) is ignored.
+func parseVersion(tag string) ([]int, bool) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "v")
+	tag, _, _ = strings.Cut(tag, "-")
+	if tag == "" {
+		return nil, false
+	}
+	var numbers []int
+	for _, part := range strings.Split(tag, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, true
+}
+
+// Newer reports whether latest is a later version than current. Development
+// builds, whose version isn't a release tag, are never offered updates.
+func Newer(current, latest string) bool {
+	have, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	want, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	for i := 0; i < max(len(have), len(want)); i++ {
+		var h, w int
+		if i < len(have) {
+			h = have[i]
+		}
+		if i < len(want) {
+			w = want[i]
+		}
+		if h != w {
+			return w > h
+		}
+	}
+	return false
+}
+
+// AssetName is the name of the release binary for a platform
+func AssetName(goos, goarch string) string {
+	name := fmt.Sprintf("tuneminal-%s-%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// asset finds a release file by name
+func (r *Release) asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Binary returns the release binary for this platform
+func (r *Release) Binary() (Asset, error) {
+	asset, ok := r.asset(AssetName(runtime.GOOS, runtime.GOARCH))
+	if !ok {
+		return Asset{}, fmt.Errorf("%w (%s/%s)", ErrNoAsset, runtime.GOOS, runtime.GOARCH)
+	}
+	return asset, nil
+}
+
+// checksum returns the SHA-256 the release lists for a file
+func (c *Checker) checksum(ctx context.Context, release *Release, name string) (string, error) {
+	asset, ok := release.asset(checksumsAsset)
+	if !ok {
+		return "", ErrNoChecksums
+	}
+	resp, err := c.get(ctx, asset.URL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s has no checksum for %s", checksumsAsset, name)
+}
+
+// Install downloads the release binary for this platform, checks it against
+// the release checksums and puts it in place of executable. The old binary
+// is kept beside it with a .old suffix until the next install.
+func (c *Checker) Install(ctx context.Context, release *Release, executable string) error {
+	binary, err := release.Binary()
+	if err != nil {
+		return err
+	}
+	want, err := c.checksum(ctx, release, binary.Name)
+	if errors.Is(err, ErrNoChecksums) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get checksums: %w", err)
+	}
+
+	resp, err := c.get(ctx, binary.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	temp, err := os.CreateTemp(filepath.Dir(executable), ".tuneminal-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(temp, hash), resp.Body)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", binary.Name, err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("%s is corrupt: checksum %s, want %s", binary.Name, got, want)
+	}
+	if err := os.Chmod(temp.Name(), 0755); err != nil {
+		return err
+	}
+
+	// A running executable can't be overwritten on Windows, but it can be
+	// renamed out of the way
+	old := executable + ".old"
+	os.Remove(old)
+	if err := os.Rename(executable, old); err != nil {
+		return err
+	}
+	if err := os.Rename(temp.Name(), executable); err != nil {
+		os.Rename(old, executable)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            bool
+	}{
+		{"v1.2.0", "v1.3.0", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.10.0", "v1.9.0", false},
+		{"1.2", "v1.2.1", true},
+		{"v1.2.1", "v1.2", false},
+		{"v2.0.0-rc1", "v2.0.0", false},
+		{"dev", "v9.0.0", false},
+		{"v1.0.0", "nightly", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.current, tt.latest); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestInstall(t *testing.T) {
+	binary := []byte("new tuneminal")
+	sum := sha256.Sum256(binary)
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/" + Repository + "/releases/latest":
+			json.NewEncoder(w).Encode(Release{
+				Tag:   "v1.5.0",
+				Notes: "- Party flow",
+				Assets: []Asset{
+					{Name: name, URL: server.URL + "/download/" + name},
+					{Name: checksumsAsset, URL: server.URL + "/download/" + checksumsAsset},
+				},
+			})
+		case "/download/" + name:
+			w.Write(binary)
+		case "/download/" + checksumsAsset:
+			fmt.Fprintf(w, "%s  %s\n", hex.EncodeToString(sum[:]), name)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	checker := &Checker{BaseURL: server.URL, Repository: Repository, Client: server.Client()}
+	release, err := checker.Latest(context.Background())
+	if err != nil {
+		t.Fatalf("Latest() error: %v", err)
+	}
+	if release.Tag != "v1.5.0" || !strings.Contains(release.Notes, "Party flow") {
+		t.Errorf("Unexpected release: %+v", release)
+	}
+
+	executable := filepath.Join(t.TempDir(), "tuneminal")
+	os.WriteFile(executable, []byte("old tuneminal"), 0755)
+	if err := checker.Install(context.Background(), release, executable); err != nil {
+		t.Fatalf("Install() error: %v", err)
+	}
+	if data, _ := os.ReadFile(executable); string(data) != string(binary) {
+		t.Errorf("Expected the new binary in place, got %q", data)
+	}
+	if data, _ := os.ReadFile(executable + ".old"); string(data) != "old tuneminal" {
+		t.Errorf("Expected the old binary kept, got %q", data)
+	}
+
+	// A binary that doesn't match its checksum isn't installed
+	binary = []byte("tampered")
+	if err := checker.Install(context.Background(), release, executable); err == nil {
+		t.Error("Expected a corrupt download to fail")
+	}
+	if data, _ := os.ReadFile(executable); string(data) != "new tuneminal" {
+		t.Errorf("Expected the installed binary untouched, got %q", data)
+	}
+}
+
+func TestInstallWithoutChecksums(t *testing.T) {
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/download/"+name {
+			w.Write([]byte("unverified tuneminal"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	checker := &Checker{BaseURL: server.URL, Repository: Repository, Client: server.Client()}
+	release := &Release{Tag: "v1.5.0", Assets: []Asset{{Name: name, URL: server.URL + "/download/" + name}}}
+	executable := filepath.Join(t.TempDir(), "tuneminal")
+	os.WriteFile(executable, []byte("old tuneminal"), 0755)
+
+	if err := checker.Install(context.Background(), release, executable); !errors.Is(err, ErrNoChecksums) {
+		t.Errorf("Expected ErrNoChecksums, got %v", err)
+	}
+	if data, _ := os.ReadFile(executable); string(data) != "old tuneminal" {
+		t.Errorf("Expected the binary untouched, got %q", data)
+	}
+}
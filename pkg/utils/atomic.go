@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// AtomicFile is written under a temporary name beside its path and only
+// moved into place by Commit, so a crash part way through leaves the old
+// file as it was instead of half of the new one
+type AtomicFile struct {
+	*os.File
+	path      string
+	mode      os.FileMode // 0 to keep path's mode
+	committed bool
+}
+
+// CreateAtomic starts writing path. Close without Commit throws the new
+// contents away, so deferring Close is enough to clean up after an error.
+func CreateAtomic(path string) (*AtomicFile, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	return &AtomicFile{File: file, path: path}, nil
+}
+
+// Commit flushes the file to disk and puts it in place of path. It keeps
+// the permissions of the file it replaces, so a private one stays private;
+// a new file gets 0644.
+func (f *AtomicFile) Commit() error {
+	if f.committed {
+		return nil
+	}
+	f.committed = true
+
+	mode := f.mode
+	if mode == 0 {
+		mode = 0644
+		if info, err := os.Stat(f.path); err == nil {
+			mode = info.Mode().Perm()
+		}
+	}
+
+	if err := f.File.Sync(); err != nil {
+		f.abort()
+		return err
+	}
+	if err := f.File.Chmod(mode); err != nil {
+		f.abort()
+		return err
+	}
+	if err := f.File.Close(); err != nil {
+		os.Remove(f.File.Name())
+		return err
+	}
+	if err := os.Rename(f.File.Name(), f.path); err != nil {
+		os.Remove(f.File.Name())
+		return err
+	}
+	return nil
+}
+
+// Close discards the file if it wasn't committed
+func (f *AtomicFile) Close() error {
+	if f.committed {
+		return nil
+	}
+	f.committed = true
+	f.abort()
+	return nil
+}
+
+// abort closes and removes the temporary file
+func (f *AtomicFile) abort() {
+	f.File.Close()
+	os.Remove(f.File.Name())
+}
+
+// WriteFileAtomic writes data to path the way os.WriteFile does, but
+// replaces the file in one step
+func WriteFileAtomic(path string, data []byte) error {
+	return writeFileAtomic(path, data, 0)
+}
+
+// writeFileAtomic is WriteFileAtomic giving the file mode, or keeping the
+// mode path has for 0
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	file, err := CreateAtomic(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	file.mode = mode
+
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+	return file.Commit()
+}
+
+// BackupPath returns where the previous version of path is kept
+func BackupPath(path string) string {
+	return path + ".bak"
+}
+
+// WriteFileWithBackup writes data to path in one step, keeping what was
+// there before in BackupPath(path) with the same permissions
+func WriteFileWithBackup(path string, data []byte) error {
+	previous, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(BackupPath(path), previous, info.Mode().Perm()); err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	return WriteFileAtomic(path, data)
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileWithBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	if err := WriteFileWithBackup(path, []byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(BackupPath(path)); !os.IsNotExist(err) {
+		t.Error("expected no backup of a file that didn't exist")
+	}
+
+	if err := WriteFileWithBackup(path, []byte("second")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "second" {
+		t.Errorf("file = %q, want second", data)
+	}
+	if data, _ := os.ReadFile(BackupPath(path)); string(data) != "first" {
+		t.Errorf("backup = %q, want first", data)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("mode = %v, %v, want 0644", info.Mode().Perm(), err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("expected only the file and its backup, got %v", entries)
+	}
+}
+
+func TestWriteFileKeepsMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte("secret"), 0600)
+	os.Chmod(path, 0600)
+
+	if err := WriteFileWithBackup(path, []byte("new secret")); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{path, BackupPath(path)} {
+		if info, err := os.Stat(file); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("%s: mode = %v, %v, want 0600", filepath.Base(file), info.Mode().Perm(), err)
+		}
+	}
+}
+
+func TestAtomicFileCloseWithoutCommit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "export.csv")
+	os.WriteFile(path, []byte("old"), 0644)
+
+	file, err := CreateAtomic(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte("half written"))
+	file.Close()
+
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("file = %q, want it untouched", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected the temporary file to be removed, got %v", entries)
+	}
+}
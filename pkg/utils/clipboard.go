@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// clipboardCommands lists the clipboard readers tried on each platform, in order
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbpaste"}},
+	"windows": {{"powershell", "-NoProfile", "-Command", "Get-Clipboard"}},
+	"linux": {
+		{"wl-paste", "--no-newline"},
+		{"xclip", "-selection", "clipboard", "-o"},
+		{"xsel", "--clipboard", "--output"},
+	},
+}
+
+// ReadClipboard returns the text on the system clipboard
+func ReadClipboard() (string, error) {
+	commands, ok := clipboardCommands[runtime.GOOS]
+	if !ok {
+		commands = clipboardCommands["linux"]
+	}
+
+	for _, command := range commands {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			continue
+		}
+		output, err := exec.Command(command[0], command[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to read clipboard with %s: %w", command[0], err)
+		}
+		return string(output), nil
+	}
+
+	return "", fmt.Errorf("no clipboard tool found (install wl-clipboard, xclip or xsel)")
+}
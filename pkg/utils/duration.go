@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatDuration formats a duration for display as mm:ss, or h:mm:ss from an
+// hour up. Partial seconds are dropped, so a position never shows ahead of
+// where playback is.
+func FormatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	hours := int(d / time.Hour)
+	minutes := int(d/time.Minute) % 60
+	seconds := int(d/time.Second) % 60
+	if hours > 0 {
+		return fmt.Sprintf("%s%d:%02d:%02d", sign, hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, minutes, seconds)
+}
+
+// FormatDurationPrecise formats a duration like FormatDuration with the
+// milliseconds added, e.g. 03:25.040
+func FormatDurationPrecise(d time.Duration) string {
+	millis := int((d % time.Second) / time.Millisecond)
+	if millis < 0 {
+		millis = -millis
+	}
+	return fmt.Sprintf("%s.%03d", FormatDuration(d), millis)
+}
+
+// ParseDuration reads a time written as ss, mm:ss or h:mm:ss, with optional
+// fractional seconds such as 1:02.5
+func ParseDuration(s string) (time.Duration, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time %q: use mm:ss or h:mm:ss", s)
+	}
+
+	// Hours and minutes, as minutes
+	minutes := 0
+	for i, part := range parts[:len(parts)-1] {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 0 || (i > 0 && value >= 60) {
+			return 0, fmt.Errorf("invalid time %q: use mm:ss or h:mm:ss", s)
+		}
+		minutes = minutes*60 + value
+	}
+
+	last := parts[len(parts)-1]
+	seconds, err := strconv.ParseFloat(last, 64)
+	if err != nil || strings.ContainsAny(last, "eE+-") || (len(parts) > 1 && seconds >= 60) {
+		return 0, fmt.Errorf("invalid time %q: use mm:ss or h:mm:ss", s)
+	}
+	return time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second)), nil
+}
+
+// FramesDuration is how long frames of audio at rate frames per second play,
+// to the nanosecond
+func FramesDuration(frames int64, rate int) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	return time.Duration(frames) * time.Second / time.Duration(rate)
+}
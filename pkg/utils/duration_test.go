@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d       time.Duration
+		want    string
+		precise string
+	}{
+		{0, "00:00", "00:00.000"},
+		{3*time.Minute + 25*time.Second + 40*time.Millisecond, "03:25", "03:25.040"},
+		{59*time.Minute + 59*time.Second + 999*time.Millisecond, "59:59", "59:59.999"},
+		{time.Hour, "1:00:00", "1:00:00.000"},
+		{2*time.Hour + 5*time.Minute + 7*time.Second + 500*time.Millisecond, "2:05:07", "2:05:07.500"},
+		{-90 * time.Second, "-01:30", "-01:30.000"},
+	}
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+		if got := FormatDurationPrecise(tt.d); got != tt.precise {
+			t.Errorf("FormatDurationPrecise(%v) = %q, want %q", tt.d, got, tt.precise)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+	}{
+		{"45", 45 * time.Second},
+		{"01:30", 90 * time.Second},
+		{"1:02.5", time.Minute + 2500*time.Millisecond},
+		{"75:00", 75 * time.Minute},
+		{"1:15:00", 75 * time.Minute},
+		{" 2:05:07.250 ", 2*time.Hour + 5*time.Minute + 7250*time.Millisecond},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.s)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, %v, want %v", tt.s, got, err, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "abc", "1:60", "1:75:00", "1:2:3:4", "-5", "1e3", "1:-30"} {
+		if _, err := ParseDuration(s); err == nil {
+			t.Errorf("ParseDuration(%q): expected an error", s)
+		}
+	}
+}
+
+func TestFramesDuration(t *testing.T) {
+	if got := FramesDuration(44100*3+22050, 44100); got != 3500*time.Millisecond {
+		t.Errorf("FramesDuration = %v, want 3.5s", got)
+	}
+	if got := FramesDuration(1000, 0); got != 0 {
+		t.Errorf("FramesDuration with no rate = %v, want 0", got)
+	}
+}
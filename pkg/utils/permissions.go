@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// DirWritable reports whether files can be created in dir, by creating and
+// removing one. Permission bits alone miss read-only mounts, such as a CD or
+// an SD card with its lock switch on.
+func DirWritable(dir string) bool {
+	probe, err := os.CreateTemp(dir, ".tuneminal-write-*")
+	if err != nil {
+		return false
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return true
+}
+
+// FileErrorHint returns what to do about a failed file operation, or "" when
+// there's nothing to suggest
+func FileErrorHint(err error) string {
+	switch {
+	case errors.Is(err, syscall.EROFS):
+		return "The drive is mounted read-only. Remount it writable, or copy the songs to a folder you can change."
+	case errors.Is(err, fs.ErrPermission):
+		return "You don't have permission to change this file or its folder. Check who owns them and their permissions, e.g. with ls -l."
+	case errors.Is(err, syscall.ENOSPC):
+		return "The drive is full. Free some space and try again."
+	case errors.Is(err, fs.ErrNotExist):
+		return "The file or folder is gone, perhaps moved, deleted or on a drive that was unplugged."
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestDirWritable(t *testing.T) {
+	dir := t.TempDir()
+	if !DirWritable(dir) {
+		t.Error("expected a temporary directory to be writable")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected the probe to be removed, got %v", entries)
+	}
+	if DirWritable(filepath.Join(dir, "missing")) {
+		t.Error("expected a missing directory not to be writable")
+	}
+}
+
+func TestFileErrorHint(t *testing.T) {
+	readOnly := &os.PathError{Op: "open", Path: "/media/cd/song.lrc", Err: syscall.EROFS}
+	if hint := FileErrorHint(fmt.Errorf("saving lyrics: %w", readOnly)); !strings.Contains(hint, "read-only") {
+		t.Errorf("hint for a read-only drive = %q", hint)
+	}
+	denied := &os.PathError{Op: "rename", Path: "/music/song.mp3", Err: syscall.EACCES}
+	if hint := FileErrorHint(denied); !strings.Contains(hint, "permission") {
+		t.Errorf("hint for a denied rename = %q", hint)
+	}
+	if hint := FileErrorHint(fmt.Errorf("bad tag")); hint != "" {
+		t.Errorf("expected no hint for an unrelated error, got %q", hint)
+	}
+}
@@ -0,0 +1,125 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tuneminal/tuneminal/pkg/metadata"
+)
+
+// fileState is what the watcher remembers about a file between polls
+type fileState struct {
+	size    int64
+	modTime time.Time
+	handled bool
+}
+
+// Watcher polls folders and reports audio files that appear in them
+type Watcher struct {
+	folders  []string
+	interval time.Duration
+	files    map[string]*fileState
+	stop     chan struct{}
+	once     sync.Once
+
+	// OnNewFile is called from the watcher goroutine for each new, fully
+	// written audio file
+	OnNewFile func(path string)
+}
+
+// NewWatcher creates a watcher for the given folders. Files already present
+// are ignored; only files that appear later are reported.
+func NewWatcher(folders []string, interval time.Duration) *Watcher {
+	w := &Watcher{
+		folders:  folders,
+		interval: interval,
+		files:    make(map[string]*fileState),
+		stop:     make(chan struct{}),
+	}
+
+	for path, info := range w.scan() {
+		w.files[path] = &fileState{size: info.Size(), modTime: info.ModTime(), handled: true}
+	}
+	return w
+}
+
+// Start begins polling in the background
+func (w *Watcher) Start() {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.Poll()
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends polling
+func (w *Watcher) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+}
+
+// Poll checks the folders once. A new file is reported after it has kept the
+// same size and modification time across two polls.
+func (w *Watcher) Poll() {
+	current := w.scan()
+
+	for path, info := range current {
+		state, seen := w.files[path]
+		if !seen {
+			w.files[path] = &fileState{size: info.Size(), modTime: info.ModTime()}
+			continue
+		}
+		if state.handled {
+			continue
+		}
+
+		// Still being written (e.g. a download in progress)
+		if info.Size() != state.size || !info.ModTime().Equal(state.modTime) {
+			state.size, state.modTime = info.Size(), info.ModTime()
+			continue
+		}
+
+		state.handled = true
+		if w.OnNewFile != nil {
+			w.OnNewFile(path)
+		}
+	}
+
+	// Forget files that were removed so they are reported if they come back
+	for path := range w.files {
+		if _, ok := current[path]; !ok {
+			delete(w.files, path)
+		}
+	}
+}
+
+// scan lists the audio files directly inside the watched folders
+func (w *Watcher) scan() map[string]os.FileInfo {
+	found := make(map[string]os.FileInfo)
+	for _, folder := range w.folders {
+		entries, err := os.ReadDir(folder)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !metadata.IsSupportedFormat(filepath.Ext(entry.Name())) {
+				continue
+			}
+			if info, err := entry.Info(); err == nil {
+				found[filepath.Join(folder, entry.Name())] = info
+			}
+		}
+	}
+	return found
+}
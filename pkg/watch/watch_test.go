@@ -0,0 +1,32 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcherReportsNewFilesOnceWritten(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "old.mp3"), []byte("old"), 0644)
+
+	var reported []string
+	w := NewWatcher([]string{dir}, time.Hour)
+	w.OnNewFile = func(path string) { reported = append(reported, path) }
+
+	newFile := filepath.Join(dir, "new.mp3")
+	os.WriteFile(newFile, []byte("part"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644)
+
+	w.Poll() // first sighting
+	if len(reported) != 0 {
+		t.Fatalf("File reported before it was known to be complete: %v", reported)
+	}
+
+	w.Poll() // unchanged, so complete
+	w.Poll() // already handled
+	if len(reported) != 1 || reported[0] != newFile {
+		t.Fatalf("Expected only %s to be reported, got %v", newFile, reported)
+	}
+}